@@ -0,0 +1,375 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin has runtime controls for operators. Controls pauses and resumes JetStream consumers and micro
+// endpoints of a running service, storing the paused state in KV so every instance, and instances started later,
+// honor it.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+var (
+	ErrInvalidTarget = fmt.Errorf("targets are dot separated tokens of letters, numbers, dashes, and underscores")
+	ErrUnknownTarget = fmt.Errorf("unknown target")
+)
+
+var validTarget = regexp.MustCompile(`^[-_a-zA-Z0-9]+(\.[-_a-zA-Z0-9]+)*$`)
+
+// State is the pause state of a target
+type State struct {
+	Target string    `json:"target"`
+	Paused bool      `json:"paused"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+}
+
+// ControlsOpt is a functional option to modify Controls
+type ControlsOpt func(*Controls)
+
+// Controls pauses and resumes registered targets. Targets are named like consumer.orders or endpoint.create.
+type Controls struct {
+	kv      nats.KeyValue
+	service string
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	targets map[string]sdnats.Pauser
+	paused  map[string]State
+}
+
+// SetLogger sets the logger used to report pauses and resumes
+func SetLogger(l *slog.Logger) ControlsOpt {
+	return func(c *Controls) {
+		c.logger = l
+	}
+}
+
+// NewControls returns Controls storing the paused state for service in kv under <service>.paused.<target>
+func NewControls(kv nats.KeyValue, service string, opts ...ControlsOpt) *Controls {
+	c := &Controls{
+		kv:      kv,
+		service: service,
+		logger:  slog.Default(),
+		now:     time.Now,
+		targets: make(map[string]sdnats.Pauser),
+		paused:  make(map[string]State),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Controls) prefix() string {
+	return c.service + ".paused."
+}
+
+// Register adds a target. It is paused right away if it was paused before it was registered. A Gate is held for
+// operator pauses separately from its other pausers.
+func (c *Controls) Register(target string, p sdnats.Pauser) error {
+	if !validTarget.MatchString(target) {
+		return fmt.Errorf("%w: %q", ErrInvalidTarget, target)
+	}
+	if g, ok := p.(*Gate); ok {
+		p = g.For(operatorHold)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.targets[target] = p
+	if s, ok := c.paused[target]; ok {
+		p.Pause()
+		c.logger.Info("target paused", "target", target, "reason", s.Reason)
+	}
+
+	return nil
+}
+
+// Gate returns a new gate registered as target. It panics if the target name is invalid.
+func (c *Controls) Gate(target string) *Gate {
+	g := NewGate()
+	if err := c.Register(target, g); err != nil {
+		panic(err)
+	}
+
+	return g
+}
+
+// Pause pauses the target on every instance of the service until it is resumed
+func (c *Controls) Pause(target, reason string) error {
+	if err := c.known(target); err != nil {
+		return err
+	}
+
+	s := State{Target: target, Paused: true, Reason: reason, Since: c.now().UTC()}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if _, err := c.kv.Put(c.prefix()+target, data); err != nil {
+		return err
+	}
+	c.apply(target, s)
+
+	return nil
+}
+
+// Resume resumes the target on every instance of the service
+func (c *Controls) Resume(target string) error {
+	if err := c.known(target); err != nil {
+		return err
+	}
+
+	if err := c.kv.Delete(c.prefix() + target); err != nil {
+		return err
+	}
+	c.apply(target, State{Target: target})
+
+	return nil
+}
+
+func (c *Controls) known(target string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.targets[target]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTarget, target)
+	}
+
+	return nil
+}
+
+// States returns the state of every registered target sorted by name
+func (c *Controls) States() []State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	states := make([]State, 0, len(c.targets))
+	for target := range c.targets {
+		s, ok := c.paused[target]
+		if !ok {
+			s = State{Target: target}
+		}
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Target < states[j].Target })
+
+	return states
+}
+
+// Watch keeps the targets in sync with the paused state in KV until the context is cancelled. The initial values
+// are applied first, so a restarted instance comes up with its targets paused.
+func (c *Controls) Watch(ctx context.Context) error {
+	w, err := c.kv.Watch(c.prefix()+">", nats.Context(ctx))
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.Updates():
+			if !ok {
+				return nil
+			}
+			// nil marks the end of the initial values
+			if e == nil {
+				continue
+			}
+			c.applyEntry(e.Key(), e.Operation(), e.Value())
+		}
+	}
+}
+
+func (c *Controls) applyEntry(key string, op nats.KeyValueOp, value []byte) {
+	target := strings.TrimPrefix(key, c.prefix())
+	if op != nats.KeyValuePut {
+		c.apply(target, State{Target: target})
+		return
+	}
+
+	var s State
+	if err := json.Unmarshal(value, &s); err != nil {
+		c.logger.Error(fmt.Sprintf("invalid pause state for %s: %v", target, err))
+		return
+	}
+	s.Target = target
+	c.apply(target, s)
+}
+
+// apply records the state and pauses or resumes the target if it is registered and its state changed
+func (c *Controls) apply(target string, s State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, wasPaused := c.paused[target]
+	if s.Paused {
+		c.paused[target] = s
+	} else {
+		delete(c.paused, target)
+	}
+
+	p, ok := c.targets[target]
+	if !ok || wasPaused == s.Paused {
+		return
+	}
+
+	if s.Paused {
+		p.Pause()
+		c.logger.Info("target paused", "target", target, "reason", s.Reason)
+		return
+	}
+	p.Resume()
+	c.logger.Info("target resumed", "target", target)
+}
+
+type pauseRequest struct {
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// clientError returns err as a client error if the caller caused it
+func clientError(err error) error {
+	switch {
+	case errors.Is(err, ErrUnknownTarget):
+		return sderrors.NewClientError(err, http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTarget):
+		return sderrors.NewClientError(err, http.StatusBadRequest)
+	}
+
+	return err
+}
+
+// Routes returns HTTP routes to list, pause, and resume targets. Mount them behind authentication.
+//
+//	GET  /paused
+//	POST /pause/{target}  {"reason": "..."}
+//	POST /resume/{target}
+func (c *Controls) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/paused",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				return json.NewEncoder(w).Encode(c.States())
+			}},
+		},
+		{
+			Method:       http.MethodPost,
+			Path:         "/pause/{target}",
+			MaxBodyBytes: 4096,
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				var req pauseRequest
+				if r.ContentLength != 0 {
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						return sderrors.NewClientError(err, http.StatusBadRequest)
+					}
+				}
+				if err := c.Pause(r.PathValue("target"), req.Reason); err != nil {
+					return clientError(err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/resume/{target}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				if err := c.Resume(r.PathValue("target")); err != nil {
+					return clientError(err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}},
+		},
+	}
+}
+
+// Endpoints returns NATS endpoints to list, pause, and resume targets. Pause and resume take a JSON body like
+// {"target": "consumer.orders", "reason": "..."}. Restrict who can publish to them with account permissions.
+func (c *Controls) Endpoints() []sdnats.Endpoint {
+	return []sdnats.Endpoint{
+		{
+			Name:    "admin-paused",
+			Subject: "admin.paused",
+			Handler: micro.HandlerFunc(func(r micro.Request) {
+				r.RespondJSON(c.States())
+			}),
+		},
+		{
+			Name:    "admin-pause",
+			Subject: "admin.pause",
+			Handler: c.microHandler(func(req pauseRequest) error {
+				return c.Pause(req.Target, req.Reason)
+			}),
+		},
+		{
+			Name:    "admin-resume",
+			Subject: "admin.resume",
+			Handler: c.microHandler(func(req pauseRequest) error {
+				return c.Resume(req.Target)
+			}),
+		},
+	}
+}
+
+func (c *Controls) microHandler(fn func(pauseRequest) error) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		var req pauseRequest
+		err := json.Unmarshal(r.Data(), &req)
+		if err != nil {
+			err = sderrors.NewClientError(err, http.StatusBadRequest)
+		} else {
+			err = clientError(fn(req))
+		}
+
+		var ce sderrors.ClientError
+		switch {
+		case errors.As(err, &ce):
+			r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body())
+			return
+		case err != nil:
+			c.logger.Error(fmt.Sprintf("admin request failed: %v", err))
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		r.RespondJSON(c.States())
+	})
+}
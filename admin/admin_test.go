@@ -0,0 +1,155 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go"
+)
+
+func TestControls(t *testing.T) {
+	kv := kvtest.New()
+	c := NewControls(kv, "orders")
+	consumer := c.Gate("consumer.orders")
+	endpoint := c.Gate("endpoint.create")
+
+	if err := c.Pause("consumer.orders", "poison message"); err != nil {
+		t.Fatal(err)
+	}
+	if !consumer.Paused() || endpoint.Paused() {
+		t.Fatal("expected only the consumer to be paused")
+	}
+
+	var stored State
+	if err := json.Unmarshal(kv.Value("orders.paused.consumer.orders"), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Paused || stored.Reason != "poison message" {
+		t.Errorf("expected the pause to be stored but got %+v", stored)
+	}
+
+	// a restarted instance gets the stored state from Watch before registering its targets
+	restarted := NewControls(kv, "orders")
+	restarted.applyEntry("orders.paused.consumer.orders", nats.KeyValuePut, kv.Value("orders.paused.consumer.orders"))
+	if !restarted.Gate("consumer.orders").Paused() {
+		t.Error("expected the stored pause to apply to a target registered later")
+	}
+
+	if err := c.Resume("consumer.orders"); err != nil {
+		t.Fatal(err)
+	}
+	if consumer.Paused() || kv.Len() != 0 {
+		t.Error("expected the consumer to be resumed and the pause removed")
+	}
+
+	if err := c.Pause("consumer.missing", ""); !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("expected %v but got %v", ErrUnknownTarget, err)
+	}
+	if err := c.Register("consumer orders", NewGate()); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("expected %v but got %v", ErrInvalidTarget, err)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	c := NewControls(kvtest.New(), "orders")
+	g := c.Gate("endpoint.create")
+
+	mux := http.NewServeMux()
+	for _, r := range c.Routes() {
+		mux.Handle(r.Method+" "+r.Path, r.Handler)
+	}
+
+	tt := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		paused bool
+	}{
+		{name: "pause", method: http.MethodPost, path: "/pause/endpoint.create", body: `{"reason": "incident 42"}`, code: http.StatusNoContent, paused: true},
+		{name: "list", method: http.MethodGet, path: "/paused", code: http.StatusOK, paused: true},
+		{name: "unknown", method: http.MethodPost, path: "/pause/endpoint.delete", code: http.StatusNotFound, paused: true},
+		{name: "resume", method: http.MethodPost, path: "/resume/endpoint.create", code: http.StatusNoContent},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(v.method, v.path, strings.NewReader(v.body)))
+			if rr.Code != v.code {
+				t.Errorf("expected %d but got %d: %s", v.code, rr.Code, rr.Body.String())
+			}
+			if g.Paused() != v.paused {
+				t.Errorf("expected paused to be %v", v.paused)
+			}
+			if v.method == http.MethodGet && !strings.Contains(rr.Body.String(), `"reason":"incident 42"`) {
+				t.Errorf("expected the pause reason to be listed but got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGateWait(t *testing.T) {
+	g := NewGate()
+	if err := g.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Wait to block while paused but got %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- g.Wait(context.Background()) }()
+	g.Resume()
+	if err := <-done; err != nil {
+		t.Errorf("expected Wait to return after Resume but got %v", err)
+	}
+}
+
+func TestGateHolds(t *testing.T) {
+	c := NewControls(kvtest.New(), "orders")
+	g := c.Gate("consumer.orders")
+
+	// lame duck handling pauses and resumes the gate directly
+	g.Pause()
+	if err := c.Pause("consumer.orders", "incident 42"); err != nil {
+		t.Fatal(err)
+	}
+	g.Resume()
+	if !g.Paused() {
+		t.Fatal("expected the operator pause to hold after lame duck handling resumed")
+	}
+
+	if err := c.Resume("consumer.orders"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Paused() {
+		t.Error("expected the gate to open once every hold was released")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/config"
+	"github.com/SencilloDev/sencillo-go/scrub"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+)
+
+// SecretConfigFields are the config key suffixes whose values ConfigRoutes never shows, so nats_seed,
+// db_password, and client_secret are all hidden
+var SecretConfigFields = []string{"password", "secret", "token", "seed", "jwt", "key", "credentials", "creds", "dsn", "authorization"}
+
+// ConfigRoutes returns an HTTP route showing the profile a service loaded, its layers, the merged values, and
+// which layer set each value. Values of keys ending in one of SecretConfigFields are replaced, and the rest are
+// scrubbed with the default detectors. Mount it behind authentication.
+//
+//	GET /config
+func ConfigRoutes(r *config.Resolved) []sdhttp.Route {
+	s := scrub.NewScrubber(scrub.AddDetectors(scrub.FieldSuffixes(SecretConfigFields...)))
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/config",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, req *http.Request) error {
+				// copy the values so scrubbing them in place leaves the loaded config alone
+				b, err := json.Marshal(r.Values)
+				if err != nil {
+					return err
+				}
+				var values map[string]any
+				if err := json.Unmarshal(b, &values); err != nil {
+					return err
+				}
+
+				resolved := *r
+				resolved.Values = s.Value("", values).(map[string]any)
+				w.Header().Set("Content-Type", "application/json")
+				return json.NewEncoder(w).Encode(resolved)
+			}},
+		},
+	}
+}
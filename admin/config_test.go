@@ -0,0 +1,78 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+	"github.com/SencilloDev/sencillo-go/config"
+)
+
+func TestConfigRoutes(t *testing.T) {
+	r := &config.Resolved{
+		Profile: "prod",
+		Layers:  []config.Layer{{Name: "base", File: "config.yaml"}, {Name: "prod", File: "config.prod.yaml"}},
+		Values: map[string]any{
+			"port":          float64(80),
+			"nats":          map[string]any{"password": "hunter2"},
+			"nats_seed":     "SUAM...",
+			"nats_jwt":      "eyJ0...",
+			"db_password":   "hunter2",
+			"client_secret": "s3cret",
+			"database_dsn":  "postgres://app:pw@db/app",
+			"cache_ttl":     "5m",
+		},
+		Origins: []config.Origin{
+			{Path: "nats.password", Layer: "prod", File: "config.prod.yaml"},
+			{Path: "port", Layer: "prod", File: "config.prod.yaml", Overrides: []string{"base"}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range ConfigRoutes(r) {
+		mux.Handle(route.Method+" "+route.Path, route.Handler)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d", rr.Code)
+	}
+
+	var got config.Resolved
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Profile != "prod" || len(got.Layers) != 2 || len(got.Origins) != 2 || got.Origins[1].Overrides[0] != "base" {
+		t.Errorf("unexpected config %+v", got)
+	}
+	if password := got.Values["nats"].(map[string]any)["password"]; password != codec.RedactedValue {
+		t.Errorf("expected the password to be scrubbed but got %v", password)
+	}
+	for _, k := range []string{"nats_seed", "nats_jwt", "db_password", "client_secret", "database_dsn"} {
+		if got.Values[k] != codec.RedactedValue {
+			t.Errorf("expected %s to be scrubbed but got %v", k, got.Values[k])
+		}
+	}
+	if got.Values["cache_ttl"] != "5m" {
+		t.Errorf("expected other values to be shown but got %v", got.Values["cache_ttl"])
+	}
+	if r.Values["nats"].(map[string]any)["password"] != "hunter2" {
+		t.Error("expected the loaded config to be left alone")
+	}
+}
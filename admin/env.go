@@ -0,0 +1,41 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/env"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+)
+
+// EnvironmentRoutes returns an HTTP route listing the environment variables returned by env.Bind, with whether
+// each was set and its value. Secret values and defaults are already redacted by Bind. Mount it behind
+// authentication.
+//
+//	GET /environment
+func EnvironmentRoutes(vars []env.Variable) []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/environment",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				return json.NewEncoder(w).Encode(vars)
+			}},
+		},
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/env"
+)
+
+func TestEnvironmentRoutes(t *testing.T) {
+	vars := []env.Variable{
+		{Name: "PORT", Type: "integer", Default: "8080", Value: "8080"},
+		{Name: "NATS_SEED", Type: "string", Secret: true, Set: true, Value: "[REDACTED]"},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range EnvironmentRoutes(vars) {
+		mux.Handle(route.Method+" "+route.Path, route.Handler)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/environment", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d", rr.Code)
+	}
+
+	var got []env.Variable
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, vars) {
+		t.Errorf("expected %+v but got %+v", vars, got)
+	}
+}
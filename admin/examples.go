@@ -0,0 +1,52 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// InfoProvider returns a service's info, like micro.Service
+type InfoProvider interface {
+	Info() micro.Info
+}
+
+// ExampleRoutes returns an HTTP route listing runnable nats and curl invocations of every endpoint of svc, built
+// from the endpoint metadata set with apidocs.Doc. Curl examples call baseURL, or apidocs.DefaultBaseURL when empty.
+//
+//	GET /examples
+func ExampleRoutes(svc InfoProvider, baseURL string) []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/examples",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				docs, err := apidocs.FromInfo(svc.Info())
+				if err != nil {
+					return err
+				}
+				docs.BaseURL = baseURL
+
+				w.Header().Set("Content-Type", "application/json")
+				return json.NewEncoder(w).Encode(docs.Snippets())
+			}},
+		},
+	}
+}
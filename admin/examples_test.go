@@ -0,0 +1,65 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type staticInfo micro.Info
+
+func (s staticInfo) Info() micro.Info { return micro.Info(s) }
+
+func TestExampleRoutes(t *testing.T) {
+	info := staticInfo{
+		Endpoints: []micro.EndpointInfo{
+			{Name: "add", Subject: "math.add", Metadata: map[string]string{
+				apidocs.RequestExampleKey: `{"a":2}`,
+				apidocs.HTTPRouteKey:      "POST /math/add",
+			}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range ExampleRoutes(info, "https://api.example.com") {
+		mux.Handle(route.Method+" "+route.Path, route.Handler)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/examples", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d", rr.Code)
+	}
+
+	var got []apidocs.Snippet
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []apidocs.Snippet{{
+		Endpoint: "add",
+		Subject:  "math.add",
+		NATS:     `nats req math.add '{"a":2}'`,
+		Curl:     `curl -X POST 'https://api.example.com/math/add' -H 'Content-Type: application/json' -d '{"a":2}'`,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v but got %+v", want, got)
+	}
+}
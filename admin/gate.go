@@ -0,0 +1,152 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+var ErrPaused = fmt.Errorf("paused by an operator")
+
+// defaultHold is the reason the gate is held for by Pause
+const defaultHold = "pause"
+
+// operatorHold is the reason Controls holds its gates for
+const operatorHold = "operator"
+
+// Gate is a pause switch for a consumer or endpoint. It implements sdnats.Pauser, so the same gate can be
+// paused by Controls and by lame duck handling. Each reason holds the gate separately, and it stays paused until
+// every reason is released, so lame duck handling ending doesn't resume a gate an operator paused.
+type Gate struct {
+	mu      sync.Mutex
+	holds   map[string]bool
+	resumed chan struct{}
+}
+
+// NewGate returns a gate that isn't paused
+func NewGate() *Gate {
+	return &Gate{holds: make(map[string]bool)}
+}
+
+// Hold pauses the gate for reason
+func (g *Gate) Hold(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.holds) == 0 {
+		g.resumed = make(chan struct{})
+	}
+	g.holds[reason] = true
+}
+
+// Release drops the hold for reason. Once no holds are left, work passes through the gate again and anything
+// blocked in Wait wakes up.
+func (g *Gate) Release(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.holds[reason] {
+		return
+	}
+	delete(g.holds, reason)
+	if len(g.holds) == 0 {
+		close(g.resumed)
+	}
+}
+
+// Pause stops work passing through the gate
+func (g *Gate) Pause() {
+	g.Hold(defaultHold)
+}
+
+// Resume releases the hold taken by Pause
+func (g *Gate) Resume() {
+	g.Release(defaultHold)
+}
+
+// For returns a Pauser that holds the gate for reason, for passing the gate to more than one pauser
+func (g *Gate) For(reason string) sdnats.Pauser {
+	return reasonHold{gate: g, reason: reason}
+}
+
+type reasonHold struct {
+	gate   *Gate
+	reason string
+}
+
+func (h reasonHold) Pause() {
+	h.gate.Hold(h.reason)
+}
+
+func (h reasonHold) Resume() {
+	h.gate.Release(h.reason)
+}
+
+// Paused reports whether the gate is paused
+func (g *Gate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.holds) > 0
+}
+
+// Wait blocks while the gate is paused. Call it before each fetch in a pull consumer loop.
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	paused, resumed := len(g.holds) > 0, g.resumed
+	g.mu.Unlock()
+	if !paused {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// MsgHandler naks messages with the delay while the gate is paused so a push consumer redelivers them after it
+// resumes
+func (g *Gate) MsgHandler(delay time.Duration, h nats.MsgHandler) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		if g.Paused() {
+			msg.NakWithDelay(delay)
+			return
+		}
+
+		h(msg)
+	}
+}
+
+// Handler responds with a 503 error while the gate is paused
+func (g *Gate) Handler(h micro.Handler) micro.Handler {
+	body, _ := json.Marshal(map[string][]string{"errors": {ErrPaused.Error()}})
+	return micro.HandlerFunc(func(r micro.Request) {
+		if g.Paused() {
+			r.Error("503", http.StatusText(http.StatusServiceUnavailable), body)
+			return
+		}
+
+		h.Handle(r)
+	})
+}
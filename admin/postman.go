@@ -0,0 +1,61 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+)
+
+// PostmanRoutes returns HTTP routes downloading a Postman collection of svc's HTTP routes and its environment,
+// built from the endpoint metadata set with apidocs.Doc. The environment's baseUrl is baseURL, or
+// apidocs.DefaultBaseURL when empty.
+//
+//	GET /postman/collection.json
+//	GET /postman/environment.json
+func PostmanRoutes(svc InfoProvider, baseURL string) []sdhttp.Route {
+	download := func(suffix string, export func(apidocs.Service) any) http.Handler {
+		return &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+			docs, err := apidocs.FromInfo(svc.Info())
+			if err != nil {
+				return err
+			}
+			docs.BaseURL = baseURL
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", docs.Name+suffix))
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(export(docs))
+		}}
+	}
+
+	return []sdhttp.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/postman/collection.json",
+			Handler: download(".postman_collection.json", func(s apidocs.Service) any { return s.Postman() }),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/postman/environment.json",
+			Handler: download(".postman_environment.json", func(s apidocs.Service) any { return s.PostmanEnvironment() }),
+		},
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestPostmanRoutes(t *testing.T) {
+	info := staticInfo{
+		ServiceIdentity: micro.ServiceIdentity{Name: "math"},
+		Endpoints: []micro.EndpointInfo{
+			{Name: "add", Subject: "math.add", Metadata: map[string]string{apidocs.HTTPRouteKey: "POST /math/add"}},
+			{Name: "internal", Subject: "math.internal"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range PostmanRoutes(info, "https://api.example.com") {
+		mux.Handle(route.Method+" "+route.Path, route.Handler)
+	}
+
+	tt := []struct {
+		path        string
+		disposition string
+		check       func(t *testing.T, body []byte)
+	}{
+		{
+			path:        "/postman/collection.json",
+			disposition: `attachment; filename="math.postman_collection.json"`,
+			check: func(t *testing.T, body []byte) {
+				var c apidocs.PostmanCollection
+				if err := json.Unmarshal(body, &c); err != nil {
+					t.Fatal(err)
+				}
+				if len(c.Item) != 1 || c.Item[0].Request.URL.Raw != "{{baseUrl}}/math/add" {
+					t.Errorf("unexpected collection %+v", c)
+				}
+			},
+		},
+		{
+			path:        "/postman/environment.json",
+			disposition: `attachment; filename="math.postman_environment.json"`,
+			check: func(t *testing.T, body []byte) {
+				var env apidocs.PostmanEnvironment
+				if err := json.Unmarshal(body, &env); err != nil {
+					t.Fatal(err)
+				}
+				if len(env.Values) != 1 || env.Values[0].Value != "https://api.example.com" {
+					t.Errorf("unexpected environment %+v", env)
+				}
+			},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, v.path, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected 200 but got %d", rr.Code)
+			}
+			if got := rr.Header().Get("Content-Disposition"); got != v.disposition {
+				t.Errorf("expected %s but got %s", v.disposition, got)
+			}
+			v.check(t, rr.Body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apidocs documents NATS endpoints from the metadata they register with micro. Services describe each
+// endpoint with a Doc, and tools read the metadata back from the service's $SRV.INFO reply to write markdown.
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Endpoint metadata keys. Schemas, examples, headers, and errors are stored as JSON.
+const (
+	DescriptionKey     = "description"
+	FormatKey          = "format"
+	RequestSchemaKey   = "request_schema"
+	ResponseSchemaKey  = "response_schema"
+	RequestExampleKey  = "request_example"
+	ResponseExampleKey = "response_example"
+	HeadersKey         = "headers"
+	ErrorsKey          = "errors"
+	HTTPRouteKey       = "http_route"
+)
+
+// documentedKeys are the metadata keys Doc sets. Endpoint.Metadata holds the others.
+var documentedKeys = []string{DescriptionKey, FormatKey, RequestSchemaKey, ResponseSchemaKey, RequestExampleKey, ResponseExampleKey, HeadersKey, ErrorsKey, HTTPRouteKey}
+
+// Header is a request header an endpoint reads
+type Header struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ErrorCode is an error an endpoint can respond with, as the code in the Nats-Service-Error-Code header
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// Doc describes an endpoint. Request and Response are values of the payload types, and their JSON Schemas are
+// reflected from them. Examples are optional and are generated from the schemas when they are missing.
+type Doc struct {
+	Description     string
+	Format          string
+	Request         any
+	Response        any
+	RequestExample  any
+	ResponseExample any
+	Headers         []Header
+	Errors          []ErrorCode
+	// HTTPRoute is the gateway route forwarding to the endpoint, like POST /api/math/add
+	HTTPRoute string
+}
+
+// Metadata returns the endpoint metadata describing the endpoint, ready for micro.WithEndpointMetadata or
+// Endpoint.Metadata in the nats transport
+func (d Doc) Metadata() (map[string]string, error) {
+	m := map[string]string{}
+	if d.Description != "" {
+		m[DescriptionKey] = d.Description
+	}
+	if d.Format != "" {
+		m[FormatKey] = d.Format
+	}
+	if d.HTTPRoute != "" {
+		m[HTTPRouteKey] = d.HTTPRoute
+	}
+
+	values := []struct {
+		key   string
+		value any
+	}{
+		{key: RequestSchemaKey, value: schema(d.Request)},
+		{key: ResponseSchemaKey, value: schema(d.Response)},
+		{key: RequestExampleKey, value: d.RequestExample},
+		{key: ResponseExampleKey, value: d.ResponseExample},
+		{key: HeadersKey, value: d.Headers},
+		{key: ErrorsKey, value: d.Errors},
+	}
+	for _, v := range values {
+		if v.value == nil || (v.key == HeadersKey && len(d.Headers) == 0) || (v.key == ErrorsKey && len(d.Errors) == 0) {
+			continue
+		}
+		b, err := json.Marshal(v.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.key, err)
+		}
+		m[v.key] = string(b)
+	}
+
+	return m, nil
+}
+
+// schema reflects the JSON Schema of v's type, or returns nil for no payload
+func schema(v any) any {
+	if v == nil {
+		return nil
+	}
+	return jsonschema.Reflect(v)
+}
+
+// Endpoint is an endpoint's documentation read back from its metadata
+type Endpoint struct {
+	Name            string
+	Subject         string
+	QueueGroup      string
+	Description     string
+	Format          string
+	RequestSchema   *jsonschema.Schema
+	ResponseSchema  *jsonschema.Schema
+	RequestExample  json.RawMessage
+	ResponseExample json.RawMessage
+	Headers         []Header
+	Errors          []ErrorCode
+	HTTPRoute       string
+	// Metadata has the keys that aren't part of the documentation
+	Metadata map[string]string
+}
+
+// Service is a service's documentation
+type Service struct {
+	Name        string
+	Version     string
+	Description string
+	Endpoints   []Endpoint
+	// BaseURL is where the gateway serves HTTP routes in examples. The default is DefaultBaseURL.
+	BaseURL string
+}
+
+// FromInfo reads the documentation from a service's info. Endpoints are sorted by subject. Metadata that isn't
+// valid JSON is reported with the endpoint's name.
+func FromInfo(info micro.Info) (Service, error) {
+	svc := Service{Name: info.Name, Version: info.Version, Description: info.Description}
+	for _, ei := range info.Endpoints {
+		e := Endpoint{
+			Name:        ei.Name,
+			Subject:     ei.Subject,
+			QueueGroup:  ei.QueueGroup,
+			Description: ei.Metadata[DescriptionKey],
+			Format:      ei.Metadata[FormatKey],
+			HTTPRoute:   ei.Metadata[HTTPRouteKey],
+			Metadata:    map[string]string{},
+		}
+		for k, v := range ei.Metadata {
+			if !slices.Contains(documentedKeys, k) {
+				e.Metadata[k] = v
+			}
+		}
+
+		fields := []struct {
+			key string
+			dst any
+		}{
+			{key: RequestSchemaKey, dst: &e.RequestSchema},
+			{key: ResponseSchemaKey, dst: &e.ResponseSchema},
+			{key: RequestExampleKey, dst: &e.RequestExample},
+			{key: ResponseExampleKey, dst: &e.ResponseExample},
+			{key: HeadersKey, dst: &e.Headers},
+			{key: ErrorsKey, dst: &e.Errors},
+		}
+		for _, f := range fields {
+			v, ok := ei.Metadata[f.key]
+			if !ok {
+				continue
+			}
+			if err := json.Unmarshal([]byte(v), f.dst); err != nil {
+				return svc, fmt.Errorf("endpoint %s: %s: %w", ei.Name, f.key, err)
+			}
+		}
+
+		svc.Endpoints = append(svc.Endpoints, e)
+	}
+
+	slices.SortFunc(svc.Endpoints, func(a, b Endpoint) int { return strings.Compare(a.Subject, b.Subject) })
+	return svc, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
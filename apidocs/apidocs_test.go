@@ -0,0 +1,274 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type mathRequest struct {
+	A int `json:"a"`
+	B int `json:"b" jsonschema:"minimum=1"`
+}
+
+type mathResponse struct {
+	Result  int       `json:"result"`
+	Op      string    `json:"op" jsonschema:"enum=add,enum=subtract"`
+	Created time.Time `json:"created"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+func testInfo(t *testing.T) micro.Info {
+	t.Helper()
+	add, err := Doc{
+		Description:    "adds two numbers",
+		Format:         "application/json",
+		Request:        &mathRequest{},
+		Response:       &mathResponse{},
+		RequestExample: mathRequest{A: 2, B: 3},
+		Headers:        []Header{{Name: "Authorization", Description: "bearer token", Required: true}},
+		Errors:         []ErrorCode{{Code: "400", Description: "the payload isn't valid JSON"}},
+		HTTPRoute:      "POST /api/{op}",
+	}.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add["team"] = "math"
+
+	return micro.Info{
+		ServiceIdentity: micro.ServiceIdentity{Name: "math", Version: "1.2.0"},
+		Description:     "does math",
+		Endpoints: []micro.EndpointInfo{
+			{Name: "subtract", Subject: "math.subtract", Metadata: map[string]string{DescriptionKey: "subtracts | numbers"}},
+			{Name: "add", Subject: "math.add", QueueGroup: "q", Metadata: add},
+		},
+	}
+}
+
+func TestFromInfo(t *testing.T) {
+	svc, err := FromInfo(testInfo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.Endpoints) != 2 || svc.Endpoints[0].Name != "add" {
+		t.Fatalf("expected the endpoints sorted by subject but got %+v", svc.Endpoints)
+	}
+
+	add := svc.Endpoints[0]
+	if add.Description != "adds two numbers" || add.Format != "application/json" || add.QueueGroup != "q" {
+		t.Errorf("unexpected endpoint %+v", add)
+	}
+	if add.RequestSchema == nil || add.ResponseSchema == nil {
+		t.Fatal("expected the schemas to be read back")
+	}
+	if !reflect.DeepEqual(add.Metadata, map[string]string{"team": "math"}) {
+		t.Errorf("expected only the other metadata but got %v", add.Metadata)
+	}
+	if len(add.Headers) != 1 || !add.Headers[0].Required || len(add.Errors) != 1 {
+		t.Errorf("unexpected headers %v and errors %v", add.Headers, add.Errors)
+	}
+
+	if _, err := FromInfo(micro.Info{Endpoints: []micro.EndpointInfo{{Name: "bad", Metadata: map[string]string{HeadersKey: "nope"}}}}); err == nil || !strings.Contains(err.Error(), "endpoint bad") {
+		t.Errorf("expected an error naming the endpoint but got %v", err)
+	}
+}
+
+func TestExample(t *testing.T) {
+	var got map[string]any
+	b, err := json.Marshal(Example(jsonschema.Reflect(&mathResponse{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"result": float64(0), "op": "add", "created": "2025-01-01T00:00:00Z", "tags": []any{"string"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+
+	svc, err := FromInfo(testInfo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(svc.Endpoints[0].RequestPayload()); got != "{\n  \"a\": 2,\n  \"b\": 3\n}" {
+		t.Errorf("expected the documented example but got %s", got)
+	}
+	if got := svc.Endpoints[1].RequestPayload(); got != nil {
+		t.Errorf("expected no payload for an undocumented request but got %s", got)
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	svc, err := FromInfo(testInfo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files, err := WriteDir(dir, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected a file per endpoint and an index but got %v", files)
+	}
+
+	add, err := os.ReadFile(filepath.Join(dir, "add.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# add\n\nadds two numbers\n",
+		"| Subject | `math.add` |",
+		"| Queue group | `q` |",
+		"| team | math |",
+		"| `Authorization` | yes | bearer token |",
+		"## Request\n\n```json\n{\n  \"a\": 2,\n  \"b\": 3\n}\n```",
+		"## Response\n\n```json\n{\n  \"created\": \"2025-01-01T00:00:00Z\",",
+		"<summary>Schema</summary>",
+		"| HTTP route | `POST /api/{op}` |",
+		"## Examples\n\n```sh\nnats req math.add",
+		"| 400 | the payload isn't valid JSON |",
+	} {
+		if !strings.Contains(string(add), want) {
+			t.Errorf("expected add.md to contain %q:\n%s", want, add)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "| [subtract](subtract.md) | `math.subtract` | subtracts \\| numbers |") {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	tt := []struct {
+		name     string
+		endpoint Endpoint
+		baseURL  string
+		nats     string
+		curl     string
+	}{
+		{
+			name:     "no payload or route",
+			endpoint: Endpoint{Name: "ping", Subject: "ping"},
+			nats:     "nats req ping ''",
+		},
+		{
+			name: "payload and headers",
+			endpoint: Endpoint{
+				Name:           "add",
+				Subject:        "math.*.add",
+				RequestExample: json.RawMessage(`{"a": 2, "note": "it's"}`),
+				Headers:        []Header{{Name: "Authorization", Required: true}, {Name: "Trace"}},
+				HTTPRoute:      "POST /api/{tenant}/add",
+			},
+			baseURL: "https://api.example.com/",
+			nats:    `nats req math.example.add -H 'Authorization: <authorization>' '{"a":2,"note":"it'\''s"}'`,
+			curl:    `curl -X POST 'https://api.example.com/api/example/add' -H 'Authorization: <authorization>' -H 'Content-Type: application/json' -d '{"a":2,"note":"it'\''s"}'`,
+		},
+		{
+			name: "get has no body",
+			endpoint: Endpoint{
+				Name:           "list",
+				Subject:        "items.>",
+				Format:         "application/x-ndjson",
+				RequestExample: json.RawMessage(`{}`),
+				HTTPRoute:      "GET /items",
+			},
+			nats: "nats req items.example '{}'",
+			curl: "curl -X GET 'http://localhost:8080/items'",
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			s := v.endpoint.Snippet(v.baseURL)
+			if s.NATS != v.nats {
+				t.Errorf("expected nats snippet\n%s\nbut got\n%s", v.nats, s.NATS)
+			}
+			if s.Curl != v.curl {
+				t.Errorf("expected curl snippet\n%s\nbut got\n%s", v.curl, s.Curl)
+			}
+		})
+	}
+}
+
+func TestPostman(t *testing.T) {
+	svc, err := FromInfo(testInfo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.BaseURL = "https://api.example.com/"
+
+	c := svc.Postman()
+	if c.Info.Name != "math 1.2.0" || c.Info.Schema != PostmanSchema {
+		t.Errorf("unexpected info %+v", c.Info)
+	}
+	if len(c.Item) != 1 {
+		t.Fatalf("expected only the endpoint with an HTTP route but got %+v", c.Item)
+	}
+
+	req := c.Item[0].Request
+	wantURL := PostmanURL{
+		Raw:      "{{baseUrl}}/api/:op",
+		Host:     []string{"{{baseUrl}}"},
+		Path:     []string{"api", ":op"},
+		Variable: []PostmanVariable{{Key: "op", Value: "example"}},
+	}
+	if req.Method != "POST" || !reflect.DeepEqual(req.URL, wantURL) {
+		t.Errorf("expected POST %+v but got %s %+v", wantURL, req.Method, req.URL)
+	}
+	wantHeaders := []PostmanHeader{
+		{Key: "Authorization", Value: "{{authorization}}", Description: "bearer token"},
+		{Key: "Content-Type", Value: "application/json"},
+	}
+	if !reflect.DeepEqual(req.Header, wantHeaders) {
+		t.Errorf("expected headers %+v but got %+v", wantHeaders, req.Header)
+	}
+	if req.Body == nil || req.Body.Raw != "{\n  \"a\": 2,\n  \"b\": 3\n}" {
+		t.Errorf("expected the example payload but got %+v", req.Body)
+	}
+
+	env := svc.PostmanEnvironment()
+	wantValues := []PostmanEnvironmentValue{
+		{Key: "baseUrl", Value: "https://api.example.com", Type: "default", Enabled: true},
+		{Key: "authorization", Type: "secret", Enabled: true},
+	}
+	if !reflect.DeepEqual(env.Values, wantValues) {
+		t.Errorf("expected %+v but got %+v", wantValues, env.Values)
+	}
+
+	files, err := WritePostman(t.TempDir(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || filepath.Base(files[0]) != "math.postman_collection.json" || filepath.Base(files[1]) != "math.postman_environment.json" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
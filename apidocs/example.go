@@ -0,0 +1,141 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// maxExampleDepth stops recursive schemas from generating examples forever
+const maxExampleDepth = 8
+
+// formatExamples are sample strings for the string formats jsonschema reflects
+var formatExamples = map[string]string{
+	"date-time": "2025-01-01T00:00:00Z",
+	"date":      "2025-01-01",
+	"time":      "12:00:00Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+}
+
+// Example returns a sample value matching the schema. The schema's own examples, default, const, or first
+// enum value are used when it has them. Otherwise every property gets a placeholder for its type.
+func Example(s *jsonschema.Schema) any {
+	if s == nil {
+		return nil
+	}
+	return example(s, s, 0)
+}
+
+func example(root, s *jsonschema.Schema, depth int) any {
+	if depth > maxExampleDepth {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(s.Ref, "#/$defs/"); ok {
+		def := root.Definitions[name]
+		if def == nil {
+			return nil
+		}
+		return example(root, def, depth+1)
+	}
+
+	switch {
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case s.Const != nil:
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return example(root, s.OneOf[0], depth+1)
+	case len(s.AnyOf) > 0:
+		return example(root, s.AnyOf[0], depth+1)
+	}
+
+	switch s.Type {
+	case "object":
+		m := map[string]any{}
+		if s.Properties != nil {
+			for p := s.Properties.Oldest(); p != nil; p = p.Next() {
+				m[p.Key] = example(root, p.Value, depth+1)
+			}
+		}
+		return m
+	case "array":
+		if s.Items == nil {
+			return []any{}
+		}
+		return []any{example(root, s.Items, depth+1)}
+	case "string":
+		if v, ok := formatExamples[s.Format]; ok {
+			return v
+		}
+		return "string"
+	case "integer", "number":
+		if s.Minimum != "" {
+			return s.Minimum
+		}
+		if s.ExclusiveMinimum != "" {
+			if n, err := s.ExclusiveMinimum.Int64(); err == nil {
+				return n + 1
+			}
+		}
+		return 0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// payload returns the example, or one generated from the schema, as indented JSON
+func payload(example json.RawMessage, s *jsonschema.Schema) []byte {
+	var v any
+	switch {
+	case len(example) > 0:
+		if json.Unmarshal(example, &v) != nil {
+			return example
+		}
+	case s != nil:
+		v = Example(s)
+	default:
+		return nil
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// RequestPayload returns the endpoint's request example, or one generated from its schema, as indented JSON.
+// It returns nil when the endpoint documents neither.
+func (e Endpoint) RequestPayload() []byte {
+	return payload(e.RequestExample, e.RequestSchema)
+}
+
+// ResponsePayload returns the endpoint's response example, or one generated from its schema, as indented JSON
+func (e Endpoint) ResponsePayload() []byte {
+	return payload(e.ResponseExample, e.ResponseSchema)
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// unsafeFileChars are replaced in endpoint names to make file names
+var unsafeFileChars = regexp.MustCompile(`[^-_a-zA-Z0-9]+`)
+
+// FileName returns the markdown file an endpoint is written to by WriteDir
+func FileName(e Endpoint) string {
+	return unsafeFileChars.ReplaceAllString(e.Name, "_") + ".md"
+}
+
+// escapeCell keeps a value from breaking a markdown table
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// WriteEndpoint writes an endpoint's markdown: its subject, headers, request and response examples with their
+// schemas, runnable nats and curl invocations, and error codes
+func WriteEndpoint(w io.Writer, svc Service, e Endpoint) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", e.Name)
+	if e.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", e.Description)
+	}
+
+	sb.WriteString("| | |\n| --- | --- |\n")
+	fmt.Fprintf(&sb, "| Service | %s %s |\n", escapeCell(svc.Name), escapeCell(svc.Version))
+	fmt.Fprintf(&sb, "| Subject | `%s` |\n", e.Subject)
+	if e.QueueGroup != "" {
+		fmt.Fprintf(&sb, "| Queue group | `%s` |\n", e.QueueGroup)
+	}
+	if e.Format != "" {
+		fmt.Fprintf(&sb, "| Format | `%s` |\n", e.Format)
+	}
+	if e.HTTPRoute != "" {
+		fmt.Fprintf(&sb, "| HTTP route | `%s` |\n", e.HTTPRoute)
+	}
+	for _, k := range sortedKeys(e.Metadata) {
+		fmt.Fprintf(&sb, "| %s | %s |\n", escapeCell(k), escapeCell(e.Metadata[k]))
+	}
+
+	if len(e.Headers) > 0 {
+		sb.WriteString("\n## Headers\n\n| Header | Required | Description |\n| --- | --- | --- |\n")
+		for _, h := range e.Headers {
+			required := ""
+			if h.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", h.Name, required, escapeCell(h.Description))
+		}
+	}
+
+	for _, section := range []struct {
+		title   string
+		payload []byte
+		schema  *jsonschema.Schema
+	}{
+		{title: "Request", payload: e.RequestPayload(), schema: e.RequestSchema},
+		{title: "Response", payload: e.ResponsePayload(), schema: e.ResponseSchema},
+	} {
+		if section.payload == nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n```json\n%s\n```\n", section.title, section.payload)
+		if section.schema == nil {
+			continue
+		}
+		if b, err := json.MarshalIndent(section.schema, "", "  "); err == nil {
+			fmt.Fprintf(&sb, "\n<details>\n<summary>Schema</summary>\n\n```json\n%s\n```\n\n</details>\n", b)
+		}
+	}
+
+	snippet := e.Snippet(svc.BaseURL)
+	sb.WriteString("\n## Examples\n\n```sh\n" + snippet.NATS + "\n")
+	if snippet.Curl != "" {
+		sb.WriteString(snippet.Curl + "\n")
+	}
+	sb.WriteString("```\n")
+
+	if len(e.Errors) > 0 {
+		sb.WriteString("\n## Errors\n\nErrors are returned with the code in the `Nats-Service-Error-Code` header.\n\n")
+		sb.WriteString("| Code | Description |\n| --- | --- |\n")
+		for _, ec := range e.Errors {
+			fmt.Fprintf(&sb, "| %s | %s |\n", escapeCell(ec.Code), escapeCell(ec.Description))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteIndex writes a markdown table of the service's endpoints linking to their files
+func WriteIndex(w io.Writer, svc Service) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", svc.Name)
+	if svc.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", svc.Description)
+	}
+	if svc.Version != "" {
+		fmt.Fprintf(&sb, "Version %s\n\n", svc.Version)
+	}
+	sb.WriteString("| Endpoint | Subject | Description |\n| --- | --- | --- |\n")
+	for _, e := range svc.Endpoints {
+		fmt.Fprintf(&sb, "| [%s](%s) | `%s` | %s |\n", e.Name, FileName(e), e.Subject, escapeCell(e.Description))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteDir writes a markdown file per endpoint and a README.md index to dir, returning the files written
+func WriteDir(dir string, svc Service) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	write := func(name string, f func(io.Writer) error) error {
+		var sb strings.Builder
+		if err := f(&sb); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	}
+
+	for _, e := range svc.Endpoints {
+		if err := write(FileName(e), func(w io.Writer) error { return WriteEndpoint(w, svc, e) }); err != nil {
+			return files, err
+		}
+	}
+	if err := write("README.md", func(w io.Writer) error { return WriteIndex(w, svc) }); err != nil {
+		return files, err
+	}
+
+	return files, nil
+}
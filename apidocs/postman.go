@@ -0,0 +1,241 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PostmanSchema is the collection format exported by Postman, which Insomnia also imports
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// BaseURLVariable is the environment variable holding the gateway URL in exported collections
+const BaseURLVariable = "baseUrl"
+
+var variableName = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// PostmanCollection is a Postman v2.1 collection
+type PostmanCollection struct {
+	Info PostmanInfo   `json:"info"`
+	Item []PostmanItem `json:"item"`
+}
+
+// PostmanInfo describes a Postman collection
+type PostmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// PostmanItem is a request in a Postman collection
+type PostmanItem struct {
+	Name    string         `json:"name"`
+	Request PostmanRequest `json:"request"`
+}
+
+// PostmanRequest is the HTTP request of a Postman item
+type PostmanRequest struct {
+	Method      string          `json:"method"`
+	Description string          `json:"description,omitempty"`
+	Header      []PostmanHeader `json:"header"`
+	URL         PostmanURL      `json:"url"`
+	Body        *PostmanBody    `json:"body,omitempty"`
+}
+
+// PostmanHeader is a request header
+type PostmanHeader struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// PostmanURL is a request URL. Path parameters are written :name and listed in Variable.
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanVariable is a path parameter
+type PostmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// PostmanBody is a raw request body
+type PostmanBody struct {
+	Mode    string         `json:"mode"`
+	Raw     string         `json:"raw"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// PostmanEnvironment is a Postman environment with the variables a collection uses
+type PostmanEnvironment struct {
+	Name   string                    `json:"name"`
+	Values []PostmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope"`
+}
+
+// PostmanEnvironmentValue is an environment variable. Type is default or secret.
+type PostmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// headerVariable returns the environment variable holding a header's value
+func headerVariable(name string) string {
+	return strings.Trim(strings.ToLower(variableName.ReplaceAllString(name, "_")), "_")
+}
+
+// postmanURL converts a gateway path into a Postman URL on the base URL variable
+func postmanURL(path string) PostmanURL {
+	u := PostmanURL{Host: []string{"{{" + BaseURLVariable + "}}"}}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+			segment = ":" + name
+			u.Variable = append(u.Variable, PostmanVariable{Key: name, Value: exampleToken})
+		}
+		u.Path = append(u.Path, segment)
+	}
+	u.Raw = strings.Join(u.Host, "")
+	if len(u.Path) > 0 {
+		u.Raw += "/" + strings.Join(u.Path, "/")
+	}
+	return u
+}
+
+// Postman returns a Postman collection with a request for every endpoint with an HTTP route. Endpoints only
+// reachable over NATS are left out. URLs use the baseUrl variable and headers use a variable named after the
+// header, both defined by PostmanEnvironment.
+func (svc Service) Postman() PostmanCollection {
+	c := PostmanCollection{
+		Info: PostmanInfo{Name: svc.Name, Description: svc.Description, Schema: PostmanSchema},
+		Item: []PostmanItem{},
+	}
+	if svc.Version != "" {
+		c.Info.Name = fmt.Sprintf("%s %s", svc.Name, svc.Version)
+	}
+
+	for _, e := range svc.Endpoints {
+		method, path, ok := strings.Cut(e.HTTPRoute, " ")
+		if !ok {
+			continue
+		}
+
+		req := PostmanRequest{
+			Method:      method,
+			Description: e.Description,
+			Header:      []PostmanHeader{},
+			URL:         postmanURL(path),
+		}
+		for _, h := range e.Headers {
+			req.Header = append(req.Header, PostmanHeader{
+				Key:         h.Name,
+				Value:       "{{" + headerVariable(h.Name) + "}}",
+				Description: h.Description,
+				Disabled:    !h.Required,
+			})
+		}
+		if payload := e.RequestPayload(); payload != nil && method != "GET" && method != "HEAD" {
+			format := e.Format
+			if format == "" {
+				format = "application/json"
+			}
+			req.Header = append(req.Header, PostmanHeader{Key: "Content-Type", Value: format})
+			req.Body = &PostmanBody{Mode: "raw", Raw: string(payload)}
+			if strings.Contains(format, "json") {
+				req.Body.Options = map[string]any{"raw": map[string]string{"language": "json"}}
+			}
+		}
+
+		c.Item = append(c.Item, PostmanItem{Name: e.Name, Request: req})
+	}
+
+	return c
+}
+
+// PostmanEnvironment returns the environment for the service's collection: the base URL and a secret, empty
+// variable for every documented header
+func (svc Service) PostmanEnvironment() PostmanEnvironment {
+	baseURL := svc.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	env := PostmanEnvironment{
+		Name:   svc.Name,
+		Values: []PostmanEnvironmentValue{{Key: BaseURLVariable, Value: strings.TrimSuffix(baseURL, "/"), Type: "default", Enabled: true}},
+		Scope:  "environment",
+	}
+
+	seen := map[string]bool{}
+	for _, e := range svc.Endpoints {
+		if e.HTTPRoute == "" {
+			continue
+		}
+		for _, h := range e.Headers {
+			key := headerVariable(h.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			env.Values = append(env.Values, PostmanEnvironmentValue{Key: key, Type: "secret", Enabled: true})
+		}
+	}
+
+	return env
+}
+
+// WritePostman writes the service's collection and environment to dir as <name>.postman_collection.json and
+// <name>.postman_environment.json, creating dir if needed. It returns the paths written.
+func WritePostman(dir string, svc Service) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{svc.Name + ".postman_collection.json", svc.Postman()},
+		{svc.Name + ".postman_environment.json", svc.PostmanEnvironment()},
+	}
+	var written []string
+	for _, f := range files {
+		b, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return written, err
+		}
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultBaseURL is where examples send HTTP requests when the service doesn't set BaseURL
+const DefaultBaseURL = "http://localhost:8080"
+
+// exampleToken replaces subject wildcards and path parameters in examples
+const exampleToken = "example"
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// Snippet is a runnable example invocation of an endpoint
+type Snippet struct {
+	Endpoint string `json:"endpoint"`
+	Subject  string `json:"subject"`
+	// NATS is a nats req command
+	NATS string `json:"nats"`
+	// Curl calls the endpoint's gateway route. It is empty when the endpoint has no HTTP route.
+	Curl string `json:"curl,omitempty"`
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// compactPayload returns the endpoint's request payload on one line
+func compactPayload(e Endpoint) string {
+	p := e.RequestPayload()
+	if p == nil {
+		return ""
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, p); err != nil {
+		return string(p)
+	}
+	return b.String()
+}
+
+// exampleSubject fills the subject's wildcards so it can be requested
+func exampleSubject(subject string) string {
+	tokens := strings.Split(subject, ".")
+	for i, t := range tokens {
+		if t == "*" || t == ">" {
+			tokens[i] = exampleToken
+		}
+	}
+	return strings.Join(tokens, ".")
+}
+
+// Snippet returns example invocations of the endpoint with its request payload and required headers. Subject
+// wildcards and path parameters are filled with "example". Header values are placeholders.
+func (e Endpoint) Snippet(baseURL string) Snippet {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	s := Snippet{Endpoint: e.Name, Subject: e.Subject}
+	payload := compactPayload(e)
+
+	var headers []string
+	for _, h := range e.Headers {
+		if h.Required {
+			headers = append(headers, fmt.Sprintf("%s: <%s>", h.Name, strings.ToLower(h.Name)))
+		}
+	}
+
+	args := []string{"nats", "req", exampleSubject(e.Subject)}
+	for _, h := range headers {
+		args = append(args, "-H", shellQuote(h))
+	}
+	args = append(args, shellQuote(payload))
+	s.NATS = strings.Join(args, " ")
+
+	if method, path, ok := strings.Cut(e.HTTPRoute, " "); ok {
+		url := strings.TrimSuffix(baseURL, "/") + pathParam.ReplaceAllString(path, exampleToken)
+		args := []string{"curl", "-X", method, shellQuote(url)}
+		for _, h := range headers {
+			args = append(args, "-H", shellQuote(h))
+		}
+		if payload != "" && method != "GET" && method != "HEAD" {
+			format := e.Format
+			if format == "" {
+				format = "application/json"
+			}
+			args = append(args, "-H", shellQuote("Content-Type: "+format), "-d", shellQuote(payload))
+		}
+		s.Curl = strings.Join(args, " ")
+	}
+
+	return s
+}
+
+// Snippets returns example invocations of every endpoint of the service
+func (svc Service) Snippets() []Snippet {
+	snippets := make([]Snippet, len(svc.Endpoints))
+	for i, e := range svc.Endpoints {
+		snippets[i] = e.Snippet(svc.BaseURL)
+	}
+	return snippets
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package app contains the process level policy for sencillo services: how they exit on fatal errors and
+// panics, and the cleanup that runs before they do
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	mu          sync.RWMutex
+	appLogger   *slog.Logger
+	exitTimeout = 10 * time.Second
+)
+
+// SetLogger sets the logger used for fatal errors, panics, and shutdown. slog.Default is used until it is set.
+func SetLogger(l *slog.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	appLogger = l
+}
+
+// SetExitTimeout sets how long Fatal waits for the shutdown hooks before exiting anyway
+func SetExitTimeout(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	exitTimeout = d
+}
+
+func logger() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	if appLogger == nil {
+		return slog.Default()
+	}
+
+	return appLogger
+}
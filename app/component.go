@@ -0,0 +1,335 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	ErrDuplicateComponent = fmt.Errorf("duplicate component")
+	ErrUnknownDependency  = fmt.Errorf("unknown dependency")
+	ErrDependencyCycle    = fmt.Errorf("dependency cycle")
+	ErrDependencyFailed   = fmt.Errorf("dependency failed to start")
+)
+
+// Component is a piece of a service, such as a database pool or NATS connection, that has to be ready before the
+// service starts taking requests
+type Component struct {
+	Name string
+	// DependsOn lists the components that must start before this one
+	DependsOn []string
+	// Start initializes the component. It should return once the component is ready. Its context stays valid
+	// after Start returns, until the context passed to App.Start is done, so it can be kept for background work.
+	Start func(ctx context.Context) error
+	// Stop is registered with OnShutdown at PriorityConnections once Start succeeds. Components stop in reverse
+	// order of starting, so a component stops before its dependencies.
+	Stop Hook
+}
+
+// App starts a service's components. Components without a dependency between them start concurrently.
+type App struct {
+	name         string
+	components   []Component
+	startTimeout time.Duration
+}
+
+type AppOpt func(*App)
+
+// SetStartTimeout bounds how long Start waits for all components. The default is 30 seconds.
+func SetStartTimeout(d time.Duration) AppOpt {
+	return func(a *App) {
+		a.startTimeout = d
+	}
+}
+
+// SetComponents adds components to the app
+func SetComponents(c ...Component) AppOpt {
+	return func(a *App) {
+		a.components = append(a.components, c...)
+	}
+}
+
+// New returns an App for the named service
+func New(name string, opts ...AppOpt) *App {
+	a := &App{
+		name:         name,
+		startTimeout: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Add adds components to the app
+func (a *App) Add(c ...Component) {
+	a.components = append(a.components, c...)
+}
+
+// ComponentTiming is how one component spent its startup
+type ComponentTiming struct {
+	Name string
+	// Waited is how long the component waited for its dependencies
+	Waited time.Duration
+	// Duration is how long Start took
+	Duration time.Duration
+	// Finished is when Start returned, relative to the start of the app
+	Finished time.Duration
+	Err      error
+}
+
+// StartupReport is the timing breakdown of App.Start
+type StartupReport struct {
+	Total      time.Duration
+	Components []ComponentTiming
+	// CriticalPath is the chain of components that determined Total
+	CriticalPath []string
+}
+
+// String formats the report as a table in the order the components finished
+func (r StartupReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tWAITED\tSTART\tFINISHED\tERROR")
+	for _, c := range r.Components {
+		errText := ""
+		if c.Err != nil {
+			errText = c.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Name, c.Waited.Round(time.Millisecond),
+			c.Duration.Round(time.Millisecond), c.Finished.Round(time.Millisecond), errText)
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "total %s, critical path %s\n", r.Total.Round(time.Millisecond), strings.Join(r.CriticalPath, " -> "))
+
+	return b.String()
+}
+
+// Validate checks that component names are unique, that every dependency exists, and that there are no cycles
+func (a *App) Validate() error {
+	byName := make(map[string]Component, len(a.components))
+	var errs []error
+	for _, c := range a.components {
+		if _, ok := byName[c.Name]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateComponent, c.Name))
+			continue
+		}
+		byName[c.Name] = c
+	}
+	for _, c := range a.components {
+		for _, dep := range c.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				errs = append(errs, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, c.Name, dep))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// depth first search, reporting the first cycle found
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(byName))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			i := slices.Index(path, name)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path[i:], name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, c := range a.components {
+		if err := visit(c.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts every component once its dependencies have started, running independent components concurrently.
+// If a component fails or the start timeout passes, the context passed to components still starting is cancelled
+// and components that depend on it aren't started. Components that have started keep a context derived from ctx,
+// so pass a context that lives as long as the service. The report is returned even when Start fails so the slow
+// or failing component can be found.
+func (a *App) Start(ctx context.Context) (StartupReport, error) {
+	if err := a.Validate(); err != nil {
+		return StartupReport{}, err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, a.startTimeout)
+	defer cancel()
+
+	type result struct {
+		done chan struct{}
+		err  error
+	}
+	results := make(map[string]*result, len(a.components))
+	for _, c := range a.components {
+		results[c.Name] = &result{done: make(chan struct{})}
+	}
+
+	var (
+		mu      sync.Mutex
+		timings = make(map[string]ComponentTiming, len(a.components))
+		wg      sync.WaitGroup
+	)
+	begin := time.Now()
+	for _, c := range a.components {
+		wg.Add(1)
+		go func(c Component) {
+			defer wg.Done()
+			res := results[c.Name]
+			defer close(res.done)
+
+			waitStart := time.Now()
+			for _, dep := range c.DependsOn {
+				<-results[dep].done
+				if results[dep].err != nil && res.err == nil {
+					res.err = fmt.Errorf("%w: %s", ErrDependencyFailed, dep)
+				}
+			}
+			timing := ComponentTiming{Name: c.Name, Waited: time.Since(waitStart)}
+
+			if res.err == nil {
+				startedAt := time.Now()
+				res.err = a.startComponent(ctx, startCtx, c)
+				timing.Duration = time.Since(startedAt)
+				if res.err != nil {
+					cancel()
+				}
+			}
+			timing.Finished = time.Since(begin)
+			timing.Err = res.err
+
+			mu.Lock()
+			defer mu.Unlock()
+			timings[c.Name] = timing
+			if res.err == nil && c.Stop != nil {
+				// registered under the lock so components stop in reverse order of finishing
+				OnShutdown(c.Name, PriorityConnections, c.Stop)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	report := StartupReport{Total: time.Since(begin)}
+	var errs []error
+	for _, c := range a.components {
+		t := timings[c.Name]
+		report.Components = append(report.Components, t)
+		if t.Err != nil && !errors.Is(t.Err, ErrDependencyFailed) {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, t.Err))
+		}
+	}
+	slices.SortStableFunc(report.Components, func(x, y ComponentTiming) int {
+		return cmp.Compare(x.Finished, y.Finished)
+	})
+	report.CriticalPath = a.criticalPath(timings)
+
+	for _, t := range report.Components {
+		attrs := []any{"app", a.name, "component", t.Name, "waited", t.Waited, "duration", t.Duration}
+		if t.Err != nil {
+			logger().Error("component failed to start", append(attrs, "error", t.Err)...)
+			continue
+		}
+		logger().Info("component started", attrs...)
+	}
+	logger().Info("startup finished", "app", a.name, "duration", report.Total,
+		"critical_path", strings.Join(report.CriticalPath, " -> "))
+
+	return report, errors.Join(errs...)
+}
+
+// startComponent runs the component's Start with a context derived from ctx that is also cancelled when startCtx
+// is done, until Start returns successfully
+func (a *App) startComponent(ctx, startCtx context.Context, c Component) (err error) {
+	if c.Start == nil {
+		return nil
+	}
+
+	componentCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(startCtx, cancel)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if !stop() || err != nil {
+			cancel()
+		}
+	}()
+
+	return c.Start(componentCtx)
+}
+
+// criticalPath follows the dependency that finished last back from the component that finished last
+func (a *App) criticalPath(timings map[string]ComponentTiming) []string {
+	byName := make(map[string]Component, len(a.components))
+	var last string
+	for _, c := range a.components {
+		byName[c.Name] = c
+		if last == "" || timings[c.Name].Finished > timings[last].Finished {
+			last = c.Name
+		}
+	}
+	if last == "" {
+		return nil
+	}
+
+	path := []string{last}
+	for {
+		deps := byName[path[len(path)-1]].DependsOn
+		if len(deps) == 0 {
+			break
+		}
+		next := deps[0]
+		for _, dep := range deps[1:] {
+			if timings[dep].Finished > timings[next].Finished {
+				next = dep
+			}
+		}
+		path = append(path, next)
+	}
+	slices.Reverse(path)
+
+	return path
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	tt := []struct {
+		name       string
+		components []Component
+		want       error
+	}{
+		{
+			name: "valid",
+			components: []Component{
+				{Name: "nats", Start: noop},
+				{Name: "db", Start: noop},
+				{Name: "cache", DependsOn: []string{"db", "nats"}, Start: noop},
+			},
+		},
+		{
+			name:       "duplicate",
+			components: []Component{{Name: "db"}, {Name: "db"}},
+			want:       ErrDuplicateComponent,
+		},
+		{
+			name:       "unknown",
+			components: []Component{{Name: "cache", DependsOn: []string{"db"}}},
+			want:       ErrUnknownDependency,
+		},
+		{
+			name: "cycle",
+			components: []Component{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+			want: ErrDependencyCycle,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			err := New("test", SetComponents(v.components...)).Validate()
+			if !errors.Is(err, v.want) {
+				t.Errorf("expected %v but got %v", v.want, err)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	capture(t)
+
+	var (
+		mu      sync.Mutex
+		started []string
+		stopped []string
+	)
+	component := func(name string, d time.Duration, deps ...string) Component {
+		return Component{
+			Name:      name,
+			DependsOn: deps,
+			Start: func(ctx context.Context) error {
+				time.Sleep(d)
+				mu.Lock()
+				defer mu.Unlock()
+				started = append(started, name)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				stopped = append(stopped, name)
+				return nil
+			},
+		}
+	}
+
+	a := New("orders", SetComponents(
+		component("db", 50*time.Millisecond),
+		component("nats", 50*time.Millisecond),
+		component("cache", 10*time.Millisecond, "db"),
+		component("http", 0, "cache", "nats"),
+	))
+	report, err := a.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Total >= 100*time.Millisecond {
+		t.Errorf("expected db and nats to start concurrently but startup took %s", report.Total)
+	}
+	if started[len(started)-1] != "http" || started[len(started)-2] != "cache" {
+		t.Errorf("expected dependencies to start first but got %v", started)
+	}
+	if want := []string{"db", "cache", "http"}; !reflect.DeepEqual(report.CriticalPath, want) {
+		t.Errorf("expected critical path %v but got %v", want, report.CriticalPath)
+	}
+	if !strings.Contains(report.String(), "critical path db -> cache -> http") {
+		t.Errorf("expected the critical path in the report\n%s", report)
+	}
+
+	Shutdown(context.Background())
+	if stopped[0] != "http" || stopped[len(stopped)-1] == "http" {
+		t.Errorf("expected dependents to stop first but got %v", stopped)
+	}
+}
+
+func TestStartFailure(t *testing.T) {
+	_, buf := capture(t)
+
+	var cacheStarted, stopped bool
+	a := New("orders", SetComponents(
+		Component{
+			Name:  "db",
+			Start: func(ctx context.Context) error { return fmt.Errorf("connection refused") },
+		},
+		Component{
+			Name: "nats",
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			Stop: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			},
+		},
+		Component{
+			Name:      "cache",
+			DependsOn: []string{"db"},
+			Start: func(ctx context.Context) error {
+				cacheStarted = true
+				return nil
+			},
+		},
+	))
+
+	report, err := a.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "db: connection refused") {
+		t.Errorf("expected the db error but got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the other components to be cancelled but got %v", err)
+	}
+	if cacheStarted {
+		t.Error("expected cache not to start without db")
+	}
+	for _, c := range report.Components {
+		if c.Name == "cache" && !errors.Is(c.Err, ErrDependencyFailed) {
+			t.Errorf("expected cache to report %v but got %v", ErrDependencyFailed, c.Err)
+		}
+	}
+	if !strings.Contains(buf.String(), `"component":"db"`) {
+		t.Errorf("expected the failure to be logged\n%s", buf.String())
+	}
+
+	Shutdown(context.Background())
+	if stopped {
+		t.Error("expected components that failed to start not to be stopped")
+	}
+}
+
+func TestStartContext(t *testing.T) {
+	capture(t)
+
+	var started context.Context
+	a := New("orders", SetStartTimeout(10*time.Millisecond), SetComponents(Component{
+		Name: "consumer",
+		Start: func(ctx context.Context) error {
+			started = ctx
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := a.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if started.Err() != nil {
+		t.Errorf("expected the component's context to outlive Start and the timeout but got %v", started.Err())
+	}
+
+	cancel()
+	if started.Err() == nil {
+		t.Error("expected the component's context to end with the app's")
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+)
+
+// Exit codes follow the BSD sysexits convention so orchestrators can tell a bad config from a crash
+const (
+	ExitOK         = 0
+	ExitRuntime    = 1
+	ExitUsage      = 64
+	ExitDependency = 69
+	ExitPanic      = 70
+	ExitConfig     = 78
+)
+
+// exit is replaced in tests
+var exit = os.Exit
+
+// ExitError is an error classified with the code the process exits with
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError marks err as a configuration problem that restarting won't fix
+func ConfigError(err error) error {
+	return classify(ExitConfig, err)
+}
+
+// DependencyError marks err as a dependency, such as NATS or a database, being unavailable
+func DependencyError(err error) error {
+	return classify(ExitDependency, err)
+}
+
+// UsageError marks err as invalid command line arguments
+func UsageError(err error) error {
+	return classify(ExitUsage, err)
+}
+
+func classify(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the code the process should exit with for err. Unclassified errors are runtime errors.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var e *ExitError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return ExitRuntime
+}
+
+func className(code int) string {
+	switch code {
+	case ExitUsage:
+		return "usage"
+	case ExitDependency:
+		return "dependency"
+	case ExitPanic:
+		return "panic"
+	case ExitConfig:
+		return "config"
+	}
+
+	return "runtime"
+}
+
+// Fatal logs err, runs the shutdown hooks so logs and traces are flushed, and exits with the code for err's
+// class. It replaces os.Exit(1) and log.Fatal, which skip all cleanup.
+func Fatal(err error) {
+	fatal(err)
+}
+
+func fatal(err error, attrs ...any) {
+	code := ExitCode(err)
+	attrs = append([]any{"error", err, "class", className(code), "exit_code", code}, attrs...)
+	logger().Error("fatal error", attrs...)
+
+	mu.RLock()
+	timeout := exitTimeout
+	mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if err := Shutdown(ctx); err != nil {
+		logger().Error("shutdown hooks failed", "error", err)
+	}
+	cancel()
+
+	exit(code)
+}
+
+// Run calls main with the exit policy applied: a panic or error exits through Fatal, and a successful return runs
+// the shutdown hooks before Run returns
+//
+//	func main() {
+//		app.Run(cmd.Execute)
+//	}
+func Run(main func() error) {
+	defer Recover()
+	if err := main(); err != nil {
+		Fatal(err)
+		return
+	}
+
+	mu.RLock()
+	timeout := exitTimeout
+	mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := Shutdown(ctx); err != nil {
+		logger().Error("shutdown hooks failed", "error", err)
+	}
+}
+
+// Recover exits with ExitPanic after logging the panic with its stack and running the shutdown hooks. Defer it
+// at the top of main. A panic in another goroutine can't be recovered from main, so start goroutines with Go.
+func Recover() {
+	if r := recover(); r != nil {
+		fatal(&ExitError{Code: ExitPanic, Err: fmt.Errorf("panic: %v", r)}, "stack", string(debug.Stack()))
+	}
+}
+
+// Go runs fn in a goroutine that exits the process like Recover if fn panics
+func Go(fn func()) {
+	go func() {
+		defer Recover()
+		fn()
+	}()
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", want: ExitOK},
+		{name: "unclassified", err: fmt.Errorf("boom"), want: ExitRuntime},
+		{name: "config", err: ConfigError(fmt.Errorf("bad port")), want: ExitConfig},
+		{name: "wrapped", err: fmt.Errorf("starting: %w", DependencyError(fmt.Errorf("no nats"))), want: ExitDependency},
+		{name: "usage", err: UsageError(fmt.Errorf("unknown flag")), want: ExitUsage},
+		{name: "classified nil", err: ConfigError(nil), want: ExitOK},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := ExitCode(v.err); got != v.want {
+				t.Errorf("expected %d but got %d", v.want, got)
+			}
+		})
+	}
+}
+
+// capture stubs out os.Exit and the logger, returning the exit code and log output
+func capture(t *testing.T) (*int, *bytes.Buffer) {
+	t.Helper()
+	code := -1
+	var buf bytes.Buffer
+
+	exit = func(c int) { code = c }
+	SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		exit = osExit
+		SetLogger(nil)
+		Shutdown(context.Background())
+	})
+
+	return &code, &buf
+}
+
+var osExit = exit
+
+func TestFatal(t *testing.T) {
+	code, buf := capture(t)
+
+	var ran []string
+	OnShutdown("tracer", PriorityTelemetry, func(ctx context.Context) error {
+		ran = append(ran, "tracer")
+		return nil
+	})
+	OnShutdown("server", PriorityServers, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected hooks to get a deadline")
+		}
+		ran = append(ran, "server")
+		return fmt.Errorf("still busy")
+	})
+
+	Fatal(ConfigError(fmt.Errorf("missing NATS url")))
+
+	if *code != ExitConfig {
+		t.Errorf("expected exit code %d but got %d", ExitConfig, *code)
+	}
+	if !reflect.DeepEqual(ran, []string{"server", "tracer"}) {
+		t.Errorf("expected hooks in priority order but got %v", ran)
+	}
+	for _, want := range []string{`"class":"config"`, `"exit_code":78`, "server: still busy"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected log to contain %s\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	code, buf := capture(t)
+
+	func() {
+		defer Recover()
+		panic("nil map")
+	}()
+
+	if *code != ExitPanic {
+		t.Errorf("expected exit code %d but got %d", ExitPanic, *code)
+	}
+	if !strings.Contains(buf.String(), "panic: nil map") || !strings.Contains(buf.String(), `"stack":"goroutine`) {
+		t.Errorf("expected the panic and stack to be logged\n%s", buf.String())
+	}
+}
+
+func TestGo(t *testing.T) {
+	capture(t)
+	codes := make(chan int, 1)
+	exit = func(c int) { codes <- c }
+
+	Go(func() { panic("worker") })
+
+	if code := <-codes; code != ExitPanic {
+		t.Errorf("expected exit code %d but got %d", ExitPanic, code)
+	}
+}
+
+func TestShutdownRunsOnce(t *testing.T) {
+	var calls int
+	OnShutdown("once", PriorityServers, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	Shutdown(context.Background())
+	Shutdown(context.Background())
+	if calls != 1 {
+		t.Errorf("expected the hook to run once but ran %d times", calls)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tt := []struct {
+		name string
+		main func() error
+		want int
+	}{
+		{name: "success", main: func() error { return nil }, want: -1},
+		{name: "error", main: func() error { return DependencyError(fmt.Errorf("nats unavailable")) }, want: ExitDependency},
+		{name: "panic", main: func() error { panic("boom") }, want: ExitPanic},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			code, _ := capture(t)
+			var flushed bool
+			OnShutdown("flush", PriorityConnections, func(ctx context.Context) error {
+				flushed = true
+				return nil
+			})
+
+			Run(v.main)
+
+			if *code != v.want {
+				t.Errorf("expected exit code %d but got %d", v.want, *code)
+			}
+			if !flushed {
+				t.Error("expected the shutdown hooks to run")
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Priorities for the usual subsystems. Hooks with a lower priority run first, so servers stop taking requests
+// before the connections and exporters they depend on are closed.
+const (
+	PriorityServers     = 100
+	PriorityWorkers     = 200
+	PriorityConnections = 300
+	PriorityTelemetry   = 400
+)
+
+// DefaultHookTimeout is how long a hook may run before Shutdown moves on to the next one
+const DefaultHookTimeout = 5 * time.Second
+
+// ErrHookTimeout is returned for a hook that didn't finish within its timeout
+var ErrHookTimeout = fmt.Errorf("shutdown hook timed out")
+
+// Hook cleans up a subsystem before the process exits. An *http.Server's Shutdown method is a Hook.
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name     string
+	priority int
+	timeout  time.Duration
+	fn       Hook
+}
+
+type HookOpt func(*hook)
+
+// SetHookTimeout overrides DefaultHookTimeout for a hook. The hook's context is also cancelled when the context
+// passed to Shutdown is.
+func SetHookTimeout(d time.Duration) HookOpt {
+	return func(h *hook) {
+		h.timeout = d
+	}
+}
+
+var hooks []hook
+
+// OnShutdown registers a hook run by Shutdown and Fatal. Hooks run in order of priority, lowest first. Hooks with
+// the same priority run in reverse order of registration, like defer.
+//
+//	app.OnShutdown("http", app.PriorityServers, srv.Shutdown)
+//	app.OnShutdown("nats", app.PriorityConnections, app.DrainNATS(nc), app.SetHookTimeout(8*time.Second))
+func OnShutdown(name string, priority int, fn Hook, opts ...HookOpt) {
+	h := hook{
+		name:     name,
+		priority: priority,
+		timeout:  DefaultHookTimeout,
+		fn:       fn,
+	}
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// Shutdown runs the registered hooks once and returns their errors joined. Each hook's duration is logged. A hook
+// that doesn't return within its timeout is abandoned so the remaining hooks still run. Hooks registered after
+// Shutdown starts aren't run.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	pending := slices.Clone(hooks)
+	hooks = nil
+	mu.Unlock()
+
+	slices.Reverse(pending)
+	slices.SortStableFunc(pending, func(a, b hook) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	var errs []error
+	for _, h := range pending {
+		start := time.Now()
+		err := runHook(ctx, h)
+		attrs := []any{"hook", h.name, "priority", h.priority, "duration", time.Since(start)}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			logger().Error("shutdown hook failed", append(attrs, "error", err)...)
+			continue
+		}
+		logger().Info("shutdown hook finished", attrs...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func runHook(ctx context.Context, h hook) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrHookTimeout, ctx.Err())
+	}
+}
+
+// TracerProvider is implemented by the OpenTelemetry SDK's tracer provider
+type TracerProvider interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// FlushTracer returns a hook that exports buffered spans and shuts down the provider
+func FlushTracer(tp TracerProvider) Hook {
+	return func(ctx context.Context) error {
+		if err := tp.ForceFlush(ctx); err != nil {
+			return err
+		}
+		return tp.Shutdown(ctx)
+	}
+}
+
+// FlushNATS returns a hook that waits for the server to receive buffered messages, such as logs sent with
+// NatsLogger, and closes the connection
+func FlushNATS(nc *nats.Conn) Hook {
+	return func(ctx context.Context) error {
+		defer nc.Close()
+		if nc.IsClosed() {
+			return nil
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			return nc.Flush()
+		}
+		return nc.FlushWithContext(ctx)
+	}
+}
+
+// DrainNATS returns a hook that drains the connection's subscriptions, so in flight requests are answered before
+// it closes, and waits for the connection to close
+func DrainNATS(nc *nats.Conn) Hook {
+	return func(ctx context.Context) error {
+		if nc.IsClosed() {
+			return nil
+		}
+
+		closed := nc.StatusChanged(nats.CLOSED)
+		if err := nc.Drain(); err != nil {
+			return err
+		}
+
+		select {
+		case <-closed:
+			return nil
+		case <-ctx.Done():
+			nc.Close()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	capture(t)
+
+	var ran []string
+	record := func(name string) Hook {
+		return func(ctx context.Context) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+	OnShutdown("exporter", PriorityTelemetry, record("exporter"))
+	OnShutdown("nats", PriorityConnections, record("nats"))
+	OnShutdown("http", PriorityServers, record("http"))
+	OnShutdown("jobs", PriorityWorkers, record("jobs"))
+	OnShutdown("grpc", PriorityServers, record("grpc"))
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"grpc", "http", "jobs", "nats", "exporter"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("expected %v but got %v", want, ran)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	_, buf := capture(t)
+
+	var ran bool
+	OnShutdown("stuck", PriorityServers, func(ctx context.Context) error {
+		select {}
+	}, SetHookTimeout(10*time.Millisecond))
+	OnShutdown("slow", PriorityWorkers, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, SetHookTimeout(10*time.Millisecond))
+	OnShutdown("after", PriorityTelemetry, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+
+	err := Shutdown(context.Background())
+	if !errors.Is(err, ErrHookTimeout) {
+		t.Errorf("expected %v but got %v", ErrHookTimeout, err)
+	}
+	if !strings.Contains(err.Error(), "stuck") {
+		t.Errorf("expected the stuck hook to be named in %v", err)
+	}
+	if !ran {
+		t.Error("expected hooks after a timed out hook to run")
+	}
+	for _, want := range []string{`"hook":"stuck"`, `"hook":"after"`, `"duration":`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected log to contain %s\n%s", want, buf.String())
+		}
+	}
+}
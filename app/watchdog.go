@@ -0,0 +1,234 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrStalled = fmt.Errorf("component stalled")
+
+// Heartbeat is beaten by a component's loop on every iteration, such as after each fetch of a pull consumer
+type Heartbeat struct {
+	name    string
+	timeout time.Duration
+	restart func(context.Context) error
+	last    atomic.Int64
+	stalled atomic.Bool
+}
+
+// Beat records that the component made progress
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// HeartbeatOpt is a functional option to modify a Heartbeat
+type HeartbeatOpt func(*Heartbeat)
+
+// SetRestart sets a func the watchdog calls to restart the component once it stalls, such as one recreating a
+// consumer's subscription. Without it, a stall only reports unless the watchdog exits the process.
+func SetRestart(fn func(context.Context) error) HeartbeatOpt {
+	return func(h *Heartbeat) {
+		h.restart = fn
+	}
+}
+
+// WatchdogOpt is a functional option to modify the Watchdog
+type WatchdogOpt func(*Watchdog)
+
+// Watchdog detects components whose loops stopped beating. A stalled component has the goroutines dumped so the
+// deadlock can be found, a metric incremented, and is restarted if it can be.
+type Watchdog struct {
+	interval time.Duration
+	dump     io.Writer
+	exit     bool
+	logger   *slog.Logger
+	now      func() time.Time
+	fatal    func(error)
+
+	stalls  *prometheus.CounterVec
+	stalled *prometheus.GaugeVec
+
+	mu         sync.Mutex
+	heartbeats map[string]*Heartbeat
+}
+
+// SetWatchdogInterval sets how often heartbeats are checked. The default is 5 seconds.
+func SetWatchdogInterval(d time.Duration) WatchdogOpt {
+	return func(w *Watchdog) {
+		w.interval = d
+	}
+}
+
+// SetGoroutineDump sets where goroutine stacks are written on a stall. The default is stderr and nil disables
+// the dump.
+func SetGoroutineDump(out io.Writer) WatchdogOpt {
+	return func(w *Watchdog) {
+		w.dump = out
+	}
+}
+
+// SetExitOnStall exits the process through Fatal when a component without a restart func stalls, or its restart
+// fails, so the orchestrator restarts the service
+func SetExitOnStall(exit bool) WatchdogOpt {
+	return func(w *Watchdog) {
+		w.exit = exit
+	}
+}
+
+// SetWatchdogLogger sets the logger used to report stalls
+func SetWatchdogLogger(l *slog.Logger) WatchdogOpt {
+	return func(w *Watchdog) {
+		w.logger = l
+	}
+}
+
+// NewWatchdog returns a Watchdog
+func NewWatchdog(opts ...WatchdogOpt) *Watchdog {
+	w := &Watchdog{
+		interval:   5 * time.Second,
+		dump:       os.Stderr,
+		logger:     logger(),
+		now:        time.Now,
+		fatal:      Fatal,
+		heartbeats: make(map[string]*Heartbeat),
+		stalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "watchdog_stalls",
+			Help: "Times a component stopped beating for longer than its timeout",
+		}, []string{"component"}),
+		stalled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "watchdog_stalled",
+			Help: "Whether a component is stalled",
+		}, []string{"component"}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Metrics returns the watchdog's collectors to register with the exporter
+func (w *Watchdog) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{w.stalls, w.stalled}
+}
+
+// Register returns the heartbeat of a component that stalls when it doesn't beat for timeout. Registering counts
+// as the first beat, so a component that never starts its loop is caught too. The timeout should cover the
+// longest an iteration can take, such as a fetch's max wait plus handling the batch.
+func (w *Watchdog) Register(name string, timeout time.Duration, opts ...HeartbeatOpt) *Heartbeat {
+	h := &Heartbeat{name: name, timeout: timeout}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.last.Store(w.now().UnixNano())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.heartbeats[name] = h
+	w.stalled.WithLabelValues(name).Set(0)
+
+	return h
+}
+
+// Unregister stops watching a component, such as one that stopped on purpose
+func (w *Watchdog) Unregister(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.heartbeats, name)
+	w.stalled.DeleteLabelValues(name)
+}
+
+// Run checks the heartbeats on the interval until ctx is done
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.check(ctx)
+	}
+}
+
+// check handles every heartbeat that went past its timeout since the last check, and clears ones beating again
+func (w *Watchdog) check(ctx context.Context) {
+	w.mu.Lock()
+	heartbeats := make([]*Heartbeat, 0, len(w.heartbeats))
+	for _, h := range w.heartbeats {
+		heartbeats = append(heartbeats, h)
+	}
+	w.mu.Unlock()
+	sort.Slice(heartbeats, func(i, j int) bool { return heartbeats[i].name < heartbeats[j].name })
+
+	now := w.now()
+	dumped := false
+	for _, h := range heartbeats {
+		since := now.Sub(time.Unix(0, h.last.Load()))
+		if since < h.timeout {
+			if h.stalled.CompareAndSwap(true, false) {
+				w.stalled.WithLabelValues(h.name).Set(0)
+				w.logger.Info(fmt.Sprintf("component %s recovered", h.name))
+			}
+			continue
+		}
+		if !h.stalled.CompareAndSwap(false, true) {
+			continue
+		}
+
+		w.stalls.WithLabelValues(h.name).Inc()
+		w.stalled.WithLabelValues(h.name).Set(1)
+		w.logger.Error(fmt.Sprintf("component %s stalled: no heartbeat for %s", h.name, since.Round(time.Millisecond)))
+		if !dumped && w.dump != nil {
+			dumped = true
+			pprof.Lookup("goroutine").WriteTo(w.dump, 2)
+		}
+		w.recover(ctx, h)
+	}
+}
+
+// recover restarts a stalled component, exiting the process when that isn't possible and SetExitOnStall is set
+func (w *Watchdog) recover(ctx context.Context, h *Heartbeat) {
+	err := fmt.Errorf("%w: %s", ErrStalled, h.name)
+	if h.restart != nil {
+		restartErr := h.restart(ctx)
+		if restartErr == nil {
+			w.logger.Info(fmt.Sprintf("component %s restarted", h.name))
+			h.Beat()
+			return
+		}
+		err = fmt.Errorf("%w: restart failed: %v", err, restartErr)
+		w.logger.Error(err.Error())
+	}
+
+	if w.exit {
+		w.fatal(err)
+	}
+}
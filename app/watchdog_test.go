@@ -0,0 +1,72 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestWatchdog(t *testing.T) {
+	var dump bytes.Buffer
+	var fatal []error
+	w := NewWatchdog(SetGoroutineDump(&dump), SetExitOnStall(true))
+	w.fatal = func(err error) { fatal = append(fatal, err) }
+	reg := sdtest.NewRegistry(t, w.Metrics()...)
+
+	var restarts int
+	healthy := w.Register("healthy", time.Minute)
+	restartable := w.Register("consumer", time.Minute, SetRestart(func(context.Context) error {
+		restarts++
+		return nil
+	}))
+	stuck := w.Register("worker", time.Minute)
+
+	stale := time.Now().Add(-2 * time.Minute).UnixNano()
+	restartable.last.Store(stale)
+	stuck.last.Store(stale)
+	healthy.Beat()
+
+	ctx := context.Background()
+	w.check(ctx)
+	w.check(ctx)
+
+	if restarts != 1 {
+		t.Errorf("expected the consumer to be restarted once but got %d", restarts)
+	}
+	if len(fatal) != 1 || !errors.Is(fatal[0], ErrStalled) || !strings.Contains(fatal[0].Error(), "worker") {
+		t.Errorf("expected one fatal error for the worker but got %v", fatal)
+	}
+	if !strings.Contains(dump.String(), "goroutine") {
+		t.Error("expected the goroutines to be dumped")
+	}
+
+	reg.AssertCounter(t, "watchdog_stalls", prometheus.Labels{"component": "consumer"}, 1)
+	reg.AssertCounter(t, "watchdog_stalls", prometheus.Labels{"component": "worker"}, 1)
+	reg.AssertGauge(t, "watchdog_stalled", prometheus.Labels{"component": "consumer"}, 0)
+	reg.AssertGauge(t, "watchdog_stalled", prometheus.Labels{"component": "worker"}, 1)
+	reg.AssertGauge(t, "watchdog_stalled", prometheus.Labels{"component": "healthy"}, 0)
+
+	stuck.Beat()
+	w.check(ctx)
+	reg.AssertGauge(t, "watchdog_stalled", prometheus.Labels{"component": "worker"}, 0)
+}
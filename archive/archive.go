@@ -0,0 +1,307 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive copies messages from JetStream streams into an object store bucket so they can be kept longer
+// than the stream's retention, and reads them back for replay or analytics export. Messages are written in
+// batches of gzipped NDJSON objects partitioned by stream, date, and subject.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	contentType     = "application/x-ndjson"
+	contentEncoding = "gzip"
+	dateLayout      = "2006-01-02"
+)
+
+var ErrInvalidObjectName = fmt.Errorf("not an archive object name")
+
+// ObjectStore is the subset of nats.ObjectStore used by the archive
+type ObjectStore interface {
+	Put(obj *nats.ObjectMeta, reader io.Reader, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error)
+	Get(name string, opts ...nats.GetObjectOpt) (nats.ObjectResult, error)
+	List(opts ...nats.ListObjectsOpt) ([]*nats.ObjectInfo, error)
+}
+
+// Record is an archived message
+type Record struct {
+	Stream   string      `json:"stream"`
+	Sequence uint64      `json:"seq"`
+	Subject  string      `json:"subject"`
+	Time     time.Time   `json:"time"`
+	Header   nats.Header `json:"header,omitempty"`
+	Data     []byte      `json:"data"`
+}
+
+// Msg returns the record as a message that can be published again
+func (r Record) Msg() *nats.Msg {
+	msg := nats.NewMsg(r.Subject)
+	msg.Data = r.Data
+	for k, v := range r.Header {
+		msg.Header[k] = append([]string(nil), v...)
+	}
+
+	return msg
+}
+
+// Partition is the part of an object name that groups records
+type Partition struct {
+	Stream string
+	Date   time.Time
+	// Subject is the leading tokens of the subjects in the object, up to the sink's subject depth
+	Subject string
+}
+
+// objectName names an object so that sorting the names orders a partition's objects by sequence. The subject
+// is escaped, since subjects can contain the slashes separating the name's parts.
+func objectName(p Partition, first, last uint64) string {
+	return fmt.Sprintf("%s/date=%s/subject=%s/%020d-%020d.ndjson.gz", p.Stream, p.Date.Format(dateLayout), url.PathEscape(p.Subject), first, last)
+}
+
+// parseObjectName returns the partition and sequence range of an object written by a Sink
+func parseObjectName(name string) (Partition, uint64, uint64, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 4 || !strings.HasPrefix(parts[1], "date=") || !strings.HasPrefix(parts[2], "subject=") {
+		return Partition{}, 0, 0, fmt.Errorf("%w: %s", ErrInvalidObjectName, name)
+	}
+
+	date, err := time.Parse(dateLayout, strings.TrimPrefix(parts[1], "date="))
+	if err != nil {
+		return Partition{}, 0, 0, fmt.Errorf("%w: %s", ErrInvalidObjectName, name)
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSuffix(parts[3], ".ndjson.gz"), "-")
+	if !ok {
+		return Partition{}, 0, 0, fmt.Errorf("%w: %s", ErrInvalidObjectName, name)
+	}
+	firstSeq, err1 := strconv.ParseUint(first, 10, 64)
+	lastSeq, err2 := strconv.ParseUint(last, 10, 64)
+	if err := errors.Join(err1, err2); err != nil {
+		return Partition{}, 0, 0, fmt.Errorf("%w: %s", ErrInvalidObjectName, name)
+	}
+
+	subject, err := url.PathUnescape(strings.TrimPrefix(parts[2], "subject="))
+	if err != nil {
+		return Partition{}, 0, 0, fmt.Errorf("%w: %s", ErrInvalidObjectName, name)
+	}
+
+	p := Partition{Stream: parts[0], Date: date, Subject: subject}
+	return p, firstSeq, lastSeq, nil
+}
+
+// subjectPartition returns the first depth tokens of the subject
+func subjectPartition(subject string, depth int) string {
+	tokens := strings.Split(subject, ".")
+	if depth > 0 && len(tokens) > depth {
+		tokens = tokens[:depth]
+	}
+
+	return strings.Join(tokens, ".")
+}
+
+// write stores the records as one gzipped NDJSON object per partition
+func write(store ObjectStore, records []Record, depth int, logger *slog.Logger) error {
+	groups := make(map[Partition][]Record)
+	var keys []Partition
+	for _, r := range records {
+		p := Partition{
+			Stream:  r.Stream,
+			Date:    r.Time.UTC().Truncate(24 * time.Hour),
+			Subject: subjectPartition(r.Subject, depth),
+		}
+		if _, ok := groups[p]; !ok {
+			keys = append(keys, p)
+		}
+		groups[p] = append(groups[p], r)
+	}
+
+	for _, p := range keys {
+		group := groups[p]
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		enc := json.NewEncoder(zw)
+		for _, r := range group {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		first, last := group[0].Sequence, group[len(group)-1].Sequence
+		meta := &nats.ObjectMeta{
+			Name: objectName(p, first, last),
+			Headers: nats.Header{
+				"Content-Type":     []string{contentType},
+				"Content-Encoding": []string{contentEncoding},
+				"Sencillo-Records": []string{strconv.Itoa(len(group))},
+			},
+		}
+		if _, err := store.Put(meta, &buf); err != nil {
+			return fmt.Errorf("writing %s: %w", meta.Name, err)
+		}
+		logger.Debug("archived messages", "object", meta.Name, "records", len(group))
+	}
+
+	return nil
+}
+
+// Query selects archived records. Empty fields match everything.
+type Query struct {
+	Stream string
+	// Subject filters records and can contain wildcards
+	Subject string
+	From    time.Time
+	To      time.Time
+}
+
+// Reader reads records written by a Sink
+type Reader struct {
+	store ObjectStore
+}
+
+// NewReader returns a Reader for the bucket
+func NewReader(store ObjectStore) *Reader {
+	return &Reader{store: store}
+}
+
+// Objects returns the names of the objects that can contain records matching the query, in order
+func (r *Reader) Objects(q Query) ([]string, error) {
+	infos, err := r.store.List()
+	if errors.Is(err, nats.ErrNoObjectsFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range infos {
+		p, _, _, err := parseObjectName(info.Name)
+		if err != nil {
+			continue
+		}
+		if q.Stream != "" && p.Stream != q.Stream {
+			continue
+		}
+		if !q.From.IsZero() && p.Date.Before(q.From.UTC().Truncate(24*time.Hour)) {
+			continue
+		}
+		if !q.To.IsZero() && p.Date.After(q.To.UTC()) {
+			continue
+		}
+		if q.Subject != "" && !partitionMatches(q.Subject, p.Subject) {
+			continue
+		}
+		names = append(names, info.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// partitionMatches reports whether a subject filter can match subjects starting with the partition's tokens
+func partitionMatches(filter, partition string) bool {
+	ft := strings.Split(filter, ".")
+	for i, pt := range strings.Split(partition, ".") {
+		if i >= len(ft) {
+			return false
+		}
+		if ft[i] == ">" {
+			return true
+		}
+		if ft[i] != "*" && ft[i] != pt {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Scan calls fn with every record matching the query, in stream sequence order within each partition. It stops at
+// the first error returned by fn.
+func (r *Reader) Scan(ctx context.Context, q Query, fn func(Record) error) error {
+	names, err := r.Objects(q)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.scanObject(name, q, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Reader) scanObject(name string, q Query, fn func(Record) error) error {
+	obj, err := r.store.Get(name)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	zr, err := gzip.NewReader(obj)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(zr)
+	for {
+		var rec Record
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		if !q.From.IsZero() && rec.Time.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && rec.Time.After(q.To) {
+			continue
+		}
+		if q.Subject != "" && !sdnats.SubjectsOverlap(q.Subject, rec.Subject) {
+			continue
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/scrub"
+	"github.com/nats-io/nats.go"
+)
+
+type fakeResult struct {
+	nats.ObjectResult
+	r io.Reader
+}
+
+func (f fakeResult) Read(p []byte) (int, error) { return f.r.Read(p) }
+func (f fakeResult) Close() error               { return nil }
+
+type fakeStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeStore) Put(obj *nats.ObjectMeta, reader io.Reader, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[obj.Name] = data
+	return &nats.ObjectInfo{ObjectMeta: *obj}, nil
+}
+
+func (f *fakeStore) Get(name string, opts ...nats.GetObjectOpt) (nats.ObjectResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, nats.ErrObjectNotFound
+	}
+	return fakeResult{r: bytes.NewReader(data)}, nil
+}
+
+func (f *fakeStore) List(opts ...nats.ListObjectsOpt) ([]*nats.ObjectInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.objects) == 0 {
+		return nil, nats.ErrNoObjectsFound
+	}
+	var infos []*nats.ObjectInfo
+	for name := range f.objects {
+		infos = append(infos, &nats.ObjectInfo{ObjectMeta: nats.ObjectMeta{Name: name}})
+	}
+	return infos, nil
+}
+
+var day = time.Date(2025, 3, 1, 23, 58, 0, 0, time.UTC)
+
+// message returns a message bound to a subscription so its metadata can be read
+func message(seq int, subject string, ts time.Time) *nats.Msg {
+	msg := nats.NewMsg(subject)
+	msg.Data = []byte(fmt.Sprintf(`{"n": %d}`, seq))
+	msg.Header.Set("Trace-Id", fmt.Sprint(seq))
+	msg.Sub = &nats.Subscription{}
+	msg.Reply = fmt.Sprintf("$JS.ACK.ORDERS.archive.1.%d.%d.%d.0", seq, seq, ts.UnixNano())
+	return msg
+}
+
+type fakeFetcher struct {
+	batches [][]*nats.Msg
+	cancel  context.CancelFunc
+}
+
+func (f *fakeFetcher) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	if len(f.batches) == 0 {
+		f.cancel()
+		return nil, nats.ErrTimeout
+	}
+	next := f.batches[0]
+	f.batches = f.batches[1:]
+	return next, nil
+}
+
+func TestSink(t *testing.T) {
+	store := &fakeStore{objects: map[string][]byte{}}
+	s := NewSink(store, "ORDERS", SetBatch(3, 1<<20, time.Hour), SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	var acked []string
+	s.ack = func(m *nats.Msg) error {
+		acked = append(acked, m.Header.Get("Trace-Id"))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &fakeFetcher{cancel: cancel, batches: [][]*nats.Msg{
+		{message(1, "orders.created.eu", day), message(2, "orders.created.us", day)},
+		{message(3, "orders.shipped.eu", day), message(4, "orders.created.eu", day.Add(3*time.Minute))},
+	}}
+	if err := s.run(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(acked, want) {
+		t.Errorf("expected every message to be acked after it was written, got %v", acked)
+	}
+
+	r := NewReader(store)
+	got, err := r.Objects(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ORDERS/date=2025-03-01/subject=orders.created/00000000000000000001-00000000000000000002.ndjson.gz",
+		"ORDERS/date=2025-03-01/subject=orders.shipped/00000000000000000003-00000000000000000003.ndjson.gz",
+		"ORDERS/date=2025-03-02/subject=orders.created/00000000000000000004-00000000000000000004.ndjson.gz",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected objects\n%v\nbut got\n%v", want, got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	store := &fakeStore{objects: map[string][]byte{}}
+	var records []Record
+	for i, subject := range []string{"orders.created.eu", "orders.created.us", "orders.shipped.eu", "users.created", "files/in.uploaded"} {
+		records = append(records, Record{
+			Stream:   "ORDERS",
+			Sequence: uint64(i + 1),
+			Subject:  subject,
+			Time:     day.Add(time.Duration(i) * time.Minute),
+			Header:   nats.Header{"Trace-Id": []string{fmt.Sprint(i + 1)}},
+			Data:     []byte(`{}`),
+		})
+	}
+	if err := write(store, records, 2, slog.Default()); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name  string
+		query Query
+		want  []uint64
+	}{
+		{name: "all", query: Query{}, want: []uint64{1, 2, 5, 3, 4}},
+		{name: "subject with a slash", query: Query{Subject: "files/in.>"}, want: []uint64{5}},
+		{name: "subject", query: Query{Subject: "orders.*.eu"}, want: []uint64{1, 3}},
+		{name: "wildcard", query: Query{Subject: "orders.>"}, want: []uint64{1, 2, 3}},
+		{name: "time", query: Query{From: day.Add(time.Minute), To: day.Add(2 * time.Minute)}, want: []uint64{2, 3}},
+		{name: "next day", query: Query{From: day.Add(24 * time.Hour)}},
+		{name: "other stream", query: Query{Stream: "USERS"}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var got []uint64
+			err := NewReader(store).Scan(context.Background(), v.query, func(r Record) error {
+				got = append(got, r.Sequence)
+				if r.Msg().Header.Get("Trace-Id") != fmt.Sprint(r.Sequence) {
+					t.Errorf("expected headers to round trip")
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %v but got %v", v.want, got)
+			}
+		})
+	}
+}
+
+func TestSinkAckWait(t *testing.T) {
+	tt := []struct {
+		maxAge time.Duration
+		want   time.Duration
+	}{
+		{maxAge: time.Second, want: 30 * time.Second},
+		{maxAge: time.Minute, want: 2 * time.Minute},
+	}
+
+	for _, v := range tt {
+		s := NewSink(&fakeStore{}, "ORDERS", SetBatch(10, 1<<20, v.maxAge))
+		if got := s.AckWait(); got != v.want || got <= v.maxAge {
+			t.Errorf("expected ack wait %s for max age %s but got %s", v.want, v.maxAge, got)
+		}
+	}
+}
+
+func TestSinkScrubber(t *testing.T) {
+	s := NewSink(&fakeStore{objects: map[string][]byte{}}, "ORDERS", SetScrubber(scrub.NewScrubber()))
+	msg := message(1, "orders.created.eu", day)
+	msg.Data = []byte(`{"email":"ana@example.com","n":1}`)
+	msg.Header.Set("Authorization", "Bearer abc")
+
+	rec, err := s.record(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rec.Data) != `{"email":"[REDACTED]","n":1}` || rec.Header.Get("Authorization") != "[REDACTED]" {
+		t.Errorf("expected the record to be scrubbed but got %s %v", rec.Data, rec.Header)
+	}
+	if msg.Header.Get("Authorization") != "Bearer abc" {
+		t.Error("expected the message to be left unchanged")
+	}
+}
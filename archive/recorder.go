@@ -0,0 +1,406 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/SencilloDev/sencillo-go/scrub"
+	sdmiddleware "github.com/SencilloDev/sencillo-go/transports/http/middleware"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RequestIDHeader identifies a request across HTTP and NATS
+const RequestIDHeader = "X-Request-ID"
+
+var ErrRecordingNotFound = fmt.Errorf("recording not found")
+
+// Transports are the values of Envelope.Transport
+const (
+	TransportHTTP = "http"
+	TransportNATS = "nats"
+)
+
+// EnvelopeMessage is one side of a recorded interaction
+type EnvelopeMessage struct {
+	Header map[string][]string `json:"header,omitempty"`
+	Body   string              `json:"body,omitempty"`
+	// Truncated is set when the body was longer than the recorder keeps
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Envelope is a recorded request and its response
+type Envelope struct {
+	RequestID string          `json:"request_id"`
+	Transport string          `json:"transport"`
+	Method    string          `json:"method,omitempty"`
+	Target    string          `json:"target"`
+	Status    int             `json:"status"`
+	Started   time.Time       `json:"started"`
+	Duration  time.Duration   `json:"duration"`
+	Request   EnvelopeMessage `json:"request"`
+	Response  EnvelopeMessage `json:"response"`
+}
+
+// JetStreamPublisher publishes to a stream. nats.JetStreamContext implements it.
+type JetStreamPublisher interface {
+	PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// RecordingSubject returns the subject a request's envelope is published to. Characters that can't be in a
+// subject token are replaced with underscores.
+func RecordingSubject(prefix, transport, requestID string) string {
+	token := strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, requestID)
+	return fmt.Sprintf("%s.%s.%s", prefix, transport, token)
+}
+
+// RecordingStream returns the configuration of a stream keeping recordings for retention. Provision it and run a
+// Sink on it to archive the recordings for longer.
+func RecordingStream(name, prefix string, retention time.Duration) nats.StreamConfig {
+	return nats.StreamConfig{
+		Name:        name,
+		Subjects:    []string{prefix + ".>"},
+		MaxAge:      retention,
+		Storage:     nats.FileStorage,
+		Duplicates:  2 * time.Minute,
+		AllowDirect: true,
+	}
+}
+
+// RecordingBucket returns the configuration of an object store bucket keeping archived recordings for retention.
+// Objects are removed once they are older than the retention.
+func RecordingBucket(name string, retention time.Duration) nats.ObjectStoreConfig {
+	return nats.ObjectStoreConfig{
+		Bucket:  name,
+		TTL:     retention,
+		Storage: nats.FileStorage,
+	}
+}
+
+// RecorderOpt is a functional option to modify the Recorder
+type RecorderOpt func(*Recorder)
+
+// Recorder records complete requests and responses for compliance. Headers and bodies are scrubbed before the
+// envelope leaves the process and published to a JetStream subject keyed by the request ID.
+type Recorder struct {
+	js       JetStreamPublisher
+	prefix   string
+	scrubber *scrub.Scrubber
+	maxBody  int
+	logger   *slog.Logger
+	now      func() time.Time
+
+	recorded *prometheus.CounterVec
+}
+
+// SetRecorderScrubber sets the scrubber applied to headers and bodies. The default uses scrub's default
+// detectors.
+func SetRecorderScrubber(sc *scrub.Scrubber) RecorderOpt {
+	return func(r *Recorder) {
+		r.scrubber = sc
+	}
+}
+
+// SetMaxBody sets how many bytes of each body are recorded. The default is 64KB. A body longer than that is
+// scrubbed as text, since what was kept of it isn't valid JSON.
+func SetMaxBody(n int) RecorderOpt {
+	return func(r *Recorder) {
+		r.maxBody = n
+	}
+}
+
+// SetRecorderLogger sets the logger used to report recordings that couldn't be published
+func SetRecorderLogger(l *slog.Logger) RecorderOpt {
+	return func(r *Recorder) {
+		r.logger = l
+	}
+}
+
+// NewRecorder returns a Recorder publishing envelopes under prefix
+func NewRecorder(js JetStreamPublisher, prefix string, opts ...RecorderOpt) *Recorder {
+	r := &Recorder{
+		js:       js,
+		prefix:   prefix,
+		scrubber: scrub.NewScrubber(),
+		maxBody:  64 << 10,
+		logger:   slog.Default(),
+		now:      time.Now,
+		recorded: metrics.NewCounterVec("recordings", "Requests recorded for compliance", []string{"transport", "result"}),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Metrics returns the recorder's collectors to register with the exporter
+func (r *Recorder) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{r.recorded}
+}
+
+// message scrubs a header and body into one side of an envelope. The body is scrubbed before it is cut to the
+// limit, so a value can't be cut short of what the detectors match.
+func (r *Recorder) message(header map[string][]string, body []byte) EnvelopeMessage {
+	m := EnvelopeMessage{}
+	if len(body) > 0 {
+		body = r.scrubber.Bytes(body)
+		if len(body) > r.maxBody {
+			body, m.Truncated = body[:r.maxBody], true
+		}
+		m.Body = string(body)
+	}
+	if len(header) > 0 {
+		m.Header = make(map[string][]string, len(header))
+		for k, values := range header {
+			for _, v := range values {
+				m.Header[k] = append(m.Header[k], r.scrubber.String(k, v))
+			}
+		}
+	}
+	return m
+}
+
+// Record publishes an envelope with a generated message ID. The request ID comes from the client, so using it as
+// the message ID would let a client reusing an ID keep its later requests from being recorded.
+func (r *Recorder) Record(e Envelope) error {
+	if e.RequestID == "" {
+		e.RequestID = nuid.Next()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(RecordingSubject(r.prefix, e.Transport, e.RequestID))
+	msg.Data = data
+	msg.Header.Set(RequestIDHeader, e.RequestID)
+	msg.Header.Set("Content-Type", "application/json")
+	msg.Header.Set(nats.MsgIdHdr, nuid.Next())
+	if _, err := r.js.PublishMsg(msg); err != nil {
+		r.recorded.WithLabelValues(e.Transport, "failed").Inc()
+		r.logger.Error(fmt.Sprintf("recording request %s: %v", e.RequestID, err))
+		return err
+	}
+	r.recorded.WithLabelValues(e.Transport, "recorded").Inc()
+	return nil
+}
+
+// limitedBody keeps the first n bytes written to it and whether more were written
+type limitedBody struct {
+	bytes.Buffer
+	n    int
+	more bool
+}
+
+func (l *limitedBody) keep(p []byte) {
+	if room := l.n - l.Len(); room < len(p) {
+		p, l.more = p[:max(room, 0)], true
+	}
+	l.Write(p)
+}
+
+// responseRecorder keeps the status and body of a response while writing it
+type responseRecorder struct {
+	*sdmiddleware.StatusRec
+	body *limitedBody
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	w.body.keep(p)
+	return w.StatusRec.Write(p)
+}
+
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.StatusRec.ResponseWriter
+}
+
+// Middleware records HTTP requests. The request body is read up to the recorder's limit and replayed to the
+// handler. The envelope is published after the handler returns.
+func (r *Recorder) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := r.now()
+		var reqBody []byte
+		truncated := false
+		if req.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(req.Body, int64(r.maxBody)+1))
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			reqBody, truncated = data, len(data) > r.maxBody
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+		}
+
+		rec := &responseRecorder{StatusRec: &sdmiddleware.StatusRec{ResponseWriter: w}, body: &limitedBody{n: r.maxBody}}
+		h.ServeHTTP(rec, req)
+
+		e := Envelope{
+			RequestID: req.Header.Get(RequestIDHeader),
+			Transport: TransportHTTP,
+			Method:    req.Method,
+			Target:    req.URL.RequestURI(),
+			Status:    rec.Status,
+			Started:   start.UTC(),
+			Duration:  r.now().Sub(start),
+			Request:   r.message(req.Header, reqBody),
+			Response:  r.message(w.Header(), rec.body.Bytes()),
+		}
+		e.Request.Truncated = e.Request.Truncated || truncated
+		e.Response.Truncated = e.Response.Truncated || rec.body.more
+		if e.RequestID == "" {
+			e.RequestID = w.Header().Get(RequestIDHeader)
+		}
+		r.Record(e)
+	})
+}
+
+// recordedRequest keeps the response a micro handler sent
+type recordedRequest struct {
+	micro.Request
+	status int
+	header nats.Header
+	data   []byte
+}
+
+func (r *recordedRequest) keep(status int, data []byte, opts []micro.RespondOpt) {
+	msg := nats.NewMsg("")
+	for _, opt := range opts {
+		opt(msg)
+	}
+	r.status, r.header, r.data = status, msg.Header, data
+}
+
+func (r *recordedRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	r.keep(http.StatusOK, data, opts)
+	return r.Request.Respond(data, opts...)
+}
+
+func (r *recordedRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return micro.ErrMarshalResponse
+	}
+	return r.Respond(data, opts...)
+}
+
+func (r *recordedRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	status, err := strconv.Atoi(code)
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	r.keep(status, data, opts)
+	r.header.Set(micro.ErrorCodeHeader, code)
+	r.header.Set(micro.ErrorHeader, description)
+	return r.Request.Error(code, description, data, opts...)
+}
+
+// MicroHandler records NATS requests. The envelope is published after the handler returns, with status 200 for
+// responses and the error code for errors.
+func (r *Recorder) MicroHandler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(req micro.Request) {
+		start := r.now()
+		rec := &recordedRequest{Request: req}
+		h.Handle(rec)
+
+		r.Record(Envelope{
+			RequestID: req.Headers().Get(RequestIDHeader),
+			Transport: TransportNATS,
+			Target:    req.Subject(),
+			Status:    rec.status,
+			Started:   start.UTC(),
+			Duration:  r.now().Sub(start),
+			Request:   r.message(req.Headers(), req.Data()),
+			Response:  r.message(rec.header, rec.data),
+		})
+	})
+}
+
+// MessageGetter reads messages from a stream. nats.JetStreamContext implements it.
+type MessageGetter interface {
+	GetMsg(name string, seq uint64, opts ...nats.JSOpt) (*nats.RawStreamMsg, error)
+}
+
+// LookupRecording returns every envelope recorded for a request ID that is still in the stream, in the order
+// they were recorded. It reads them with direct gets, which the stream from RecordingStream allows.
+func LookupRecording(js MessageGetter, stream, prefix, requestID string) ([]Envelope, error) {
+	subject := RecordingSubject(prefix, "*", requestID)
+	var envelopes []Envelope
+	for seq := uint64(1); ; {
+		msg, err := js.GetMsg(stream, seq, nats.DirectGetNext(subject))
+		if errors.Is(err, nats.ErrMsgNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var e Envelope
+		if err := json.Unmarshal(msg.Data, &e); err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, e)
+		seq = msg.Sequence + 1
+	}
+	if len(envelopes) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRecordingNotFound, requestID)
+	}
+	return envelopes, nil
+}
+
+// FindRecording returns the archived envelopes for a request ID. Narrow the search with From and To, since
+// every object of the stream that holds recordings is read.
+func (r *Reader) FindRecording(ctx context.Context, stream, prefix, requestID string, from, to time.Time) ([]Envelope, error) {
+	q := Query{Stream: stream, Subject: RecordingSubject(prefix, "*", requestID), From: from, To: to}
+	var envelopes []Envelope
+	err := r.Scan(ctx, q, func(rec Record) error {
+		var e Envelope
+		if err := json.Unmarshal(rec.Data, &e); err != nil {
+			return err
+		}
+		envelopes = append(envelopes, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(envelopes) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRecordingNotFound, requestID)
+	}
+	return envelopes, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeJetStream struct {
+	msgs []*nats.Msg
+	// next is the subject filter GetMsg is called with, which the options don't expose
+	next string
+}
+
+func (f *fakeJetStream) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	f.msgs = append(f.msgs, m)
+	return &nats.PubAck{Stream: "RECORDINGS", Sequence: uint64(len(f.msgs))}, nil
+}
+
+func (f *fakeJetStream) GetMsg(name string, seq uint64, opts ...nats.JSOpt) (*nats.RawStreamMsg, error) {
+	for i := int(seq) - 1; i < len(f.msgs); i++ {
+		if partitionMatches(f.next, f.msgs[i].Subject) {
+			return &nats.RawStreamMsg{Subject: f.msgs[i].Subject, Sequence: uint64(i + 1), Data: f.msgs[i].Data}, nil
+		}
+	}
+	return nil, nats.ErrMsgNotFound
+}
+
+func (f *fakeJetStream) envelope(t *testing.T, i int) Envelope {
+	t.Helper()
+	var e Envelope
+	if err := json.Unmarshal(f.msgs[i].Data, &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+type recorderReply struct {
+	micro.Request
+	msg *nats.Msg
+}
+
+func (r *recorderReply) Headers() micro.Headers { return micro.Headers(r.msg.Header) }
+func (r *recorderReply) Data() []byte           { return r.msg.Data }
+func (r *recorderReply) Subject() string        { return r.msg.Subject }
+func (r *recorderReply) Respond([]byte, ...micro.RespondOpt) error {
+	return nil
+}
+func (r *recorderReply) Error(string, string, []byte, ...micro.RespondOpt) error {
+	return nil
+}
+
+func TestRecorderMiddleware(t *testing.T) {
+	tt := []struct {
+		name      string
+		id        string
+		body      string
+		status    int
+		reqBody   string
+		respBody  string
+		truncated bool
+	}{
+		{
+			name:     "scrubbed",
+			id:       "req-1",
+			body:     `{"email": "ana@example.com", "password": "hunter2"}`,
+			status:   http.StatusCreated,
+			reqBody:  `{"email":"[REDACTED]","password":"[REDACTED]"}`,
+			respBody: `{"id":"42"}`,
+		},
+		{
+			name:      "truncated",
+			id:        "req.2",
+			body:      strings.Repeat("a", 80),
+			status:    http.StatusBadRequest,
+			reqBody:   strings.Repeat("a", 64),
+			respBody:  `{"id":"42"}`,
+			truncated: true,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			js := &fakeJetStream{}
+			rec := NewRecorder(js, "recordings", SetMaxBody(64), SetRecorderLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			var handled string
+			h := rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				handled = string(data)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(v.status)
+				w.Write([]byte(`{"id":"42"}`))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/users?x=1", strings.NewReader(v.body))
+			req.Header.Set(RequestIDHeader, v.id)
+			req.Header.Set("Authorization", "Bearer abc")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if handled != v.body {
+				t.Errorf("expected the handler to read the whole body but got %q", handled)
+			}
+			if want := RecordingSubject("recordings", TransportHTTP, v.id); js.msgs[0].Subject != want || strings.Contains(want, "req.2") {
+				t.Errorf("expected subject %s but got %s", want, js.msgs[0].Subject)
+			}
+			e := js.envelope(t, 0)
+			if e.RequestID != v.id || e.Method != http.MethodPost || e.Target != "/users?x=1" || e.Status != v.status {
+				t.Errorf("unexpected envelope %+v", e)
+			}
+			if e.Request.Body != v.reqBody || e.Response.Body != v.respBody || e.Request.Truncated != v.truncated {
+				t.Errorf("unexpected bodies %+v %+v", e.Request, e.Response)
+			}
+			if got := e.Request.Header["Authorization"]; len(got) != 1 || got[0] == "Bearer abc" {
+				t.Errorf("expected the authorization header to be scrubbed but got %v", got)
+			}
+		})
+	}
+}
+
+func TestRecorderMicroHandler(t *testing.T) {
+	js := &fakeJetStream{}
+	rec := NewRecorder(js, "recordings")
+	reg := sdtest.NewRegistry(t, rec.Metrics()...)
+	h := rec.MicroHandler(micro.HandlerFunc(func(r micro.Request) {
+		r.Error("404", "Not Found", []byte(`{"errors": ["no such order"]}`))
+	}))
+
+	h.Handle(&recorderReply{msg: &nats.Msg{Subject: "orders.get", Data: []byte(`{"id": "7"}`), Header: nats.Header{RequestIDHeader: {"req-3"}}}})
+
+	e := js.envelope(t, 0)
+	if e.Transport != TransportNATS || e.Target != "orders.get" || e.Status != http.StatusNotFound || e.Response.Header[micro.ErrorCodeHeader][0] != "404" {
+		t.Errorf("unexpected envelope %+v", e)
+	}
+	reg.AssertCounter(t, "recordings", prometheus.Labels{"transport": "nats", "result": "recorded"}, 1)
+}
+
+func TestFindRecording(t *testing.T) {
+	js := &fakeJetStream{}
+	rec := NewRecorder(js, "recordings")
+	rec.now = func() time.Time { return day }
+	h := rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// the client reuses req-2, and both of its requests are recorded
+	for _, id := range []string{"req-1", "req-2", "req-2"} {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(RequestIDHeader, id)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if js.msgs[1].Header.Get(nats.MsgIdHdr) == js.msgs[2].Header.Get(nats.MsgIdHdr) {
+		t.Error("expected each recording to get its own message ID")
+	}
+
+	js.next = RecordingSubject("recordings", "*", "req-2")
+	envelopes, err := LookupRecording(js, "RECORDINGS", "recordings", "req-2")
+	if err != nil || len(envelopes) != 2 || envelopes[0].RequestID != "req-2" || envelopes[1].RequestID != "req-2" {
+		t.Errorf("expected the stream lookup to find both req-2 recordings but got %v %v", envelopes, err)
+	}
+
+	records := make([]Record, len(js.msgs))
+	for i, msg := range js.msgs {
+		records[i] = Record{Stream: "RECORDINGS", Sequence: uint64(i + 1), Subject: msg.Subject, Time: day, Header: msg.Header, Data: msg.Data}
+	}
+	store := &fakeStore{objects: map[string][]byte{}}
+	if err := write(store, records, 2, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(store)
+
+	tt := []struct {
+		id    string
+		count int
+		err   error
+	}{
+		{id: "req-1", count: 1},
+		{id: "req-2", count: 2},
+		{id: "req-9", err: ErrRecordingNotFound},
+	}
+
+	for _, v := range tt {
+		t.Run(v.id, func(t *testing.T) {
+			envelopes, err := r.FindRecording(context.Background(), "RECORDINGS", "recordings", v.id, day, day.Add(time.Hour))
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if v.err == nil && (len(envelopes) != v.count || envelopes[0].RequestID != v.id) {
+				t.Errorf("expected %d envelopes of %s but got %+v", v.count, v.id, envelopes)
+			}
+		})
+	}
+}
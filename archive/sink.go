@@ -0,0 +1,232 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/scrub"
+	"github.com/nats-io/nats.go"
+)
+
+// Fetcher fetches a batch of messages from a pull consumer. *nats.Subscription implements it.
+type Fetcher interface {
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+}
+
+// SinkOpt is a functional option to modify the Sink
+type SinkOpt func(*Sink)
+
+// Sink archives the messages of a stream. A durable pull consumer tracks its progress, and messages are only
+// acknowledged after the object holding them is written, so nothing is lost if the sink stops.
+type Sink struct {
+	store        ObjectStore
+	stream       string
+	durable      string
+	maxRecords   int
+	maxBytes     int
+	maxAge       time.Duration
+	subjectDepth int
+	logger       *slog.Logger
+	scrubber     *scrub.Scrubber
+	ack          func(*nats.Msg) error
+	nak          func(*nats.Msg) error
+}
+
+// SetDurable sets the name of the pull consumer. The default is archive.
+func SetDurable(name string) SinkOpt {
+	return func(s *Sink) {
+		s.durable = name
+	}
+}
+
+// SetBatch sets the limits of a batch. A batch is written once it has maxRecords records, maxBytes bytes of
+// message data, or its first message is maxAge old.
+func SetBatch(maxRecords, maxBytes int, maxAge time.Duration) SinkOpt {
+	return func(s *Sink) {
+		s.maxRecords = maxRecords
+		s.maxBytes = maxBytes
+		s.maxAge = maxAge
+	}
+}
+
+// SetSubjectDepth sets how many leading subject tokens partition the objects. The default is 2, so
+// orders.created.eu and orders.created.us share the orders.created partition.
+func SetSubjectDepth(n int) SinkOpt {
+	return func(s *Sink) {
+		s.subjectDepth = n
+	}
+}
+
+// SetLogger sets the logger used to report archived batches
+func SetLogger(l *slog.Logger) SinkOpt {
+	return func(s *Sink) {
+		s.logger = l
+	}
+}
+
+// SetScrubber scrubs personal data from message headers and payloads before they are archived. Archived records
+// keep the scrubbed values, so replays get them too.
+func SetScrubber(sc *scrub.Scrubber) SinkOpt {
+	return func(s *Sink) {
+		s.scrubber = sc
+	}
+}
+
+// NewSink returns a Sink archiving stream into store
+func NewSink(store ObjectStore, stream string, opts ...SinkOpt) *Sink {
+	s := &Sink{
+		store:        store,
+		stream:       stream,
+		durable:      "archive",
+		maxRecords:   1000,
+		maxBytes:     8 << 20,
+		maxAge:       time.Minute,
+		subjectDepth: 2,
+		logger:       slog.Default(),
+		ack:          func(m *nats.Msg) error { return m.Ack() },
+		nak:          func(m *nats.Msg) error { return m.Nak() },
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// AckWait returns how long the sink's consumer waits for acknowledgements. Messages are held for up to the
+// batch's max age before they are written and acknowledged, so it is twice the max age and at least 30 seconds,
+// which keeps batched messages from being redelivered and archived twice.
+func (s *Sink) AckWait() time.Duration {
+	return max(2*s.maxAge, 30*time.Second)
+}
+
+// Run creates or binds the sink's pull consumer for subject, which can be empty for the whole stream, and
+// archives messages until ctx is done. The batch in progress is written before Run returns.
+func (s *Sink) Run(ctx context.Context, js nats.JetStreamContext, subject string) error {
+	sub, err := js.PullSubscribe(subject, s.durable, nats.BindStream(s.stream), nats.ManualAck(), nats.AckWait(s.AckWait()))
+	if err != nil {
+		return err
+	}
+
+	return s.run(ctx, sub)
+}
+
+type pending struct {
+	msgs    []*nats.Msg
+	records []Record
+	bytes   int
+	started time.Time
+}
+
+func (s *Sink) run(ctx context.Context, f Fetcher) error {
+	var batch pending
+	for {
+		if ctx.Err() != nil {
+			return s.flush(&batch)
+		}
+
+		wait := s.maxAge
+		if len(batch.msgs) > 0 {
+			wait = time.Until(batch.started.Add(s.maxAge))
+		}
+		if wait < 10*time.Millisecond {
+			wait = 10 * time.Millisecond
+		}
+
+		msgs, err := f.Fetch(s.maxRecords-len(batch.msgs), nats.MaxWait(wait))
+		if err != nil && !errors.Is(err, nats.ErrTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+			return errors.Join(err, s.flush(&batch))
+		}
+
+		for _, msg := range msgs {
+			rec, err := s.record(msg)
+			if err != nil {
+				return errors.Join(err, s.flush(&batch))
+			}
+			if len(batch.msgs) == 0 {
+				batch.started = time.Now()
+			}
+			batch.msgs = append(batch.msgs, msg)
+			batch.records = append(batch.records, rec)
+			batch.bytes += len(msg.Data)
+		}
+
+		full := len(batch.msgs) >= s.maxRecords || batch.bytes >= s.maxBytes
+		old := len(batch.msgs) > 0 && time.Since(batch.started) >= s.maxAge
+		if full || old {
+			if err := s.flush(&batch); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Sink) record(msg *nats.Msg) (Record, error) {
+	meta, err := msg.Metadata()
+	if err != nil {
+		return Record{}, err
+	}
+
+	rec := Record{
+		Stream:   meta.Stream,
+		Sequence: meta.Sequence.Stream,
+		Subject:  msg.Subject,
+		Time:     meta.Timestamp.UTC(),
+		Header:   msg.Header,
+		Data:     msg.Data,
+	}
+	if s.scrubber != nil {
+		rec.Header = make(nats.Header, len(msg.Header))
+		for k, values := range msg.Header {
+			for _, v := range values {
+				rec.Header.Add(k, s.scrubber.String(k, v))
+			}
+		}
+		rec.Data = s.scrubber.Bytes(msg.Data)
+	}
+
+	return rec, nil
+}
+
+// flush writes the batch and acknowledges its messages. The messages are redelivered if the write fails.
+func (s *Sink) flush(b *pending) error {
+	if len(b.msgs) == 0 {
+		return nil
+	}
+
+	if err := write(s.store, b.records, s.subjectDepth, s.logger); err != nil {
+		for _, msg := range b.msgs {
+			s.nak(msg)
+		}
+		*b = pending{}
+		return fmt.Errorf("archiving %s: %w", s.stream, err)
+	}
+
+	for _, msg := range b.msgs {
+		if err := s.ack(msg); err != nil {
+			s.logger.Error(fmt.Sprintf("error acknowledging archived message: %v", err))
+		}
+	}
+	s.logger.Info("archived batch", "stream", s.stream, "records", len(b.records), "bytes", b.bytes)
+	*b = pending{}
+
+	return nil
+}
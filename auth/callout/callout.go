@@ -0,0 +1,374 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+const (
+	// AuthCalloutSubject is the subject the server sends authorization requests on
+	AuthCalloutSubject = "$SYS.REQ.USER.AUTH"
+
+	serverXKeyHeader = "Nats-Server-Xkey"
+)
+
+var (
+	ErrInvalidRequest  = fmt.Errorf("invalid authorization request")
+	ErrInvalidIdentity = fmt.Errorf("identity values used in permission templates must not be empty or contain '.', '*', '>', or whitespace")
+	ErrNotAuthorized   = fmt.Errorf("not authorized")
+)
+
+// Identity is the result of authenticating a client with an external identity provider
+type Identity struct {
+	// Name is the user name placed in the user JWT and available to permission templates
+	Name string
+
+	// Account is the account the user is placed in
+	Account string
+
+	// Attributes are extra values from the identity provider available to permission templates
+	Attributes map[string]string
+
+	// Permissions are added to the permissions from the template
+	Permissions jwt.Permissions
+
+	// Expires overrides the default user JWT lifetime when set
+	Expires time.Time
+}
+
+// Authenticator validates the credentials in an authorization request, usually against an external identity provider
+type Authenticator interface {
+	Authenticate(context.Context, jwt.AuthorizationRequest) (Identity, error)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(context.Context, jwt.AuthorizationRequest) (Identity, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, r jwt.AuthorizationRequest) (Identity, error) {
+	return f(ctx, r)
+}
+
+// PermissionTemplate holds subjects that are executed as text/templates with the Identity, for example
+// "users.{{ .Name }}.>"
+type PermissionTemplate struct {
+	Pub jwt.Permission
+	Sub jwt.Permission
+}
+
+// ResponderOpt is a functional option to modify the Responder
+type ResponderOpt func(*Responder)
+
+// Responder answers NATS auth callout requests by authenticating the client and minting a user JWT
+type Responder struct {
+	issuer        nkeys.KeyPair
+	issuerAccount string
+	xkey          nkeys.KeyPair
+	servers       map[string]bool
+	auth          Authenticator
+	template      PermissionTemplate
+	ttl           time.Duration
+	timeout       time.Duration
+	logger        *slog.Logger
+}
+
+// NewResponder returns a Responder that signs user JWTs and responses with the issuer account key
+func NewResponder(issuer nkeys.KeyPair, auth Authenticator, opts ...ResponderOpt) *Responder {
+	r := &Responder{
+		issuer:  issuer,
+		auth:    auth,
+		ttl:     time.Hour,
+		timeout: 5 * time.Second,
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// SetXKey sets the curve key used to decrypt requests and encrypt responses when the server sends its xkey
+func SetXKey(kp nkeys.KeyPair) ResponderOpt {
+	return func(r *Responder) {
+		r.xkey = kp
+	}
+}
+
+// SetIssuerAccount sets the account public key when the issuer is a signing key of that account
+func SetIssuerAccount(pub string) ResponderOpt {
+	return func(r *Responder) {
+		r.issuerAccount = pub
+	}
+}
+
+// SetTrustedServers only accepts requests signed by the given server public keys
+func SetTrustedServers(keys ...string) ResponderOpt {
+	return func(r *Responder) {
+		r.servers = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			r.servers[k] = true
+		}
+	}
+}
+
+// SetPermissionTemplate sets the permissions granted to every authenticated user
+func SetPermissionTemplate(t PermissionTemplate) ResponderOpt {
+	return func(r *Responder) {
+		r.template = t
+	}
+}
+
+// SetUserTTL sets the lifetime of minted user JWTs
+func SetUserTTL(d time.Duration) ResponderOpt {
+	return func(r *Responder) {
+		r.ttl = d
+	}
+}
+
+// SetTimeout sets the deadline for the Authenticator
+func SetTimeout(d time.Duration) ResponderOpt {
+	return func(r *Responder) {
+		r.timeout = d
+	}
+}
+
+// SetLogger sets the logger used to report failed authorizations
+func SetLogger(l *slog.Logger) ResponderOpt {
+	return func(r *Responder) {
+		r.logger = l
+	}
+}
+
+// Listen subscribes to the auth callout subject. The connection must be for a user in the auth account.
+func (r *Responder) Listen(nc *nats.Conn) (*nats.Subscription, error) {
+	return nc.Subscribe(AuthCalloutSubject, r.Handle)
+}
+
+// Handle responds to a single authorization request message
+func (r *Responder) Handle(msg *nats.Msg) {
+	resp, err := r.Respond(context.Background(), msg.Data, msg.Header.Get(serverXKeyHeader))
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("error handling auth callout: %v", err))
+		return
+	}
+
+	if err := msg.Respond(resp); err != nil {
+		r.logger.Error(fmt.Sprintf("error responding to auth callout: %v", err))
+	}
+}
+
+// Respond decodes an authorization request and returns the signed, and optionally encrypted, response.
+// Authentication failures are returned to the server in the response rather than as an error. The response only
+// says ErrNotAuthorized, since the server passes it on to the client, and the reason is logged.
+func (r *Responder) Respond(ctx context.Context, data []byte, serverXKey string) ([]byte, error) {
+	if serverXKey != "" {
+		if r.xkey == nil {
+			return nil, fmt.Errorf("server sent an encrypted request but no xkey is configured")
+		}
+		opened, err := r.xkey.Open(data, serverXKey)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting request: %w", err)
+		}
+		data = opened
+	}
+
+	req, err := r.request(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := jwt.NewAuthorizationResponseClaims(req.UserNkey)
+	resp.Audience = req.Server.ID
+	resp.IssuerAccount = r.issuerAccount
+
+	user, err := r.authorize(ctx, req.AuthorizationRequest)
+	if err != nil {
+		r.logger.Info(fmt.Sprintf("authorization failed for %s: %v", req.ClientInformation.Host, err))
+		resp.Error = ErrNotAuthorized.Error()
+	} else {
+		resp.Jwt = user
+	}
+
+	token, err := resp.Encode(r.issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverXKey != "" {
+		return r.xkey.Seal([]byte(token), serverXKey)
+	}
+
+	return []byte(token), nil
+}
+
+// request decodes the authorization request and checks it was signed by the server that sent it and has not expired
+func (r *Responder) request(token string) (*jwt.AuthorizationRequestClaims, error) {
+	req, err := jwt.DecodeAuthorizationRequestClaims(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	if req.Issuer != req.Server.ID {
+		return nil, fmt.Errorf("%w: issuer %s does not match server %s", ErrInvalidRequest, req.Issuer, req.Server.ID)
+	}
+	if r.servers != nil && !r.servers[req.Issuer] {
+		return nil, fmt.Errorf("%w: untrusted server %s", ErrInvalidRequest, req.Issuer)
+	}
+
+	vr := jwt.CreateValidationResults()
+	req.Validate(vr)
+	var errs []error
+	for _, i := range vr.Issues {
+		if i.Blocking || i.TimeCheck {
+			errs = append(errs, errors.New(i.Description))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, errors.Join(errs...))
+	}
+
+	return req, nil
+}
+
+// authorize authenticates the request and returns a signed user JWT
+func (r *Responder) authorize(ctx context.Context, req jwt.AuthorizationRequest) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	id, err := r.auth.Authenticate(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	perms, err := r.permissions(id)
+	if err != nil {
+		return "", err
+	}
+
+	expires := id.Expires
+	if expires.IsZero() {
+		expires = time.Now().Add(r.ttl)
+	}
+
+	account := id.Account
+	if account == "" {
+		account = "$G"
+	}
+
+	claims := jwt.NewUserClaims(req.UserNkey)
+	claims.Name = id.Name
+	claims.Audience = account
+	claims.Expires = expires.Unix()
+	claims.Permissions = perms
+	claims.IssuerAccount = r.issuerAccount
+
+	return claims.Encode(r.issuer)
+}
+
+// permissions executes the permission template with the identity and adds the identity's own permissions
+func (r *Responder) permissions(id Identity) (jwt.Permissions, error) {
+	var p jwt.Permissions
+	var err error
+
+	t := r.template
+	if len(t.Pub.Allow)+len(t.Pub.Deny)+len(t.Sub.Allow)+len(t.Sub.Deny) > 0 {
+		if err := checkIdentity(id); err != nil {
+			return p, err
+		}
+	}
+
+	if p.Pub.Allow, err = expand(r.template.Pub.Allow, id); err != nil {
+		return p, err
+	}
+	if p.Pub.Deny, err = expand(r.template.Pub.Deny, id); err != nil {
+		return p, err
+	}
+	if p.Sub.Allow, err = expand(r.template.Sub.Allow, id); err != nil {
+		return p, err
+	}
+	if p.Sub.Deny, err = expand(r.template.Sub.Deny, id); err != nil {
+		return p, err
+	}
+
+	p.Pub.Allow = append(p.Pub.Allow, id.Permissions.Pub.Allow...)
+	p.Pub.Deny = append(p.Pub.Deny, id.Permissions.Pub.Deny...)
+	p.Sub.Allow = append(p.Sub.Allow, id.Permissions.Sub.Allow...)
+	p.Sub.Deny = append(p.Sub.Deny, id.Permissions.Sub.Deny...)
+
+	return p, nil
+}
+
+// checkIdentity rejects identity values that would widen or break a templated subject, such as a name of "*",
+// "x.>", or ""
+func checkIdentity(id Identity) error {
+	if err := checkToken("Name", id.Name); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(id.Attributes))
+	for k := range id.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := checkToken("Attributes."+k, id.Attributes[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkToken(field, v string) error {
+	if v == "" || strings.ContainsAny(v, ".*>") || strings.IndexFunc(v, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%w: %s %q", ErrInvalidIdentity, field, v)
+	}
+
+	return nil
+}
+
+func expand(subjects []string, id Identity) ([]string, error) {
+	var out []string
+	for _, s := range subjects {
+		t, err := template.New("subject").Option("missingkey=error").Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing permission template %q: %w", s, err)
+		}
+
+		var b bytes.Buffer
+		if err := t.Execute(&b, id); err != nil {
+			return nil, fmt.Errorf("error executing permission template %q: %w", s, err)
+		}
+		out = append(out, b.String())
+	}
+
+	return out, nil
+}
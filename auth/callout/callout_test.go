@@ -0,0 +1,257 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func newServer(t *testing.T) (nkeys.KeyPair, string) {
+	t.Helper()
+	server, err := nkeys.CreateServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := server.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, pub
+}
+
+func authRequest(t *testing.T, server nkeys.KeyPair, token string, modify func(*jwt.AuthorizationRequestClaims)) (string, string) {
+	t.Helper()
+	user, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverPub, _ := server.PublicKey()
+	userPub, _ := user.PublicKey()
+	req := jwt.NewAuthorizationRequestClaims(userPub)
+	req.Audience = "nats-authorization-request"
+	req.Server = jwt.ServerID{ID: serverPub, Name: "test"}
+	req.UserNkey = userPub
+	req.ConnectOptions = jwt.ConnectOptions{Token: token}
+	if modify != nil {
+		modify(req)
+	}
+
+	token, err = req.Encode(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token, userPub
+}
+
+func testAuthenticator() Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, r jwt.AuthorizationRequest) (Identity, error) {
+		switch r.ConnectOptions.Token {
+		case "valid":
+			return Identity{Name: "alice", Account: "APP"}, nil
+		case "wildcard":
+			return Identity{Name: "*", Account: "APP"}, nil
+		case "empty":
+			return Identity{Account: "APP"}, nil
+		case "attribute":
+			return Identity{Name: "alice", Account: "APP", Attributes: map[string]string{"team": "ops.>"}}, nil
+		default:
+			return Identity{}, fmt.Errorf("invalid token")
+		}
+	})
+}
+
+func TestRespond(t *testing.T) {
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerPub, _ := issuer.PublicKey()
+
+	var logs bytes.Buffer
+	responder := NewResponder(issuer, testAuthenticator(), SetLogger(slog.New(slog.NewTextHandler(&logs, nil))), SetPermissionTemplate(PermissionTemplate{
+		Pub: jwt.Permission{Allow: []string{"users.{{ .Name }}.>"}},
+	}))
+
+	tt := []struct {
+		name   string
+		token  string
+		reason string
+		pub    jwt.StringList
+	}{
+		{name: "authorized", token: "valid", pub: jwt.StringList{"users.alice.>"}},
+		{name: "rejected", token: "nope", reason: "invalid token"},
+		{name: "wildcard name", token: "wildcard", reason: ErrInvalidIdentity.Error() + `: Name \"*\"`},
+		{name: "empty name", token: "empty", reason: ErrInvalidIdentity.Error() + `: Name \"\"`},
+		{name: "wildcard attribute", token: "attribute", reason: ErrInvalidIdentity.Error() + `: Attributes.team \"ops.>\"`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			logs.Reset()
+			server, serverPub := newServer(t)
+			req, userPub := authRequest(t, server, v.token, nil)
+			data, err := responder.Respond(context.Background(), []byte(req), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := jwt.DecodeAuthorizationResponseClaims(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Issuer != issuerPub || resp.Audience != serverPub || resp.Subject != userPub {
+				t.Errorf("unexpected response claims %+v", resp.ClaimsData)
+			}
+			if v.reason != "" {
+				// the reason is logged, since the server passes the response's error on to the client
+				if resp.Error != ErrNotAuthorized.Error() || !strings.Contains(logs.String(), v.reason) {
+					t.Fatalf("expected %q with %q logged but got %q and %s", ErrNotAuthorized, v.reason, resp.Error, logs.String())
+				}
+				return
+			}
+			if resp.Error != "" {
+				t.Fatalf("expected no error but got %q", resp.Error)
+			}
+
+			user, err := jwt.DecodeUserClaims(resp.Jwt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if user.Subject != userPub || user.Audience != "APP" || !reflect.DeepEqual(user.Pub.Allow, v.pub) {
+				t.Errorf("unexpected user claims %+v", user)
+			}
+			if user.Expires <= time.Now().Unix() {
+				t.Errorf("expected user jwt to expire in the future but got %d", user.Expires)
+			}
+		})
+	}
+}
+
+func TestRespondInvalidRequest(t *testing.T) {
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trusted, trustedPub := newServer(t)
+	other, _ := newServer(t)
+	_, otherPub := newServer(t)
+
+	tt := []struct {
+		name   string
+		server nkeys.KeyPair
+		modify func(*jwt.AuthorizationRequestClaims)
+		err    bool
+	}{
+		{name: "valid", server: trusted},
+		{name: "untrusted server", server: other, err: true},
+		{name: "issuer is not the server", server: trusted, modify: func(c *jwt.AuthorizationRequestClaims) { c.Server.ID = otherPub }, err: true},
+		{name: "expired", server: trusted, modify: func(c *jwt.AuthorizationRequestClaims) { c.Expires = time.Now().Add(-time.Minute).Unix() }, err: true},
+		{name: "not yet valid", server: trusted, modify: func(c *jwt.AuthorizationRequestClaims) { c.NotBefore = time.Now().Add(time.Hour).Unix() }, err: true},
+		{name: "missing user nkey", server: trusted, modify: func(c *jwt.AuthorizationRequestClaims) { c.UserNkey = "" }, err: true},
+	}
+
+	responder := NewResponder(issuer, testAuthenticator(), SetTrustedServers(trustedPub))
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			req, _ := authRequest(t, v.server, "valid", v.modify)
+			_, err := responder.Respond(context.Background(), []byte(req), "")
+			if v.err != errors.Is(err, ErrInvalidRequest) {
+				t.Errorf("expected invalid request %t but got %v", v.err, err)
+			}
+		})
+	}
+
+	t.Run("bad signature", func(t *testing.T) {
+		req, _ := authRequest(t, trusted, "valid", nil)
+		other, _ := authRequest(t, trusted, "valid", nil)
+		forged := req[:strings.LastIndex(req, ".")] + other[strings.LastIndex(other, "."):]
+		_, err := responder.Respond(context.Background(), []byte(forged), "")
+		if !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("expected invalid request but got %v", err)
+		}
+	})
+}
+
+func TestRespondXKey(t *testing.T) {
+	issuer, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xkey, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xkeyPub, _ := xkey.PublicKey()
+	serverX, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverXPub, _ := serverX.PublicKey()
+
+	server, _ := newServer(t)
+	req, userPub := authRequest(t, server, "valid", func(c *jwt.AuthorizationRequestClaims) { c.Server.XKey = serverXPub })
+	sealed, err := serverX.Seal([]byte(req), xkeyPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewResponder(issuer, testAuthenticator()).Respond(context.Background(), sealed, serverXPub); err == nil {
+		t.Error("expected an error without an xkey")
+	}
+
+	data, err := NewResponder(issuer, testAuthenticator(), SetXKey(xkey)).Respond(context.Background(), sealed, serverXPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := jwt.DecodeAuthorizationResponseClaims(string(data)); err == nil {
+		t.Fatal("expected the response to be encrypted")
+	}
+
+	opened, err := serverX.Open(data, xkeyPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := jwt.DecodeAuthorizationResponseClaims(string(opened))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := jwt.DecodeUserClaims(resp.Jwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Subject != userPub {
+		t.Errorf("expected user %s but got %s", userPub, user.Subject)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callout
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// FetchFunc obtains a credential, usually a token from an identity provider, and when it expires
+type FetchFunc func(context.Context) (string, time.Time, error)
+
+// TokenSourceOpt is a functional option to modify the TokenSource
+type TokenSourceOpt func(*TokenSource)
+
+// TokenSource caches a credential for services connecting through auth callout and refreshes it before it expires.
+// The credential is presented as the connect token, which the Responder receives in ConnectOptions.Token.
+type TokenSource struct {
+	fetch   FetchFunc
+	early   time.Duration
+	timeout time.Duration
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenSource returns a TokenSource that refreshes one minute before expiry
+func NewTokenSource(fetch FetchFunc, opts ...TokenSourceOpt) *TokenSource {
+	t := &TokenSource{
+		fetch:   fetch,
+		early:   time.Minute,
+		timeout: 10 * time.Second,
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// SetRefreshBefore sets how long before expiry the credential is refreshed
+func SetRefreshBefore(d time.Duration) TokenSourceOpt {
+	return func(t *TokenSource) {
+		t.early = d
+	}
+}
+
+// SetFetchTimeout sets the deadline for a single fetch
+func SetFetchTimeout(d time.Duration) TokenSourceOpt {
+	return func(t *TokenSource) {
+		t.timeout = d
+	}
+}
+
+// SetTokenLogger sets the logger used to report refresh failures
+func SetTokenLogger(l *slog.Logger) TokenSourceOpt {
+	return func(t *TokenSource) {
+		t.logger = l
+	}
+}
+
+// Token returns the cached credential, fetching a new one if it is missing or about to expire
+func (t *TokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && (t.expires.IsZero() || time.Until(t.expires) > t.early) {
+		return t.token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	token, expires, err := t.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error fetching credentials: %w", err)
+	}
+
+	t.token = token
+	t.expires = expires
+
+	return token, nil
+}
+
+// Option returns a nats.Option that presents a fresh credential every time the client connects or reconnects.
+// If a refresh fails the last credential is used so the server can report the authorization error.
+func (t *TokenSource) Option() nats.Option {
+	return nats.TokenHandler(func() string {
+		token, err := t.Token(context.Background())
+		if err != nil {
+			t.logger.Error(err.Error())
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return t.token
+		}
+
+		return token
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache keeps the results of expensive reads. Entries record when they were stored so callers decide
+// how stale a value they accept.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var ErrNotFound = fmt.Errorf("cache entry not found")
+
+// Entry is a cached value
+type Entry struct {
+	Value  []byte    `json:"value"`
+	Stored time.Time `json:"stored"`
+}
+
+// Age returns how long ago the entry was stored
+func (e Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.Stored)
+}
+
+// Cache keeps entries by key. Get returns an error wrapping ErrNotFound for missing keys.
+type Cache interface {
+	Get(ctx context.Context, key string) (Entry, error)
+	Set(ctx context.Context, key string, e Entry) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryItem struct {
+	key   string
+	entry Entry
+}
+
+// Memory is a Cache local to the instance that evicts the least recently used entry when it is full
+type Memory struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+// NewMemory returns a Memory cache holding up to size entries. Zero or less doesn't limit it.
+func NewMemory(size int) *Memory {
+	return &Memory{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) (Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	m.order.MoveToFront(el)
+
+	return el.Value.(*memoryItem).entry, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryItem).entry = e
+		m.order.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, entry: e})
+	if m.size > 0 && m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryItem).key)
+	}
+
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.order.Remove(el)
+		delete(m.items, key)
+	}
+
+	return nil
+}
+
+// KV is a Cache shared by every instance of a service. Keys are hashed since cache keys like URLs aren't valid
+// KV keys, so give the bucket a TTL to expire entries.
+type KV struct {
+	kv nats.KeyValue
+}
+
+// NewKV returns a cache backed by kv
+func NewKV(kv nats.KeyValue) *KV {
+	return &KV{kv: kv}
+}
+
+func kvKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *KV) Get(ctx context.Context, key string) (Entry, error) {
+	var e Entry
+	entry, err := c.kv.Get(kvKey(key))
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return e, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return e, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &e)
+	return e, err
+}
+
+func (c *KV) Set(ctx context.Context, key string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = c.kv.Put(kvKey(key), data)
+	return err
+}
+
+func (c *KV) Delete(ctx context.Context, key string) error {
+	return c.kv.Delete(kvKey(key))
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+)
+
+func TestCaches(t *testing.T) {
+	tt := []struct {
+		name  string
+		cache Cache
+	}{
+		{name: "memory", cache: NewMemory(10)},
+		{name: "kv", cache: NewKV(kvtest.New())},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "/api/v1/posts?page=2"
+			stored := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+
+			if _, err := v.cache.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected %v but got %v", ErrNotFound, err)
+			}
+			if err := v.cache.Set(ctx, key, Entry{Value: []byte("posts"), Stored: stored}); err != nil {
+				t.Fatal(err)
+			}
+
+			e, err := v.cache.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(e.Value) != "posts" || !e.Stored.Equal(stored) {
+				t.Errorf("expected the stored entry but got %+v", e)
+			}
+			if age := e.Age(stored.Add(time.Minute)); age != time.Minute {
+				t.Errorf("expected an age of 1m but got %s", age)
+			}
+
+			if err := v.cache.Delete(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.cache.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected %v after delete but got %v", ErrNotFound, err)
+			}
+		})
+	}
+}
+
+func TestMemoryEviction(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	m.Set(ctx, "a", Entry{Value: []byte("a")})
+	m.Set(ctx, "b", Entry{Value: []byte("b")})
+	// reading a makes b the least recently used
+	if _, err := m.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	m.Set(ctx, "c", Entry{Value: []byte("c")})
+
+	if _, err := m.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected b to be evicted but got %v", err)
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, err := m.Get(ctx, k); err != nil {
+			t.Errorf("expected %s to be kept but got %v", k, err)
+		}
+	}
+}
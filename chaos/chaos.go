@@ -0,0 +1,283 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chaos injects faults into HTTP and NATS handlers for resilience testing. The faults are described
+// by a Policy that is usually stored as JSON in a KV key so experiments can be started and stopped without a
+// deploy.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Header is set on responses that had a fault injected
+const Header = "Sencillo-Chaos"
+
+var ErrInvalidStatus = fmt.Errorf("fault error must be a status code from 100 to 599")
+
+// Duration is a time.Duration written as a string like "250ms" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+
+	return nil
+}
+
+// Fault is a single experiment. It applies to HTTP requests whose path matches Path and NATS requests whose
+// subject matches Subject. An empty Path or Subject matches everything.
+type Fault struct {
+	// Path is a path.Match pattern like /api/orders/*
+	Path string `json:"path,omitempty"`
+	// Subject is a NATS subject that can contain * and > wildcards
+	Subject string `json:"subject,omitempty"`
+	// Percent of matching requests the fault is injected into
+	Percent float64 `json:"percent"`
+	// Latency is added before the request is handled
+	Latency Duration `json:"latency,omitempty"`
+	// Error is the status code returned instead of handling the request
+	Error int `json:"error,omitempty"`
+	// Drop handles nothing and sends no response, so callers time out
+	Drop bool `json:"drop,omitempty"`
+	// Start and End limit the fault to a time window
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+func (f Fault) active(now time.Time) bool {
+	return (f.Start == nil || !now.Before(*f.Start)) && (f.End == nil || now.Before(*f.End))
+}
+
+// Policy is the set of faults a Controller injects. Faults are checked in order and the first match applies.
+type Policy struct {
+	Enabled bool    `json:"enabled"`
+	Faults  []Fault `json:"faults"`
+}
+
+// Validate checks that every fault's Error is a status code that can be written
+func (p Policy) Validate() error {
+	for i, f := range p.Faults {
+		if f.Error != 0 && (f.Error < 100 || f.Error > 599) {
+			return fmt.Errorf("fault %d: %w, got %d", i, ErrInvalidStatus, f.Error)
+		}
+	}
+	return nil
+}
+
+// ControllerOpt is a functional option to modify the Controller
+type ControllerOpt func(*Controller)
+
+// Controller holds the current policy and injects its faults
+type Controller struct {
+	policy atomic.Pointer[Policy]
+	logger *slog.Logger
+	roll   func() float64
+	now    func() time.Time
+}
+
+// NewController returns a Controller with chaos disabled until a policy is set
+func NewController(opts ...ControllerOpt) *Controller {
+	c := &Controller{
+		logger: slog.Default(),
+		roll:   rand.Float64,
+		now:    time.Now,
+	}
+	c.policy.Store(&Policy{})
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// SetLogger sets the logger used to report policy changes
+func SetLogger(l *slog.Logger) ControllerOpt {
+	return func(c *Controller) {
+		c.logger = l
+	}
+}
+
+// Set replaces the policy
+func (c *Controller) Set(p Policy) {
+	c.policy.Store(&p)
+}
+
+// Policy returns the current policy
+func (c *Controller) Policy() Policy {
+	return *c.policy.Load()
+}
+
+// Watch keeps the policy in sync with the JSON stored at key until the context is cancelled. Deleting the key
+// disables chaos. Invalid policies are logged and the previous one is kept.
+func (c *Controller) Watch(ctx context.Context, kv nats.KeyValue, key string) error {
+	w, err := kv.Watch(key, nats.Context(ctx))
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.Updates():
+			if !ok {
+				return nil
+			}
+			// nil marks the end of the initial values
+			if e == nil {
+				continue
+			}
+			c.apply(e.Operation(), e.Value())
+		}
+	}
+}
+
+func (c *Controller) apply(op nats.KeyValueOp, value []byte) {
+	if op != nats.KeyValuePut {
+		c.Set(Policy{})
+		c.logger.Info("chaos policy removed")
+		return
+	}
+
+	var p Policy
+	if err := json.Unmarshal(value, &p); err != nil {
+		c.logger.Error(fmt.Sprintf("invalid chaos policy: %v", err))
+		return
+	}
+	if err := p.Validate(); err != nil {
+		c.logger.Error(fmt.Sprintf("invalid chaos policy: %v", err))
+		return
+	}
+
+	c.Set(p)
+	c.logger.Info(fmt.Sprintf("chaos policy updated: enabled=%v faults=%d", p.Enabled, len(p.Faults)))
+}
+
+// fault returns the fault to inject, if any
+func (c *Controller) fault(match func(Fault) bool) (Fault, bool) {
+	p := c.policy.Load()
+	if !p.Enabled {
+		return Fault{}, false
+	}
+
+	now := c.now()
+	for _, f := range p.Faults {
+		if !f.active(now) || !match(f) {
+			continue
+		}
+		if c.roll()*100 >= f.Percent {
+			return Fault{}, false
+		}
+		return f, true
+	}
+
+	return Fault{}, false
+}
+
+// sleep waits for the latency or until the context is done
+func sleep(ctx context.Context, d Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(d))
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Middleware injects faults into HTTP requests. Dropped requests abort the connection without a response.
+func (c *Controller) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := c.fault(func(f Fault) bool { return f.Path == "" || matchPath(f.Path, r.URL.Path) })
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set(Header, "injected")
+		if err := sleep(r.Context(), f.Latency); err != nil {
+			return
+		}
+
+		switch {
+		case f.Drop:
+			panic(http.ErrAbortHandler)
+		case f.Error != 0:
+			http.Error(w, "chaos: injected fault", f.Error)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Handler injects faults into a micro handler. Dropped requests are never responded to.
+func (c *Controller) Handler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		f, ok := c.fault(func(f Fault) bool { return f.Subject == "" || sdnats.MatchSubject(f.Subject, r.Subject()) })
+		if !ok {
+			h.Handle(r)
+			return
+		}
+
+		sleep(context.Background(), f.Latency)
+
+		switch {
+		case f.Drop:
+		case f.Error != 0:
+			r.Error(strconv.Itoa(f.Error), "chaos: injected fault", nil, micro.WithHeaders(micro.Headers{Header: []string{"injected"}}))
+		default:
+			h.Handle(r)
+		}
+	})
+}
+
+// matchPath reports whether the path matches the path.Match pattern
+func matchPath(pattern, p string) bool {
+	ok, err := path.Match(pattern, p)
+	return err == nil && ok
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tt := []struct {
+		name    string
+		policy  Policy
+		path    string
+		code    int
+		dropped bool
+		slow    bool
+	}{
+		{name: "disabled", policy: Policy{Faults: []Fault{{Percent: 100, Error: 503}}}, path: "/orders", code: 200},
+		{name: "error", policy: Policy{Enabled: true, Faults: []Fault{{Percent: 100, Error: 503}}}, path: "/orders", code: 503},
+		{name: "other path", policy: Policy{Enabled: true, Faults: []Fault{{Path: "/users/*", Percent: 100, Error: 503}}}, path: "/orders", code: 200},
+		{name: "sampled out", policy: Policy{Enabled: true, Faults: []Fault{{Percent: 10, Error: 503}}}, path: "/orders", code: 200},
+		{name: "window ended", policy: Policy{Enabled: true, Faults: []Fault{{Percent: 100, Error: 503, End: &before}}}, path: "/orders", code: 200},
+		{name: "window", policy: Policy{Enabled: true, Faults: []Fault{{Percent: 100, Error: 500, Start: &before, End: &after}}}, path: "/orders", code: 500},
+		{name: "latency", policy: Policy{Enabled: true, Faults: []Fault{{Percent: 100, Latency: Duration(50 * time.Millisecond)}}}, path: "/orders", code: 200, slow: true},
+		{name: "drop", policy: Policy{Enabled: true, Faults: []Fault{{Path: "/orders", Percent: 100, Drop: true}}}, path: "/orders", dropped: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			c := NewController()
+			c.roll = func() float64 { return 0.5 }
+			c.now = func() time.Time { return now }
+			c.Set(v.policy)
+
+			srv := httptest.NewServer(c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+			defer srv.Close()
+
+			start := time.Now()
+			resp, err := srv.Client().Get(srv.URL + v.path)
+			if v.dropped {
+				if err == nil {
+					t.Fatalf("expected the response to be dropped but got %d", resp.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != v.code {
+				t.Errorf("expected %d but got %d", v.code, resp.StatusCode)
+			}
+			if slow := time.Since(start) >= 50*time.Millisecond; slow != v.slow {
+				t.Errorf("expected slow=%v but took %s", v.slow, time.Since(start))
+			}
+		})
+	}
+}
+
+type fakeRequest struct {
+	micro.Request
+	subject string
+	code    string
+	handled bool
+}
+
+func (f *fakeRequest) Subject() string { return f.subject }
+
+func (f *fakeRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	f.code = code
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	c := NewController()
+	c.Set(Policy{Enabled: true, Faults: []Fault{
+		{Subject: "orders.drop", Percent: 100, Drop: true},
+		{Subject: "orders.*", Percent: 100, Error: 503},
+	}})
+
+	tt := []struct {
+		subject string
+		handled bool
+		code    string
+	}{
+		{subject: "orders.create", code: "503"},
+		{subject: "orders.drop"},
+		{subject: "users.create", handled: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.subject, func(t *testing.T) {
+			r := &fakeRequest{subject: v.subject}
+			c.Handler(micro.HandlerFunc(func(micro.Request) { r.handled = true })).Handle(r)
+
+			if r.handled != v.handled || r.code != v.code {
+				t.Errorf("expected handled=%v code=%q but got handled=%v code=%q", v.handled, v.code, r.handled, r.code)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	c := NewController()
+
+	c.apply(nats.KeyValuePut, []byte(`{"enabled": true, "faults": [{"subject": "orders.>", "percent": 5, "latency": "250ms"}]}`))
+	p := c.Policy()
+	if !p.Enabled || len(p.Faults) != 1 || time.Duration(p.Faults[0].Latency) != 250*time.Millisecond {
+		t.Fatalf("unexpected policy %+v", p)
+	}
+
+	c.apply(nats.KeyValuePut, []byte(`{"enabled": true, "faults": [{"latency": "soon"}]}`))
+	if got := c.Policy(); len(got.Faults) != 1 || got.Faults[0].Subject != "orders.>" {
+		t.Errorf("expected an invalid policy to keep the previous one but got %+v", got)
+	}
+
+	for _, code := range []int{42, 600, -1} {
+		c.apply(nats.KeyValuePut, []byte(fmt.Sprintf(`{"enabled": true, "faults": [{"percent": 100, "error": %d}]}`, code)))
+		if got := c.Policy(); len(got.Faults) != 1 || got.Faults[0].Error != 0 {
+			t.Errorf("expected error %d to be rejected but got %+v", code, got)
+		}
+	}
+
+	c.apply(nats.KeyValueDelete, nil)
+	if c.Policy().Enabled {
+		t.Error("expected deleting the policy to disable chaos")
+	}
+
+	b, err := json.Marshal(Fault{Latency: Duration(time.Second)})
+	if err != nil || string(b) != `{"percent":0,"latency":"1s"}` {
+		t.Errorf("unexpected json %s: %v", b, err)
+	}
+}
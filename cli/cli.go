@@ -0,0 +1,153 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// App is the root command of a service's ctl binary. It loads the config file and environment and binds the
+// flags of the command being run when that command runs.
+type App struct {
+	root       *cobra.Command
+	name       string
+	cfgFile    string
+	configType string
+	config     any
+	viper      *viper.Viper
+}
+
+type AppOpt func(*App)
+
+// NewApp creates the root command for the named service. The binary is named <name>ctl, environment
+// variables are prefixed with the name, and the config file defaults to $HOME/.<name>.json.
+func NewApp(name string, opts ...AppOpt) *App {
+	a := &App{
+		name:       name,
+		configType: "json",
+		viper:      viper.GetViper(),
+	}
+
+	a.root = &cobra.Command{
+		Use:               name + "ctl",
+		PersistentPreRunE: a.preRun,
+	}
+	a.root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return app.UsageError(err)
+	})
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.root.PersistentFlags().StringVar(&a.cfgFile, "config", "", fmt.Sprintf("config file (default is $HOME/.%s.%s)", name, a.configType))
+
+	return a
+}
+
+// SetShort sets the description shown in the root command's help
+func SetShort(s string) AppOpt {
+	return func(a *App) {
+		a.root.Short = s
+	}
+}
+
+// SetConfig sets a struct the config is unmarshalled into before a command runs, using mapstructure tags
+func SetConfig(dst any) AppOpt {
+	return func(a *App) {
+		a.config = dst
+	}
+}
+
+// SetConfigType sets the format of the default config file
+func SetConfigType(t string) AppOpt {
+	return func(a *App) {
+		a.configType = t
+	}
+}
+
+// SetViper sets the viper instance config is loaded into. The global instance is used by default.
+func SetViper(v *viper.Viper) AppOpt {
+	return func(a *App) {
+		a.viper = v
+	}
+}
+
+// Root returns the root command
+func (a *App) Root() *cobra.Command {
+	return a.root
+}
+
+// AddCommand adds subcommands to the root command. Subcommands should not set PersistentPreRun since it
+// replaces the app's flag binding.
+func (a *App) AddCommand(cmds ...*cobra.Command) {
+	a.root.AddCommand(cmds...)
+}
+
+// Execute runs the command selected by the arguments
+func (a *App) Execute() error {
+	return a.root.Execute()
+}
+
+// preRun loads the config for the command. Errors are classified as config errors so app.Fatal exits with
+// app.ExitConfig.
+func (a *App) preRun(cmd *cobra.Command, args []string) error {
+	if err := a.loadConfig(); err != nil {
+		return app.ConfigError(err)
+	}
+
+	if err := bindFlags(a.viper, cmd); err != nil {
+		return app.ConfigError(err)
+	}
+
+	if a.config == nil {
+		return nil
+	}
+
+	return app.ConfigError(a.viper.Unmarshal(a.config))
+}
+
+func (a *App) loadConfig() error {
+	v := a.viper
+	if a.cfgFile != "" {
+		v.SetConfigFile(a.cfgFile)
+	} else {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			v.AddConfigPath(home)
+		}
+		v.SetConfigType(a.configType)
+		v.SetConfigName("." + a.name)
+	}
+
+	v.SetEnvPrefix(strings.ReplaceAll(a.name, "-", "_"))
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+
+	err := v.ReadInConfig()
+	var notFound viper.ConfigFileNotFoundError
+	if err != nil && (a.cfgFile != "" || !errors.As(err, &notFound)) {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdapp "github.com/SencilloDev/sencillo-go/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type testConfig struct {
+	Port     int    `mapstructure:"port"`
+	NatsURLs string `mapstructure:"nats_urls"`
+	Name     string `mapstructure:"name"`
+}
+
+// newTestApp has two subcommands that define the same flag with different defaults
+func newTestApp(cfg *testConfig) *App {
+	app := NewApp("demo", SetViper(viper.New()), SetConfig(cfg))
+
+	svc := &cobra.Command{Use: "service"}
+	AddFlags(svc, NATSFlags()...)
+	AddFlags(svc, HTTPFlags()...)
+	svc.AddCommand(ServeCommand(func(*cobra.Command, []string) error { return nil }))
+
+	admin := &cobra.Command{Use: "admin", RunE: func(*cobra.Command, []string) error { return nil }}
+	AddFlags(admin, Flag{Name: "port", Default: 9090, Usage: "Admin port"})
+
+	app.AddCommand(svc, admin, VersionCommand("v1.2.3"))
+	return app
+}
+
+func TestApp(t *testing.T) {
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "demo.json")
+	if err := os.WriteFile(cfgFile, []byte(`{"name": "from-file", "port": 7000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want testConfig
+	}{
+		{name: "defaults", args: []string{"service", "start"}, want: testConfig{Port: 8080, NatsURLs: "nats://localhost:4222"}},
+		{name: "flag", args: []string{"service", "serve", "-p", "8000"}, want: testConfig{Port: 8000, NatsURLs: "nats://localhost:4222"}},
+		{name: "other command default", args: []string{"admin"}, want: testConfig{Port: 9090}},
+		{name: "env", args: []string{"service", "start"}, env: map[string]string{"DEMO_NATS_URLS": "nats://nats:4222"}, want: testConfig{Port: 8080, NatsURLs: "nats://nats:4222"}},
+		{name: "config file", args: []string{"admin", "--config", cfgFile}, want: testConfig{Port: 7000, Name: "from-file"}},
+		{name: "flag over config file", args: []string{"admin", "--config", cfgFile, "--port", "7001"}, want: testConfig{Port: 7001, Name: "from-file"}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for k, val := range v.env {
+				t.Setenv(k, val)
+			}
+
+			var cfg testConfig
+			app := newTestApp(&cfg)
+			app.Root().SetArgs(v.args)
+			if err := app.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg != v.want {
+				t.Errorf("expected %+v but got %+v", v.want, cfg)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	var cfg testConfig
+	app := newTestApp(&cfg)
+
+	var out bytes.Buffer
+	app.Root().SetOut(&out)
+	app.Root().SetArgs([]string{"version"})
+	if err := app.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "v1.2.3\n" {
+		t.Errorf("expected version but got %q", out.String())
+	}
+
+	var ran []string
+	migrate := MigrateCommand(
+		func(context.Context) error { ran = append(ran, "up"); return nil },
+		nil,
+	)
+	app.AddCommand(migrate)
+	app.Root().SetArgs([]string{"migrate", "up"})
+	if err := app.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 1 {
+		t.Errorf("expected up to run but got %v", ran)
+	}
+	if len(migrate.Commands()) != 1 {
+		t.Errorf("expected down to be left out")
+	}
+
+	dir := t.TempDir()
+	app.AddCommand(DocsCommand(dir))
+	app.Root().SetArgs([]string{"docs"})
+	if err := app.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "democtl_service_start.md")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tt := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "ok", args: []string{"admin"}, want: sdapp.ExitOK},
+		{name: "missing config file", args: []string{"admin", "--config", filepath.Join(t.TempDir(), "missing.json")}, want: sdapp.ExitConfig},
+		{name: "unknown flag", args: []string{"admin", "--nope"}, want: sdapp.ExitUsage},
+		{name: "invalid flag value", args: []string{"admin", "--port", "abc"}, want: sdapp.ExitUsage},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var cfg testConfig
+			app := newTestApp(&cfg)
+			app.Root().SetArgs(v.args)
+			app.Root().SetOut(&bytes.Buffer{})
+			app.Root().SetErr(&bytes.Buffer{})
+
+			if got := sdapp.ExitCode(app.Execute()); got != v.want {
+				t.Errorf("expected exit code %d but got %d", v.want, got)
+			}
+		})
+	}
+}
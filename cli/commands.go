@@ -0,0 +1,92 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// VersionCommand prints the version
+func VersionCommand(version string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Prints the version",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintln(cmd.OutOrStdout(), version)
+		},
+	}
+}
+
+// DocsCommand writes markdown documentation for the whole command tree to dir
+func DocsCommand(dir string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "docs",
+		Short: "Generate cli documentation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+
+			return doc.GenMarkdownTree(cmd.Root(), dir)
+		},
+	}
+}
+
+// ServeCommand runs the service. It is named start, with serve as an alias.
+func ServeCommand(run func(cmd *cobra.Command, args []string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:          "start",
+		Aliases:      []string{"serve"},
+		Short:        "starts the service",
+		RunE:         run,
+		SilenceUsage: true,
+	}
+}
+
+// MigrateCommand has up and down subcommands that run the migrations. The down subcommand is left out if down
+// is nil.
+func MigrateCommand(up, down func(ctx context.Context) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "runs database migrations",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:          "up",
+		Short:        "applies pending migrations",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return up(cmd.Context())
+		},
+	})
+
+	if down != nil {
+		cmd.AddCommand(&cobra.Command{
+			Use:          "down",
+			Short:        "rolls back migrations",
+			SilenceUsage: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return down(cmd.Context())
+			},
+		})
+	}
+
+	return cmd
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// keyAnnotation marks flags added with AddFlags with the config key they are bound to
+const keyAnnotation = "sencillo_config_key"
+
+// Flag describes a flag and the config key it sets
+type Flag struct {
+	Name      string
+	Shorthand string
+	// Key is the config key the flag is bound to. It defaults to the name with dashes replaced by underscores.
+	Key   string
+	Usage string
+	// Default is the default value and sets the flag's type. It must be a string, int, bool, []string,
+	// time.Duration, or nil for a string flag.
+	Default any
+}
+
+func (f Flag) key() string {
+	if f.Key != "" {
+		return f.Key
+	}
+
+	return strings.ReplaceAll(f.Name, "-", "_")
+}
+
+// AddFlags adds persistent flags to the command. They are bound to their config keys only when the command
+// or one of its subcommands runs, so commands can define the same flag with different defaults without
+// overwriting each other's values.
+func AddFlags(cmd *cobra.Command, flags ...Flag) {
+	fs := cmd.PersistentFlags()
+	for _, f := range flags {
+		switch d := f.Default.(type) {
+		case nil:
+			fs.StringP(f.Name, f.Shorthand, "", f.Usage)
+		case string:
+			fs.StringP(f.Name, f.Shorthand, d, f.Usage)
+		case int:
+			fs.IntP(f.Name, f.Shorthand, d, f.Usage)
+		case bool:
+			fs.BoolP(f.Name, f.Shorthand, d, f.Usage)
+		case []string:
+			fs.StringSliceP(f.Name, f.Shorthand, d, f.Usage)
+		case time.Duration:
+			fs.DurationP(f.Name, f.Shorthand, d, f.Usage)
+		default:
+			panic(fmt.Sprintf("cli: unsupported default %T for flag %s", f.Default, f.Name))
+		}
+
+		fs.SetAnnotation(f.Name, keyAnnotation, []string{f.key()})
+	}
+}
+
+// bindFlags binds the flags added with AddFlags that the command can see, including inherited ones
+func bindFlags(v *viper.Viper, cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		key, ok := f.Annotations[keyAnnotation]
+		if !ok || err != nil {
+			return
+		}
+		err = v.BindPFlag(key[0], f)
+	})
+
+	return err
+}
+
+// NATSFlags are the flags for connecting to NATS
+func NATSFlags() []Flag {
+	return []Flag{
+		{Name: "nats-urls", Default: "nats://localhost:4222", Usage: "NATS URLs"},
+		{Name: "nats-jwt", Usage: "NATS JWT as a string"},
+		{Name: "nats-seed", Usage: "NATS seed as a string"},
+		{Name: "credentials-file", Usage: "Path to NATS user credentials file"},
+		{Name: "use-traffic-shaping", Default: false, Usage: "Local development connection"},
+	}
+}
+
+// HTTPFlags are the flags for the HTTP server
+func HTTPFlags() []Flag {
+	return []Flag{
+		{Name: "port", Shorthand: "p", Default: 8080, Usage: "Server port"},
+	}
+}
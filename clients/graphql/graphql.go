@@ -16,10 +16,13 @@ package graphql
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/retry"
 )
 
 type ClientOption func(*GraphQLClient)
@@ -27,6 +30,7 @@ type ClientOption func(*GraphQLClient)
 type GraphQLClient struct {
 	client *http.Client
 	URL    string
+	retry  retry.Policy
 }
 
 type gqlResponse struct {
@@ -68,6 +72,7 @@ func NewGraphQLClient(url string, opts ...ClientOption) *GraphQLClient {
 	c := &GraphQLClient{
 		client: http.DefaultClient,
 		URL:    url,
+		retry:  retry.NewPolicy(retry.SetMaxAttempts(1)),
 	}
 
 	for _, opt := range opts {
@@ -83,11 +88,23 @@ func SetHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// SetRetryPolicy retries requests that fail with a transport error or a 5xx/429 status
+func SetRetryPolicy(p retry.Policy) ClientOption {
+	return func(c *GraphQLClient) {
+		c.retry = p
+	}
+}
+
 func (g *GraphQLClient) newPostRequest(url string, data []byte) ([]byte, error) {
+	return retry.DoValue(context.Background(), g.retry, func(ctx context.Context) ([]byte, error) {
+		return g.post(ctx, url, data)
+	})
+}
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+func (g *GraphQLClient) post(ctx context.Context, url string, data []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, retry.Permanent(err)
 	}
 
 	req.Header.Add("Content-Type", "application/json")
@@ -96,6 +113,7 @@ func (g *GraphQLClient) newPostRequest(url string, data []byte) ([]byte, error)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -103,11 +121,15 @@ func (g *GraphQLClient) newPostRequest(url string, data []byte) ([]byte, error)
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("error: %v", string(body))
+		err := fmt.Errorf("error: %v", string(body))
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, err
+		}
+		return nil, retry.Permanent(err)
 	}
 
 	if err := handleGraphQLErrors(body); err != nil {
-		return nil, err
+		return nil, retry.Permanent(err)
 	}
 
 	return body, nil
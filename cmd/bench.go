@@ -0,0 +1,414 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/fatih/color"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Drives load against a NATS subject or HTTP endpoint",
+	Long: `Sends requests from concurrent workers for a duration and reports throughput, error rate, and latency
+percentiles. Payloads are templates that can use {{ .Seq }}, {{ .Worker }}, {{ UUID }}, and {{ RandInt 1 100 }}.
+Results can be saved with --save and compared against a saved baseline with --compare.`,
+	RunE:         bench,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().String("subject", "", "NATS subject to send requests to")
+	viper.BindPFlag("bench.subject", benchCmd.Flags().Lookup("subject"))
+	benchCmd.Flags().String("url", "", "HTTP URL to send requests to")
+	viper.BindPFlag("bench.url", benchCmd.Flags().Lookup("url"))
+	benchCmd.Flags().String("method", "GET", "HTTP method")
+	viper.BindPFlag("bench.method", benchCmd.Flags().Lookup("method"))
+	benchCmd.Flags().StringArrayP("header", "H", nil, "Header to send as Key: Value, can be repeated")
+	viper.BindPFlag("bench.headers", benchCmd.Flags().Lookup("header"))
+	benchCmd.Flags().String("payload", "", "Payload template")
+	viper.BindPFlag("bench.payload", benchCmd.Flags().Lookup("payload"))
+	benchCmd.Flags().String("payload-file", "", "File containing the payload template")
+	viper.BindPFlag("bench.payload_file", benchCmd.Flags().Lookup("payload-file"))
+	benchCmd.Flags().IntP("concurrency", "c", 10, "Number of concurrent workers")
+	viper.BindPFlag("bench.concurrency", benchCmd.Flags().Lookup("concurrency"))
+	benchCmd.Flags().Duration("duration", 10*time.Second, "How long to send requests")
+	viper.BindPFlag("bench.duration", benchCmd.Flags().Lookup("duration"))
+	benchCmd.Flags().Int("requests", 0, "Stop after this many requests, 0 for no limit")
+	viper.BindPFlag("bench.requests", benchCmd.Flags().Lookup("requests"))
+	benchCmd.Flags().Duration("timeout", 5*time.Second, "Timeout for a single request")
+	viper.BindPFlag("bench.timeout", benchCmd.Flags().Lookup("timeout"))
+	benchCmd.Flags().String("save", "", "Write the results as JSON to this file")
+	viper.BindPFlag("bench.save", benchCmd.Flags().Lookup("save"))
+	benchCmd.Flags().String("compare", "", "Compare the results with a baseline saved with --save")
+	viper.BindPFlag("bench.compare", benchCmd.Flags().Lookup("compare"))
+	benchCmd.Flags().Float64("threshold", 10, "Percent a latency percentile can grow over the baseline before it counts as a regression")
+	viper.BindPFlag("bench.threshold", benchCmd.Flags().Lookup("threshold"))
+	benchCmd.Flags().String("nats-urls", nats.DefaultURL, "NATS server urls")
+	viper.BindPFlag("bench.nats_urls", benchCmd.Flags().Lookup("nats-urls"))
+	benchCmd.Flags().String("nats-jwt", "", "NATS user JWT")
+	viper.BindPFlag("bench.nats_jwt", benchCmd.Flags().Lookup("nats-jwt"))
+	benchCmd.Flags().String("nats-seed", "", "NATS user seed")
+	viper.BindPFlag("bench.nats_seed", benchCmd.Flags().Lookup("nats-seed"))
+	benchCmd.Flags().String("credentials-file", "", "NATS credentials file")
+	viper.BindPFlag("bench.credentials_file", benchCmd.Flags().Lookup("credentials-file"))
+}
+
+// BenchResult is the summary of a bench run
+type BenchResult struct {
+	Target    string        `json:"target"`
+	Requests  int           `json:"requests"`
+	Errors    int           `json:"errors"`
+	Duration  time.Duration `json:"duration"`
+	RPS       float64       `json:"rps"`
+	ErrorRate float64       `json:"error_rate"`
+	Mean      time.Duration `json:"mean"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// payloadData is passed to payload templates
+type payloadData struct {
+	Seq    int64
+	Worker int
+}
+
+// sendFunc sends a single request
+type sendFunc func(ctx context.Context, payload []byte) error
+
+func bench(cmd *cobra.Command, args []string) error {
+	subject, target := viper.GetString("bench.subject"), viper.GetString("bench.url")
+	if (subject == "") == (target == "") {
+		return fmt.Errorf("set exactly one of --subject or --url")
+	}
+
+	payload := viper.GetString("bench.payload")
+	if f := viper.GetString("bench.payload_file"); f != "" {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		payload = string(b)
+	}
+
+	tmpl, err := payloadTemplate(payload)
+	if err != nil {
+		return err
+	}
+
+	var send sendFunc
+	if subject != "" {
+		nc, problems := connectNATS(
+			viper.GetString("bench.nats_urls"),
+			viper.GetString("bench.nats_jwt"),
+			viper.GetString("bench.nats_seed"),
+			viper.GetString("bench.credentials_file"),
+		)
+		if len(problems) > 0 {
+			return fmt.Errorf("%s", problems[0].Message)
+		}
+		defer nc.Close()
+		send = natsSender(nc, subject)
+		target = subject
+	} else {
+		headers, err := parseHeaders(viper.GetStringSlice("bench.headers"))
+		if err != nil {
+			return err
+		}
+		send = httpSender(http.DefaultClient, viper.GetString("bench.method"), target, headers)
+	}
+
+	res, err := runBench(cmd.Context(), benchConfig{
+		send:        send,
+		payload:     tmpl,
+		concurrency: viper.GetInt("bench.concurrency"),
+		duration:    viper.GetDuration("bench.duration"),
+		requests:    viper.GetInt("bench.requests"),
+		timeout:     viper.GetDuration("bench.timeout"),
+	})
+	if err != nil {
+		return err
+	}
+	res.Target = target
+
+	out := cmd.OutOrStdout()
+	writeBenchResult(out, res)
+
+	if f := viper.GetString("bench.save"); f != "" {
+		b, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(f, b, 0644); err != nil {
+			return err
+		}
+	}
+
+	if f := viper.GetString("bench.compare"); f != "" {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		var base BenchResult
+		if err := json.Unmarshal(b, &base); err != nil {
+			return fmt.Errorf("invalid baseline %s: %w", f, err)
+		}
+
+		if regressions := compareBench(out, base, res, viper.GetFloat64("bench.threshold")); regressions > 0 {
+			return fmt.Errorf("%d regressions compared to %s", regressions, f)
+		}
+	}
+
+	return nil
+}
+
+// payloadTemplate parses the payload with the generator's template funcs plus UUID and RandInt
+func payloadTemplate(payload string) (*template.Template, error) {
+	funcs := tpl.FuncMap()
+	funcs["UUID"] = nuid.Next
+	funcs["RandInt"] = func(lo, hi int) int {
+		if hi <= lo {
+			return lo
+		}
+		return lo + rand.IntN(hi-lo)
+	}
+
+	return template.New("payload").Funcs(funcs).Parse(payload)
+}
+
+func parseHeaders(raw []string) (http.Header, error) {
+	h := http.Header{}
+	for _, v := range raw {
+		k, val, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, expected Key: Value", v)
+		}
+		h.Add(strings.TrimSpace(k), strings.TrimSpace(val))
+	}
+
+	return h, nil
+}
+
+func httpSender(client *http.Client, method, url string, headers http.Header) sendFunc {
+	return func(ctx context.Context, payload []byte) error {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header = headers.Clone()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+func natsSender(nc *nats.Conn, subject string) sendFunc {
+	return func(ctx context.Context, payload []byte) error {
+		msg, err := nc.RequestWithContext(ctx, subject, payload)
+		if err != nil {
+			return err
+		}
+
+		if code := msg.Header.Get(micro.ErrorCodeHeader); code != "" {
+			return fmt.Errorf("service error %s", code)
+		}
+
+		return nil
+	}
+}
+
+type benchConfig struct {
+	send        sendFunc
+	payload     *template.Template
+	concurrency int
+	duration    time.Duration
+	requests    int
+	timeout     time.Duration
+}
+
+// runBench sends requests until the duration passes or the request limit is reached
+func runBench(ctx context.Context, cfg benchConfig) (BenchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	var (
+		seq       atomic.Int64
+		mu        sync.Mutex
+		latencies []time.Duration
+		errors    int
+	)
+
+	start := time.Now()
+	g, ctx := concurrency.NewGroup(ctx, cfg.concurrency)
+	for w := 0; w < max(cfg.concurrency, 1); w++ {
+		g.Go(func(ctx context.Context) error {
+			var local []time.Duration
+			var localErrors int
+			var buf bytes.Buffer
+
+			for ctx.Err() == nil {
+				n := seq.Add(1)
+				if cfg.requests > 0 && n > int64(cfg.requests) {
+					break
+				}
+
+				buf.Reset()
+				if err := cfg.payload.Execute(&buf, payloadData{Seq: n, Worker: w}); err != nil {
+					return err
+				}
+
+				reqCtx, reqCancel := context.WithTimeout(ctx, cfg.timeout)
+				t := time.Now()
+				err := cfg.send(reqCtx, buf.Bytes())
+				elapsed := time.Since(t)
+				reqCancel()
+
+				// requests cut off by the end of the run don't count
+				if err != nil && ctx.Err() != nil {
+					break
+				}
+
+				local = append(local, elapsed)
+				if err != nil {
+					localErrors++
+				}
+			}
+
+			mu.Lock()
+			latencies = append(latencies, local...)
+			errors += localErrors
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return BenchResult{}, err
+	}
+
+	return summarize(latencies, errors, time.Since(start)), nil
+}
+
+// summarize computes the result from every request's latency
+func summarize(latencies []time.Duration, errors int, elapsed time.Duration) BenchResult {
+	res := BenchResult{
+		Requests: len(latencies),
+		Errors:   errors,
+		Duration: elapsed,
+	}
+	if len(latencies) == 0 {
+		return res
+	}
+
+	slices.Sort(latencies)
+	var total time.Duration
+	for _, v := range latencies {
+		total += v
+	}
+
+	res.RPS = float64(len(latencies)) / elapsed.Seconds()
+	res.ErrorRate = float64(errors) / float64(len(latencies))
+	res.Mean = total / time.Duration(len(latencies))
+	res.P50 = percentile(latencies, 50)
+	res.P90 = percentile(latencies, 90)
+	res.P99 = percentile(latencies, 99)
+	res.Max = latencies[len(latencies)-1]
+
+	return res
+}
+
+// percentile returns the nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func writeBenchResult(w io.Writer, r BenchResult) {
+	fmt.Fprintf(w, "target:     %s\n", r.Target)
+	fmt.Fprintf(w, "requests:   %d in %s (%.1f/s)\n", r.Requests, r.Duration.Round(time.Millisecond), r.RPS)
+	fmt.Fprintf(w, "errors:     %d (%.2f%%)\n", r.Errors, r.ErrorRate*100)
+	fmt.Fprintf(w, "latency:    mean %s, p50 %s, p90 %s, p99 %s, max %s\n", r.Mean, r.P50, r.P90, r.P99, r.Max)
+}
+
+// compareBench prints each metric next to the baseline and returns how many regressed. Latency percentiles
+// regress when they grow by more than threshold percent, the error rate when it grows at all.
+func compareBench(w io.Writer, base, cur BenchResult, threshold float64) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nMETRIC\tBASELINE\tCURRENT\tCHANGE\t")
+
+	var regressions int
+	row := func(name string, b, c float64, format func(float64) string, regressed bool) {
+		change := "n/a"
+		if b != 0 {
+			change = fmt.Sprintf("%+.1f%%", (c-b)/b*100)
+		}
+		if regressed {
+			regressions++
+			change = color.RedString(change + " regression")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", name, format(b), format(c), change)
+	}
+
+	dur := func(v float64) string { return time.Duration(v).String() }
+	grew := func(b, c time.Duration) bool {
+		return b > 0 && float64(c-b)/float64(b)*100 > threshold
+	}
+
+	row("rps", base.RPS, cur.RPS, func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }, false)
+	row("error rate", base.ErrorRate, cur.ErrorRate, func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) }, cur.ErrorRate > base.ErrorRate)
+	row("p50", float64(base.P50), float64(cur.P50), dur, grew(base.P50, cur.P50))
+	row("p90", float64(base.P90), float64(cur.P90), dur, grew(base.P90, cur.P90))
+	row("p99", float64(base.P99), float64(cur.P99), dur, grew(base.P99, cur.P99))
+	tw.Flush()
+
+	return regressions
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	tt := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{name: "p50", sorted: latencies, p: 50, want: 50 * time.Millisecond},
+		{name: "p99", sorted: latencies, p: 99, want: 99 * time.Millisecond},
+		{name: "p100", sorted: latencies, p: 100, want: 100 * time.Millisecond},
+		{name: "p0", sorted: latencies, p: 0, want: time.Millisecond},
+		{name: "single", sorted: []time.Duration{time.Second}, p: 90, want: time.Second},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := percentile(v.sorted, v.p); got != v.want {
+				t.Errorf("expected %s, got %s", v.want, got)
+			}
+		})
+	}
+}
+
+func TestCompareBench(t *testing.T) {
+	base := BenchResult{RPS: 100, ErrorRate: 0.01, P50: 10 * time.Millisecond, P90: 20 * time.Millisecond, P99: 40 * time.Millisecond}
+
+	tt := []struct {
+		name      string
+		cur       BenchResult
+		threshold float64
+		want      int
+	}{
+		{name: "same", cur: base, threshold: 10, want: 0},
+		{name: "within threshold", cur: BenchResult{RPS: 90, ErrorRate: 0.01, P50: 11 * time.Millisecond, P90: 20 * time.Millisecond, P99: 40 * time.Millisecond}, threshold: 10, want: 0},
+		{name: "slower", cur: BenchResult{RPS: 50, ErrorRate: 0.01, P50: 20 * time.Millisecond, P90: 40 * time.Millisecond, P99: 40 * time.Millisecond}, threshold: 10, want: 2},
+		{name: "more errors", cur: BenchResult{RPS: 100, ErrorRate: 0.05, P50: 10 * time.Millisecond, P90: 20 * time.Millisecond, P99: 40 * time.Millisecond}, threshold: 10, want: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if got := compareBench(&buf, base, v.cur, v.threshold); got != v.want {
+				t.Errorf("expected %d regressions, got %d\n%s", v.want, got, buf.String())
+			}
+		})
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if n%4 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	tmpl, err := payloadTemplate(`{"seq":{{ .Seq }}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := runBench(context.Background(), benchConfig{
+		send:        httpSender(srv.Client(), http.MethodPost, srv.URL, http.Header{}),
+		payload:     tmpl,
+		concurrency: 4,
+		duration:    5 * time.Second,
+		requests:    40,
+		timeout:     time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Requests != 40 {
+		t.Errorf("expected 40 requests, got %d", res.Requests)
+	}
+	if res.Errors != 10 {
+		t.Errorf("expected 10 errors, got %d", res.Errors)
+	}
+	if res.P50 == 0 || res.Max < res.P99 {
+		t.Errorf("unexpected latencies %+v", res)
+	}
+}
+
+func TestRunBenchPayload(t *testing.T) {
+	var seen atomic.Int64
+	send := func(ctx context.Context, payload []byte) error {
+		if !strings.HasPrefix(string(payload), "req-") {
+			return fmt.Errorf("unexpected payload %s", payload)
+		}
+		seen.Add(1)
+		return nil
+	}
+
+	tmpl, err := payloadTemplate(`req-{{ .Seq }}-{{ .Worker }}-{{ RandInt 1 10 }}-{{ UUID }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := runBench(context.Background(), benchConfig{send: send, payload: tmpl, concurrency: 2, duration: time.Second, requests: 10, timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Errors != 0 || seen.Load() != 10 {
+		t.Errorf("expected 10 successful requests, got %d with %d errors", seen.Load(), res.Errors)
+	}
+}
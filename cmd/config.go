@@ -0,0 +1,59 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	sdconfig "github.com/SencilloDev/sencillo-go/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Works with service config files",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validates a config file against the service's JSON Schema",
+	Long: `Checks a cue, json, or yaml config file against the JSON Schema written by config.Schema and lists the
+path of every invalid value, so malformed config is caught before it is deployed.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         configValidate,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().String("schema", "config.schema.json", "JSON Schema file of the service's config")
+	viper.BindPFlag("config.schema", configValidateCmd.Flags().Lookup("schema"))
+}
+
+func configValidate(cmd *cobra.Command, args []string) error {
+	schema, err := sdconfig.ReadSchema(viper.GetString("config.schema"))
+	if err != nil {
+		return err
+	}
+
+	if err := sdconfig.Validate(schema, args[0]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", args[0])
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+	"github.com/fatih/color"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// doctorTimeout bounds each network check
+const doctorTimeout = 5 * time.Second
+
+var goDirective = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks the environment and a generated service for problems",
+	Long: `Checks the Go toolchain, NATS connectivity and credentials, JetStream, KV buckets, and the OTLP endpoint a
+service needs, and that a generated service's dependencies are compatible with the wiring sgoctl scaffolded`,
+	RunE: doctor,
+	// failed checks are not usage errors
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().String("dir", ".", "Directory of the service to check")
+	viper.BindPFlag("doctor.dir", doctorCmd.Flags().Lookup("dir"))
+	doctorCmd.Flags().String("template-dir", "", "Template overrides the service was generated with")
+	viper.BindPFlag("doctor.template_dir", doctorCmd.Flags().Lookup("template-dir"))
+	doctorCmd.Flags().String("nats-urls", nats.DefaultURL, "NATS server urls")
+	viper.BindPFlag("doctor.nats_urls", doctorCmd.Flags().Lookup("nats-urls"))
+	doctorCmd.Flags().String("nats-jwt", "", "NATS user JWT")
+	viper.BindPFlag("doctor.nats_jwt", doctorCmd.Flags().Lookup("nats-jwt"))
+	doctorCmd.Flags().String("nats-seed", "", "NATS user seed")
+	viper.BindPFlag("doctor.nats_seed", doctorCmd.Flags().Lookup("nats-seed"))
+	doctorCmd.Flags().String("credentials-file", "", "NATS credentials file")
+	viper.BindPFlag("doctor.credentials_file", doctorCmd.Flags().Lookup("credentials-file"))
+	doctorCmd.Flags().StringSlice("kv-buckets", nil, "KV buckets the service requires")
+	viper.BindPFlag("doctor.kv_buckets", doctorCmd.Flags().Lookup("kv-buckets"))
+	doctorCmd.Flags().String("otlp-endpoint", "", "OTLP endpoint the service exports telemetry to")
+	viper.BindPFlag("doctor.otlp_endpoint", doctorCmd.Flags().Lookup("otlp-endpoint"))
+}
+
+func doctor(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), 4*doctorTimeout)
+	defer cancel()
+
+	dir := viper.GetString("doctor.dir")
+	tpl.SetOverlay(viper.GetString("doctor.template_dir"))
+	r := &report{w: cmd.OutOrStdout()}
+
+	r.check("go toolchain", checkGo(ctx, dir))
+
+	if _, err := os.Stat(filepath.Join(dir, lockFile)); err == nil {
+		problems, err := checkLock(dir)
+		if err != nil {
+			problems = []Problem{{Message: err.Error(), Fix: fmt.Sprintf("regenerate %s with `sgoctl new service`", lockFile)}}
+		}
+		r.check("service compatibility", problems)
+	} else {
+		r.skip("service compatibility", fmt.Sprintf("no %s in %s", lockFile, dir))
+	}
+
+	urls := viper.GetString("doctor.nats_urls")
+	nc, problems := connectNATS(urls, viper.GetString("doctor.nats_jwt"), viper.GetString("doctor.nats_seed"), viper.GetString("doctor.credentials_file"))
+	r.check("nats connection", problems)
+	if nc != nil {
+		defer nc.Close()
+		js, problems := checkJetStream(ctx, nc)
+		r.check("jetstream", problems)
+
+		if buckets := viper.GetStringSlice("doctor.kv_buckets"); len(buckets) > 0 && js != nil {
+			r.check("kv buckets", checkBuckets(js, buckets))
+		}
+	}
+
+	if endpoint := viper.GetString("doctor.otlp_endpoint"); endpoint != "" {
+		r.check("otlp endpoint", checkEndpoint(ctx, endpoint))
+	} else {
+		r.skip("otlp endpoint", "--otlp-endpoint not set")
+	}
+
+	if r.failed > 0 {
+		return fmt.Errorf("%d checks failed", r.failed)
+	}
+
+	return nil
+}
+
+// report prints the result of each check
+type report struct {
+	w      io.Writer
+	failed int
+}
+
+func (r *report) check(name string, problems []Problem) {
+	if len(problems) == 0 {
+		fmt.Fprintf(r.w, "%s %s\n", color.GreenString("✓"), name)
+		return
+	}
+
+	r.failed++
+	fmt.Fprintf(r.w, "%s %s\n", color.RedString("✗"), name)
+	for _, v := range problems {
+		fmt.Fprintf(r.w, "    %s\n", v.Message)
+		if v.Fix != "" {
+			fmt.Fprintf(r.w, "    fix: %s\n", v.Fix)
+		}
+	}
+}
+
+func (r *report) skip(name, reason string) {
+	fmt.Fprintf(r.w, "%s %s: skipped, %s\n", color.YellowString("-"), name, reason)
+}
+
+// checkGo checks that go is installed and at least the version the service's go.mod requires
+func checkGo(ctx context.Context, dir string) []Problem {
+	out, err := exec.CommandContext(ctx, "go", "env", "GOVERSION").Output()
+	if err != nil {
+		return []Problem{{Message: fmt.Sprintf("go is not available: %s", err), Fix: "install Go from https://go.dev/dl"}}
+	}
+	have := strings.TrimSpace(string(out))
+
+	mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil
+	}
+
+	m := goDirective.FindSubmatch(mod)
+	if m == nil {
+		return nil
+	}
+	want := string(m[1])
+
+	if compareVersions(have, want) < 0 {
+		return []Problem{{
+			Message: fmt.Sprintf("go.mod requires go %s but %s is installed", want, have),
+			Fix:     fmt.Sprintf("install Go %s or newer", want),
+		}}
+	}
+
+	return nil
+}
+
+// connectNATS connects with the given credentials. The connection is nil if it failed.
+func connectNATS(urls, jwt, seed, creds string) (*nats.Conn, []Problem) {
+	opts := []nats.Option{nats.Name("sgoctl"), nats.Timeout(doctorTimeout)}
+	if jwt != "" {
+		opts = append(opts, nats.UserJWTAndSeed(jwt, seed))
+	}
+	if creds != "" {
+		opts = append(opts, nats.UserCredentials(creds))
+	}
+
+	nc, err := nats.Connect(urls, opts...)
+	switch {
+	case err == nil:
+		return nc, nil
+	case errors.Is(err, nats.ErrAuthorization), errors.Is(err, nats.ErrAuthExpired), errors.Is(err, nats.ErrAuthRevoked):
+		return nil, []Problem{{Message: err.Error(), Fix: "check the --nats-jwt and --nats-seed or --credentials-file the service is started with"}}
+	case errors.Is(err, nats.ErrNoServers):
+		return nil, []Problem{{Message: err.Error(), Fix: fmt.Sprintf("check that NATS is running at %s, `make up` starts it in a generated service", urls)}}
+	default:
+		return nil, []Problem{{Message: err.Error()}}
+	}
+}
+
+// checkJetStream checks that JetStream is enabled for the account. The context is nil if it isn't.
+func checkJetStream(ctx context.Context, nc *nats.Conn) (nats.JetStreamContext, []Problem) {
+	js, err := nc.JetStream(nats.Context(ctx))
+	if err != nil {
+		return nil, []Problem{{Message: err.Error()}}
+	}
+
+	_, err = js.AccountInfo(nats.Context(ctx))
+	switch {
+	case err == nil:
+		return js, nil
+	case errors.Is(err, nats.ErrJetStreamNotEnabledForAccount):
+		return nil, []Problem{{Message: err.Error(), Fix: "enable JetStream for the account in the server or operator configuration"}}
+	case errors.Is(err, nats.ErrJetStreamNotEnabled):
+		return nil, []Problem{{Message: err.Error(), Fix: "start nats-server with -js"}}
+	default:
+		return nil, []Problem{{Message: err.Error()}}
+	}
+}
+
+// checkBuckets checks that each KV bucket exists
+func checkBuckets(js nats.KeyValueManager, buckets []string) []Problem {
+	var problems []Problem
+	for _, v := range buckets {
+		_, err := js.KeyValue(v)
+		switch {
+		case err == nil:
+		case errors.Is(err, nats.ErrBucketNotFound):
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("bucket %s does not exist", v),
+				Fix:     fmt.Sprintf("create it with `nats kv add %s`", v),
+			})
+		default:
+			problems = append(problems, Problem{Message: fmt.Sprintf("bucket %s: %s", v, err)})
+		}
+	}
+
+	return problems
+}
+
+// checkEndpoint checks that something is listening at the endpoint, which is either host:port or a URL
+func checkEndpoint(ctx context.Context, endpoint string) []Problem {
+	addr := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		addr = u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			addr = net.JoinHostPort(u.Hostname(), port)
+		}
+	}
+
+	d := net.Dialer{Timeout: doctorTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return []Problem{{
+			Message: fmt.Sprintf("%s is unreachable: %s", endpoint, err),
+			Fix:     "check that the collector is running, `make up` starts one in services generated with --enable-telemetry",
+		}}
+	}
+	conn.Close()
+
+	return nil
+}
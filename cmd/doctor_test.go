@@ -0,0 +1,69 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+)
+
+func TestCheckBuckets(t *testing.T) {
+	kv := kvtest.NewManager("config")
+
+	problems := checkBuckets(kv, []string{"config", "sessions"})
+	if len(problems) != 1 {
+		t.Fatalf("expected 1 problem but got %v", problems)
+	}
+	if problems[0].Fix != "create it with `nats kv add sessions`" {
+		t.Errorf("unexpected fix %q", problems[0].Fix)
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	open := ln.Addr().String()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+	defer ln.Close()
+
+	tt := []struct {
+		name     string
+		endpoint string
+		problems int
+	}{
+		{name: "host port", endpoint: open, problems: 0},
+		{name: "url", endpoint: "http://" + open + "/v1/traces", problems: 0},
+		{name: "unreachable", endpoint: closedAddr, problems: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := checkEndpoint(context.Background(), v.endpoint); len(got) != v.problems {
+				t.Errorf("expected %d problems but got %v", v.problems, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var docsEndpointsCmd = &cobra.Command{
+	Use:   "endpoints [service]",
+	Short: "Generate markdown documentation for a service's NATS endpoints",
+	Long: `Writes a markdown file per endpoint with its subject, headers, request and response examples, schemas, runnable
+nats and curl invocations, and error codes, read from the endpoint metadata set with apidocs.Doc. The metadata comes from the running service's
+$SRV.INFO reply, or from a saved reply with --from, such as the output of nats micro info <service> --json.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         docsEndpoints,
+	SilenceUsage: true,
+}
+
+func init() {
+	docsCmd.AddCommand(docsEndpointsCmd)
+	docsEndpointsCmd.Flags().String("out", "docs/endpoints", "Directory to write the markdown to")
+	serviceDocsFlags(docsEndpointsCmd)
+}
+
+// serviceDocsFlags adds the flags choosing where a docs subcommand reads the service info from. Several
+// subcommands share the viper keys, so they are bound when the command runs rather than in init.
+func serviceDocsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "Read the service info from a JSON file instead of NATS")
+	cmd.Flags().String("base-url", apidocs.DefaultBaseURL, "HTTP gateway URL used in examples")
+	cmd.Flags().String("nats-urls", nats.DefaultURL, "NATS server urls")
+	cmd.Flags().String("nats-jwt", "", "NATS user JWT")
+	cmd.Flags().String("nats-seed", "", "NATS user seed")
+	cmd.Flags().String("credentials-file", "", "NATS credentials file")
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		viper.BindPFlag("docs.out", cmd.Flags().Lookup("out"))
+		viper.BindPFlag("docs.from", cmd.Flags().Lookup("from"))
+		viper.BindPFlag("docs.base_url", cmd.Flags().Lookup("base-url"))
+		viper.BindPFlag("docs.nats_urls", cmd.Flags().Lookup("nats-urls"))
+		viper.BindPFlag("docs.nats_jwt", cmd.Flags().Lookup("nats-jwt"))
+		viper.BindPFlag("docs.nats_seed", cmd.Flags().Lookup("nats-seed"))
+		viper.BindPFlag("docs.credentials_file", cmd.Flags().Lookup("credentials-file"))
+	}
+}
+
+// readServiceInfo reads a service's info from a saved $SRV.INFO reply
+func readServiceInfo(path string) (micro.Info, error) {
+	var info micro.Info
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(b, &info); err != nil {
+		return info, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// requestServiceInfo asks a running instance of the service for its info
+func requestServiceInfo(name string) (micro.Info, error) {
+	var info micro.Info
+	subject, err := micro.ControlSubject(micro.InfoVerb, name, "")
+	if err != nil {
+		return info, err
+	}
+
+	nc, problems := connectNATS(
+		viper.GetString("docs.nats_urls"),
+		viper.GetString("docs.nats_jwt"),
+		viper.GetString("docs.nats_seed"),
+		viper.GetString("docs.credentials_file"),
+	)
+	if len(problems) > 0 {
+		return info, fmt.Errorf("%s", problems[0].Message)
+	}
+	defer nc.Close()
+
+	msg, err := nc.Request(subject, nil, 5*time.Second)
+	if err != nil {
+		return info, fmt.Errorf("error requesting %s: %w", subject, err)
+	}
+	if err := json.Unmarshal(msg.Data, &info); err != nil {
+		return info, fmt.Errorf("error parsing %s reply: %w", subject, err)
+	}
+	return info, nil
+}
+
+// loadServiceDocs reads the service's documentation from --from or from the running service
+func loadServiceDocs(args []string) (apidocs.Service, error) {
+	var info micro.Info
+	var err error
+	switch {
+	case viper.GetString("docs.from") != "":
+		info, err = readServiceInfo(viper.GetString("docs.from"))
+	case len(args) == 1:
+		info, err = requestServiceInfo(args[0])
+	default:
+		return apidocs.Service{}, fmt.Errorf("name a service or pass --from")
+	}
+	if err != nil {
+		return apidocs.Service{}, err
+	}
+
+	svc, err := apidocs.FromInfo(info)
+	svc.BaseURL = viper.GetString("docs.base_url")
+	return svc, err
+}
+
+func docsEndpoints(cmd *cobra.Command, args []string) error {
+	svc, err := loadServiceDocs(args)
+	if err != nil {
+		return err
+	}
+
+	files, err := apidocs.WriteDir(viper.GetString("docs.out"), svc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "wrote %d files for %d endpoints of %s\n", len(files), len(svc.Endpoints), svc.Name)
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadServiceDocs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "info.json")
+	info := `{"name": "math", "version": "1.0.0", "endpoints": [{"name": "add", "subject": "math.add", "metadata": {"description": "adds two numbers"}}]}`
+	if err := os.WriteFile(path, []byte(info), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("docs.from", path)
+	t.Cleanup(func() { viper.Set("docs.from", "") })
+
+	svc, err := loadServiceDocs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Name != "math" || len(svc.Endpoints) != 1 || svc.Endpoints[0].Description != "adds two numbers" {
+		t.Errorf("unexpected service %+v", svc)
+	}
+
+	viper.Set("docs.from", "")
+	if _, err := loadServiceDocs(nil); err == nil {
+		t.Error("expected an error without a service or --from")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/cmd/plugin"
+	"github.com/spf13/cobra"
+)
+
+var genCmd = &cobra.Command{
+	Use:   "gen <plugin> [args]",
+	Short: "Runs a generator plugin",
+	Long: `Runs the sgoctl-gen-<plugin> executable on the PATH and writes the files it generates. Without a
+plugin name the available plugins are listed.`,
+	RunE:         gen,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(genCmd)
+	// flags after the plugin name belong to the plugin
+	genCmd.Flags().SetInterspersed(false)
+}
+
+func gen(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		names := listPlugins(filepath.SplitList(os.Getenv("PATH")))
+		if len(names) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "no plugins found, install an executable named %s<name> on your PATH\n", plugin.Prefix)
+			return nil
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "available plugins:")
+		for _, v := range names {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", v)
+		}
+		return nil
+	}
+
+	bin, err := exec.LookPath(plugin.Prefix + args[0])
+	if err != nil {
+		return fmt.Errorf("plugin %s not found: %w", args[0], err)
+	}
+
+	req := plugin.Request{Version: Version, Args: args[1:]}
+	if l, err := readLock("."); err == nil {
+		req.Manifest = &plugin.Manifest{
+			Version:      l.Version,
+			TemplateHash: l.TemplateHash,
+			Name:         l.Name,
+			Module:       l.Module,
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	resp, err := runPlugin(bin, req)
+	if err != nil {
+		return fmt.Errorf("plugin %s failed: %w", args[0], err)
+	}
+
+	return writePluginFiles(resp.Files)
+}
+
+// runPlugin sends the request to the plugin and decodes its response
+func runPlugin(bin string, req plugin.Request) (plugin.Response, error) {
+	var resp plugin.Response
+	in, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var out bytes.Buffer
+	c := exec.Command(bin)
+	c.Stdin = bytes.NewReader(in)
+	c.Stdout = &out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return resp, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// writePluginFiles validates every path before writing any file so a bad response leaves the project alone
+func writePluginFiles(files []plugin.File) error {
+	for _, v := range files {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range files {
+		n := filepath.FromSlash(v.Path)
+		if !cfg.Debug && !cfg.DryRun {
+			if err := os.MkdirAll(filepath.Dir(n), 0755); err != nil {
+				return fmt.Errorf("error creating path: %s", err)
+			}
+		}
+
+		if err := writeOutput(n, []byte(v.Content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listPlugins returns the names of the plugins in dirs. Plugins earlier in the list shadow later ones.
+func listPlugins(dirs []string) []string {
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), plugin.Prefix)
+			if !ok || name == "" || e.IsDir() {
+				continue
+			}
+			if _, err := exec.LookPath(filepath.Join(dir, e.Name())); err != nil {
+				continue
+			}
+			seen[strings.TrimSuffix(name, ".exe")] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for k := range seen {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/cmd/plugin"
+)
+
+func TestListPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are detected by the executable bit")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]os.FileMode{
+		filepath.Join(first, "sgoctl-gen-openapi"):  0755,
+		filepath.Join(first, "sgoctl-gen-notes"):    0644,
+		filepath.Join(second, "sgoctl-gen-openapi"): 0755,
+		filepath.Join(second, "sgoctl-gen-proto"):   0755,
+		filepath.Join(second, "sgoctl-gen-"):        0755,
+		filepath.Join(second, "other"):              0755,
+	}
+	for k, v := range files {
+		if err := os.WriteFile(k, []byte("#!/bin/sh\n"), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := listPlugins([]string{first, second, filepath.Join(first, "missing")})
+	if want := []string{"openapi", "proto"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestWritePluginFiles(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	err = writePluginFiles([]plugin.File{
+		{Path: "api/openapi.yaml", Content: "openapi: 3.1.0\n"},
+		{Path: "../outside.txt", Content: "nope"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a path outside the project")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api")); !os.IsNotExist(err) {
+		t.Error("expected no files to be written when any path is invalid")
+	}
+
+	if err := writePluginFiles([]plugin.File{{Path: "api/openapi.yaml", Content: "openapi: 3.1.0\n"}}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "api", "openapi.yaml"))
+	if err != nil || string(b) != "openapi: 3.1.0\n" {
+		t.Errorf("unexpected file %q: %v", b, err)
+	}
+}
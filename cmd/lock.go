@@ -0,0 +1,181 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+	"gopkg.in/yaml.v3"
+)
+
+// lockFile records which sgoctl and templates generated a service
+const lockFile = ".sencillo.lock"
+
+// libraryModule is the module the generated wiring imports
+const libraryModule = "github.com/SencilloDev/sencillo-go"
+
+var libraryRequire = regexp.MustCompile(`(?m)^\s*(?:require\s+)?` + regexp.QuoteMeta(libraryModule) + `\s+(v\S+)`)
+
+// Lock is the contents of .sencillo.lock
+type Lock struct {
+	// Version is the sgoctl version that generated the service. The library is released with sgoctl so the
+	// generated code expects at least the same library version.
+	Version      string `yaml:"version"`
+	TemplateHash string `yaml:"template_hash"`
+	// Templates are the templates rendered for the service's options. Only these are hashed, so changes to
+	// templates the service doesn't use aren't reported.
+	Templates []string `yaml:"templates,omitempty"`
+	Name      string   `yaml:"name"`
+	Module    string   `yaml:"module"`
+}
+
+// Problem is an incompatibility found by a check along with how to fix it
+type Problem struct {
+	Message string
+	Fix     string
+}
+
+// writeLock creates the lock file for the service
+func writeLock(s *Service) error {
+	hash, err := tpl.Hash(s.templates...)
+	if err != nil {
+		return fmt.Errorf("error hashing templates: %w", err)
+	}
+
+	b, err := yaml.Marshal(Lock{
+		Version:      Version,
+		TemplateHash: hash,
+		Templates:    s.templates,
+		Name:         s.Name,
+		Module:       s.Module,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(filepath.Join(s.OutputDir, lockFile), b)
+}
+
+// readLock reads the lock file in dir
+func readLock(dir string) (Lock, error) {
+	var l Lock
+	b, err := os.ReadFile(filepath.Join(dir, lockFile))
+	if err != nil {
+		return l, err
+	}
+
+	if err := yaml.Unmarshal(b, &l); err != nil {
+		return l, fmt.Errorf("error parsing %s: %w", lockFile, err)
+	}
+
+	return l, nil
+}
+
+// checkLock compares the lock file in dir with the library version in its go.mod and the templates of the
+// running sgoctl
+func checkLock(dir string) ([]Problem, error) {
+	l, err := readLock(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+
+	mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := libraryRequire.FindSubmatch(mod)
+	switch {
+	case m == nil:
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("go.mod does not require %s", libraryModule),
+			Fix:     "run `go mod tidy`",
+		})
+	case compareVersions(string(m[1]), l.Version) < 0:
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("the service was generated by sgoctl %s but go.mod requires %s %s", l.Version, libraryModule, m[1]),
+			Fix:     fmt.Sprintf("run `go get %s@%s`", libraryModule, l.Version),
+		})
+	}
+
+	hash, err := tpl.Hash(l.Templates...)
+	if err != nil {
+		return nil, err
+	}
+	if l.TemplateHash != hash {
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("the templates changed since the service was generated by sgoctl %s", l.Version),
+			Fix:     fmt.Sprintf("run `sgoctl new service -n %s --dry-run` with the original flags to review the changes", l.Name),
+		})
+	}
+
+	return problems, nil
+}
+
+// compareVersions compares the major, minor, and patch numbers of two versions. Versions that can't
+// be parsed, like dev builds, compare as equal to anything.
+func compareVersions(a, b string) int {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return 0
+	}
+	pb, ok := parseVersion(b)
+	if !ok {
+		return 0
+	}
+
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// parseVersion parses semantic versions and Go versions like go1.22 or 1.22.2. Pre-release and build suffixes
+// are ignored.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "go"), "v")
+	if i := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return out, false
+		}
+		out[i] = n
+	}
+
+	return out, true
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tt := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{name: "equal", a: "v1.2.3", b: "v1.2.3", want: 0},
+		{name: "older patch", a: "v1.2.3", b: "v1.2.4", want: -1},
+		{name: "newer minor", a: "v1.10.0", b: "v1.9.9", want: 1},
+		{name: "prerelease", a: "v1.2.3-rc.1", b: "v1.2.3", want: 0},
+		{name: "no prefix", a: "1.0.0", b: "v2.0.0", want: -1},
+		{name: "dev", a: "v1.0.0", b: "dev", want: 0},
+		{name: "go toolchain", a: "go1.22.2", b: "1.22", want: 1},
+		{name: "go release candidate", a: "go1.23rc1", b: "1.23.0", want: 0},
+		{name: "pseudo", a: "v0.0.0-20250101000000-abcdef123456", b: "v0.1.0", want: -1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := compareVersions(v.a, v.b); got != v.want {
+				t.Errorf("expected %d but got %d", v.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	hash, err := tpl.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainHash, err := tpl.Hash("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an override of a template the service doesn't render
+	overlay := t.TempDir()
+	if err := os.WriteFile(filepath.Join(overlay, "edgedb.toml.tmpl"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name     string
+		lock     string
+		mod      string
+		overlay  string
+		problems int
+	}{
+		{name: "compatible", lock: "version: v1.2.0\ntemplate_hash: " + hash + "\n", mod: "require github.com/SencilloDev/sencillo-go v1.2.0\n", problems: 0},
+		{name: "old library", lock: "version: v1.2.0\ntemplate_hash: " + hash + "\n", mod: "require (\n\tgithub.com/SencilloDev/sencillo-go v1.1.0\n)\n", problems: 1},
+		{name: "missing library", lock: "version: v1.2.0\ntemplate_hash: " + hash + "\n", mod: "module example.com/demo\n", problems: 1},
+		{name: "templates changed", lock: "version: v1.2.0\ntemplate_hash: sha256:old\n", mod: "require github.com/SencilloDev/sencillo-go v1.3.0\n", problems: 1},
+		{name: "unused template changed", lock: "version: v1.2.0\ntemplate_hash: " + mainHash + "\ntemplates: [main.go]\n", mod: "require github.com/SencilloDev/sencillo-go v1.2.0\n", overlay: overlay, problems: 0},
+		{name: "unrecorded templates changed", lock: "version: v1.2.0\ntemplate_hash: " + hash + "\n", mod: "require github.com/SencilloDev/sencillo-go v1.2.0\n", overlay: overlay, problems: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			tpl.SetOverlay(v.overlay)
+			defer tpl.SetOverlay("")
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, lockFile), []byte(v.lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(v.mod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := checkLock(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != v.problems {
+				t.Errorf("expected %d problems but got %v", v.problems, problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+)
+
+var moduleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// setupMonorepo points the service at services/<name> and derives its module from the monorepo's
+func (s *Service) setupMonorepo() error {
+	prefix := s.ModulePrefix
+	if prefix == "" {
+		b, err := os.ReadFile("go.mod")
+		if err != nil {
+			return fmt.Errorf("set --module-prefix or run from a directory with a go.mod: %w", err)
+		}
+
+		m := moduleLine.FindSubmatch(b)
+		if m == nil {
+			return fmt.Errorf("no module found in go.mod")
+		}
+		prefix = string(m[1])
+	}
+
+	s.OutputDir = filepath.Join("services", s.Name)
+	s.Module = path.Join(prefix, "services", s.Name)
+
+	return nil
+}
+
+// monorepoFiles are the files a monorepo service needs besides the usual ones. Files at the root of the
+// repo are shared by every service, so they are only created if they don't exist yet.
+func monorepoFiles(workflowDelims Delims) []CreateFileFromTemplate {
+	return []CreateFileFromTemplate{
+		fromSharedTemplate("monorepo/go.mod", func(s *Service) string { return filepath.Join(s.OutputDir, "go.mod") }, dd),
+		fromSharedTemplate("monorepo/Makefile", func(*Service) string { return "Makefile" }, dd),
+		fromSharedTemplate("monorepo/.github/workflows/services.yaml", func(*Service) string { return ".github/workflows/services.yaml" }, workflowDelims),
+		updateGoWork,
+	}
+}
+
+// fromSharedTemplate renders a template to the path returned by out unless a file is already there
+func fromSharedTemplate(name string, out func(*Service) string, dd Delims) CreateFileFromTemplate {
+	return func(s *Service) error {
+		n := out(s)
+		if _, err := os.Stat(n); err == nil {
+			return nil
+		}
+
+		b, err := tpl.Read(name)
+		if err != nil {
+			return fmt.Errorf("error reading template %s: %w", name, err)
+		}
+		s.templates = append(s.templates, name)
+
+		return s.createOrPrintFile(n, b, dd)
+	}
+}
+
+// updateGoWork adds the service to the go.work file, creating it if needed. The root module is added too when
+// the repo has one, so services can import it.
+func updateGoWork(s *Service) error {
+	dir := "./" + filepath.ToSlash(s.OutputDir)
+
+	existing, err := os.ReadFile("go.work")
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading go.work: %s", err)
+	}
+
+	work := addGoWorkUse(string(existing), dir)
+	if _, err := os.Stat("go.mod"); err == nil {
+		work = addGoWorkUse(work, ".")
+	}
+
+	return writeOutput("go.work", []byte(work))
+}
+
+// addGoWorkUse returns the go.work contents with dir in its use directives
+func addGoWorkUse(work, dir string) string {
+	if work == "" {
+		return fmt.Sprintf("go 1.22\n\nuse (\n\t%s\n)\n", dir)
+	}
+
+	for _, line := range strings.Split(work, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "use"))
+		if len(fields) == 1 && fields[0] == dir {
+			return work
+		}
+	}
+
+	if i := strings.Index(work, "use ("); i >= 0 {
+		end := i + len("use (")
+		return work[:end] + "\n\t" + dir + work[end:]
+	}
+
+	return strings.TrimSuffix(work, "\n") + fmt.Sprintf("\n\nuse %s\n", dir)
+}
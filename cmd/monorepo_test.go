@@ -0,0 +1,115 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+)
+
+func TestAddGoWorkUse(t *testing.T) {
+	tt := []struct {
+		name string
+		work string
+		want string
+	}{
+		{name: "new", want: "go 1.22\n\nuse (\n\t./services/users\n)\n"},
+		{name: "block", work: "go 1.22\n\nuse (\n\t./services/orders\n)\n", want: "go 1.22\n\nuse (\n\t./services/users\n\t./services/orders\n)\n"},
+		{name: "single", work: "go 1.22\n\nuse ./services/orders\n", want: "go 1.22\n\nuse ./services/orders\n\nuse ./services/users\n"},
+		{name: "existing", work: "go 1.22\n\nuse ./services/users\n", want: "go 1.22\n\nuse ./services/users\n"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := addGoWorkUse(v.work, "./services/users"); got != v.want {
+				t.Errorf("expected %q but got %q", v.want, got)
+			}
+		})
+	}
+}
+
+func TestMonorepoWorkspaceBuilds(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	files := map[string]string{
+		"go.mod":         "module example.com/repo\n\ngo 1.22\n",
+		"shared/name.go": "package shared\n\nconst Name = \"users\"\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Service{Name: "users", Monorepo: true}
+	if err := s.setupMonorepo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(s.OutputDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"monorepo/go.mod", "Makefile"} {
+		b, err := tpl.Read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := s.handleOutput(&out, b, dd); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(out.String(), "-mod=vendor") {
+			t.Errorf("expected %s not to use the vendor directory in workspace mode", name)
+		}
+		if err := os.WriteFile(filepath.Join(s.OutputDir, filepath.Base(name)), out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main := "package main\n\nimport \"example.com/repo/shared\"\n\nfunc main() { println(shared.Name) }\n"
+	if err := os.WriteFile(filepath.Join(s.OutputDir, "main.go"), []byte(main), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateGoWork(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// the service imports the root module, which only resolves when go.work uses it
+	cmd := exec.Command("go", "build", "-o", os.DevNull, ".")
+	cmd.Dir = s.OutputDir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected the workspace to build: %v\n%s", err, out)
+	}
+}
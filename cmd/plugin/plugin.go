@@ -0,0 +1,97 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin is the protocol between sgoctl and generator plugins. A plugin is an executable named
+// sgoctl-gen-<name> on the PATH. `sgoctl gen <name> [args]` runs it with a Request as JSON on stdin and
+// writes the files in the Response it prints as JSON on stdout. Anything the plugin writes to stderr is shown
+// to the user.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Prefix is the prefix of plugin executable names
+const Prefix = "sgoctl-gen-"
+
+var ErrInvalidPath = fmt.Errorf("plugin file paths must be relative and inside the project")
+
+// Manifest describes the generated project the plugin runs in, from its .sencillo.lock
+type Manifest struct {
+	Version      string `json:"version"`
+	TemplateHash string `json:"template_hash"`
+	Name         string `json:"name"`
+	Module       string `json:"module"`
+}
+
+// Request is sent to the plugin on stdin
+type Request struct {
+	// Version is the version of sgoctl running the plugin
+	Version string `json:"version"`
+	// Manifest is nil when the plugin runs outside a generated project
+	Manifest *Manifest `json:"manifest,omitempty"`
+	Args     []string  `json:"args"`
+}
+
+// Response is printed by the plugin on stdout
+type Response struct {
+	Files []File `json:"files"`
+}
+
+// File is a file the plugin generated. Path is slash separated and relative to the project root.
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Validate checks that the path stays inside the project
+func (f File) Validate() error {
+	if f.Path == "" || path.IsAbs(f.Path) || strings.Contains(f.Path, "\\") {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, f.Path)
+	}
+
+	if c := path.Clean(f.Path); c == ".." || strings.HasPrefix(c, "../") {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, f.Path)
+	}
+
+	return nil
+}
+
+// Serve runs a plugin written in Go. It reads the request from stdin, calls gen, and prints the response. It
+// exits with a non-zero status if gen fails.
+func Serve(gen func(Request) (Response, error)) {
+	if err := serve(os.Stdin, os.Stdout, gen); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func serve(r io.Reader, w io.Writer, gen func(Request) (Response, error)) error {
+	var req Request
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("error reading request: %w", err)
+	}
+
+	resp, err := gen(req)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name string
+		path string
+		err  error
+	}{
+		{name: "relative", path: "api/openapi.yaml"},
+		{name: "dot", path: "./Makefile"},
+		{name: "inner parent", path: "api/../docs/api.md"},
+		{name: "empty", path: "", err: ErrInvalidPath},
+		{name: "absolute", path: "/etc/passwd", err: ErrInvalidPath},
+		{name: "parent", path: "../other/main.go", err: ErrInvalidPath},
+		{name: "escapes", path: "api/../../main.go", err: ErrInvalidPath},
+		{name: "backslash", path: `..\main.go`, err: ErrInvalidPath},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if err := (File{Path: v.path}).Validate(); !errors.Is(err, v.err) {
+				t.Errorf("expected %v but got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := strings.NewReader(`{"version":"v1.0.0","manifest":{"name":"orders"},"args":["--spec","api.yaml"]}`)
+	var out bytes.Buffer
+
+	err := serve(in, &out, func(req Request) (Response, error) {
+		if req.Manifest == nil || req.Manifest.Name != "orders" || len(req.Args) != 2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		return Response{Files: []File{{Path: "api/orders.go", Content: "package api\n"}}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"files":[{"path":"api/orders.go","content":"package api\n"}]}` + "\n"
+	if out.String() != want {
+		t.Errorf("expected %s but got %s", want, out.String())
+	}
+}
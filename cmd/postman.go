@@ -0,0 +1,56 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/SencilloDev/sencillo-go/apidocs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var docsPostmanCmd = &cobra.Command{
+	Use:   "postman [service]",
+	Short: "Export a Postman collection and environment for a service's HTTP routes",
+	Long: `Writes a Postman v2.1 collection with a request for every endpoint whose metadata names an HTTP gateway
+route, and an environment with the gateway URL and a variable per documented header. Insomnia imports the same
+files. The metadata comes from the running service's $SRV.INFO reply, or from a saved reply with --from.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         docsPostman,
+	SilenceUsage: true,
+}
+
+func init() {
+	docsCmd.AddCommand(docsPostmanCmd)
+	docsPostmanCmd.Flags().String("out", "docs/postman", "Directory to write the collection and environment to")
+	serviceDocsFlags(docsPostmanCmd)
+}
+
+func docsPostman(cmd *cobra.Command, args []string) error {
+	svc, err := loadServiceDocs(args)
+	if err != nil {
+		return err
+	}
+
+	files, err := apidocs.WritePostman(viper.GetString("docs.out"), svc)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", f)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ErrNothingToRelease = fmt.Errorf("no features, fixes, or breaking changes since the last release")
+
+// conventionalHeader matches commit subjects like feat(api)!: add versions
+var conventionalHeader = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Bumps the version and writes the changelog from conventional commits",
+	Long: `Reads the conventional commits since the last v* tag and computes the next semantic version: breaking
+changes bump the major version, features the minor version, and fixes and performance improvements the patch
+version. Before v1.0.0 breaking changes bump the minor version. The version files are rewritten and a section is
+added to the top of the changelog. With --tag the changes are committed and tagged. With --dry-run the section is
+only printed.`,
+	RunE:         releaseRun,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.Flags().String("dir", ".", "Repository to release")
+	viper.BindPFlag("release.dir", releaseCmd.Flags().Lookup("dir"))
+	releaseCmd.Flags().String("changelog", "CHANGELOG.md", "Changelog file, relative to --dir")
+	viper.BindPFlag("release.changelog", releaseCmd.Flags().Lookup("changelog"))
+	releaseCmd.Flags().StringSlice("version-file", []string{"VERSION"}, "Files holding only the version, relative to --dir. Missing files are created.")
+	viper.BindPFlag("release.version_files", releaseCmd.Flags().Lookup("version-file"))
+	releaseCmd.Flags().Bool("tag", false, "Commit the changes and create an annotated tag")
+	viper.BindPFlag("release.tag", releaseCmd.Flags().Lookup("tag"))
+}
+
+// conventionalCommit is a commit message following the Conventional Commits spec
+type conventionalCommit struct {
+	Hash     string
+	Type     string
+	Scope    string
+	Subject  string
+	Breaking bool
+	// Note is the text of a BREAKING CHANGE footer
+	Note string
+}
+
+// parseCommit parses a commit message. Messages that aren't conventional commits return false.
+func parseCommit(hash, message string) (conventionalCommit, bool) {
+	header, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	m := conventionalHeader.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return conventionalCommit{}, false
+	}
+
+	c := conventionalCommit{Hash: hash, Type: strings.ToLower(m[1]), Scope: m[2], Subject: m[4], Breaking: m[3] == "!"}
+	for _, line := range strings.Split(body, "\n") {
+		for _, footer := range []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"} {
+			if note, ok := strings.CutPrefix(line, footer); ok {
+				c.Breaking = true
+				c.Note = strings.TrimSpace(note)
+			}
+		}
+	}
+
+	return c, true
+}
+
+// nextVersion returns the version after current for the commits
+func nextVersion(current string, commits []conventionalCommit) (string, error) {
+	v, ok := parseVersion(current)
+	if !ok {
+		return "", fmt.Errorf("invalid version %q", current)
+	}
+
+	var major, minor, patch bool
+	for _, c := range commits {
+		switch {
+		case c.Breaking:
+			major = true
+		case c.Type == "feat":
+			minor = true
+		case c.Type == "fix" || c.Type == "perf":
+			patch = true
+		}
+	}
+
+	switch {
+	case major && v[0] > 0:
+		v = [3]int{v[0] + 1, 0, 0}
+	case major || minor:
+		v = [3]int{v[0], v[1] + 1, 0}
+	case patch:
+		v[2]++
+	default:
+		return "", ErrNothingToRelease
+	}
+
+	return fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2]), nil
+}
+
+// changelogSection renders the changes in a release as markdown
+func changelogSection(version string, date time.Time, commits []conventionalCommit) string {
+	groups := []struct {
+		title string
+		match func(conventionalCommit) bool
+	}{
+		{title: "Breaking Changes", match: func(c conventionalCommit) bool { return c.Breaking }},
+		{title: "Features", match: func(c conventionalCommit) bool { return c.Type == "feat" }},
+		{title: "Fixes", match: func(c conventionalCommit) bool { return c.Type == "fix" }},
+		{title: "Performance", match: func(c conventionalCommit) bool { return c.Type == "perf" }},
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s (%s)\n", version, date.Format(time.DateOnly))
+	for _, g := range groups {
+		var lines []string
+		for _, c := range commits {
+			if !g.match(c) {
+				continue
+			}
+			text := c.Subject
+			if g.title == "Breaking Changes" && c.Note != "" {
+				text = c.Note
+			}
+			if c.Scope != "" {
+				text = fmt.Sprintf("**%s:** %s", c.Scope, text)
+			}
+			if c.Hash != "" {
+				text = fmt.Sprintf("%s (%.7s)", text, c.Hash)
+			}
+			lines = append(lines, "- "+text)
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&sb, "\n### %s\n\n%s\n", g.title, strings.Join(lines, "\n"))
+		}
+	}
+
+	return sb.String()
+}
+
+// prependChangelog adds the section above the newest release, keeping a leading title in place
+func prependChangelog(existing, section string) string {
+	if existing == "" {
+		return "# Changelog\n\n" + section
+	}
+	if strings.HasPrefix(existing, "# ") {
+		title, rest, _ := strings.Cut(existing, "\n")
+		return title + "\n\n" + section + "\n" + strings.TrimLeft(rest, "\n")
+	}
+	return section + "\n" + existing
+}
+
+// git runs a git command in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// releaseCommits returns the last release tag, or v0.0.0 when there is none, and the conventional commits after it
+func releaseCommits(dir string) (string, []conventionalCommit, error) {
+	tag, err := git(dir, "describe", "--tags", "--abbrev=0", "--match", "v*")
+	rng := "HEAD"
+	if err != nil {
+		tag = "v0.0.0"
+	} else {
+		rng = tag + "..HEAD"
+	}
+
+	// fields are separated by the unit separator and commits by the record separator
+	out, err := git(dir, "log", "--reverse", "--format=%H%x1f%B%x1e", rng)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var commits []conventionalCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		if c, ok := parseCommit(hash, message); ok {
+			commits = append(commits, c)
+		}
+	}
+
+	return tag, commits, nil
+}
+
+// releaseResult is what a release changed
+type releaseResult struct {
+	Previous string
+	Version  string
+	Section  string
+	Files    []string
+}
+
+// release computes the next version and, unless dryRun is set, writes the version files and changelog
+func release(dir, changelog string, versionFiles []string, now time.Time, dryRun bool) (releaseResult, error) {
+	previous, commits, err := releaseCommits(dir)
+	if err != nil {
+		return releaseResult{}, err
+	}
+	version, err := nextVersion(previous, commits)
+	if err != nil {
+		return releaseResult{}, err
+	}
+
+	res := releaseResult{Previous: previous, Version: version, Section: changelogSection(version, now, commits)}
+	if dryRun {
+		return res, nil
+	}
+
+	for _, f := range versionFiles {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(version+"\n"), 0644); err != nil {
+			return res, err
+		}
+		res.Files = append(res.Files, f)
+	}
+
+	existing, err := os.ReadFile(filepath.Join(dir, changelog))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return res, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, changelog), []byte(prependChangelog(string(existing), res.Section)), 0644); err != nil {
+		return res, err
+	}
+	res.Files = append(res.Files, changelog)
+
+	return res, nil
+}
+
+// tagRelease commits the release files and creates an annotated tag
+func tagRelease(dir string, res releaseResult) error {
+	if _, err := git(dir, append([]string{"add", "--"}, res.Files...)...); err != nil {
+		return err
+	}
+	if _, err := git(dir, "commit", "-m", fmt.Sprintf("chore(release): %s", res.Version)); err != nil {
+		return err
+	}
+	_, err := git(dir, "tag", "-a", res.Version, "-m", fmt.Sprintf("Release %s", res.Version))
+	return err
+}
+
+func releaseRun(cmd *cobra.Command, args []string) error {
+	dir := viper.GetString("release.dir")
+	res, err := release(dir, viper.GetString("release.changelog"), viper.GetStringSlice("release.version_files"), time.Now(), cfg.DryRun)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s -> %s\n\n%s", res.Previous, res.Version, res.Section)
+	if cfg.DryRun || !viper.GetBool("release.tag") {
+		return nil
+	}
+
+	if err := tagRelease(dir, res); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\ntagged %s, push it with `git push --follow-tags`\n", res.Version)
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCommit(t *testing.T) {
+	tt := []struct {
+		message string
+		want    conventionalCommit
+		ok      bool
+	}{
+		{message: "feat: add versions", want: conventionalCommit{Type: "feat", Subject: "add versions"}, ok: true},
+		{message: "fix(http): close bodies\n\nDetails.", want: conventionalCommit{Type: "fix", Scope: "http", Subject: "close bodies"}, ok: true},
+		{message: "feat(api)!: drop v1", want: conventionalCommit{Type: "feat", Scope: "api", Subject: "drop v1", Breaking: true}, ok: true},
+		{message: "refactor: split router\n\nBREAKING CHANGE: Route.Timeout is now required", want: conventionalCommit{Type: "refactor", Subject: "split router", Breaking: true, Note: "Route.Timeout is now required"}, ok: true},
+		{message: "Merge branch 'main'"},
+		{message: "[1479] Add API versions"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.message, func(t *testing.T) {
+			got, ok := parseCommit("", v.message)
+			if ok != v.ok || !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %+v %v but got %+v %v", v.want, v.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	feat := conventionalCommit{Type: "feat"}
+	fix := conventionalCommit{Type: "fix"}
+	breaking := conventionalCommit{Type: "fix", Breaking: true}
+	docs := conventionalCommit{Type: "docs"}
+
+	tt := []struct {
+		name    string
+		current string
+		commits []conventionalCommit
+		want    string
+		err     error
+	}{
+		{name: "patch", current: "v1.2.3", commits: []conventionalCommit{fix, docs}, want: "v1.2.4"},
+		{name: "minor", current: "v1.2.3", commits: []conventionalCommit{fix, feat}, want: "v1.3.0"},
+		{name: "major", current: "v1.2.3", commits: []conventionalCommit{feat, breaking}, want: "v2.0.0"},
+		{name: "breaking before v1", current: "v0.4.1", commits: []conventionalCommit{breaking}, want: "v0.5.0"},
+		{name: "first release", current: "v0.0.0", commits: []conventionalCommit{feat}, want: "v0.1.0"},
+		{name: "nothing", current: "v1.2.3", commits: []conventionalCommit{docs}, err: ErrNothingToRelease},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			got, err := nextVersion(v.current, v.commits)
+			if !errors.Is(err, v.err) || got != v.want {
+				t.Errorf("expected %s %v but got %s %v", v.want, v.err, got, err)
+			}
+		})
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	section := changelogSection("v1.3.0", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), []conventionalCommit{
+		{Hash: "0123456789abcdef", Type: "feat", Scope: "api", Subject: "add versions"},
+		{Hash: "fedcba9876543210", Type: "fix", Subject: "close bodies", Breaking: true, Note: "bodies must be closed by callers"},
+		{Type: "docs", Subject: "fix typo"},
+	})
+	want := "## v1.3.0 (2026-10-16)\n\n" +
+		"### Breaking Changes\n\n- bodies must be closed by callers (fedcba9)\n\n" +
+		"### Features\n\n- **api:** add versions (0123456)\n\n" +
+		"### Fixes\n\n- close bodies (fedcba9)\n"
+	if section != want {
+		t.Fatalf("expected\n%s\nbut got\n%s", want, section)
+	}
+
+	tt := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{name: "new", want: "# Changelog\n\n" + section},
+		{name: "with title", existing: "# Changelog\n\n## v1.2.0\n", want: "# Changelog\n\n" + section + "\n## v1.2.0\n"},
+		{name: "without title", existing: "## v1.2.0\n", want: section + "\n## v1.2.0\n"},
+	}
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := prependChangelog(v.existing, section); got != v.want {
+				t.Errorf("expected\n%q\nbut got\n%q", v.want, got)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	commit := func(message string) {
+		t.Helper()
+		if _, err := git(dir, "commit", "--allow-empty", "-q", "-m", message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"config", "user.email", "dev@example.com"}, {"config", "user.name", "dev"}, {"config", "commit.gpgsign", "false"}, {"config", "tag.gpgsign", "false"}} {
+		if _, err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit("feat: first")
+	if _, err := git(dir, "tag", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	commit("fix(http): close bodies")
+	commit("chore: tidy")
+
+	res, err := release(dir, "CHANGELOG.md", []string{"VERSION"}, time.Now(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Previous != "v1.0.0" || res.Version != "v1.0.1" {
+		t.Errorf("expected v1.0.0 -> v1.0.1 but got %s -> %s", res.Previous, res.Version)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "VERSION")); string(b) != "v1.0.1\n" {
+		t.Errorf("unexpected VERSION %q", b)
+	}
+
+	if err := tagRelease(dir, res); err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := git(dir, "describe", "--tags", "--exact-match"); err != nil || tag != "v1.0.1" {
+		t.Errorf("expected HEAD to be tagged v1.0.1 but got %q %v", tag, err)
+	}
+	if _, err := release(dir, "CHANGELOG.md", nil, time.Now(), true); !errors.Is(err, ErrNothingToRelease) {
+		t.Errorf("expected %v but got %v", ErrNothingToRelease, err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/replay"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Republishes a range of messages from a stream",
+	Long: `Reads the messages matching --subject from a stream, starting at --from-seq or --since and ending at
+--to-seq or --until, and republishes them to --target or to their subject under --target-prefix. Times can be
+RFC3339 or a duration before now like 2h. With --dry-run the range is only read and counted.`,
+	RunE:         replayRun,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().String("stream", "", "Stream to read from")
+	viper.BindPFlag("replay.stream", replayCmd.Flags().Lookup("stream"))
+	replayCmd.Flags().String("subject", "", "Subject filter, can contain wildcards")
+	viper.BindPFlag("replay.subject", replayCmd.Flags().Lookup("subject"))
+	replayCmd.Flags().Uint64("from-seq", 0, "First stream sequence to replay")
+	viper.BindPFlag("replay.from_seq", replayCmd.Flags().Lookup("from-seq"))
+	replayCmd.Flags().Uint64("to-seq", 0, "Last stream sequence to replay")
+	viper.BindPFlag("replay.to_seq", replayCmd.Flags().Lookup("to-seq"))
+	replayCmd.Flags().String("since", "", "Replay messages stored at or after this time")
+	viper.BindPFlag("replay.since", replayCmd.Flags().Lookup("since"))
+	replayCmd.Flags().String("until", "", "Replay messages stored at or before this time")
+	viper.BindPFlag("replay.until", replayCmd.Flags().Lookup("until"))
+	replayCmd.Flags().String("target", "", "Subject to republish every message to")
+	viper.BindPFlag("replay.target", replayCmd.Flags().Lookup("target"))
+	replayCmd.Flags().String("target-prefix", "", "Prefix to republish every message under its original subject")
+	viper.BindPFlag("replay.target_prefix", replayCmd.Flags().Lookup("target-prefix"))
+	replayCmd.Flags().Float64("rate", 100, "Messages per second, 0 for no limit")
+	viper.BindPFlag("replay.rate", replayCmd.Flags().Lookup("rate"))
+	replayCmd.Flags().StringArray("set-header", nil, "Header to set as Key: Value, can be repeated")
+	viper.BindPFlag("replay.set_headers", replayCmd.Flags().Lookup("set-header"))
+	replayCmd.Flags().StringArray("remove-header", nil, "Header to remove, can be repeated")
+	viper.BindPFlag("replay.remove_headers", replayCmd.Flags().Lookup("remove-header"))
+	replayCmd.Flags().StringArray("rename-header", nil, "Header to rename as Old:New, can be repeated")
+	viper.BindPFlag("replay.rename_headers", replayCmd.Flags().Lookup("rename-header"))
+	replayCmd.Flags().Duration("idle-timeout", 5*time.Second, "How long to wait for another message before finishing")
+	viper.BindPFlag("replay.idle_timeout", replayCmd.Flags().Lookup("idle-timeout"))
+	replayCmd.Flags().String("nats-urls", nats.DefaultURL, "NATS server urls")
+	viper.BindPFlag("replay.nats_urls", replayCmd.Flags().Lookup("nats-urls"))
+	replayCmd.Flags().String("nats-jwt", "", "NATS user JWT")
+	viper.BindPFlag("replay.nats_jwt", replayCmd.Flags().Lookup("nats-jwt"))
+	replayCmd.Flags().String("nats-seed", "", "NATS user seed")
+	viper.BindPFlag("replay.nats_seed", replayCmd.Flags().Lookup("nats-seed"))
+	replayCmd.Flags().String("credentials-file", "", "NATS credentials file")
+	viper.BindPFlag("replay.credentials_file", replayCmd.Flags().Lookup("credentials-file"))
+}
+
+func replayRun(cmd *cobra.Command, args []string) error {
+	rng, err := replayRange(time.Now())
+	if err != nil {
+		return err
+	}
+
+	rewrites, err := headerRewrites(
+		viper.GetStringSlice("replay.set_headers"),
+		viper.GetStringSlice("replay.remove_headers"),
+		viper.GetStringSlice("replay.rename_headers"),
+	)
+	if err != nil {
+		return err
+	}
+
+	opts := []replay.ReplayerOpt{
+		replay.SetRate(viper.GetFloat64("replay.rate")),
+		replay.SetIdleTimeout(viper.GetDuration("replay.idle_timeout")),
+		replay.SetHeaderRewrites(rewrites...),
+	}
+	target, prefix := viper.GetString("replay.target"), viper.GetString("replay.target_prefix")
+	switch {
+	case target != "" && prefix != "":
+		return fmt.Errorf("set only one of --target or --target-prefix")
+	case target != "":
+		opts = append(opts, replay.SetTarget(target))
+	case prefix != "":
+		opts = append(opts, replay.SetTargetPrefix(prefix))
+	}
+
+	nc, problems := connectNATS(
+		viper.GetString("replay.nats_urls"),
+		viper.GetString("replay.nats_jwt"),
+		viper.GetString("replay.nats_seed"),
+		viper.GetString("replay.credentials_file"),
+	)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", problems[0].Message)
+	}
+	defer nc.Close()
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return err
+	}
+
+	var pub replay.Publisher = nc
+	if viper.GetBool("dry_run") {
+		pub = nil
+	}
+
+	stats, err := replay.NewReplayer(js, pub, opts...).Run(cmd.Context(), rng)
+	fmt.Fprintf(cmd.OutOrStdout(), "read %d, published %d, sequences %d to %d in %s\n",
+		stats.Read, stats.Published, stats.FirstSeq, stats.LastSeq, stats.Duration.Round(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	return nc.Flush()
+}
+
+// replayRange builds the range from the flags
+func replayRange(now time.Time) (replay.Range, error) {
+	rng := replay.Range{
+		Stream:   viper.GetString("replay.stream"),
+		Subject:  viper.GetString("replay.subject"),
+		StartSeq: viper.GetUint64("replay.from_seq"),
+		EndSeq:   viper.GetUint64("replay.to_seq"),
+	}
+
+	var err error
+	if rng.Start, err = parseReplayTime(viper.GetString("replay.since"), now); err != nil {
+		return rng, fmt.Errorf("--since: %w", err)
+	}
+	if rng.End, err = parseReplayTime(viper.GetString("replay.until"), now); err != nil {
+		return rng, fmt.Errorf("--until: %w", err)
+	}
+
+	return rng, nil
+}
+
+// parseReplayTime parses an RFC3339 time or a duration before now. An empty string is the zero time.
+func parseReplayTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// headerRewrites parses the header flags. Sets are applied before removes and renames.
+func headerRewrites(set, remove, rename []string) ([]replay.HeaderRewrite, error) {
+	var rewrites []replay.HeaderRewrite
+	for _, h := range set {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, expected Key: Value", h)
+		}
+		rewrites = append(rewrites, replay.SetHeader(strings.TrimSpace(k), strings.TrimSpace(v)))
+	}
+	for _, h := range remove {
+		rewrites = append(rewrites, replay.RemoveHeader(h))
+	}
+	for _, h := range rename {
+		from, to, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header rename %q, expected Old:New", h)
+		}
+		rewrites = append(rewrites, replay.RenameHeader(strings.TrimSpace(from), strings.TrimSpace(to)))
+	}
+
+	return rewrites, nil
+}
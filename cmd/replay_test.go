@@ -0,0 +1,73 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestParseReplayTime(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		in   string
+		want time.Time
+		err  bool
+	}{
+		{in: ""},
+		{in: "2h", want: now.Add(-2 * time.Hour)},
+		{in: "2025-02-28T09:30:00Z", want: time.Date(2025, 2, 28, 9, 30, 0, 0, time.UTC)},
+		{in: "yesterday", err: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.in, func(t *testing.T) {
+			got, err := parseReplayTime(v.in, now)
+			if (err != nil) != v.err {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+			if !got.Equal(v.want) {
+				t.Errorf("expected %s but got %s", v.want, got)
+			}
+		})
+	}
+}
+
+func TestHeaderRewrites(t *testing.T) {
+	rewrites, err := headerRewrites([]string{"Reason: bug 12"}, []string{"Auth"}, []string{"Trace-Id:Original-Trace-Id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := nats.Header{}
+	h.Set("Auth", "token")
+	h.Set("Trace-Id", "abc")
+	for _, rw := range rewrites {
+		rw(h)
+	}
+
+	want := map[string]string{"Reason": "bug 12", "Auth": "", "Trace-Id": "", "Original-Trace-Id": "abc"}
+	for k, v := range want {
+		if got := h.Get(k); got != v {
+			t.Errorf("expected %s to be %q but got %q", k, v, got)
+		}
+	}
+
+	if _, err := headerRewrites([]string{"Reason"}, nil, nil); err == nil {
+		t.Error("expected an error for a header without a value")
+	}
+}
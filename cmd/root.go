@@ -36,6 +36,7 @@ var cfg Config
 
 type Config struct {
 	Debug   bool    `mapstructure:"debug"`
+	DryRun  bool    `mapstructure:"dry_run"`
 	Service Service `mapstructure:"service"`
 	Level   slog.LevelVar
 }
@@ -44,15 +45,24 @@ type Service struct {
 	Namespace         string `mapstructure:"namespace"`
 	Domain            string `mapstructure:"domain"`
 	Module            string
-	EnableTelemetry   bool   `mapstructure:"enable_telemetry"`
-	DisableDeployment bool   `mapstructure:"disable_deployment"`
-	MetricsUrl        string `mapstructure:"metrics_url"`
-	EnableHTTP        bool   `mapstructure:"enable_http"`
-	NatsSubject       string `mapstructure:"nats_subject"`
-	NatsServers       string `mapstructure:"nats_servers"`
-	EnableGraphql     bool   `mapstructure:"enable_graphql"`
-	EnableEdgeDB      bool   `mapstructure:"enable_edgedb"`
-	ContainerRegistry string `mapstructure:"container_registry"`
+	EnableTelemetry   bool     `mapstructure:"enable_telemetry"`
+	DisableDeployment bool     `mapstructure:"disable_deployment"`
+	MetricsUrl        string   `mapstructure:"metrics_url"`
+	EnableHTTP        bool     `mapstructure:"enable_http"`
+	NatsSubject       string   `mapstructure:"nats_subject"`
+	NatsServers       string   `mapstructure:"nats_servers"`
+	EnableGraphql     bool     `mapstructure:"enable_graphql"`
+	EnableEdgeDB      bool     `mapstructure:"enable_edgedb"`
+	EnablePostgres    bool     `mapstructure:"enable_postgres"`
+	ContainerRegistry string   `mapstructure:"container_registry"`
+	TemplateDir       string   `mapstructure:"template_dir"`
+	Monorepo          bool     `mapstructure:"monorepo"`
+	ModulePrefix      string   `mapstructure:"module_prefix"`
+	Terraform         []string `mapstructure:"terraform"`
+	OutputDir         string
+
+	// templates are the names of the templates rendered so far, hashed into the lock file
+	templates []string
 }
 
 func Execute() {
@@ -69,6 +79,8 @@ func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "Print output instead of creating files")
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Print a diff of the files that would change instead of writing them")
+	viper.BindPFlag("dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
 }
 
 func initConfig() {
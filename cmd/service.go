@@ -15,14 +15,17 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"strings"
+	"path/filepath"
+	"slices"
 	"text/template"
 
 	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+	"github.com/SencilloDev/sencillo-go/internal/diff"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,6 +45,8 @@ func init() {
 	viper.BindPFlag("service.enable_telemetry", serviceCmd.Flags().Lookup("enable-telemetry"))
 	serviceCmd.Flags().Bool("enable-edgedb", false, "Enable EdgeDB integration")
 	viper.BindPFlag("service.enable_edgedb", serviceCmd.Flags().Lookup("enable-edgedb"))
+	serviceCmd.Flags().Bool("enable-postgres", false, "Add Postgres to the local docker compose stack")
+	viper.BindPFlag("service.enable_postgres", serviceCmd.Flags().Lookup("enable-postgres"))
 	serviceCmd.Flags().StringP("name", "n", "", "Application name")
 	serviceCmd.MarkFlagRequired("name")
 	serviceCmd.PersistentFlags().String("namespace", "default", "Namespace for deployment")
@@ -61,6 +66,14 @@ func init() {
 	viper.BindPFlag("service.container_registry", serviceCmd.PersistentFlags().Lookup("container-registry"))
 	serviceCmd.PersistentFlags().String("domain", "example.com", "Domain for ingress URLs")
 	viper.BindPFlag("service.domain", serviceCmd.PersistentFlags().Lookup("domain"))
+	serviceCmd.PersistentFlags().String("template-dir", "", "Directory of templates that override the built in ones")
+	viper.BindPFlag("service.template_dir", serviceCmd.PersistentFlags().Lookup("template-dir"))
+	serviceCmd.PersistentFlags().Bool("monorepo", false, "Generate the service into services/<name> of a monorepo")
+	viper.BindPFlag("service.monorepo", serviceCmd.PersistentFlags().Lookup("monorepo"))
+	serviceCmd.PersistentFlags().String("module-prefix", "", "Module path of the monorepo, defaults to the module in ./go.mod")
+	viper.BindPFlag("service.module_prefix", serviceCmd.PersistentFlags().Lookup("module-prefix"))
+	serviceCmd.PersistentFlags().StringSlice("terraform", nil, "Generate Terraform modules for the given deploy targets (aws, gcp)")
+	viper.BindPFlag("service.terraform", serviceCmd.PersistentFlags().Lookup("terraform"))
 }
 
 type Delims struct {
@@ -70,23 +83,47 @@ type Delims struct {
 
 type CreateFileFromTemplate func(s *Service) error
 
+// terraformTargets are the deploy targets Terraform modules can be generated for
+var terraformTargets = []string{"aws", "gcp"}
+
 var dd Delims
 
 func service(cmd *cobra.Command, args []string) error {
-	mod := modInfo()
-	if mod == "command-line-arguments" {
-		return fmt.Errorf("you must initialize a module with `go mod init <MODNAME>`")
+	if cfg.Service.Monorepo {
+		if err := cfg.Service.setupMonorepo(); err != nil {
+			return err
+		}
+	} else {
+		mod := modInfo()
+		if mod == "command-line-arguments" {
+			return fmt.Errorf("you must initialize a module with `go mod init <MODNAME>`")
+		}
+		cfg.Service.Module = mod
 	}
-	cfg.Service.Module = mod
+	tpl.SetOverlay(cfg.Service.TemplateDir)
 
-	if !cfg.Debug {
-		dirs := []string{"./cmd", "./service", "./.github/workflows"}
+	for _, v := range cfg.Service.Terraform {
+		if !slices.Contains(terraformTargets, v) {
+			return fmt.Errorf("unknown terraform target %q, must be one of %v", v, terraformTargets)
+		}
+	}
+
+	if !cfg.Debug && !cfg.DryRun {
+		dirs := []string{"./cmd", "./service"}
 		if cfg.Service.EnableGraphql {
 			dirs = append(dirs, "./graph")
 		}
 		if cfg.Service.EnableEdgeDB {
 			dirs = append(dirs, "./dbschema")
 		}
+		for _, v := range cfg.Service.Terraform {
+			dirs = append(dirs, filepath.Join("./deploy/terraform", v))
+		}
+		for i, v := range dirs {
+			dirs[i] = filepath.Join(cfg.Service.OutputDir, v)
+		}
+		// workflows live at the root of a monorepo
+		dirs = append(dirs, "./.github/workflows")
 		for _, v := range dirs {
 			if _, err := os.Stat(v); os.IsNotExist(err) {
 				if err := os.MkdirAll(v, 0755); err != nil {
@@ -97,51 +134,72 @@ func service(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	goreleaserDelims := Delims{First: "[%", Second: "%]"}
+
 	// files we always create
 	opts := []CreateFileFromTemplate{
-		createMain(dd),
-		createRoot(dd),
-		createService(dd),
-		createServiceStart(dd),
-		createVersion(dd),
-		createMakefile(dd),
-		createDockerfile(dd),
-		createGoReleaser(Delims{First: "[%", Second: "%]"}),
-		createTestWorkflow(Delims{First: "[%", Second: "%]"}),
-		createGitignore(dd),
-		createReleaseWorkflow(Delims{First: "[%", Second: "%]"}),
-		createFlags(dd),
-		createDocs(dd),
-		createNats(dd),
-		createNatsCmdHelper(dd),
+		fromTemplate("main.go", dd),
+		fromTemplate("cmd/root.go", dd),
+		fromTemplate("cmd/service.go", dd),
+		fromTemplate("cmd/start.go", dd),
+		fromTemplate("Makefile", dd),
+		fromTemplate("Dockerfile", dd),
+		fromTemplate(".goreleaser.yaml", goreleaserDelims),
+		fromTemplate(".gitignore", dd),
+		fromTemplate("service/nats.go", dd),
+		fromTemplate("cmd/nats.go", dd),
+		fromTemplate("docker-compose.yaml", dd),
+	}
+
+	if cfg.Service.EnableTelemetry {
+		opts = append(opts,
+			fromTemplate("otel-collector.yaml", dd),
+		)
+	}
+
+	if cfg.Service.Monorepo {
+		opts = append(opts, monorepoFiles(goreleaserDelims)...)
+	} else {
+		opts = append(opts,
+			fromTemplate(".github/workflows/test.yaml", goreleaserDelims),
+			fromTemplate(".github/workflows/release.yaml", goreleaserDelims),
+		)
 	}
 
 	if cfg.Service.EnableEdgeDB {
 		opts = append(opts,
-			createEdgedbToml(dd),
-			createEdgedbDefault(dd),
+			fromTemplate("edgedb.toml", dd),
+			fromTemplate("dbschema/default.esdl", dd),
 		)
 	}
 
 	if cfg.Service.EnableHTTP {
 		opts = append(opts,
-			createServicePackage(dd),
+			fromTemplate("service/server.go", dd),
 		)
 	}
 
 	// graphql
 	if cfg.Service.EnableGraphql {
 		opts = append(opts,
-			createClient(dd),
-			createGQLGen(dd),
-			createSchemaGraphql(dd),
-			createResolver(dd),
-			createModelsGen(dd),
-			createSchemaResolver(dd),
-			createTools(dd),
+			fromTemplate("graph/client.go", dd),
+			fromTemplate("gqlgen.yaml", dd),
+			fromTemplate("graph/schema.graphqls", dd),
+			fromTemplate("graph/resolver.go", dd),
+			fromTemplate("graph/models_gen.go", dd),
+			fromTemplate("graph/schema.resolvers.go", dd),
+			fromTemplate("tools.go", dd),
 		)
 	}
 
+	for _, v := range cfg.Service.Terraform {
+		for _, f := range []string{"main.tf", "variables.tf", "outputs.tf"} {
+			opts = append(opts, fromTemplate("deploy/terraform/"+v+"/"+f, dd))
+		}
+	}
+
+	opts = append(opts, writeLock)
+
 	err := cfg.Service.CreateFilesFromTemplates(opts...)
 
 	if err != nil {
@@ -151,162 +209,17 @@ func service(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// core files needed for any project
-// templates under project.go
-func createMain(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("main.go", tpl.Main(), dd)
-	}
-}
-
-func createRoot(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/root.go", tpl.Root(), dd)
-	}
-}
-
-func createService(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/service.go", tpl.Service(), dd)
-	}
-}
-
-func createServiceStart(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/start.go", tpl.ServiceStart(), dd)
-	}
-}
-
-func createServicePackage(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("service/server.go", tpl.ServicePackage(), dd)
-	}
-}
-
-func createVersion(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/version.go", tpl.Version(), dd)
-	}
-}
-
-func createFlags(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/flags.go", tpl.Flags(), dd)
-	}
-}
-
-func createDocs(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/docs.go", tpl.Docs(), dd)
-	}
-}
-
-func createNatsCmdHelper(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("cmd/nats.go", tpl.NatsHelper(), dd)
-	}
-}
-
-// build and deployments
-// templates under deployment.go
-func createMakefile(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("Makefile", tpl.Makefile(), dd)
-	}
-}
-
-func createDockerfile(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("Dockerfile", tpl.Dockerfile(), dd)
-	}
-}
-
-func createGoReleaser(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile(".goreleaser.yaml", tpl.GoReleaser(), dd)
-	}
-}
-
-func createTestWorkflow(dd Delims) CreateFileFromTemplate {
+// fromTemplate renders the template for the named file from the tpl package to the same path in the
+// service's output directory
+func fromTemplate(name string, dd Delims) CreateFileFromTemplate {
 	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile(".github/workflows/test.yaml", tpl.TestWorkflow(), dd)
-	}
-}
-
-func createReleaseWorkflow(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile(".github/workflows/release.yaml", tpl.ReleaseWorkflow(), dd)
-	}
-}
-
-func createGitignore(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile(".gitignore", tpl.Gitignore(), dd)
-	}
-}
-
-// graphql stuff
-// templates under graphql.go
-func createClient(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("graph/client.go", tpl.Client(), dd)
-	}
-}
-
-func createGQLGen(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("gqlgen.yaml", tpl.GQLGen(), dd)
-	}
-}
-
-func createSchemaGraphql(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("graph/schema.graphqls", tpl.SchemaGraphqls(), dd)
-	}
-}
-
-func createResolver(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("graph/resolver.go", tpl.Resolvers(), dd)
-	}
-}
-
-func createModelsGen(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("graph/models_gen.go", tpl.ModelsGen(), dd)
-	}
-}
-
-func createSchemaResolver(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("graph/schema.resolvers.go", tpl.SchemaResolvers(), dd)
-	}
-}
-
-func createTools(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("tools.go", tpl.Tools(), dd)
-	}
-}
-
-// nats
-// templates under nats.go
-func createNats(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("service/nats.go", tpl.Nats(), dd)
-	}
-}
+		b, err := tpl.Read(name)
+		if err != nil {
+			return fmt.Errorf("error reading template %s: %w", name, err)
+		}
+		s.templates = append(s.templates, name)
 
-// edgedb
-// templates under edgedb.go
-func createEdgedbToml(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("edgedb.toml", tpl.EdgeDBToml(), dd)
-	}
-}
-func createEdgedbDefault(dd Delims) CreateFileFromTemplate {
-	return func(s *Service) error {
-		return cfg.Service.createOrPrintFile("dbschema/default.esdl", tpl.DefaultEsdl(), dd)
+		return s.createOrPrintFile(filepath.Join(s.OutputDir, name), b, dd)
 	}
 }
 
@@ -326,26 +239,45 @@ func (s *Service) createOrPrintFile(n string, b []byte, d Delims) error {
 		d.Second = "}}"
 	}
 
+	var rendered bytes.Buffer
+	if err := s.handleOutput(&rendered, b, d); err != nil {
+		return err
+	}
+
+	return writeOutput(n, rendered.Bytes())
+}
+
+// writeOutput prints the file, prints a diff against the existing file, or writes it depending on the debug
+// and dry-run flags
+func writeOutput(n string, b []byte) error {
 	if cfg.Debug {
-		return s.handleOutput(os.Stdout, b, d)
+		_, err := os.Stdout.Write(b)
+		return err
 	}
 
-	f, err := os.Create(n)
-	if err != nil {
-		return fmt.Errorf("error creating file: %s", err)
+	if cfg.DryRun {
+		existing, err := os.ReadFile(n)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading file: %s", err)
+		}
+
+		diff.Write(os.Stdout, n, string(existing), string(b))
+		return nil
 	}
 
-	defer f.Close()
+	if err := os.WriteFile(n, b, 0644); err != nil {
+		return fmt.Errorf("error creating file: %s", err)
+	}
 
-	return s.handleOutput(f, b, d)
+	return nil
 }
 
 func (s *Service) handleOutput(w io.Writer, b []byte, d Delims) error {
-	fmap := template.FuncMap{
-		"ToUpper": strings.ToUpper,
-		"ToLower": strings.ToLower,
+	temp, err := template.New("file").Delims(d.First, d.Second).Funcs(tpl.FuncMap()).Parse(string(b))
+	if err != nil {
+		return fmt.Errorf("error parsing template: %s", err)
 	}
-	temp := template.Must(template.New("file").Delims(d.First, d.Second).Funcs(fmap).Parse(string(b)))
+
 	if err := temp.Execute(w, s); err != nil {
 		return fmt.Errorf("error executing template: %s", err)
 	}
@@ -0,0 +1,122 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/registry"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var servicesCmd = &cobra.Command{
+	Use:   "services",
+	Short: "Inspects running services",
+}
+
+var servicesListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "Lists the service instances in the registry",
+	RunE:         servicesList,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(servicesCmd)
+	servicesCmd.AddCommand(servicesListCmd)
+	servicesCmd.PersistentFlags().String("nats-urls", nats.DefaultURL, "NATS server urls")
+	viper.BindPFlag("services.nats_urls", servicesCmd.PersistentFlags().Lookup("nats-urls"))
+	servicesCmd.PersistentFlags().String("nats-jwt", "", "NATS user JWT")
+	viper.BindPFlag("services.nats_jwt", servicesCmd.PersistentFlags().Lookup("nats-jwt"))
+	servicesCmd.PersistentFlags().String("nats-seed", "", "NATS user seed")
+	viper.BindPFlag("services.nats_seed", servicesCmd.PersistentFlags().Lookup("nats-seed"))
+	servicesCmd.PersistentFlags().String("credentials-file", "", "NATS credentials file")
+	viper.BindPFlag("services.credentials_file", servicesCmd.PersistentFlags().Lookup("credentials-file"))
+	servicesCmd.PersistentFlags().String("bucket", registry.DefaultBucket, "KV bucket of the registry")
+	viper.BindPFlag("services.bucket", servicesCmd.PersistentFlags().Lookup("bucket"))
+	servicesListCmd.Flags().StringP("output", "o", "table", "Output format, table or json")
+	viper.BindPFlag("services.output", servicesListCmd.Flags().Lookup("output"))
+}
+
+func servicesList(cmd *cobra.Command, args []string) error {
+	nc, problems := connectNATS(
+		viper.GetString("services.nats_urls"),
+		viper.GetString("services.nats_jwt"),
+		viper.GetString("services.nats_seed"),
+		viper.GetString("services.credentials_file"),
+	)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", problems[0].Message)
+	}
+	defer nc.Close()
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return err
+	}
+
+	kv, err := js.KeyValue(viper.GetString("services.bucket"))
+	if err != nil {
+		return fmt.Errorf("error opening registry: %w", err)
+	}
+
+	instances, err := registry.NewRegistry(kv).List(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if viper.GetString("services.output") == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(instances)
+	}
+
+	return writeInstances(cmd.OutOrStdout(), instances, time.Now())
+}
+
+// writeInstances prints the instances as a table
+func writeInstances(w io.Writer, instances []registry.Instance, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tID\tHEALTH\tENDPOINTS\tUPTIME\tLAST SEEN")
+	for _, v := range instances {
+		var endpoints []string
+		for _, e := range v.Endpoints {
+			if e.Subject != "" {
+				endpoints = append(endpoints, e.Subject)
+			} else {
+				endpoints = append(endpoints, e.URL)
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s ago\n",
+			v.Name,
+			v.Version,
+			v.ID,
+			v.Health,
+			strings.Join(endpoints, ","),
+			now.Sub(v.Started).Round(time.Second),
+			now.Sub(v.Heartbeat).Round(time.Second),
+		)
+	}
+
+	return tw.Flush()
+}
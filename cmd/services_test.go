@@ -0,0 +1,49 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/registry"
+)
+
+func TestWriteInstances(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	instances := []registry.Instance{
+		{
+			Name:      "orders",
+			ID:        "abc",
+			Version:   "1.2.0",
+			Health:    registry.HealthOK,
+			Endpoints: []registry.Endpoint{{Subject: "orders.create"}, {URL: "http://orders:8080/api"}},
+			Started:   now.Add(-time.Hour),
+			Heartbeat: now.Add(-3 * time.Second),
+		},
+	}
+
+	var out bytes.Buffer
+	if err := writeInstances(&out, instances, now); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NAME    VERSION  ID   HEALTH  ENDPOINTS                             UPTIME  LAST SEEN\n" +
+		"orders  1.2.0    abc  ok      orders.create,http://orders:8080/api  1h0m0s  3s ago\n"
+	if out.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/cmd/tpl"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+func TestTemplatesGolden(t *testing.T) {
+	s := &Service{
+		Name:        "orders",
+		Module:      "example.com/orders",
+		NatsSubject: "orders",
+		NatsServers: "nats://localhost:4222",
+		EnableHTTP:  true,
+		Terraform:   []string{"aws"},
+	}
+
+	for _, name := range []string{"main.go", "cmd/root.go", "cmd/service.go", "cmd/start.go", "cmd/nats.go", "Dockerfile", "deploy/terraform/aws/main.tf"} {
+		t.Run(name, func(t *testing.T) {
+			b, err := tpl.Read(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := s.handleOutput(&out, b, Delims{First: "{{", Second: "}}"}); err != nil {
+				t.Fatal(err)
+			}
+
+			sdtest.Golden(t, "templates/"+name, out.String())
+		})
+	}
+}
+
+func TestComposeGolden(t *testing.T) {
+	// a dashed name checks the environment variable names
+	s := &Service{
+		Name:            "order-api",
+		Module:          "example.com/order-api",
+		NatsSubject:     "orders",
+		EnableHTTP:      true,
+		EnablePostgres:  true,
+		EnableTelemetry: true,
+	}
+
+	b, err := tpl.Read("docker-compose.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := s.handleOutput(&out, b, Delims{First: "{{", Second: "}}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sdtest.Golden(t, "templates/docker-compose.yaml", out.String())
+}
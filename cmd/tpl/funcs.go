@@ -0,0 +1,158 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FuncMap returns the functions available to every template
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"ToUpper":  strings.ToUpper,
+		"ToLower":  strings.ToLower,
+		"Title":    title,
+		"Camel":    camel,
+		"Pascal":   pascal,
+		"Snake":    func(s string) string { return strings.Join(lowerWords(s), "_") },
+		"Kebab":    func(s string) string { return strings.Join(lowerWords(s), "-") },
+		"Default":  defaultValue,
+		"Indent":   indent,
+		"NIndent":  func(n int, s string) string { return "\n" + indent(n, s) },
+		"ToYaml":   toYaml,
+		"Quote":    func(s string) string { return fmt.Sprintf("%q", s) },
+		"Trim":     strings.TrimSpace,
+		"Replace":  func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"Join":     func(sep string, s []string) string { return strings.Join(s, sep) },
+		"Contains": func(substr, s string) bool { return strings.Contains(s, substr) },
+		// EnvPrefix matches the prefix cli.App gives environment variables
+		"EnvPrefix": func(s string) string { return strings.ToUpper(strings.ReplaceAll(s, "-", "_")) },
+	}
+}
+
+// words splits s on separators and lower to upper case transitions, so "my-service", "my_service", and
+// "myService" all become [my service]
+func words(s string) []string {
+	var out []string
+	var current []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				out = append(out, string(current))
+				current = nil
+			}
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(current) > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			out = append(out, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		out = append(out, string(current))
+	}
+
+	return out
+}
+
+func lowerWords(s string) []string {
+	w := words(s)
+	for i, v := range w {
+		w[i] = strings.ToLower(v)
+	}
+
+	return w
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func pascal(s string) string {
+	w := lowerWords(s)
+	for i, v := range w {
+		w[i] = title(v)
+	}
+
+	return strings.Join(w, "")
+}
+
+func camel(s string) string {
+	p := []rune(pascal(s))
+	if len(p) > 0 {
+		p[0] = unicode.ToLower(p[0])
+	}
+
+	return string(p)
+}
+
+// defaultValue returns value unless it is the zero value for its type, in which case def is returned. The
+// argument order allows piping: {{ .Namespace | Default "default" }}
+func defaultValue(def any, value any) any {
+	switch v := value.(type) {
+	case nil:
+		return def
+	case string:
+		if v == "" {
+			return def
+		}
+	case bool:
+		if !v {
+			return def
+		}
+	case int:
+		if v == 0 {
+			return def
+		}
+	}
+
+	return value
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, v := range lines {
+		if v != "" {
+			lines[i] = pad + v
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func toYaml(v any) (string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
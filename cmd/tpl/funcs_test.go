@@ -0,0 +1,77 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	tt := []struct {
+		name string
+		tmpl string
+		data any
+		want string
+	}{
+		{name: "pascal", tmpl: `{{ Pascal . }}`, data: "my-service_name", want: "MyServiceName"},
+		{name: "camel", tmpl: `{{ Camel . }}`, data: "MyHTTPServer", want: "myHttpServer"},
+		{name: "snake", tmpl: `{{ Snake . }}`, data: "myService", want: "my_service"},
+		{name: "kebab", tmpl: `{{ Kebab . }}`, data: "My Service", want: "my-service"},
+		{name: "env prefix", tmpl: `{{ EnvPrefix . }}_PORT`, data: "order-api", want: "ORDER_API_PORT"},
+		{name: "default", tmpl: `{{ . | Default "default" }}`, data: "", want: "default"},
+		{name: "default set", tmpl: `{{ . | Default "default" }}`, data: "prod", want: "prod"},
+		{name: "indent", tmpl: `{{ Indent 2 . }}`, data: "a\n\nb", want: "  a\n\n  b"},
+		{name: "yaml", tmpl: `{{ ToYaml . | NIndent 2 }}`, data: map[string]int{"replicas": 2}, want: "\n  replicas: 2"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := template.Must(template.New("").Funcs(FuncMap()).Parse(v.tmpl)).Execute(&b, v.data); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != v.want {
+				t.Errorf("expected %q but got %q", v.want, b.String())
+			}
+		})
+	}
+}
+
+func TestTemplatesParse(t *testing.T) {
+	names, err := Names()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		b, err := Read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// GitHub workflows and goreleaser use {{ }} themselves so they are rendered with [% %]
+		left, right := "{{", "}}"
+		if strings.Contains(name, ".github/") || name == ".goreleaser.yaml" {
+			left, right = "[%", "%]"
+		}
+
+		if _, err := template.New(name).Delims(left, right).Funcs(FuncMap()).Parse(string(b)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
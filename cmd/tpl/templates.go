@@ -0,0 +1,98 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpl
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// templates holds the files the generator renders, named after the file they produce with a .tmpl suffix
+//
+//go:embed all:templates
+var templates embed.FS
+
+var overlay fs.FS
+
+// SetOverlay makes templates in dir take precedence over the built in ones. Files are matched by their path
+// relative to dir, so dir/Makefile.tmpl replaces the Makefile template.
+func SetOverlay(dir string) {
+	if dir == "" {
+		overlay = nil
+		return
+	}
+
+	overlay = os.DirFS(dir)
+}
+
+// Read returns the template for the named output file, checking the overlay first
+func Read(name string) ([]byte, error) {
+	if overlay != nil {
+		b, err := fs.ReadFile(overlay, name+".tmpl")
+		if err == nil {
+			return b, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return templates.ReadFile("templates/" + name + ".tmpl")
+}
+
+// Names returns the output file names of every built in template
+func Names() ([]string, error) {
+	var names []string
+	err := fs.WalkDir(templates, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		names = append(names, path[len("templates/"):len(path)-len(".tmpl")])
+		return nil
+	})
+
+	return names, err
+}
+
+// Hash returns a digest of the named templates' names and contents, including overrides from the overlay.
+// Without names it hashes every template, which is what locks written before the names were recorded hold.
+// It changes whenever the generated output for the same options would change.
+func Hash(names ...string) (string, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = Names(); err != nil {
+			return "", err
+		}
+	}
+
+	h := sha256.New()
+	for _, v := range names {
+		b, err := Read(v)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
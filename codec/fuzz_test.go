@@ -0,0 +1,109 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type fuzzOrder struct {
+	ID    string            `json:"id" xml:"id,attr"`
+	Qty   int               `json:"qty" xml:"qty"`
+	Price float64           `json:"price" xml:"price"`
+	Tags  []string          `json:"tags" xml:"tag"`
+	Notes map[string]string `json:"notes" xml:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	tt := []struct {
+		name    string
+		codec   Codec
+		data    string
+		invalid bool
+	}{
+		{name: "json", codec: JSON{}, data: `{"id":"a","qty":2,"tags":["x"]}`},
+		{name: "xml", codec: XML{}, data: `<fuzzOrder id="a"><qty>2</qty><tag>x</tag></fuzzOrder>`},
+		{name: "invalid", codec: JSON{}, data: `{"id":`, invalid: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			err := RoundTrip(v.codec, []byte(v.data), &fuzzOrder{})
+			if errors.Is(err, ErrRoundTrip) || (err != nil) != v.invalid {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func FuzzJSONRoundTrip(f *testing.F) {
+	for _, v := range []string{`{"id":"a","qty":1,"price":1.5,"tags":["x"],"notes":{"k":"v"}}`, `{"id":"é\"<>"}`, `{"price":1e308}`, `null`} {
+		f.Add([]byte(v))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := RoundTrip(JSON{}, data, &fuzzOrder{}); errors.Is(err, ErrRoundTrip) {
+			t.Fatal(err)
+		}
+		var anyValue any
+		if err := RoundTrip(JSON{}, data, &anyValue); errors.Is(err, ErrRoundTrip) {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzXMLRoundTrip(f *testing.F) {
+	for _, v := range []string{`<fuzzOrder id="a"><qty>1</qty><price>1.5</price><tag>x</tag></fuzzOrder>`, `<fuzzOrder id="&lt;&amp;"></fuzzOrder>`} {
+		f.Add([]byte(v))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := RoundTrip(XML{}, data, &fuzzOrder{}); errors.Is(err, ErrRoundTrip) {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzDecodeNDJSON(f *testing.F) {
+	f.Add([]byte("{\"id\":\"a\"}\n\n{\"id\":\"b\"}\n"))
+	f.Add([]byte("{\"id\":\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var lines int
+		DecodeNDJSON(context.Background(), bytes.NewReader(data), func(o fuzzOrder) error {
+			lines++
+			return nil
+		})
+		if n := bytes.Count(data, []byte("\n")) + 1; lines > n {
+			t.Errorf("decoded %d records from %d lines", lines, n)
+		}
+	})
+}
+
+func FuzzNegotiate(f *testing.F) {
+	for _, v := range []string{"application/json", "application/xml;q=0.9, */*;q=0.1", "text/*;q=abc", ",,;;", ""} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, accept string) {
+		c, err := DefaultRegistry.Negotiate(accept)
+		if err == nil && c == nil {
+			t.Errorf("no codec and no error for %q", accept)
+		}
+	})
+}
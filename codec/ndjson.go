@@ -0,0 +1,59 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NDJSONContentType is the content type for newline delimited JSON streams
+const NDJSONContentType = "application/x-ndjson"
+
+// maxNDJSONLine is the longest record DecodeNDJSON accepts
+const maxNDJSONLine = 10 << 20
+
+// DecodeNDJSON decodes each line of r into a T and calls fn with it until r is exhausted, fn returns an
+// error, or ctx is done. Blank lines are skipped.
+func DecodeNDJSON[T any](ctx context.Context, r io.Reader, fn func(T) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("error decoding line %d: %w", line, err)
+		}
+
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
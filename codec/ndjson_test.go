@@ -0,0 +1,56 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNDJSON(t *testing.T) {
+	type record struct {
+		ID int `json:"id"`
+	}
+
+	tt := []struct {
+		name  string
+		input string
+		want  []int
+		err   bool
+	}{
+		{name: "records", input: "{\"id\":1}\n\n{\"id\":2}\n", want: []int{1, 2}},
+		{name: "no trailing newline", input: `{"id":1}`, want: []int{1}},
+		{name: "invalid line", input: "{\"id\":1}\nnope\n", want: []int{1}, err: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var got []int
+			err := DecodeNDJSON(context.Background(), strings.NewReader(v.input), func(r record) error {
+				got = append(got, r.ID)
+				return nil
+			})
+
+			if (err != nil) != v.err {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(v.want) {
+				t.Errorf("expected %v but got %v", v.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// RedactedValue replaces fields tagged with `sencillo:"redact"`
+const RedactedValue = "[REDACTED]"
+
+// ErrCustomMarshaler is returned when a type with redaction tags has its own MarshalJSON or MarshalText, which
+// would encode the fields Redact hides
+var ErrCustomMarshaler = fmt.Errorf("type with redaction tags has a custom marshaler")
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type rolesKey struct{}
+
+// WithRoles returns a copy of the context carrying the roles of the caller
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// RolesFrom returns the roles of the caller from the context
+func RolesFrom(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
+
+// Redactor marshals values with a Codec after removing fields the caller isn't allowed to see.
+//
+// Fields tagged `sencillo:"redact"` are masked, fields tagged `sencillo:"omit"` are zeroed, and fields tagged
+// `view:"admin,support"` are zeroed unless the caller has one of the listed roles. The value keeps its type, so
+// any codec can encode it. Tag hidden fields with omitempty to leave them out of the output entirely.
+type Redactor struct {
+	Codec Codec
+}
+
+// NewRedactor returns a Redactor using the codec, or JSON if it is nil
+func NewRedactor(c Codec) *Redactor {
+	if c == nil {
+		c = JSON{}
+	}
+
+	return &Redactor{Codec: c}
+}
+
+// Marshal encodes the value for a caller with the roles from the context
+func (r *Redactor) Marshal(ctx context.Context, v any) ([]byte, error) {
+	redacted, err := Redact(v, RolesFrom(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Codec.Marshal(redacted)
+}
+
+// Redact returns a deep copy of the value with the fields hidden from the roles zeroed or masked. Masked strings
+// are set to RedactedValue and other masked types to their zero value. Values of types with a custom
+// MarshalJSON or MarshalText are copied as is, since their marshaler decides what is encoded, unless the type
+// has redaction tags, which returns ErrCustomMarshaler. Pointer cycles are preserved in the copy.
+func Redact[T any](v T, roles ...string) (T, error) {
+	r := redactor{
+		roles: make(map[string]bool, len(roles)),
+		seen:  make(map[visit]reflect.Value),
+	}
+	for _, role := range roles {
+		r.roles[role] = true
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	out, err := r.copy(rv)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return out.Interface().(T), nil
+}
+
+// visit identifies a pointer, map, or slice already copied, so cycles are copied once
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type redactor struct {
+	roles map[string]bool
+	seen  map[visit]reflect.Value
+}
+
+func (r *redactor) copy(v reflect.Value) (reflect.Value, error) {
+	t := v.Type()
+	if t.Implements(jsonMarshaler) || t.Implements(textMarshaler) ||
+		reflect.PointerTo(t).Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+		if hasTags(t, map[reflect.Type]bool{}) {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrCustomMarshaler, t)
+		}
+		return v, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+		key := visit{ptr: v.Pointer(), typ: t}
+		if c, ok := r.seen[key]; ok {
+			return c, nil
+		}
+		out := reflect.New(t.Elem())
+		r.seen[key] = out
+		elem, err := r.copy(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.Elem().Set(elem)
+		return out, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := r.copy(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t).Elem()
+		out.Set(elem)
+		return out, nil
+	case reflect.Struct:
+		return r.copyStruct(v)
+	case reflect.Slice:
+		if v.IsNil() || t.Elem().Kind() == reflect.Uint8 {
+			return v, nil
+		}
+		key := visit{ptr: v.Pointer(), typ: t, len: v.Len()}
+		if c, ok := r.seen[key]; ok {
+			return c, nil
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		r.seen[key] = out
+		for i := 0; i < v.Len(); i++ {
+			elem, err := r.copy(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			elem, err := r.copy(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		key := visit{ptr: v.Pointer(), typ: t}
+		if c, ok := r.seen[key]; ok {
+			return c, nil
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		r.seen[key] = out
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := r.copy(iter.Value())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// copyStruct copies the struct and then replaces its exported and embedded fields. Other unexported fields keep
+// the shallow copy.
+func (r *redactor) copyStruct(v reflect.Value) (reflect.Value, error) {
+	t := v.Type()
+	out := reflect.New(t).Elem()
+	out.Set(v)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := out.Field(i)
+		src := v.Field(i)
+		if !fv.CanSet() {
+			if !f.Anonymous {
+				continue
+			}
+			// exported fields promoted through an unexported embedded struct are encoded, so they're redacted
+			// through a settable view of the field in the copy
+			fv = reflect.NewAt(f.Type, unsafe.Pointer(fv.UnsafeAddr())).Elem()
+			src = fv
+		}
+
+		if !r.visible(f) {
+			fv.SetZero()
+			continue
+		}
+
+		if hasOption(f.Tag.Get("sencillo"), "redact") {
+			if !(omitEmpty(f) && fv.IsZero()) {
+				mask(fv)
+			}
+			continue
+		}
+
+		c, err := r.copy(src)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		fv.Set(c)
+	}
+
+	return out, nil
+}
+
+// mask sets strings and string pointers to RedactedValue and anything else to its zero value
+func mask(v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(RedactedValue)
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.String:
+		p := reflect.New(v.Type().Elem())
+		p.Elem().SetString(RedactedValue)
+		v.Set(p)
+	default:
+		v.SetZero()
+	}
+}
+
+// visible reports whether the field should be included for the roles
+func (r *redactor) visible(f reflect.StructField) bool {
+	if hasOption(f.Tag.Get("sencillo"), "omit") {
+		return false
+	}
+
+	view := f.Tag.Get("view")
+	if view == "" {
+		return true
+	}
+
+	for _, role := range strings.Split(view, ",") {
+		if r.roles[strings.TrimSpace(role)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasTags reports whether the type, or a type it contains, has fields with redaction tags
+func hasTags(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return hasTags(t.Elem(), visiting)
+	case reflect.Map:
+		return hasTags(t.Key(), visiting) || hasTags(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			sencillo := f.Tag.Get("sencillo")
+			if _, ok := f.Tag.Lookup("view"); ok || hasOption(sencillo, "redact") || hasOption(sencillo, "omit") {
+				return true
+			}
+			if hasTags(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func omitEmpty(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return hasOption(opts, "omitempty")
+}
+
+func hasOption(tag, option string) bool {
+	for _, v := range strings.Split(tag, ",") {
+		if strings.TrimSpace(v) == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City   string `json:"city" xml:"city"`
+	Street string `json:"street,omitempty" xml:"street,omitempty" view:"admin"`
+}
+
+type customer struct {
+	ID       string    `json:"id"`
+	Email    string    `json:"email,omitempty" view:"admin,support"`
+	SSN      string    `json:"ssn" sencillo:"redact"`
+	Password string    `json:"-"`
+	Internal string    `json:"internal,omitempty" sencillo:"omit"`
+	Notes    string    `json:"notes,omitempty"`
+	Address  address   `json:"address"`
+	Previous []address `json:"previous,omitempty"`
+}
+
+func TestRedactorMarshal(t *testing.T) {
+	c := customer{
+		ID:       "1",
+		Email:    "a@example.com",
+		SSN:      "123-45-6789",
+		Password: "secret",
+		Internal: "x",
+		Address:  address{City: "Austin", Street: "Main"},
+	}
+
+	tt := []struct {
+		name  string
+		roles []string
+		want  string
+	}{
+		{name: "anonymous", want: `{"address":{"city":"Austin"},"id":"1","ssn":"[REDACTED]"}`},
+		{name: "support", roles: []string{"support"}, want: `{"address":{"city":"Austin"},"email":"a@example.com","id":"1","ssn":"[REDACTED]"}`},
+		{name: "admin", roles: []string{"admin"}, want: `{"address":{"city":"Austin","street":"Main"},"email":"a@example.com","id":"1","ssn":"[REDACTED]"}`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			b, err := NewRedactor(nil).Marshal(WithRoles(context.Background(), v.roles...), c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want map[string]any
+			json.Unmarshal(b, &got)
+			json.Unmarshal([]byte(v.want), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %s but got %s", v.want, b)
+			}
+		})
+	}
+}
+
+func TestRedactKeepsType(t *testing.T) {
+	c := &customer{ID: "1", Email: "a@example.com", SSN: "123-45-6789", Address: address{City: "Austin", Street: "Main"}}
+
+	got, err := Redact(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &customer{ID: "1", SSN: RedactedValue, Address: address{City: "Austin"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v but got %+v", want, got)
+	}
+	if c.SSN != "123-45-6789" || c.Address.Street != "Main" {
+		t.Errorf("expected the original to be unchanged but got %+v", c)
+	}
+}
+
+func TestRedactorXML(t *testing.T) {
+	b, err := NewRedactor(XML{}).Marshal(context.Background(), address{City: "Austin", Street: "Main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<address><city>Austin</city></address>`
+	if !strings.HasSuffix(string(b), want) {
+		t.Errorf("expected %s but got %s", want, b)
+	}
+}
+
+type node struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret" sencillo:"redact"`
+	Next   *node  `json:"next"`
+}
+
+func TestRedactCycle(t *testing.T) {
+	n := &node{Name: "a", Secret: "x"}
+	n.Next = n
+
+	got, err := Redact(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Next != got || got.Secret != RedactedValue || n.Secret != "x" {
+		t.Errorf("expected a redacted copy of the cycle but got %+v", got)
+	}
+}
+
+type credentials struct {
+	APIKey string `json:"api_key" sencillo:"redact"`
+	Notes  string `json:"notes,omitempty" view:"admin"`
+}
+
+type integration struct {
+	Name string `json:"name"`
+	credentials
+	*settings
+}
+
+type settings struct {
+	Token string `json:"token" sencillo:"redact"`
+}
+
+func TestRedactUnexportedEmbedded(t *testing.T) {
+	in := integration{Name: "crm", credentials: credentials{APIKey: "k", Notes: "rotated"}, settings: &settings{Token: "t"}}
+
+	got, err := Redact(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"crm","api_key":"[REDACTED]","token":"[REDACTED]"}`; string(b) != want {
+		t.Errorf("expected %s but got %s", want, b)
+	}
+	if in.APIKey != "k" || in.Token != "t" {
+		t.Error("expected the original to be left alone")
+	}
+}
+
+type customJSON struct {
+	Secret string `json:"secret" sencillo:"redact"`
+}
+
+func (c customJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"secret": c.Secret})
+}
+
+func TestRedactCustomMarshaler(t *testing.T) {
+	if _, err := Redact(customJSON{Secret: "x"}); !errors.Is(err, ErrCustomMarshaler) {
+		t.Errorf("expected %v but got %v", ErrCustomMarshaler, err)
+	}
+
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := Redact(struct{ At time.Time }{At: ts})
+	if err != nil || !got.At.Equal(ts) {
+		t.Errorf("expected the time to be copied but got %v %v", got, err)
+	}
+}
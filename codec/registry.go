@@ -0,0 +1,232 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrNotAcceptable   = fmt.Errorf("no codec matches the accepted content types")
+	ErrNotProtoMessage = fmt.Errorf("value is not a proto.Message")
+)
+
+// DefaultRegistry is used by the package level Register, Lookup, and Negotiate functions
+var DefaultRegistry = NewRegistry(JSON{}, XML{}, MsgPack{}, Protobuf{})
+
+// Protobuf encodes values that implement proto.Message in the protobuf wire format
+type Protobuf struct{}
+
+func (Protobuf) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (Protobuf) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return proto.Marshal(m)
+}
+
+func (Protobuf) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	return proto.Unmarshal(data, m)
+}
+
+// Registry holds codecs by content type. The first codec registered is used when the client accepts anything.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs []Codec
+}
+
+// NewRegistry returns a Registry with the given codecs
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+
+	return r
+}
+
+// Register adds a codec, replacing any codec with the same content type
+func (r *Registry) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, v := range r.codecs {
+		if v.ContentType() == c.ContentType() {
+			r.codecs[i] = c
+			return
+		}
+	}
+
+	r.codecs = append(r.codecs, c)
+}
+
+// Lookup returns the codec for a content type. Parameters such as charset are ignored.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.codecs {
+		if v.ContentType() == mediaType {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// Negotiate returns the codec that best matches an Accept header value, honoring quality values and
+// wildcards. An empty header accepts the first registered codec.
+func (r *Registry) Negotiate(accept string) (Codec, error) {
+	codecs := r.Acceptable(accept)
+	if len(codecs) == 0 {
+		return nil, ErrNotAcceptable
+	}
+
+	return codecs[0], nil
+}
+
+// Acceptable returns every codec matching an Accept header value, best match first. Each codec takes the quality
+// of the most specific media range matching it, so "*/*, application/xml;q=0" accepts everything but XML. An
+// empty header accepts every codec in the order they were registered.
+func (r *Registry) Acceptable(accept string) []Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if strings.TrimSpace(accept) == "" {
+		return append([]Codec{}, r.codecs...)
+	}
+
+	type match struct {
+		codec Codec
+		q     float64
+		index int
+	}
+
+	ranges := parseAccept(accept)
+	var matches []match
+	for _, v := range r.codecs {
+		best := -1
+		for i, mr := range ranges {
+			if matchMediaType(mr.mediaType, v.ContentType()) && (best < 0 || specificity(mr.mediaType) > specificity(ranges[best].mediaType)) {
+				best = i
+			}
+		}
+		if best >= 0 && ranges[best].q > 0 {
+			matches = append(matches, match{codec: v, q: ranges[best].q, index: best})
+		}
+	}
+
+	// higher quality first, then the order of the header, then the order the codecs were registered
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].q != matches[j].q {
+			return matches[i].q > matches[j].q
+		}
+		return matches[i].index < matches[j].index
+	})
+
+	codecs := make([]Codec, len(matches))
+	for i, m := range matches {
+		codecs[i] = m.codec
+	}
+
+	return codecs
+}
+
+// Register adds a codec to the DefaultRegistry
+func Register(c Codec) {
+	DefaultRegistry.Register(c)
+}
+
+// Lookup returns a codec from the DefaultRegistry
+func Lookup(contentType string) (Codec, bool) {
+	return DefaultRegistry.Lookup(contentType)
+}
+
+// Negotiate picks a codec from the DefaultRegistry
+func Negotiate(accept string) (Codec, error) {
+	return DefaultRegistry.Negotiate(accept)
+}
+
+// mediaRange is one media range of an Accept header and its quality
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept header, including those with a quality of zero, which
+// exclude the types they match
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// specificity ranks a media range so a concrete type beats type/* and type/* beats */*
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func matchMediaType(accepted, contentType string) bool {
+	if accepted == "*/*" || accepted == contentType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(accepted, "/*")
+	return ok && strings.HasPrefix(contentType, prefix+"/")
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"errors"
+	"testing"
+)
+
+type text struct{}
+
+func (text) ContentType() string                { return "text/plain" }
+func (text) Marshal(v any) ([]byte, error)      { return nil, nil }
+func (text) Unmarshal(data []byte, v any) error { return nil }
+
+func TestNegotiate(t *testing.T) {
+	r := NewRegistry(JSON{}, XML{}, Protobuf{}, text{})
+
+	tt := []struct {
+		name   string
+		accept string
+		want   string
+		err    error
+	}{
+		{name: "empty", accept: "", want: "application/json"},
+		{name: "exact", accept: "application/x-protobuf", want: "application/x-protobuf"},
+		{name: "quality", accept: "application/json;q=0.5, text/plain", want: "text/plain"},
+		{name: "wildcard", accept: "*/*", want: "application/json"},
+		{name: "xml", accept: "application/xml, application/json;q=0.9", want: "application/xml"},
+		{name: "subtype wildcard", accept: "text/*", want: "text/plain"},
+		{name: "excluded", accept: "application/json;q=0, text/html", err: ErrNotAcceptable},
+		{name: "no match", accept: "application/msgpack", err: ErrNotAcceptable},
+		{name: "wildcard exclusion", accept: "*/*, application/json;q=0", want: "application/xml"},
+		{name: "subtype exclusion", accept: "application/*;q=0, */*", want: "text/plain"},
+		{name: "specific beats exclusion", accept: "application/*;q=0, application/xml", want: "application/xml"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			c, err := r.Negotiate(v.accept)
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+			if err == nil && c.ContentType() != v.want {
+				t.Errorf("expected %s but got %s", v.want, c.ContentType())
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c, ok := NewRegistry(JSON{}).Lookup("application/json; charset=utf-8")
+	if !ok || c.ContentType() != "application/json" {
+		t.Errorf("expected the JSON codec but got %v", c)
+	}
+}
+
+func TestXML(t *testing.T) {
+	type user struct {
+		Name string `xml:"name"`
+	}
+
+	data, err := XML{}.Marshal(user{Name: "sencillo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got user
+	if err := (XML{}).Unmarshal(data, &got); err != nil || got.Name != "sencillo" {
+		t.Errorf("expected sencillo but got %q, %v", got.Name, err)
+	}
+}
+
+func TestMsgPack(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	data, err := MsgPack{}.Marshal(user{Name: "sencillo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := (MsgPack{}).Unmarshal(data, &got); err != nil || got["name"] != "sencillo" {
+		t.Errorf("expected the json field name but got %v, %v", got, err)
+	}
+}
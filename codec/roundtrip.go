@@ -0,0 +1,60 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// ErrRoundTrip is returned by RoundTrip when a decoded value doesn't survive being encoded and decoded again
+var ErrRoundTrip = fmt.Errorf("value changed in round trip")
+
+// RoundTrip decodes data into v, a pointer, and checks that encoding the result and decoding it again into a
+// new value produces the same encoding. Input the codec rejects returns the decode error, which fuzz targets
+// usually skip, while every other failure wraps ErrRoundTrip.
+//
+//	f.Fuzz(func(t *testing.T, data []byte) {
+//		if err := codec.RoundTrip(codec.JSON{}, data, &Order{}); errors.Is(err, codec.ErrRoundTrip) {
+//			t.Fatal(err)
+//		}
+//	})
+func RoundTrip(c Codec, data []byte, v any) error {
+	if err := c.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	first, err := c.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: encoding decoded value: %v", ErrRoundTrip, err)
+	}
+
+	again := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := c.Unmarshal(first, again); err != nil {
+		return fmt.Errorf("%w: decoding %q: %v", ErrRoundTrip, first, err)
+	}
+
+	second, err := c.Marshal(again)
+	if err != nil {
+		return fmt.Errorf("%w: encoding %q again: %v", ErrRoundTrip, first, err)
+	}
+
+	if !bytes.Equal(first, second) {
+		return fmt.Errorf("%w: %q encoded as %q", ErrRoundTrip, first, second)
+	}
+
+	return nil
+}
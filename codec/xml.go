@@ -0,0 +1,40 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"encoding/xml"
+)
+
+// XML encodes values with encoding/xml for clients that require application/xml. Values must be types
+// encoding/xml supports, so maps cannot be encoded.
+type XML struct{}
+
+func (XML) ContentType() string {
+	return "application/xml"
+}
+
+func (XML) Marshal(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (XML) Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
@@ -0,0 +1,272 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// failureDecay is the weight of each request in the moving failure rate compared with the error budget
+const failureDecay = 0.05
+
+// latencyDecay is the weight of each successful request in the smoothed latency compared with the threshold
+const latencyDecay = 0.1
+
+// warmup is how many requests the smoothed latency takes in before it can set the baseline
+const warmup = 10
+
+// AdaptiveOpt is a functional option to modify the AdaptiveLimiter
+type AdaptiveOpt func(*AdaptiveLimiter)
+
+// AdaptiveLimiter finds how many requests a service can run at once instead of using a fixed limit. It raises
+// the limit by one for every limit's worth of requests that finish in time (additive increase) and cuts it by the
+// backoff factor when latency rises or requests fail past the error budget (multiplicative decrease). Latency is
+// smoothed with a moving average, so one slow request doesn't cut the limit. It has risen when the average is
+// over the target latency, or without a target, over the tolerance times the no-load baseline, which is the
+// lowest average seen recently. Requests over the limit are shed right away rather than queued, since queueing
+// would hide the latency the limit reacts to.
+type AdaptiveLimiter struct {
+	minLimit  int
+	maxLimit  int
+	backoff   float64
+	tolerance float64
+	target    time.Duration
+	window    time.Duration
+	budget    float64
+	now       func() time.Time
+
+	mu            sync.Mutex
+	limit         float64
+	inFlight      int
+	smoothed      time.Duration
+	samples       int
+	lowest        time.Duration
+	prevLowest    time.Duration
+	windowStart   time.Time
+	failureRate   float64
+	lastDecrease  time.Time
+	sinceDecrease int
+
+	limitGauge    prometheus.Gauge
+	inFlightGauge prometheus.Gauge
+	admitted      prometheus.Counter
+	shed          prometheus.Counter
+	decreases     prometheus.Counter
+}
+
+// SetInitialLimit sets the limit before any requests have been seen. The default is 20.
+func SetInitialLimit(n int) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		l.limit = float64(n)
+	}
+}
+
+// SetLimitBounds sets the lowest and highest the limit can go. The defaults are 1 and 1000.
+func SetLimitBounds(lowest, highest int) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		l.minLimit, l.maxLimit = max(lowest, 1), max(highest, lowest, 1)
+	}
+}
+
+// SetBackoff sets the factor the limit is multiplied by when the service is overloaded. The default is 0.9.
+func SetBackoff(f float64) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		if f > 0 && f < 1 {
+			l.backoff = f
+		}
+	}
+}
+
+// SetTargetLatency sets the average latency over which the service is overloaded. Without it, the threshold
+// follows the no-load baseline times the tolerance.
+func SetTargetLatency(d time.Duration) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		l.target = d
+	}
+}
+
+// SetLatencyTolerance sets how many times the no-load baseline the average latency can reach before the
+// service is overloaded. The default is 2.
+func SetLatencyTolerance(f float64) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		if f > 1 {
+			l.tolerance = f
+		}
+	}
+}
+
+// SetLatencyWindow sets how long the no-load baseline is remembered, so it follows the service when its normal
+// latency changes. The default is 1 minute.
+func SetLatencyWindow(d time.Duration) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		l.window = d
+	}
+}
+
+// SetErrorBudget sets the share of requests that can fail without shrinking the limit, such as 0.01 for 1%.
+// Failures past the budget are treated as overload. The default is 0, so every failure shrinks the limit.
+func SetErrorBudget(f float64) AdaptiveOpt {
+	return func(l *AdaptiveLimiter) {
+		l.budget = f
+	}
+}
+
+// NewAdaptiveLimiter returns an AdaptiveLimiter. Its metric names start with name, such as orders_adaptive_limit,
+// so every limiter can register its own.
+func NewAdaptiveLimiter(name string, opts ...AdaptiveOpt) *AdaptiveLimiter {
+	l := &AdaptiveLimiter{
+		minLimit:      1,
+		maxLimit:      1000,
+		backoff:       0.9,
+		tolerance:     2,
+		window:        time.Minute,
+		limit:         20,
+		now:           time.Now,
+		limitGauge:    metrics.NewGaugeVec(name+"_adaptive_limit", "Current limit of the adaptive concurrency limiter", nil).WithLabelValues(),
+		inFlightGauge: metrics.NewGaugeVec(name+"_adaptive_limiter_in_flight", "Requests running under the adaptive concurrency limiter", nil).WithLabelValues(),
+		admitted:      metrics.NewCounterVec(name+"_adaptive_limiter_admitted", "Requests admitted by the adaptive concurrency limiter", nil).WithLabelValues(),
+		shed:          metrics.NewCounterVec(name+"_adaptive_limiter_shed", "Requests shed by the adaptive concurrency limiter", nil).WithLabelValues(),
+		decreases:     metrics.NewCounterVec(name+"_adaptive_limiter_decreases", "Times the adaptive limit was cut for overload", nil).WithLabelValues(),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.limit = min(max(l.limit, float64(l.minLimit)), float64(l.maxLimit))
+	l.windowStart = l.now()
+	l.limitGauge.Set(float64(int(l.limit)))
+
+	return l
+}
+
+// Metrics returns the limiter's collectors to register with the exporter
+func (l *AdaptiveLimiter) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{l.limitGauge, l.inFlightGauge, l.admitted, l.shed, l.decreases}
+}
+
+// Limit returns the current limit
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Acquire admits a request if it is under the limit and returns the func to call when it finishes, with ok
+// false if it failed in a way that signals overload, such as a timeout or a 5xx. It returns ErrShed over the
+// limit.
+func (l *AdaptiveLimiter) Acquire() (func(ok bool), error) {
+	l.mu.Lock()
+	if l.inFlight >= int(l.limit) {
+		l.mu.Unlock()
+		l.shed.Inc()
+		return nil, ErrShed
+	}
+	l.inFlight++
+	start := l.now()
+	l.mu.Unlock()
+
+	l.admitted.Inc()
+	l.inFlightGauge.Inc()
+
+	var once sync.Once
+	return func(ok bool) {
+		once.Do(func() {
+			l.inFlightGauge.Dec()
+			l.release(start, ok)
+		})
+	}, nil
+}
+
+// release adjusts the limit from a finished request
+func (l *AdaptiveLimiter) release(start time.Time, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	end := l.now()
+	latency := end.Sub(start)
+	l.inFlight--
+	l.sinceDecrease++
+
+	failed := 0.0
+	if !ok {
+		failed = 1
+	}
+	l.failureRate = l.failureRate*(1-failureDecay) + failed*failureDecay
+	if ok {
+		l.observe(end, latency)
+	}
+
+	threshold := l.target
+	if threshold == 0 {
+		if baseline := l.baseline(); baseline > 0 {
+			threshold = time.Duration(float64(baseline) * l.tolerance)
+		}
+	}
+
+	overloaded := (!ok && l.failureRate > l.budget) || (threshold > 0 && l.smoothed > threshold)
+	switch {
+	case overloaded:
+		// requests admitted before the last cut ran under the old limit, and the average needs a limit's worth
+		// of requests to catch up with it, so neither can cut it again
+		if start.Before(l.lastDecrease) || (ok && l.sinceDecrease < int(l.limit)) {
+			return
+		}
+		l.limit = max(l.limit*l.backoff, float64(l.minLimit))
+		l.lastDecrease = end
+		l.sinceDecrease = 0
+		l.decreases.Inc()
+	case l.inFlight+1 >= int(l.limit)/2:
+		// only grow while the limit is being used, so an idle service doesn't drift to the maximum
+		l.limit = min(l.limit+1/l.limit, float64(l.maxLimit))
+	}
+	l.limitGauge.Set(float64(int(l.limit)))
+}
+
+// observe adds a successful request's latency to the moving average and records the lowest average in the
+// current window
+func (l *AdaptiveLimiter) observe(now time.Time, latency time.Duration) {
+	l.samples++
+	if l.smoothed == 0 {
+		l.smoothed = latency
+	} else {
+		l.smoothed = time.Duration(float64(l.smoothed)*(1-latencyDecay) + float64(latency)*latencyDecay)
+	}
+	if l.samples < warmup {
+		return
+	}
+
+	if now.Sub(l.windowStart) >= l.window {
+		l.prevLowest, l.lowest, l.windowStart = l.lowest, 0, now
+	}
+	if l.lowest == 0 || l.smoothed < l.lowest {
+		l.lowest = l.smoothed
+	}
+}
+
+// baseline returns the lowest average latency across the current and previous window
+func (l *AdaptiveLimiter) baseline() time.Duration {
+	switch {
+	case l.prevLowest == 0:
+		return l.lowest
+	case l.lowest == 0:
+		return l.prevLowest
+	}
+	return min(l.lowest, l.prevLowest)
+}
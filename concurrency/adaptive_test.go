@@ -0,0 +1,183 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time          { return f.now }
+func (f *fakeClock) Advance(d time.Duration) { f.now = f.now.Add(d) }
+
+func TestAdaptiveLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := NewAdaptiveLimiter("test", SetInitialLimit(4), SetLimitBounds(2, 5), SetBackoff(0.5))
+	l.now = clock.Now
+	other := NewAdaptiveLimiter("other")
+	reg := sdtest.NewRegistry(t, append(l.Metrics(), other.Metrics()...)...)
+
+	// run starts n requests at once, each taking latency, and finishes them in order
+	run := func(n int, latency time.Duration, ok bool) {
+		releases := make([]func(bool), 0, n)
+		for range n {
+			release, err := l.Acquire()
+			if err != nil {
+				t.Fatal(err)
+			}
+			releases = append(releases, release)
+		}
+		clock.Advance(latency)
+		for _, release := range releases {
+			release(ok)
+		}
+	}
+
+	tt := []struct {
+		name    string
+		n       int
+		rounds  int
+		latency time.Duration
+		ok      bool
+		limit   int
+	}{
+		{name: "baseline", n: 4, latency: 10 * time.Millisecond, ok: true, limit: 4},
+		{name: "grows", n: 4, latency: 12 * time.Millisecond, ok: true, limit: 5},
+		{name: "at the maximum", n: 5, latency: 15 * time.Millisecond, ok: true, limit: 5},
+		{name: "slow cuts once", n: 5, latency: 50 * time.Millisecond, ok: true, limit: 2},
+		{name: "slow again stops at the minimum", n: 2, latency: 50 * time.Millisecond, ok: true, limit: 2},
+		{name: "recovering", n: 2, rounds: 2, latency: 10 * time.Millisecond, ok: true, limit: 2},
+		{name: "recovered", n: 2, rounds: 2, latency: 10 * time.Millisecond, ok: true, limit: 3},
+		{name: "failure cuts", n: 1, latency: 10 * time.Millisecond, limit: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for range max(v.rounds, 1) {
+				run(v.n, v.latency, v.ok)
+			}
+			if got := l.Limit(); got != v.limit {
+				t.Errorf("expected limit %d but got %d", v.limit, got)
+			}
+			reg.AssertGauge(t, "test_adaptive_limit", nil, float64(v.limit))
+		})
+	}
+
+	release, _ := l.Acquire()
+	l.Acquire()
+	if _, err := l.Acquire(); !errors.Is(err, ErrShed) {
+		t.Errorf("expected the request over the limit to be shed but got %v", err)
+	}
+	release(true)
+	reg.AssertCounter(t, "test_adaptive_limiter_shed", nil, 1)
+	reg.AssertGauge(t, "test_adaptive_limiter_in_flight", nil, 1)
+}
+
+func TestAdaptiveLimiterNoisyLatency(t *testing.T) {
+	tt := []struct {
+		name       string
+		latency    func(i int) time.Duration
+		overloaded bool
+	}{
+		{
+			name:    "healthy with jitter",
+			latency: func(i int) time.Duration { return time.Duration(1+(i*7)%5) * time.Millisecond },
+		},
+		{
+			name: "one slow request",
+			latency: func(i int) time.Duration {
+				return map[bool]time.Duration{true: 200 * time.Millisecond}[i == 500] + 2*time.Millisecond
+			},
+		},
+		{
+			name: "overloaded",
+			latency: func(i int) time.Duration {
+				if i < 500 {
+					return time.Duration(1+(i*7)%5) * time.Millisecond
+				}
+				return time.Duration(20+(i*7)%5) * time.Millisecond
+			},
+			overloaded: true,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			l := NewAdaptiveLimiter("test")
+			l.now = clock.Now
+
+			// 10 callers each send a request at a time, finishing in order of latency
+			for i := 0; i < 2000; i += 10 {
+				type call struct {
+					release func(bool)
+					latency time.Duration
+				}
+				calls := make([]call, 0, 10)
+				for j := range 10 {
+					release, err := l.Acquire()
+					if err != nil {
+						continue
+					}
+					calls = append(calls, call{release, v.latency(i + j)})
+				}
+				sort.Slice(calls, func(a, b int) bool { return calls[a].latency < calls[b].latency })
+				var elapsed time.Duration
+				for _, c := range calls {
+					clock.Advance(c.latency - elapsed)
+					elapsed = c.latency
+					c.release(true)
+				}
+			}
+
+			if got := l.Limit(); v.overloaded != (got < 10) {
+				t.Errorf("expected overloaded to be %v but the limit is %d", v.overloaded, got)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiterErrorBudget(t *testing.T) {
+	tt := []struct {
+		name   string
+		budget float64
+		limit  int
+	}{
+		{name: "no budget", limit: 9},
+		{name: "within budget", budget: 0.1, limit: 10},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			l := NewAdaptiveLimiter("test", SetInitialLimit(10), SetBackoff(0.9), SetErrorBudget(v.budget))
+			release, err := l.Acquire()
+			if err != nil {
+				t.Fatal(err)
+			}
+			release(false)
+			if got := l.Limit(); got != v.limit {
+				t.Errorf("expected limit %d but got %d", v.limit, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	ErrPanic      = fmt.Errorf("worker panicked")
+	ErrPoolClosed = fmt.Errorf("pool is closed")
+)
+
+// PanicError is returned when a worker panics. The panic value and stack are kept for logging.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic, p.Value)
+}
+
+func (p *PanicError) Unwrap() error {
+	return ErrPanic
+}
+
+// Group runs functions in goroutines with an optional limit on how many run at once. The first error
+// cancels the group's context and is returned from Wait.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// NewGroup returns a Group and a context that is canceled when a function fails or Wait returns.
+// A limit of zero or less means unbounded.
+func NewGroup(ctx context.Context, limit int) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+
+	return g, ctx
+}
+
+// Go runs f in a new goroutine once a slot is available. It blocks while the group is at its limit and
+// skips f if the group's context is already done.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.fail(context.Cause(g.ctx))
+			return
+		}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		if err := safeCall(g.ctx, f); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait blocks until every function returns and then returns the first error
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
+
+func (g *Group) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
+
+func safeCall(ctx context.Context, f func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return f(ctx)
+}
+
+// Map calls f for every item with at most limit calls in flight and returns the results in input order.
+// The first error cancels the remaining calls.
+func Map[T, R any](ctx context.Context, limit int, items []T, f func(context.Context, T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	g, ctx := NewGroup(ctx, limit)
+	for i, item := range items {
+		i, item := i, item
+		g.Go(func(ctx context.Context) error {
+			r, err := f(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = r
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Result holds the outcome of a single call made by Stream
+type Result[T, R any] struct {
+	Item  T
+	Value R
+	Err   error
+}
+
+// Stream calls f for every item with at most limit calls in flight and sends results on the returned
+// channel as they complete, in no particular order. Errors do not stop the other calls. The channel is
+// closed once every call returns or the context is done.
+func Stream[T, R any](ctx context.Context, limit int, items []T, f func(context.Context, T) (R, error)) <-chan Result[T, R] {
+	out := make(chan Result[T, R])
+	if limit <= 0 {
+		limit = len(items)
+	}
+
+	go func() {
+		defer close(out)
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, max(limit, 1))
+		for _, item := range items {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+
+			wg.Add(1)
+			go func(item T) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				var r R
+				err := safeCall(ctx, func(ctx context.Context) error {
+					var err error
+					r, err = f(ctx, item)
+					return err
+				})
+
+				select {
+				case out <- Result[T, R]{Item: item, Value: r, Err: err}:
+				case <-ctx.Done():
+				}
+			}(item)
+		}
+		wg.Wait()
+	}()
+
+	return out
+}
+
+// Pool is a fixed set of long running workers reading jobs from a bounded queue
+type Pool[T any] struct {
+	jobs    chan T
+	wg      sync.WaitGroup
+	handler func(context.Context, T) error
+	onError func(T, error)
+
+	// mu is held for reading while submitting so Close doesn't close jobs under a sender
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// PoolOpt is a functional option to modify the Pool
+type PoolOpt[T any] func(*Pool[T])
+
+// SetOnError sets a function called when a job returns an error or panics
+func SetOnError[T any](f func(T, error)) PoolOpt[T] {
+	return func(p *Pool[T]) {
+		p.onError = f
+	}
+}
+
+// NewPool starts workers goroutines that call handler for each submitted job. Workers stop when ctx is
+// done or Close is called.
+func NewPool[T any](ctx context.Context, workers, queue int, handler func(context.Context, T) error, opts ...PoolOpt[T]) *Pool[T] {
+	p := &Pool[T]{
+		jobs:    make(chan T, queue),
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for i := 0; i < max(workers, 1); i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+
+	return p
+}
+
+func (p *Pool[T]) work(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job, ok := <-p.jobs:
+			if !ok {
+				return
+			}
+			err := safeCall(ctx, func(ctx context.Context) error {
+				return p.handler(ctx, job)
+			})
+			if err != nil && p.onError != nil {
+				p.onError(job, err)
+			}
+		}
+	}
+}
+
+// Submit queues a job, blocking while the queue is full or until ctx is done. It returns ErrPoolClosed once
+// Close is called.
+func (p *Pool[T]) Submit(ctx context.Context, job T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return ErrPoolClosed
+	}
+}
+
+// Close stops accepting jobs and waits for queued jobs to finish. It is safe to call more than once.
+func (p *Pool[T]) Close() {
+	p.closeOnce.Do(func() {
+		// wake blocked submitters first so they release the read lock
+		close(p.done)
+		p.mu.Lock()
+		p.closed = true
+		close(p.jobs)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+}
+
+type flightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Flight runs one call per key at a time and shares its result with every caller asking for the key while it
+// runs. The zero value is ready to use.
+type Flight[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[V]
+}
+
+// Do calls f unless a call for key is already running, and returns its result and whether it was shared with
+// another caller. f gets ctx without its cancellation so a caller that gives up doesn't fail the others.
+func (f *Flight[V]) Do(ctx context.Context, key string, fn func(context.Context) (V, error)) (V, bool, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]*flightCall[V])
+	}
+	c, shared := f.calls[key]
+	if !shared {
+		c = &flightCall[V]{done: make(chan struct{})}
+		f.calls[key] = c
+		go func() {
+			defer func() {
+				f.mu.Lock()
+				delete(f.calls, key)
+				f.mu.Unlock()
+				close(c.done)
+			}()
+			c.err = safeCall(context.WithoutCancel(ctx), func(ctx context.Context) error {
+				var err error
+				c.value, err = fn(ctx)
+				return err
+			})
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		var zero V
+		return zero, shared, ctx.Err()
+	case <-c.done:
+		return c.value, shared, c.err
+	}
+}
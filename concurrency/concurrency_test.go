@@ -0,0 +1,203 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errBoom = fmt.Errorf("boom")
+
+func TestMap(t *testing.T) {
+	tt := []struct {
+		name  string
+		items []int
+		fail  int
+		want  []int
+		err   error
+	}{
+		{name: "ordered", items: []int{1, 2, 3, 4, 5}, fail: -1, want: []int{2, 4, 6, 8, 10}},
+		{name: "error", items: []int{1, 2, 3}, fail: 2, err: errBoom},
+		{name: "panic", items: []int{1, 2, 3}, fail: 0, err: ErrPanic},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var inFlight, peak int32
+			got, err := Map(context.Background(), 2, v.items, func(ctx context.Context, i int) (int, error) {
+				n := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+
+				switch {
+				case v.fail == 0 && i == 1:
+					panic("bad")
+				case i == v.fail:
+					return 0, errBoom
+				}
+				return i * 2, nil
+			})
+
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(v.want) && v.err == nil {
+				t.Errorf("expected %v but got %v", v.want, got)
+			}
+			if peak > 2 {
+				t.Errorf("expected at most 2 in flight but got %d", peak)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	var sum, errs int
+	for r := range Stream(context.Background(), 3, []int{1, 2, 3, 4}, func(ctx context.Context, i int) (int, error) {
+		if i == 3 {
+			return 0, errBoom
+		}
+		return i, nil
+	}) {
+		if r.Err != nil {
+			errs++
+			continue
+		}
+		sum += r.Value
+	}
+
+	if sum != 7 || errs != 1 {
+		t.Errorf("expected sum 7 with 1 error but got %d with %d", sum, errs)
+	}
+}
+
+func TestPool(t *testing.T) {
+	var done, failed int32
+	p := NewPool(context.Background(), 3, 10, func(ctx context.Context, i int) error {
+		if i%5 == 0 {
+			panic("bad job")
+		}
+		atomic.AddInt32(&done, 1)
+		return nil
+	}, SetOnError(func(int, error) { atomic.AddInt32(&failed, 1) }))
+
+	for i := 1; i <= 20; i++ {
+		if err := p.Submit(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Close()
+
+	if done != 16 || failed != 4 {
+		t.Errorf("expected 16 done and 4 failed but got %d and %d", done, failed)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	block := make(chan struct{})
+	p := NewPool(context.Background(), 1, 1, func(ctx context.Context, i int) error {
+		<-block
+		return nil
+	})
+
+	// one job runs and one fills the queue, so the third submit blocks until Close
+	p.Submit(context.Background(), 1)
+	p.Submit(context.Background(), 2)
+	blocked := make(chan error)
+	go func() {
+		blocked <- p.Submit(context.Background(), 3)
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	if err := <-blocked; !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected a blocked submit to get %v but got %v", ErrPoolClosed, err)
+	}
+
+	close(block)
+	<-closed
+	p.Close()
+	if err := p.Submit(context.Background(), 4); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected %v but got %v", ErrPoolClosed, err)
+	}
+}
+
+func TestFlight(t *testing.T) {
+	var f Flight[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	type result struct {
+		value  int
+		shared bool
+		err    error
+	}
+	results := make(chan result, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			v, shared, err := f.Do(context.Background(), "posts", fn)
+			results <- result{v, shared, err}
+		}()
+	}
+
+	// a caller that gives up doesn't cancel the call for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := f.Do(ctx, "posts", fn); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v but got %v", context.Canceled, err)
+	}
+
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	shared := 0
+	for i := 0; i < 5; i++ {
+		r := <-results
+		if r.value != 42 || r.err != nil {
+			t.Errorf("expected 42 but got %d: %v", r.value, r.err)
+		}
+		if r.shared {
+			shared++
+		}
+	}
+	if n := calls.Load(); n != 1 && shared != 5-int(n) {
+		t.Errorf("expected callers to share calls but got %d calls and %d shared results", n, shared)
+	}
+
+	_, _, err := f.Do(context.Background(), "panic", func(ctx context.Context) (int, error) { panic("boom") })
+	if !errors.Is(err, ErrPanic) {
+		t.Errorf("expected %v but got %v", ErrPanic, err)
+	}
+}
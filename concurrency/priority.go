@@ -0,0 +1,256 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PriorityHeader carries a request's priority over HTTP and NATS, as high, normal, or low
+const PriorityHeader = "X-Sencillo-Priority"
+
+var (
+	ErrShed            = fmt.Errorf("request shed under load")
+	ErrInvalidPriority = fmt.Errorf("invalid priority")
+)
+
+// Priority is the class a request is admitted in. Lower values are admitted first and shed last.
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+)
+
+// Priorities lists every class from highest to lowest
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// ParsePriority returns the priority with the given name, ignoring case. An empty name is PriorityNormal.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNormal, nil
+	}
+	for _, p := range Priorities {
+		if strings.EqualFold(p.String(), s) {
+			return p, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("%w: %q", ErrInvalidPriority, s)
+}
+
+type waiter struct {
+	priority Priority
+	ready    chan error
+}
+
+// LimiterOpt is a functional option to modify the Limiter
+type LimiterOpt func(*Limiter)
+
+// Limiter caps how many requests run at once. Requests over the limit wait in a queue per priority, and a freed
+// slot goes to the oldest request of the highest waiting priority. When the queue is full, the newest request of
+// the lowest priority is shed to make room for a higher one.
+type Limiter struct {
+	limit    int
+	maxQueue int
+	maxWait  time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	queues   [3][]*waiter
+	queued   int
+
+	admitted *prometheus.CounterVec
+	shed     *prometheus.CounterVec
+	wait     *prometheus.HistogramVec
+	waiting  *prometheus.GaugeVec
+}
+
+// SetMaxQueue sets how many requests can wait for a slot across all priorities. The default is the limit.
+func SetMaxQueue(n int) LimiterOpt {
+	return func(l *Limiter) {
+		l.maxQueue = n
+	}
+}
+
+// SetMaxWait sets how long a request waits for a slot before it is shed. The default is 1 second.
+func SetMaxWait(d time.Duration) LimiterOpt {
+	return func(l *Limiter) {
+		l.maxWait = d
+	}
+}
+
+// NewLimiter returns a Limiter running at most limit requests at once
+func NewLimiter(limit int, opts ...LimiterOpt) *Limiter {
+	l := &Limiter{
+		limit:    max(limit, 1),
+		maxQueue: max(limit, 1),
+		maxWait:  time.Second,
+		admitted: metrics.NewCounterVec("limiter_admitted", "Requests admitted by the concurrency limiter", []string{"priority"}),
+		shed:     metrics.NewCounterVec("limiter_shed", "Requests shed by the concurrency limiter", []string{"priority"}),
+		wait:     metrics.NewHistogramVec("limiter_wait_seconds", "Time admitted requests waited for a slot", []string{"priority"}),
+		waiting: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "limiter_queued",
+			Help: "Requests waiting for a slot",
+		}, []string{"priority"}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Metrics returns the limiter's collectors to register with the exporter
+func (l *Limiter) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{l.admitted, l.shed, l.wait, l.waiting}
+}
+
+// Acquire waits for a slot for a request of priority p and returns the func releasing it. It returns ErrShed
+// if the request is shed, or the context's error.
+func (l *Limiter) Acquire(ctx context.Context, p Priority) (func(), error) {
+	if p < PriorityHigh || p > PriorityLow {
+		p = PriorityNormal
+	}
+	start := time.Now()
+
+	l.mu.Lock()
+	if l.inFlight < l.limit && l.queued == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		l.admit(p, start)
+		return l.release, nil
+	}
+
+	if l.queued >= l.maxQueue && !l.shedLower(p) {
+		l.mu.Unlock()
+		l.shed.WithLabelValues(p.String()).Inc()
+		return nil, ErrShed
+	}
+
+	w := &waiter{priority: p, ready: make(chan error, 1)}
+	l.queues[p] = append(l.queues[p], w)
+	l.queued++
+	l.waiting.WithLabelValues(p.String()).Inc()
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err = <-w.ready:
+		if err == nil {
+			l.admit(p, start)
+			return l.release, nil
+		}
+		// shed for a higher priority request
+		l.shed.WithLabelValues(p.String()).Inc()
+		return nil, err
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C:
+		err = ErrShed
+	}
+
+	// a waiter granted a slot or shed as it gave up has its result waiting, and a granted slot is passed on
+	if !l.remove(w) && <-w.ready == nil {
+		l.release()
+	}
+	l.shed.WithLabelValues(p.String()).Inc()
+
+	return nil, err
+}
+
+func (l *Limiter) admit(p Priority, start time.Time) {
+	l.admitted.WithLabelValues(p.String()).Inc()
+	l.wait.WithLabelValues(p.String()).Observe(time.Since(start).Seconds())
+}
+
+// shedLower sheds the newest waiter of the lowest priority below p, reporting whether one was shed. l.mu must be
+// held.
+func (l *Limiter) shedLower(p Priority) bool {
+	for lower := PriorityLow; lower > p; lower-- {
+		q := l.queues[lower]
+		if len(q) == 0 {
+			continue
+		}
+		w := q[len(q)-1]
+		l.queues[lower] = q[:len(q)-1]
+		l.queued--
+		l.waiting.WithLabelValues(lower.String()).Dec()
+		w.ready <- ErrShed
+		return true
+	}
+
+	return false
+}
+
+// remove takes a waiter that gave up out of its queue, reporting whether it was still waiting
+func (l *Limiter) remove(w *waiter) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	q := l.queues[w.priority]
+	for i, v := range q {
+		if v == w {
+			l.queues[w.priority] = append(q[:i:i], q[i+1:]...)
+			l.queued--
+			l.waiting.WithLabelValues(w.priority.String()).Dec()
+			return true
+		}
+	}
+
+	return false
+}
+
+// release hands the slot to the oldest waiter of the highest priority, or frees it if nothing is waiting
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range Priorities {
+		q := l.queues[p]
+		if len(q) == 0 {
+			continue
+		}
+		w := q[0]
+		l.queues[p] = q[1:]
+		l.queued--
+		l.waiting.WithLabelValues(p.String()).Dec()
+		w.ready <- nil
+		return
+	}
+
+	l.inFlight--
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		queued := l.queued
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued requests", n)
+}
+
+func TestLimiterPriority(t *testing.T) {
+	l := NewLimiter(1, SetMaxQueue(2), SetMaxWait(time.Minute))
+	reg := sdtest.NewRegistry(t, l.Metrics()...)
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, PriorityNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		p   Priority
+		err error
+	}
+	results := make(chan result, 4)
+	acquire := func(p Priority) {
+		release, err := l.Acquire(ctx, p)
+		if err == nil {
+			release()
+		}
+		results <- result{p: p, err: err}
+	}
+
+	go acquire(PriorityLow)
+	waitQueued(t, l, 1)
+	go acquire(PriorityNormal)
+	waitQueued(t, l, 2)
+
+	// the queue is full, so the high request sheds the low one
+	go acquire(PriorityHigh)
+	if r := <-results; r.p != PriorityLow || !errors.Is(r.err, ErrShed) {
+		t.Fatalf("expected the low request to be shed but got %+v", r)
+	}
+	waitQueued(t, l, 2)
+
+	// and a low request is shed since nothing is lower
+	if _, err := l.Acquire(ctx, PriorityLow); !errors.Is(err, ErrShed) {
+		t.Errorf("expected %v but got %v", ErrShed, err)
+	}
+
+	release()
+	for _, want := range []Priority{PriorityHigh, PriorityNormal} {
+		if r := <-results; r.p != want || r.err != nil {
+			t.Errorf("expected %s to be admitted but got %+v", want, r)
+		}
+	}
+
+	reg.AssertCounter(t, "limiter_shed", prometheus.Labels{"priority": "low"}, 2)
+	reg.AssertCounter(t, "limiter_admitted", prometheus.Labels{"priority": "normal"}, 2)
+	reg.AssertCounter(t, "limiter_admitted", prometheus.Labels{"priority": "high"}, 1)
+	reg.AssertGauge(t, "limiter_queued", prometheus.Labels{"priority": "high"}, 0)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight != 0 {
+		t.Errorf("expected every slot to be released but %d are in flight", l.inFlight)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(1, SetMaxWait(10*time.Millisecond))
+	release, err := l.Acquire(context.Background(), PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	if _, err := l.Acquire(context.Background(), PriorityHigh); !errors.Is(err, ErrShed) {
+		t.Errorf("expected the wait to time out with %v but got %v", ErrShed, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Acquire(ctx, PriorityHigh); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v but got %v", context.Canceled, err)
+	}
+	if l.queued != 0 {
+		t.Errorf("expected the queue to be empty but %d are waiting", l.queued)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tt := []struct {
+		in   string
+		want Priority
+		err  error
+	}{
+		{in: "", want: PriorityNormal},
+		{in: "HIGH", want: PriorityHigh},
+		{in: "low", want: PriorityLow},
+		{in: "urgent", want: PriorityNormal, err: ErrInvalidPriority},
+	}
+
+	for _, v := range tt {
+		t.Run(v.in, func(t *testing.T) {
+			p, err := ParsePriority(v.in)
+			if p != v.want || !errors.Is(err, v.err) {
+				t.Errorf("expected %s, %v but got %s, %v", v.want, v.err, p, err)
+			}
+		})
+	}
+}
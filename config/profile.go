@@ -0,0 +1,275 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// ProfileEnv is the environment variable ProfileFromEnv reads
+const ProfileEnv = "SENCILLO_PROFILE"
+
+var (
+	ErrUnknownProfile = fmt.Errorf("unknown config profile")
+	ErrMissingBase    = fmt.Errorf("missing base config file")
+	ErrAmbiguousLayer = fmt.Errorf("more than one config file for a layer")
+)
+
+// configExts are the file types a layer can use, in the order they are looked for
+var configExts = []string{".cue", ".json", ".yaml", ".yml"}
+
+// ProfileFromEnv returns the profile named by SENCILLO_PROFILE
+func ProfileFromEnv() string {
+	return os.Getenv(ProfileEnv)
+}
+
+// Layer is a config file merged into a profile
+type Layer struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// Origin records which layer set a config value and which earlier layers it replaced
+type Origin struct {
+	Path      string   `json:"path"`
+	Layer     string   `json:"layer"`
+	File      string   `json:"file"`
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// Resolved is a profile's config after merging its layers
+type Resolved struct {
+	Profile string         `json:"profile"`
+	Layers  []Layer        `json:"layers"`
+	Values  map[string]any `json:"values"`
+	// Origins has an entry for every value, sorted by path. Arrays are a single value.
+	Origins []Origin `json:"origins"`
+}
+
+// Origin returns where the value at path, like nats.servers, came from
+func (r *Resolved) Origin(path string) (Origin, bool) {
+	i, ok := slices.BinarySearchFunc(r.Origins, path, func(o Origin, p string) int { return strings.Compare(o.Path, p) })
+	if !ok {
+		return Origin{}, false
+	}
+	return r.Origins[i], true
+}
+
+// ProfilesOpt is a functional option to modify Profiles
+type ProfilesOpt func(*Profiles)
+
+// Profiles layers config files in a directory. The base file, config.yaml, is overlaid by the selected
+// profile's file, such as config.prod.yaml, and then by config.local.yaml for overrides kept out of version
+// control. Objects are merged key by key. Any other value, arrays included, replaces the earlier one.
+type Profiles struct {
+	dir      string
+	name     string
+	profiles []string
+	local    string
+}
+
+// NewProfiles returns Profiles reading files from dir with the dev, staging, and prod profiles
+func NewProfiles(dir string, opts ...ProfilesOpt) *Profiles {
+	p := &Profiles{
+		dir:      dir,
+		name:     "config",
+		profiles: []string{"dev", "staging", "prod"},
+		local:    "local",
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// SetBaseName sets the name of the files without extension. The default is config.
+func SetBaseName(name string) ProfilesOpt {
+	return func(p *Profiles) {
+		p.name = name
+	}
+}
+
+// SetProfileNames sets the profiles that can be selected
+func SetProfileNames(names ...string) ProfilesOpt {
+	return func(p *Profiles) {
+		p.profiles = names
+	}
+}
+
+// SetLocalOverrides sets the layer applied after the profile, local by default. An empty name turns local
+// overrides off, which production deployments should do.
+func SetLocalOverrides(name string) ProfilesOpt {
+	return func(p *Profiles) {
+		p.local = name
+	}
+}
+
+// find returns the file for a layer, or an empty string if there is none
+func (p *Profiles) find(layer string) (string, error) {
+	base := p.name
+	if layer != "" {
+		base += "." + layer
+	}
+
+	var found []string
+	for _, ext := range configExts {
+		f := filepath.Join(p.dir, base+ext)
+		_, err := os.Stat(f)
+		switch {
+		case err == nil:
+			found = append(found, f)
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", err
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrAmbiguousLayer, strings.Join(found, ", "))
+}
+
+// Resolve merges the base file, the profile's file, and the local overrides. The profile must be one of the
+// profile names. Only the base file is required.
+func (p *Profiles) Resolve(profile string) (*Resolved, error) {
+	if !slices.Contains(p.profiles, profile) {
+		return nil, fmt.Errorf("%w: %q, choose one of %s with %s", ErrUnknownProfile, profile, strings.Join(p.profiles, ", "), ProfileEnv)
+	}
+
+	layers := []Layer{{Name: "base"}, {Name: profile}}
+	if p.local != "" {
+		layers = append(layers, Layer{Name: p.local})
+	}
+
+	r := &Resolved{Profile: profile, Values: map[string]any{}}
+	origins := map[string]Origin{}
+	for i, l := range layers {
+		name := l.Name
+		if i == 0 {
+			name = ""
+		}
+		f, err := p.find(name)
+		if err != nil {
+			return nil, err
+		}
+		if f == "" {
+			if i == 0 {
+				return nil, fmt.Errorf("%w: no %s file in %s", ErrMissingBase, p.name, p.dir)
+			}
+			continue
+		}
+
+		v, err := readValue(f)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected an object but got %s", f, typeName(v))
+		}
+
+		l.File = f
+		r.Layers = append(r.Layers, l)
+		merge(r.Values, m, "", l, origins)
+	}
+
+	for _, o := range origins {
+		r.Origins = append(r.Origins, o)
+	}
+	slices.SortFunc(r.Origins, func(a, b Origin) int { return strings.Compare(a.Path, b.Path) })
+
+	return r, nil
+}
+
+// merge overlays src on dst, recording the origin of every value src sets
+func merge(dst, src map[string]any, path string, l Layer, origins map[string]Origin) {
+	for k, v := range src {
+		p := join(path, k)
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			merge(dstMap, srcMap, p, l, origins)
+			continue
+		}
+
+		// the value replaces whatever was under its path
+		for op := range origins {
+			if strings.HasPrefix(op, p+".") {
+				delete(origins, op)
+			}
+		}
+		var overrides []string
+		if prev, ok := origins[p]; ok {
+			overrides = append(prev.Overrides, prev.Layer)
+			delete(origins, p)
+		}
+
+		if srcIsMap {
+			m := map[string]any{}
+			dst[k] = m
+			merge(m, srcMap, p, l, origins)
+			continue
+		}
+		dst[k] = v
+		origins[p] = Origin{Path: p, Layer: l.Name, File: l.File, Overrides: overrides}
+	}
+}
+
+// LoadProfile resolves the profile, validates the merged config against the schema for T, and unmarshals it
+// with the cue schema. Validation errors name the file each invalid value came from. The Resolved config is
+// returned so admin.ConfigRoutes can show where each value came from.
+func LoadProfile[T any](config T, schema string, p *Profiles, profile string) (T, *Resolved, error) {
+	r, err := p.Resolve(profile)
+	if err != nil {
+		return config, nil, err
+	}
+
+	err = validateValue(Schema[T](), fmt.Sprintf("%s profile", profile), r.Values)
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		for i, f := range ve.Fields {
+			if o, ok := r.Origin(f.Path); ok {
+				ve.Fields[i].Message = fmt.Sprintf("%s (set in %s)", f.Message, o.File)
+			}
+		}
+	}
+	if err != nil {
+		return config, r, err
+	}
+
+	b, err := json.Marshal(r.Values)
+	if err != nil {
+		return config, r, err
+	}
+	c := cueConfig[T]{ctx: cuecontext.New(), schema: schema, userConfig: config}
+	c.value = c.ctx.CompileBytes(b)
+	config, err = c.loadCueConfig()
+
+	return config, r, err
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":       "name: orders\nport: 8080\nnats:\n  servers: [nats://localhost:4222]\n  subject: orders\n",
+		"config.prod.json":  `{"port": 80, "nats": {"servers": ["nats://a:4222", "nats://b:4222"]}}`,
+		"config.local.yaml": "port: 9090\n",
+	})
+
+	tt := []struct {
+		name    string
+		opts    []ProfilesOpt
+		profile string
+		values  map[string]any
+		origins []Origin
+		err     error
+	}{
+		{
+			name:    "dev without overlay",
+			opts:    []ProfilesOpt{SetLocalOverrides("")},
+			profile: "dev",
+			values:  map[string]any{"name": "orders", "port": float64(8080), "nats": map[string]any{"servers": []any{"nats://localhost:4222"}, "subject": "orders"}},
+			origins: []Origin{
+				{Path: "name", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+				{Path: "nats.servers", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+				{Path: "nats.subject", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+				{Path: "port", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+			},
+		},
+		{
+			name:    "prod with local overrides",
+			profile: "prod",
+			values:  map[string]any{"name": "orders", "port": float64(9090), "nats": map[string]any{"servers": []any{"nats://a:4222", "nats://b:4222"}, "subject": "orders"}},
+			origins: []Origin{
+				{Path: "name", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+				{Path: "nats.servers", Layer: "prod", File: filepath.Join(dir, "config.prod.json"), Overrides: []string{"base"}},
+				{Path: "nats.subject", Layer: "base", File: filepath.Join(dir, "config.yaml")},
+				{Path: "port", Layer: "local", File: filepath.Join(dir, "config.local.yaml"), Overrides: []string{"base", "prod"}},
+			},
+		},
+		{name: "unknown profile", profile: "production", err: ErrUnknownProfile},
+		{name: "no profile", err: ErrUnknownProfile},
+		{name: "missing base", opts: []ProfilesOpt{SetBaseName("app")}, profile: "dev", err: ErrMissingBase},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			r, err := NewProfiles(dir, v.opts...).Resolve(v.profile)
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(r.Values, v.values) {
+				t.Errorf("expected values %v but got %v", v.values, r.Values)
+			}
+			if !reflect.DeepEqual(r.Origins, v.origins) {
+				t.Errorf("expected origins %+v but got %+v", v.origins, r.Origins)
+			}
+		})
+	}
+}
+
+func TestResolveReplacesObjects(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.json":     `{"cache": {"ttl": 60, "size": 100}}`,
+		"config.dev.json": `{"cache": "off"}`,
+	})
+
+	r, err := NewProfiles(dir).Resolve("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Origin{{Path: "cache", Layer: "dev", File: filepath.Join(dir, "config.dev.json")}}
+	if !reflect.DeepEqual(r.Origins, want) {
+		t.Errorf("expected %+v but got %+v", want, r.Origins)
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"config.json": `{}`, "config.yaml": "{}\n"})
+	if _, err := NewProfiles(dir).Resolve("dev"); !errors.Is(err, ErrAmbiguousLayer) {
+		t.Errorf("expected %v but got %v", ErrAmbiguousLayer, err)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":      "name: orders\nport: 8080\nnats:\n  servers: [nats://localhost:4222]\n",
+		"config.prod.yaml": "port: 70000\n",
+	})
+	p := NewProfiles(dir, SetLocalOverrides(""))
+
+	cfg, r, err := LoadProfile(serviceConfig{}, "", p, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 || r.Profile != "dev" {
+		t.Errorf("unexpected config %+v from %s", cfg, r.Profile)
+	}
+
+	_, _, err = LoadProfile(serviceConfig{}, "", p, "prod")
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected a validation error but got %v", err)
+	}
+	want := []FieldError{{Path: "port", Message: "must be at most 65535 (set in " + filepath.Join(dir, "config.prod.yaml") + ")"}}
+	if !reflect.DeepEqual(ve.Fields, want) {
+		t.Errorf("expected %v but got %v", want, ve.Fields)
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/load"
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidConfig = fmt.Errorf("invalid config")
+	ErrSchemaRef     = fmt.Errorf("unresolved schema reference")
+)
+
+// Schema returns the JSON Schema for the config struct T. Fields are named by their json tags and are required
+// unless tagged omitempty. Unknown fields are not allowed. Constraints such as enum, minimum, and pattern come
+// from jsonschema struct tags.
+func Schema[T any]() *jsonschema.Schema {
+	var config T
+	return (&jsonschema.Reflector{}).Reflect(config)
+}
+
+// ReadSchema reads a JSON Schema written by Schema
+func ReadSchema(filePath string) (*jsonschema.Schema, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return &s, nil
+}
+
+// FieldError is a config value that doesn't match the schema. Path locates it, like nats.servers[1].
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (f FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// ValidationError lists every value in a config file that doesn't match the schema
+type ValidationError struct {
+	File   string
+	Fields []FieldError
+}
+
+func (v *ValidationError) Error() string {
+	lines := make([]string, len(v.Fields))
+	for i, f := range v.Fields {
+		lines[i] = f.Error()
+	}
+	return fmt.Sprintf("%v %s:\n  %s", ErrInvalidConfig, v.File, strings.Join(lines, "\n  "))
+}
+
+func (v *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Validate checks the config file against the schema. The file can be cue, json, or yaml. It returns a
+// *ValidationError with the path of every invalid value.
+func Validate(schema *jsonschema.Schema, filePath string) error {
+	value, err := readValue(filePath)
+	if err != nil {
+		return err
+	}
+
+	return validateValue(schema, filePath, value)
+}
+
+// validateValue checks a decoded config against the schema. File names where the config came from.
+func validateValue(schema *jsonschema.Schema, file string, value any) error {
+	v := &validator{root: schema}
+	if err := v.validate(schema, "", value); err != nil {
+		return err
+	}
+	if len(v.errs) > 0 {
+		return &ValidationError{File: file, Fields: v.errs}
+	}
+	return nil
+}
+
+// Load validates the config file against the schema for T before unmarshalling it, so malformed config is
+// refused at startup with the path of each bad value
+func Load[T any](config T, schema, filePath string) (T, error) {
+	if err := Validate(Schema[T](), filePath); err != nil {
+		return config, err
+	}
+	return Unmarshal(config, schema, filePath)
+}
+
+// readValue decodes the config file into the plain values encoding/json produces
+func readValue(filePath string) (any, error) {
+	var data []byte
+	switch filepath.Ext(filePath) {
+	case ".cue":
+		bi := load.Instances([]string{filePath}, nil)
+		v := cuecontext.New().BuildInstance(bi[0])
+		b, err := v.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		data = b
+	case ".json":
+		b, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	case ".yaml", ".yml":
+		b, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		// round trip through JSON so YAML values have the same types as JSON ones
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+	default:
+		return nil, ErrFileFormat
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return v, nil
+}
+
+// validator checks values against the subset of JSON Schema that Schema generates
+type validator struct {
+	root *jsonschema.Schema
+	errs []FieldError
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// resolve follows a $ref to the schema's definitions
+func (v *validator) resolve(s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	for s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		def := v.root.Definitions[name]
+		if !ok || def == nil {
+			return nil, fmt.Errorf("%w: %s", ErrSchemaRef, s.Ref)
+		}
+		s = def
+	}
+	return s, nil
+}
+
+// validate records every way value doesn't match s. It only returns an error for a broken schema.
+func (v *validator) validate(s *jsonschema.Schema, path string, value any) error {
+	s, err := v.resolve(s)
+	if err != nil {
+		return err
+	}
+	if isFalse(s) {
+		v.fail(path, "is not allowed")
+		return nil
+	}
+
+	if s.Type != "" && !hasType(s.Type, value) {
+		v.fail(path, "expected %s but got %s", s.Type, typeName(value))
+		return nil
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, value) }) {
+		v.fail(path, "must be one of %s", enumList(s.Enum))
+	}
+	if s.Const != nil && !equal(s.Const, value) {
+		v.fail(path, "must be %v", s.Const)
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.validateString(s, path, value)
+	case float64:
+		v.validateNumber(s, path, value)
+	case []any:
+		if s.MinItems != nil && uint64(len(value)) < *s.MinItems {
+			v.fail(path, "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && uint64(len(value)) > *s.MaxItems {
+			v.fail(path, "must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				if err := v.validate(s.Items, fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		if err := v.validateObject(s, path, value); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		if err := v.validate(sub, path, value); err != nil {
+			return err
+		}
+	}
+	for _, alternatives := range [][]*jsonschema.Schema{s.AnyOf, s.OneOf} {
+		if len(alternatives) == 0 {
+			continue
+		}
+		matched := 0
+		for _, sub := range alternatives {
+			alt := &validator{root: v.root}
+			if err := alt.validate(sub, path, value); err != nil {
+				return err
+			}
+			if len(alt.errs) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			v.fail(path, "doesn't match any of the allowed schemas")
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateString(s *jsonschema.Schema, path, value string) {
+	n := uint64(len([]rune(value)))
+	if s.MinLength != nil && n < *s.MinLength {
+		v.fail(path, "must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		v.fail(path, "must be at most %d characters", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(value) {
+			v.fail(path, "must match %s", s.Pattern)
+		}
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			v.fail(path, "must be an RFC 3339 date-time")
+		}
+	}
+}
+
+func (v *validator) validateNumber(s *jsonschema.Schema, path string, value float64) {
+	bound := func(n json.Number) (float64, bool) {
+		if n == "" {
+			return 0, false
+		}
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	if min, ok := bound(s.Minimum); ok && value < min {
+		v.fail(path, "must be at least %s", s.Minimum)
+	}
+	if max, ok := bound(s.Maximum); ok && value > max {
+		v.fail(path, "must be at most %s", s.Maximum)
+	}
+	if min, ok := bound(s.ExclusiveMinimum); ok && value <= min {
+		v.fail(path, "must be greater than %s", s.ExclusiveMinimum)
+	}
+	if max, ok := bound(s.ExclusiveMaximum); ok && value >= max {
+		v.fail(path, "must be less than %s", s.ExclusiveMaximum)
+	}
+}
+
+func (v *validator) validateObject(s *jsonschema.Schema, path string, value map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			v.fail(join(path, name), "is required")
+		}
+	}
+
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		var prop *jsonschema.Schema
+		if s.Properties != nil {
+			prop, _ = s.Properties.Get(k)
+		}
+		if prop == nil {
+			for pattern, ps := range s.PatternProperties {
+				if re, err := regexp.Compile(pattern); err == nil && re.MatchString(k) {
+					prop = ps
+					break
+				}
+			}
+		}
+		if prop == nil && s.AdditionalProperties != nil {
+			if isFalse(s.AdditionalProperties) {
+				v.fail(join(path, k), "is not a known field")
+				continue
+			}
+			prop = s.AdditionalProperties
+		}
+		if prop == nil {
+			continue
+		}
+		if err := v.validate(prop, join(path, k), value[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// join appends a field to a path like nats.servers
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func isFalse(s *jsonschema.Schema) bool {
+	b, err := s.MarshalJSON()
+	return err == nil && string(b) == "false"
+}
+
+func hasType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func typeName(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// equal compares values from the schema and the config, which may have different numeric types
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func enumList(values []any) string {
+	list := make([]string, len(values))
+	for i, e := range values {
+		if s, ok := e.(string); ok {
+			list[i] = strconv.Quote(s)
+			continue
+		}
+		list[i] = fmt.Sprint(e)
+	}
+	return strings.Join(list, ", ")
+}
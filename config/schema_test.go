@@ -0,0 +1,128 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type natsConfig struct {
+	Servers []string `json:"servers" jsonschema:"minItems=1"`
+	Subject string   `json:"subject,omitempty" jsonschema:"pattern=^[a-z.]+$"`
+}
+
+type serviceConfig struct {
+	Name     string            `json:"name"`
+	Port     int               `json:"port" jsonschema:"minimum=1,maximum=65535"`
+	LogLevel string            `json:"log_level,omitempty" jsonschema:"enum=debug,enum=info,enum=error"`
+	NATS     natsConfig        `json:"nats"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name     string
+		fileName string
+		data     string
+		fields   []FieldError
+	}{
+		{name: "valid json", fileName: "config.json", data: `{"name": "orders", "port": 8080, "nats": {"servers": ["nats://localhost:4222"]}, "labels": {"team": "core"}}`},
+		{name: "valid yaml", fileName: "config.yaml", data: "name: orders\nport: 8080\nlog_level: info\nnats:\n  servers: [nats://localhost:4222]\n  subject: orders.get\n"},
+		{name: "valid cue", fileName: "config.cue", data: "name: \"orders\"\nport: 8080\nnats: servers: [\"nats://localhost:4222\"]\n"},
+		{name: "invalid json", fileName: "config.json", data: `{"name": "orders", "port": 70000, "log_level": "trace", "nats": {"servers": [4222], "subjct": "orders"}, "labels": {"team": 1}}`, fields: []FieldError{
+			{Path: "labels.team", Message: "expected string but got integer"},
+			{Path: "log_level", Message: `must be one of "debug", "info", "error"`},
+			{Path: "nats.servers[0]", Message: "expected string but got integer"},
+			{Path: "nats.subjct", Message: "is not a known field"},
+			{Path: "port", Message: "must be at most 65535"},
+		}},
+		{name: "invalid yaml", fileName: "config.yml", data: "port: eighty\nnats:\n  servers: []\n  subject: Orders\n", fields: []FieldError{
+			{Path: "name", Message: "is required"},
+			{Path: "nats.servers", Message: "must have at least 1 items"},
+			{Path: "nats.subject", Message: "must match ^[a-z.]+$"},
+			{Path: "port", Message: "expected integer but got string"},
+		}},
+	}
+
+	schema := Schema[serviceConfig]()
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), v.fileName)
+			if err := os.WriteFile(fp, []byte(v.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := Validate(schema, fp)
+			var ve *ValidationError
+			if v.fields == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.As(err, &ve) || !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("expected a validation error but got %v", err)
+			}
+			if !reflect.DeepEqual(ve.Fields, v.fields) {
+				t.Errorf("expected %v but got %v", v.fields, ve.Fields)
+			}
+		})
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	b, err := json.Marshal(Schema[serviceConfig]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "config.schema.json")
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(schemaPath, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{"name": "orders", "port": 0, "nats": {"servers": ["nats://localhost:4222"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := ReadSchema(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ve *ValidationError
+	if err := Validate(schema, configPath); !errors.As(err, &ve) || len(ve.Fields) != 1 || ve.Fields[0].Path != "port" {
+		t.Errorf("expected the read schema to reject port but got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(fp, []byte(`{"name": "orders", "port": 8080, "nats": {"servers": ["nats://localhost:4222"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(serviceConfig{}, "", fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "orders" || cfg.Port != 8080 || len(cfg.NATS.Servers) != 1 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// EncryptMsg encrypts the message data in place using the subject as additional data and sets the EncryptedHeader
+func (e *Encryptor) EncryptMsg(ctx context.Context, msg *nats.Msg) error {
+	data, err := e.Seal(ctx, msg.Data, []byte(msg.Subject))
+	if err != nil {
+		return err
+	}
+
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	msg.Header.Set(EncryptedHeader, "1")
+	msg.Data = data
+
+	return nil
+}
+
+// DecryptMsg decrypts the data of a message sent to subject. Messages without the EncryptedHeader are returned as is.
+func (e *Encryptor) DecryptMsg(ctx context.Context, subject string, msg *nats.Msg) ([]byte, error) {
+	if msg.Header.Get(EncryptedHeader) == "" {
+		return msg.Data, nil
+	}
+
+	return e.Open(ctx, msg.Data, []byte(subject))
+}
+
+// encryptedRequest decrypts the request data and encrypts responses for the wrapped handler
+type encryptedRequest struct {
+	micro.Request
+	ctx  context.Context
+	enc  *Encryptor
+	data []byte
+}
+
+func (r *encryptedRequest) Data() []byte {
+	return r.data
+}
+
+func (r *encryptedRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	sealed, err := r.enc.Seal(r.ctx, data, []byte(r.Subject()))
+	if err != nil {
+		return err
+	}
+
+	opts = append(opts, micro.WithHeaders(micro.Headers{EncryptedHeader: []string{"1"}}))
+	return r.Request.Respond(sealed, opts...)
+}
+
+func (r *encryptedRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return r.Respond(data, opts...)
+}
+
+// Handler transparently decrypts requests carrying the EncryptedHeader and encrypts their responses. Requests
+// without the header are rejected unless allowPlaintext is set. Errors are returned unencrypted.
+func Handler(e *Encryptor, allowPlaintext bool, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		if r.Headers().Get(EncryptedHeader) == "" {
+			if !allowPlaintext {
+				r.Error("400", "Bad Request", []byte(`{"errors": ["request must be encrypted"]}`))
+				return
+			}
+			h.Handle(r)
+			return
+		}
+
+		ctx := context.Background()
+		data, err := e.Open(ctx, r.Data(), []byte(r.Subject()))
+		if err != nil {
+			r.Error("400", "Bad Request", []byte(`{"errors": ["unable to decrypt request"]}`))
+			return
+		}
+
+		h.Handle(&encryptedRequest{Request: r, ctx: ctx, enc: e, data: data})
+	})
+}
+
+// KeyValue encrypts values at rest in a bucket. The bucket and key are used as additional data so values can't be
+// moved between keys. Only Get, Put, PutString, Create, and Update are encrypted; watchers and history return the
+// raw envelopes, which can be opened with Open.
+type KeyValue struct {
+	nats.KeyValue
+	enc *Encryptor
+}
+
+// NewKeyValue wraps the bucket
+func NewKeyValue(kv nats.KeyValue, e *Encryptor) *KeyValue {
+	return &KeyValue{KeyValue: kv, enc: e}
+}
+
+type entry struct {
+	nats.KeyValueEntry
+	value []byte
+}
+
+func (e entry) Value() []byte {
+	return e.value
+}
+
+func (k *KeyValue) additional(key string) []byte {
+	return []byte(fmt.Sprintf("%s.%s", k.Bucket(), key))
+}
+
+func (k *KeyValue) Get(key string) (nats.KeyValueEntry, error) {
+	e, err := k.KeyValue.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := k.enc.Open(context.Background(), e.Value(), k.additional(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return entry{KeyValueEntry: e, value: value}, nil
+}
+
+func (k *KeyValue) Put(key string, value []byte) (uint64, error) {
+	sealed, err := k.enc.Seal(context.Background(), value, k.additional(key))
+	if err != nil {
+		return 0, err
+	}
+
+	return k.KeyValue.Put(key, sealed)
+}
+
+func (k *KeyValue) PutString(key string, value string) (uint64, error) {
+	return k.Put(key, []byte(value))
+}
+
+func (k *KeyValue) Create(key string, value []byte) (uint64, error) {
+	sealed, err := k.enc.Seal(context.Background(), value, k.additional(key))
+	if err != nil {
+		return 0, err
+	}
+
+	return k.KeyValue.Create(key, sealed)
+}
+
+func (k *KeyValue) Update(key string, value []byte, last uint64) (uint64, error) {
+	sealed, err := k.enc.Seal(context.Background(), value, k.additional(key))
+	if err != nil {
+		return 0, err
+	}
+
+	return k.KeyValue.Update(key, sealed, last)
+}
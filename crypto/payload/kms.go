@@ -0,0 +1,186 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payload
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nats-io/nkeys"
+)
+
+// LocalKMS wraps data keys with AES-256-GCM key encryption keys held in memory. Older keys can be kept in the
+// ring so data wrapped before a rotation can still be read.
+type LocalKMS struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// NewLocalKMS returns a LocalKMS using the 32 byte key as the current key
+func NewLocalKMS(keyID string, key []byte) (*LocalKMS, error) {
+	l := &LocalKMS{keys: make(map[string][]byte)}
+	if err := l.Rotate(keyID, key); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Rotate adds the key to the ring and makes it the current key
+func (l *LocalKMS) Rotate(keyID string, key []byte) error {
+	if len(key) != dataKeySize {
+		return fmt.Errorf("key must be %d bytes", dataKeySize)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys[keyID] = key
+	l.current = keyID
+
+	return nil
+}
+
+// KeyID returns the current key id
+func (l *LocalKMS) KeyID() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+func (l *LocalKMS) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	l.mu.RLock()
+	keyID, key := l.current, l.keys[l.current]
+	l.mu.RUnlock()
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+
+	return keyID, gcm.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+func (l *LocalKMS) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	l.mu.RLock()
+	key, ok := l.keys[keyID]
+	l.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], nil)
+}
+
+// XKeyKMS wraps data keys for an X25519 recipient with NATS curve keys (xkeys) from nkeys. It fills the role of
+// age recipients without another dependency, but wrapped keys use the nkeys sealed box format, so age can't
+// open them. Services that only write can be given a KMS built from the recipient public key, while readers hold
+// the seed.
+type XKeyKMS struct {
+	recipient string
+	private   nkeys.KeyPair
+}
+
+// NewXKeyKMS returns an XKeyKMS for the recipient public curve key. The private key pair is only required to Unwrap
+// and may be nil for write only services.
+func NewXKeyKMS(recipient string, private nkeys.KeyPair) (*XKeyKMS, error) {
+	if !nkeys.IsValidPublicCurveKey(recipient) {
+		return nil, fmt.Errorf("invalid recipient curve key %q", recipient)
+	}
+
+	return &XKeyKMS{
+		recipient: recipient,
+		private:   private,
+	}, nil
+}
+
+// KeyID returns the recipient public key
+func (x *XKeyKMS) KeyID() string {
+	return x.recipient
+}
+
+// Wrap seals the data key for the recipient with a new sender key. The sender public key is prepended so Unwrap
+// can open it.
+func (x *XKeyKMS) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	sender, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	defer sender.Wipe()
+
+	pub, err := sender.PublicKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	sealed, err := sender.Seal(dataKey, x.recipient)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return x.recipient, append([]byte(pub), sealed...), nil
+}
+
+func (x *XKeyKMS) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if x.private == nil || keyID != x.recipient {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	// curve public keys are always 56 characters
+	const pubLen = 56
+	if len(wrapped) < pubLen {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return x.private.Open(wrapped[pubLen:], string(wrapped[:pubLen]))
+}
+
+// RemoteKMS adapts a cloud KMS client, such as AWS KMS, GCP KMS, or Vault transit, by calling its encrypt and
+// decrypt operations for each data key
+type RemoteKMS struct {
+	ID      string
+	Encrypt func(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt func(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+func (r RemoteKMS) KeyID() string {
+	return r.ID
+}
+
+func (r RemoteKMS) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := r.Encrypt(ctx, r.ID, dataKey)
+	return r.ID, wrapped, err
+}
+
+func (r RemoteKMS) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	return r.Decrypt(ctx, keyID, wrapped)
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payload
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// EncryptedHeader marks a message whose data is an encrypted envelope
+const EncryptedHeader = "X-Sencillo-Encrypted"
+
+const (
+	envelopeVersion = 1
+	dataKeySize     = 32
+)
+
+var (
+	ErrInvalidEnvelope = fmt.Errorf("invalid encrypted envelope")
+	ErrUnknownKey      = fmt.Errorf("unknown key id")
+)
+
+// KMS wraps and unwraps data encryption keys with a key encryption key it manages
+type KMS interface {
+	// Wrap encrypts a data key with the current key and returns that key's id. The id is returned with the
+	// wrapped key, so a rotation between the two can't record the wrong key.
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// Unwrap decrypts a data key that was wrapped with the key id
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Envelope is an AES-GCM encrypted payload along with its wrapped data key
+type Envelope struct {
+	KeyID      string
+	WrappedKey []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// Encryptor seals payloads with a fresh AES-256-GCM data key per payload, wrapped by the KMS
+type Encryptor struct {
+	kms KMS
+}
+
+// NewEncryptor returns an Encryptor using the KMS
+func NewEncryptor(kms KMS) *Encryptor {
+	return &Encryptor{kms: kms}
+}
+
+// Seal encrypts the plaintext. The additional data, such as a subject or key name, is authenticated but not
+// stored, so the same value must be passed to Open.
+func (e *Encryptor) Seal(ctx context.Context, plaintext, additional []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	keyID, wrapped, err := e.kms.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key: %w", err)
+	}
+
+	env := Envelope{
+		KeyID:      keyID,
+		WrappedKey: wrapped,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, additional),
+	}
+
+	return env.MarshalBinary()
+}
+
+// Open decrypts a payload created by Seal
+func (e *Encryptor) Open(ctx context.Context, data, additional []byte) ([]byte, error) {
+	var env Envelope
+	if err := env.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	dataKey, err := e.kms.Unwrap(ctx, env.KeyID, env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return gcm.Open(nil, env.Nonce, env.Ciphertext, additional)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// MarshalBinary encodes the envelope as a version byte followed by length prefixed fields
+func (e Envelope) MarshalBinary() ([]byte, error) {
+	b := []byte{envelopeVersion}
+	for _, f := range [][]byte{[]byte(e.KeyID), e.WrappedKey, e.Nonce, e.Ciphertext} {
+		b = binary.AppendUvarint(b, uint64(len(f)))
+		b = append(b, f...)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes an envelope encoded with MarshalBinary
+func (e *Envelope) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 || b[0] != envelopeVersion {
+		return ErrInvalidEnvelope
+	}
+	b = b[1:]
+
+	fields := make([][]byte, 4)
+	for i := range fields {
+		n, read := binary.Uvarint(b)
+		if read <= 0 || uint64(len(b)-read) < n {
+			return ErrInvalidEnvelope
+		}
+		fields[i] = b[read : read+int(n)]
+		b = b[read+int(n):]
+	}
+
+	if len(b) != 0 {
+		return ErrInvalidEnvelope
+	}
+
+	e.KeyID = string(fields[0])
+	e.WrappedKey = fields[1]
+	e.Nonce = fields[2]
+	e.Ciphertext = fields[3]
+
+	return nil
+}
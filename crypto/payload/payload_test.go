@@ -0,0 +1,135 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestSealOpen(t *testing.T) {
+	local, err := NewLocalKMS("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	curve, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := curve.PublicKey()
+	xkms, err := NewXKeyKMS(pub, curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+		kms  KMS
+	}{
+		{name: "local", kms: local},
+		{name: "xkey", kms: xkms},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctx := context.Background()
+			e := NewEncryptor(v.kms)
+			plaintext := []byte(`{"ssn": "123-45-6789"}`)
+
+			sealed, err := e.Seal(ctx, plaintext, []byte("users.1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes.Contains(sealed, plaintext) {
+				t.Fatal("expected plaintext to be encrypted")
+			}
+
+			opened, err := e.Open(ctx, sealed, []byte("users.1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("expected %s but got %s", plaintext, opened)
+			}
+
+			if _, err := e.Open(ctx, sealed, []byte("users.2")); err == nil {
+				t.Errorf("expected mismatched additional data to fail")
+			}
+		})
+	}
+}
+
+func TestLocalKMSRotate(t *testing.T) {
+	ctx := context.Background()
+	kms, err := NewLocalKMS("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEncryptor(kms)
+
+	old, err := e.Seal(ctx, []byte("old"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kms.Rotate("k2", bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.Open(ctx, old, nil); err != nil {
+		t.Errorf("expected data sealed before rotation to open, got %v", err)
+	}
+
+	other, _ := NewLocalKMS("k3", bytes.Repeat([]byte{3}, 32))
+	if _, err := NewEncryptor(other).Open(ctx, old, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey but got %v", err)
+	}
+}
+
+func TestSealDuringRotate(t *testing.T) {
+	ctx := context.Background()
+	kms, err := NewLocalKMS("k0", bytes.Repeat([]byte{0}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEncryptor(kms)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			kms.Rotate(fmt.Sprintf("k%d", i+1), bytes.Repeat([]byte{byte(i + 1)}, 32))
+		}
+	}()
+
+	// every payload must record the key that wrapped it, whichever key was current at the time
+	for range 100 {
+		sealed, err := e.Seal(ctx, []byte("data"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Open(ctx, sealed, nil); err != nil {
+			t.Fatalf("expected the payload to open, got %v", err)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConsulOpt is a functional option to modify the Consul registrar
+type ConsulOpt func(*Consul)
+
+// Consul registers each listener as a Consul service through the local agent's HTTP API, with a TTL check
+// kept passing by heartbeats
+type Consul struct {
+	addr       string
+	token      string
+	client     *http.Client
+	ttl        time.Duration
+	deregister time.Duration
+}
+
+// SetConsulToken sets the ACL token sent with every request
+func SetConsulToken(token string) ConsulOpt {
+	return func(c *Consul) {
+		c.token = token
+	}
+}
+
+// SetConsulClient sets the HTTP client used to call the agent
+func SetConsulClient(client *http.Client) ConsulOpt {
+	return func(c *Consul) {
+		c.client = client
+	}
+}
+
+// SetCheckTTL sets how long the check stays passing without a heartbeat. It defaults to 30 seconds; keep it a
+// few heartbeat intervals long.
+func SetCheckTTL(d time.Duration) ConsulOpt {
+	return func(c *Consul) {
+		c.ttl = d
+	}
+}
+
+// SetDeregisterAfter sets how long the check can be critical before Consul removes an instance that stopped
+// without deregistering. It defaults to a minute.
+func SetDeregisterAfter(d time.Duration) ConsulOpt {
+	return func(c *Consul) {
+		c.deregister = d
+	}
+}
+
+// NewConsul returns a registrar for the agent at addr, such as http://127.0.0.1:8500
+func NewConsul(addr string, opts ...ConsulOpt) *Consul {
+	c := &Consul{
+		addr:       strings.TrimSuffix(addr, "/"),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		ttl:        30 * time.Second,
+		deregister: time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+type consulCheck struct {
+	CheckID                        string `json:"CheckID"`
+	Name                           string `json:"Name"`
+	TTL                            string `json:"TTL"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+type consulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   consulCheck       `json:"Check"`
+}
+
+// serviceID returns the Consul ID of a listener, unique on the agent
+func serviceID(s Service, l Listener) string {
+	return s.ID + "-" + l.Name
+}
+
+func (c *Consul) services(s Service) []consulService {
+	meta := map[string]string{"instance": s.ID}
+	if s.Version != "" {
+		meta["version"] = s.Version
+	}
+	for k, v := range s.Metadata {
+		meta[k] = v
+	}
+
+	var out []consulService
+	for _, l := range s.Listeners {
+		id := serviceID(s, l)
+		out = append(out, consulService{
+			ID:      id,
+			Name:    s.Name,
+			Tags:    append([]string{l.Name}, l.Tags...),
+			Address: s.address(),
+			Port:    l.Port,
+			Meta:    meta,
+			Check: consulCheck{
+				CheckID:                        "service:" + id,
+				Name:                           fmt.Sprintf("%s %s TTL", s.Name, l.Name),
+				TTL:                            c.ttl.String(),
+				DeregisterCriticalServiceAfter: c.deregister.String(),
+			},
+		})
+	}
+	return out
+}
+
+func (c *Consul) Register(ctx context.Context, s Service) error {
+	for _, svc := range c.services(s) {
+		if err := c.put(ctx, "/v1/agent/service/register", svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Consul) Heartbeat(ctx context.Context, s Service, health error) error {
+	update := struct {
+		Status string `json:"Status"`
+		Output string `json:"Output,omitempty"`
+	}{Status: "passing"}
+	if health != nil {
+		update.Status, update.Output = "critical", health.Error()
+	}
+
+	for _, l := range s.Listeners {
+		if err := c.put(ctx, "/v1/agent/check/update/"+url.PathEscape("service:"+serviceID(s, l)), update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Consul) Deregister(ctx context.Context, s Service) error {
+	for _, l := range s.Listeners {
+		if err := c.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(serviceID(s, l)), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Consul) put(ctx context.Context, path string, body any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.addr+path, r)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("consul %s: %s: %s", path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
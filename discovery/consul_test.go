@@ -0,0 +1,96 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsul(t *testing.T) {
+	var requests []string
+	bodies := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		requests = append(requests, r.URL.EscapedPath())
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > 0 {
+			var body map[string]any
+			json.Unmarshal(data, &body)
+			bodies[r.URL.EscapedPath()+" "+strings.Join([]string{str(body["ID"]), str(body["Status"])}, "")] = body
+		}
+	}))
+	defer srv.Close()
+
+	c := NewConsul(srv.URL, SetConsulToken("secret"), SetCheckTTL(20*time.Second))
+	ctx := context.Background()
+	if err := c.Register(ctx, orders); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Heartbeat(ctx, orders, errors.New("database down")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Deregister(ctx, orders); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"/v1/agent/service/register",
+		"/v1/agent/service/register",
+		"/v1/agent/check/update/service:abc123-http",
+		"/v1/agent/check/update/service:abc123-grpc",
+		"/v1/agent/service/deregister/abc123-http",
+		"/v1/agent/service/deregister/abc123-grpc",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests %v", requests)
+	}
+
+	grpc := bodies["/v1/agent/service/register abc123-grpc"]
+	if grpc["Name"] != "orders" || grpc["Address"] != "10.0.0.7" || grpc["Port"] != float64(9090) {
+		t.Errorf("unexpected registration %v", grpc)
+	}
+	check, _ := grpc["Check"].(map[string]any)
+	if check["TTL"] != "20s" || check["CheckID"] != "service:abc123-grpc" {
+		t.Errorf("unexpected check %v", check)
+	}
+	meta, _ := grpc["Meta"].(map[string]any)
+	if meta["version"] != "1.2.0" || meta["region"] != "eu" {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+	if update := bodies["/v1/agent/check/update/service:abc123-http critical"]; update["Output"] != "database down" {
+		t.Errorf("expected a critical check update but got %v", bodies)
+	}
+
+	err := NewConsul(srv.URL).Register(ctx, orders)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected the agent's status in the error but got %v", err)
+	}
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package discovery registers a service's listeners with the discovery systems of heterogeneous environments,
+// such as Consul or DNS-SD on the local network, alongside the NATS-native registry. The service is declared
+// once and every Registrar publishes it in its own format.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/registry"
+)
+
+var ErrInvalidService = fmt.Errorf("services need a name, an id, and listeners with a name and port")
+
+// Listener is a port the service accepts connections on. Name is the protocol spoken, such as http or grpc,
+// and Protocol is the transport, tcp by default.
+type Listener struct {
+	Name     string
+	Port     int
+	Protocol string
+	Tags     []string
+}
+
+func (l Listener) protocol() string {
+	if l.Protocol == "" {
+		return "tcp"
+	}
+	return l.Protocol
+}
+
+// Service is an instance of a service and the listeners it exposes. Address is the host or IP other services
+// reach it at; it defaults to the first non-loopback IPv4 address of the machine.
+type Service struct {
+	Name      string
+	ID        string
+	Version   string
+	Address   string
+	Listeners []Listener
+	Metadata  map[string]string
+}
+
+func (s Service) validate() error {
+	if s.Name == "" || s.ID == "" || len(s.Listeners) == 0 {
+		return ErrInvalidService
+	}
+	for _, l := range s.Listeners {
+		if l.Name == "" || l.Port <= 0 {
+			return ErrInvalidService
+		}
+	}
+	return nil
+}
+
+// address returns the address other services reach the instance at
+func (s Service) address() string {
+	if s.Address != "" {
+		return s.Address
+	}
+	if ip := hostIP(); ip != nil {
+		return ip.String()
+	}
+	return "127.0.0.1"
+}
+
+// hostIP returns the first non-loopback IPv4 address of the machine
+func hostIP() net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.To4()
+		}
+	}
+	return nil
+}
+
+// Registrar publishes a service in one discovery system. Register is retried on every heartbeat until it
+// succeeds. Heartbeat reports the instance's health, nil when healthy, so systems with health checks can stop
+// routing to it.
+type Registrar interface {
+	Register(ctx context.Context, s Service) error
+	Heartbeat(ctx context.Context, s Service, health error) error
+	Deregister(ctx context.Context, s Service) error
+}
+
+// RunOpt is a functional option to modify Run
+type RunOpt func(*runConfig)
+
+type runConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+	health   func(context.Context) error
+	logger   *slog.Logger
+}
+
+// SetInterval sets how often heartbeats are sent. It defaults to 10 seconds.
+func SetInterval(d time.Duration) RunOpt {
+	return func(c *runConfig) {
+		c.interval = d
+	}
+}
+
+// SetDeregisterTimeout sets how long deregistering may take once Run is stopped. It defaults to 5 seconds.
+func SetDeregisterTimeout(d time.Duration) RunOpt {
+	return func(c *runConfig) {
+		c.timeout = d
+	}
+}
+
+// SetHealthCheck sets a check run before every heartbeat. Its error is reported as the instance's health.
+func SetHealthCheck(f func(context.Context) error) RunOpt {
+	return func(c *runConfig) {
+		c.health = f
+	}
+}
+
+// SetLogger sets the logger used to report failed registrations
+func SetLogger(l *slog.Logger) RunOpt {
+	return func(c *runConfig) {
+		c.logger = l
+	}
+}
+
+// Run registers the service with every registrar and sends heartbeats until ctx is done, then deregisters it.
+// A registrar that fails is logged and retried on the next interval without holding up the others.
+func Run(ctx context.Context, s Service, registrars []Registrar, opts ...RunOpt) error {
+	cfg := runConfig{
+		interval: 10 * time.Second,
+		timeout:  5 * time.Second,
+		logger:   slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if s.Address == "" {
+		s.Address = s.address()
+	}
+
+	registered := make([]bool, len(registrars))
+	ticker := time.NewTicker(cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		var health error
+		if cfg.health != nil {
+			health = cfg.health(ctx)
+		}
+
+		for i, r := range registrars {
+			if !registered[i] {
+				if err := r.Register(ctx, s); err != nil {
+					cfg.logger.Error(fmt.Sprintf("registering %s with %T: %v", s.ID, r, err))
+					continue
+				}
+				registered[i] = true
+			}
+			if err := r.Heartbeat(ctx, s, health); err != nil {
+				// the registration may have expired or been lost, so register again on the next tick
+				cfg.logger.Warn(fmt.Sprintf("heartbeat of %s to %T: %v", s.ID, r, err))
+				registered[i] = false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return deregister(s, registrars, registered, cfg.timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func deregister(s Service, registrars []Registrar, registered []bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for i, r := range registrars {
+		if !registered[i] {
+			continue
+		}
+		if err := r.Deregister(ctx, s); err != nil {
+			errs = append(errs, fmt.Errorf("deregistering from %T: %w", r, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// natsRegistrar publishes the service in the NATS-native registry
+type natsRegistrar struct {
+	registry *registry.Registry
+	started  time.Time
+}
+
+// NATS returns a Registrar publishing the service in the registry, with a URL endpoint for each listener, so
+// `sgoctl services list` shows the same declaration
+func NATS(r *registry.Registry) Registrar {
+	return &natsRegistrar{registry: r, started: time.Now().UTC()}
+}
+
+func (n *natsRegistrar) instance(s Service, health error) registry.Instance {
+	inst := registry.Instance{
+		Name:     s.Name,
+		ID:       s.ID,
+		Version:  s.Version,
+		Metadata: s.Metadata,
+		Health:   registry.HealthOK,
+		Started:  n.started,
+	}
+	if health != nil {
+		inst.Health = fmt.Sprintf("%s: %v", registry.HealthUnhealthy, health)
+	}
+	for _, l := range s.Listeners {
+		inst.Endpoints = append(inst.Endpoints, registry.Endpoint{
+			Name: l.Name,
+			URL:  fmt.Sprintf("%s://%s", l.Name, net.JoinHostPort(s.address(), strconv.Itoa(l.Port))),
+		})
+	}
+	return inst
+}
+
+func (n *natsRegistrar) Register(ctx context.Context, s Service) error {
+	return n.registry.Put(n.instance(s, nil))
+}
+
+func (n *natsRegistrar) Heartbeat(ctx context.Context, s Service, health error) error {
+	return n.registry.Put(n.instance(s, health))
+}
+
+func (n *natsRegistrar) Deregister(ctx context.Context, s Service) error {
+	return n.registry.Deregister(n.instance(s, nil))
+}
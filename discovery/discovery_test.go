@@ -0,0 +1,177 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/SencilloDev/sencillo-go/registry"
+)
+
+var orders = Service{
+	Name:      "orders",
+	ID:        "abc123",
+	Version:   "1.2.0",
+	Address:   "10.0.0.7",
+	Listeners: []Listener{{Name: "http", Port: 8080}, {Name: "grpc", Port: 9090}},
+	Metadata:  map[string]string{"region": "eu"},
+}
+
+// fakeRegistrar records calls and fails to register the first failures times and to heartbeat the first lost
+// times
+type fakeRegistrar struct {
+	mu       sync.Mutex
+	failures int
+	lost     int
+	calls    []string
+}
+
+func (f *fakeRegistrar) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeRegistrar) Register(ctx context.Context, s Service) error {
+	f.record("register")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (f *fakeRegistrar) Heartbeat(ctx context.Context, s Service, health error) error {
+	f.mu.Lock()
+	lost := f.lost > 0
+	if lost {
+		f.lost--
+	}
+	f.mu.Unlock()
+	if lost {
+		f.record("lost")
+		return errors.New("not registered")
+	}
+	if health != nil {
+		f.record("unhealthy")
+		return nil
+	}
+	f.record("healthy")
+	return nil
+}
+
+func (f *fakeRegistrar) Deregister(ctx context.Context, s Service) error {
+	f.record("deregister")
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	flaky := &fakeRegistrar{failures: 1}
+	steady := &fakeRegistrar{}
+	expired := &fakeRegistrar{lost: 1}
+	var checks int
+	var mu sync.Mutex
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, orders, []Registrar{flaky, steady, expired},
+			SetInterval(10*time.Millisecond),
+			SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			SetHealthCheck(func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				checks++
+				if checks == 2 {
+					return errors.New("database down")
+				}
+				if checks == 3 {
+					cancel()
+				}
+				return nil
+			}),
+		)
+	}()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name      string
+		registrar *fakeRegistrar
+		calls     string
+	}{
+		{name: "retried", registrar: flaky, calls: "register,register,unhealthy,healthy,deregister"},
+		{name: "steady", registrar: steady, calls: "register,healthy,unhealthy,healthy,deregister"},
+		{name: "reregistered", registrar: expired, calls: "register,lost,register,unhealthy,healthy,deregister"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := strings.Join(v.registrar.calls, ","); got != v.calls {
+				t.Errorf("expected %s but got %s", v.calls, got)
+			}
+		})
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	err := Run(context.Background(), Service{Name: "orders", ID: "1", Listeners: []Listener{{Name: "http"}}}, nil)
+	if !errors.Is(err, ErrInvalidService) {
+		t.Errorf("expected a listener without a port to be invalid but got %v", err)
+	}
+}
+
+func TestNATS(t *testing.T) {
+	kv := kvtest.New()
+	r := NATS(registry.NewRegistry(kv))
+	ctx := context.Background()
+
+	if err := r.Register(ctx, orders); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Heartbeat(ctx, orders, errors.New("database down")); err != nil {
+		t.Fatal(err)
+	}
+
+	var inst registry.Instance
+	if err := json.Unmarshal(kv.Value("orders.abc123"), &inst); err != nil {
+		t.Fatal(err)
+	}
+	if inst.Health != "unhealthy: database down" || inst.Version != "1.2.0" {
+		t.Errorf("unexpected instance %+v", inst)
+	}
+	if len(inst.Endpoints) != 2 || inst.Endpoints[0].URL != "http://10.0.0.7:8080" || inst.Endpoints[1].URL != "grpc://10.0.0.7:9090" {
+		t.Errorf("expected an endpoint per listener but got %+v", inst.Endpoints)
+	}
+
+	if err := r.Deregister(ctx, orders); err != nil {
+		t.Fatal(err)
+	}
+	if kv.Len() != 0 {
+		t.Errorf("expected the instance to be removed but got %d keys", kv.Len())
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mdnsAddr is the IPv4 multicast group and port of multicast DNS
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// classCacheFlush marks records only this responder answers for, so caches replace instead of merging them
+const classCacheFlush = 1 << 15
+
+// DNSSDOpt is a functional option to modify the DNS-SD registrar
+type DNSSDOpt func(*DNSSD)
+
+// DNSSD advertises each listener as a DNS-SD service over multicast DNS, so tools like dns-sd and avahi-browse
+// and other services on the local network find it without a discovery server. A listener named http is
+// advertised as _http._tcp.local. Instances are withdrawn while unhealthy.
+type DNSSD struct {
+	domain string
+	host   string
+	ttl    uint32
+	iface  *net.Interface
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	records []dnsmessage.Resource
+	active  bool
+	conn    *net.UDPConn
+}
+
+// SetDNSSDDomain sets the domain services are advertised in. It defaults to local.
+func SetDNSSDDomain(d string) DNSSDOpt {
+	return func(r *DNSSD) {
+		r.domain = strings.Trim(d, ".")
+	}
+}
+
+// SetDNSSDHost sets the host name advertised for the service's address. It defaults to the machine's host name.
+func SetDNSSDHost(h string) DNSSDOpt {
+	return func(r *DNSSD) {
+		r.host = h
+	}
+}
+
+// SetDNSSDTTL sets how many seconds resolvers cache the records. It defaults to 120.
+func SetDNSSDTTL(ttl uint32) DNSSDOpt {
+	return func(r *DNSSD) {
+		r.ttl = ttl
+	}
+}
+
+// SetDNSSDInterface sets the network interface to advertise on. The default lets the system choose.
+func SetDNSSDInterface(iface *net.Interface) DNSSDOpt {
+	return func(r *DNSSD) {
+		r.iface = iface
+	}
+}
+
+// SetDNSSDLogger sets the logger used to report malformed queries and failed responses
+func SetDNSSDLogger(l *slog.Logger) DNSSDOpt {
+	return func(r *DNSSD) {
+		r.logger = l
+	}
+}
+
+// NewDNSSD returns a registrar advertising services over multicast DNS
+func NewDNSSD(opts ...DNSSDOpt) *DNSSD {
+	host, _ := os.Hostname()
+	r := &DNSSD{
+		domain: "local",
+		host:   strings.Split(host, ".")[0],
+		ttl:    120,
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// label makes s usable as one DNS label
+func label(s string) string {
+	return strings.NewReplacer(".", "-", " ", "-").Replace(s)
+}
+
+// buildRecords returns the PTR, SRV, TXT, and A records advertising every listener of the service
+func (r *DNSSD) buildRecords(s Service) ([]dnsmessage.Resource, error) {
+	ip := net.ParseIP(s.address()).To4()
+	if ip == nil {
+		addrs, err := net.LookupIP(s.address())
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			if ip = a.To4(); ip != nil {
+				break
+			}
+		}
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("no IPv4 address for %s", s.address())
+	}
+
+	host, err := dnsmessage.NewName(label(r.host) + "." + r.domain + ".")
+	if err != nil {
+		return nil, err
+	}
+	enumeration, err := dnsmessage.NewName("_services._dns-sd._udp." + r.domain + ".")
+	if err != nil {
+		return nil, err
+	}
+
+	txt := []string{"id=" + s.ID}
+	if s.Version != "" {
+		txt = append(txt, "version="+s.Version)
+	}
+	var meta []string
+	for k, v := range s.Metadata {
+		meta = append(meta, k+"="+v)
+	}
+	sort.Strings(meta)
+	txt = append(txt, meta...)
+
+	header := func(name dnsmessage.Name, typ dnsmessage.Type, unique bool) dnsmessage.ResourceHeader {
+		class := dnsmessage.ClassINET
+		if unique {
+			class |= classCacheFlush
+		}
+		return dnsmessage.ResourceHeader{Name: name, Type: typ, Class: class, TTL: r.ttl}
+	}
+
+	records := []dnsmessage.Resource{{
+		Header: header(host, dnsmessage.TypeA, true),
+		Body:   &dnsmessage.AResource{A: [4]byte(ip)},
+	}}
+	for _, l := range s.Listeners {
+		serviceType, err := dnsmessage.NewName(fmt.Sprintf("_%s._%s.%s.", label(l.Name), l.protocol(), r.domain))
+		if err != nil {
+			return nil, err
+		}
+		instance, err := dnsmessage.NewName(fmt.Sprintf("%s.%s", label(s.Name+"-"+s.ID), serviceType))
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records,
+			dnsmessage.Resource{Header: header(enumeration, dnsmessage.TypePTR, false), Body: &dnsmessage.PTRResource{PTR: serviceType}},
+			dnsmessage.Resource{Header: header(serviceType, dnsmessage.TypePTR, false), Body: &dnsmessage.PTRResource{PTR: instance}},
+			dnsmessage.Resource{Header: header(instance, dnsmessage.TypeSRV, true), Body: &dnsmessage.SRVResource{Port: uint16(l.Port), Target: host}},
+			dnsmessage.Resource{Header: header(instance, dnsmessage.TypeTXT, true), Body: &dnsmessage.TXTResource{TXT: txt}},
+		)
+	}
+
+	return records, nil
+}
+
+func sameName(a, b dnsmessage.Name) bool {
+	return strings.EqualFold(a.String(), b.String())
+}
+
+// target returns the name a PTR or SRV record points at
+func target(rr dnsmessage.Resource) (dnsmessage.Name, bool) {
+	switch b := rr.Body.(type) {
+	case *dnsmessage.PTRResource:
+		return b.PTR, true
+	case *dnsmessage.SRVResource:
+		return b.Target, true
+	}
+	return dnsmessage.Name{}, false
+}
+
+// answer returns the response to a query, with the records a browser needs next as additionals, and whether
+// there is anything to answer
+func (r *DNSSD) answer(q dnsmessage.Message) (dnsmessage.Message, bool) {
+	r.mu.Lock()
+	records := r.records
+	if !r.active {
+		records = nil
+	}
+	r.mu.Unlock()
+
+	resp := dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+	if q.Header.Response {
+		return resp, false
+	}
+
+	included := make(map[int]bool)
+	var follow []dnsmessage.Name
+	for _, question := range q.Questions {
+		for i, rr := range records {
+			if included[i] || !sameName(rr.Header.Name, question.Name) {
+				continue
+			}
+			if question.Type != rr.Header.Type && question.Type != dnsmessage.TypeALL {
+				continue
+			}
+			included[i] = true
+			resp.Answers = append(resp.Answers, rr)
+			if t, ok := target(rr); ok {
+				follow = append(follow, t)
+			}
+		}
+	}
+
+	// follow PTR to SRV and TXT, and SRV to A, so one round trip resolves an instance
+	for len(follow) > 0 {
+		name := follow[0]
+		follow = follow[1:]
+		for i, rr := range records {
+			if included[i] || !sameName(rr.Header.Name, name) || rr.Header.Type == dnsmessage.TypePTR {
+				continue
+			}
+			included[i] = true
+			resp.Additionals = append(resp.Additionals, rr)
+			if t, ok := target(rr); ok {
+				follow = append(follow, t)
+			}
+		}
+	}
+
+	return resp, len(resp.Answers) > 0
+}
+
+// announce multicasts every record, or withdraws them with a TTL of zero
+func (r *DNSSD) announce(withdraw bool) error {
+	r.mu.Lock()
+	conn := r.conn
+	records := append([]dnsmessage.Resource(nil), r.records...)
+	r.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	for i := range records {
+		if withdraw {
+			records[i].Header.TTL = 0
+		}
+	}
+	data, err := (&dnsmessage.Message{
+		Header:  dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: records,
+	}).Pack()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.WriteToUDP(data, mdnsAddr)
+	return err
+}
+
+func (r *DNSSD) serve(conn *net.UDPConn) {
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			r.logger.Warn(fmt.Sprintf("reading mDNS query: %v", err))
+			continue
+		}
+
+		var q dnsmessage.Message
+		if err := q.Unpack(buf[:n]); err != nil {
+			r.logger.Debug(fmt.Sprintf("dropping malformed mDNS message from %s: %v", from, err))
+			continue
+		}
+		resp, ok := r.answer(q)
+		if !ok {
+			continue
+		}
+		data, err := resp.Pack()
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("packing mDNS response: %v", err))
+			continue
+		}
+		if _, err := conn.WriteToUDP(data, mdnsAddr); err != nil {
+			r.logger.Warn(fmt.Sprintf("sending mDNS response: %v", err))
+		}
+	}
+}
+
+func (r *DNSSD) Register(ctx context.Context, s Service) error {
+	records, err := r.buildRecords(s)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", r.iface, mdnsAddr)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.records = records
+	r.active = true
+	r.conn = conn
+	r.mu.Unlock()
+
+	go r.serve(conn)
+	return r.announce(false)
+}
+
+// Heartbeat withdraws the records while the instance is unhealthy and announces them again once it recovers
+func (r *DNSSD) Heartbeat(ctx context.Context, s Service, health error) error {
+	r.mu.Lock()
+	changed := r.active != (health == nil)
+	r.active = health == nil
+	r.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	return r.announce(health != nil)
+}
+
+// Deregister says goodbye, so browsers drop the service right away, and stops answering queries
+func (r *DNSSD) Deregister(ctx context.Context, s Service) error {
+	err := r.announce(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = false
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func query(name string, typ dnsmessage.Type) dnsmessage.Message {
+	return dnsmessage.Message{Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET}}}
+}
+
+// describe lists the records of a section as sorted name/type pairs
+func describe(records []dnsmessage.Resource) string {
+	var out []string
+	for _, rr := range records {
+		out = append(out, rr.Header.Name.String()+" "+rr.Header.Type.String())
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
+
+func TestDNSSDAnswer(t *testing.T) {
+	r := NewDNSSD(SetDNSSDHost("node1"))
+	records, err := r.buildRecords(orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.records, r.active = records, true
+
+	tt := []struct {
+		name        string
+		query       dnsmessage.Message
+		answers     string
+		additionals string
+	}{
+		{
+			name:        "browse",
+			query:       query("_http._tcp.local.", dnsmessage.TypePTR),
+			answers:     "_http._tcp.local. TypePTR",
+			additionals: "node1.local. TypeA, orders-abc123._http._tcp.local. TypeSRV, orders-abc123._http._tcp.local. TypeTXT",
+		},
+		{
+			name:        "resolve",
+			query:       query("ORDERS-abc123._grpc._tcp.local.", dnsmessage.TypeSRV),
+			answers:     "orders-abc123._grpc._tcp.local. TypeSRV",
+			additionals: "node1.local. TypeA",
+		},
+		{
+			name:    "enumerate",
+			query:   query("_services._dns-sd._udp.local.", dnsmessage.TypePTR),
+			answers: "_services._dns-sd._udp.local. TypePTR, _services._dns-sd._udp.local. TypePTR",
+		},
+		{name: "other service", query: query("_ipp._tcp.local.", dnsmessage.TypePTR)},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			resp, ok := r.answer(v.query)
+			if ok != (v.answers != "") {
+				t.Fatalf("expected an answer to be %v", v.answers != "")
+			}
+			if got := describe(resp.Answers); got != v.answers {
+				t.Errorf("expected answers %s but got %s", v.answers, got)
+			}
+			if got := describe(resp.Additionals); got != v.additionals {
+				t.Errorf("expected additionals %s but got %s", v.additionals, got)
+			}
+			if _, err := resp.Pack(); err != nil {
+				t.Errorf("response doesn't pack: %v", err)
+			}
+		})
+	}
+
+	resp, _ := r.answer(query("orders-abc123._http._tcp.local.", dnsmessage.TypeALL))
+	for _, rr := range resp.Answers {
+		switch b := rr.Body.(type) {
+		case *dnsmessage.SRVResource:
+			if b.Port != 8080 || b.Target.String() != "node1.local." {
+				t.Errorf("unexpected SRV %v", b)
+			}
+		case *dnsmessage.TXTResource:
+			if strings.Join(b.TXT, " ") != "id=abc123 version=1.2.0 region=eu" {
+				t.Errorf("unexpected TXT %v", b.TXT)
+			}
+		}
+	}
+
+	if err := r.Heartbeat(context.Background(), orders, errors.New("database down")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.answer(query("_http._tcp.local.", dnsmessage.TypePTR)); ok {
+		t.Error("expected an unhealthy instance to be withdrawn")
+	}
+}
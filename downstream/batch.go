@@ -0,0 +1,109 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrMissing = fmt.Errorf("batch returned no value for key")
+
+// BatchFunc loads the values for a batch of unique keys. Keys left out of the map get ErrMissing.
+type BatchFunc[K comparable, V any] func(context.Context, []K) (map[K]V, error)
+
+type batch[K comparable, V any] struct {
+	ctx    context.Context
+	keys   []K
+	seen   map[K]struct{}
+	timer  *time.Timer
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// Batcher collects the keys loaded during a window into one call, so many handlers looking up one record each
+// cost a single upstream request. Keys loaded twice in a window are fetched once.
+type Batcher[K comparable, V any] struct {
+	fn      BatchFunc[K, V]
+	window  time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+}
+
+// NewBatcher returns a Batcher calling fn with the keys loaded within window of the first one, or sooner once
+// maxSize keys are waiting. A maxSize of zero or less doesn't cap batches.
+func NewBatcher[K comparable, V any](window time.Duration, maxSize int, fn BatchFunc[K, V]) *Batcher[K, V] {
+	return &Batcher[K, V]{fn: fn, window: window, maxSize: maxSize}
+}
+
+// Load returns the value for key once its batch is loaded
+func (b *Batcher[K, V]) Load(ctx context.Context, key K) (V, error) {
+	b.mu.Lock()
+	p := b.pending
+	if p == nil {
+		// the batch runs on behalf of several callers, so it keeps the first one's values but not its cancellation
+		p = &batch[K, V]{ctx: context.WithoutCancel(ctx), seen: make(map[K]struct{}), done: make(chan struct{})}
+		p.timer = time.AfterFunc(b.window, func() { b.flush(p) })
+		b.pending = p
+	}
+	if _, ok := p.seen[key]; !ok {
+		p.seen[key] = struct{}{}
+		p.keys = append(p.keys, key)
+	}
+	full := b.maxSize > 0 && len(p.keys) >= b.maxSize
+	b.mu.Unlock()
+
+	if full && p.timer.Stop() {
+		go b.flush(p)
+	}
+
+	var zero V
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-p.done:
+	}
+
+	if p.err != nil {
+		return zero, p.err
+	}
+	v, ok := p.values[key]
+	if !ok {
+		return zero, fmt.Errorf("%w: %v", ErrMissing, key)
+	}
+
+	return v, nil
+}
+
+func (b *Batcher[K, V]) flush(p *batch[K, V]) {
+	b.mu.Lock()
+	if b.pending == p {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+
+	defer close(p.done)
+	defer func() {
+		if r := recover(); r != nil {
+			p.err = fmt.Errorf("batch panicked: %v", r)
+		}
+	}()
+	p.values, p.err = b.fn(p.ctx, p.keys)
+}
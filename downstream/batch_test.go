@@ -0,0 +1,93 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	b := NewBatcher(20*time.Millisecond, 3, func(ctx context.Context, ids []int) (map[int]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]int(nil), ids...)
+		sort.Ints(sorted)
+		batches = append(batches, sorted)
+
+		users := make(map[int]string)
+		for _, id := range ids {
+			if id != 404 {
+				users[id] = fmt.Sprintf("user %d", id)
+			}
+		}
+		return users, nil
+	})
+
+	tt := []struct {
+		name    string
+		ids     []int
+		batches [][]int
+	}{
+		{name: "window", ids: []int{1, 2, 1}, batches: [][]int{{1, 2}}},
+		{name: "max size", ids: []int{1, 2, 3, 4}, batches: [][]int{{1, 2, 3}, {4}}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			batches = nil
+			var wg sync.WaitGroup
+			for _, id := range v.ids {
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+					user, err := b.Load(context.Background(), id)
+					if err != nil || user != fmt.Sprintf("user %d", id) {
+						t.Errorf("expected user %d but got %q: %v", id, user, err)
+					}
+				}(id)
+				// keep the order of the keys so batches are predictable
+				time.Sleep(time.Millisecond)
+			}
+			wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			sort.Slice(batches, func(i, j int) bool { return len(batches[i]) > len(batches[j]) })
+			if !reflect.DeepEqual(batches, v.batches) {
+				t.Errorf("expected batches %v but got %v", v.batches, batches)
+			}
+		})
+	}
+
+	if _, err := b.Load(context.Background(), 404); !errors.Is(err, ErrMissing) {
+		t.Errorf("expected %v but got %v", ErrMissing, err)
+	}
+
+	failing := NewBatcher(time.Millisecond, 0, func(ctx context.Context, ids []int) (map[int]string, error) {
+		return nil, fmt.Errorf("upstream unavailable")
+	})
+	if _, err := failing.Load(context.Background(), 1); err == nil || err.Error() != "upstream unavailable" {
+		t.Errorf("expected the batch error but got %v", err)
+	}
+}
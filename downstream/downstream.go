@@ -0,0 +1,347 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package downstream wraps calls to third party APIs with per host rate limits, concurrency caps, request
+// coalescing, and batching windows so a busy service doesn't trip the limits of the APIs it calls
+package downstream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Limit is how hard a host may be called. Zero values are unlimited.
+type Limit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+	// Burst is how many requests may go at once after a quiet period. It defaults to the rate rounded up.
+	Burst int
+	// Concurrency is how many requests may be in flight, counting until the response body is closed
+	Concurrency int
+}
+
+// host holds the token bucket and semaphore for one host
+type host struct {
+	limit Limit
+	sem   chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newHost(l Limit, now time.Time) *host {
+	if l.Rate > 0 && l.Burst <= 0 {
+		l.Burst = int(math.Ceil(l.Rate))
+	}
+	h := &host{limit: l, tokens: float64(l.Burst), last: now}
+	if l.Concurrency > 0 {
+		h.sem = make(chan struct{}, l.Concurrency)
+	}
+
+	return h
+}
+
+// reserve takes a token and returns how long to wait before using it. The bucket goes negative so waiting
+// callers are served in order.
+func (h *host) reserve(now time.Time) time.Duration {
+	if h.limit.Rate <= 0 {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = math.Min(float64(h.limit.Burst), h.tokens+now.Sub(h.last).Seconds()*h.limit.Rate)
+	h.last = now
+	h.tokens--
+	if h.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-h.tokens / h.limit.Rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a caller that gave up waiting
+func (h *host) cancel() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens++
+}
+
+// wait blocks until the host can be called, returning a function releasing the concurrency slot
+func (h *host) wait(ctx context.Context, now time.Time) (func(), error) {
+	if d := h.reserve(now); d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			h.cancel()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	if h.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case h.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-h.sem }) }, nil
+}
+
+// call is a request shared by coalesced callers
+type call struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+	// canceled is set when the call failed because the request that made it was canceled
+	canceled bool
+}
+
+// TransportOpt is a functional option to modify the Transport
+type TransportOpt func(*Transport)
+
+// Transport is an http.RoundTripper that applies per host limits before calling the next round tripper
+type Transport struct {
+	next     http.RoundTripper
+	limits   map[string]Limit
+	def      Limit
+	coalesce bool
+	now      func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*host
+	calls map[string]*call
+
+	wait      *prometheus.HistogramVec
+	inFlight  *prometheus.GaugeVec
+	coalesced *prometheus.CounterVec
+}
+
+// SetNext sets the round tripper making the calls. The default is http.DefaultTransport.
+func SetNext(rt http.RoundTripper) TransportOpt {
+	return func(t *Transport) {
+		t.next = rt
+	}
+}
+
+// SetHostLimit sets the limit for a host, matched against the request URL's host including any port
+func SetHostLimit(host string, l Limit) TransportOpt {
+	return func(t *Transport) {
+		t.limits[host] = l
+	}
+}
+
+// SetDefaultLimit sets the limit for hosts without their own. Each host gets a separate budget.
+func SetDefaultLimit(l Limit) TransportOpt {
+	return func(t *Transport) {
+		t.def = l
+	}
+}
+
+// SetCoalescing shares one upstream call between identical GET and HEAD requests in flight at the same time.
+// Requests are identical when their method, URL, and headers match.
+func SetCoalescing(b bool) TransportOpt {
+	return func(t *Transport) {
+		t.coalesce = b
+	}
+}
+
+// NewTransport returns a Transport without limits until they are set
+func NewTransport(opts ...TransportOpt) *Transport {
+	t := &Transport{
+		next:      http.DefaultTransport,
+		limits:    make(map[string]Limit),
+		now:       time.Now,
+		hosts:     make(map[string]*host),
+		calls:     make(map[string]*call),
+		wait:      metrics.NewHistogramVec("downstream_wait_seconds", "Time requests waited for a host's rate limit and concurrency cap", []string{"host"}),
+		coalesced: metrics.NewCounterVec("downstream_coalesced", "Requests served by an identical request already in flight", []string{"host"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "downstream_in_flight",
+			Help: "Requests in flight by host",
+		}, []string{"host"}),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Client returns an http.Client using the transport
+func (t *Transport) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: t, Timeout: timeout}
+}
+
+// Metrics returns the transport's collectors to register with the exporter
+func (t *Transport) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{t.wait, t.inFlight, t.coalesced}
+}
+
+func (t *Transport) host(name string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[name]
+	if !ok {
+		l, ok := t.limits[name]
+		if !ok {
+			l = t.def
+		}
+		h = newHost(l, t.now())
+		t.hosts[name] = h
+	}
+
+	return h
+}
+
+// RoundTrip waits for the host's limits and calls the next round tripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.coalesce && (req.Method == http.MethodGet || req.Method == http.MethodHead) && (req.Body == nil || req.Body == http.NoBody) {
+		return t.shared(req)
+	}
+
+	return t.roundTrip(req)
+}
+
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	name := req.URL.Host
+	h := t.host(name)
+
+	start := t.now()
+	release, err := h.wait(req.Context(), start)
+	if err != nil {
+		return nil, err
+	}
+	t.wait.WithLabelValues(name).Observe(t.now().Sub(start).Seconds())
+
+	t.inFlight.WithLabelValues(name).Inc()
+	done := func() {
+		release()
+		t.inFlight.WithLabelValues(name).Dec()
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: sync.OnceFunc(done)}
+
+	return resp, nil
+}
+
+// releaseBody frees the concurrency slot when the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// shared makes the request or waits for an identical one in flight, buffering the body for every caller. When
+// the caller that made the shared call is canceled, the others make the call again instead of failing with it.
+func (t *Transport) shared(req *http.Request) (*http.Response, error) {
+	key := coalesceKey(req)
+
+	for {
+		t.mu.Lock()
+		c, ok := t.calls[key]
+		if !ok {
+			c = &call{done: make(chan struct{})}
+			t.calls[key] = c
+		}
+		t.mu.Unlock()
+
+		if !ok {
+			return t.lead(req, key, c)
+		}
+
+		t.coalesced.WithLabelValues(req.URL.Host).Inc()
+		select {
+		case <-c.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if !c.canceled {
+			return c.response(req)
+		}
+	}
+}
+
+// lead makes the shared call for key and returns its response
+func (t *Transport) lead(req *http.Request, key string, c *call) (*http.Response, error) {
+
+	func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.calls, key)
+			t.mu.Unlock()
+			close(c.done)
+		}()
+
+		defer func() {
+			c.canceled = c.err != nil && req.Context().Err() != nil
+		}()
+
+		c.resp, c.err = t.roundTrip(req)
+		if c.err != nil {
+			return
+		}
+		defer c.resp.Body.Close()
+		c.body, c.err = io.ReadAll(c.resp.Body)
+	}()
+
+	return c.response(req)
+}
+
+// response returns a copy of the shared response with its own body
+func (c *call) response(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+
+	return &resp, nil
+}
+
+func coalesceKey(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method + " " + req.URL.String() + "\n")
+	req.Header.Write(&b)
+	return b.String()
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+// fakeTransport counts calls and how many were in flight at once, blocking each until release is closed
+type fakeTransport struct {
+	calls   atomic.Int32
+	current atomic.Int32
+	peak    atomic.Int32
+	release chan struct{}
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls.Add(1)
+	n := f.current.Add(1)
+	defer f.current.Add(-1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	if f.release != nil {
+		<-f.release
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id": 1}`)),
+		Request:    req,
+	}, nil
+}
+
+func get(t *testing.T, c *http.Client, url string) string {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Error(err)
+	}
+	return string(body)
+}
+
+func TestRateLimit(t *testing.T) {
+	tr := NewTransport(
+		SetNext(&fakeTransport{}),
+		SetHostLimit("slow.example.com", Limit{Rate: 100, Burst: 1}),
+	)
+	c := tr.Client(time.Second)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		get(t, c, "http://slow.example.com/posts")
+	}
+	if d := time.Since(start); d < 35*time.Millisecond {
+		t.Errorf("expected 5 requests at 100/s with a burst of 1 to take at least 40ms but took %s", d)
+	}
+
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		get(t, c, "http://fast.example.com/posts")
+	}
+	if d := time.Since(start); d > 20*time.Millisecond {
+		t.Errorf("expected a host without a limit not to wait but took %s", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "http://slow.example.com/posts", nil).WithContext(ctx)
+	req.RequestURI = ""
+	if _, err := c.Do(req); err == nil {
+		t.Error("expected a cancelled request waiting for the limit to fail")
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	fake := &fakeTransport{release: make(chan struct{})}
+	tr := NewTransport(SetNext(fake), SetDefaultLimit(Limit{Concurrency: 2}))
+	reg := sdtest.NewRegistry(t, tr.Metrics()...)
+	c := tr.Client(time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			get(t, c, fmt.Sprintf("http://api.example.com/posts/%d", i))
+		}(i)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for fake.current.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	reg.AssertGauge(t, "downstream_in_flight", map[string]string{"host": "api.example.com"}, 2)
+	close(fake.release)
+	wg.Wait()
+
+	if p := fake.peak.Load(); p != 2 {
+		t.Errorf("expected at most 2 requests in flight but got %d", p)
+	}
+	reg.AssertGauge(t, "downstream_in_flight", map[string]string{"host": "api.example.com"}, 0)
+	reg.AssertHistogramCount(t, "downstream_wait_seconds", map[string]string{"host": "api.example.com"}, 6)
+}
+
+func TestCoalescing(t *testing.T) {
+	fake := &fakeTransport{release: make(chan struct{})}
+	tr := NewTransport(SetNext(fake), SetCoalescing(true))
+	reg := sdtest.NewRegistry(t, tr.Metrics()...)
+	c := tr.Client(time.Second)
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = get(t, c, "http://api.example.com/posts/1")
+		}(i)
+	}
+
+	// the first request blocks upstream until the others have joined it
+	host := map[string]string{"host": "api.example.com"}
+	deadline := time.Now().Add(time.Second)
+	for reg.CounterValue(t, "downstream_coalesced", host) < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(fake.release)
+	wg.Wait()
+
+	if n := fake.calls.Load(); n != 1 {
+		t.Errorf("expected one upstream call but got %d", n)
+	}
+	for _, b := range bodies {
+		if b != `{"id": 1}` {
+			t.Errorf("expected every caller to get the body but got %q", b)
+		}
+	}
+	reg.AssertCounter(t, "downstream_coalesced", host, 4)
+
+	// a request after the first finished calls upstream again
+	get(t, c, "http://api.example.com/posts/1")
+	if n := fake.calls.Load(); n != 2 {
+		t.Errorf("expected a second upstream call but got %d", n)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCoalescingLeaderCanceled(t *testing.T) {
+	var calls atomic.Int32
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// the first call blocks until its caller gives up
+		if calls.Add(1) == 1 {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	})
+	tr := NewTransport(SetNext(next), SetCoalescing(true))
+	reg := sdtest.NewRegistry(t, tr.Metrics()...)
+	c := tr.Client(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.example.com/posts/1", nil)
+		_, err := c.Do(req)
+		leader <- err
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	follower := make(chan string, 1)
+	go func() { follower <- get(t, c, "http://api.example.com/posts/1") }()
+	host := map[string]string{"host": "api.example.com"}
+	deadline := time.Now().Add(time.Second)
+	for reg.CounterValue(t, "downstream_coalesced", host) < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-leader; err == nil {
+		t.Error("expected the canceled request to fail")
+	}
+	if body := <-follower; body != "ok" {
+		t.Errorf("expected the follower to make the call again but got %q", body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected two upstream calls but got %d", n)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package env binds environment variables to a struct using field tags, and lists every variable a service
+// reads so the list can be published as ENVIRONMENT.md or from an admin route.
+//
+//	type Config struct {
+//		NATSURLs []string      `env:"NATS_URLS,required" help:"NATS servers, comma separated"`
+//		Timeout  time.Duration `env:"TIMEOUT" default:"5s"`
+//		Token    string        `env:"API_TOKEN,secret"`
+//	}
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+)
+
+var (
+	ErrNotStruct       = fmt.Errorf("env: bind a pointer to a struct")
+	ErrMissingVariable = fmt.Errorf("missing required environment variable")
+	ErrInvalidVariable = fmt.Errorf("invalid environment variable")
+	ErrUnsupportedType = fmt.Errorf("unsupported environment variable type")
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Variable describes an environment variable read into a struct field
+type Variable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Set and Value are filled in by Bind. Secret values are redacted.
+	Set   bool   `json:"set"`
+	Value string `json:"value,omitempty"`
+}
+
+// BindOpt is a functional option to modify Bind and Describe
+type BindOpt func(*binder)
+
+type binder struct {
+	prefix string
+	lookup func(string) (string, bool)
+}
+
+// SetPrefix sets a prefix added to every variable name, such as ORDERS_
+func SetPrefix(p string) BindOpt {
+	return func(b *binder) {
+		b.prefix = p
+	}
+}
+
+// SetLookup sets the function reading variables. The default is os.LookupEnv.
+func SetLookup(f func(string) (string, bool)) BindOpt {
+	return func(b *binder) {
+		b.lookup = f
+	}
+}
+
+// field is a tagged struct field and the variable it reads. def keeps the default when v.Default is redacted.
+type field struct {
+	value reflect.Value
+	v     Variable
+	def   string
+}
+
+// Bind sets the fields of the struct dst points to from the environment. Fields are read from the variable
+// named in their env tag, which can add the required and secret options. The default tag is used when the
+// variable isn't set, and nested structs tagged with envPrefix add that prefix to their fields' names. Every
+// missing or invalid variable is reported, not just the first. Bind returns the variables it read.
+func Bind(dst any, opts ...BindOpt) ([]Variable, error) {
+	b := newBinder(opts)
+	fields, err := b.fields(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	vars := make([]Variable, len(fields))
+	for i, f := range fields {
+		raw, ok := b.lookup(f.v.Name)
+		f.v.Set = ok
+		switch {
+		case ok:
+		case f.def != "":
+			raw = f.def
+		case f.v.Required:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingVariable, f.v.Name))
+			vars[i] = f.v
+			continue
+		default:
+			vars[i] = f.v
+			continue
+		}
+
+		if err := set(f.value, raw); err != nil {
+			// secret values stay out of the error
+			if f.v.Secret {
+				err = fmt.Errorf("expected %s", f.v.Type)
+			}
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalidVariable, f.v.Name, err))
+		}
+		f.v.Value = raw
+		if f.v.Secret {
+			f.v.Value = codec.RedactedValue
+		}
+		vars[i] = f.v
+	}
+
+	return vars, errors.Join(errs...)
+}
+
+// Describe returns the variables Bind reads into the struct v points to, without reading them. Defaults of secret
+// variables are redacted.
+func Describe(v any, opts ...BindOpt) ([]Variable, error) {
+	fields, err := newBinder(opts).fields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]Variable, len(fields))
+	for i, f := range fields {
+		vars[i] = f.v
+	}
+	return vars, nil
+}
+
+func newBinder(opts []BindOpt) *binder {
+	b := &binder{lookup: os.LookupEnv}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *binder) fields(dst any) ([]field, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	return b.walk(rv.Elem(), b.prefix)
+}
+
+func (b *binder) walk(rv reflect.Value, prefix string) ([]field, error) {
+	var fields []field
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("env")
+		if !ok {
+			if p, nested := sf.Tag.Lookup("envPrefix"); nested && sf.Type.Kind() == reflect.Struct {
+				children, err := b.walk(rv.Field(i), prefix+p)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, children...)
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		v := Variable{
+			Name:        prefix + name,
+			Type:        typeName(sf.Type),
+			Default:     sf.Tag.Get("default"),
+			Description: sf.Tag.Get("help"),
+		}
+		for _, o := range strings.Split(options, ",") {
+			switch o {
+			case "required":
+				v.Required = true
+			case "secret":
+				v.Secret = true
+			}
+		}
+		if v.Type == "" {
+			return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedType, sf.Name, sf.Type)
+		}
+		def := v.Default
+		if v.Secret && v.Default != "" {
+			v.Default = codec.RedactedValue
+		}
+		fields = append(fields, field{value: rv.Field(i), v: v, def: def})
+	}
+	return fields, nil
+}
+
+// typeName returns how a type is described in the docs, or an empty string if Bind can't set it
+func typeName(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "string"
+	}
+	if t == durationType {
+		return "duration"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if elem := typeName(t.Elem()); elem != "" {
+			return "list of " + elem
+		}
+	}
+	return ""
+}
+
+// set parses raw into the field. Lists are comma separated.
+func set(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		var parts []string
+		if raw != "" {
+			parts = strings.Split(raw, ",")
+		}
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := set(s.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	}
+	return nil
+}
+
+// WriteMarkdown writes the variables as an ENVIRONMENT.md table
+func WriteMarkdown(w io.Writer, title string, vars []Variable) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	sb.WriteString("| Variable | Type | Default | Required | Secret | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	yes := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return ""
+	}
+	for _, v := range vars {
+		def := v.Default
+		switch {
+		case v.Secret && def != "":
+			def = "hidden"
+		case def != "":
+			def = "`" + def + "`"
+		}
+		desc := strings.ReplaceAll(v.Description, "|", `\|`)
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s | %s |\n", v.Name, v.Type, def, yes(v.Required), yes(v.Secret), desc)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
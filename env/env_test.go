@@ -0,0 +1,162 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+)
+
+type natsConfig struct {
+	URLs []string `env:"URLS,required" help:"NATS servers, comma separated"`
+	Seed string   `env:"SEED,secret"`
+}
+
+type testConfig struct {
+	Port    int           `env:"PORT" default:"8080" help:"HTTP port"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Ratio   float64       `env:"RATIO"`
+	Bind    net.IP        `env:"BIND"`
+	NATS    natsConfig    `envPrefix:"NATS_"`
+	ignored string
+}
+
+func lookup(vars map[string]string) BindOpt {
+	return SetLookup(func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	})
+}
+
+func TestBind(t *testing.T) {
+	tt := []struct {
+		name string
+		env  map[string]string
+		want testConfig
+		errs []error
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"ORDERS_NATS_URLS": "nats://a:4222, nats://b:4222"},
+			want: testConfig{Port: 8080, Timeout: 5 * time.Second, NATS: natsConfig{URLs: []string{"nats://a:4222", "nats://b:4222"}}},
+		},
+		{
+			name: "set",
+			env:  map[string]string{"ORDERS_PORT": "9090", "ORDERS_DEBUG": "true", "ORDERS_TIMEOUT": "1m", "ORDERS_RATIO": "0.5", "ORDERS_BIND": "10.0.0.1", "ORDERS_NATS_URLS": "nats://a:4222", "ORDERS_NATS_SEED": "SUAB"},
+			want: testConfig{Port: 9090, Debug: true, Timeout: time.Minute, Ratio: 0.5, Bind: net.ParseIP("10.0.0.1"), NATS: natsConfig{URLs: []string{"nats://a:4222"}, Seed: "SUAB"}},
+		},
+		{
+			name: "every problem",
+			env:  map[string]string{"ORDERS_PORT": "eighty", "ORDERS_TIMEOUT": "5"},
+			errs: []error{ErrInvalidVariable, ErrMissingVariable},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var cfg testConfig
+			_, err := Bind(&cfg, SetPrefix("ORDERS_"), lookup(v.env))
+			for _, e := range v.errs {
+				if !errors.Is(err, e) {
+					t.Errorf("expected %v in %v", e, err)
+				}
+			}
+			if v.errs != nil {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, v.want) {
+				t.Errorf("expected %+v but got %+v", v.want, cfg)
+			}
+		})
+	}
+}
+
+func TestBindVariables(t *testing.T) {
+	var cfg testConfig
+	vars, err := Bind(&cfg, lookup(map[string]string{"NATS_URLS": "nats://a:4222", "NATS_SEED": "SUAB"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]Variable{}
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+	if seed := byName["NATS_SEED"]; !seed.Set || !seed.Secret || seed.Value != codec.RedactedValue {
+		t.Errorf("expected the seed to be set and redacted but got %+v", seed)
+	}
+	if port := byName["PORT"]; port.Set || port.Value != "8080" || port.Type != "integer" {
+		t.Errorf("expected the port default but got %+v", port)
+	}
+	if _, err := Bind(cfg); !errors.Is(err, ErrNotStruct) {
+		t.Errorf("expected %v but got %v", ErrNotStruct, err)
+	}
+
+	var secret struct {
+		Token string `env:"TOKEN,secret" default:"s3cret"`
+	}
+	vars, err = Describe(&secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars[0].Default != codec.RedactedValue {
+		t.Errorf("expected the secret default to be redacted but got %+v", vars[0])
+	}
+	if _, err := Bind(&secret, lookup(nil)); err != nil || secret.Token != "s3cret" {
+		t.Errorf("expected the secret default to be bound but got %q: %v", secret.Token, err)
+	}
+
+	var bad struct {
+		C chan int `env:"C"`
+	}
+	if _, err := Describe(&bad); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected %v but got %v", ErrUnsupportedType, err)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	vars, err := Describe(&testConfig{}, SetPrefix("ORDERS_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, "Orders Environment", vars); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Orders Environment\n\n" +
+		"| Variable | Type | Default | Required | Secret | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `ORDERS_PORT` | integer | `8080` |  |  | HTTP port |\n" +
+		"| `ORDERS_DEBUG` | bool |  |  |  |  |\n" +
+		"| `ORDERS_TIMEOUT` | duration | `5s` |  |  |  |\n" +
+		"| `ORDERS_RATIO` | number |  |  |  |  |\n" +
+		"| `ORDERS_BIND` | string |  |  |  |  |\n" +
+		"| `ORDERS_NATS_URLS` | list of string |  | yes |  | NATS servers, comma separated |\n" +
+		"| `ORDERS_NATS_SEED` | string |  |  | yes |  |\n"
+	if b.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, b.String())
+	}
+}
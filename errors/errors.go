@@ -15,7 +15,11 @@
 package errors
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -33,12 +37,83 @@ type ClientError struct {
 
 type ClientErrorOpt func(*ClientError)
 
+// Localizer is implemented by errors that can return their message in the caller's language
+type Localizer interface {
+	Localize(acceptLanguage string) string
+}
+
 func (c ClientError) Error() string {
 	return strings.Join(c.Details, ", ")
 }
 
+// Messages returns the details as plain strings. Details created by NewClientError are quoted, details set
+// directly are returned as they are.
+func (c ClientError) Messages() []string {
+	messages := make([]string, len(c.Details))
+	for i, v := range c.Details {
+		m, err := strconv.Unquote(v)
+		if err != nil {
+			m = v
+		}
+		messages[i] = m
+	}
+
+	return messages
+}
+
+// localized returns the messages with errors implementing Localizer translated for the Accept-Language value
+func (c ClientError) localized(acceptLanguage string) []string {
+	messages := c.Messages()
+	for i, err := range c.DetailedErrors {
+		var l Localizer
+		if i < len(messages) && errors.As(err, &l) {
+			messages[i] = l.Localize(acceptLanguage)
+		}
+	}
+
+	return messages
+}
+
+// Body returns the details as a JSON object with an errors array
 func (c ClientError) Body() []byte {
-	return []byte(fmt.Sprintf(`{"errors": [%s]}`, strings.Join(c.Details, ",")))
+	return jsonBody(c.Messages())
+}
+
+// LocalizedBody is like Body but details from errors implementing Localizer are returned in the best language
+// for the Accept-Language header value
+func (c ClientError) LocalizedBody(acceptLanguage string) []byte {
+	return jsonBody(c.localized(acceptLanguage))
+}
+
+// jsonBody encodes each message as a JSON string in the same {"errors": [...]} layout used across the library
+func jsonBody(messages []string) []byte {
+	quoted := make([]string, len(messages))
+	for i, v := range messages {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		// encoding a string can't fail
+		enc.Encode(v)
+		quoted[i] = strings.TrimSuffix(b.String(), "\n")
+	}
+
+	return []byte(fmt.Sprintf(`{"errors": [%s]}`, strings.Join(quoted, ",")))
+}
+
+// xmlErrors is the XML form of the error body
+type xmlErrors struct {
+	XMLName xml.Name `xml:"errors"`
+	Errors  []string `xml:"error"`
+}
+
+// XMLBody is like LocalizedBody but returns the errors as XML for clients that accept application/xml
+func (c ClientError) XMLBody(acceptLanguage string) []byte {
+	data, err := xml.Marshal(xmlErrors{Errors: c.localized(acceptLanguage)})
+	if err != nil {
+		return []byte("<errors/>")
+	}
+
+	return append([]byte(xml.Header), data...)
 }
 
 func (c ClientError) Code() int {
@@ -0,0 +1,96 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBody(t *testing.T) {
+	tt := []struct {
+		name string
+		err  ClientError
+		want string
+	}{
+		{name: "single", err: NewClientError(fmt.Errorf("not found"), 404), want: `{"errors": ["not found"]}`},
+		{name: "quotes", err: NewClientError(fmt.Errorf(`field "name" is required`), 400), want: `{"errors": ["field \"name\" is required"]}`},
+		{name: "html", err: NewClientError(fmt.Errorf("a < b & c"), 400), want: `{"errors": ["a < b & c"]}`},
+		{name: "multiple", err: MultipleClientErrors([]error{fmt.Errorf("a"), fmt.Errorf("b")}, 400), want: `{"errors": ["a","b"]}`},
+		{name: "unquoted details", err: ClientError{Status: 400, Details: []string{`bad "input"`}}, want: `{"errors": ["bad \"input\""]}`},
+		{name: "control characters", err: NewClientError(fmt.Errorf("line\x00\t"), 400), want: `{"errors": ["line\u0000\t"]}`},
+		{name: "empty", err: ClientError{Status: 400}, want: `{"errors": []}`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := string(v.err.Body()); got != v.want {
+				t.Errorf("expected %s but got %s", v.want, got)
+			}
+		})
+	}
+}
+
+type localizedErr struct{}
+
+func (localizedErr) Error() string { return "not found" }
+func (localizedErr) Localize(acceptLanguage string) string {
+	return `"` + acceptLanguage + `" no encontrado`
+}
+
+func TestLocalizedBody(t *testing.T) {
+	ce := MultipleClientErrors([]error{localizedErr{}, fmt.Errorf("plain")}, 404)
+
+	want := `{"errors": ["\"es\" no encontrado","plain"]}`
+	if got := string(ce.LocalizedBody("es")); got != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+}
+
+func FuzzClientErrorBody(f *testing.F) {
+	for _, v := range []string{"not found", `say "hi"`, `back\slash`, "<tag>&amp;", "\x00\xff", "multi\nline", "ü日本"} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, msg string) {
+		ce := NewClientError(fmt.Errorf("%s", msg), 400)
+
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+		if err := json.Unmarshal(ce.Body(), &body); err != nil {
+			t.Fatalf("invalid JSON body %q: %v", ce.Body(), err)
+		}
+		// invalid UTF-8 is replaced when marshaling so only valid strings survive exactly
+		if utf8.ValidString(msg) && !reflect.DeepEqual(body.Errors, []string{msg}) {
+			t.Errorf("expected %q but got %q", msg, body.Errors)
+		}
+
+		if err := json.Unmarshal(ce.LocalizedBody("en"), &body); err != nil {
+			t.Fatalf("invalid localized JSON body: %v", err)
+		}
+
+		var x xmlErrors
+		data := ce.XMLBody("en")
+		if err := xml.Unmarshal([]byte(strings.TrimPrefix(string(data), xml.Header)), &x); err != nil {
+			t.Fatalf("invalid XML body %q: %v", data, err)
+		}
+	})
+}
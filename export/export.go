@@ -0,0 +1,464 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export streams large query results over HTTP as CSV or Parquet. Rows are written as the query produces
+// them, so a slow client slows the query down instead of the results piling up in memory.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Type is the type of a column's values
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+)
+
+// Formats an export can be written in
+const (
+	CSV     = "csv"
+	Parquet = "parquet"
+)
+
+const (
+	CSVContentType     = "text/csv"
+	ParquetContentType = "application/vnd.apache.parquet"
+)
+
+var (
+	ErrUnknownColumn = fmt.Errorf("unknown column")
+	ErrUnknownFormat = fmt.Errorf("unknown export format")
+	ErrColumnType    = fmt.Errorf("value doesn't match the column type")
+)
+
+// Column is a column of the export. Value returns the column's value for a row, or nil for an empty value.
+type Column[T any] struct {
+	Name  string
+	Type  Type
+	Value func(T) any
+}
+
+type column struct {
+	name string
+	typ  Type
+}
+
+// rowWriter writes rows in one format
+type rowWriter interface {
+	WriteRow(values []any) error
+	Flush() error
+	Close() error
+}
+
+// ExporterOpt is a functional option to modify an Exporter
+type ExporterOpt func(*config)
+
+type config struct {
+	flushRows    int
+	rowGroupSize int
+	format       string
+	logger       *slog.Logger
+}
+
+// SetFlushRows sets how many CSV rows are written between flushes to the client. Values below one are ignored.
+func SetFlushRows(n int) ExporterOpt {
+	return func(c *config) {
+		if n > 0 {
+			c.flushRows = n
+		}
+	}
+}
+
+// SetRowGroupSize sets how many rows are buffered for each Parquet row group. Values below one are ignored.
+func SetRowGroupSize(n int) ExporterOpt {
+	return func(c *config) {
+		if n > 0 {
+			c.rowGroupSize = n
+		}
+	}
+}
+
+// SetDefaultFormat sets the format used when the request doesn't ask for one. Unknown formats are ignored.
+func SetDefaultFormat(f string) ExporterOpt {
+	return func(c *config) {
+		if f == CSV || f == Parquet {
+			c.format = f
+		}
+	}
+}
+
+// SetLogger sets the logger used to report exports that fail after they started
+func SetLogger(l *slog.Logger) ExporterOpt {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// Exporter writes rows of type T
+type Exporter[T any] struct {
+	name    string
+	columns []Column[T]
+	cfg     config
+
+	rows     *prometheus.CounterVec
+	bytes    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	active   *prometheus.GaugeVec
+}
+
+// NewExporter returns an Exporter named name, which is used for the file name and as the namespace of its metrics
+func NewExporter[T any](name string, columns []Column[T], opts ...ExporterOpt) *Exporter[T] {
+	ns := metricNamespace(name)
+	e := &Exporter[T]{
+		name:    name,
+		columns: columns,
+		cfg: config{
+			flushRows:    100,
+			rowGroupSize: 10000,
+			format:       CSV,
+			logger:       slog.Default(),
+		},
+		rows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "export_rows",
+			Help:      "Rows written by format",
+		}, []string{"format"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "export_bytes",
+			Help:      "Bytes written by format",
+		}, []string{"format"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "export_duration_seconds",
+			Help:      "Export duration by format and outcome",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8),
+		}, []string{"format", "outcome"}),
+		active: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "exports_in_progress",
+			Help:      "Exports being written by format",
+		}, []string{"format"}),
+	}
+
+	for _, opt := range opts {
+		opt(&e.cfg)
+	}
+
+	return e
+}
+
+// Metrics returns the exporter's collectors. Append them to the server's Exporter to serve them on /metrics. Their
+// names start with the exporter's name, so every exporter can register its own.
+func (e *Exporter[T]) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{e.rows, e.bytes, e.duration, e.active}
+}
+
+// metricNamespace turns an exporter name into a valid metric namespace, like orders-2025 into orders_2025
+func metricNamespace(name string) string {
+	ns := []byte(name)
+	for i, c := range ns {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			ns[i] = '_'
+		}
+	}
+	return string(ns)
+}
+
+// QueryFunc runs the query for a request and calls emit with each row. emit blocks while the client catches up
+// and returns an error once the export can't continue, which the query should return.
+type QueryFunc[T any] func(ctx context.Context, r *http.Request, emit func(T) error) error
+
+// Handler returns a handler that exports the rows produced by query. The format comes from the format query
+// parameter or the Accept header, and the columns query parameter selects and orders columns, like
+// ?format=parquet&columns=id,total. Errors after the first row has been written abort the response so a
+// truncated export can't be mistaken for a complete one.
+func (e *Exporter[T]) Handler(query QueryFunc[T]) http.Handler {
+	return &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		format, err := e.format(r)
+		if err != nil {
+			return sderrors.NewClientError(err, http.StatusNotAcceptable)
+		}
+		selected, err := e.selectColumns(r.URL.Query().Get("columns"))
+		if err != nil {
+			return sderrors.NewClientError(err, http.StatusBadRequest)
+		}
+
+		return e.export(w, r, format, selected, query)
+	}}
+}
+
+func (e *Exporter[T]) export(w http.ResponseWriter, r *http.Request, format string, selected []Column[T], query QueryFunc[T]) error {
+	start := time.Now()
+	e.active.WithLabelValues(format).Inc()
+	defer e.active.WithLabelValues(format).Dec()
+
+	cw := &countingWriter{w: w}
+	flusher, _ := w.(http.Flusher)
+	cols := make([]column, len(selected))
+	for i, c := range selected {
+		cols[i] = column{name: c.Name, typ: c.Type}
+	}
+
+	var rw rowWriter
+	switch format {
+	case Parquet:
+		w.Header().Set("Content-Type", ParquetContentType)
+		rw = newParquetWriter(cw, cols, e.cfg.rowGroupSize)
+	default:
+		w.Header().Set("Content-Type", CSVContentType+"; charset=utf-8")
+		rw = newCSVWriter(cw, cols)
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.name + "." + format}))
+
+	var written int
+	emit := func(row T) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+
+		values := make([]any, len(selected))
+		for i, c := range selected {
+			v, err := normalize(c.Type, c.Value(row))
+			if err != nil {
+				return fmt.Errorf("column %s: %w", c.Name, err)
+			}
+			values[i] = v
+		}
+		if err := rw.WriteRow(values); err != nil {
+			return err
+		}
+
+		written++
+		e.rows.WithLabelValues(format).Inc()
+		if format == CSV && written%e.cfg.flushRows == 0 {
+			if err := rw.Flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		return nil
+	}
+
+	err := query(r.Context(), r, emit)
+	if err == nil {
+		err = rw.Close()
+	}
+	e.bytes.WithLabelValues(format).Add(float64(cw.n))
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	e.duration.WithLabelValues(format, outcome).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		return nil
+	}
+	if cw.n == 0 && written == 0 {
+		return err
+	}
+
+	e.cfg.logger.Error(fmt.Sprintf("export %s failed after %d rows: %v", e.name, written, err))
+	panic(http.ErrAbortHandler)
+}
+
+// format picks the format from the format query parameter, then the Accept header
+func (e *Exporter[T]) format(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		if f != CSV && f != Parquet {
+			return "", fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+		}
+		return f, nil
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case CSVContentType:
+			return CSV, nil
+		case ParquetContentType:
+			return Parquet, nil
+		}
+	}
+
+	return e.cfg.format, nil
+}
+
+// selectColumns returns the named columns in the requested order, or every column if names is empty
+func (e *Exporter[T]) selectColumns(names string) ([]Column[T], error) {
+	if names == "" {
+		return e.columns, nil
+	}
+
+	var selected []Column[T]
+	var errs []error
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(e.columns, func(c Column[T]) bool { return c.Name == name })
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownColumn, name))
+			continue
+		}
+		selected = append(selected, e.columns[i])
+	}
+
+	return selected, errors.Join(errs...)
+}
+
+// normalize converts v to the Go type written for the column type
+func normalize(t Type, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t {
+	case String:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case fmt.Stringer:
+			return x.String(), nil
+		}
+	case Int:
+		switch x := v.(type) {
+		case int:
+			return int64(x), nil
+		case int32:
+			return int64(x), nil
+		case int64:
+			return x, nil
+		case uint32:
+			return int64(x), nil
+		}
+	case Float:
+		switch x := v.(type) {
+		case float32:
+			return float64(x), nil
+		case float64:
+			return x, nil
+		}
+	case Bool:
+		if x, ok := v.(bool); ok {
+			return x, nil
+		}
+	case Time:
+		if x, ok := v.(time.Time); ok {
+			return x.UTC(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrColumnType, v)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// csvWriter writes a header row followed by the rows
+type csvWriter struct {
+	w       *csv.Writer
+	columns []column
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []column) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (c *csvWriter) header() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+
+	names := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		names[i] = col.name
+	}
+
+	return c.w.Write(names)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch x := v.(type) {
+		case nil:
+		case string:
+			record[i] = x
+		case int64:
+			record[i] = strconv.FormatInt(x, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(x, 'f', -1, 64)
+		case bool:
+			record[i] = strconv.FormatBool(x)
+		case time.Time:
+			record[i] = x.Format(time.RFC3339Nano)
+		}
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	return c.Flush()
+}
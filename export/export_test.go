@@ -0,0 +1,169 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+type order struct {
+	ID      string
+	Qty     int
+	Total   float64
+	Note    *string
+	Created time.Time
+}
+
+var orderColumns = []Column[order]{
+	{Name: "id", Type: String, Value: func(o order) any { return o.ID }},
+	{Name: "qty", Type: Int, Value: func(o order) any { return o.Qty }},
+	{Name: "total", Type: Float, Value: func(o order) any { return o.Total }},
+	{Name: "note", Type: String, Value: func(o order) any {
+		if o.Note == nil {
+			return nil
+		}
+		return *o.Note
+	}},
+	{Name: "created", Type: Time, Value: func(o order) any { return o.Created }},
+}
+
+func orders(n int, fail error) QueryFunc[order] {
+	note := "gift, wrapped"
+	return func(ctx context.Context, r *http.Request, emit func(order) error) error {
+		for i := 0; i < n; i++ {
+			o := order{ID: fmt.Sprintf("o-%d", i), Qty: i, Total: float64(i) + 0.25, Created: time.Date(2025, 3, 1, 0, i, 0, 0, time.UTC)}
+			if i == 1 {
+				o.Note = &note
+			}
+			if err := emit(o); err != nil {
+				return err
+			}
+		}
+		return fail
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tt := []struct {
+		name   string
+		url    string
+		accept string
+		code   int
+		ctype  string
+		body   string
+	}{
+		{
+			name:  "csv",
+			url:   "/orders",
+			code:  http.StatusOK,
+			ctype: "text/csv; charset=utf-8",
+			body:  "id,qty,total,note,created\no-0,0,0.25,,2025-03-01T00:00:00Z\no-1,1,1.25,\"gift, wrapped\",2025-03-01T00:01:00Z\n",
+		},
+		{
+			name:  "columns",
+			url:   "/orders?columns=total,id",
+			code:  http.StatusOK,
+			ctype: "text/csv; charset=utf-8",
+			body:  "total,id\n0.25,o-0\n1.25,o-1\n",
+		},
+		{name: "unknown column", url: "/orders?columns=id,secret", code: http.StatusBadRequest},
+		{name: "unknown format", url: "/orders?format=xlsx", code: http.StatusNotAcceptable},
+		{name: "accept parquet", url: "/orders", accept: "application/vnd.apache.parquet", code: http.StatusOK, ctype: ParquetContentType},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			e := NewExporter("orders", orderColumns, SetFlushRows(1))
+			reg := sdtest.NewRegistry(t, e.Metrics()...)
+
+			req := httptest.NewRequest(http.MethodGet, v.url, nil)
+			req.Header.Set("Accept", v.accept)
+			rr := httptest.NewRecorder()
+			e.Handler(orders(2, nil)).ServeHTTP(rr, req)
+
+			if rr.Code != v.code {
+				t.Fatalf("expected %d but got %d: %s", v.code, rr.Code, rr.Body.String())
+			}
+			if v.code != http.StatusOK {
+				return
+			}
+			if got := rr.Header().Get("Content-Type"); got != v.ctype {
+				t.Errorf("expected content type %s but got %s", v.ctype, got)
+			}
+			if v.body != "" && rr.Body.String() != v.body {
+				t.Errorf("expected body\n%s\nbut got\n%s", v.body, rr.Body.String())
+			}
+			if v.ctype == ParquetContentType {
+				if _, rows := readParquet(t, rr.Body.Bytes()); len(rows) != 2 {
+					t.Errorf("expected 2 parquet rows but got %d", len(rows))
+				}
+			}
+			reg.AssertCounter(t, "orders_export_rows", nil, 2)
+		})
+	}
+}
+
+func TestExporterOptions(t *testing.T) {
+	e := NewExporter("orders", orderColumns, SetFlushRows(0), SetRowGroupSize(-1), SetDefaultFormat("xlsx"))
+	if e.cfg.flushRows != 100 || e.cfg.rowGroupSize != 10000 || e.cfg.format != CSV {
+		t.Errorf("expected invalid options to be ignored but got %+v", e.cfg)
+	}
+
+	rr := httptest.NewRecorder()
+	e.Handler(orders(2, nil)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 but got %d", rr.Code)
+	}
+
+	// every exporter registers its own metrics
+	other := NewExporter("order-items.2025", orderColumns)
+	reg := sdtest.NewRegistry(t, append(e.Metrics(), other.Metrics()...)...)
+	reg.AssertCounter(t, "orders_export_rows", nil, 2)
+	reg.AssertCounter(t, "order_items_2025_export_rows", nil, 0)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	e := NewExporter("orders", orderColumns)
+
+	rr := httptest.NewRecorder()
+	e.Handler(orders(0, fmt.Errorf("database unavailable"))).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected a failure before the first row to be a 500 but got %d", rr.Code)
+	}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("expected a failure after rows were written to abort the response but got %v", r)
+		}
+	}()
+	e.Handler(orders(3, fmt.Errorf("connection reset"))).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+}
+
+func TestNormalize(t *testing.T) {
+	if _, err := normalize(Int, "12"); !errors.Is(err, ErrColumnType) {
+		t.Errorf("expected %v but got %v", ErrColumnType, err)
+	}
+	if v, err := normalize(Int, int32(12)); err != nil || v != int64(12) {
+		t.Errorf("expected int64 12 but got %v, %v", v, err)
+	}
+}
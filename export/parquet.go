@@ -0,0 +1,282 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types, converted types, and encodings from parquet.thrift
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	repetitionOptional = 1
+)
+
+type chunkMeta struct {
+	offset int64
+	size   int64
+}
+
+type rowGroupMeta struct {
+	rows   int64
+	chunks []chunkMeta
+}
+
+// parquetWriter writes an uncompressed Parquet file with one optional column per export column. Rows are
+// buffered until a row group is full, so memory is bounded by the row group size rather than the result size.
+type parquetWriter struct {
+	w         io.Writer
+	columns   []column
+	groupSize int
+
+	offset  int64
+	rows    [][]any
+	groups  []rowGroupMeta
+	numRows int64
+}
+
+func newParquetWriter(w io.Writer, columns []column, groupSize int) *parquetWriter {
+	return &parquetWriter{w: w, columns: columns, groupSize: groupSize}
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// WriteRow buffers a row, writing the row group once it is full
+func (p *parquetWriter) WriteRow(values []any) error {
+	if p.offset == 0 {
+		if err := p.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+
+	p.rows = append(p.rows, values)
+	if len(p.rows) >= p.groupSize {
+		return p.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes the buffered rows as a row group
+func (p *parquetWriter) Flush() error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+
+	group := rowGroupMeta{rows: int64(len(p.rows))}
+	for i, c := range p.columns {
+		body := p.page(i, c.typ)
+
+		var h thriftWriter
+		h.beginStruct()
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(len(body)))
+		h.i32(3, int32(len(body)))
+		h.structField(5)
+		h.i32(1, int32(len(p.rows)))
+		h.i32(2, encodingPlain)
+		h.i32(3, encodingRLE)
+		h.i32(4, encodingRLE)
+		h.endStruct()
+		h.endStruct()
+
+		chunk := chunkMeta{offset: p.offset, size: int64(h.buf.Len() + len(body))}
+		if err := p.write(h.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(body); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+	}
+
+	p.groups = append(p.groups, group)
+	p.numRows += group.rows
+	p.rows = p.rows[:0]
+
+	return nil
+}
+
+// page encodes the definition levels and non-null values of column i
+func (p *parquetWriter) page(i int, typ Type) []byte {
+	levels := make([]byte, len(p.rows))
+	var values []any
+	for r, row := range p.rows {
+		if row[i] != nil {
+			levels[r] = 1
+			values = append(values, row[i])
+		}
+	}
+
+	var body bytes.Buffer
+	rle := encodeLevels(levels)
+	body.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(rle))))
+	body.Write(rle)
+
+	switch typ {
+	case Bool:
+		bits := make([]byte, (len(values)+7)/8)
+		for j, v := range values {
+			if v.(bool) {
+				bits[j/8] |= 1 << (j % 8)
+			}
+		}
+		body.Write(bits)
+	case Int:
+		for _, v := range values {
+			body.Write(binary.LittleEndian.AppendUint64(nil, uint64(v.(int64))))
+		}
+	case Float:
+		for _, v := range values {
+			body.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v.(float64))))
+		}
+	case Time:
+		for _, v := range values {
+			body.Write(binary.LittleEndian.AppendUint64(nil, uint64(v.(time.Time).UnixMilli())))
+		}
+	default:
+		for _, v := range values {
+			s := v.(string)
+			body.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+			body.WriteString(s)
+		}
+	}
+
+	return body.Bytes()
+}
+
+// encodeLevels encodes definition levels with a bit width of one as RLE runs of the RLE/bit-packing hybrid
+func encodeLevels(levels []byte) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		out = append(out, levels[i])
+		i = j
+	}
+
+	return out
+}
+
+// Close writes any buffered rows and the footer
+func (p *parquetWriter) Close() error {
+	if p.offset == 0 {
+		if err := p.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+
+	var t thriftWriter
+	t.beginStruct()
+	t.i32(1, 1)
+	t.structList(2, len(p.columns)+1)
+	t.beginStruct()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns)))
+	t.endStruct()
+	for _, c := range p.columns {
+		physical, converted := parquetType(c.typ)
+		t.beginStruct()
+		t.i32(1, physical)
+		t.i32(3, repetitionOptional)
+		t.binary(4, c.name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.endStruct()
+	}
+	t.i64(3, p.numRows)
+	t.structList(4, len(p.groups))
+	for _, g := range p.groups {
+		var total int64
+		t.beginStruct()
+		t.structList(1, len(g.chunks))
+		for i, chunk := range g.chunks {
+			physical, _ := parquetType(p.columns[i].typ)
+			total += chunk.size
+			t.beginStruct()
+			t.i64(2, chunk.offset)
+			t.structField(3)
+			t.i32(1, physical)
+			t.i32List(2, encodingPlain, encodingRLE)
+			t.stringList(3, p.columns[i].name)
+			t.i32(4, 0) // UNCOMPRESSED
+			t.i64(5, g.rows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, total)
+		t.i64(3, g.rows)
+		t.endStruct()
+	}
+	t.binary(6, "sencillo-go")
+	t.endStruct()
+
+	footer := t.buf.Bytes()
+	if err := p.write(footer); err != nil {
+		return err
+	}
+	if err := p.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+
+	return p.write([]byte(parquetMagic))
+}
+
+// parquetType returns the physical and converted type of a column type. The converted type is -1 if there is
+// none.
+func parquetType(t Type) (int32, int32) {
+	switch t {
+	case Bool:
+		return parquetBoolean, -1
+	case Int:
+		return parquetInt64, -1
+	case Float:
+		return parquetDouble, -1
+	case Time:
+		return parquetInt64, convertedTimestampMillis
+	default:
+		return parquetByteArray, convertedUTF8
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+// Message is an error whose text is a catalog key resolved when the error is serialized. It can be passed to
+// errors.NewClientError so the client receives the message in its own language.
+type Message struct {
+	catalog *Catalog
+	Key     string
+	Args    []any
+}
+
+// Error returns a new Message error for the key
+func (c *Catalog) Error(key string, args ...any) *Message {
+	return &Message{
+		catalog: c,
+		Key:     key,
+		Args:    args,
+	}
+}
+
+// Error returns the message in the catalog's fallback language
+func (m *Message) Error() string {
+	return m.catalog.Translate(m.catalog.fallback, m.Key, m.Args...)
+}
+
+// Localize returns the message in the best language for the Accept-Language header value
+func (m *Message) Localize(acceptLanguage string) string {
+	return m.catalog.Translate(m.catalog.Negotiate(acceptLanguage), m.Key, m.Args...)
+}
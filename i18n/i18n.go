@@ -0,0 +1,192 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type languageKey struct{}
+
+// Catalog holds translated messages by language and key. Messages are fmt format strings.
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]string
+}
+
+// NewCatalog returns a Catalog that falls back to the language when a translation is missing
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalize(fallback),
+		messages: make(map[string]map[string]string),
+	}
+}
+
+// Add adds messages for the language, replacing any existing messages with the same key
+func (c *Catalog) Add(lang string, messages map[string]string) *Catalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lang = normalize(lang)
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string)
+	}
+	for k, v := range messages {
+		c.messages[lang][k] = v
+	}
+
+	return c
+}
+
+// Languages returns the languages in the catalog
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	langs := make([]string, 0, len(c.messages))
+	for k := range c.messages {
+		langs = append(langs, k)
+	}
+	sort.Strings(langs)
+
+	return langs
+}
+
+// Translate returns the message for the key in the language, falling back to the base language, then the
+// fallback language, and finally the key itself
+func (c *Catalog) Translate(lang, key string, args ...any) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	lang = normalize(lang)
+	for _, l := range []string{lang, base(lang), c.fallback} {
+		if msg, ok := c.messages[l][key]; ok {
+			if len(args) == 0 {
+				return msg
+			}
+			return fmt.Sprintf(msg, args...)
+		}
+	}
+
+	return key
+}
+
+// T translates the key for the language in the context
+func (c *Catalog) T(ctx context.Context, key string, args ...any) string {
+	return c.Translate(LanguageFrom(ctx), key, args...)
+}
+
+// Negotiate returns the best supported language for an Accept-Language header value, or the fallback language
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, lang := range parseAcceptLanguage(acceptLanguage) {
+		if lang == "*" {
+			break
+		}
+		if _, ok := c.messages[lang]; ok {
+			return lang
+		}
+		if _, ok := c.messages[base(lang)]; ok {
+			return base(lang)
+		}
+	}
+
+	return c.fallback
+}
+
+// Middleware negotiates the language from the Accept-Language header and places it in the request context
+func (c *Catalog) Middleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		lang := c.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", lang)
+		h.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), lang)))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// WithLanguage returns a copy of the context carrying the language
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, normalize(lang))
+}
+
+// LanguageFrom returns the language from the context or an empty string
+func LanguageFrom(ctx context.Context) string {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	return lang
+}
+
+// parseAcceptLanguage returns the languages from the header ordered by quality
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := normalize(fields[0])
+		if lang == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(f, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		if q > 0 {
+			langs = append(langs, weighted{lang: lang, q: q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	out := make([]string, 0, len(langs))
+	for _, v := range langs {
+		out = append(out, v.lang)
+	}
+
+	return out
+}
+
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+func base(lang string) string {
+	if i := strings.Index(lang, "-"); i > 0 {
+		return lang[:i]
+	}
+
+	return lang
+}
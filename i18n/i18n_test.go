@@ -0,0 +1,68 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"fmt"
+	"testing"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+)
+
+func testCatalog() *Catalog {
+	return NewCatalog("en").
+		Add("en", map[string]string{"user.not_found": "user %s not found"}).
+		Add("es", map[string]string{"user.not_found": "usuario %s no encontrado"}).
+		Add("pt-BR", map[string]string{"user.not_found": "usuário %s não encontrado"})
+}
+
+func TestNegotiate(t *testing.T) {
+	tt := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "empty", header: "", want: "en"},
+		{name: "exact", header: "es", want: "es"},
+		{name: "region to base", header: "es-MX", want: "es"},
+		{name: "region", header: "pt-BR", want: "pt-br"},
+		{name: "quality", header: "fr;q=1, es;q=0.5, en;q=0.8", want: "en"},
+		{name: "unsupported", header: "de", want: "en"},
+	}
+
+	c := testCatalog()
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := c.Negotiate(v.header); got != v.want {
+				t.Errorf("expected %s but got %s", v.want, got)
+			}
+		})
+	}
+}
+
+func TestClientErrorLocalizedBody(t *testing.T) {
+	c := testCatalog()
+	ce := sderrors.MultipleClientErrors([]error{c.Error("user.not_found", "42"), fmt.Errorf("plain")}, 404)
+
+	want := `{"errors": ["usuario 42 no encontrado","plain"]}`
+	if got := string(ce.LocalizedBody("es-ES,es;q=0.9")); got != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+
+	want = `{"errors": ["user 42 not found","plain"]}`
+	if got := string(ce.Body()); got != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+}
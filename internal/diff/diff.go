@@ -0,0 +1,194 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff renders line based unified diffs
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the size of the LCS table. Larger files are shown as fully replaced.
+const maxDiffCells = 4_000_000
+
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// lineDiff returns the edits that turn a into b using the longest common subsequence of their lines
+func lineDiff(a, b []string) []diffLine {
+	if len(a)*len(b) > maxDiffCells {
+		var out []diffLine
+		for _, v := range a {
+			out = append(out, diffLine{opDelete, v})
+		}
+		for _, v := range b {
+			out = append(out, diffLine{opInsert, v})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{opDelete, a[i]})
+			i++
+		default:
+			out = append(out, diffLine{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, diffLine{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, diffLine{opInsert, b[j]})
+	}
+
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Write writes a unified diff of old and new for the named file and reports whether they differ
+func Write(w io.Writer, name, old, new string) bool {
+	lines := lineDiff(splitLines(old), splitLines(new))
+
+	changed := false
+	for _, v := range lines {
+		if v.op != opEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return false
+	}
+
+	bold := color.New(color.Bold)
+	cyan := color.New(color.FgCyan)
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+
+	from := "a/" + name
+	if old == "" {
+		from = "/dev/null"
+	}
+	bold.Fprintf(w, "--- %s\n+++ b/%s\n", from, name)
+
+	for _, h := range hunks(lines) {
+		oldLine, newLine := lineNumbers(lines, h[0])
+		oldCount, newCount := 0, 0
+		for _, v := range lines[h[0]:h[1]] {
+			if v.op != opInsert {
+				oldCount++
+			}
+			if v.op != opDelete {
+				newCount++
+			}
+		}
+		cyan.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+
+		for _, v := range lines[h[0]:h[1]] {
+			switch v.op {
+			case opEqual:
+				fmt.Fprintf(w, " %s\n", v.text)
+			case opDelete:
+				red.Fprintf(w, "-%s\n", v.text)
+			case opInsert:
+				green.Fprintf(w, "+%s\n", v.text)
+			}
+		}
+	}
+
+	return true
+}
+
+// hunks returns the [start, end) ranges of the diff to print: every change with diffContext lines around
+// it, merging ranges that touch
+func hunks(lines []diffLine) [][2]int {
+	var out [][2]int
+	for i, v := range lines {
+		if v.op == opEqual {
+			continue
+		}
+
+		start, end := max(i-diffContext, 0), min(i+diffContext+1, len(lines))
+		if n := len(out); n > 0 && start <= out[n-1][1] {
+			out[n-1][1] = end
+			continue
+		}
+		out = append(out, [2]int{start, end})
+	}
+
+	return out
+}
+
+// lineNumbers returns the 1 based line numbers in the old and new file at index i of the diff
+func lineNumbers(lines []diffLine, i int) (int, int) {
+	oldLine, newLine := 1, 1
+	for _, v := range lines[:i] {
+		if v.op != opInsert {
+			oldLine++
+		}
+		if v.op != opDelete {
+			newLine++
+		}
+	}
+
+	return oldLine, newLine
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestWrite(t *testing.T) {
+	color.NoColor = true
+
+	tt := []struct {
+		name    string
+		old     string
+		new     string
+		changed bool
+		want    string
+	}{
+		{name: "unchanged", old: "a\nb\n", new: "a\nb\n"},
+		{
+			name:    "new file",
+			new:     "a\nb\n",
+			changed: true,
+			want:    "--- /dev/null\n+++ b/file\n@@ -1,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "changed line",
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:     "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			changed: true,
+			want:    "--- a/file\n+++ b/file\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var b bytes.Buffer
+			changed := Write(&b, "file", v.old, v.new)
+			if changed != v.changed {
+				t.Errorf("expected changed to be %v but got %v", v.changed, changed)
+			}
+			if b.String() != v.want {
+				t.Errorf("expected\n%s\nbut got\n%s", v.want, b.String())
+			}
+		})
+	}
+}
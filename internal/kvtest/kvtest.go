@@ -0,0 +1,170 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kvtest provides an in-memory NATS KV bucket for tests
+package kvtest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Entry is a value stored in a KV
+type Entry struct {
+	nats.KeyValueEntry
+	key      string
+	value    []byte
+	revision uint64
+	created  time.Time
+}
+
+func (e Entry) Key() string        { return e.key }
+func (e Entry) Value() []byte      { return e.value }
+func (e Entry) Revision() uint64   { return e.revision }
+func (e Entry) Created() time.Time { return e.created }
+
+// KV is an in-memory nats.KeyValue implementing Get, Put, PutString, Create, Update, Delete, Purge, and Keys.
+// Every write takes the next revision of the bucket. Other methods panic.
+type KV struct {
+	nats.KeyValue
+	// Now sets the Created time of writes, like the server's clock
+	Now  func() time.Time
+	mu   sync.Mutex
+	rev  uint64
+	data map[string]Entry
+}
+
+// New returns an empty KV
+func New() *KV {
+	return &KV{Now: time.Now, data: make(map[string]Entry)}
+}
+
+func (k *KV) Get(key string) (nats.KeyValueEntry, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.data[key]
+	if !ok {
+		return nil, nats.ErrKeyNotFound
+	}
+	return e, nil
+}
+
+func (k *KV) Put(key string, value []byte) (uint64, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.put(key, value), nil
+}
+
+func (k *KV) PutString(key string, value string) (uint64, error) {
+	return k.Put(key, []byte(value))
+}
+
+func (k *KV) Create(key string, value []byte) (uint64, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.data[key]; ok {
+		return 0, nats.ErrKeyExists
+	}
+	return k.put(key, value), nil
+}
+
+// Update stores the value if the key's revision is last. A missing key has revision 0.
+func (k *KV) Update(key string, value []byte, last uint64) (uint64, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.data[key].revision != last {
+		return 0, nats.ErrKeyExists
+	}
+	return k.put(key, value), nil
+}
+
+func (k *KV) put(key string, value []byte) uint64 {
+	k.rev++
+	k.data[key] = Entry{key: key, value: value, revision: k.rev, created: k.Now()}
+	return k.rev
+}
+
+func (k *KV) Delete(key string, opts ...nats.DeleteOpt) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.data, key)
+	return nil
+}
+
+func (k *KV) Purge(key string, opts ...nats.DeleteOpt) error {
+	return k.Delete(key)
+}
+
+// Keys returns the keys in order, or nats.ErrNoKeysFound when the bucket is empty
+func (k *KV) Keys(opts ...nats.WatchOpt) ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.data) == 0 {
+		return nil, nats.ErrNoKeysFound
+	}
+	keys := make([]string, 0, len(k.data))
+	for key := range k.data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Value returns the key's value, or nil if it isn't set
+func (k *KV) Value(key string) []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.data[key].value
+}
+
+// Len returns the number of keys
+func (k *KV) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.data)
+}
+
+// Revision returns the bucket's latest revision, which counts the writes so far
+func (k *KV) Revision() uint64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.rev
+}
+
+// Manager is a nats.KeyValueManager holding named KVs. Other methods panic.
+type Manager struct {
+	nats.KeyValueManager
+	buckets map[string]*KV
+}
+
+// NewManager returns a Manager with an empty KV for each bucket
+func NewManager(buckets ...string) *Manager {
+	m := &Manager{buckets: make(map[string]*KV)}
+	for _, b := range buckets {
+		m.buckets[b] = New()
+	}
+	return m
+}
+
+// KeyValue returns the bucket, or nats.ErrBucketNotFound
+func (m *Manager) KeyValue(bucket string) (nats.KeyValue, error) {
+	kv, ok := m.buckets[bucket]
+	if !ok {
+		return nil, nats.ErrBucketNotFound
+	}
+	return kv, nil
+}
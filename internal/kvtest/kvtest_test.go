@@ -0,0 +1,61 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestKV(t *testing.T) {
+	kv := New()
+	if _, err := kv.Keys(); !errors.Is(err, nats.ErrNoKeysFound) {
+		t.Errorf("expected %v but got %v", nats.ErrNoKeysFound, err)
+	}
+
+	rev, err := kv.Create("b", []byte("1"))
+	if err != nil || rev != 1 {
+		t.Fatalf("expected revision 1 but got %d, %v", rev, err)
+	}
+	if _, err := kv.Create("b", []byte("2")); !errors.Is(err, nats.ErrKeyExists) {
+		t.Errorf("expected %v but got %v", nats.ErrKeyExists, err)
+	}
+	if _, err := kv.Update("b", []byte("2"), 0); !errors.Is(err, nats.ErrKeyExists) {
+		t.Errorf("expected a stale update to fail but got %v", err)
+	}
+	if _, err := kv.Update("b", []byte("2"), rev); err != nil {
+		t.Fatal(err)
+	}
+	kv.Put("a", []byte("3"))
+
+	e, err := kv.Get("b")
+	if err != nil || string(e.Value()) != "2" || e.Revision() != 2 || e.Key() != "b" {
+		t.Errorf("unexpected entry %v, %v", e, err)
+	}
+	if keys, _ := kv.Keys(); !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("expected sorted keys but got %v", keys)
+	}
+
+	kv.Delete("b")
+	if _, err := kv.Get("b"); !errors.Is(err, nats.ErrKeyNotFound) {
+		t.Errorf("expected %v but got %v", nats.ErrKeyNotFound, err)
+	}
+	if kv.Len() != 1 || kv.Revision() != 3 {
+		t.Errorf("expected 1 key at revision 3 but got %d at %d", kv.Len(), kv.Revision())
+	}
+}
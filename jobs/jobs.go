@@ -0,0 +1,205 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs runs background jobs from a JetStream work queue. Jobs are published to
+// <subject>.<priority>.<kind>, so each priority level is a subject partition of one stream with its own pull
+// consumer, and workers drain higher priorities first.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EnqueuedHeader holds when a job was enqueued, used to measure how long it waited
+const EnqueuedHeader = "Sencillo-Job-Enqueued"
+
+var ErrInvalidPriority = fmt.Errorf("invalid priority")
+
+// Priority is the level a job is queued at. Lower values are drained first.
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+)
+
+// Priorities lists every level from highest to lowest
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// ParsePriority returns the priority with the given name
+func ParsePriority(s string) (Priority, error) {
+	for _, p := range Priorities {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidPriority, s)
+}
+
+// Job is a message taken from the queue
+type Job struct {
+	Kind     string
+	Priority Priority
+	Data     []byte
+	Header   nats.Header
+	Enqueued time.Time
+	Msg      *nats.Msg
+}
+
+// Publisher publishes jobs. nats.JetStreamContext implements it.
+type Publisher interface {
+	PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// EnqueueOpt is a functional option to modify a job when it is enqueued
+type EnqueueOpt func(*nats.Msg, *Priority)
+
+// SetPriority sets the job's priority. The default is PriorityNormal.
+func SetPriority(p Priority) EnqueueOpt {
+	return func(_ *nats.Msg, priority *Priority) {
+		*priority = p
+	}
+}
+
+// SetHeader sets a header on the job's message
+func SetHeader(key, value string) EnqueueOpt {
+	return func(m *nats.Msg, _ *Priority) {
+		m.Header.Set(key, value)
+	}
+}
+
+// QueueOpt is a functional option to modify the Queue
+type QueueOpt func(*Queue)
+
+// Queue publishes jobs under a subject prefix
+type Queue struct {
+	js      Publisher
+	subject string
+	now     func() time.Time
+
+	schedule  nats.KeyValue
+	tick      time.Duration
+	logger    *slog.Logger
+	scheduled prometheus.Gauge
+	overdue   prometheus.Gauge
+}
+
+// NewQueue returns a Queue publishing to subjects under subject, such as jobs
+func NewQueue(js Publisher, subject string, opts ...QueueOpt) *Queue {
+	q := &Queue{
+		js:      js,
+		subject: subject,
+		now:     time.Now,
+		tick:    time.Second,
+		logger:  slog.Default(),
+		scheduled: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "jobs_scheduled",
+			Help: "Jobs scheduled to be enqueued later",
+		}),
+		overdue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "jobs_scheduled_overdue_seconds",
+			Help: "How late the oldest due scheduled job is",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// Metrics returns the queue's collectors for the scheduled backlog
+func (q *Queue) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{q.scheduled, q.overdue}
+}
+
+// StreamConfig returns a work queue stream config capturing every job of the queue. Pass it to AddStream.
+func (q *Queue) StreamConfig(name string) *nats.StreamConfig {
+	return &nats.StreamConfig{
+		Name:      name,
+		Subjects:  []string{q.subject + ".>"},
+		Retention: nats.WorkQueuePolicy,
+	}
+}
+
+// Subject returns the subject jobs of kind are published to at priority
+func (q *Queue) Subject(p Priority, kind string) string {
+	return fmt.Sprintf("%s.%s.%s", q.subject, p, kind)
+}
+
+// Enqueue publishes a job of the given kind, such as email.send
+func (q *Queue) Enqueue(ctx context.Context, kind string, data []byte, opts ...EnqueueOpt) error {
+	msg, _, err := q.msg(kind, data, opts)
+	if err != nil {
+		return err
+	}
+
+	msg.Header.Set(EnqueuedHeader, q.now().UTC().Format(time.RFC3339Nano))
+	_, err = q.js.PublishMsg(msg, nats.Context(ctx))
+	return err
+}
+
+// msg builds the message for a job
+func (q *Queue) msg(kind string, data []byte, opts []EnqueueOpt) (*nats.Msg, Priority, error) {
+	msg := nats.NewMsg("")
+	msg.Data = data
+	priority := PriorityNormal
+	for _, opt := range opts {
+		opt(msg, &priority)
+	}
+
+	if priority < PriorityHigh || priority > PriorityLow {
+		return nil, priority, fmt.Errorf("%w: %d", ErrInvalidPriority, priority)
+	}
+
+	msg.Subject = q.Subject(priority, kind)
+	return msg, priority, nil
+}
+
+// job returns the job carried by msg
+func (q *Queue) job(p Priority, msg *nats.Msg) Job {
+	j := Job{
+		Kind:     strings.TrimPrefix(msg.Subject, fmt.Sprintf("%s.%s.", q.subject, p)),
+		Priority: p,
+		Data:     msg.Data,
+		Header:   msg.Header,
+		Msg:      msg,
+	}
+	if t, err := time.Parse(time.RFC3339Nano, msg.Header.Get(EnqueuedHeader)); err == nil {
+		j.Enqueued = t
+	}
+
+	return j
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/retry"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakePublisher struct {
+	msgs []*nats.Msg
+}
+
+func (f *fakePublisher) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	f.msgs = append(f.msgs, m)
+	return &nats.PubAck{}, nil
+}
+
+type fakeSource struct {
+	mu   sync.Mutex
+	msgs []*nats.Msg
+	// fail is how many fetches fail before messages are returned
+	fail int
+}
+
+func (f *fakeSource) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail > 0 {
+		f.fail--
+		return nil, nats.ErrConnectionClosed
+	}
+	if len(f.msgs) == 0 {
+		return nil, nats.ErrTimeout
+	}
+	m := f.msgs[0]
+	f.msgs = f.msgs[1:]
+	return []*nats.Msg{m}, nil
+}
+
+func (f *fakeSource) ConsumerInfo() (*nats.ConsumerInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &nats.ConsumerInfo{NumPending: uint64(len(f.msgs))}, nil
+}
+
+func TestEnqueue(t *testing.T) {
+	pub := &fakePublisher{}
+	q := NewQueue(pub, "jobs")
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	if err := q.Enqueue(context.Background(), "email.send", []byte("hi"), SetPriority(PriorityHigh), SetHeader("Tenant", "acme")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(context.Background(), "report.build", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(context.Background(), "report.build", nil, SetPriority(Priority(7))); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("expected %v but got %v", ErrInvalidPriority, err)
+	}
+
+	var subjects []string
+	for _, m := range pub.msgs {
+		subjects = append(subjects, m.Subject)
+	}
+	if want := []string{"jobs.high.email.send", "jobs.normal.report.build"}; !reflect.DeepEqual(subjects, want) {
+		t.Errorf("expected subjects %v but got %v", want, subjects)
+	}
+
+	j := q.job(PriorityHigh, pub.msgs[0])
+	if j.Kind != "email.send" || !j.Enqueued.Equal(now) || j.Header.Get("Tenant") != "acme" {
+		t.Errorf("unexpected job %+v", j)
+	}
+
+	cfg := q.StreamConfig("JOBS")
+	if cfg.Retention != nats.WorkQueuePolicy || cfg.Subjects[0] != "jobs.>" {
+		t.Errorf("unexpected stream config %+v", cfg)
+	}
+}
+
+func TestWorkerPriorities(t *testing.T) {
+	q := NewQueue(&fakePublisher{}, "jobs")
+	enqueued := time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano)
+	msgs := func(p Priority, n int) []*nats.Msg {
+		var out []*nats.Msg
+		for i := range n {
+			m := nats.NewMsg(q.Subject(p, fmt.Sprintf("task.%d", i)))
+			m.Header.Set(EnqueuedHeader, enqueued)
+			out = append(out, m)
+		}
+		return out
+	}
+	sources := map[Priority]Source{
+		PriorityHigh:   &fakeSource{msgs: msgs(PriorityHigh, 6)},
+		PriorityNormal: &fakeSource{},
+		PriorityLow:    &fakeSource{msgs: msgs(PriorityLow, 2)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var got []string
+	w := NewWorker(q, func(ctx context.Context, j Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, j.Priority.String())
+		if len(got) == 8 {
+			cancel()
+		}
+		if j.Kind == "task.0" && j.Priority == PriorityLow {
+			return fmt.Errorf("flaky")
+		}
+		return nil
+	},
+		SetConcurrency(1),
+		SetStarvationLimit(2),
+		SetPollWait(time.Millisecond),
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	var nacked int
+	w.ack = func(*nats.Msg) error { return nil }
+	w.nak = func(*nats.Msg, time.Duration) error { nacked++; return nil }
+	reg := sdtest.NewRegistry(t, w.Metrics()...)
+
+	w.run(ctx, sources)
+
+	// low priority gets a turn after every two high priority jobs
+	want := []string{"high", "high", "low", "high", "high", "low", "high", "high"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected order %v but got %v", want, got)
+	}
+	if nacked != 1 {
+		t.Errorf("expected the failed job to be naked but got %d naks", nacked)
+	}
+
+	reg.AssertCounter(t, "jobs_processed", prometheus.Labels{"priority": "high", "outcome": "success"}, 6)
+	reg.AssertCounter(t, "jobs_processed", prometheus.Labels{"priority": "low", "outcome": "error"}, 1)
+	reg.AssertHistogramCount(t, "jobs_wait_seconds", prometheus.Labels{"priority": "low"}, 2)
+
+	w.updateDepth(map[Priority]Source{PriorityNormal: &fakeSource{msgs: msgs(PriorityNormal, 3)}})
+	reg.AssertGauge(t, "jobs_queue_depth", prometheus.Labels{"priority": "normal"}, 3)
+}
+
+func TestWorkerFetchErrors(t *testing.T) {
+	q := NewQueue(&fakePublisher{}, "jobs")
+	sources := map[Priority]Source{
+		PriorityHigh:   &fakeSource{fail: 3, msgs: []*nats.Msg{nats.NewMsg(q.Subject(PriorityHigh, "task"))}},
+		PriorityNormal: &fakeSource{},
+		PriorityLow:    &fakeSource{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var processed int
+	w := NewWorker(q, func(ctx context.Context, j Job) error {
+		processed++
+		cancel()
+		return nil
+	},
+		SetConcurrency(1),
+		SetPollWait(time.Millisecond),
+		SetIdleWait(4*time.Millisecond),
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	w.ack = func(*nats.Msg) error { return nil }
+
+	w.run(ctx, sources)
+	if processed != 1 {
+		t.Errorf("expected the job to be processed after the failed fetches but got %d", processed)
+	}
+}
+
+func TestWorkerRetryDelay(t *testing.T) {
+	q := NewQueue(&fakePublisher{}, "jobs")
+	tt := []struct {
+		name  string
+		reply string
+		delay time.Duration
+	}{
+		{name: "first delivery", reply: "$JS.ACK.JOBS.worker-high.1.5.5.1700000000000000000.0", delay: time.Second},
+		{name: "third delivery", reply: "$JS.ACK.JOBS.worker-high.3.5.5.1700000000000000000.0", delay: 4 * time.Second},
+		{name: "capped", reply: "$JS.ACK.JOBS.worker-high.9.5.5.1700000000000000000.0", delay: 10 * time.Second},
+		{name: "no metadata", delay: time.Second},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			w := NewWorker(q, func(ctx context.Context, j Job) error { return fmt.Errorf("flaky") },
+				SetRetryPolicy(retry.NewPolicy(retry.SetBackoff(time.Second, 10*time.Second, 2), retry.SetJitter(0))),
+				SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			)
+			var got time.Duration
+			w.nak = func(m *nats.Msg, d time.Duration) error { got = d; return nil }
+
+			m := nats.NewMsg(q.Subject(PriorityHigh, "task"))
+			if v.reply != "" {
+				m.Reply = v.reply
+				m.Sub = &nats.Subscription{}
+			}
+			w.process(context.Background(), PriorityHigh, m)
+			if got != v.delay {
+				t.Errorf("expected a redelivery delay of %s but got %s", v.delay, got)
+			}
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range Priorities {
+		got, err := ParsePriority(p.String())
+		if err != nil || got != p {
+			t.Errorf("expected %s to parse but got %v %v", p, got, err)
+		}
+	}
+	if _, err := ParsePriority("urgent"); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("expected %v but got %v", ErrInvalidPriority, err)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+var (
+	ErrNoSchedule   = fmt.Errorf("queue has no schedule bucket")
+	ErrNotScheduled = fmt.Errorf("job is not scheduled")
+)
+
+// Scheduled is a job waiting in the schedule bucket to be enqueued
+type Scheduled struct {
+	ID       string      `json:"id"`
+	Kind     string      `json:"kind"`
+	Priority Priority    `json:"priority"`
+	At       time.Time   `json:"at"`
+	Subject  string      `json:"subject"`
+	Data     []byte      `json:"data,omitempty"`
+	Header   nats.Header `json:"header,omitempty"`
+}
+
+// SetSchedule keeps delayed jobs in kv until they are due. Keys start with the due time so they sort in due
+// order. The scheduler lists every key on each check but only reads the entries that are due, so keep the bucket
+// for scheduled jobs alone.
+func SetSchedule(kv nats.KeyValue) QueueOpt {
+	return func(q *Queue) {
+		q.schedule = kv
+	}
+}
+
+// SetScheduleInterval sets how often the scheduler checks for due jobs. The default is a second.
+func SetScheduleInterval(d time.Duration) QueueOpt {
+	return func(q *Queue) {
+		q.tick = d
+	}
+}
+
+// SetQueueLogger sets the logger used to report scheduler failures
+func SetQueueLogger(l *slog.Logger) QueueOpt {
+	return func(q *Queue) {
+		q.logger = l
+	}
+}
+
+// EnqueueAfter schedules a job to be enqueued after d and returns its ID for Cancel
+func (q *Queue) EnqueueAfter(ctx context.Context, d time.Duration, kind string, data []byte, opts ...EnqueueOpt) (string, error) {
+	return q.EnqueueAt(ctx, q.now().Add(d), kind, data, opts...)
+}
+
+// EnqueueAt schedules a job to be enqueued at t and returns its ID for Cancel. Jobs are enqueued by
+// RunScheduler, so they run no earlier than t plus the time they wait in the queue. Times before 1970 are
+// treated as 1970, which is already due.
+func (q *Queue) EnqueueAt(ctx context.Context, t time.Time, kind string, data []byte, opts ...EnqueueOpt) (string, error) {
+	if q.schedule == nil {
+		return "", ErrNoSchedule
+	}
+
+	// a negative UnixNano would start the key with a minus sign, which sorts out of order and isn't parsed as a due time
+	if epoch := time.Unix(0, 0); t.Before(epoch) {
+		t = epoch
+	}
+
+	msg, priority, err := q.msg(kind, data, opts)
+	if err != nil {
+		return "", err
+	}
+
+	s := Scheduled{
+		ID:       fmt.Sprintf("%020d-%s", t.UnixNano(), nuid.Next()),
+		Kind:     kind,
+		Priority: priority,
+		At:       t.UTC(),
+		Subject:  msg.Subject,
+		Data:     msg.Data,
+		Header:   msg.Header,
+	}
+	value, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := q.schedule.Create(s.ID, value); err != nil {
+		return "", err
+	}
+	return s.ID, nil
+}
+
+// Cancel removes a scheduled job that hasn't been enqueued yet
+func (q *Queue) Cancel(ctx context.Context, id string) error {
+	if q.schedule == nil {
+		return ErrNoSchedule
+	}
+
+	if _, err := q.schedule.Get(id); err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrInvalidKey) {
+			return fmt.Errorf("%w: %s", ErrNotScheduled, id)
+		}
+		return err
+	}
+
+	return q.schedule.Delete(id)
+}
+
+// Scheduled returns the jobs waiting to be enqueued, soonest first
+func (q *Queue) Scheduled(ctx context.Context) ([]Scheduled, error) {
+	if q.schedule == nil {
+		return nil, ErrNoSchedule
+	}
+
+	keys, err := q.scheduleKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Scheduled, 0, len(keys))
+	for _, key := range keys {
+		s, _, err := q.getScheduled(key)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, s)
+	}
+
+	return jobs, nil
+}
+
+// RunScheduler enqueues scheduled jobs as they come due until ctx is done. Every instance can run it: jobs are
+// published with their ID as the message ID, so JetStream drops the duplicate when two instances enqueue the
+// same job.
+func (q *Queue) RunScheduler(ctx context.Context) error {
+	if q.schedule == nil {
+		return ErrNoSchedule
+	}
+
+	ticker := time.NewTicker(q.tick)
+	defer ticker.Stop()
+
+	for {
+		if err := q.enqueueDue(ctx); err != nil {
+			q.logger.Error(fmt.Sprintf("enqueueing scheduled jobs: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue publishes every due job and removes it from the schedule
+func (q *Queue) enqueueDue(ctx context.Context) error {
+	keys, err := q.scheduleKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := q.now()
+	q.scheduled.Set(float64(len(keys)))
+	q.overdue.Set(0)
+
+	var errs []error
+	for i, key := range keys {
+		due, ok := dueAt(key)
+		if !ok {
+			continue
+		}
+		if due.After(now) {
+			break
+		}
+		if i == 0 {
+			q.overdue.Set(now.Sub(due).Seconds())
+		}
+
+		s, rev, err := q.getScheduled(key)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			// cancelled or enqueued by another instance
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		msg := nats.NewMsg(s.Subject)
+		msg.Data = s.Data
+		for k, v := range s.Header {
+			msg.Header[k] = v
+		}
+		msg.Header.Set(EnqueuedHeader, s.At.Format(time.RFC3339Nano))
+		msg.Header.Set(nats.MsgIdHdr, s.ID)
+		if _, err := q.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+			errs = append(errs, fmt.Errorf("enqueueing %s: %w", s.ID, err))
+			continue
+		}
+
+		if err := q.schedule.Delete(key, nats.LastRevision(rev)); err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+			q.logger.Warn(fmt.Sprintf("removing enqueued job %s from the schedule: %v", s.ID, err))
+		}
+		q.scheduled.Dec()
+	}
+
+	return errors.Join(errs...)
+}
+
+func (q *Queue) scheduleKeys(ctx context.Context) ([]string, error) {
+	keys, err := q.schedule.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (q *Queue) getScheduled(key string) (Scheduled, uint64, error) {
+	var s Scheduled
+	entry, err := q.schedule.Get(key)
+	if err != nil {
+		return s, 0, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &s)
+	return s, entry.Revision(), err
+}
+
+// dueAt returns the due time at the start of a schedule key
+func dueAt(key string) (time.Time, bool) {
+	prefix, _, ok := strings.Cut(key, "-")
+	if !ok {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+)
+
+func TestSchedule(t *testing.T) {
+	pub := &fakePublisher{}
+	kv := kvtest.New()
+	q := NewQueue(pub, "jobs", SetSchedule(kv))
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	reg := sdtest.NewRegistry(t, q.Metrics()...)
+	ctx := context.Background()
+
+	reminder, err := q.EnqueueAfter(ctx, time.Hour, "email.reminder", []byte("later"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoice, err := q.EnqueueAt(ctx, now.Add(time.Minute), "invoice.send", []byte("soon"), SetPriority(PriorityHigh), SetHeader("Tenant", "acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := q.EnqueueAfter(ctx, 2*time.Minute, "email.digest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Cancel(ctx, cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Cancel(ctx, cancelled); !errors.Is(err, ErrNotScheduled) {
+		t.Errorf("expected %v but got %v", ErrNotScheduled, err)
+	}
+
+	backlog, err := q.Scheduled(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range backlog {
+		ids = append(ids, s.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{invoice, reminder}) {
+		t.Errorf("expected the backlog soonest first but got %v", ids)
+	}
+
+	// nothing is due yet
+	if err := q.enqueueDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.msgs) != 0 {
+		t.Fatalf("expected nothing to be enqueued but got %d jobs", len(pub.msgs))
+	}
+	reg.AssertGauge(t, "jobs_scheduled", nil, 2)
+
+	now = now.Add(90 * time.Second)
+	if err := q.enqueueDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.msgs) != 1 {
+		t.Fatalf("expected the invoice to be enqueued but got %d jobs", len(pub.msgs))
+	}
+	m := pub.msgs[0]
+	if m.Subject != "jobs.high.invoice.send" || string(m.Data) != "soon" || m.Header.Get("Tenant") != "acme" || m.Header.Get(nats.MsgIdHdr) != invoice {
+		t.Errorf("unexpected message %s %q %v", m.Subject, m.Data, m.Header)
+	}
+	reg.AssertGauge(t, "jobs_scheduled", nil, 1)
+	reg.AssertGauge(t, "jobs_scheduled_overdue_seconds", nil, 30)
+	if _, err := kv.Get(invoice); !errors.Is(err, nats.ErrKeyNotFound) {
+		t.Error("expected the enqueued job to be removed from the schedule")
+	}
+
+	if _, err := NewQueue(pub, "jobs").EnqueueAfter(ctx, time.Minute, "email.reminder", nil); !errors.Is(err, ErrNoSchedule) {
+		t.Errorf("expected %v but got %v", ErrNoSchedule, err)
+	}
+}
+
+func TestSchedulePast(t *testing.T) {
+	pub := &fakePublisher{}
+	q := NewQueue(pub, "jobs", SetSchedule(kvtest.New()))
+	ctx := context.Background()
+
+	id, err := q.EnqueueAt(ctx, time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC), "email.reminder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due, ok := dueAt(id); !ok || !due.Equal(time.Unix(0, 0)) {
+		t.Errorf("expected a time before 1970 to be scheduled at the epoch but got %s", id)
+	}
+
+	if err := q.enqueueDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.msgs) != 1 {
+		t.Errorf("expected the job to be enqueued but got %d jobs", len(pub.msgs))
+	}
+}
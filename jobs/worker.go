@@ -0,0 +1,346 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/SencilloDev/sencillo-go/retry"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Handler processes a job. Returning an error naks the job so it is redelivered after the worker's retry delay.
+type Handler func(context.Context, Job) error
+
+// Source is a pull consumer for one priority. *nats.Subscription implements it.
+type Source interface {
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+	ConsumerInfo() (*nats.ConsumerInfo, error)
+}
+
+// WorkerOpt is a functional option to modify the Worker
+type WorkerOpt func(*Worker)
+
+// Worker runs a pool of goroutines taking jobs from the queue, highest priority first. A lower priority that
+// has been passed over for the starvation limit of jobs is checked first for its next job, so a steady stream
+// of high priority work can't stop low priority work completely.
+type Worker struct {
+	queue       *Queue
+	handler     Handler
+	concurrency int
+	starvation  int
+	pollWait    time.Duration
+	idleWait    time.Duration
+	depthEvery  time.Duration
+	retry       retry.Policy
+	logger      *slog.Logger
+	ack         func(*nats.Msg) error
+	nak         func(*nats.Msg, time.Duration) error
+	now         func() time.Time
+
+	depth     *prometheus.GaugeVec
+	wait      *prometheus.HistogramVec
+	processed *prometheus.CounterVec
+
+	mu      sync.Mutex
+	skipped map[Priority]int
+}
+
+// SetConcurrency sets how many jobs run at once. The default is 4.
+func SetConcurrency(n int) WorkerOpt {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// SetStarvationLimit sets how many higher priority jobs can run before a waiting lower priority gets the next
+// turn. The default is 10.
+func SetStarvationLimit(n int) WorkerOpt {
+	return func(w *Worker) {
+		w.starvation = n
+	}
+}
+
+// SetPollWait sets how long each priority is waited on for a job when looking for the next one. It is kept short
+// so a job arriving at a higher priority isn't held up by a wait on a lower one. The default is 50ms.
+func SetPollWait(d time.Duration) WorkerOpt {
+	return func(w *Worker) {
+		w.pollWait = d
+	}
+}
+
+// SetIdleWait caps how long an idle worker sleeps before checking the priorities again. While the queue is empty
+// the sleep doubles from the poll wait up to this, and failed fetches are retried after the same backoff. The
+// default is 1 second.
+func SetIdleWait(d time.Duration) WorkerOpt {
+	return func(w *Worker) {
+		w.idleWait = d
+	}
+}
+
+// SetDepthInterval sets how often the queue depth metric is refreshed from the consumers
+func SetDepthInterval(d time.Duration) WorkerOpt {
+	return func(w *Worker) {
+		w.depthEvery = d
+	}
+}
+
+// SetRetryPolicy sets the policy whose backoff, for the job's delivery count, delays the redelivery of a failed
+// job. The default starts at 1 second and doubles up to 1 minute.
+func SetRetryPolicy(p retry.Policy) WorkerOpt {
+	return func(w *Worker) {
+		w.retry = p
+	}
+}
+
+// SetLogger sets the logger used to report failed jobs
+func SetLogger(l *slog.Logger) WorkerOpt {
+	return func(w *Worker) {
+		w.logger = l
+	}
+}
+
+// NewWorker returns a Worker running handler for the jobs of q
+func NewWorker(q *Queue, handler Handler, opts ...WorkerOpt) *Worker {
+	w := &Worker{
+		queue:       q,
+		handler:     handler,
+		concurrency: 4,
+		starvation:  10,
+		pollWait:    50 * time.Millisecond,
+		idleWait:    time.Second,
+		depthEvery:  15 * time.Second,
+		retry:       retry.NewPolicy(retry.SetBackoff(time.Second, time.Minute, 2)),
+		logger:      slog.Default(),
+		ack:         func(m *nats.Msg) error { return m.Ack() },
+		nak:         func(m *nats.Msg, d time.Duration) error { return m.NakWithDelay(d) },
+		now:         time.Now,
+		depth:       metrics.NewGaugeVec("jobs_queue_depth", "Jobs waiting in the queue by priority", []string{"priority"}),
+		wait:        metrics.NewHistogramVec("jobs_wait_seconds", "Time jobs waited in the queue by priority", []string{"priority"}),
+		processed:   metrics.NewCounterVec("jobs_processed", "Jobs processed by priority and outcome", []string{"priority", "outcome"}),
+		skipped:     make(map[Priority]int),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Metrics returns the worker's collectors. Append them to the server's Exporter to serve them on /metrics.
+func (w *Worker) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{w.depth, w.wait, w.processed}
+}
+
+// Run creates or binds a durable pull consumer per priority, named <durable>-<priority>, and processes jobs until
+// ctx is done. Failed fetches are logged and retried. Jobs already running are finished before Run returns.
+func (w *Worker) Run(ctx context.Context, js nats.JetStreamContext, durable string) error {
+	sources := make(map[Priority]Source, len(Priorities))
+	for _, p := range Priorities {
+		sub, err := js.PullSubscribe(fmt.Sprintf("%s.%s.>", w.queue.subject, p), fmt.Sprintf("%s-%s", durable, p), nats.ManualAck())
+		if err != nil {
+			return err
+		}
+		sources[p] = sub
+	}
+
+	w.run(ctx, sources)
+	return nil
+}
+
+func (w *Worker) run(ctx context.Context, sources map[Priority]Source) {
+	n := max(w.concurrency, 1)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.poll(ctx, sources)
+		}()
+	}
+
+	go w.watchDepth(ctx, sources)
+
+	wg.Wait()
+}
+
+// poll takes jobs until ctx is done. Each round makes a short fetch from every priority in order, so a new high
+// priority job is found within one poll wait. A round that finds nothing sleeps before the next one, longer for
+// each empty round in a row, and a job resets the sleep.
+func (w *Worker) poll(ctx context.Context, sources map[Priority]Source) {
+	idle := retry.NewPolicy(retry.SetBackoff(w.pollWait, w.idleWait, 2), retry.SetJitter(0))
+	misses := 0
+	for ctx.Err() == nil {
+		found, err := w.next(ctx, sources)
+		if err != nil {
+			w.logger.Error(fmt.Sprintf("fetching jobs: %v", err))
+		}
+		if found {
+			misses = 0
+			continue
+		}
+
+		misses++
+		timer := time.NewTimer(idle.Backoff(misses))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// order returns the priorities in the order to look for the next job: starving priorities first, then the rest
+// from highest to lowest
+func (w *Worker) order() []Priority {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var starving, rest []Priority
+	for _, p := range Priorities {
+		if w.skipped[p] >= w.starvation {
+			starving = append(starving, p)
+			continue
+		}
+		rest = append(rest, p)
+	}
+
+	return append(starving, rest...)
+}
+
+// record updates the starvation counters after a search. Priorities found empty aren't starving, the priority
+// served gets a fresh count, and lower priorities that weren't checked were passed over.
+func (w *Worker) record(served Priority, found bool, empty []Priority) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, p := range empty {
+		w.skipped[p] = 0
+	}
+	if !found {
+		return
+	}
+
+	w.skipped[served] = 0
+	for _, p := range Priorities {
+		if p > served && !slices.Contains(empty, p) {
+			w.skipped[p]++
+		}
+	}
+}
+
+// next takes one job from the first priority in order that has one and processes it, waiting up to the poll wait
+// on each priority. A priority that fails to fetch is skipped, and its error is returned with whether a job was
+// found.
+func (w *Worker) next(ctx context.Context, sources map[Priority]Source) (bool, error) {
+	var empty []Priority
+	var errs []error
+	for _, p := range w.order() {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
+		// the fetch context ends the pull as soon as ctx is done
+		fctx, cancel := context.WithTimeout(ctx, w.pollWait)
+		msgs, err := sources[p].Fetch(1, nats.Context(fctx))
+		cancel()
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) || (err == nil && len(msgs) == 0) {
+			empty = append(empty, p)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s priority: %w", p, err))
+			continue
+		}
+
+		w.record(p, true, empty)
+		for _, m := range msgs {
+			w.process(ctx, p, m)
+		}
+		return true, errors.Join(errs...)
+	}
+
+	w.record(0, false, empty)
+	return false, errors.Join(errs...)
+}
+
+func (w *Worker) process(ctx context.Context, p Priority, m *nats.Msg) {
+	j := w.queue.job(p, m)
+	if !j.Enqueued.IsZero() {
+		w.wait.WithLabelValues(p.String()).Observe(w.now().Sub(j.Enqueued).Seconds())
+	}
+
+	err := safeHandle(ctx, w.handler, j)
+	if err != nil {
+		w.logger.Error(fmt.Sprintf("job %s failed: %v", m.Subject, err))
+		w.processed.WithLabelValues(p.String(), "error").Inc()
+		if err := w.nak(m, w.retry.Backoff(attempt(j))); err != nil {
+			w.logger.Error(fmt.Sprintf("naking job %s: %v", m.Subject, err))
+		}
+		return
+	}
+
+	w.processed.WithLabelValues(p.String(), "success").Inc()
+	if err := w.ack(m); err != nil {
+		w.logger.Error(fmt.Sprintf("acking job %s: %v", m.Subject, err))
+	}
+}
+
+func safeHandle(ctx context.Context, h Handler, j Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, j)
+}
+
+// watchDepth refreshes the queue depth metric from the consumers' pending counts
+func (w *Worker) watchDepth(ctx context.Context, sources map[Priority]Source) {
+	ticker := time.NewTicker(w.depthEvery)
+	defer ticker.Stop()
+
+	for {
+		w.updateDepth(sources)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) updateDepth(sources map[Priority]Source) {
+	for p, src := range sources {
+		info, err := src.ConsumerInfo()
+		if err != nil {
+			w.logger.Warn(fmt.Sprintf("getting %s priority queue depth: %v", p, err))
+			continue
+		}
+		w.depth.WithLabelValues(p.String()).Set(float64(info.NumPending + uint64(info.NumAckPending)))
+	}
+}
@@ -0,0 +1,608 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+const (
+	// WorkflowRunHeader and WorkflowStepHeader identify the workflow step a job runs
+	WorkflowRunHeader  = "Sencillo-Workflow-Run"
+	WorkflowStepHeader = "Sencillo-Workflow-Step"
+)
+
+var (
+	ErrInvalidWorkflow = fmt.Errorf("invalid workflow")
+	ErrUnknownWorkflow = fmt.Errorf("unknown workflow")
+	ErrUnknownStep     = fmt.Errorf("unknown step")
+)
+
+// FailurePolicy decides what happens to the rest of a workflow when a step fails
+type FailurePolicy int
+
+const (
+	// FailWorkflow skips every step that hasn't been queued yet
+	FailWorkflow FailurePolicy = iota
+	// SkipDependents skips the steps that depend on the failed step, directly or not, and runs the rest
+	SkipDependents
+	// Continue runs dependents anyway. They get the failure in StepInput.Errors instead of a result.
+	Continue
+)
+
+// Step is a node of a workflow. Its job of Kind is enqueued once every step in After has finished.
+type Step struct {
+	Name  string
+	Kind  string
+	After []string
+	// Options are passed to Enqueue for the step's job, such as SetPriority. Jobs default to PriorityNormal.
+	Options   []EnqueueOpt
+	OnFailure FailurePolicy
+	// Attempts is how many times the step runs before it counts as failed. The default is 1.
+	Attempts int
+}
+
+// Workflow is a graph of steps
+type Workflow struct {
+	Name  string
+	Steps []Step
+}
+
+// NewWorkflow returns a validated workflow. Steps must have unique names and kinds, depend only on steps of
+// the workflow, and not form a cycle.
+func NewWorkflow(name string, steps ...Step) (*Workflow, error) {
+	w := &Workflow{Name: name, Steps: steps}
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Workflow) validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("%w: %s has no steps", ErrInvalidWorkflow, w.Name)
+	}
+
+	var errs []error
+	names := make(map[string]Step, len(w.Steps))
+	kinds := make(map[string]string, len(w.Steps))
+	for _, s := range w.Steps {
+		if _, ok := names[s.Name]; ok {
+			errs = append(errs, fmt.Errorf("%w: duplicate step %s", ErrInvalidWorkflow, s.Name))
+		}
+		if s.Kind == "" {
+			errs = append(errs, fmt.Errorf("%w: step %s has no kind", ErrInvalidWorkflow, s.Name))
+		} else if other, ok := kinds[s.Kind]; ok {
+			errs = append(errs, fmt.Errorf("%w: steps %s and %s have the same kind %s", ErrInvalidWorkflow, other, s.Name, s.Kind))
+		}
+		names[s.Name] = s
+		kinds[s.Kind] = s.Name
+	}
+	for _, s := range w.Steps {
+		for _, dep := range s.After {
+			if _, ok := names[dep]; !ok {
+				errs = append(errs, fmt.Errorf("%w: step %s runs after unknown step %s", ErrInvalidWorkflow, s.Name, dep))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// depth first search for a cycle, reporting the steps on it
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			i := len(path) - 1
+			for path[i] != name {
+				i--
+			}
+			return fmt.Errorf("%w: cycle %s", ErrInvalidWorkflow, strings.Join(append(path[i:], name), " -> "))
+		case done:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range names[name].After {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, s := range w.Steps {
+		if err := visit(s.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Workflow) step(name string) (Step, bool) {
+	for _, s := range w.Steps {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Step{}, false
+}
+
+// Mermaid returns the workflow as a Mermaid flowchart for documentation
+func (w *Workflow) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, s := range w.Steps {
+		fmt.Fprintf(&b, "    %s[\"%s<br/>%s\"]\n", s.Name, s.Name, s.Kind)
+	}
+	for _, s := range w.Steps {
+		for _, dep := range s.After {
+			fmt.Fprintf(&b, "    %s --> %s\n", dep, s.Name)
+		}
+	}
+	return b.String()
+}
+
+// DOT returns the workflow as a Graphviz digraph for documentation
+func (w *Workflow) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", w.Name)
+	for _, s := range w.Steps {
+		fmt.Fprintf(&b, "  %q [label=%q];\n", s.Name, s.Name+"\n"+s.Kind)
+	}
+	for _, s := range w.Steps {
+		for _, dep := range s.After {
+			fmt.Fprintf(&b, "  %q -> %q;\n", dep, s.Name)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// StepStatus is the state of a step in a run
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepQueued    StepStatus = "queued"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+)
+
+func (s StepStatus) finished() bool {
+	return s == StepSucceeded || s == StepFailed || s == StepSkipped
+}
+
+// RunStatus is the state of a workflow run
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// StepState is the stored state of a step in a run
+type StepState struct {
+	Status StepStatus      `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Run is the stored state of a workflow run
+type Run struct {
+	ID       string               `json:"id"`
+	Workflow string               `json:"workflow"`
+	Status   RunStatus            `json:"status"`
+	Input    json.RawMessage      `json:"input,omitempty"`
+	Steps    map[string]StepState `json:"steps"`
+	Created  time.Time            `json:"created"`
+	Updated  time.Time            `json:"updated"`
+}
+
+// StepInput is passed to a step: the run's input and the results of the steps it runs after
+type StepInput struct {
+	Run     string                     `json:"run"`
+	Step    string                     `json:"step"`
+	Input   json.RawMessage            `json:"input,omitempty"`
+	Results map[string]json.RawMessage `json:"results,omitempty"`
+	Errors  map[string]string          `json:"errors,omitempty"`
+}
+
+// StepFunc runs a step and returns its result, which is stored as JSON and passed to the steps after it
+type StepFunc func(context.Context, StepInput) (any, error)
+
+// EngineOpt is a functional option to modify the Engine
+type EngineOpt func(*Engine)
+
+// Engine runs workflows on a queue, keeping the state of each run in a KV bucket. Steps run as ordinary jobs, so
+// they get the queue's priorities and any worker can run them.
+type Engine struct {
+	queue     *Queue
+	kv        nats.KeyValue
+	logger    *slog.Logger
+	now       func() time.Time
+	workflows map[string]*Workflow
+
+	mu    sync.RWMutex
+	steps map[string]StepFunc
+}
+
+// SetEngineLogger sets the logger used to report failed steps
+func SetEngineLogger(l *slog.Logger) EngineOpt {
+	return func(e *Engine) {
+		e.logger = l
+	}
+}
+
+// SetWorkflows registers the workflows the engine can start
+func SetWorkflows(w ...*Workflow) EngineOpt {
+	return func(e *Engine) {
+		for _, v := range w {
+			e.workflows[v.Name] = v
+		}
+	}
+}
+
+// NewEngine returns an Engine enqueuing steps on q and keeping runs in kv
+func NewEngine(q *Queue, kv nats.KeyValue, opts ...EngineOpt) *Engine {
+	e := &Engine{
+		queue:     q,
+		kv:        kv,
+		logger:    slog.Default(),
+		now:       time.Now,
+		workflows: make(map[string]*Workflow),
+		steps:     make(map[string]StepFunc),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Handle sets the function that runs steps of the given job kind
+func (e *Engine) Handle(kind string, fn StepFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.steps[kind] = fn
+}
+
+// Start stores a new run of the workflow and enqueues its first steps
+func (e *Engine) Start(ctx context.Context, workflow string, input any) (Run, error) {
+	w, ok := e.workflows[workflow]
+	if !ok {
+		return Run{}, fmt.Errorf("%w: %s", ErrUnknownWorkflow, workflow)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return Run{}, err
+	}
+
+	now := e.now().UTC()
+	run := Run{
+		ID:       nuid.Next(),
+		Workflow: workflow,
+		Status:   RunRunning,
+		Input:    data,
+		Steps:    make(map[string]StepState, len(w.Steps)),
+		Created:  now,
+		Updated:  now,
+	}
+	for _, s := range w.Steps {
+		run.Steps[s.Name] = StepState{Status: StepPending}
+	}
+	ready := advance(w, &run)
+
+	value, err := json.Marshal(run)
+	if err != nil {
+		return run, err
+	}
+	if _, err := e.kv.Create(run.ID, value); err != nil {
+		return run, err
+	}
+
+	return run, e.enqueue(ctx, w, run, ready)
+}
+
+// Resume enqueues the run's queued steps again. Use it when Start returned an error after storing the run, since
+// its first steps may not have been published. Jobs that were published are deduplicated by message ID.
+func (e *Engine) Resume(ctx context.Context, id string) error {
+	run, err := e.Get(id)
+	if err != nil {
+		return err
+	}
+	w, ok := e.workflows[run.Workflow]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWorkflow, run.Workflow)
+	}
+
+	return e.enqueue(ctx, w, run, queued(w, run, ""))
+}
+
+// Get returns the stored run
+func (e *Engine) Get(id string) (Run, error) {
+	run, _, err := e.get(id)
+	return run, err
+}
+
+func (e *Engine) get(id string) (Run, uint64, error) {
+	var run Run
+	entry, err := e.kv.Get(id)
+	if err != nil {
+		return run, 0, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &run)
+	return run, entry.Revision(), err
+}
+
+// Handler returns the job handler for workflow steps. Pass it to NewWorker.
+func (e *Engine) Handler() Handler {
+	return func(ctx context.Context, j Job) error {
+		runID, stepName := j.Header.Get(WorkflowRunHeader), j.Header.Get(WorkflowStepHeader)
+		if runID == "" || stepName == "" {
+			return fmt.Errorf("job %s is not a workflow step", j.Kind)
+		}
+
+		e.mu.RLock()
+		fn, ok := e.steps[j.Kind]
+		e.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: no step function for %s", ErrUnknownStep, j.Kind)
+		}
+
+		var in StepInput
+		if err := json.Unmarshal(j.Data, &in); err != nil {
+			return e.complete(ctx, runID, stepName, nil, fmt.Errorf("decoding step input: %w", err))
+		}
+
+		result, err := safeStep(ctx, fn, in)
+		if err != nil {
+			run, err2 := e.Get(runID)
+			if err2 != nil {
+				return err2
+			}
+			w, ok := e.workflows[run.Workflow]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownWorkflow, run.Workflow)
+			}
+			step, _ := w.step(stepName)
+			if attempt(j) < max(step.Attempts, 1) {
+				return err
+			}
+			e.logger.Error(fmt.Sprintf("workflow %s step %s failed: %v", run.Workflow, stepName, err))
+		}
+
+		return e.complete(ctx, runID, stepName, result, err)
+	}
+}
+
+func safeStep(ctx context.Context, fn StepFunc, in StepInput) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, in)
+}
+
+// attempt returns which delivery of the job this is
+func attempt(j Job) int {
+	if j.Msg == nil {
+		return 1
+	}
+	meta, err := j.Msg.Metadata()
+	if err != nil {
+		return 1
+	}
+	return int(meta.NumDelivered)
+}
+
+// complete records a step's outcome and enqueues the steps it made ready. Concurrent steps of a run update it
+// with compare and swap, so a step after several others is enqueued exactly once. When enqueueing fails the job
+// is redelivered, and the redelivery enqueues the queued steps after it again. Their jobs have the same message
+// ID, so a job that was published before the failure is dropped as a duplicate.
+func (e *Engine) complete(ctx context.Context, runID, stepName string, result any, stepErr error) error {
+	state := StepState{Status: StepSucceeded}
+	if stepErr != nil {
+		state = StepState{Status: StepFailed, Error: stepErr.Error()}
+	} else {
+		data, err := json.Marshal(result)
+		if err != nil {
+			state = StepState{Status: StepFailed, Error: err.Error()}
+		}
+		state.Result = data
+	}
+
+	for {
+		run, rev, err := e.get(runID)
+		if err != nil {
+			return err
+		}
+		w, ok := e.workflows[run.Workflow]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownWorkflow, run.Workflow)
+		}
+		if _, ok := run.Steps[stepName]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownStep, stepName)
+		}
+		// a redelivered step that was already recorded only makes sure the steps after it were enqueued
+		if run.Steps[stepName].Status.finished() {
+			return e.enqueue(ctx, w, run, queued(w, run, stepName))
+		}
+
+		run.Steps[stepName] = state
+		ready := advance(w, &run)
+		run.Updated = e.now().UTC()
+
+		value, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		_, err = e.kv.Update(runID, value, rev)
+		if errors.Is(err, nats.ErrKeyExists) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		return e.enqueue(ctx, w, run, ready)
+	}
+}
+
+// advance applies failure policies, marks the steps whose dependencies have finished as queued, and returns
+// them. The run's status is set once no step is left to run.
+func advance(w *Workflow, run *Run) []Step {
+	for _, s := range w.Steps {
+		if run.Steps[s.Name].Status == StepFailed && s.OnFailure == FailWorkflow {
+			run.Status = RunFailed
+		}
+	}
+
+	var ready []Step
+	changed := true
+	for changed {
+		changed = false
+		for _, s := range w.Steps {
+			if run.Steps[s.Name].Status != StepPending {
+				continue
+			}
+			if run.Status == RunFailed {
+				run.Steps[s.Name] = StepState{Status: StepSkipped}
+				changed = true
+				continue
+			}
+
+			waiting, skip := false, false
+			for _, dep := range s.After {
+				depStep, _ := w.step(dep)
+				switch st := run.Steps[dep].Status; {
+				case !st.finished():
+					waiting = true
+				case st == StepSkipped, st == StepFailed && depStep.OnFailure != Continue:
+					skip = true
+				}
+			}
+
+			switch {
+			case skip && !waiting:
+				run.Steps[s.Name] = StepState{Status: StepSkipped}
+				changed = true
+			case !waiting:
+				run.Steps[s.Name] = StepState{Status: StepQueued}
+				ready = append(ready, s)
+			}
+		}
+	}
+
+	if run.Status == RunRunning {
+		done, failed := true, false
+		for _, st := range run.Steps {
+			done = done && st.Status.finished()
+			failed = failed || st.Status == StepFailed || st.Status == StepSkipped
+		}
+		if done && failed {
+			run.Status = RunFailed
+		} else if done {
+			run.Status = RunSucceeded
+		}
+	}
+
+	return ready
+}
+
+// queued returns the steps marked queued, limited to the steps running after the named step when it isn't empty
+func queued(w *Workflow, run Run, after string) []Step {
+	var steps []Step
+	for _, s := range w.Steps {
+		if run.Steps[s.Name].Status != StepQueued {
+			continue
+		}
+		if after == "" || slices.Contains(s.After, after) {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// enqueue publishes the jobs for the ready steps with the results of the steps they run after
+func (e *Engine) enqueue(ctx context.Context, w *Workflow, run Run, ready []Step) error {
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Name < ready[j].Name })
+
+	var errs []error
+	for _, s := range ready {
+		in := StepInput{Run: run.ID, Step: s.Name, Input: run.Input}
+		for _, dep := range s.After {
+			st := run.Steps[dep]
+			if st.Status == StepFailed {
+				if in.Errors == nil {
+					in.Errors = make(map[string]string)
+				}
+				in.Errors[dep] = st.Error
+				continue
+			}
+			if in.Results == nil {
+				in.Results = make(map[string]json.RawMessage)
+			}
+			in.Results[dep] = st.Result
+		}
+
+		data, err := json.Marshal(in)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		opts := append(slices.Clone(s.Options),
+			SetHeader(WorkflowRunHeader, run.ID),
+			SetHeader(WorkflowStepHeader, s.Name),
+			SetHeader(nats.MsgIdHdr, run.ID+"."+s.Name),
+		)
+		err = e.queue.Enqueue(ctx, s.Kind, data, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("enqueueing workflow %s step %s: %w", w.Name, s.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
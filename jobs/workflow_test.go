@@ -0,0 +1,276 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go"
+)
+
+// drain runs the enqueued jobs with h until none are left
+func drain(t *testing.T, q *Queue, pub *fakePublisher, h Handler) {
+	t.Helper()
+	for len(pub.msgs) > 0 {
+		msg := pub.msgs[0]
+		pub.msgs = pub.msgs[1:]
+		p, err := ParsePriority(strings.Split(msg.Subject, ".")[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h(context.Background(), q.job(p, msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	tt := []struct {
+		name  string
+		steps []Step
+		err   string
+	}{
+		{name: "valid", steps: []Step{{Name: "a", Kind: "a"}, {Name: "b", Kind: "b", After: []string{"a"}}}},
+		{name: "empty", err: "has no steps"},
+		{name: "duplicate", steps: []Step{{Name: "a", Kind: "a"}, {Name: "a", Kind: "b"}}, err: "duplicate step a"},
+		{name: "no kind", steps: []Step{{Name: "a"}}, err: "step a has no kind"},
+		{name: "duplicate kind", steps: []Step{{Name: "a", Kind: "k"}, {Name: "b", Kind: "k"}}, err: "steps a and b have the same kind k"},
+		{name: "unknown", steps: []Step{{Name: "a", Kind: "a", After: []string{"z"}}}, err: "unknown step z"},
+		{
+			name: "cycle",
+			steps: []Step{
+				{Name: "a", Kind: "a"},
+				{Name: "b", Kind: "b", After: []string{"a", "c"}},
+				{Name: "c", Kind: "c", After: []string{"b"}},
+			},
+			err: "cycle b -> c -> b",
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := NewWorkflow("test", v.steps...)
+			if v.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidWorkflow) || !strings.Contains(err.Error(), v.err) {
+				t.Errorf("expected an error containing %q but got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func diamond(t *testing.T, policy FailurePolicy) *Workflow {
+	t.Helper()
+	w, err := NewWorkflow("orders",
+		Step{Name: "reserve", Kind: "orders.reserve"},
+		Step{Name: "charge", Kind: "orders.charge", After: []string{"reserve"}, OnFailure: policy},
+		Step{Name: "email", Kind: "orders.email", After: []string{"reserve"}, Options: []EnqueueOpt{SetPriority(PriorityLow)}},
+		Step{Name: "ship", Kind: "orders.ship", After: []string{"charge", "email"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestWorkflowRun(t *testing.T) {
+	tt := []struct {
+		name   string
+		fail   bool
+		policy FailurePolicy
+		status RunStatus
+		steps  map[string]StepStatus
+	}{
+		{
+			name:   "succeeded",
+			status: RunSucceeded,
+			steps:  map[string]StepStatus{"reserve": StepSucceeded, "charge": StepSucceeded, "email": StepSucceeded, "ship": StepSucceeded},
+		},
+		{
+			name:   "fail workflow",
+			fail:   true,
+			policy: FailWorkflow,
+			status: RunFailed,
+			steps:  map[string]StepStatus{"reserve": StepSucceeded, "charge": StepFailed, "email": StepSucceeded, "ship": StepSkipped},
+		},
+		{
+			name:   "skip dependents",
+			fail:   true,
+			policy: SkipDependents,
+			status: RunFailed,
+			steps:  map[string]StepStatus{"reserve": StepSucceeded, "charge": StepFailed, "email": StepSucceeded, "ship": StepSkipped},
+		},
+		{
+			name:   "continue",
+			fail:   true,
+			policy: Continue,
+			status: RunFailed,
+			steps:  map[string]StepStatus{"reserve": StepSucceeded, "charge": StepFailed, "email": StepSucceeded, "ship": StepSucceeded},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			q := NewQueue(pub, "jobs")
+			e := NewEngine(q, kvtest.New(), SetWorkflows(diamond(t, v.policy)))
+
+			var shipped StepInput
+			e.Handle("orders.reserve", func(ctx context.Context, in StepInput) (any, error) {
+				var order string
+				err := json.Unmarshal(in.Input, &order)
+				return map[string]string{"reservation": "r-" + order}, err
+			})
+			e.Handle("orders.charge", func(ctx context.Context, in StepInput) (any, error) {
+				if v.fail {
+					return nil, fmt.Errorf("card declined")
+				}
+				return "c-1", nil
+			})
+			e.Handle("orders.email", func(ctx context.Context, in StepInput) (any, error) {
+				return nil, nil
+			})
+			e.Handle("orders.ship", func(ctx context.Context, in StepInput) (any, error) {
+				shipped = in
+				return "s-1", nil
+			})
+
+			run, err := e.Start(context.Background(), "orders", "o-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pub.msgs) != 1 || pub.msgs[0].Subject != "jobs.normal.orders.reserve" {
+				t.Fatalf("expected only the first step to be enqueued")
+			}
+
+			drain(t, q, pub, e.Handler())
+
+			run, err = e.Get(run.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run.Status != v.status {
+				t.Errorf("expected run to be %s but got %s", v.status, run.Status)
+			}
+			for step, want := range v.steps {
+				if got := run.Steps[step].Status; got != want {
+					t.Errorf("expected step %s to be %s but got %s", step, want, got)
+				}
+			}
+
+			if run.Steps["ship"].Status != StepSucceeded {
+				return
+			}
+			if string(shipped.Results["email"]) != "null" {
+				t.Errorf("expected the email result to be passed but got %v", shipped.Results)
+			}
+			if v.fail {
+				if shipped.Errors["charge"] != "card declined" {
+					t.Errorf("expected the charge failure to be passed but got %v", shipped.Errors)
+				}
+				return
+			}
+			if string(shipped.Results["charge"]) != `"c-1"` {
+				t.Errorf("expected the charge result to be passed but got %v", shipped.Results)
+			}
+		})
+	}
+}
+
+// flakyPublisher fails the next fail publishes and drops duplicate message IDs like JetStream
+type flakyPublisher struct {
+	fakePublisher
+	fail int
+	seen map[string]bool
+}
+
+func (f *flakyPublisher) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	if f.fail > 0 {
+		f.fail--
+		return nil, errors.New("nats: timeout")
+	}
+	id := m.Header.Get(nats.MsgIdHdr)
+	if f.seen[id] {
+		return &nats.PubAck{Duplicate: true}, nil
+	}
+	f.seen[id] = true
+	return f.fakePublisher.PublishMsg(m, opts...)
+}
+
+func TestWorkflowEnqueueFailure(t *testing.T) {
+	pub := &flakyPublisher{seen: map[string]bool{}}
+	q := NewQueue(pub, "jobs")
+	e := NewEngine(q, kvtest.New(), SetWorkflows(diamond(t, FailWorkflow)))
+	for _, kind := range []string{"orders.reserve", "orders.charge", "orders.email", "orders.ship"} {
+		e.Handle(kind, func(ctx context.Context, in StepInput) (any, error) { return nil, nil })
+	}
+
+	run, err := e.Start(context.Background(), "orders", "o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserve := pub.msgs[0]
+	pub.msgs = nil
+	job := q.job(PriorityNormal, reserve)
+
+	pub.fail = 1
+	if err := e.Handler()(context.Background(), job); err == nil {
+		t.Fatal("expected the step to fail when its dependents can't be enqueued")
+	}
+	if err := e.Handler()(context.Background(), job); err != nil {
+		t.Fatalf("expected the redelivery to enqueue the dependents but got %v", err)
+	}
+
+	var subjects []string
+	for _, m := range pub.msgs {
+		subjects = append(subjects, m.Subject)
+	}
+	if want := []string{"jobs.low.orders.email", "jobs.normal.orders.charge"}; strings.Join(subjects, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v to be enqueued again but got %v", want, subjects)
+	}
+
+	drain(t, q, &pub.fakePublisher, e.Handler())
+	if run, err = e.Get(run.ID); err != nil || run.Status != RunSucceeded {
+		t.Errorf("expected the run to finish but got %+v %v", run, err)
+	}
+}
+
+func TestWorkflowExport(t *testing.T) {
+	w := diamond(t, FailWorkflow)
+
+	mermaid := w.Mermaid()
+	for _, want := range []string{"flowchart TD", "reserve --> charge", "reserve --> email", "charge --> ship", "email --> ship"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("expected %q in %s", want, mermaid)
+		}
+	}
+
+	dot := w.DOT()
+	for _, want := range []string{`digraph "orders" {`, `"charge" -> "ship";`, `"email" -> "ship";`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected %q in %s", want, dot)
+		}
+	}
+}
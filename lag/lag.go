@@ -0,0 +1,307 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lag watches how far JetStream consumers fall behind their streams and raises an alert when a consumer
+// passes its threshold.
+package lag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JetStream reads stream and consumer state. nats.JetStreamContext implements it.
+type JetStream interface {
+	StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error)
+	ConsumerInfo(stream, consumer string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+}
+
+// Publisher publishes alerts. *nats.Conn implements it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Lag is how far a consumer is behind its stream, in stream sequences
+type Lag struct {
+	Stream       string `json:"stream"`
+	Consumer     string `json:"consumer"`
+	LastSequence uint64 `json:"last_sequence"`
+	Delivered    uint64 `json:"delivered"`
+	AckFloor     uint64 `json:"ack_floor"`
+	// Undelivered is how many stream sequences haven't been delivered to the consumer
+	Undelivered uint64 `json:"undelivered"`
+	// Unacked is how many stream sequences the consumer hasn't acknowledged, delivered or not
+	Unacked   uint64    `json:"unacked"`
+	Threshold uint64    `json:"threshold"`
+	Exceeded  bool      `json:"exceeded"`
+	Error     string    `json:"error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+// AlertFunc is called when a consumer's lag passes its threshold or falls back under it
+type AlertFunc func(context.Context, Lag)
+
+// PublishAlert returns an AlertFunc publishing the lag as JSON to subject
+func PublishAlert(pub Publisher, subject string) AlertFunc {
+	return func(ctx context.Context, l Lag) {
+		data, err := json.Marshal(l)
+		if err != nil {
+			return
+		}
+		pub.Publish(subject, data)
+	}
+}
+
+// MonitorOpt is a functional option to modify the Monitor
+type MonitorOpt func(*Monitor)
+
+// Monitor periodically compares each watched consumer's delivered sequence and ack floor with its stream's last
+// sequence. A consumer exceeds its threshold when its unacknowledged lag is over it for several checks in a row,
+// so one burst of publishes doesn't raise an alert.
+type Monitor struct {
+	js          JetStream
+	interval    time.Duration
+	timeout     time.Duration
+	exceedAfter int
+	onExceeded  AlertFunc
+	onRecovered AlertFunc
+	logger      *slog.Logger
+	now         func() time.Time
+
+	undelivered *prometheus.GaugeVec
+	unacked     *prometheus.GaugeVec
+	exceeded    *prometheus.GaugeVec
+	failures    *prometheus.CounterVec
+
+	mu      sync.Mutex
+	watched []*watched
+}
+
+type watched struct {
+	Lag
+	over int
+}
+
+// SetInterval sets how often lag is checked. The default is 30 seconds.
+func SetInterval(d time.Duration) MonitorOpt {
+	return func(m *Monitor) {
+		m.interval = d
+	}
+}
+
+// SetTimeout sets the deadline of a single check. The default is 5 seconds.
+func SetTimeout(d time.Duration) MonitorOpt {
+	return func(m *Monitor) {
+		m.timeout = d
+	}
+}
+
+// SetExceedAfter sets how many checks in a row a consumer must be over its threshold before it alerts. The
+// default is 2.
+func SetExceedAfter(n int) MonitorOpt {
+	return func(m *Monitor) {
+		if n > 0 {
+			m.exceedAfter = n
+		}
+	}
+}
+
+// SetOnExceeded sets what is called when a consumer passes its threshold
+func SetOnExceeded(fn AlertFunc) MonitorOpt {
+	return func(m *Monitor) {
+		m.onExceeded = fn
+	}
+}
+
+// SetOnRecovered sets what is called when a consumer that exceeded its threshold falls back under it
+func SetOnRecovered(fn AlertFunc) MonitorOpt {
+	return func(m *Monitor) {
+		m.onRecovered = fn
+	}
+}
+
+// SetLogger sets the logger used to report lag alerts and failed checks
+func SetLogger(l *slog.Logger) MonitorOpt {
+	return func(m *Monitor) {
+		m.logger = l
+	}
+}
+
+// NewMonitor returns a Monitor reading stream and consumer state from js
+func NewMonitor(js JetStream, opts ...MonitorOpt) *Monitor {
+	labels := []string{"stream", "consumer"}
+	m := &Monitor{
+		js:          js,
+		interval:    30 * time.Second,
+		timeout:     5 * time.Second,
+		exceedAfter: 2,
+		logger:      slog.Default(),
+		now:         time.Now,
+		undelivered: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "consumer_lag_undelivered",
+			Help: "Stream sequences not yet delivered to the consumer",
+		}, labels),
+		unacked: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "consumer_lag_unacked",
+			Help: "Stream sequences the consumer hasn't acknowledged",
+		}, labels),
+		exceeded: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "consumer_lag_exceeded",
+			Help: "Whether the consumer's lag is over its threshold",
+		}, labels),
+		failures: metrics.NewCounterVec("consumer_lag_check_failures", "Lag checks that couldn't read the stream or consumer", labels),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Metrics returns the monitor's collectors to register with the exporter
+func (m *Monitor) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{m.undelivered, m.unacked, m.exceeded, m.failures}
+}
+
+// Watch adds a consumer to check. A threshold of 0 only exports its lag.
+func (m *Monitor) Watch(stream, consumer string, threshold uint64) *Monitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watched = append(m.watched, &watched{Lag: Lag{Stream: stream, Consumer: consumer, Threshold: threshold, Since: m.now().UTC()}})
+	m.exceeded.WithLabelValues(stream, consumer).Set(0)
+	return m
+}
+
+// Statuses returns each watched consumer's lag as of the last check
+func (m *Monitor) Statuses() []Lag {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]Lag, len(m.watched))
+	for i, w := range m.watched {
+		statuses[i] = w.Lag
+	}
+	return statuses
+}
+
+// Run checks lag on the interval until ctx is done
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check reads every watched consumer once, updates the metrics, and calls the alert functions for consumers
+// that passed or fell back under their threshold
+func (m *Monitor) Check(ctx context.Context) {
+	m.mu.Lock()
+	watching := append([]*watched(nil), m.watched...)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	streams := make(map[string]*nats.StreamInfo)
+	var alerts []func()
+	for _, w := range watching {
+		si, ok := streams[w.Stream]
+		if !ok {
+			var err error
+			si, err = m.js.StreamInfo(w.Stream, nats.Context(ctx))
+			if err != nil {
+				m.failed(w, fmt.Errorf("reading stream %s: %w", w.Stream, err))
+				continue
+			}
+			streams[w.Stream] = si
+		}
+		ci, err := m.js.ConsumerInfo(w.Stream, w.Consumer, nats.Context(ctx))
+		if err != nil {
+			m.failed(w, fmt.Errorf("reading consumer %s: %w", w.Consumer, err))
+			continue
+		}
+		if alert := m.apply(w, si.State.LastSeq, ci); alert != nil {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	for _, alert := range alerts {
+		alert()
+	}
+}
+
+func (m *Monitor) failed(w *watched, err error) {
+	m.failures.WithLabelValues(w.Stream, w.Consumer).Inc()
+	m.logger.Warn(fmt.Sprintf("checking lag of %s on %s: %v", w.Consumer, w.Stream, err))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Error = err.Error()
+}
+
+// apply records a consumer's lag and returns the alert to send when it changed state
+func (m *Monitor) apply(w *watched, last uint64, ci *nats.ConsumerInfo) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.LastSequence, w.Delivered, w.AckFloor, w.Error = last, ci.Delivered.Stream, ci.AckFloor.Stream, ""
+	w.Undelivered = last - min(last, w.Delivered)
+	w.Unacked = last - min(last, w.AckFloor)
+	m.undelivered.WithLabelValues(w.Stream, w.Consumer).Set(float64(w.Undelivered))
+	m.unacked.WithLabelValues(w.Stream, w.Consumer).Set(float64(w.Unacked))
+
+	if w.Threshold == 0 {
+		return nil
+	}
+	if w.Unacked <= w.Threshold {
+		w.over = 0
+		if !w.Exceeded {
+			return nil
+		}
+		w.Exceeded, w.Since = false, m.now().UTC()
+		m.exceeded.WithLabelValues(w.Stream, w.Consumer).Set(0)
+		m.logger.Info(fmt.Sprintf("consumer %s on %s recovered with lag %d", w.Consumer, w.Stream, w.Unacked))
+		return m.alert(m.onRecovered, w.Lag)
+	}
+
+	w.over++
+	if w.Exceeded || w.over < m.exceedAfter {
+		return nil
+	}
+	w.Exceeded, w.Since = true, m.now().UTC()
+	m.exceeded.WithLabelValues(w.Stream, w.Consumer).Set(1)
+	m.logger.Warn(fmt.Sprintf("consumer %s on %s is %d messages behind, over its threshold of %d", w.Consumer, w.Stream, w.Unacked, w.Threshold))
+	return m.alert(m.onExceeded, w.Lag)
+}
+
+func (m *Monitor) alert(fn AlertFunc, l Lag) func() {
+	if fn == nil {
+		return nil
+	}
+	return func() { fn(context.Background(), l) }
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeJetStream struct {
+	last      uint64
+	delivered uint64
+	ackFloor  uint64
+	err       error
+}
+
+func (f *fakeJetStream) StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &nats.StreamInfo{State: nats.StreamState{LastSeq: f.last}}, nil
+}
+
+func (f *fakeJetStream) ConsumerInfo(stream, consumer string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	return &nats.ConsumerInfo{
+		Delivered: nats.SequenceInfo{Stream: f.delivered},
+		AckFloor:  nats.SequenceInfo{Stream: f.ackFloor},
+	}, nil
+}
+
+type fakePublisher struct {
+	subjects []string
+	alerts   []Lag
+}
+
+func (f *fakePublisher) Publish(subject string, data []byte) error {
+	var l Lag
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	f.subjects = append(f.subjects, subject)
+	f.alerts = append(f.alerts, l)
+	return nil
+}
+
+func TestMonitor(t *testing.T) {
+	js := &fakeJetStream{}
+	pub := &fakePublisher{}
+	var recovered []Lag
+	m := NewMonitor(js,
+		SetExceedAfter(2),
+		SetOnExceeded(PublishAlert(pub, "alerts.lag")),
+		SetOnRecovered(func(ctx context.Context, l Lag) { recovered = append(recovered, l) }),
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	).Watch("ORDERS", "fulfillment", 100)
+	reg := sdtest.NewRegistry(t, m.Metrics()...)
+	labels := prometheus.Labels{"stream": "ORDERS", "consumer": "fulfillment"}
+	ctx := context.Background()
+
+	tt := []struct {
+		name        string
+		last        uint64
+		delivered   uint64
+		ackFloor    uint64
+		err         error
+		undelivered float64
+		unacked     float64
+		exceeded    bool
+		alerts      int
+		recovered   int
+	}{
+		{name: "caught up", last: 50, delivered: 50, ackFloor: 50},
+		{name: "behind once", last: 300, delivered: 250, ackFloor: 150, undelivered: 50, unacked: 150},
+		{name: "behind twice", last: 400, delivered: 350, ackFloor: 200, undelivered: 50, unacked: 200, exceeded: true, alerts: 1},
+		{name: "still behind", last: 500, delivered: 450, ackFloor: 300, undelivered: 50, unacked: 200, exceeded: true, alerts: 1},
+		{name: "stream unavailable", err: errors.New("timeout"), undelivered: 50, unacked: 200, exceeded: true, alerts: 1},
+		{name: "recovered", last: 500, delivered: 500, ackFloor: 450, unacked: 50, alerts: 1, recovered: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			js.last, js.delivered, js.ackFloor, js.err = v.last, v.delivered, v.ackFloor, v.err
+			m.Check(ctx)
+
+			reg.AssertGauge(t, "consumer_lag_undelivered", labels, v.undelivered)
+			reg.AssertGauge(t, "consumer_lag_unacked", labels, v.unacked)
+			exceeded := 0.0
+			if v.exceeded {
+				exceeded = 1
+			}
+			reg.AssertGauge(t, "consumer_lag_exceeded", labels, exceeded)
+			if status := m.Statuses()[0]; status.Exceeded != v.exceeded || (status.Error != "") != (v.err != nil) {
+				t.Errorf("unexpected status %+v", status)
+			}
+			if len(pub.alerts) != v.alerts || len(recovered) != v.recovered {
+				t.Errorf("expected %d alerts and %d recoveries but got %d and %d", v.alerts, v.recovered, len(pub.alerts), len(recovered))
+			}
+		})
+	}
+
+	if pub.subjects[0] != "alerts.lag" || pub.alerts[0].Unacked != 200 || pub.alerts[0].Threshold != 100 {
+		t.Errorf("unexpected alert %s %+v", pub.subjects[0], pub.alerts[0])
+	}
+	reg.AssertCounter(t, "consumer_lag_check_failures", labels, 1)
+}
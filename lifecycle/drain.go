@@ -0,0 +1,264 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DrainReason is the reason readiness is held while draining
+const DrainReason = "draining"
+
+// DrainerOpt is a functional option to modify the Drainer
+type DrainerOpt func(*Drainer)
+
+// Drainer coordinates a pod's termination so rolling updates don't drop requests. Draining flips readiness to
+// not ready and keeps serving for the deregistration delay, while Kubernetes removes the pod from its endpoints
+// and load balancers stop sending to it. Only then does the shutdown hook stop the servers, with the rest of the
+// termination grace period as its deadline.
+type Drainer struct {
+	readiness Holder
+	delay     time.Duration
+	grace     time.Duration
+	hook      func(context.Context) error
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu      sync.Mutex
+	started time.Time
+	begun   chan struct{}
+	done    chan struct{}
+
+	draining prometheus.Gauge
+}
+
+// SetDrainReadiness sets what draining holds, usually the Sequencer
+func SetDrainReadiness(h Holder) DrainerOpt {
+	return func(d *Drainer) {
+		d.readiness = h
+	}
+}
+
+// SetDeregistrationDelay sets how long to keep serving after readiness flips. It should cover a failing
+// readiness probe plus the time for endpoint changes to reach kube-proxy and ingress controllers. It defaults to
+// 5 seconds.
+func SetDeregistrationDelay(delay time.Duration) DrainerOpt {
+	return func(d *Drainer) {
+		d.delay = delay
+	}
+}
+
+// SetGracePeriod sets the pod's terminationGracePeriodSeconds. Kubernetes kills the process that long after
+// termination starts, preStop hook included. It defaults to 30 seconds.
+func SetGracePeriod(grace time.Duration) DrainerOpt {
+	return func(d *Drainer) {
+		d.grace = grace
+	}
+}
+
+// SetDrainHook sets what stops the process once the deregistration delay has passed, usually app.Shutdown
+func SetDrainHook(fn func(context.Context) error) DrainerOpt {
+	return func(d *Drainer) {
+		d.hook = fn
+	}
+}
+
+// SetDrainLogger sets the logger used to report drain progress
+func SetDrainLogger(l *slog.Logger) DrainerOpt {
+	return func(d *Drainer) {
+		d.logger = l
+	}
+}
+
+// NewDrainer returns a Drainer
+func NewDrainer(opts ...DrainerOpt) *Drainer {
+	d := &Drainer{
+		delay:  5 * time.Second,
+		grace:  30 * time.Second,
+		logger: slog.Default(),
+		now:    time.Now,
+		begun:  make(chan struct{}),
+		done:   make(chan struct{}),
+		draining: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "draining",
+			Help: "Whether the instance is draining before shutdown",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Metrics returns the drainer's collectors
+func (d *Drainer) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{d.draining}
+}
+
+// Begin starts draining if it hasn't started and returns when it started. It doesn't block.
+func (d *Drainer) Begin() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.started.IsZero() {
+		return d.started
+	}
+
+	d.started = d.now()
+	close(d.begun)
+	d.draining.Set(1)
+	if d.readiness != nil {
+		d.readiness.Hold(DrainReason)
+	}
+	d.logger.Info(fmt.Sprintf("draining, shutting down in %s", d.delay))
+
+	go func() {
+		time.Sleep(d.delay)
+		close(d.done)
+	}()
+	return d.started
+}
+
+// Draining reports whether draining has started
+func (d *Drainer) Draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.started.IsZero()
+}
+
+// Ready reports whether the readiness probe should pass. It fails once draining starts, and while the readiness
+// set with SetDrainReadiness isn't ready, when it reports readiness like the Sequencer does.
+func (d *Drainer) Ready() bool {
+	if d.Draining() {
+		return false
+	}
+	if r, ok := d.readiness.(interface{ Ready() bool }); ok {
+		return r.Ready()
+	}
+	return true
+}
+
+// Deregistered returns a channel closed once the deregistration delay has passed since draining started
+func (d *Drainer) Deregistered() <-chan struct{} {
+	return d.done
+}
+
+// Deadline returns when Kubernetes will kill the process, the grace period after draining started
+func (d *Drainer) Deadline() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.started.Add(d.grace)
+}
+
+// Run waits for SIGTERM, SIGINT, ctx to be done, or draining to start, drains, and then runs the shutdown hook with
+// a deadline a second before the grace period ends. When a preStop hook or POST /drain already started draining,
+// only the rest of the delay is waited out.
+func (d *Drainer) Run(ctx context.Context) error {
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-sigCtx.Done():
+	case <-d.begun:
+	}
+
+	d.Begin()
+	<-d.done
+
+	if d.hook == nil {
+		return nil
+	}
+	hookCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), d.Deadline().Add(-time.Second))
+	defer cancel()
+	d.logger.Info(fmt.Sprintf("shutting down with %s left of the grace period", time.Until(d.Deadline()).Round(time.Millisecond)))
+	return d.hook(hookCtx)
+}
+
+type drainStatus struct {
+	Draining bool       `json:"draining"`
+	Started  *time.Time `json:"started,omitempty"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+func (d *Drainer) status() drainStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started.IsZero() {
+		return drainStatus{}
+	}
+	started, deadline := d.started, d.started.Add(d.grace)
+	return drainStatus{Draining: true, Started: &started, Deadline: &deadline}
+}
+
+// Routes returns the drain endpoints. Mount them on an admin port that isn't exposed outside the pod.
+//
+//	GET  /readyz  responds with a 503 once draining starts, for the readiness probe
+//	GET  /prestop starts draining and responds once the deregistration delay has passed, for a preStop httpGet hook
+//	POST /drain   starts draining and responds right away
+//	GET  /drain   reports whether the instance is draining
+func (d *Drainer) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/readyz",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				code := http.StatusOK
+				if !d.Ready() {
+					code = http.StatusServiceUnavailable
+				}
+				return sdhttp.Respond(w, r, code, d.status())
+			}},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/prestop",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				d.Begin()
+				select {
+				case <-d.done:
+				case <-r.Context().Done():
+					return nil
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, d.status())
+			}},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/drain",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				d.Begin()
+				return sdhttp.Respond(w, r, http.StatusAccepted, d.status())
+			}},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/drain",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return sdhttp.Respond(w, r, http.StatusOK, d.status())
+			}},
+		},
+	}
+}
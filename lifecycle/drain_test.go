@@ -0,0 +1,146 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+func drainRoute(t *testing.T, d *Drainer, method, path string) http.Handler {
+	t.Helper()
+	for _, r := range d.Routes() {
+		if r.Method == method && r.Path == path {
+			return r.Handler
+		}
+	}
+	t.Fatalf("no route %s %s", method, path)
+	return nil
+}
+
+func TestDrainerPreStop(t *testing.T) {
+	seq := NewSequencer()
+	if err := seq.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDrainer(SetDrainReadiness(seq), SetDeregistrationDelay(50*time.Millisecond))
+	reg := sdtest.NewRegistry(t, d.Metrics()...)
+	readyz := drainRoute(t, d, http.MethodGet, "/readyz")
+
+	tt := []struct {
+		name  string
+		req   func()
+		ready bool
+	}{
+		{name: "serving", req: func() {}, ready: true},
+		{name: "prestop", req: func() {
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			drainRoute(t, d, http.MethodGet, "/prestop").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prestop", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected 200 but got %d", rec.Code)
+			}
+			if waited := time.Since(start); waited < 50*time.Millisecond {
+				t.Errorf("expected preStop to wait out the deregistration delay but it returned after %s", waited)
+			}
+		}},
+		{name: "drain again", req: func() {
+			rec := httptest.NewRecorder()
+			drainRoute(t, d, http.MethodPost, "/drain").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/drain", nil))
+			if rec.Code != http.StatusAccepted {
+				t.Errorf("expected 202 but got %d", rec.Code)
+			}
+		}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			v.req()
+
+			rec := httptest.NewRecorder()
+			readyz.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			want := http.StatusServiceUnavailable
+			if v.ready {
+				want = http.StatusOK
+			}
+			if rec.Code != want {
+				t.Errorf("expected readiness probe to respond %d but got %d", want, rec.Code)
+			}
+			if seq.Ready() != v.ready {
+				t.Errorf("expected sequencer ready to be %v", v.ready)
+			}
+			gauge := 1.0
+			if v.ready {
+				gauge = 0
+			}
+			reg.AssertGauge(t, "draining", nil, gauge)
+		})
+	}
+}
+
+func TestDrainerRun(t *testing.T) {
+	tt := []struct {
+		name     string
+		prestop  bool
+		manual   bool
+		minDelay time.Duration
+	}{
+		{name: "sigterm", minDelay: 50 * time.Millisecond},
+		{name: "after prestop", prestop: true},
+		{name: "manual drain", manual: true, minDelay: 40 * time.Millisecond},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var hookDeadline time.Time
+			d := NewDrainer(
+				SetDeregistrationDelay(50*time.Millisecond),
+				SetGracePeriod(10*time.Second),
+				SetDrainHook(func(ctx context.Context) error {
+					hookDeadline, _ = ctx.Deadline()
+					return nil
+				}),
+			)
+			if v.prestop {
+				d.Begin()
+				<-d.Deregistered()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			start := time.Now()
+			if v.manual {
+				// POST /drain starts draining without a signal
+				d.Begin()
+			} else {
+				cancel()
+			}
+			if err := d.Run(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if waited := time.Since(start); waited < v.minDelay {
+				t.Errorf("expected to wait at least %s but waited %s", v.minDelay, waited)
+			}
+			if want := d.Deadline().Add(-time.Second); !hookDeadline.Equal(want) {
+				t.Errorf("expected the hook deadline to be %s but got %s", want, hookDeadline)
+			}
+		})
+	}
+}
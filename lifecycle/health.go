@@ -0,0 +1,295 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Holder holds readiness for a reason until it is released. Sequencer implements it.
+type Holder interface {
+	Hold(reason string)
+	Release(reason string)
+}
+
+// DependencyStatus is the health of a monitored dependency
+type DependencyStatus struct {
+	Name     string    `json:"name"`
+	Critical bool      `json:"critical"`
+	Healthy  bool      `json:"healthy"`
+	Error    string    `json:"error,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+type monitored struct {
+	DependencyStatus
+	check     ReadyFunc
+	failures  int
+	successes int
+}
+
+// MonitorOpt is a functional option to modify the Monitor
+type MonitorOpt func(*Monitor)
+
+// Monitor checks dependencies after startup. A critical dependency that goes unhealthy holds readiness until it
+// recovers, while a soft one only marks the service degraded. A dependency changes state only after several
+// checks in a row agree, so one slow check doesn't flap readiness.
+type Monitor struct {
+	readiness    Holder
+	interval     time.Duration
+	timeout      time.Duration
+	failAfter    int
+	recoverAfter int
+	logger       *slog.Logger
+	now          func() time.Time
+
+	healthy  *prometheus.GaugeVec
+	degraded prometheus.Gauge
+
+	mu   sync.Mutex
+	deps []*monitored
+}
+
+// SetMonitorReadiness sets what critical dependencies hold, usually the Sequencer
+func SetMonitorReadiness(h Holder) MonitorOpt {
+	return func(m *Monitor) {
+		m.readiness = h
+	}
+}
+
+// SetMonitorInterval sets how often dependencies are checked. The default is 10 seconds.
+func SetMonitorInterval(d time.Duration) MonitorOpt {
+	return func(m *Monitor) {
+		m.interval = d
+	}
+}
+
+// SetMonitorTimeout sets the deadline of a single check. The default is 5 seconds.
+func SetMonitorTimeout(d time.Duration) MonitorOpt {
+	return func(m *Monitor) {
+		m.timeout = d
+	}
+}
+
+// SetFailAfter sets how many failed checks in a row mark a dependency unhealthy. The default is 3.
+func SetFailAfter(n int) MonitorOpt {
+	return func(m *Monitor) {
+		if n > 0 {
+			m.failAfter = n
+		}
+	}
+}
+
+// SetRecoverAfter sets how many passing checks in a row mark a dependency healthy again. The default is 2.
+func SetRecoverAfter(n int) MonitorOpt {
+	return func(m *Monitor) {
+		if n > 0 {
+			m.recoverAfter = n
+		}
+	}
+}
+
+// SetMonitorLogger sets the logger used to report dependency state changes
+func SetMonitorLogger(l *slog.Logger) MonitorOpt {
+	return func(m *Monitor) {
+		m.logger = l
+	}
+}
+
+// NewMonitor returns a Monitor. Dependencies start healthy, since the Sequencer already waited for them.
+func NewMonitor(opts ...MonitorOpt) *Monitor {
+	m := &Monitor{
+		interval:     10 * time.Second,
+		timeout:      5 * time.Second,
+		failAfter:    3,
+		recoverAfter: 2,
+		logger:       slog.Default(),
+		now:          time.Now,
+		healthy: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "dependency_healthy",
+			Help: "Whether a dependency is healthy",
+		}, []string{"dependency", "criticality"}),
+		degraded: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "service_degraded",
+			Help: "Whether a soft dependency is unhealthy",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Metrics returns the monitor's collectors to register with the exporter
+func (m *Monitor) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{m.healthy, m.degraded}
+}
+
+// Critical adds a dependency the service can't serve without
+func (m *Monitor) Critical(name string, check ReadyFunc) *Monitor {
+	return m.add(name, true, check)
+}
+
+// Soft adds a dependency the service can serve without, such as a cache
+func (m *Monitor) Soft(name string, check ReadyFunc) *Monitor {
+	return m.add(name, false, check)
+}
+
+func (m *Monitor) add(name string, critical bool, check ReadyFunc) *Monitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deps = append(m.deps, &monitored{
+		DependencyStatus: DependencyStatus{Name: name, Critical: critical, Healthy: true, Since: m.now().UTC()},
+		check:            check,
+	})
+	m.healthy.WithLabelValues(name, criticality(critical)).Set(1)
+
+	return m
+}
+
+func criticality(critical bool) string {
+	if critical {
+		return "critical"
+	}
+	return "soft"
+}
+
+// Run checks the dependencies on the interval until ctx is done
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.checkAll(ctx)
+	}
+}
+
+// checkAll checks every dependency concurrently and applies the results
+func (m *Monitor) checkAll(ctx context.Context) {
+	m.mu.Lock()
+	deps := append([]*monitored(nil), m.deps...)
+	m.mu.Unlock()
+
+	errs := make([]error, len(deps))
+	var wg sync.WaitGroup
+	for i, d := range deps {
+		wg.Add(1)
+		go func(i int, check ReadyFunc) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+			defer cancel()
+			errs[i] = check(checkCtx)
+		}(i, d.check)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, d := range deps {
+		m.apply(d, errs[i])
+	}
+	m.degraded.Set(0)
+	for _, d := range m.deps {
+		if !d.Critical && !d.Healthy {
+			m.degraded.Set(1)
+		}
+	}
+}
+
+// apply records a check result and changes the dependency's state once enough checks in a row agree
+func (m *Monitor) apply(d *monitored, err error) {
+	if err == nil {
+		d.failures = 0
+		d.successes++
+		if d.Healthy || d.successes < m.recoverAfter {
+			return
+		}
+		d.Healthy, d.Error, d.Since = true, "", m.now().UTC()
+		m.healthy.WithLabelValues(d.Name, criticality(d.Critical)).Set(1)
+		m.logger.Info(fmt.Sprintf("dependency %s recovered", d.Name))
+		if d.Critical && m.readiness != nil {
+			m.readiness.Release(holdReason(d.Name))
+		}
+		return
+	}
+
+	d.successes = 0
+	d.failures++
+	if !d.Healthy {
+		d.Error = err.Error()
+		return
+	}
+	if d.failures < m.failAfter {
+		m.logger.Warn(fmt.Sprintf("dependency %s check failed (%d/%d): %v", d.Name, d.failures, m.failAfter, err))
+		return
+	}
+
+	d.Healthy, d.Error, d.Since = false, err.Error(), m.now().UTC()
+	m.healthy.WithLabelValues(d.Name, criticality(d.Critical)).Set(0)
+	if !d.Critical {
+		m.logger.Warn(fmt.Sprintf("soft dependency %s unhealthy, service degraded: %v", d.Name, err))
+		return
+	}
+	m.logger.Error(fmt.Sprintf("critical dependency %s unhealthy: %v", d.Name, err))
+	if m.readiness != nil {
+		m.readiness.Hold(holdReason(d.Name))
+	}
+}
+
+func holdReason(name string) string {
+	return fmt.Sprintf("dependency %s unhealthy", name)
+}
+
+// Statuses returns the status of every dependency sorted by name
+func (m *Monitor) Statuses() []DependencyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]DependencyStatus, 0, len(m.deps))
+	for _, d := range m.deps {
+		statuses = append(statuses, d.DependencyStatus)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses
+}
+
+// Degraded reports whether a soft dependency is unhealthy
+func (m *Monitor) Degraded() bool {
+	for _, s := range m.Statuses() {
+		if !s.Critical && !s.Healthy {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMonitor(t *testing.T) {
+	seq := NewSequencer()
+	if err := seq.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var dbDown, cacheDown atomic.Bool
+	check := func(down *atomic.Bool) ReadyFunc {
+		return func(context.Context) error {
+			if down.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+	}
+
+	m := NewMonitor(SetMonitorReadiness(seq), SetFailAfter(2), SetRecoverAfter(2)).
+		Critical("db", check(&dbDown)).
+		Soft("cache", check(&cacheDown))
+	reg := sdtest.NewRegistry(t, m.Metrics()...)
+	ctx := context.Background()
+
+	tt := []struct {
+		name      string
+		dbDown    bool
+		cacheDown bool
+		ready     bool
+		degraded  bool
+	}{
+		{name: "healthy", ready: true},
+		{name: "one failure", dbDown: true, cacheDown: true, ready: true},
+		{name: "threshold", dbDown: true, cacheDown: true, degraded: true},
+		{name: "one success", degraded: true},
+		{name: "flap", dbDown: true, cacheDown: true, degraded: true},
+		{name: "first of two", degraded: true},
+		{name: "recovered", ready: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			dbDown.Store(v.dbDown)
+			cacheDown.Store(v.cacheDown)
+			m.checkAll(ctx)
+
+			if seq.Ready() != v.ready {
+				t.Errorf("expected ready to be %v", v.ready)
+			}
+			if m.Degraded() != v.degraded {
+				t.Errorf("expected degraded to be %v", v.degraded)
+			}
+			want := 0.0
+			if v.degraded {
+				want = 1
+			}
+			reg.AssertGauge(t, "service_degraded", nil, want)
+			reg.AssertGauge(t, "dependency_healthy", prometheus.Labels{"dependency": "db", "criticality": "critical"}, 1-want)
+		})
+	}
+}
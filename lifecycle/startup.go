@@ -0,0 +1,249 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+var (
+	ErrStartupTimeout = fmt.Errorf("startup timed out waiting for dependencies")
+	ErrNotReady       = fmt.Errorf("service is not ready")
+)
+
+// ReadyFunc returns nil once a dependency is ready to be used
+type ReadyFunc func(context.Context) error
+
+// Dependency is something that must be ready before the service accepts requests
+type Dependency struct {
+	Name  string
+	Ready ReadyFunc
+}
+
+// SequencerOpt is a functional option to modify the Sequencer
+type SequencerOpt func(*Sequencer)
+
+// Sequencer waits for declared dependencies to be ready before registering endpoints and marking the
+// service ready
+type Sequencer struct {
+	deps     []Dependency
+	timeout  time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+	ready    atomic.Bool
+
+	mu    sync.Mutex
+	holds map[string]struct{}
+}
+
+// NewSequencer returns a Sequencer with a 30 second startup timeout
+func NewSequencer(opts ...SequencerOpt) *Sequencer {
+	s := &Sequencer{
+		timeout:  30 * time.Second,
+		interval: 500 * time.Millisecond,
+		logger:   slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// SetStartupTimeout sets how long Start waits for dependencies before aborting
+func SetStartupTimeout(d time.Duration) SequencerOpt {
+	return func(s *Sequencer) {
+		s.timeout = d
+	}
+}
+
+// SetPollInterval sets how often a dependency that isn't ready is checked again
+func SetPollInterval(d time.Duration) SequencerOpt {
+	return func(s *Sequencer) {
+		s.interval = d
+	}
+}
+
+// SetLogger sets the logger used to report startup progress
+func SetLogger(l *slog.Logger) SequencerOpt {
+	return func(s *Sequencer) {
+		s.logger = l
+	}
+}
+
+// Require declares a dependency that must be ready before Start registers endpoints
+func (s *Sequencer) Require(name string, ready ReadyFunc) *Sequencer {
+	s.deps = append(s.deps, Dependency{Name: name, Ready: ready})
+	return s
+}
+
+// Ready reports whether Start has completed successfully and nothing is holding readiness
+func (s *Sequencer) Ready() bool {
+	if !s.ready.Load() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.holds) == 0
+}
+
+// Hold marks the service not ready until Release is called with the same reason, such as while its NATS
+// connection moves off a server in lame duck mode
+func (s *Sequencer) Hold(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holds == nil {
+		s.holds = make(map[string]struct{})
+	}
+	s.holds[reason] = struct{}{}
+	s.logger.Info(fmt.Sprintf("readiness held: %s", reason))
+}
+
+// Release removes a hold placed with Hold
+func (s *Sequencer) Release(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.holds[reason]; !ok {
+		return
+	}
+	delete(s.holds, reason)
+	s.logger.Info(fmt.Sprintf("readiness released: %s", reason))
+}
+
+// Start waits for every dependency to be ready, calls the register functions in order, and then marks the
+// sequencer ready. If the dependencies aren't ready before the startup timeout, an error wrapping
+// ErrStartupTimeout lists the dependencies that weren't ready and their last error.
+func (s *Sequencer) Start(ctx context.Context, register ...func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	pending := make(map[string]error, len(s.deps))
+	for _, d := range s.deps {
+		pending[d.Name] = ErrNotReady
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range s.deps {
+		wg.Add(1)
+		go func(d Dependency) {
+			defer wg.Done()
+			err := s.wait(ctx, d)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				delete(pending, d.Name)
+				return
+			}
+			pending[d.Name] = err
+		}(d)
+	}
+	wg.Wait()
+
+	if len(pending) > 0 {
+		return startupError(ctx, pending)
+	}
+
+	for _, r := range register {
+		if err := r(); err != nil {
+			return fmt.Errorf("error registering endpoints: %w", err)
+		}
+	}
+
+	s.ready.Store(true)
+	s.logger.Info("all dependencies ready")
+
+	return nil
+}
+
+// wait polls the dependency until it is ready or the context is done, returning the last error
+func (s *Sequencer) wait(ctx context.Context, d Dependency) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		err := d.Ready(ctx)
+		if err == nil {
+			s.logger.Info(fmt.Sprintf("dependency %s ready", d.Name))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+func startupError(ctx context.Context, pending map[string]error) error {
+	names := make([]string, 0, len(pending))
+	for k := range pending {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	details := make([]string, 0, len(names))
+	for _, n := range names {
+		details = append(details, fmt.Sprintf("%s: %v", n, pending[n]))
+	}
+
+	base := ErrStartupTimeout
+	if errors.Is(ctx.Err(), context.Canceled) {
+		base = ctx.Err()
+	}
+
+	return fmt.Errorf("%w: %s", base, strings.Join(details, "; "))
+}
+
+// Middleware responds with a 503 until the sequencer is ready
+func (s *Sequencer) Middleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !s.Ready() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, ErrNotReady.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// MicroHandler responds with a 503 error until the sequencer is ready
+func (s *Sequencer) MicroHandler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		if !s.Ready() {
+			r.Error("503", http.StatusText(http.StatusServiceUnavailable), []byte(fmt.Sprintf(`{"errors": [%q]}`, ErrNotReady.Error())))
+			return
+		}
+
+		h.Handle(r)
+	})
+}
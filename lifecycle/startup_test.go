@@ -0,0 +1,110 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSequencerStart(t *testing.T) {
+	var calls atomic.Int32
+	eventually := func(context.Context) error {
+		if calls.Add(1) < 3 {
+			return fmt.Errorf("not yet")
+		}
+		return nil
+	}
+	never := func(context.Context) error {
+		return fmt.Errorf("connection refused")
+	}
+
+	tt := []struct {
+		name string
+		deps map[string]ReadyFunc
+		err  error
+	}{
+		{name: "ready", deps: map[string]ReadyFunc{"db": eventually}},
+		{name: "timeout", deps: map[string]ReadyFunc{"db": never}, err: ErrStartupTimeout},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			s := NewSequencer(SetStartupTimeout(100*time.Millisecond), SetPollInterval(time.Millisecond))
+			for name, f := range v.deps {
+				s.Require(name, f)
+			}
+
+			h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rr.Code != http.StatusServiceUnavailable {
+				t.Errorf("expected 503 before start but got %d", rr.Code)
+			}
+
+			var registered bool
+			err := s.Start(context.Background(), func() error {
+				registered = true
+				return nil
+			})
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+
+			if v.err != nil {
+				if !strings.Contains(err.Error(), "db: connection refused") {
+					t.Errorf("expected error to name the dependency, got %v", err)
+				}
+				if registered || s.Ready() {
+					t.Errorf("expected endpoints not to be registered")
+				}
+				return
+			}
+
+			rr = httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if !registered || rr.Code != http.StatusOK {
+				t.Errorf("expected endpoints to be registered and ready, got status %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestSequencerHold(t *testing.T) {
+	s := NewSequencer()
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Hold("lame duck")
+	s.Hold("migration")
+	s.Release("lame duck")
+	if s.Ready() {
+		t.Error("expected the service not to be ready while a hold remains")
+	}
+
+	s.Release("migration")
+	s.Release("unknown")
+	if !s.Ready() {
+		t.Error("expected the service to be ready once every hold is released")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy controls what happens to a record when the AsyncHandler queue is full
+type DropPolicy int
+
+const (
+	// Block waits for room in the queue, applying backpressure to the caller
+	Block DropPolicy = iota
+	// DropNewest discards the incoming record
+	DropNewest
+	// DropOldest discards the oldest queued record to make room for the incoming one
+	DropOldest
+)
+
+// AsyncOption is a functional option to modify the AsyncHandler
+type AsyncOption func(*asyncCore)
+
+type entry struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+	flushed chan struct{}
+}
+
+// asyncCore is shared between an AsyncHandler and any handlers derived from it with WithAttrs or WithGroup
+type asyncCore struct {
+	queue     chan entry
+	size      int
+	policy    DropPolicy
+	syncLevel slog.Leveler
+	dropped   atomic.Uint64
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+}
+
+// AsyncHandler buffers log records and writes them to the wrapped handler on a background goroutine
+type AsyncHandler struct {
+	next slog.Handler
+	core *asyncCore
+}
+
+// NewAsyncHandler wraps the handler and starts the background writer. Close must be called to flush
+// any buffered records and stop the writer.
+func NewAsyncHandler(next slog.Handler, opts ...AsyncOption) *AsyncHandler {
+	c := &asyncCore{
+		size:   1024,
+		policy: Block,
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.queue = make(chan entry, c.size)
+	go c.run()
+
+	return &AsyncHandler{
+		next: next,
+		core: c,
+	}
+}
+
+// SetQueueSize sets the number of records that can be buffered before the drop policy applies
+func SetQueueSize(n int) AsyncOption {
+	return func(c *asyncCore) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// SetDropPolicy sets the behavior when the queue is full
+func SetDropPolicy(p DropPolicy) AsyncOption {
+	return func(c *asyncCore) {
+		c.policy = p
+	}
+}
+
+// SetSyncLevel writes records at or above the level synchronously after flushing the queue, so
+// important records are never dropped or lost on a crash
+func SetSyncLevel(l slog.Leveler) AsyncOption {
+	return func(c *asyncCore) {
+		c.syncLevel = l
+	}
+}
+
+func (c *asyncCore) run() {
+	defer close(c.done)
+	for e := range c.queue {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+		e.handler.Handle(e.ctx, e.record)
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle queues the record for the background writer according to the drop policy
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.core
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return h.next.Handle(ctx, r)
+	}
+
+	if c.syncLevel != nil && r.Level >= c.syncLevel.Level() {
+		c.flush(ctx)
+		return h.next.Handle(ctx, r)
+	}
+
+	e := entry{
+		handler: h.next,
+		ctx:     context.WithoutCancel(ctx),
+		record:  r.Clone(),
+	}
+
+	switch c.policy {
+	case DropNewest:
+		select {
+		case c.queue <- e:
+		default:
+			c.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case c.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case old := <-c.queue:
+				if old.flushed != nil {
+					close(old.flushed)
+					continue
+				}
+				c.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		c.queue <- e
+	}
+
+	return nil
+}
+
+// WithAttrs returns a handler sharing the same queue with the attributes added to the wrapped handler
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{
+		next: h.next.WithAttrs(attrs),
+		core: h.core,
+	}
+}
+
+// WithGroup returns a handler sharing the same queue with the group added to the wrapped handler
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{
+		next: h.next.WithGroup(name),
+		core: h.core,
+	}
+}
+
+// Dropped returns the number of records discarded by the drop policy
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.core.dropped.Load()
+}
+
+// Flush blocks until every record queued before the call has been written or the context is done
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	c := h.core
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil
+	}
+
+	return c.flush(ctx)
+}
+
+func (c *asyncCore) flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case c.queue <- entry{flushed: flushed}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting records, writes everything still queued, and stops the background writer.
+// Records logged after Close are written synchronously.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	c := h.core
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
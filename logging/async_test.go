@@ -0,0 +1,101 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer guards a bytes.Buffer since the async writer and the test read it from different goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncHandlerFlush(t *testing.T) {
+	tt := []struct {
+		name   string
+		policy DropPolicy
+	}{
+		{name: "block", policy: Block},
+		{name: "drop newest", policy: DropNewest},
+		{name: "drop oldest", policy: DropOldest},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf syncBuffer
+			h := NewAsyncHandler(slog.NewTextHandler(&buf, nil), SetDropPolicy(v.policy), SetQueueSize(4))
+			logger := slog.New(h).With("service", "test")
+
+			for i := 0; i < 100; i++ {
+				logger.Info("message", "i", i)
+			}
+
+			if err := h.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Count(buf.String(), "\n")
+			if uint64(lines)+h.Dropped() != 100 {
+				t.Errorf("expected 100 written or dropped records but got %d written and %d dropped", lines, h.Dropped())
+			}
+
+			if v.policy == Block && h.Dropped() != 0 {
+				t.Errorf("expected no dropped records but got %d", h.Dropped())
+			}
+
+			if !strings.Contains(buf.String(), "service=test") {
+				t.Errorf("expected attributes to be preserved, got %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestAsyncHandlerSyncLevel(t *testing.T) {
+	var buf syncBuffer
+	h := NewAsyncHandler(slog.NewTextHandler(&buf, nil), SetSyncLevel(slog.LevelError))
+	defer h.Close(context.Background())
+
+	logger := slog.New(h)
+	logger.Info("first")
+	logger.Error("second")
+
+	out := buf.String()
+	if !strings.Contains(out, "first") || !strings.Contains(out, "second") {
+		t.Fatalf("expected both records to be written, got %s", out)
+	}
+
+	if strings.Index(out, "first") > strings.Index(out, "second") {
+		t.Errorf("expected queued records to be written before the synchronous record")
+	}
+}
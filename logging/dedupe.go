@@ -0,0 +1,301 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DedupeOption is a functional option to modify the DedupeHandler
+type DedupeOption func(*dedupeCore)
+
+type dedupeEntry struct {
+	start      time.Time
+	seen       int
+	suppressed int
+	// the handler and record details used to write the summary of suppressed records
+	next  slog.Handler
+	level slog.Level
+	msg   string
+	pc    uintptr
+}
+
+// dedupeSummary is a pending summary of suppressed records and the handler it is written to
+type dedupeSummary struct {
+	next slog.Handler
+	r    slog.Record
+}
+
+// dedupeCore is shared between a DedupeHandler and any handlers derived from it with WithAttrs or WithGroup
+type dedupeCore struct {
+	window  time.Duration
+	burst   int
+	level   slog.Leveler
+	maxKeys int
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[uint64]*dedupeEntry
+	swept   time.Time
+	timer   *time.Timer
+}
+
+// DedupeHandler rate-limits identical records. Records are considered identical when their level, message,
+// and record attributes match; attributes added with WithAttrs, such as a request ID, are ignored so
+// repeated failures across requests are grouped together.
+type DedupeHandler struct {
+	next slog.Handler
+	core *dedupeCore
+}
+
+// NewDedupeHandler wraps the handler. By default error records are limited to one per message per minute.
+func NewDedupeHandler(next slog.Handler, opts ...DedupeOption) *DedupeHandler {
+	c := &dedupeCore{
+		window:  time.Minute,
+		burst:   1,
+		level:   slog.LevelError,
+		maxKeys: 10000,
+		now:     time.Now,
+		entries: make(map[uint64]*dedupeEntry),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &DedupeHandler{
+		next: next,
+		core: c,
+	}
+}
+
+// SetDedupeWindow sets the period in which duplicate records are suppressed
+func SetDedupeWindow(d time.Duration) DedupeOption {
+	return func(c *dedupeCore) {
+		c.window = d
+	}
+}
+
+// SetDedupeBurst sets how many identical records are written in each window before suppressing
+func SetDedupeBurst(n int) DedupeOption {
+	return func(c *dedupeCore) {
+		if n > 0 {
+			c.burst = n
+		}
+	}
+}
+
+// SetDedupeLevel sets the minimum level that is deduplicated. Records below it are always written.
+func SetDedupeLevel(l slog.Leveler) DedupeOption {
+	return func(c *dedupeCore) {
+		c.level = l
+	}
+}
+
+// SetDedupeMaxKeys bounds the number of distinct messages tracked at once
+func SetDedupeMaxKeys(n int) DedupeOption {
+	return func(c *dedupeCore) {
+		if n > 0 {
+			c.maxKeys = n
+		}
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level
+func (h *DedupeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle writes the record unless an identical record has already been written burst times in the
+// current window. Summaries of records suppressed in windows that have ended are written first; they are
+// also written when a window ends without further records and on Close.
+func (h *DedupeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.core.level.Level() {
+		return h.next.Handle(ctx, r)
+	}
+
+	allowed, pending := h.core.check(recordKey(r), r, h.next)
+	if err := writeSummaries(ctx, pending); err != nil {
+		return err
+	}
+
+	if !allowed {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+// Close stops the flush timer and writes summaries for any records suppressed in the current windows
+func (h *DedupeHandler) Close() error {
+	c := h.core
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	now := c.now()
+	var pending []dedupeSummary
+	for k, e := range c.entries {
+		if e.suppressed > 0 {
+			pending = append(pending, e.summary(now))
+		}
+		delete(c.entries, k)
+	}
+	c.mu.Unlock()
+
+	return writeSummaries(context.Background(), pending)
+}
+
+// check records an occurrence of key and reports whether it should be written along with the summaries of
+// records suppressed in windows that have ended
+func (c *dedupeCore) check(key uint64, r slog.Record, next slog.Handler) (bool, []dedupeSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	var pending []dedupeSummary
+	if now.Sub(c.swept) >= c.window {
+		pending, _ = c.sweep(now)
+	}
+
+	e, ok := c.entries[key]
+	if !ok || now.Sub(e.start) >= c.window {
+		if ok && e.suppressed > 0 {
+			pending = append(pending, e.summary(now))
+		} else if !ok && len(c.entries) >= c.maxKeys {
+			c.prune(now)
+		}
+		c.entries[key] = &dedupeEntry{start: now, seen: 1}
+		return true, pending
+	}
+
+	e.seen++
+	if e.seen <= c.burst {
+		return true, pending
+	}
+
+	e.suppressed++
+	if e.suppressed == 1 {
+		e.next, e.level, e.msg, e.pc = next, r.Level, r.Message, r.PC
+		if c.timer == nil {
+			c.timer = time.AfterFunc(e.start.Add(c.window).Sub(now), c.flush)
+		}
+	}
+
+	return false, pending
+}
+
+// sweep removes expired entries and returns the summaries of their suppressed records along with the time
+// the next window with suppressed records ends
+func (c *dedupeCore) sweep(now time.Time) ([]dedupeSummary, time.Time) {
+	c.swept = now
+
+	var pending []dedupeSummary
+	var next time.Time
+	for k, e := range c.entries {
+		end := e.start.Add(c.window)
+		if now.Before(end) {
+			if e.suppressed > 0 && (next.IsZero() || end.Before(next)) {
+				next = end
+			}
+			continue
+		}
+
+		if e.suppressed > 0 {
+			pending = append(pending, e.summary(now))
+		}
+		delete(c.entries, k)
+	}
+
+	return pending, next
+}
+
+// flush writes the summaries of windows that ended without further records and schedules the next flush
+func (c *dedupeCore) flush() {
+	c.mu.Lock()
+	now := c.now()
+	pending, next := c.sweep(now)
+	c.timer = nil
+	if !next.IsZero() {
+		c.timer = time.AfterFunc(next.Sub(now), c.flush)
+	}
+	c.mu.Unlock()
+
+	writeSummaries(context.Background(), pending)
+}
+
+// prune removes expired entries, or every entry if all are still active. Summaries of the removed entries
+// are dropped.
+func (c *dedupeCore) prune(now time.Time) {
+	for k, v := range c.entries {
+		if now.Sub(v.start) >= c.window {
+			delete(c.entries, k)
+		}
+	}
+
+	if len(c.entries) >= c.maxKeys {
+		c.entries = make(map[uint64]*dedupeEntry)
+	}
+}
+
+func (e *dedupeEntry) summary(now time.Time) dedupeSummary {
+	r := slog.NewRecord(now, e.level, fmt.Sprintf("suppressed %d duplicates", e.suppressed), e.pc)
+	r.AddAttrs(slog.String("duplicate_msg", e.msg))
+
+	return dedupeSummary{next: e.next, r: r}
+}
+
+func writeSummaries(ctx context.Context, pending []dedupeSummary) error {
+	for _, p := range pending {
+		if err := p.next.Handle(ctx, p.r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordKey(r slog.Record) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s", r.Level, r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(h, "|%s=%s", a.Key, a.Value.String())
+		return true
+	})
+
+	return h.Sum64()
+}
+
+// WithAttrs returns a handler sharing the same duplicate tracking with the attributes added to the wrapped handler
+func (h *DedupeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DedupeHandler{
+		next: h.next.WithAttrs(attrs),
+		core: h.core,
+	}
+}
+
+// WithGroup returns a handler sharing the same duplicate tracking with the group added to the wrapped handler
+func (h *DedupeHandler) WithGroup(name string) slog.Handler {
+	return &DedupeHandler{
+		next: h.next.WithGroup(name),
+		core: h.core,
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupeHandler(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+	h := NewDedupeHandler(slog.NewTextHandler(&buf, nil), SetDedupeWindow(time.Minute))
+	h.core.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		slog.New(h).With("request_id", i).ErrorContext(ctx, "downstream failed", "code", 503)
+	}
+	slog.New(h).Info("not deduplicated")
+	slog.New(h).Info("not deduplicated")
+
+	if c := strings.Count(buf.String(), "downstream failed"); c != 1 {
+		t.Errorf("expected 1 error record but got %d", c)
+	}
+
+	if c := strings.Count(buf.String(), "not deduplicated"); c != 2 {
+		t.Errorf("expected 2 info records but got %d", c)
+	}
+
+	now = now.Add(time.Minute)
+	slog.New(h).Error("downstream failed", "code", 503)
+
+	if !strings.Contains(buf.String(), "suppressed 9 duplicates") {
+		t.Errorf("expected suppression summary, got %s", buf.String())
+	}
+
+	slog.New(h).Error("downstream failed", "code", 500)
+	if c := strings.Count(buf.String(), "code=500"); c != 1 {
+		t.Errorf("expected records with different attributes to be written, got %s", buf.String())
+	}
+}
+
+func TestDedupeBurstThenSilence(t *testing.T) {
+	var buf syncBuffer
+	h := NewDedupeHandler(slog.NewTextHandler(&buf, nil), SetDedupeWindow(20*time.Millisecond))
+	defer h.Close()
+
+	for i := 0; i < 5; i++ {
+		slog.New(h).Error("downstream failed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "suppressed 4 duplicates") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected summary after the window ended, got %s", buf.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDedupeFlushOnOtherKey(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+	h := NewDedupeHandler(slog.NewTextHandler(&buf, nil))
+	h.core.now = func() time.Time { return now }
+	defer h.Close()
+
+	for i := 0; i < 3; i++ {
+		slog.New(h).Error("downstream failed")
+	}
+
+	now = now.Add(time.Minute)
+	slog.New(h).Error("cache miss")
+
+	if !strings.Contains(buf.String(), "suppressed 2 duplicates") {
+		t.Errorf("expected summary when another record is written, got %s", buf.String())
+	}
+}
+
+func TestDedupeClose(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewDedupeHandler(slog.NewTextHandler(&buf, nil))
+
+	for i := 0; i < 3; i++ {
+		slog.New(h).Error("downstream failed")
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "suppressed 2 duplicates") {
+		t.Errorf("expected summary on close, got %s", buf.String())
+	}
+}
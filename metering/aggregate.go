@@ -0,0 +1,262 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var invalidToken = regexp.MustCompile(`[^-_a-zA-Z0-9]`)
+
+// token makes s usable as one token of a KV key
+func token(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return invalidToken.ReplaceAllString(s, "_")
+}
+
+// Rollup is the usage of a tenant's calls to an endpoint on one day
+type Rollup struct {
+	Tenant   string        `json:"tenant"`
+	Service  string        `json:"service"`
+	Endpoint string        `json:"endpoint"`
+	Day      string        `json:"day"`
+	Requests int64         `json:"requests"`
+	Units    int64         `json:"units"`
+	Duration time.Duration `json:"duration"`
+	// Sequence is the stream sequence of the last batch applied, so a redelivered batch isn't counted twice
+	Sequence uint64 `json:"sequence"`
+}
+
+func rollupKey(tenant, service, endpoint, day string) string {
+	return strings.Join([]string{token(tenant), token(service), token(endpoint), day}, ".")
+}
+
+// Source delivers event batches. A pull subscription implements it.
+type Source interface {
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+}
+
+// AggregatorOpt is a functional option to modify the Aggregator
+type AggregatorOpt func(*Aggregator)
+
+// Aggregator rolls up usage events in KV under <tenant>.<service>.<endpoint>.<day>
+type Aggregator struct {
+	kv      nats.KeyValue
+	subject string
+	wait    time.Duration
+	logger  *slog.Logger
+}
+
+// SetAggregatorSubject sets the subject prefix events are read from. The default is metering.
+func SetAggregatorSubject(s string) AggregatorOpt {
+	return func(a *Aggregator) {
+		a.subject = s
+	}
+}
+
+// SetAggregatorLogger sets the logger used to report invalid batches
+func SetAggregatorLogger(l *slog.Logger) AggregatorOpt {
+	return func(a *Aggregator) {
+		a.logger = l
+	}
+}
+
+// NewAggregator returns an Aggregator storing rollups in kv
+func NewAggregator(kv nats.KeyValue, opts ...AggregatorOpt) *Aggregator {
+	a := &Aggregator{
+		kv:      kv,
+		subject: "metering",
+		wait:    time.Second,
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Run creates or binds a durable pull consumer and applies batches until ctx is done. Batches are applied one at
+// a time in stream order, which is what lets a redelivered batch be skipped.
+func (a *Aggregator) Run(ctx context.Context, js nats.JetStreamContext, durable string) error {
+	sub, err := js.PullSubscribe(a.subject+".>", durable, nats.ManualAck(), nats.MaxAckPending(1))
+	if err != nil {
+		return err
+	}
+
+	return a.run(ctx, sub)
+}
+
+func (a *Aggregator) run(ctx context.Context, src Source) error {
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		msgs, err := src.Fetch(1, nats.MaxWait(a.wait))
+		if errors.Is(err, nats.ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if err := a.Apply(msg); err != nil {
+				a.logger.Error(fmt.Sprintf("error applying usage events: %v", err))
+				msg.Nak()
+				continue
+			}
+			msg.Ack()
+		}
+	}
+}
+
+// Apply adds a batch of events to the rollups
+func (a *Aggregator) Apply(msg *nats.Msg) error {
+	var events []Event
+	if err := json.Unmarshal(msg.Data, &events); err != nil {
+		// a batch that can't be decoded won't decode on redelivery either
+		a.logger.Error(fmt.Sprintf("dropping invalid usage batch on %s: %v", msg.Subject, err))
+		return nil
+	}
+
+	var seq uint64
+	if meta, err := msg.Metadata(); err == nil {
+		seq = meta.Sequence.Stream
+	}
+
+	rollups := make(map[string]*Rollup)
+	for _, e := range events {
+		day := e.Time.UTC().Format(time.DateOnly)
+		key := rollupKey(e.Tenant, e.Service, e.Endpoint, day)
+		r, ok := rollups[key]
+		if !ok {
+			r = &Rollup{Tenant: e.Tenant, Service: e.Service, Endpoint: e.Endpoint, Day: day}
+			rollups[key] = r
+		}
+		r.Requests++
+		r.Units += e.Units
+		r.Duration += e.Duration
+	}
+
+	for key, r := range rollups {
+		if err := a.add(key, *r, seq); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add adds the batch's usage to a stored rollup with compare and swap
+func (a *Aggregator) add(key string, batch Rollup, seq uint64) error {
+	for {
+		current := batch
+		current.Requests, current.Units, current.Duration = 0, 0, 0
+
+		var rev uint64
+		entry, err := a.kv.Get(key)
+		switch {
+		case errors.Is(err, nats.ErrKeyNotFound):
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(entry.Value(), &current); err != nil {
+				return err
+			}
+			rev = entry.Revision()
+		}
+
+		if seq != 0 && current.Sequence >= seq {
+			return nil
+		}
+		current.Requests += batch.Requests
+		current.Units += batch.Units
+		current.Duration += batch.Duration
+		current.Sequence = seq
+
+		data, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if rev == 0 {
+			_, err = a.kv.Create(key, data)
+		} else {
+			_, err = a.kv.Update(key, data, rev)
+		}
+		if errors.Is(err, nats.ErrKeyExists) {
+			continue
+		}
+
+		return err
+	}
+}
+
+// Rollups returns the tenant's rollups sorted by day, service, and endpoint
+func (a *Aggregator) Rollups(ctx context.Context, tenant string) ([]Rollup, error) {
+	keys, err := a.kv.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := token(tenant) + "."
+	var rollups []Rollup
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		entry, err := a.kv.Get(k)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var r Rollup
+		if err := json.Unmarshal(entry.Value(), &r); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, r)
+	}
+
+	sort.Slice(rollups, func(i, j int) bool {
+		if rollups[i].Day != rollups[j].Day {
+			return rollups[i].Day < rollups[j].Day
+		}
+		if rollups[i].Service != rollups[j].Service {
+			return rollups[i].Service < rollups[j].Service
+		}
+		return rollups[i].Endpoint < rollups[j].Endpoint
+	})
+
+	return rollups, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go"
+)
+
+// batch returns a message delivered from the stream at seq
+func batch(t *testing.T, seq int, events ...Event) *nats.Msg {
+	t.Helper()
+	data, err := json.Marshal(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &nats.Msg{
+		Subject: "metering.orders",
+		Data:    data,
+		Reply:   fmt.Sprintf("$JS.ACK.METERING.rollups.1.%d.%d.1736251200000000000.0", seq, seq),
+		Sub:     &nats.Subscription{},
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	a := NewAggregator(kvtest.New())
+	day := time.Date(2025, 1, 7, 23, 0, 0, 0, time.UTC)
+	list := Event{Service: "orders", Endpoint: "posts-list", Tenant: "acme", Units: 25, Duration: time.Second, Time: day}
+	get := Event{Service: "orders", Endpoint: "posts.get", Tenant: "acme", Units: 1, Duration: time.Second, Time: day}
+	other := Event{Service: "orders", Endpoint: "posts-list", Tenant: "globex", Units: 1, Time: day}
+	nextDay := list
+	nextDay.Time = day.Add(2 * time.Hour)
+
+	msgs := []*nats.Msg{
+		batch(t, 1, list, get, list, other),
+		batch(t, 2, list, nextDay),
+		// a redelivered batch is already counted
+		batch(t, 2, list, nextDay),
+		{Subject: "metering.orders", Data: []byte("not json")},
+	}
+	for _, m := range msgs {
+		if err := a.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rollups, err := a.Rollups(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rollup{
+		{Tenant: "acme", Service: "orders", Endpoint: "posts-list", Day: "2025-01-07", Requests: 3, Units: 75, Duration: 3 * time.Second, Sequence: 2},
+		{Tenant: "acme", Service: "orders", Endpoint: "posts.get", Day: "2025-01-07", Requests: 1, Units: 1, Duration: time.Second, Sequence: 1},
+		{Tenant: "acme", Service: "orders", Endpoint: "posts-list", Day: "2025-01-08", Requests: 1, Units: 25, Duration: time.Second, Sequence: 2},
+	}
+	if len(rollups) != len(want) {
+		t.Fatalf("expected %d rollups but got %+v", len(want), rollups)
+	}
+	for i := range want {
+		if rollups[i] != want[i] {
+			t.Errorf("expected %+v but got %+v", want[i], rollups[i])
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metering emits usage events for billing. Handlers wrapped by a Meter record which tenant called which
+// endpoint, how many units it used, and how long it took. Events are published in batches to a JetStream stream,
+// and an Aggregator rolls them up per tenant, endpoint, and day in KV.
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Event is the usage of one request
+type Event struct {
+	Service  string        `json:"service"`
+	Endpoint string        `json:"endpoint"`
+	Tenant   string        `json:"tenant"`
+	Units    int64         `json:"units"`
+	Duration time.Duration `json:"duration"`
+	Time     time.Time     `json:"time"`
+}
+
+// Publisher publishes event batches. nats.JetStreamContext implements it.
+type Publisher interface {
+	PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+type unitsKey struct{}
+
+// AddUnits adds units to the usage of the request in ctx. Requests use one unit unless their handler adds more,
+// such as one per item of a batch.
+func AddUnits(ctx context.Context, n int64) {
+	if u, ok := ctx.Value(unitsKey{}).(*atomic.Int64); ok {
+		u.Add(n)
+	}
+}
+
+// withUnits returns a context counting units for the request
+func withUnits(ctx context.Context) (context.Context, *atomic.Int64) {
+	u := &atomic.Int64{}
+	return context.WithValue(ctx, unitsKey{}, u), u
+}
+
+// units returns the units added by the handler, or one if it added none
+func units(u *atomic.Int64) int64 {
+	if n := u.Load(); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// MeterOpt is a functional option to modify the Meter
+type MeterOpt func(*Meter)
+
+// Meter batches usage events and publishes them to <subject>.<service>
+type Meter struct {
+	js         Publisher
+	service    string
+	subject    string
+	batchSize  int
+	maxPending int
+	interval   time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+	dropped    prometheus.Counter
+
+	mu      sync.Mutex
+	open    []Event
+	batches []eventBatch
+	flushMu sync.Mutex
+}
+
+// eventBatch is a cut batch of events. Its ID is the message ID of every attempt to publish it, so a retry of a batch
+// that was stored is dropped as a duplicate.
+type eventBatch struct {
+	id     string
+	events []Event
+}
+
+// SetSubject sets the subject prefix events are published under. The default is metering.
+func SetSubject(s string) MeterOpt {
+	return func(m *Meter) {
+		m.subject = s
+	}
+}
+
+// SetBatchSize sets how many events are published in one message. The default is 100.
+func SetBatchSize(n int) MeterOpt {
+	return func(m *Meter) {
+		m.batchSize = n
+	}
+}
+
+// SetFlushInterval sets how often Run publishes a partial batch. The default is 5 seconds.
+func SetFlushInterval(d time.Duration) MeterOpt {
+	return func(m *Meter) {
+		m.interval = d
+	}
+}
+
+// SetMaxPending sets how many events are kept while publishing fails before the oldest are dropped. The default
+// is 100 batches.
+func SetMaxPending(n int) MeterOpt {
+	return func(m *Meter) {
+		m.maxPending = n
+	}
+}
+
+// SetLogger sets the logger used to report failed publishes
+func SetLogger(l *slog.Logger) MeterOpt {
+	return func(m *Meter) {
+		m.logger = l
+	}
+}
+
+// NewMeter returns a Meter publishing the usage of service with js
+func NewMeter(js Publisher, service string, opts ...MeterOpt) *Meter {
+	m := &Meter{
+		js:        js,
+		service:   service,
+		subject:   "metering",
+		batchSize: 100,
+		interval:  5 * time.Second,
+		logger:    slog.Default(),
+		now:       time.Now,
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "metering_events_dropped",
+			Help: "Usage events dropped because they couldn't be published",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.maxPending <= 0 {
+		m.maxPending = 100 * m.batchSize
+	}
+
+	return m
+}
+
+// Metrics returns the meter's collectors to register with the exporter
+func (m *Meter) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{m.dropped}
+}
+
+// StreamConfig returns a stream config capturing the meter's events. Streams are deduplicated by message ID,
+// so a batch published twice is stored once.
+func (m *Meter) StreamConfig(name string) *nats.StreamConfig {
+	return &nats.StreamConfig{
+		Name:       name,
+		Subjects:   []string{m.subject + ".>"},
+		Duplicates: 10 * time.Minute,
+	}
+}
+
+// Record queues an event, publishing a batch once enough are queued. The service and time are filled in.
+func (m *Meter) Record(ctx context.Context, e Event) {
+	e.Service = m.service
+	if e.Time.IsZero() {
+		e.Time = m.now().UTC()
+	}
+
+	m.mu.Lock()
+	m.open = append(m.open, e)
+	full := len(m.open) >= m.batchSize
+	if full {
+		m.cut()
+	}
+	m.trim()
+	m.mu.Unlock()
+
+	if full {
+		go m.Flush(context.WithoutCancel(ctx))
+	}
+}
+
+// cut moves the open events into a batch with its message ID. The caller holds mu.
+func (m *Meter) cut() {
+	if len(m.open) == 0 {
+		return
+	}
+	m.batches = append(m.batches, eventBatch{id: nuid.Next(), events: m.open})
+	m.open = nil
+}
+
+// trim drops the oldest events past the max pending. The caller holds mu.
+func (m *Meter) trim() {
+	over := len(m.open) - m.maxPending
+	for _, b := range m.batches {
+		over += len(b.events)
+	}
+
+	for over > 0 && len(m.batches) > 0 {
+		n := min(over, len(m.batches[0].events))
+		m.batches[0].events = m.batches[0].events[n:]
+		if len(m.batches[0].events) == 0 {
+			m.batches = m.batches[1:]
+		}
+		m.dropped.Add(float64(n))
+		over -= n
+	}
+	if over > 0 {
+		m.open = m.open[over:]
+		m.dropped.Add(float64(over))
+	}
+}
+
+// Flush publishes the queued events in batches. A batch that fails to publish stays queued and is retried with
+// the same message ID.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+
+	m.mu.Lock()
+	m.cut()
+	m.mu.Unlock()
+
+	for {
+		m.mu.Lock()
+		if len(m.batches) == 0 {
+			m.mu.Unlock()
+			return nil
+		}
+		b := eventBatch{id: m.batches[0].id, events: append([]Event(nil), m.batches[0].events...)}
+		m.mu.Unlock()
+
+		if err := m.publish(ctx, b); err != nil {
+			m.logger.Error(fmt.Sprintf("error publishing usage events: %v", err))
+			return err
+		}
+
+		m.mu.Lock()
+		// the batch may have been dropped while publishing
+		if len(m.batches) > 0 && m.batches[0].id == b.id {
+			m.batches = m.batches[1:]
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (m *Meter) publish(ctx context.Context, b eventBatch) error {
+	data, err := json.Marshal(b.events)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(fmt.Sprintf("%s.%s", m.subject, m.service))
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, b.id)
+	_, err = m.js.PublishMsg(msg, nats.Context(ctx))
+	return err
+}
+
+// Run flushes the queued events every flush interval until ctx is done, then flushes once more
+func (m *Meter) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.Flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			m.Flush(ctx)
+		}
+	}
+}
+
+// Middleware records an event for every request to the endpoint made by a tenant. Requests without a tenant
+// aren't metered.
+func (m *Meter) Middleware(endpoint string, tenant func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t := tenant(r)
+			if t == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, u := withUnits(r.Context())
+			start := m.now()
+			next.ServeHTTP(w, r.WithContext(ctx))
+			m.Record(ctx, Event{Endpoint: endpoint, Tenant: t, Units: units(u), Duration: m.now().Sub(start)})
+		})
+	}
+}
+
+// AppHandler records an event for every request to the endpoint made by a tenant. Use it inside
+// sdnats.ErrorHandler so handlers can call AddUnits with their context.
+func (m *Meter) AppHandler(endpoint string, tenant func(micro.Request) string, h sdnats.AppHandler) sdnats.AppHandler {
+	return func(ctx context.Context, r micro.Request, hctx sdnats.HandlerContext) error {
+		t := tenant(r)
+		if t == "" {
+			return h(ctx, r, hctx)
+		}
+
+		ctx, u := withUnits(ctx)
+		start := m.now()
+		err := h(ctx, r, hctx)
+		m.Record(ctx, Event{Endpoint: endpoint, Tenant: t, Units: units(u), Duration: m.now().Sub(start)})
+		return err
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	fail atomic.Bool
+	msgs []*nats.Msg
+}
+
+func (f *fakePublisher) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail.Load() {
+		return nil, nats.ErrNoResponders
+	}
+	f.msgs = append(f.msgs, m)
+	return &nats.PubAck{}, nil
+}
+
+func (f *fakePublisher) events(t *testing.T) []Event {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var events []Event
+	for _, m := range f.msgs {
+		var batch []Event
+		if err := json.Unmarshal(m.Data, &batch); err != nil {
+			t.Fatal(err)
+		}
+		if m.Subject != "metering.orders" || m.Header.Get(nats.MsgIdHdr) == "" {
+			t.Errorf("expected a deduplicated batch on metering.orders but got %s %v", m.Subject, m.Header)
+		}
+		events = append(events, batch...)
+	}
+	return events
+}
+
+func TestMeterRetryKeepsID(t *testing.T) {
+	pub := &fakePublisher{}
+	m := NewMeter(pub, "orders", SetBatchSize(10))
+	m.Record(context.Background(), Event{Endpoint: "posts-list", Tenant: "acme"})
+
+	// a publish that was stored but whose ack was lost looks like a failure
+	pub.fail.Store(true)
+	if err := m.Flush(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+	m.Record(context.Background(), Event{Endpoint: "posts-list", Tenant: "globex"})
+	pub.fail.Store(false)
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pub.msgs) != 2 {
+		t.Fatalf("expected the retried batch and the new one but got %d messages", len(pub.msgs))
+	}
+	first, second := pub.msgs[0].Header.Get(nats.MsgIdHdr), pub.msgs[1].Header.Get(nats.MsgIdHdr)
+	if first == second {
+		t.Errorf("expected separate batches to have separate IDs")
+	}
+
+	// retrying the same batch reuses its ID
+	m.Record(context.Background(), Event{Endpoint: "posts-list", Tenant: "acme"})
+	m.mu.Lock()
+	m.cut()
+	id := m.batches[0].id
+	m.mu.Unlock()
+	pub.fail.Store(true)
+	m.Flush(context.Background())
+	pub.fail.Store(false)
+	m.Flush(context.Background())
+	if got := pub.msgs[2].Header.Get(nats.MsgIdHdr); got != id {
+		t.Errorf("expected the retry to use the batch ID %s but got %s", id, got)
+	}
+}
+
+type fakeRequest struct {
+	micro.Request
+	headers micro.Headers
+}
+
+func (f *fakeRequest) Headers() micro.Headers { return f.headers }
+
+func TestMeter(t *testing.T) {
+	pub := &fakePublisher{}
+	m := NewMeter(pub, "orders", SetBatchSize(2), SetMaxPending(3))
+	reg := sdtest.NewRegistry(t, m.Metrics()...)
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(10 * time.Millisecond)
+		return now
+	}
+
+	h := m.Middleware("posts-list", func(r *http.Request) string { return r.Header.Get("Tenant") })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddUnits(r.Context(), 25)
+	}))
+	for _, tenant := range []string{"acme", "", "globex"} {
+		req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+		req.Header.Set("Tenant", tenant)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	app := m.AppHandler("posts-get", func(r micro.Request) string { return r.Headers().Get("Tenant") }, func(ctx context.Context, r micro.Request, h sdnats.HandlerContext) error {
+		return fmt.Errorf("not found")
+	})
+	if err := app(context.Background(), &fakeRequest{headers: micro.Headers{"Tenant": {"acme"}}}, sdnats.HandlerContext{}); err == nil {
+		t.Error("expected the handler's error to be returned")
+	}
+
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	events := pub.events(t)
+	want := []Event{
+		{Service: "orders", Endpoint: "posts-list", Tenant: "acme", Units: 25, Duration: 10 * time.Millisecond},
+		{Service: "orders", Endpoint: "posts-list", Tenant: "globex", Units: 25, Duration: 10 * time.Millisecond},
+		{Service: "orders", Endpoint: "posts-get", Tenant: "acme", Units: 1, Duration: 10 * time.Millisecond},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events but got %+v", len(want), events)
+	}
+	for i, e := range events {
+		e.Time = time.Time{}
+		if e != want[i] {
+			t.Errorf("expected %+v but got %+v", want[i], e)
+		}
+	}
+
+	// events are kept while publishing fails, up to the max pending
+	pub.fail.Store(true)
+	for i := 0; i < 5; i++ {
+		m.Record(context.Background(), Event{Endpoint: "posts-list", Tenant: "acme", Units: 1})
+	}
+	if err := m.Flush(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+	reg.AssertCounter(t, "metering_events_dropped", nil, 2)
+
+	pub.fail.Store(false)
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pub.events(t)); n != 6 {
+		t.Errorf("expected the pending events to be published but got %d events", n)
+	}
+}
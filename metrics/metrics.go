@@ -48,6 +48,17 @@ func NewCounterVec(name, help string, labels []string) *prometheus.CounterVec {
 	)
 }
 
+func NewGaugeVec(name, help string, labels []string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        name,
+			Help:        help,
+			ConstLabels: nil,
+		},
+		labels,
+	)
+}
+
 func NewHistogramVec(name, help string, labels []string) *prometheus.HistogramVec {
 	return prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -60,8 +71,12 @@ func NewHistogramVec(name, help string, labels []string) *prometheus.HistogramVe
 	)
 }
 
+// NewOTLPExporter returns a trace exporter sending to the OTLP HTTP endpoint, given as host:port. An empty
+// endpoint uses OTEL_EXPORTER_OTLP_ENDPOINT, or localhost:4318 when it isn't set.
 func NewOTLPExporter(ctx context.Context, endpoint string, opts ...otlptracehttp.Option) (*otlptrace.Exporter, error) {
-	opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
 	c := otlptracehttp.NewClient(opts...)
 	return otlptrace.New(ctx, c)
 }
@@ -0,0 +1,490 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify delivers notifications addressed to a user to that user's open WebSocket and SSE connections,
+// whichever instance of a service holds them. A directory in a KV bucket records which instances hold
+// connections for each user. Notifications are published to one subject, a router on any instance looks up the
+// user's instances and forwards the notification to each instance's inbox subject, and the instance writes it
+// to the user's connections. Notifications for users without a connection fall back to a job, such as sending
+// an email or a push notification.
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/jobs"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UserHeader holds the user a notification queued as an offline job is for
+const UserHeader = "Sencillo-Notify-User"
+
+var (
+	ErrNoUser       = fmt.Errorf("notification has no user")
+	ErrSlowConsumer = fmt.Errorf("connection is not keeping up")
+)
+
+// Notification is a message for a user. Event names the kind of notification, like the event field of SSE.
+type Notification struct {
+	ID    string          `json:"id,omitempty"`
+	User  string          `json:"user"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Conn is one open connection of a user. Send must not block for long, since a notification is written to
+// every connection of the user in turn.
+type Conn interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// ConnFunc adapts a function, such as one writing JSON to a WebSocket, to a Conn
+type ConnFunc func(ctx context.Context, n Notification) error
+
+func (f ConnFunc) Send(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// Publisher publishes notifications. *nats.Conn implements it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSConn is the connection the fanout routes notifications over. *nats.Conn implements it.
+type NATSConn interface {
+	Publisher
+	RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error)
+	Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error)
+	QueueSubscribe(subject, queue string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+// Publish sends a notification to its user through the fanout listening under prefix, such as notify
+func Publish(pub Publisher, prefix string, n Notification) error {
+	if n.User == "" {
+		return ErrNoUser
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return pub.Publish(prefix+".send", data)
+}
+
+// FanoutOpt is a functional option to modify the Fanout
+type FanoutOpt func(*Fanout)
+
+// Fanout routes notifications to the connections of this instance and the instances it shares a directory with
+type Fanout struct {
+	nc       NATSConn
+	kv       nats.KeyValue
+	instance string
+	prefix   string
+	timeout  time.Duration
+	buffer   int
+	ping     time.Duration
+	offline  *jobs.Queue
+	kind     string
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	conns map[string]map[*local]struct{}
+	// users serializes a user's directory changes by this instance, so a disconnect can't remove the instance
+	// after a new connection found it already listed
+	users map[string]*userLock
+
+	routed      *prometheus.CounterVec
+	deliveries  *prometheus.CounterVec
+	connections prometheus.Gauge
+}
+
+// userLock is held while the instance changes a user's directory entry. refs counts its holders and waiters so
+// the lock is dropped once nobody needs it.
+type userLock struct {
+	sync.Mutex
+	refs int
+}
+
+// local is a registered connection, a pointer so the same Conn can be registered twice
+type local struct {
+	Conn
+}
+
+// SetPrefix sets the subject prefix. Notifications are published to <prefix>.send and forwarded to
+// <prefix>.inbox.<instance>. It defaults to notify.
+func SetPrefix(p string) FanoutOpt {
+	return func(f *Fanout) {
+		f.prefix = p
+	}
+}
+
+// SetDeliveryTimeout sets how long to wait for an instance to write a notification to its connections. It
+// defaults to 2 seconds.
+func SetDeliveryTimeout(d time.Duration) FanoutOpt {
+	return func(f *Fanout) {
+		f.timeout = d
+	}
+}
+
+// SetOffline enqueues notifications that reach no connection as jobs of kind, such as notify.offline, with the
+// notification as the job's data and its user in UserHeader
+func SetOffline(q *jobs.Queue, kind string) FanoutOpt {
+	return func(f *Fanout) {
+		f.offline = q
+		f.kind = kind
+	}
+}
+
+// SetBuffer sets how many notifications an SSE connection buffers before further ones are dropped. It defaults
+// to 16.
+func SetBuffer(n int) FanoutOpt {
+	return func(f *Fanout) {
+		f.buffer = n
+	}
+}
+
+// SetPing sets how often an idle SSE connection gets a comment so proxies don't close it. It defaults to 15
+// seconds.
+func SetPing(d time.Duration) FanoutOpt {
+	return func(f *Fanout) {
+		f.ping = d
+	}
+}
+
+// SetLogger sets the logger used to report failed deliveries
+func SetLogger(l *slog.Logger) FanoutOpt {
+	return func(f *Fanout) {
+		f.logger = l
+	}
+}
+
+// NewFanout returns a Fanout for the instance with the given ID, keeping the directory of connections in kv
+func NewFanout(nc NATSConn, kv nats.KeyValue, instance string, opts ...FanoutOpt) *Fanout {
+	f := &Fanout{
+		nc:       nc,
+		kv:       kv,
+		instance: instance,
+		prefix:   "notify",
+		timeout:  2 * time.Second,
+		buffer:   16,
+		ping:     15 * time.Second,
+		logger:   slog.Default(),
+		conns:    make(map[string]map[*local]struct{}),
+		users:    make(map[string]*userLock),
+		routed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "notify_routed",
+			Help: "Notifications routed by outcome",
+		}, []string{"outcome"}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "notify_deliveries",
+			Help: "Notifications written to connections of this instance by result",
+		}, []string{"result"}),
+		connections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "notify_connections",
+			Help: "Open notification connections on this instance",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Metrics returns the fanout's collectors
+func (f *Fanout) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{f.routed, f.deliveries, f.connections}
+}
+
+// InboxSubject returns the subject notifications for an instance's connections are forwarded to
+func (f *Fanout) InboxSubject(instance string) string {
+	return f.prefix + ".inbox." + instance
+}
+
+// Listen routes published notifications in a queue group and receives the ones forwarded to this instance until
+// ctx is done
+func (f *Fanout) Listen(ctx context.Context) error {
+	router, err := f.nc.QueueSubscribe(f.prefix+".send", f.prefix, func(msg *nats.Msg) {
+		var n Notification
+		if err := json.Unmarshal(msg.Data, &n); err != nil {
+			f.logger.Warn(fmt.Sprintf("dropping notification: %v", err))
+			return
+		}
+		if _, err := f.Route(ctx, n); err != nil {
+			f.logger.Error(fmt.Sprintf("routing notification for %s: %v", n.User, err))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	inbox, err := f.nc.Subscribe(f.InboxSubject(f.instance), f.handleInbox)
+	if err != nil {
+		router.Unsubscribe()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		router.Unsubscribe()
+		inbox.Unsubscribe()
+	}()
+
+	return nil
+}
+
+// handleInbox writes a forwarded notification to local connections and replies with how many it reached
+func (f *Fanout) handleInbox(msg *nats.Msg) {
+	msg.Respond(f.receive(msg.Data))
+}
+
+func (f *Fanout) receive(data []byte) []byte {
+	var n Notification
+	if err := json.Unmarshal(data, &n); err != nil {
+		f.logger.Warn(fmt.Sprintf("dropping forwarded notification: %v", err))
+		return []byte("0")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+	return []byte(strconv.Itoa(f.Deliver(ctx, n)))
+}
+
+// Route forwards the notification to every instance holding a connection of its user and returns how many
+// connections it reached. Instances that are gone are removed from the directory. A notification that reaches
+// no connection is enqueued as an offline job when SetOffline is used.
+func (f *Fanout) Route(ctx context.Context, n Notification) (int, error) {
+	if n.User == "" {
+		return 0, ErrNoUser
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		return 0, err
+	}
+
+	instances, _, err := f.lookup(n.User)
+	if err != nil {
+		return 0, err
+	}
+
+	var delivered int
+	for _, instance := range instances {
+		reqCtx, cancel := context.WithTimeout(ctx, f.timeout)
+		reply, err := f.nc.RequestMsgWithContext(reqCtx, &nats.Msg{Subject: f.InboxSubject(instance), Data: data})
+		cancel()
+		if errors.Is(err, nats.ErrNoResponders) {
+			f.logger.Debug(fmt.Sprintf("removing %s from the directory of %s", instance, n.User))
+			if err := f.remove(n.User, instance); err != nil {
+				f.logger.Warn(fmt.Sprintf("removing %s from the directory of %s: %v", instance, n.User, err))
+			}
+			continue
+		}
+		if err != nil {
+			f.logger.Warn(fmt.Sprintf("forwarding notification for %s to %s: %v", n.User, instance, err))
+			continue
+		}
+		count, _ := strconv.Atoi(string(reply.Data))
+		delivered += count
+	}
+
+	if delivered > 0 {
+		f.routed.WithLabelValues("delivered").Inc()
+		return delivered, nil
+	}
+	if f.offline == nil {
+		f.routed.WithLabelValues("dropped").Inc()
+		return 0, nil
+	}
+
+	f.routed.WithLabelValues("offline").Inc()
+	return 0, f.offline.Enqueue(ctx, f.kind, data, jobs.SetHeader(UserHeader, n.User))
+}
+
+// Deliver writes the notification to the user's connections on this instance and returns how many it reached.
+// A connection that fails is left for its handler to close.
+func (f *Fanout) Deliver(ctx context.Context, n Notification) int {
+	f.mu.Lock()
+	conns := make([]*local, 0, len(f.conns[n.User]))
+	for c := range f.conns[n.User] {
+		conns = append(conns, c)
+	}
+	f.mu.Unlock()
+
+	var delivered int
+	for _, c := range conns {
+		if err := c.Send(ctx, n); err != nil {
+			f.deliveries.WithLabelValues("failed").Inc()
+			f.logger.Debug(fmt.Sprintf("sending notification to %s: %v", n.User, err))
+			continue
+		}
+		f.deliveries.WithLabelValues("delivered").Inc()
+		delivered++
+	}
+
+	return delivered
+}
+
+// Connect registers a connection of the user on this instance. Call the returned function when the connection
+// closes.
+func (f *Fanout) Connect(user string, c Conn) (func(), error) {
+	if user == "" {
+		return nil, ErrNoUser
+	}
+
+	l := &local{Conn: c}
+	f.mu.Lock()
+	first := len(f.conns[user]) == 0
+	if first {
+		f.conns[user] = make(map[*local]struct{})
+	}
+	f.conns[user][l] = struct{}{}
+	f.mu.Unlock()
+	f.connections.Inc()
+
+	disconnect := func() {
+		f.mu.Lock()
+		delete(f.conns[user], l)
+		if len(f.conns[user]) == 0 {
+			delete(f.conns, user)
+		}
+		f.mu.Unlock()
+		f.connections.Dec()
+
+		unlock := f.lockUser(user)
+		defer unlock()
+		// a connection may have arrived since, and it counts on the instance staying listed
+		f.mu.Lock()
+		last := len(f.conns[user]) == 0
+		f.mu.Unlock()
+		if !last {
+			return
+		}
+		if err := f.remove(user, f.instance); err != nil {
+			f.logger.Warn(fmt.Sprintf("removing %s from the directory of %s: %v", f.instance, user, err))
+		}
+	}
+
+	// every connect adds the instance, in case a router removed it while it was briefly unreachable
+	unlock := f.lockUser(user)
+	err := f.add(user, f.instance)
+	unlock()
+	if err != nil {
+		disconnect()
+		return nil, err
+	}
+
+	return sync.OnceFunc(disconnect), nil
+}
+
+// lockUser locks the user's directory changes by this instance and returns the function unlocking them
+func (f *Fanout) lockUser(user string) func() {
+	f.mu.Lock()
+	l, ok := f.users[user]
+	if !ok {
+		l = &userLock{}
+		f.users[user] = l
+	}
+	l.refs++
+	f.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		f.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(f.users, user)
+		}
+		f.mu.Unlock()
+	}
+}
+
+// key returns the directory key of a user. IDs are encoded since they can hold characters keys can't, like @.
+func key(user string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(user))
+}
+
+// lookup returns the instances holding connections of the user and the entry's revision
+func (f *Fanout) lookup(user string) ([]string, uint64, error) {
+	entry, err := f.kv.Get(key(user))
+	if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrKeyDeleted) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var instances []string
+	err = json.Unmarshal(entry.Value(), &instances)
+	return instances, entry.Revision(), err
+}
+
+// update changes the user's instances with compare and swap, retrying when another instance changed them first
+func (f *Fanout) update(user string, change func([]string) []string) error {
+	for {
+		instances, rev, err := f.lookup(user)
+		if err != nil {
+			return err
+		}
+
+		changed := change(slices.Clone(instances))
+		if slices.Equal(changed, instances) {
+			return nil
+		}
+
+		switch {
+		case len(changed) == 0:
+			err = f.kv.Delete(key(user), nats.LastRevision(rev))
+		case rev == 0:
+			var data []byte
+			data, _ = json.Marshal(changed)
+			_, err = f.kv.Create(key(user), data)
+		default:
+			var data []byte
+			data, _ = json.Marshal(changed)
+			_, err = f.kv.Update(key(user), data, rev)
+		}
+		if errors.Is(err, nats.ErrKeyExists) || errors.Is(err, nats.ErrKeyNotFound) {
+			continue
+		}
+		return err
+	}
+}
+
+func (f *Fanout) add(user, instance string) error {
+	return f.update(user, func(instances []string) []string {
+		if slices.Contains(instances, instance) {
+			return instances
+		}
+		instances = append(instances, instance)
+		slices.Sort(instances)
+		return instances
+	})
+}
+
+func (f *Fanout) remove(user, instance string) error {
+	return f.update(user, func(instances []string) []string {
+		return slices.DeleteFunc(instances, func(i string) bool { return i == instance })
+	})
+}
@@ -0,0 +1,271 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/SencilloDev/sencillo-go/jobs"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeBus forwards inbox requests straight to the fanout of the instance
+type fakeBus struct {
+	mu        sync.Mutex
+	inboxes   map[string]*Fanout
+	published []*nats.Msg
+}
+
+func (b *fakeBus) Publish(subject string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, &nats.Msg{Subject: subject, Data: data})
+	return nil
+}
+
+func (b *fakeBus) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, m)
+	return &nats.PubAck{}, nil
+}
+
+func (b *fakeBus) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	b.mu.Lock()
+	f, ok := b.inboxes[msg.Subject]
+	b.mu.Unlock()
+	if !ok {
+		return nil, nats.ErrNoResponders
+	}
+	return &nats.Msg{Data: f.receive(msg.Data)}, nil
+}
+
+func (b *fakeBus) Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (b *fakeBus) QueueSubscribe(subject, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (b *fakeBus) fanout(kv nats.KeyValue, instance string, opts ...FanoutOpt) *Fanout {
+	f := NewFanout(b, kv, instance, opts...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inboxes[f.InboxSubject(instance)] = f
+	return f
+}
+
+// recorder is a connection that records what it was sent
+type recorder struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (r *recorder) Send(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestRoute(t *testing.T) {
+	bus := &fakeBus{inboxes: map[string]*Fanout{}}
+	kv := kvtest.New()
+	a := bus.fanout(kv, "ws-a")
+	b := bus.fanout(kv, "ws-b")
+	reg := sdtest.NewRegistry(t, a.Metrics()...)
+	ctx := context.Background()
+
+	conns := []*recorder{{}, {}, {}}
+	var disconnects []func()
+	for i, f := range []*Fanout{a, a, b} {
+		disconnect, err := f.Connect("ana@example.com", conns[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		disconnects = append(disconnects, disconnect)
+	}
+	if instances, _, _ := a.lookup("ana@example.com"); strings.Join(instances, ",") != "ws-a,ws-b" {
+		t.Fatalf("expected both instances in the directory but got %v", instances)
+	}
+
+	n := Notification{ID: "1", User: "ana@example.com", Event: "message", Data: json.RawMessage(`{"text":"hi"}`)}
+	delivered, err := a.Route(ctx, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 3 {
+		t.Errorf("expected every connection across instances to get the notification but got %d", delivered)
+	}
+
+	disconnects[2]()
+	disconnects[2]()
+	if instances, _, _ := a.lookup("ana@example.com"); strings.Join(instances, ",") != "ws-a" {
+		t.Errorf("expected the instance to leave the directory with its last connection but got %v", instances)
+	}
+	delivered, _ = b.Route(ctx, n)
+	if delivered != 2 {
+		t.Errorf("expected the remaining connections to get the notification but got %d", delivered)
+	}
+	if len(conns[0].sent) != 2 || len(conns[2].sent) != 1 || conns[0].sent[0].Event != "message" {
+		t.Errorf("unexpected deliveries %+v %+v", conns[0].sent, conns[2].sent)
+	}
+	reg.AssertCounter(t, "notify_routed", prometheus.Labels{"outcome": "delivered"}, 1)
+	reg.AssertCounter(t, "notify_deliveries", prometheus.Labels{"result": "delivered"}, 4)
+	reg.AssertGauge(t, "notify_connections", nil, 2)
+}
+
+// blockingKV holds Delete until release is closed, after signaling on deleting
+type blockingKV struct {
+	*kvtest.KV
+	deleting chan struct{}
+	release  chan struct{}
+}
+
+func (k *blockingKV) Delete(key string, opts ...nats.DeleteOpt) error {
+	close(k.deleting)
+	<-k.release
+	return k.KV.Delete(key, opts...)
+}
+
+func TestConnectDuringDisconnect(t *testing.T) {
+	bus := &fakeBus{inboxes: map[string]*Fanout{}}
+	kv := &blockingKV{KV: kvtest.New(), deleting: make(chan struct{}), release: make(chan struct{})}
+	f := bus.fanout(kv, "ws-a")
+
+	disconnect, err := f.Connect("ana@example.com", &recorder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		disconnect()
+	}()
+	<-kv.deleting
+
+	// the new connection arrives while the last one is still removing the instance
+	go func() {
+		defer wg.Done()
+		if _, err := f.Connect("ana@example.com", &recorder{}); err != nil {
+			t.Error(err)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(kv.release)
+	wg.Wait()
+
+	if instances, _, _ := f.lookup("ana@example.com"); strings.Join(instances, ",") != "ws-a" {
+		t.Errorf("expected the instance to stay in the directory for the new connection but got %v", instances)
+	}
+}
+
+func TestRouteOffline(t *testing.T) {
+	bus := &fakeBus{inboxes: map[string]*Fanout{}}
+	kv := kvtest.New()
+	f := bus.fanout(kv, "ws-a", SetOffline(jobs.NewQueue(bus, "jobs"), "notify.offline"))
+	reg := sdtest.NewRegistry(t, f.Metrics()...)
+
+	// an instance that died without cleaning up
+	if err := f.add("ana@example.com", "ws-gone"); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err := f.Route(context.Background(), Notification{User: "ana@example.com", Data: json.RawMessage(`"hi"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 0 {
+		t.Errorf("expected no deliveries but got %d", delivered)
+	}
+	if instances, _, _ := f.lookup("ana@example.com"); len(instances) != 0 {
+		t.Errorf("expected the dead instance to be removed but got %v", instances)
+	}
+	if len(bus.published) != 1 || bus.published[0].Subject != "jobs.normal.notify.offline" || bus.published[0].Header.Get(UserHeader) != "ana@example.com" {
+		t.Errorf("expected an offline job but got %v", bus.published)
+	}
+	reg.AssertCounter(t, "notify_routed", prometheus.Labels{"outcome": "offline"}, 1)
+}
+
+func TestSSE(t *testing.T) {
+	bus := &fakeBus{inboxes: map[string]*Fanout{}}
+	f := bus.fanout(kvtest.New(), "ws-a")
+	srv := httptest.NewServer(f.SSE(func(r *http.Request) string { return r.URL.Query().Get("user") }))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?user=ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %s", ct)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		delivered, err := f.Route(context.Background(), Notification{ID: "7", User: "ana", Event: "message", Data: json.RawMessage(`{"text":"hi"}`)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivered == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stream never connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	var event []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "\n" {
+			break
+		}
+		event = append(event, strings.TrimSuffix(line, "\n"))
+	}
+	if got := strings.Join(event, "|"); got != `id: 7|event: message|data: {"text":"hi"}` {
+		t.Errorf("unexpected event %s", got)
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a user but got %d", resp.StatusCode)
+	}
+}
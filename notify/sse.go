@@ -0,0 +1,107 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+)
+
+// sseConn buffers notifications for an SSE stream
+type sseConn struct {
+	ch chan Notification
+}
+
+// Send drops the notification instead of blocking other connections when the client isn't keeping up
+func (s *sseConn) Send(ctx context.Context, n Notification) error {
+	select {
+	case s.ch <- n:
+		return nil
+	default:
+		return ErrSlowConsumer
+	}
+}
+
+// writeEvent writes n in the text/event-stream format
+func writeEvent(w io.Writer, n Notification) error {
+	var b bytes.Buffer
+	if n.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", n.ID)
+	}
+	if n.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", n.Event)
+	}
+	for _, line := range bytes.Split(n.Data, []byte("\n")) {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// SSE returns a handler streaming the user's notifications as server-sent events until the client goes away.
+// user returns who is connecting; requests without a user get a 401.
+func (f *Fanout) SSE(user sdhttp.PrincipalFunc) http.Handler {
+	return &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		u := user(r)
+		if u == "" {
+			return sderrors.NewClientError(errors.New("unauthenticated"), http.StatusUnauthorized)
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return fmt.Errorf("streaming is not supported by %T", w)
+		}
+
+		conn := &sseConn{ch: make(chan Notification, f.buffer)}
+		disconnect, err := f.Connect(u, conn)
+		if err != nil {
+			return err
+		}
+		defer disconnect()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ping := time.NewTicker(f.ping)
+		defer ping.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return nil
+			case n := <-conn.ch:
+				if err := writeEvent(w, n); err != nil {
+					return nil
+				}
+			case <-ping.C:
+				if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+					return nil
+				}
+			}
+			flusher.Flush()
+		}
+	}}
+}
@@ -0,0 +1,608 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operations is the standard way for services to expose long running work. A handler accepts the request
+// with a 202 and a task ID, the work runs on a worker pool, and the task's status, progress, and result are kept
+// in a Store so any instance can answer a poll for it. Unfinished tasks are touched on a heartbeat, so tasks lost
+// with a stopped instance go stale and are collected. Finished tasks expire and are garbage collected.
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nuid"
+)
+
+// Work is the background part of a request. Its result is stored as JSON. Client errors are stored with their
+// message; any other error is logged and stored as an internal error. Call ReportProgress with its context to
+// update the task's progress.
+type Work func(context.Context) (any, error)
+
+// Publisher sends completion events. *nats.Conn implements it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+type job struct {
+	id   string
+	work Work
+}
+
+// inflight is a task queued or running on this instance. mu serializes the writes from the worker, progress
+// reports, and the heartbeat so none of them stores an older copy of the task.
+type inflight struct {
+	mu sync.Mutex
+	t  Task
+}
+
+// ManagerOpt is a functional option to modify the Manager
+type ManagerOpt func(*Manager)
+
+// TaskOpt is a functional option to modify a task when it is started
+type TaskOpt func(*Task)
+
+// Manager starts tasks and stores their state
+type Manager struct {
+	store      Store
+	pub        Publisher
+	prefix     string
+	location   string
+	workers    int
+	queue      int
+	poll       time.Duration
+	maxWait    time.Duration
+	retention  time.Duration
+	staleAfter time.Duration
+	heartbeat  time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+	pool       *concurrency.Pool[job]
+
+	mu            sync.Mutex
+	inflight      map[string]*inflight
+	stopHeartbeat context.CancelFunc
+}
+
+// SetPublisher sets where completion events are published. Without it nothing is published.
+func SetPublisher(p Publisher) ManagerOpt {
+	return func(m *Manager) {
+		m.pub = p
+	}
+}
+
+// SetSubjectPrefix sets the prefix of completion subjects, which are <prefix>.<id>. It defaults to operations.
+func SetSubjectPrefix(p string) ManagerOpt {
+	return func(m *Manager) {
+		m.prefix = p
+	}
+}
+
+// SetLocationPrefix sets the path Routes is mounted at, which Accept uses for the Location header. It defaults to
+// /operations.
+func SetLocationPrefix(p string) ManagerOpt {
+	return func(m *Manager) {
+		m.location = strings.TrimSuffix(p, "/")
+	}
+}
+
+// SetWorkers sets how many tasks run at once and how many can wait for a worker
+func SetWorkers(workers, queue int) ManagerOpt {
+	return func(m *Manager) {
+		m.workers = workers
+		m.queue = queue
+	}
+}
+
+// SetPollInterval sets how often a long poll checks the task
+func SetPollInterval(d time.Duration) ManagerOpt {
+	return func(m *Manager) {
+		m.poll = d
+	}
+}
+
+// SetMaxWait caps the wait parameter of a long poll
+func SetMaxWait(d time.Duration) ManagerOpt {
+	return func(m *Manager) {
+		m.maxWait = d
+	}
+}
+
+// SetRetention sets how long finished tasks are kept before garbage collection removes them. It defaults to a day.
+func SetRetention(d time.Duration) ManagerOpt {
+	return func(m *Manager) {
+		m.retention = d
+	}
+}
+
+// SetStaleAfter sets how long a pending or running task can go without an update before garbage collection fails
+// it as abandoned, such as when the instance running it stopped. It defaults to five heartbeats.
+func SetStaleAfter(d time.Duration) ManagerOpt {
+	return func(m *Manager) {
+		m.staleAfter = d
+	}
+}
+
+// SetHeartbeat sets how often the pending and running tasks of this instance are updated, which keeps them from
+// going stale while the instance is alive. It defaults to a minute.
+func SetHeartbeat(d time.Duration) ManagerOpt {
+	return func(m *Manager) {
+		m.heartbeat = d
+	}
+}
+
+// SetLogger sets the logger used to report failed tasks
+func SetLogger(l *slog.Logger) ManagerOpt {
+	return func(m *Manager) {
+		m.logger = l
+	}
+}
+
+// SetKind labels a task with the kind of work it does, such as report.export, so tasks can be listed by kind
+func SetKind(kind string) TaskOpt {
+	return func(t *Task) {
+		t.Kind = kind
+	}
+}
+
+// NewManager returns a Manager keeping tasks in store. Its workers stop when ctx is done or Close is called.
+func NewManager(ctx context.Context, store Store, opts ...ManagerOpt) *Manager {
+	m := &Manager{
+		store:     store,
+		prefix:    "operations",
+		location:  "/operations",
+		workers:   4,
+		queue:     100,
+		poll:      250 * time.Millisecond,
+		maxWait:   time.Minute,
+		retention: 24 * time.Hour,
+		heartbeat: time.Minute,
+		logger:    slog.Default(),
+		now:       time.Now,
+		inflight:  make(map[string]*inflight),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.staleAfter == 0 {
+		m.staleAfter = 5 * m.heartbeat
+	}
+
+	m.pool = concurrency.NewPool(ctx, m.workers, m.queue, m.run)
+
+	hbCtx, cancel := context.WithCancel(ctx)
+	m.stopHeartbeat = cancel
+	go m.runHeartbeat(hbCtx)
+
+	return m
+}
+
+// Close stops accepting tasks and waits for queued ones to finish
+func (m *Manager) Close() {
+	m.pool.Close()
+	m.stopHeartbeat()
+}
+
+// Subject returns the subject the task's completion is published to
+func (m *Manager) Subject(id string) string {
+	return m.prefix + "." + id
+}
+
+// Start stores a pending task and queues the work. It blocks while the queue is full until ctx is done.
+func (m *Manager) Start(ctx context.Context, work Work, opts ...TaskOpt) (Task, error) {
+	now := m.now().UTC()
+	t := Task{ID: nuid.Next(), Status: StatusPending, Created: now, Updated: now}
+	for _, opt := range opts {
+		opt(&t)
+	}
+
+	if err := m.store.Put(ctx, t); err != nil {
+		return t, err
+	}
+
+	m.mu.Lock()
+	m.inflight[t.ID] = &inflight{t: t}
+	m.mu.Unlock()
+
+	if err := m.pool.Submit(ctx, job{id: t.ID, work: work}); err != nil {
+		m.release(t.ID)
+		m.finish(context.WithoutCancel(ctx), t, nil, err)
+		return t, err
+	}
+
+	return t, nil
+}
+
+// release stops the heartbeat for a task
+func (m *Manager) release(id string) {
+	m.mu.Lock()
+	delete(m.inflight, id)
+	m.mu.Unlock()
+}
+
+// runHeartbeat touches the tasks queued and running on this instance every heartbeat until ctx is done
+func (m *Manager) runHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(m.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		tasks := make([]*inflight, 0, len(m.inflight))
+		for _, f := range m.inflight {
+			tasks = append(tasks, f)
+		}
+		m.mu.Unlock()
+
+		for _, f := range tasks {
+			f.mu.Lock()
+			if !f.t.Done() {
+				f.t.Updated = m.now().UTC()
+				if err := m.store.Put(ctx, f.t); err != nil {
+					m.logger.Error(fmt.Sprintf("updating task %s: %v", f.t.ID, err))
+				}
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+// Get returns the stored task
+func (m *Manager) Get(ctx context.Context, id string) (Task, error) {
+	return m.store.Get(ctx, id)
+}
+
+// List returns the stored tasks, limited to a kind and status when they aren't empty
+func (m *Manager) List(ctx context.Context, kind string, status Status) ([]Task, error) {
+	tasks, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := tasks[:0]
+	for _, t := range tasks {
+		if (kind == "" || t.Kind == kind) && (status == "" || t.Status == status) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Wait returns the task once it is done or, with the latest state, when ctx is done
+func (m *Manager) Wait(ctx context.Context, id string) (Task, error) {
+	ticker := time.NewTicker(m.poll)
+	defer ticker.Stop()
+
+	for {
+		t, err := m.store.Get(context.WithoutCancel(ctx), id)
+		if err != nil || t.Done() {
+			return t, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return t, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Accept starts the work and responds with a 202, a Location header for polling, and the pending task. The work
+// gets a context that isn't cancelled when the request ends.
+func (m *Manager) Accept(w http.ResponseWriter, r *http.Request, work Work, opts ...TaskOpt) error {
+	t, err := m.Start(context.WithoutCancel(r.Context()), work, opts...)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", m.location+"/"+t.ID)
+	return sdhttp.Respond(w, r, http.StatusAccepted, t)
+}
+
+// Collect removes finished tasks that have expired and fails unfinished tasks that went stale, and returns how
+// many tasks it removed or failed. A stale task is failed as abandoned rather than removed, so pollers get a
+// final status, and then expires after the retention like any other finished task.
+func (m *Manager) Collect(ctx context.Context) (int, error) {
+	tasks, err := m.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := m.now()
+	var collected int
+	for _, t := range tasks {
+		switch {
+		case m.stale(t, now):
+			m.logger.Warn(fmt.Sprintf("task %s was abandoned", t.ID))
+			t.Status = StatusFailed
+			t.Error = ErrAbandoned.Error()
+			t.Updated = now.UTC()
+			expires := t.Updated.Add(m.retention)
+			t.Expires = &expires
+			if err := m.store.Put(ctx, t); err != nil {
+				return collected, err
+			}
+			m.publish(t)
+		case m.expired(t, now):
+			if err := m.store.Delete(ctx, t.ID); err != nil {
+				return collected, err
+			}
+		default:
+			continue
+		}
+		collected++
+	}
+
+	return collected, nil
+}
+
+// RunGC collects expired tasks every interval until ctx is done. Stores that expire entries themselves, like a KV
+// bucket with a TTL, don't need it.
+func (m *Manager) RunGC(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		collected, err := m.Collect(ctx)
+		if err != nil {
+			m.logger.Error(fmt.Sprintf("collecting expired tasks: %v", err))
+			continue
+		}
+		if collected > 0 {
+			m.logger.Debug("collected expired and abandoned tasks", "collected", collected)
+		}
+	}
+}
+
+// stale reports whether an unfinished task hasn't been updated within the stale age
+func (m *Manager) stale(t Task, now time.Time) bool {
+	return !t.Done() && !t.Updated.IsZero() && now.Sub(t.Updated) > m.staleAfter
+}
+
+// expired reports whether a finished task is past its expiry
+func (m *Manager) expired(t Task, now time.Time) bool {
+	return t.Done() && t.Expires != nil && !t.Expires.After(now)
+}
+
+func (m *Manager) run(ctx context.Context, j job) error {
+	m.mu.Lock()
+	f, ok := m.inflight[j.id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, j.id)
+	}
+	defer m.release(j.id)
+
+	f.mu.Lock()
+	f.t.Status = StatusRunning
+	f.t.Updated = m.now().UTC()
+	err := m.store.Put(ctx, f.t)
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// work can report progress from several goroutines
+	ctx = context.WithValue(ctx, reporterKey{}, reporter(func(p Progress) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.t.Progress = &p
+		f.t.Updated = m.now().UTC()
+		return m.store.Put(ctx, f.t)
+	}))
+
+	result, err := j.work(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = m.finish(ctx, f.t, result, err)
+	return nil
+}
+
+// finish stores the outcome, publishes the completed task, and returns it
+func (m *Manager) finish(ctx context.Context, t Task, result any, err error) Task {
+	t.Updated = m.now().UTC()
+	expires := t.Updated.Add(m.retention)
+	t.Expires = &expires
+	t.Status = StatusSucceeded
+	if err == nil {
+		t.Result, err = json.Marshal(result)
+	}
+
+	var ce sderrors.ClientError
+	switch {
+	case errors.As(err, &ce):
+		t.Status = StatusFailed
+		t.Error = strings.Join(ce.Messages(), ", ")
+	case err != nil:
+		m.logger.Error(fmt.Sprintf("task %s failed: %v", t.ID, err))
+		t.Status = StatusFailed
+		t.Error = sdhttp.ErrInternalError.Error()
+	}
+	if t.Status == StatusFailed {
+		t.Result = nil
+	}
+
+	if err := m.store.Put(ctx, t); err != nil {
+		m.logger.Error(fmt.Sprintf("storing task %s: %v", t.ID, err))
+	}
+
+	m.publish(t)
+	return t
+}
+
+// publish sends the finished task to its completion subject
+func (m *Manager) publish(t Task) {
+	if m.pub == nil {
+		return
+	}
+	data, _ := json.Marshal(t)
+	if err := m.pub.Publish(m.Subject(t.ID), data); err != nil {
+		m.logger.Error(fmt.Sprintf("publishing task %s: %v", t.ID, err))
+	}
+}
+
+// parseWait parses the wait of a long poll, capped at the maximum wait
+func (m *Manager) parseWait(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, sderrors.NewClientError(fmt.Errorf("invalid wait %q", v), http.StatusBadRequest)
+	}
+	return min(d, m.maxWait), nil
+}
+
+// wait gets a task, long polling for up to wait
+func (m *Manager) wait(ctx context.Context, id string, wait time.Duration) (Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	t, err := m.Wait(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return t, sderrors.NewClientError(err, http.StatusNotFound)
+	}
+	return t, err
+}
+
+// Routes returns routes to list and poll tasks. Mount them at the location prefix so they match the Location
+// header, behind authentication if tasks shouldn't be visible to every caller.
+//
+//	GET /?kind=report.export&status=running
+//	GET /{id}?wait=30s
+//
+// wait long polls, responding as soon as the task is done or after the duration with its current state.
+func (m *Manager) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/{$}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				q := r.URL.Query()
+				tasks, err := m.List(r.Context(), q.Get("kind"), Status(q.Get("status")))
+				if err != nil {
+					return err
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, tasks)
+			}},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/{id}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				wait, err := m.parseWait(r.URL.Query().Get("wait"))
+				if err != nil {
+					return err
+				}
+
+				t, err := m.wait(r.Context(), r.PathValue("id"), wait)
+				if err != nil {
+					return err
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, t)
+			}},
+		},
+	}
+}
+
+type getRequest struct {
+	ID   string `json:"id"`
+	Wait string `json:"wait"`
+}
+
+type listRequest struct {
+	Kind   string `json:"kind"`
+	Status Status `json:"status"`
+}
+
+// Endpoints returns NATS endpoints matching Routes. operations.get takes {"id": "...", "wait": "30s"} and
+// operations.list takes {"kind": "...", "status": "..."}.
+func (m *Manager) Endpoints() []sdnats.Endpoint {
+	return []sdnats.Endpoint{
+		{
+			Name:    "operations-get",
+			Subject: "operations.get",
+			Handler: m.microHandler(func(ctx context.Context, data []byte) (any, error) {
+				var req getRequest
+				if err := json.Unmarshal(data, &req); err != nil {
+					return nil, sderrors.NewClientError(err, http.StatusBadRequest)
+				}
+				wait, err := m.parseWait(req.Wait)
+				if err != nil {
+					return nil, err
+				}
+				return m.wait(ctx, req.ID, wait)
+			}),
+		},
+		{
+			Name:    "operations-list",
+			Subject: "operations.list",
+			Handler: m.microHandler(func(ctx context.Context, data []byte) (any, error) {
+				var req listRequest
+				if len(data) > 0 {
+					if err := json.Unmarshal(data, &req); err != nil {
+						return nil, sderrors.NewClientError(err, http.StatusBadRequest)
+					}
+				}
+				return m.List(ctx, req.Kind, req.Status)
+			}),
+		},
+	}
+}
+
+func (m *Manager) microHandler(fn func(context.Context, []byte) (any, error)) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		v, err := fn(context.Background(), r.Data())
+
+		var ce sderrors.ClientError
+		switch {
+		case errors.As(err, &ce):
+			r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body())
+			return
+		case err != nil:
+			m.logger.Error(fmt.Sprintf("operations request failed: %v", err))
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		r.RespondJSON(v)
+	})
+}
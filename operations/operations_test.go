@@ -0,0 +1,385 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	msgs map[string][]byte
+}
+
+func (f *fakePublisher) Publish(subject string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.msgs[subject] = data
+	return nil
+}
+
+func newTestManager(t *testing.T, store Store, opts ...ManagerOpt) (*Manager, *fakePublisher) {
+	t.Helper()
+	pub := &fakePublisher{msgs: map[string][]byte{}}
+	opts = append([]ManagerOpt{
+		SetPublisher(pub),
+		SetPollInterval(time.Millisecond),
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, opts...)
+	m := NewManager(context.Background(), store, opts...)
+	t.Cleanup(m.Close)
+	return m, pub
+}
+
+func TestManager(t *testing.T) {
+	tt := []struct {
+		name       string
+		work       Work
+		wantStatus Status
+		wantResult string
+		wantError  string
+	}{
+		{
+			name: "succeeded",
+			work: func(ctx context.Context) (any, error) {
+				ReportProgress(ctx, Progress{Current: 10, Total: 10, Message: "rows exported"})
+				return map[string]int{"rows": 10}, nil
+			},
+			wantStatus: StatusSucceeded,
+			wantResult: `{"rows":10}`,
+		},
+		{
+			name: "client error",
+			work: func(context.Context) (any, error) {
+				return nil, sderrors.NewClientError(fmt.Errorf("report is empty"), http.StatusUnprocessableEntity)
+			},
+			wantStatus: StatusFailed,
+			wantError:  "report is empty",
+		},
+		{
+			name:       "internal error",
+			work:       func(context.Context) (any, error) { return nil, fmt.Errorf("disk full") },
+			wantStatus: StatusFailed,
+			wantError:  "internal server error",
+		},
+	}
+
+	for _, store := range []Store{NewMemoryStore(), NewKVStore(kvtest.New())} {
+		for _, v := range tt {
+			t.Run(fmt.Sprintf("%T %s", store, v.name), func(t *testing.T) {
+				m, pub := newTestManager(t, store)
+				release := make(chan struct{})
+				task, err := m.Start(context.Background(), func(ctx context.Context) (any, error) {
+					<-release
+					return v.work(ctx)
+				}, SetKind("report.export"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if task.Status != StatusPending || task.Kind != "report.export" {
+					t.Errorf("expected a pending report.export task but got %+v", task)
+				}
+
+				close(release)
+				done, err := m.Wait(context.Background(), task.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if done.Status != v.wantStatus || string(done.Result) != v.wantResult || done.Error != v.wantError {
+					t.Errorf("unexpected task %+v", done)
+				}
+				if done.Expires == nil || done.Expires.Sub(done.Updated) != 24*time.Hour {
+					t.Errorf("expected the task to expire after the retention but got %s", done.Expires)
+				}
+				if v.wantStatus == StatusSucceeded && (done.Progress == nil || done.Progress.Current != 10) {
+					t.Errorf("expected the reported progress to be stored but got %+v", done.Progress)
+				}
+
+				pub.mu.Lock()
+				defer pub.mu.Unlock()
+				var published Task
+				if err := json.Unmarshal(pub.msgs["operations."+task.ID], &published); err != nil {
+					t.Fatal(err)
+				}
+				if published.Status != v.wantStatus {
+					t.Errorf("expected the completion to be published with %s but got %s", v.wantStatus, published.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	store := NewMemoryStore()
+	m, _ := newTestManager(t, store, SetRetention(time.Hour))
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	store.Put(ctx, Task{ID: "expired", Status: StatusSucceeded, Expires: at(-time.Minute)})
+	store.Put(ctx, Task{ID: "kept", Status: StatusFailed, Expires: at(time.Minute)})
+	store.Put(ctx, Task{ID: "running", Status: StatusRunning, Updated: now.Add(-time.Minute)})
+	store.Put(ctx, Task{ID: "stale-running", Status: StatusRunning, Updated: now.Add(-2 * time.Hour)})
+	store.Put(ctx, Task{ID: "stale-pending", Status: StatusPending, Updated: now.Add(-2 * time.Hour)})
+
+	collected, err := m.Collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected != 3 {
+		t.Errorf("expected 3 tasks to be collected but got %d", collected)
+	}
+	if _, err := store.Get(ctx, "expired"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the expired task to be removed but got %v", err)
+	}
+	for _, id := range []string{"stale-running", "stale-pending"} {
+		got, err := store.Get(ctx, id)
+		if err != nil || got.Status != StatusFailed || got.Error != ErrAbandoned.Error() || !got.Expires.Equal(now.Add(time.Hour)) {
+			t.Errorf("expected %s to be failed as abandoned but got %+v %v", id, got, err)
+		}
+	}
+	if tasks, _ := store.List(ctx); len(tasks) != 4 {
+		t.Errorf("expected 4 tasks to be kept but got %d", len(tasks))
+	}
+
+	// abandoned tasks expire on the retention schedule
+	now = now.Add(2 * time.Hour)
+	if _, err := m.Collect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"stale-running", "stale-pending"} {
+		if _, err := store.Get(ctx, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %s to be removed after the retention but got %v", id, err)
+		}
+	}
+}
+
+func TestKVStoreListOrder(t *testing.T) {
+	store := NewKVStore(kvtest.New())
+	ctx := context.Background()
+	now := time.Now()
+	for i, id := range []string{"c", "a", "b"} {
+		store.Put(ctx, Task{ID: id, Created: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	tasks, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, v := range tasks {
+		ids = append(ids, v.ID)
+	}
+	if strings.Join(ids, "") != "cab" {
+		t.Errorf("expected tasks in created order but got %v", ids)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	store := NewMemoryStore()
+	m, _ := newTestManager(t, store, SetHeartbeat(5*time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+
+	task, err := m.Start(context.Background(), func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		got, _ := store.Get(context.Background(), task.ID)
+		if got.Status == StatusRunning && got.Updated.After(task.Updated.Add(10*time.Millisecond)) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("expected the heartbeat to update the running task")
+}
+
+func TestTaskJSON(t *testing.T) {
+	b, err := json.Marshal(Task{ID: "1", Status: StatusRunning})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "expires") {
+		t.Errorf("expected an unfinished task to omit expires but got %s", b)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	m, _ := newTestManager(t, NewMemoryStore(), SetLocationPrefix("/api/v1/operations/"))
+	release := make(chan struct{})
+	defer close(release)
+
+	rr := httptest.NewRecorder()
+	err := m.Accept(rr, httptest.NewRequest(http.MethodPost, "/reports", nil), func(ctx context.Context) (any, error) {
+		select {
+		case <-release:
+		case <-time.After(50 * time.Millisecond):
+		}
+		return "report.csv", nil
+	}, SetKind("report.export"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var accepted Task
+	json.Unmarshal(rr.Body.Bytes(), &accepted)
+	if rr.Code != http.StatusAccepted || rr.Header().Get("Location") != "/api/v1/operations/"+accepted.ID {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Header().Get("Location"))
+	}
+
+	mux := http.NewServeMux()
+	for _, r := range m.Routes() {
+		mux.Handle(r.Method+" "+r.Path, r.Handler)
+	}
+
+	tt := []struct {
+		name   string
+		path   string
+		code   int
+		status Status
+		count  int
+	}{
+		{name: "poll", path: "/" + accepted.ID, code: http.StatusOK, status: StatusRunning},
+		{name: "list running", path: "/?status=running", code: http.StatusOK, count: 1},
+		{name: "list other kind", path: "/?kind=invoice.send", code: http.StatusOK},
+		{name: "long poll", path: "/" + accepted.ID + "?wait=5s", code: http.StatusOK, status: StatusSucceeded},
+		{name: "missing", path: "/nope", code: http.StatusNotFound},
+		{name: "bad wait", path: "/" + accepted.ID + "?wait=soon", code: http.StatusBadRequest},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			// wait for the worker to pick the task up so the first poll sees it running
+			for range 100 {
+				if task, _ := m.Get(context.Background(), accepted.ID); task.Status != StatusPending {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, v.path, nil))
+			if rr.Code != v.code {
+				t.Fatalf("expected %d but got %d: %s", v.code, rr.Code, rr.Body.String())
+			}
+
+			switch {
+			case v.status != "":
+				var task Task
+				if err := json.Unmarshal(rr.Body.Bytes(), &task); err != nil {
+					t.Fatal(err)
+				}
+				if task.Status != v.status {
+					t.Errorf("expected %s but got %s", v.status, task.Status)
+				}
+			case v.code == http.StatusOK:
+				var tasks []Task
+				if err := json.Unmarshal(rr.Body.Bytes(), &tasks); err != nil {
+					t.Fatal(err)
+				}
+				if len(tasks) != v.count {
+					t.Errorf("expected %d tasks but got %d", v.count, len(tasks))
+				}
+			}
+		})
+	}
+}
+
+type fakeRequest struct {
+	micro.Request
+	data []byte
+	code string
+	resp []byte
+}
+
+func (f *fakeRequest) Data() []byte { return f.data }
+
+func (f *fakeRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	f.resp, _ = json.Marshal(v)
+	return nil
+}
+
+func (f *fakeRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	f.code = code
+	return nil
+}
+
+func TestEndpoints(t *testing.T) {
+	m, _ := newTestManager(t, NewMemoryStore())
+	task, err := m.Start(context.Background(), func(context.Context) (any, error) { return 1, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints := map[string]micro.Handler{}
+	for _, e := range m.Endpoints() {
+		endpoints[e.Subject] = e.Handler
+	}
+
+	tt := []struct {
+		name    string
+		subject string
+		data    string
+		code    string
+		want    Status
+	}{
+		{name: "get", subject: "operations.get", data: fmt.Sprintf(`{"id": %q, "wait": "5s"}`, task.ID), want: StatusSucceeded},
+		{name: "missing", subject: "operations.get", data: `{"id": "nope"}`, code: "404"},
+		{name: "invalid", subject: "operations.get", data: `{`, code: "400"},
+		{name: "list", subject: "operations.list", data: `{"status": "succeeded"}`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			r := &fakeRequest{data: []byte(v.data)}
+			endpoints[v.subject].Handle(r)
+			if r.code != v.code {
+				t.Fatalf("expected code %q but got %q", v.code, r.code)
+			}
+			if v.want != "" {
+				var got Task
+				json.Unmarshal(r.resp, &got)
+				if got.Status != v.want {
+					t.Errorf("expected %s but got %s", v.want, got.Status)
+				}
+			}
+		})
+	}
+}
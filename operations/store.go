@@ -0,0 +1,148 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+var (
+	ErrNotFound  = fmt.Errorf("task not found")
+	ErrAbandoned = fmt.Errorf("abandoned")
+)
+
+// Store keeps tasks. Get returns an error wrapping ErrNotFound for unknown IDs.
+type Store interface {
+	Get(ctx context.Context, id string) (Task, error)
+	Put(ctx context.Context, t Task) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]Task, error)
+}
+
+// KVStore keeps tasks in a NATS KV bucket keyed by ID so every instance of a service sees them
+type KVStore struct {
+	kv nats.KeyValue
+}
+
+// NewKVStore returns a store backed by kv
+func NewKVStore(kv nats.KeyValue) *KVStore {
+	return &KVStore{kv: kv}
+}
+
+func (s *KVStore) Get(ctx context.Context, id string) (Task, error) {
+	var t Task
+	entry, err := s.kv.Get(id)
+	if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrInvalidKey) {
+		return t, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return t, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &t)
+	return t, err
+}
+
+func (s *KVStore) Put(ctx context.Context, t Task) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = s.kv.Put(t.ID, data)
+	return err
+}
+
+func (s *KVStore) Delete(ctx context.Context, id string) error {
+	return s.kv.Delete(id)
+}
+
+func (s *KVStore) List(ctx context.Context) ([]Task, error) {
+	keys, err := s.kv.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(keys))
+	for _, key := range keys {
+		t, err := s.Get(ctx, key)
+		// a task collected by another instance since listing the keys is skipped
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Created.Before(tasks[j].Created) })
+
+	return tasks, nil
+}
+
+// MemoryStore keeps tasks in memory. It suits tests and single instance services.
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks map[string]Task
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tasks: make(map[string]Task)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return t, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return t, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, t Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[t.ID] = t
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tasks, id)
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Created.Before(tasks[j].Created) })
+	return tasks, nil
+}
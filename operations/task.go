@@ -0,0 +1,70 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Status is the state of a task
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Progress is how far a running task has got. Total is zero when the amount of work isn't known.
+type Progress struct {
+	Current int64  `json:"current"`
+	Total   int64  `json:"total,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Task is the stored state of a piece of background work
+type Task struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind,omitempty"`
+	Status   Status          `json:"status"`
+	Progress *Progress       `json:"progress,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Created  time.Time       `json:"created"`
+	Updated  time.Time       `json:"updated"`
+	// Expires is when a finished task is removed by garbage collection
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Done reports whether the task has finished
+func (t Task) Done() bool {
+	return t.Status == StatusSucceeded || t.Status == StatusFailed
+}
+
+type reporterKey struct{}
+
+type reporter func(Progress) error
+
+// ReportProgress records the progress of the task running with ctx. It does nothing outside of a task.
+func ReportProgress(ctx context.Context, p Progress) error {
+	report, ok := ctx.Value(reporterKey{}).(reporter)
+	if !ok {
+		return nil
+	}
+	return report(p)
+}
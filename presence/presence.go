@@ -0,0 +1,424 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package presence tracks which clients and instances are online. Members publish heartbeats on a subject, a
+// Tracker keeps the latest heartbeat of each member in a KV bucket so every instance can answer queries, and
+// members that stop sending heartbeats expire after a TTL. Joins and leaves are published as events.
+package presence
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	EventJoin  = "join"
+	EventLeave = "leave"
+)
+
+const (
+	// ReasonLeft is the reason of a leave event for a member that said goodbye
+	ReasonLeft = "left"
+	// ReasonExpired is the reason of a leave event for a member whose heartbeats stopped
+	ReasonExpired = "expired"
+)
+
+var (
+	ErrNotFound = fmt.Errorf("member not found")
+	ErrNoID     = fmt.Errorf("member has no id")
+)
+
+// Member is a client or instance that is online. Instance is which instance of a service a client is connected
+// to, so messages for the client can be routed there.
+type Member struct {
+	ID       string            `json:"id"`
+	Kind     string            `json:"kind,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Joined   time.Time         `json:"joined"`
+	LastSeen time.Time         `json:"last_seen"`
+}
+
+// Event is a member joining or leaving
+type Event struct {
+	Type   string    `json:"type"`
+	Reason string    `json:"reason,omitempty"`
+	Member Member    `json:"member"`
+	Time   time.Time `json:"time"`
+}
+
+// Publisher publishes heartbeats and events. *nats.Conn implements it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// QueueSubscriber creates queue subscriptions. *nats.Conn implements it.
+type QueueSubscriber interface {
+	QueueSubscribe(subject, queue string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+// TrackerOpt is a functional option to modify the Tracker
+type TrackerOpt func(*Tracker)
+
+// Tracker records heartbeats and answers who is online
+type Tracker struct {
+	kv      nats.KeyValue
+	pub     Publisher
+	subject string
+	ttl     time.Duration
+	logger  *slog.Logger
+	now     func() time.Time
+
+	members prometheus.Gauge
+	events  *prometheus.CounterVec
+}
+
+// SetSubject sets the subject prefix. Heartbeats are sent to <subject>.heartbeat, goodbyes to <subject>.leave,
+// and events are published to <subject>.events.join and <subject>.events.leave. It defaults to presence.
+func SetSubject(s string) TrackerOpt {
+	return func(t *Tracker) {
+		t.subject = s
+	}
+}
+
+// SetTTL sets how long a member stays online after its last heartbeat. It defaults to 30 seconds; send
+// heartbeats at a third of it so a lost heartbeat doesn't expire the member.
+func SetTTL(d time.Duration) TrackerOpt {
+	return func(t *Tracker) {
+		t.ttl = d
+	}
+}
+
+// SetLogger sets the logger used to report failed heartbeats
+func SetLogger(l *slog.Logger) TrackerOpt {
+	return func(t *Tracker) {
+		t.logger = l
+	}
+}
+
+// NewTracker returns a Tracker keeping members in kv and publishing events with pub
+func NewTracker(kv nats.KeyValue, pub Publisher, opts ...TrackerOpt) *Tracker {
+	t := &Tracker{
+		kv:      kv,
+		pub:     pub,
+		subject: "presence",
+		ttl:     30 * time.Second,
+		logger:  slog.Default(),
+		now:     time.Now,
+		members: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "presence_members",
+			Help: "Members online as of the last sweep",
+		}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "presence_events",
+			Help: "Members that joined or left",
+		}, []string{"type", "reason"}),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Metrics returns the tracker's collectors
+func (t *Tracker) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{t.members, t.events}
+}
+
+// HeartbeatSubject returns the subject members send heartbeats to
+func (t *Tracker) HeartbeatSubject() string {
+	return t.subject + ".heartbeat"
+}
+
+// LeaveSubject returns the subject members send goodbyes to
+func (t *Tracker) LeaveSubject() string {
+	return t.subject + ".leave"
+}
+
+// EventSubject returns the subject events of the given type are published to. Pass * for every type.
+func (t *Tracker) EventSubject(typ string) string {
+	return t.subject + ".events." + typ
+}
+
+// key returns the KV key of a member. IDs are encoded since they can hold characters keys can't, like @.
+func key(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// Listen subscribes to heartbeats and goodbyes in a queue group, so each is recorded by one instance
+func (t *Tracker) Listen(nc QueueSubscriber) (*nats.Subscription, error) {
+	return nc.QueueSubscribe(t.subject+".*", t.subject, t.Handle)
+}
+
+// Handle records a single heartbeat or goodbye message
+func (t *Tracker) Handle(msg *nats.Msg) {
+	var m Member
+	if err := json.Unmarshal(msg.Data, &m); err != nil {
+		t.logger.Warn(fmt.Sprintf("dropping presence message on %s: %v", msg.Subject, err))
+		return
+	}
+
+	var err error
+	switch msg.Subject {
+	case t.HeartbeatSubject():
+		err = t.Heartbeat(m)
+	case t.LeaveSubject():
+		err = t.Leave(m.ID)
+	default:
+		return
+	}
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("recording presence of %s: %v", m.ID, err))
+	}
+}
+
+// Heartbeat marks the member online, publishing a join event if it wasn't. Services holding client connections
+// can call it directly instead of publishing heartbeats.
+func (t *Tracker) Heartbeat(m Member) error {
+	if m.ID == "" {
+		return ErrNoID
+	}
+
+	now := t.now().UTC()
+	m.LastSeen = now
+	existing, rev, err := t.get(m.ID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		m.Joined = now
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		// of two heartbeats racing to create the member, only the one that wins announces the join
+		if _, err := t.kv.Create(key(m.ID), data); errors.Is(err, nats.ErrKeyExists) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		t.publish(Event{Type: EventJoin, Member: m, Time: now})
+		return nil
+	case err != nil:
+		return err
+	}
+
+	m.Joined = existing.Joined
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	// a conflicting update is another heartbeat of the same member, which refreshed it anyway
+	if _, err := t.kv.Update(key(m.ID), data, rev); err != nil && !errors.Is(err, nats.ErrKeyExists) {
+		return err
+	}
+	return nil
+}
+
+// Leave marks the member offline, publishing a leave event if it was online
+func (t *Tracker) Leave(id string) error {
+	return t.remove(id, ReasonLeft)
+}
+
+// remove deletes the member if it hasn't changed since it was read, so only one instance publishes its leave
+func (t *Tracker) remove(id, reason string) error {
+	m, rev, err := t.get(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := t.kv.Delete(key(id), nats.LastRevision(rev)); err != nil {
+		return err
+	}
+	t.publish(Event{Type: EventLeave, Reason: reason, Member: m, Time: t.now().UTC()})
+	return nil
+}
+
+func (t *Tracker) publish(e Event) {
+	t.events.WithLabelValues(e.Type, e.Reason).Inc()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := t.pub.Publish(t.EventSubject(e.Type), data); err != nil {
+		t.logger.Error(fmt.Sprintf("publishing %s of %s: %v", e.Type, e.Member.ID, err))
+	}
+}
+
+func (t *Tracker) get(id string) (Member, uint64, error) {
+	var m Member
+	entry, err := t.kv.Get(key(id))
+	if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrKeyDeleted) {
+		return m, 0, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return m, 0, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &m)
+	return m, entry.Revision(), err
+}
+
+// expired reports whether the member's heartbeats stopped more than the TTL ago
+func (t *Tracker) expired(m Member) bool {
+	return t.now().Sub(m.LastSeen) > t.ttl
+}
+
+// Get returns the member if it is online
+func (t *Tracker) Get(id string) (Member, error) {
+	m, _, err := t.get(id)
+	if err == nil && t.expired(m) {
+		return Member{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return m, err
+}
+
+// Online reports whether the member is online
+func (t *Tracker) Online(id string) bool {
+	_, err := t.Get(id)
+	return err == nil
+}
+
+// List returns the members online, limited to a kind when it isn't empty, sorted by ID
+func (t *Tracker) List(ctx context.Context, kind string) ([]Member, error) {
+	members, err := t.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	online := members[:0]
+	for _, m := range members {
+		if !t.expired(m) && (kind == "" || m.Kind == kind) {
+			online = append(online, m)
+		}
+	}
+
+	return online, nil
+}
+
+// all returns every stored member, expired or not
+func (t *Tracker) all(ctx context.Context) ([]Member, error) {
+	keys, err := t.kv.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, 0, len(keys))
+	for _, k := range keys {
+		entry, err := t.kv.Get(k)
+		// a member that left since listing the keys is skipped
+		if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrKeyDeleted) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var m Member
+		if err := json.Unmarshal(entry.Value(), &m); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	return members, nil
+}
+
+// Sweep removes members whose heartbeats stopped, publishing a leave event for each, and returns how many were
+// removed
+func (t *Tracker) Sweep(ctx context.Context) (int, error) {
+	members, err := t.all(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, m := range members {
+		if !t.expired(m) {
+			continue
+		}
+		// the member sent a heartbeat or another instance swept it since it was read
+		if err := t.remove(m.ID, ReasonExpired); err != nil {
+			t.logger.Debug(fmt.Sprintf("sweeping %s: %v", m.ID, err))
+			continue
+		}
+		removed++
+	}
+	t.members.Set(float64(len(members) - removed))
+
+	return removed, nil
+}
+
+// RunSweeper sweeps expired members every interval until ctx is done. Every instance can run it; a member
+// expires once.
+func (t *Tracker) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := t.Sweep(ctx); err != nil {
+			t.logger.Error(fmt.Sprintf("sweeping expired members: %v", err))
+		}
+	}
+}
+
+// Events streams join and leave events until ctx is done
+func (t *Tracker) Events(ctx context.Context, nc sdnats.Subscriber) (<-chan Event, error) {
+	return sdnats.Subscribe(ctx, nc, t.EventSubject("*"), sdnats.DecodeJSON[Event], nil)
+}
+
+// Announce sends heartbeats for m under the tracker's subject prefix, such as presence, every interval until ctx
+// is done, and then says goodbye so the member leaves without waiting to expire. Run it in a goroutine for each
+// instance or client that should appear online.
+func Announce(ctx context.Context, pub Publisher, prefix string, m Member, interval time.Duration) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := pub.Publish(prefix+".heartbeat", data); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return pub.Publish(prefix+".leave", data)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	msgs []*nats.Msg
+}
+
+func (f *fakePublisher) Publish(subject string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.msgs = append(f.msgs, &nats.Msg{Subject: subject, Data: data})
+	return nil
+}
+
+// events decodes the published events and forgets them
+func (f *fakePublisher) events(t *testing.T) []Event {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var events []Event
+	for _, m := range f.msgs {
+		var e Event
+		if err := json.Unmarshal(m.Data, &e); err != nil {
+			t.Fatal(err)
+		}
+		if m.Subject != "presence.events."+e.Type {
+			t.Errorf("unexpected event subject %s", m.Subject)
+		}
+		events = append(events, e)
+	}
+	f.msgs = nil
+	return events
+}
+
+func TestTracker(t *testing.T) {
+	pub := &fakePublisher{}
+	tr := NewTracker(kvtest.New(), pub)
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	reg := sdtest.NewRegistry(t, tr.Metrics()...)
+
+	heartbeat := func(m Member) {
+		t.Helper()
+		data, _ := json.Marshal(m)
+		tr.Handle(&nats.Msg{Subject: "presence.heartbeat", Data: data})
+	}
+
+	heartbeat(Member{ID: "ana@example.com", Kind: "user", Instance: "ws-1"})
+	heartbeat(Member{ID: "ws-1", Kind: "instance"})
+	if events := pub.events(t); len(events) != 2 || events[0].Type != EventJoin || events[0].Member.ID != "ana@example.com" {
+		t.Fatalf("expected two joins but got %+v", events)
+	}
+
+	now = now.Add(10 * time.Second)
+	heartbeat(Member{ID: "ana@example.com", Kind: "user", Instance: "ws-2"})
+	if events := pub.events(t); len(events) != 0 {
+		t.Errorf("expected a heartbeat of an online member to publish nothing but got %+v", events)
+	}
+
+	m, err := tr.Get("ana@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Instance != "ws-2" || !m.LastSeen.Equal(now) || !m.Joined.Equal(now.Add(-10*time.Second)) {
+		t.Errorf("expected the heartbeat to refresh the member but got %+v", m)
+	}
+
+	users, err := tr.List(context.Background(), "user")
+	if err != nil || len(users) != 1 {
+		t.Errorf("expected one user online but got %+v %v", users, err)
+	}
+
+	tr.Handle(&nats.Msg{Subject: "presence.leave", Data: []byte(`{"id": "ana@example.com"}`)})
+	events := pub.events(t)
+	if len(events) != 1 || events[0].Type != EventLeave || events[0].Reason != ReasonLeft || events[0].Member.Instance != "ws-2" {
+		t.Errorf("expected a leave with the last known member but got %+v", events)
+	}
+	if tr.Online("ana@example.com") {
+		t.Error("expected the member to be offline after leaving")
+	}
+	reg.AssertCounter(t, "presence_events", prometheus.Labels{"type": EventJoin, "reason": ""}, 2)
+	reg.AssertCounter(t, "presence_events", prometheus.Labels{"type": EventLeave, "reason": ReasonLeft}, 1)
+}
+
+func TestSweep(t *testing.T) {
+	pub := &fakePublisher{}
+	tr := NewTracker(kvtest.New(), pub, SetTTL(30*time.Second))
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	reg := sdtest.NewRegistry(t, tr.Metrics()...)
+
+	tr.Heartbeat(Member{ID: "stale"})
+	now = now.Add(20 * time.Second)
+	tr.Heartbeat(Member{ID: "fresh"})
+	pub.events(t)
+
+	now = now.Add(15 * time.Second)
+	if tr.Online("stale") {
+		t.Error("expected a member past its TTL to be offline before it is swept")
+	}
+	online, err := tr.List(context.Background(), "")
+	if err != nil || len(online) != 1 || online[0].ID != "fresh" {
+		t.Errorf("expected only the fresh member to be listed but got %+v %v", online, err)
+	}
+
+	removed, err := tr.Sweep(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected one member swept but got %d", removed)
+	}
+	events := pub.events(t)
+	if len(events) != 1 || events[0].Member.ID != "stale" || events[0].Reason != ReasonExpired {
+		t.Errorf("expected the stale member to expire but got %+v", events)
+	}
+	reg.AssertGauge(t, "presence_members", nil, 1)
+}
+
+func TestAnnounce(t *testing.T) {
+	pub := &fakePublisher{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Announce(ctx, pub, "presence", Member{ID: "ws-1", Kind: "instance"}, time.Hour)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		pub.mu.Lock()
+		n := len(pub.msgs)
+		pub.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pub.msgs) != 2 || pub.msgs[0].Subject != "presence.heartbeat" || pub.msgs[1].Subject != "presence.leave" {
+		t.Errorf("expected a heartbeat and a goodbye but got %v", pub.msgs)
+	}
+}
@@ -0,0 +1,700 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package privacy runs data subject requests. Each store that holds personal data registers a Locator that can
+// export or erase everything it keeps about a subject ID. A request is stored as a Report in a KV bucket and run
+// as a job, so it survives restarts, retries the stores that failed, and leaves an auditable record of what was
+// done, when, and for whom. Exported data is kept in an object store with a TTL rather than in the report.
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/jobs"
+	"github.com/SencilloDev/sencillo-go/metrics"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ErrNotFound         = fmt.Errorf("privacy request not found")
+	ErrInvalidSubject   = fmt.Errorf("invalid subject")
+	ErrInvalidAction    = fmt.Errorf("invalid action")
+	ErrNoLocators       = fmt.Errorf("no locators registered")
+	ErrDuplicateLocator = fmt.Errorf("duplicate locator")
+)
+
+// Action is what a request does with a subject's data
+type Action string
+
+const (
+	ActionExport Action = "export"
+	ActionErase  Action = "erase"
+)
+
+// Status is the state of a request or of one locator's part of it
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Locator finds a subject's data in one store. Export returns the data to include in an export, marshaled as
+// JSON. Erase deletes the data and returns how many records were removed. Erase is retried when it fails, so it
+// must be safe to call again for data that is already gone. A locator without Export or Erase is skipped for
+// that action.
+type Locator struct {
+	Name   string
+	Export func(ctx context.Context, subject string) (any, error)
+	Erase  func(ctx context.Context, subject string) (int, error)
+}
+
+// Outcome is what one locator did for a request
+type Outcome struct {
+	Locator  string    `json:"locator"`
+	Status   Status    `json:"status"`
+	Records  int       `json:"records,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// Report is the stored record of a request. Data holds each locator's export. It is only filled in by Get, is
+// empty for erasures, and is gone once the export object expires or the subject is erased.
+type Report struct {
+	ID          string                     `json:"id"`
+	Action      Action                     `json:"action"`
+	Subject     string                     `json:"subject"`
+	RequestedBy string                     `json:"requested_by,omitempty"`
+	Status      Status                     `json:"status"`
+	Attempts    int                        `json:"attempts"`
+	Outcomes    []Outcome                  `json:"outcomes"`
+	Data        map[string]json.RawMessage `json:"data,omitempty"`
+	Created     time.Time                  `json:"created"`
+	Updated     time.Time                  `json:"updated"`
+	Finished    time.Time                  `json:"finished,omitempty"`
+}
+
+// Done reports whether the request has finished
+func (r Report) Done() bool {
+	return r.Status == StatusSucceeded || r.Status == StatusFailed
+}
+
+// ManagerOpt is a functional option to modify the Manager
+type ManagerOpt func(*Manager)
+
+// Manager registers locators and runs requests against them
+type Manager struct {
+	queue     *jobs.Queue
+	kv        nats.KeyValue
+	exports   ExportStore
+	kind      string
+	priority  jobs.Priority
+	attempts  int
+	location  string
+	principal sdhttp.PrincipalFunc
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu       sync.RWMutex
+	locators map[string]Locator
+
+	requests *prometheus.CounterVec
+	failures *prometheus.CounterVec
+}
+
+// ExportStore keeps exported data, like nats.ObjectStore. Create it with ExportsConfig so exports expire.
+type ExportStore interface {
+	PutBytes(name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error)
+	GetBytes(name string, opts ...nats.GetObjectOpt) ([]byte, error)
+	Delete(name string) error
+}
+
+// ExportsConfig returns the object store config for exports, which are deleted after ttl
+func ExportsConfig(bucket string, ttl time.Duration) nats.ObjectStoreConfig {
+	return nats.ObjectStoreConfig{
+		Bucket:      bucket,
+		Description: "privacy request exports",
+		TTL:         ttl,
+	}
+}
+
+// SetJobKind sets the prefix of the job kinds requests run as, which are <kind>.export and <kind>.erase. It
+// defaults to privacy.
+func SetJobKind(kind string) ManagerOpt {
+	return func(m *Manager) {
+		m.kind = kind
+	}
+}
+
+// SetJobPriority sets the priority requests are queued at. It defaults to jobs.PriorityLow.
+func SetJobPriority(p jobs.Priority) ManagerOpt {
+	return func(m *Manager) {
+		m.priority = p
+	}
+}
+
+// SetAttempts sets how many times a request runs before the locators still failing are given up on. It
+// defaults to 5.
+func SetAttempts(n int) ManagerOpt {
+	return func(m *Manager) {
+		m.attempts = n
+	}
+}
+
+// SetLocationPrefix sets the path Routes is mounted at, which is used for the Location header. It defaults to
+// /privacy.
+func SetLocationPrefix(p string) ManagerOpt {
+	return func(m *Manager) {
+		m.location = strings.TrimSuffix(p, "/")
+	}
+}
+
+// SetPrincipal sets the function returning who made an HTTP request, recorded in the report as RequestedBy
+func SetPrincipal(f sdhttp.PrincipalFunc) ManagerOpt {
+	return func(m *Manager) {
+		m.principal = f
+	}
+}
+
+// SetLogger sets the logger used to report failed locators
+func SetLogger(l *slog.Logger) ManagerOpt {
+	return func(m *Manager) {
+		m.logger = l
+	}
+}
+
+// NewManager returns a Manager queueing requests on q, keeping their reports in kv, and keeping exported data
+// in exports. Run a jobs.Worker with Handler to process them.
+func NewManager(q *jobs.Queue, kv nats.KeyValue, exports ExportStore, opts ...ManagerOpt) *Manager {
+	m := &Manager{
+		queue:    q,
+		kv:       kv,
+		exports:  exports,
+		kind:     "privacy",
+		priority: jobs.PriorityLow,
+		attempts: 5,
+		location: "/privacy",
+		logger:   slog.Default(),
+		now:      time.Now,
+		locators: make(map[string]Locator),
+		requests: metrics.NewCounterVec("privacy_requests", "Finished privacy requests", []string{"action", "status"}),
+		failures: metrics.NewCounterVec("privacy_locator_failures", "Failed locator runs", []string{"action", "locator"}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Metrics returns the manager's collectors
+func (m *Manager) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.failures}
+}
+
+// Register adds locators. Names must be unique since reports refer to locators by name.
+func (m *Manager) Register(locators ...Locator) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, l := range locators {
+		if _, ok := m.locators[l.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateLocator, l.Name)
+		}
+		m.locators[l.Name] = l
+	}
+
+	return nil
+}
+
+// names returns the registered locators supporting action, sorted by name
+func (m *Manager) names(action Action) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for name, l := range m.locators {
+		if (action == ActionExport && l.Export != nil) || (action == ActionErase && l.Erase != nil) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Kind returns the job kind requests of action run as
+func (m *Manager) Kind(action Action) string {
+	return m.kind + "." + string(action)
+}
+
+// Request stores a pending report for the subject and queues the job running it
+func (m *Manager) Request(ctx context.Context, action Action, subject, requestedBy string) (Report, error) {
+	if action != ActionExport && action != ActionErase {
+		return Report{}, fmt.Errorf("%w: %q", ErrInvalidAction, action)
+	}
+	if strings.TrimSpace(subject) == "" {
+		return Report{}, ErrInvalidSubject
+	}
+
+	names := m.names(action)
+	if len(names) == 0 {
+		return Report{}, fmt.Errorf("%w for %s", ErrNoLocators, action)
+	}
+
+	now := m.now().UTC()
+	report := Report{
+		ID:          nuid.Next(),
+		Action:      action,
+		Subject:     subject,
+		RequestedBy: requestedBy,
+		Status:      StatusPending,
+		Created:     now,
+		Updated:     now,
+	}
+	for _, name := range names {
+		report.Outcomes = append(report.Outcomes, Outcome{Locator: name, Status: StatusPending})
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return report, err
+	}
+	if _, err := m.kv.Create(report.ID, data); err != nil {
+		return report, err
+	}
+
+	return report, m.queue.Enqueue(ctx, m.Kind(action), []byte(report.ID), jobs.SetPriority(m.priority))
+}
+
+// Get returns the stored report with its exported data, if it hasn't expired
+func (m *Manager) Get(id string) (Report, error) {
+	report, _, err := m.get(id)
+	if err != nil || report.Action != ActionExport {
+		return report, err
+	}
+
+	report.Data, err = m.loadExport(id)
+	return report, err
+}
+
+// loadExport returns the data exported so far for a request
+func (m *Manager) loadExport(id string) (map[string]json.RawMessage, error) {
+	b, err := m.exports.GetBytes(id)
+	if errors.Is(err, nats.ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]json.RawMessage
+	return data, json.Unmarshal(b, &data)
+}
+
+func (m *Manager) get(id string) (Report, uint64, error) {
+	var report Report
+	entry, err := m.kv.Get(id)
+	if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrInvalidKey) {
+		return report, 0, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return report, 0, err
+	}
+
+	err = json.Unmarshal(entry.Value(), &report)
+	return report, entry.Revision(), err
+}
+
+// List returns the stored reports without their exported data, oldest first, limited to a subject when it
+// isn't empty
+func (m *Manager) List(ctx context.Context, subject string) ([]Report, error) {
+	keys, err := m.kv.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for _, key := range keys {
+		report, _, err := m.get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if subject == "" || report.Subject == subject {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Created.Before(reports[j].Created) })
+
+	return reports, nil
+}
+
+// Handler returns the job handler running requests. Pass it to jobs.NewWorker for the kinds returned by Kind.
+// Locators that already succeeded are skipped when a request is retried, and the job fails while any locator
+// fails and attempts are left, so the queue redelivers it. An erasure also purges the subject's earlier export
+// reports and their data.
+func (m *Manager) Handler() jobs.Handler {
+	return func(ctx context.Context, j jobs.Job) error {
+		report, rev, err := m.get(string(j.Data))
+		if errors.Is(err, ErrNotFound) {
+			m.logger.Warn(fmt.Sprintf("privacy request %s no longer exists", j.Data))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if report.Done() {
+			return nil
+		}
+
+		report.Status = StatusRunning
+		report.Attempts++
+		if report.Action == ActionExport {
+			if report.Data, err = m.loadExport(report.ID); err != nil {
+				return err
+			}
+		}
+		failed := m.run(ctx, &report)
+		switch report.Action {
+		case ActionExport:
+			failed += m.saveExport(&report)
+		case ActionErase:
+			if err := m.purgeExports(ctx, report.Subject); err != nil {
+				failed++
+				m.logger.Error(fmt.Sprintf("privacy request %s: purging exports failed: %v", report.ID, err))
+			}
+		}
+
+		if failed > 0 && report.Attempts < m.attempts {
+			if err := m.put(report, rev); err != nil {
+				return err
+			}
+			return fmt.Errorf("privacy request %s: %d of %d locators failed", report.ID, failed, len(report.Outcomes))
+		}
+
+		report.Status = StatusSucceeded
+		if failed > 0 {
+			report.Status = StatusFailed
+		}
+		report.Finished = report.Updated
+		if err := m.put(report, rev); err != nil {
+			return err
+		}
+
+		m.requests.WithLabelValues(string(report.Action), string(report.Status)).Inc()
+		m.logger.Info("privacy request finished", "id", report.ID, "action", report.Action, "status", report.Status, "attempts", report.Attempts)
+		return nil
+	}
+}
+
+// run calls the locators that haven't succeeded yet and returns how many failed
+func (m *Manager) run(ctx context.Context, report *Report) int {
+	var failed int
+	for i, o := range report.Outcomes {
+		if o.Status == StatusSucceeded {
+			continue
+		}
+
+		m.mu.RLock()
+		l, ok := m.locators[o.Locator]
+		m.mu.RUnlock()
+
+		var err error
+		switch {
+		case !ok:
+			err = fmt.Errorf("locator %s is not registered", o.Locator)
+		case report.Action == ActionExport:
+			var data json.RawMessage
+			data, err = export(ctx, l, report.Subject)
+			if err == nil {
+				if report.Data == nil {
+					report.Data = make(map[string]json.RawMessage)
+				}
+				report.Data[o.Locator] = data
+			}
+		default:
+			o.Records, err = erase(ctx, l, report.Subject)
+		}
+
+		o.Status, o.Error = StatusSucceeded, ""
+		if err != nil {
+			failed++
+			o.Status, o.Error = StatusFailed, err.Error()
+			m.failures.WithLabelValues(string(report.Action), o.Locator).Inc()
+			m.logger.Error(fmt.Sprintf("privacy request %s: %s %s failed: %v", report.ID, o.Locator, report.Action, err))
+		}
+		o.Finished = m.now().UTC()
+		report.Outcomes[i] = o
+	}
+	report.Updated = m.now().UTC()
+
+	return failed
+}
+
+// saveExport stores the exported data and clears it from the report. When the data can't be stored, the
+// locators it came from are marked failed so they run again, and their count is returned.
+func (m *Manager) saveExport(report *Report) int {
+	defer func() { report.Data = nil }()
+	if len(report.Data) == 0 {
+		return 0
+	}
+
+	b, err := json.Marshal(report.Data)
+	if err == nil {
+		_, err = m.exports.PutBytes(report.ID, b)
+	}
+	if err == nil {
+		return 0
+	}
+
+	m.logger.Error(fmt.Sprintf("privacy request %s: storing export failed: %v", report.ID, err))
+	var failed int
+	for i, o := range report.Outcomes {
+		if _, ok := report.Data[o.Locator]; ok && o.Status == StatusSucceeded {
+			report.Outcomes[i].Status, report.Outcomes[i].Error = StatusFailed, fmt.Sprintf("storing export: %v", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// purgeExports deletes the subject's export reports and their data, so an erasure leaves no copy behind
+func (m *Manager) purgeExports(ctx context.Context, subject string) error {
+	reports, err := m.List(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		if r.Action != ActionExport {
+			continue
+		}
+		if err := m.exports.Delete(r.ID); err != nil && !errors.Is(err, nats.ErrObjectNotFound) {
+			return err
+		}
+		if err := m.kv.Purge(r.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func export(ctx context.Context, l Locator, subject string) (data json.RawMessage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	v, err := l.Export(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func erase(ctx context.Context, l Locator, subject string) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return l.Erase(ctx, subject)
+}
+
+// put stores the report if it hasn't changed since rev, so a request delivered twice isn't recorded twice
+func (m *Manager) put(report Report, rev uint64) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = m.kv.Update(report.ID, data, rev)
+	return err
+}
+
+// clientError maps request errors to client errors
+func clientError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return sderrors.NewClientError(err, http.StatusNotFound)
+	case errors.Is(err, ErrInvalidSubject), errors.Is(err, ErrInvalidAction), errors.Is(err, ErrNoLocators):
+		return sderrors.NewClientError(err, http.StatusBadRequest)
+	}
+	return err
+}
+
+// accept starts a request and responds with a 202, a Location header for polling, and the pending report
+func (m *Manager) accept(action Action) http.Handler {
+	return &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		var requestedBy string
+		if m.principal != nil {
+			requestedBy = m.principal(r)
+		}
+
+		report, err := m.Request(r.Context(), action, r.PathValue("subject"), requestedBy)
+		if err != nil {
+			return clientError(err)
+		}
+
+		w.Header().Set("Location", m.location+"/requests/"+report.ID)
+		return sdhttp.Respond(w, r, http.StatusAccepted, report)
+	}}
+}
+
+// Routes returns routes to start and audit requests. Mount them at the location prefix behind authentication
+// limited to whoever handles data subject requests, since exports contain the subject's data. Only
+// GET /requests/{id} returns exported data.
+//
+//	POST /subjects/{subject}/export
+//	POST /subjects/{subject}/erase
+//	GET  /requests?subject=...
+//	GET  /requests/{id}
+func (m *Manager) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/subjects/{subject}/export",
+			Handler: m.accept(ActionExport),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subjects/{subject}/erase",
+			Handler: m.accept(ActionErase),
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/requests",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				reports, err := m.List(r.Context(), r.URL.Query().Get("subject"))
+				if err != nil {
+					return err
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, reports)
+			}},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/requests/{id}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				report, err := m.Get(r.PathValue("id"))
+				if err != nil {
+					return clientError(err)
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, report)
+			}},
+		},
+	}
+}
+
+type startRequest struct {
+	Subject     string `json:"subject"`
+	RequestedBy string `json:"requested_by"`
+}
+
+type getRequest struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+}
+
+// Endpoints returns NATS endpoints matching Routes. privacy.export and privacy.erase take
+// {"subject": "...", "requested_by": "..."}, privacy.get takes {"id": "..."} and privacy.list takes
+// {"subject": "..."}. requested_by is recorded as sent, so restrict who can publish to these subjects.
+func (m *Manager) Endpoints() []sdnats.Endpoint {
+	start := func(action Action) micro.Handler {
+		return m.microHandler(func(ctx context.Context, data []byte) (any, error) {
+			var req startRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				return nil, sderrors.NewClientError(err, http.StatusBadRequest)
+			}
+			report, err := m.Request(ctx, action, req.Subject, req.RequestedBy)
+			return report, clientError(err)
+		})
+	}
+
+	return []sdnats.Endpoint{
+		{Name: "privacy-export", Subject: "privacy.export", Handler: start(ActionExport)},
+		{Name: "privacy-erase", Subject: "privacy.erase", Handler: start(ActionErase)},
+		{
+			Name:    "privacy-get",
+			Subject: "privacy.get",
+			Handler: m.microHandler(func(ctx context.Context, data []byte) (any, error) {
+				var req getRequest
+				if err := json.Unmarshal(data, &req); err != nil {
+					return nil, sderrors.NewClientError(err, http.StatusBadRequest)
+				}
+				report, err := m.Get(req.ID)
+				return report, clientError(err)
+			}),
+		},
+		{
+			Name:    "privacy-list",
+			Subject: "privacy.list",
+			Handler: m.microHandler(func(ctx context.Context, data []byte) (any, error) {
+				var req getRequest
+				if len(data) > 0 {
+					if err := json.Unmarshal(data, &req); err != nil {
+						return nil, sderrors.NewClientError(err, http.StatusBadRequest)
+					}
+				}
+				return m.List(ctx, req.Subject)
+			}),
+		},
+	}
+}
+
+func (m *Manager) microHandler(fn func(context.Context, []byte) (any, error)) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		v, err := fn(context.Background(), r.Data())
+
+		var ce sderrors.ClientError
+		switch {
+		case errors.As(err, &ce):
+			r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body())
+			return
+		case err != nil:
+			m.logger.Error(fmt.Sprintf("privacy request failed: %v", err))
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		r.RespondJSON(v)
+	})
+}
@@ -0,0 +1,298 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/SencilloDev/sencillo-go/jobs"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeExports struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	err     error
+}
+
+func (f *fakeExports) PutBytes(name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.objects[name] = data
+	return &nats.ObjectInfo{}, nil
+}
+
+func (f *fakeExports) GetBytes(name string, opts ...nats.GetObjectOpt) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[name]
+	if !ok {
+		return nil, nats.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (f *fakeExports) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, name)
+	return nil
+}
+
+type fakePublisher struct {
+	msgs []*nats.Msg
+}
+
+func (f *fakePublisher) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	f.msgs = append(f.msgs, m)
+	return &nats.PubAck{}, nil
+}
+
+func newManager(t *testing.T, opts ...ManagerOpt) (*Manager, *fakePublisher) {
+	t.Helper()
+	pub := &fakePublisher{}
+	return NewManager(jobs.NewQueue(pub, "jobs"), kvtest.New(), &fakeExports{objects: map[string][]byte{}}, opts...), pub
+}
+
+// deliver runs the newest queued job with the manager's handler
+func deliver(m *Manager, pub *fakePublisher) error {
+	msg := pub.msgs[len(pub.msgs)-1]
+	return m.Handler()(context.Background(), jobs.Job{Data: msg.Data, Header: msg.Header, Msg: msg})
+}
+
+func TestExport(t *testing.T) {
+	m, pub := newManager(t)
+	err := m.Register(
+		Locator{
+			Name: "profiles",
+			Export: func(ctx context.Context, subject string) (any, error) {
+				return map[string]string{"id": subject, "email": "ana@example.com"}, nil
+			},
+			Erase: func(ctx context.Context, subject string) (int, error) { return 1, nil },
+		},
+		Locator{
+			Name:  "sessions",
+			Erase: func(ctx context.Context, subject string) (int, error) { return 3, nil },
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := m.Request(context.Background(), ActionExport, "user-1", "dpo@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.msgs) != 1 || pub.msgs[0].Subject != "jobs.low.privacy.export" || string(pub.msgs[0].Data) != report.ID {
+		t.Fatalf("expected the request to be queued as a low priority job but got %v", pub.msgs)
+	}
+	if err := deliver(m, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = m.Get(report.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != StatusSucceeded || report.RequestedBy != "dpo@example.com" || report.Finished.IsZero() {
+		t.Errorf("expected a finished audited report but got %+v", report)
+	}
+	if len(report.Outcomes) != 1 || report.Outcomes[0].Locator != "profiles" {
+		t.Errorf("expected only locators that export but got %+v", report.Outcomes)
+	}
+	if got := string(report.Data["profiles"]); got != `{"email":"ana@example.com","id":"user-1"}` {
+		t.Errorf("unexpected export %s", got)
+	}
+	if stored := m.kv.(*kvtest.KV).Value(report.ID); strings.Contains(string(stored), "ana@example.com") {
+		t.Errorf("expected the export to be kept out of the report but got %s", stored)
+	}
+
+	reports, err := m.List(context.Background(), "user-1")
+	if err != nil || len(reports) != 1 || reports[0].Data != nil {
+		t.Errorf("expected the list to leave out exported data but got %+v %v", reports, err)
+	}
+
+	if _, err := m.Request(context.Background(), ActionErase, "user-1", "dpo@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(m, pub); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get(report.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the erasure to purge the export report but got %v", err)
+	}
+	if n := len(m.exports.(*fakeExports).objects); n != 0 {
+		t.Errorf("expected the erasure to delete the exported data but %d objects remain", n)
+	}
+}
+
+func TestExportStoreFailure(t *testing.T) {
+	m, pub := newManager(t, SetAttempts(2))
+	m.exports.(*fakeExports).err = errors.New("object store unavailable")
+	m.Register(Locator{Name: "profiles", Export: func(ctx context.Context, subject string) (any, error) { return "data", nil }})
+
+	report, err := m.Request(context.Background(), ActionExport, "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deliver(m, pub); err == nil {
+		t.Fatal("expected the job to fail while the export can't be stored")
+	}
+	if err := deliver(m, pub); err != nil {
+		t.Fatalf("expected the last attempt to finish the request but got %v", err)
+	}
+
+	report, err = m.Get(report.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != StatusFailed || report.Attempts != 2 || !strings.Contains(report.Outcomes[0].Error, "storing export") {
+		t.Errorf("expected every attempt to be recorded but got %+v", report)
+	}
+}
+
+func TestEraseRetries(t *testing.T) {
+	tt := []struct {
+		name     string
+		failures int
+		status   Status
+		records  int
+	}{
+		{name: "first attempt", status: StatusSucceeded, records: 3},
+		{name: "retried", failures: 1, status: StatusSucceeded, records: 3},
+		{name: "given up", failures: 5, status: StatusFailed},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			m, pub := newManager(t, SetAttempts(2))
+			reg := sdtest.NewRegistry(t, m.Metrics()...)
+			var profiles, sessions int
+			m.Register(
+				Locator{Name: "profiles", Erase: func(ctx context.Context, subject string) (int, error) {
+					profiles++
+					return 1, nil
+				}},
+				Locator{Name: "sessions", Erase: func(ctx context.Context, subject string) (int, error) {
+					sessions++
+					if sessions <= v.failures {
+						return 0, errors.New("store unavailable")
+					}
+					return 3, nil
+				}},
+			)
+
+			report, err := m.Request(context.Background(), ActionErase, "user-1", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2; i++ {
+				err = deliver(m, pub)
+				if err == nil {
+					break
+				}
+			}
+			if err != nil {
+				t.Fatalf("expected the last attempt to finish the request but got %v", err)
+			}
+
+			report, err = m.Get(report.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Status != v.status {
+				t.Errorf("expected %s but got %s", v.status, report.Status)
+			}
+			if profiles != 1 {
+				t.Errorf("expected a succeeded locator to run once but it ran %d times", profiles)
+			}
+			if o := report.Outcomes[1]; o.Records != v.records || (v.status == StatusFailed) != (o.Error != "") {
+				t.Errorf("unexpected outcome %+v", o)
+			}
+			if len(report.Data) != 0 {
+				t.Errorf("expected no data in an erasure report but got %v", report.Data)
+			}
+			reg.AssertCounter(t, "privacy_requests", prometheus.Labels{"action": "erase", "status": string(v.status)}, 1)
+			if v.failures > 0 {
+				reg.AssertCounter(t, "privacy_locator_failures", prometheus.Labels{"action": "erase", "locator": "sessions"}, float64(min(v.failures, 2)))
+			}
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	m, _ := newManager(t, SetPrincipal(func(r *http.Request) string { return r.Header.Get("X-User") }))
+	m.Register(Locator{Name: "profiles", Erase: func(ctx context.Context, subject string) (int, error) { return 1, nil }})
+
+	mux := http.NewServeMux()
+	for _, r := range m.Routes() {
+		mux.Handle(r.Method+" "+r.Path, r.Handler)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/subjects/user-1/erase", nil)
+	req.Header.Set("X-User", "dpo")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 but got %d: %s", rr.Code, rr.Body)
+	}
+	var report Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if loc := rr.Header().Get("Location"); loc != "/privacy/requests/"+report.ID {
+		t.Errorf("unexpected location %s", loc)
+	}
+
+	tt := []struct {
+		name   string
+		method string
+		path   string
+		code   int
+	}{
+		{name: "get", method: http.MethodGet, path: "/requests/" + report.ID, code: http.StatusOK},
+		{name: "list", method: http.MethodGet, path: "/requests?subject=user-1", code: http.StatusOK},
+		{name: "missing", method: http.MethodGet, path: "/requests/nope", code: http.StatusNotFound},
+		{name: "no exporters", method: http.MethodPost, path: "/subjects/user-1/export", code: http.StatusBadRequest},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(v.method, v.path, nil))
+			if rr.Code != v.code {
+				t.Errorf("expected %d but got %d: %s", v.code, rr.Code, rr.Body)
+			}
+		})
+	}
+
+	reports, err := m.List(context.Background(), "user-1")
+	if err != nil || len(reports) != 1 || reports[0].RequestedBy != "dpo" {
+		t.Errorf("expected one audited request but got %+v %v", reports, err)
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package probe calls a service's own endpoints on an interval and checks the responses, so a broken endpoint
+// shows up in health checks and metrics before users report it
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrUnhealthy = fmt.Errorf("probes failing")
+
+// Expect describes a passing response. Zero values aren't checked.
+type Expect struct {
+	// Status is the expected HTTP status or micro error code. NATS probes expect no error code when it is zero.
+	Status int
+	// Contains is a string the body must contain
+	Contains string
+	// Validate checks the body, such as by decoding it
+	Validate func([]byte) error
+}
+
+func (e Expect) check(status int, body []byte) error {
+	if e.Status != 0 && status != e.Status {
+		return fmt.Errorf("expected status %d but got %d: %s", e.Status, status, truncate(body))
+	}
+	if e.Contains != "" && !bytes.Contains(body, []byte(e.Contains)) {
+		return fmt.Errorf("expected body to contain %q but got %s", e.Contains, truncate(body))
+	}
+	if e.Validate != nil {
+		return e.Validate(body)
+	}
+	return nil
+}
+
+func truncate(b []byte) string {
+	const limit = 256
+	if len(b) > limit {
+		return string(b[:limit]) + "..."
+	}
+	return string(b)
+}
+
+// Probe is a check of one endpoint
+type Probe struct {
+	Name string
+	// Interval overrides the prober's interval for this probe
+	Interval time.Duration
+	Check    func(context.Context) error
+}
+
+// HTTP returns a probe sending a request to url and checking the response
+func HTTP(name string, client *http.Client, method, url string, body []byte, expect Expect) Probe {
+	if expect.Status == 0 {
+		expect.Status = http.StatusOK
+	}
+
+	return Probe{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("User-Agent", "sencillo-probe")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return expect.check(resp.StatusCode, data)
+		},
+	}
+}
+
+// NATS returns a probe sending a request to subject and checking the response
+func NATS(name string, nc sdnats.Requester, subject string, data []byte, expect Expect) Probe {
+	return Probe{
+		Name: name,
+		Check: func(ctx context.Context) error {
+			msg := nats.NewMsg(subject)
+			msg.Data = data
+			reply, err := nc.RequestMsgWithContext(ctx, msg)
+			if err != nil {
+				return err
+			}
+
+			status := 0
+			if code := reply.Header.Get(micro.ErrorCodeHeader); code != "" {
+				status, _ = strconv.Atoi(code)
+				if expect.Status == 0 {
+					return fmt.Errorf("expected no error but got %s %s: %s", code, reply.Header.Get(micro.ErrorHeader), truncate(reply.Data))
+				}
+			}
+			return expect.check(status, reply.Data)
+		},
+	}
+}
+
+// Result is the latest outcome of a probe
+type Result struct {
+	Name     string        `json:"name"`
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Failures int           `json:"failures"`
+	Duration time.Duration `json:"duration"`
+	Checked  time.Time     `json:"checked"`
+}
+
+// ProberOpt is a functional option to modify the Prober
+type ProberOpt func(*Prober)
+
+// Prober runs probes on an interval
+type Prober struct {
+	probes    []Probe
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	logger    *slog.Logger
+	now       func() time.Time
+
+	success  *prometheus.GaugeVec
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+
+	mu      sync.Mutex
+	results map[string]Result
+}
+
+// SetInterval sets how often probes run. The default is 30 seconds.
+func SetInterval(d time.Duration) ProberOpt {
+	return func(p *Prober) {
+		p.interval = d
+	}
+}
+
+// SetTimeout sets the deadline of a single probe. The default is 5 seconds.
+func SetTimeout(d time.Duration) ProberOpt {
+	return func(p *Prober) {
+		p.timeout = d
+	}
+}
+
+// SetFailureThreshold sets how many consecutive failures make a probe unhealthy. The default is 2, so one
+// slow response doesn't fail the health check.
+func SetFailureThreshold(n int) ProberOpt {
+	return func(p *Prober) {
+		if n > 0 {
+			p.threshold = n
+		}
+	}
+}
+
+// SetLogger sets the logger used to report failed probes
+func SetLogger(l *slog.Logger) ProberOpt {
+	return func(p *Prober) {
+		p.logger = l
+	}
+}
+
+// SetProbes sets the probes to run
+func SetProbes(probes ...Probe) ProberOpt {
+	return func(p *Prober) {
+		p.probes = append(p.probes, probes...)
+	}
+}
+
+// NewProber returns a Prober. Probes are healthy until they fail.
+func NewProber(opts ...ProberOpt) *Prober {
+	p := &Prober{
+		interval:  30 * time.Second,
+		timeout:   5 * time.Second,
+		threshold: 2,
+		logger:    slog.Default(),
+		now:       time.Now,
+		results:   make(map[string]Result),
+		success: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_success",
+			Help: "Whether the last run of a probe passed",
+		}, []string{"probe"}),
+		duration: metrics.NewHistogramVec("probe_duration_seconds", "Time probes took", []string{"probe"}),
+		failures: metrics.NewCounterVec("probe_failures", "Failed probe runs", []string{"probe"}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for _, probe := range p.probes {
+		p.results[probe.Name] = Result{Name: probe.Name, Healthy: true}
+	}
+
+	return p
+}
+
+// Metrics returns the prober's collectors to register with the exporter
+func (p *Prober) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{p.success, p.duration, p.failures}
+}
+
+// Run runs every probe on its interval until ctx is done. Start it once the service is serving.
+func (p *Prober) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, probe := range p.probes {
+		wg.Add(1)
+		go func(probe Probe) {
+			defer wg.Done()
+			interval := probe.Interval
+			if interval <= 0 {
+				interval = p.interval
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				p.run(ctx, probe)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(probe)
+	}
+	wg.Wait()
+}
+
+// run runs a probe once and records the result
+func (p *Prober) run(ctx context.Context, probe Probe) {
+	start := p.now()
+	checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	err := probe.Check(checkCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	elapsed := p.now().Sub(start)
+	p.duration.WithLabelValues(probe.Name).Observe(elapsed.Seconds())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.results[probe.Name]
+	r.Duration = elapsed
+	r.Checked = start.UTC()
+	if err == nil {
+		if !r.Healthy {
+			p.logger.Info(fmt.Sprintf("probe %s recovered", probe.Name))
+		}
+		r.Healthy, r.Error, r.Failures = true, "", 0
+		p.success.WithLabelValues(probe.Name).Set(1)
+		p.results[probe.Name] = r
+		return
+	}
+
+	r.Failures++
+	r.Error = err.Error()
+	r.Healthy = r.Failures < p.threshold
+	p.failures.WithLabelValues(probe.Name).Inc()
+	p.success.WithLabelValues(probe.Name).Set(0)
+	p.logger.Error(fmt.Sprintf("probe %s failed (%d/%d): %v", probe.Name, r.Failures, p.threshold, err))
+	p.results[probe.Name] = r
+}
+
+// Results returns the latest result of every probe sorted by name
+func (p *Prober) Results() []Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := make([]Result, 0, len(p.results))
+	for _, r := range p.results {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	return results
+}
+
+// Check returns an error wrapping ErrUnhealthy naming the unhealthy probes. It is a sdnats.HealthCheck, so
+// sdnats.PeriodicHealthCheck can stop a service whose endpoints stay broken.
+func (p *Prober) Check(context.Context) error {
+	var failing []string
+	for _, r := range p.Results() {
+		if !r.Healthy {
+			failing = append(failing, fmt.Sprintf("%s: %s", r.Name, r.Error))
+		}
+	}
+	if len(failing) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnhealthy, strings.Join(failing, "; "))
+	}
+
+	return nil
+}
+
+// Routes returns a GET /probes route responding with the results, with a 503 if any probe is unhealthy
+func (p *Prober) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/probes",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				code := http.StatusOK
+				if p.Check(r.Context()) != nil {
+					code = http.StatusServiceUnavailable
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(code)
+				return json.NewEncoder(w).Encode(p.Results())
+			}},
+		},
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeRequester struct {
+	reply *nats.Msg
+	err   error
+}
+
+func (f fakeRequester) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	return f.reply, f.err
+}
+
+func TestProbes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer srv.Close()
+
+	failed := nats.NewMsg("")
+	failed.Header.Set(micro.ErrorCodeHeader, "500")
+	failed.Header.Set(micro.ErrorHeader, "Internal Server Error")
+
+	tt := []struct {
+		name  string
+		probe Probe
+		err   string
+	}{
+		{name: "http ok", probe: HTTP("ok", srv.Client(), http.MethodGet, srv.URL+"/ok", nil, Expect{Contains: `"ok"`})},
+		{name: "http status", probe: HTTP("broken", srv.Client(), http.MethodGet, srv.URL+"/broken", nil, Expect{}), err: "expected status 200 but got 500"},
+		{name: "http body", probe: HTTP("body", srv.Client(), http.MethodGet, srv.URL+"/ok", nil, Expect{Contains: "ready"}), err: `expected body to contain "ready"`},
+		{name: "nats ok", probe: NATS("ok", fakeRequester{reply: &nats.Msg{Data: []byte("pong")}}, "svc.ping", nil, Expect{Contains: "pong"})},
+		{name: "nats error", probe: NATS("error", fakeRequester{reply: failed}, "svc.ping", nil, Expect{}), err: "expected no error but got 500"},
+		{name: "nats expected error", probe: NATS("missing", fakeRequester{reply: failed}, "svc.ping", nil, Expect{Status: 500})},
+		{name: "nats timeout", probe: NATS("timeout", fakeRequester{err: nats.ErrTimeout}, "svc.ping", nil, Expect{}), err: nats.ErrTimeout.Error()},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			err := v.probe.Check(context.Background())
+			if v.err == "" && err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if v.err != "" && (err == nil || !strings.Contains(err.Error(), v.err)) {
+				t.Errorf("expected error containing %q but got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestProberThreshold(t *testing.T) {
+	var fail atomic.Bool
+	probe := Probe{Name: "orders", Check: func(context.Context) error {
+		if fail.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+
+	p := NewProber(SetProbes(probe), SetFailureThreshold(2))
+	reg := sdtest.NewRegistry(t, p.Metrics()...)
+	labels := prometheus.Labels{"probe": "orders"}
+
+	p.run(context.Background(), probe)
+	reg.AssertGauge(t, "probe_success", labels, 1)
+
+	fail.Store(true)
+	p.run(context.Background(), probe)
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("expected one failure to stay under the threshold but got %v", err)
+	}
+	reg.AssertGauge(t, "probe_success", labels, 0)
+
+	p.run(context.Background(), probe)
+	err := p.Check(context.Background())
+	if !errors.Is(err, ErrUnhealthy) || !strings.Contains(err.Error(), "orders: connection refused") {
+		t.Errorf("expected the probe to be unhealthy but got %v", err)
+	}
+	reg.AssertCounter(t, "probe_failures", labels, 2)
+	reg.AssertHistogramCount(t, "probe_duration_seconds", labels, 3)
+
+	rr := httptest.NewRecorder()
+	p.Routes()[0].Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/probes", nil))
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), `"failures":2`) {
+		t.Errorf("expected a 503 listing the failures but got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	fail.Store(false)
+	p.run(context.Background(), probe)
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("expected the probe to recover but got %v", err)
+	}
+}
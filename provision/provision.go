@@ -0,0 +1,334 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Action is what the Provisioner did, or would do in dry-run mode, for a resource
+type Action string
+
+const (
+	ActionNone   Action = "none"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+)
+
+// Kind is the type of JetStream resource
+type Kind string
+
+const (
+	KindStream      Kind = "stream"
+	KindConsumer    Kind = "consumer"
+	KindKeyValue    Kind = "kv"
+	KindObjectStore Kind = "object_store"
+)
+
+// Consumer is a durable consumer on a stream
+type Consumer struct {
+	Stream string
+	Config nats.ConsumerConfig
+}
+
+// Spec declares the JetStream resources a service requires. Only fields set to a non zero value are
+// compared against existing resources, so server defaults don't produce updates.
+type Spec struct {
+	Streams      []nats.StreamConfig
+	Consumers    []Consumer
+	KeyValues    []nats.KeyValueConfig
+	ObjectStores []nats.ObjectStoreConfig
+}
+
+// Change describes the action for a single resource along with the fields that differ
+type Change struct {
+	Kind   Kind
+	Name   string
+	Action Action
+	Diff   []string
+}
+
+// Plan is the list of changes from Apply
+type Plan []Change
+
+// ProvisionerOpt is a functional option to modify the Provisioner
+type ProvisionerOpt func(*Provisioner)
+
+// Provisioner creates or updates JetStream resources to match a Spec
+type Provisioner struct {
+	js     nats.JetStreamContext
+	dryRun bool
+	logger *slog.Logger
+}
+
+// NewProvisioner returns a Provisioner using the JetStream context
+func NewProvisioner(js nats.JetStreamContext, opts ...ProvisionerOpt) *Provisioner {
+	p := &Provisioner{
+		js:     js,
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// SetDryRun computes the plan without creating or updating anything
+func SetDryRun(d bool) ProvisionerOpt {
+	return func(p *Provisioner) {
+		p.dryRun = d
+	}
+}
+
+// SetLogger sets the logger used to report changes
+func SetLogger(l *slog.Logger) ProvisionerOpt {
+	return func(p *Provisioner) {
+		p.logger = l
+	}
+}
+
+// Apply creates or updates every resource in the spec. Streams are applied first so consumers can bind to them.
+// The returned plan contains every change made, or that would be made in dry-run mode, up to the first error.
+func (p *Provisioner) Apply(spec Spec) (Plan, error) {
+	var plan Plan
+
+	// streams the plan creates, so a dry run can plan consumers on them
+	created := make(map[string]bool)
+	for _, v := range spec.Streams {
+		c, err := p.stream(v)
+		if err != nil {
+			return plan, fmt.Errorf("error provisioning stream %s: %w", v.Name, err)
+		}
+		plan = append(plan, c)
+		created[v.Name] = c.Action == ActionCreate
+	}
+
+	for _, v := range spec.Consumers {
+		c, err := p.consumer(v, created[v.Stream])
+		if err != nil {
+			return plan, fmt.Errorf("error provisioning consumer %s on %s: %w", v.Config.Durable, v.Stream, err)
+		}
+		plan = append(plan, c)
+	}
+
+	for _, v := range spec.KeyValues {
+		c, err := p.keyValue(v)
+		if err != nil {
+			return plan, fmt.Errorf("error provisioning kv bucket %s: %w", v.Bucket, err)
+		}
+		plan = append(plan, c)
+	}
+
+	for _, v := range spec.ObjectStores {
+		c, err := p.objectStore(v)
+		if err != nil {
+			return plan, fmt.Errorf("error provisioning object store %s: %w", v.Bucket, err)
+		}
+		plan = append(plan, c)
+	}
+
+	return plan, nil
+}
+
+func (p *Provisioner) stream(cfg nats.StreamConfig) (Change, error) {
+	c := Change{Kind: KindStream, Name: cfg.Name}
+	info, err := p.js.StreamInfo(cfg.Name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		c.Action = ActionCreate
+		return c, p.apply(c, func() error {
+			_, err := p.js.AddStream(&cfg)
+			return err
+		})
+	}
+	if err != nil {
+		return c, err
+	}
+
+	return p.updateStream(c, info.Config, cfg)
+}
+
+// updateStream merges the desired fields into the existing stream config and updates the stream if they differ
+func (p *Provisioner) updateStream(c Change, existing, desired nats.StreamConfig) (Change, error) {
+	c.Diff = diff(existing, desired)
+	if len(c.Diff) == 0 {
+		c.Action = ActionNone
+		return c, nil
+	}
+
+	c.Action = ActionUpdate
+	merge(&existing, desired)
+	return c, p.apply(c, func() error {
+		_, err := p.js.UpdateStream(&existing)
+		return err
+	})
+}
+
+// consumer creates or updates the consumer. In a dry run, a consumer on a stream the plan creates would be created
+// along with it.
+func (p *Provisioner) consumer(v Consumer, newStream bool) (Change, error) {
+	name := v.Config.Durable
+	if name == "" {
+		name = v.Config.Name
+	}
+	c := Change{Kind: KindConsumer, Name: fmt.Sprintf("%s/%s", v.Stream, name)}
+
+	info, err := p.js.ConsumerInfo(v.Stream, name)
+	if errors.Is(err, nats.ErrConsumerNotFound) || (p.dryRun && newStream && errors.Is(err, nats.ErrStreamNotFound)) {
+		c.Action = ActionCreate
+		return c, p.apply(c, func() error {
+			_, err := p.js.AddConsumer(v.Stream, &v.Config)
+			return err
+		})
+	}
+	if err != nil {
+		return c, err
+	}
+
+	existing := info.Config
+	c.Diff = diff(existing, v.Config)
+	if len(c.Diff) == 0 {
+		c.Action = ActionNone
+		return c, nil
+	}
+
+	c.Action = ActionUpdate
+	merge(&existing, v.Config)
+	return c, p.apply(c, func() error {
+		_, err := p.js.UpdateConsumer(v.Stream, &existing)
+		return err
+	})
+}
+
+// keyValue creates the bucket or updates the settings of its backing stream
+func (p *Provisioner) keyValue(cfg nats.KeyValueConfig) (Change, error) {
+	c := Change{Kind: KindKeyValue, Name: cfg.Bucket}
+	info, err := p.js.StreamInfo(fmt.Sprintf("KV_%s", cfg.Bucket))
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		c.Action = ActionCreate
+		return c, p.apply(c, func() error {
+			_, err := p.js.CreateKeyValue(&cfg)
+			return err
+		})
+	}
+	if err != nil {
+		return c, err
+	}
+
+	return p.updateStream(c, info.Config, nats.StreamConfig{
+		Description:       cfg.Description,
+		MaxMsgsPerSubject: int64(cfg.History),
+		MaxAge:            cfg.TTL,
+		MaxBytes:          cfg.MaxBytes,
+		MaxMsgSize:        cfg.MaxValueSize,
+		Replicas:          cfg.Replicas,
+	})
+}
+
+// objectStore creates the bucket or updates the settings of its backing stream
+func (p *Provisioner) objectStore(cfg nats.ObjectStoreConfig) (Change, error) {
+	c := Change{Kind: KindObjectStore, Name: cfg.Bucket}
+	info, err := p.js.StreamInfo(fmt.Sprintf("OBJ_%s", cfg.Bucket))
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		c.Action = ActionCreate
+		return c, p.apply(c, func() error {
+			_, err := p.js.CreateObjectStore(&cfg)
+			return err
+		})
+	}
+	if err != nil {
+		return c, err
+	}
+
+	return p.updateStream(c, info.Config, nats.StreamConfig{
+		Description: cfg.Description,
+		MaxAge:      cfg.TTL,
+		MaxBytes:    cfg.MaxBytes,
+		Replicas:    cfg.Replicas,
+	})
+}
+
+func (p *Provisioner) apply(c Change, f func() error) error {
+	if p.dryRun {
+		p.logger.Info(fmt.Sprintf("dry run: would %s", c))
+		return nil
+	}
+
+	p.logger.Info(c.String())
+	return f()
+}
+
+// String returns a human readable description of the change
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.Name)
+	if len(c.Diff) > 0 {
+		s = fmt.Sprintf("%s (%s)", s, strings.Join(c.Diff, ", "))
+	}
+
+	return s
+}
+
+// Changed returns the changes that create or update a resource
+func (p Plan) Changed() Plan {
+	var changed Plan
+	for _, v := range p {
+		if v.Action != ActionNone {
+			changed = append(changed, v)
+		}
+	}
+
+	return changed
+}
+
+// diff returns the fields set in desired that differ from existing
+func diff(existing, desired any) []string {
+	e := reflect.ValueOf(existing)
+	d := reflect.ValueOf(desired)
+	t := d.Type()
+
+	var diffs []string
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() || d.Field(i).IsZero() {
+			continue
+		}
+
+		if !reflect.DeepEqual(e.Field(i).Interface(), d.Field(i).Interface()) {
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", t.Field(i).Name, e.Field(i).Interface(), d.Field(i).Interface()))
+		}
+	}
+
+	return diffs
+}
+
+// merge copies the fields set in desired onto existing
+func merge[T any](existing *T, desired T) {
+	e := reflect.ValueOf(existing).Elem()
+	d := reflect.ValueOf(desired)
+	t := d.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() || d.Field(i).IsZero() {
+			continue
+		}
+		e.Field(i).Set(d.Field(i))
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provision
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// fakeJetStream has no streams or consumers
+type fakeJetStream struct {
+	nats.JetStreamContext
+}
+
+func (f fakeJetStream) StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	return nil, nats.ErrStreamNotFound
+}
+
+func (f fakeJetStream) ConsumerInfo(stream, name string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	return nil, nats.ErrStreamNotFound
+}
+
+func TestDiffAndMerge(t *testing.T) {
+	existing := nats.StreamConfig{
+		Name:      "ORDERS",
+		Subjects:  []string{"orders.>"},
+		Retention: nats.LimitsPolicy,
+		MaxAge:    time.Hour,
+		Replicas:  1,
+	}
+
+	tt := []struct {
+		name    string
+		desired nats.StreamConfig
+		diffs   int
+	}{
+		{name: "unchanged", desired: nats.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}}, diffs: 0},
+		{name: "zero fields ignored", desired: nats.StreamConfig{Name: "ORDERS"}, diffs: 0},
+		{name: "changed", desired: nats.StreamConfig{Name: "ORDERS", MaxAge: 2 * time.Hour, Replicas: 3}, diffs: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			d := diff(existing, v.desired)
+			if len(d) != v.diffs {
+				t.Fatalf("expected %d diffs but got %v", v.diffs, d)
+			}
+
+			merged := existing
+			merge(&merged, v.desired)
+			if len(diff(merged, v.desired)) != 0 {
+				t.Errorf("expected merged config to match desired, got %+v", merged)
+			}
+
+			if !reflect.DeepEqual(merged.Subjects, existing.Subjects) {
+				t.Errorf("expected unset fields to be preserved, got %v", merged.Subjects)
+			}
+		})
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	tt := []struct {
+		name   string
+		spec   Spec
+		plan   []string
+		errNil bool
+	}{
+		{
+			name: "consumer on a new stream",
+			spec: Spec{
+				Streams:   []nats.StreamConfig{{Name: "ORDERS", Subjects: []string{"orders.>"}}},
+				Consumers: []Consumer{{Stream: "ORDERS", Config: nats.ConsumerConfig{Durable: "billing"}}},
+			},
+			plan:   []string{"create stream ORDERS", "create consumer ORDERS/billing"},
+			errNil: true,
+		},
+		{
+			name: "consumer on a missing stream",
+			spec: Spec{
+				Consumers: []Consumer{{Stream: "ORDERS", Config: nats.ConsumerConfig{Durable: "billing"}}},
+			},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			p := NewProvisioner(fakeJetStream{}, SetDryRun(true), SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			plan, err := p.Apply(v.spec)
+			if v.errNil && err != nil {
+				t.Fatal(err)
+			}
+			if !v.errNil && !errors.Is(err, nats.ErrStreamNotFound) {
+				t.Fatalf("expected %v but got %v", nats.ErrStreamNotFound, err)
+			}
+
+			var got []string
+			for _, c := range plan {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, v.plan) {
+				t.Errorf("expected plan %v but got %v", v.plan, got)
+			}
+		})
+	}
+}
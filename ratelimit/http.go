@@ -0,0 +1,239 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go/micro"
+)
+
+const (
+	LimitHeader     = "X-RateLimit-Limit"
+	RemainingHeader = "X-RateLimit-Remaining"
+	ResetHeader     = "X-RateLimit-Reset"
+)
+
+// tightest returns the usage with the fewest units remaining
+func tightest(usages []Usage) (Usage, bool) {
+	if len(usages) == 0 {
+		return Usage{}, false
+	}
+	t := usages[0]
+	for _, u := range usages[1:] {
+		if u.Remaining < t.Remaining {
+			t = u
+		}
+	}
+	return t, true
+}
+
+// Headers returns the quota headers for the usage closest to its limit. Reset is in seconds.
+func Headers(usages []Usage, now time.Time) map[string]string {
+	u, ok := tightest(usages)
+	if !ok {
+		return nil
+	}
+
+	return map[string]string{
+		LimitHeader:     strconv.FormatInt(u.Max, 10),
+		RemainingHeader: strconv.FormatInt(u.Remaining, 10),
+		ResetHeader:     strconv.Itoa(int(u.Reset.Sub(now).Seconds())),
+	}
+}
+
+// clientError returns err as a client error if the caller caused it
+func clientError(err error) error {
+	switch {
+	case errors.Is(err, ErrQuotaExceeded):
+		return sderrors.NewClientError(err, http.StatusTooManyRequests)
+	case errors.Is(err, ErrInvalidKey):
+		return sderrors.NewClientError(err, http.StatusBadRequest)
+	}
+
+	return err
+}
+
+// Middleware takes one unit of the quota of the key returned by key for every request, responding with a 429
+// once it is used up. Requests without a key aren't counted. Responses get the quota headers.
+func (q *Quotas) Middleware(key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+
+			usages, err := q.Take(r.Context(), k, 1)
+			for h, v := range Headers(usages, q.now()) {
+				w.Header().Set(h, v)
+			}
+			if errors.Is(err, ErrQuotaExceeded) {
+				w.Header().Set("Retry-After", w.Header().Get(ResetHeader))
+			}
+			if err != nil {
+				return clientError(err)
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		}}
+	}
+}
+
+// MicroHandler takes one unit of the quota of the key returned by key for every request, responding with a 429
+// error once it is used up. Responses get the quota headers.
+func (q *Quotas) MicroHandler(key func(micro.Request) string, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		k := key(r)
+		if k == "" {
+			h.Handle(r)
+			return
+		}
+
+		usages, err := q.Take(context.Background(), k, 1)
+		headers := micro.Headers{}
+		for k, v := range Headers(usages, q.now()) {
+			headers[k] = []string{v}
+		}
+
+		var ce sderrors.ClientError
+		switch err := clientError(err); {
+		case errors.As(err, &ce):
+			r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body(), micro.WithHeaders(headers))
+			return
+		case err != nil:
+			q.logger.Error(fmt.Sprintf("error taking quota for %s: %v", k, err))
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		h.Handle(&quotaRequest{Request: r, headers: headers})
+	})
+}
+
+// quotaRequest adds the quota headers to the handler's response
+type quotaRequest struct {
+	micro.Request
+	headers micro.Headers
+}
+
+func (r *quotaRequest) with(opts []micro.RespondOpt) []micro.RespondOpt {
+	return append([]micro.RespondOpt{micro.WithHeaders(r.headers)}, opts...)
+}
+
+func (r *quotaRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	return r.Request.Respond(data, r.with(opts)...)
+}
+
+func (r *quotaRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	return r.Request.RespondJSON(v, r.with(opts)...)
+}
+
+func (r *quotaRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	return r.Request.Error(code, description, data, r.with(opts)...)
+}
+
+// Routes returns HTTP routes to inspect and reset a key's usage. Mount them behind authentication.
+//
+//	GET    /quotas/{key}
+//	DELETE /quotas/{key}
+func (q *Quotas) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/quotas/{key}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				usages, err := q.Usage(r.Context(), r.PathValue("key"))
+				if err != nil {
+					return clientError(err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				return json.NewEncoder(w).Encode(usages)
+			}},
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/quotas/{key}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				if err := q.Reset(r.Context(), r.PathValue("key")); err != nil {
+					return clientError(err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}},
+		},
+	}
+}
+
+type quotaRequestBody struct {
+	Key string `json:"key"`
+}
+
+// Endpoints returns NATS endpoints to inspect and reset a key's usage. Both take a JSON body like
+// {"key": "acme"} and respond with the key's usage. Restrict who can publish to them with account permissions.
+func (q *Quotas) Endpoints() []sdnats.Endpoint {
+	return []sdnats.Endpoint{
+		{
+			Name:    "quotas-usage",
+			Subject: "quotas.usage",
+			Handler: q.adminHandler(func(r micro.Request, key string) error { return nil }),
+		},
+		{
+			Name:    "quotas-reset",
+			Subject: "quotas.reset",
+			Handler: q.adminHandler(func(r micro.Request, key string) error {
+				return q.Reset(context.Background(), key)
+			}),
+		},
+	}
+}
+
+func (q *Quotas) adminHandler(fn func(micro.Request, string) error) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		var req quotaRequestBody
+		var usages []Usage
+		err := json.Unmarshal(r.Data(), &req)
+		if err != nil {
+			err = sderrors.NewClientError(err, http.StatusBadRequest)
+		} else if err = clientError(fn(r, req.Key)); err == nil {
+			usages, err = q.Usage(context.Background(), req.Key)
+			err = clientError(err)
+		}
+
+		var ce sderrors.ClientError
+		switch {
+		case errors.As(err, &ce):
+			r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body())
+			return
+		case err != nil:
+			q.logger.Error(fmt.Sprintf("quota request failed: %v", err))
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		r.RespondJSON(usages)
+	})
+}
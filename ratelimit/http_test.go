@@ -0,0 +1,93 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	q, _ := newTestQuotas(&now)
+	h := q.Middleware(func(r *http.Request) string { return r.Header.Get("X-API-Key") })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tt := []struct {
+		name      string
+		key       string
+		code      int
+		remaining string
+	}{
+		{name: "first", key: "acme", code: http.StatusOK, remaining: "2"},
+		{name: "second", key: "acme", code: http.StatusOK, remaining: "1"},
+		{name: "third", key: "acme", code: http.StatusOK, remaining: "0"},
+		{name: "exceeded", key: "acme", code: http.StatusTooManyRequests, remaining: "0"},
+		{name: "anonymous", code: http.StatusOK},
+		{name: "invalid", key: "acme corp", code: http.StatusBadRequest},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			req.Header.Set("X-API-Key", v.key)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != v.code {
+				t.Errorf("expected %d but got %d: %s", v.code, rr.Code, rr.Body.String())
+			}
+			if got := rr.Header().Get(RemainingHeader); got != v.remaining {
+				t.Errorf("expected %q remaining but got %q", v.remaining, got)
+			}
+			if v.remaining != "" && rr.Header().Get(ResetHeader) != "43200" {
+				t.Errorf("expected the daily window to reset in 12 hours but got %s", rr.Header().Get(ResetHeader))
+			}
+			if v.code == http.StatusTooManyRequests && rr.Header().Get("Retry-After") != "43200" {
+				t.Errorf("expected Retry-After but got %q", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	q, _ := newTestQuotas(&now)
+	q.Take(context.Background(), "acme", 2)
+
+	mux := http.NewServeMux()
+	for _, r := range q.Routes() {
+		mux.Handle(r.Method+" "+r.Path, r.Handler)
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/quotas/acme", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"period":"day","used":2,"max":3,"remaining":1`) {
+		t.Errorf("expected the usage but got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/quotas/acme", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected 204 but got %d", rr.Code)
+	}
+	if usages, _ := q.Usage(context.Background(), "acme"); usages[0].Used != 0 {
+		t.Errorf("expected the usage to be reset but got %+v", usages)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit enforces per key usage limits. Quotas count requests per API key or tenant over days and
+// months in a KV bucket, so every instance of a service shares the counts and they survive restarts.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var (
+	ErrQuotaExceeded = fmt.Errorf("quota exceeded")
+	ErrInvalidKey    = fmt.Errorf("quota keys are letters, numbers, dashes, and underscores")
+)
+
+var validKey = regexp.MustCompile(`^[-_a-zA-Z0-9]+$`)
+
+// Period is the window a quota counts over. Windows start at midnight UTC.
+type Period string
+
+const (
+	Daily   Period = "day"
+	Monthly Period = "month"
+)
+
+// start returns the beginning of the window containing t
+func (p Period) start(t time.Time) time.Time {
+	t = t.UTC()
+	if p == Monthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// end returns the beginning of the next window
+func (p Period) end(t time.Time) time.Time {
+	if p == Monthly {
+		return p.start(t).AddDate(0, 1, 0)
+	}
+	return p.start(t).AddDate(0, 0, 1)
+}
+
+func (p Period) window(t time.Time) string {
+	if p == Monthly {
+		return p.start(t).Format("200601")
+	}
+	return p.start(t).Format("20060102")
+}
+
+// Limit is the most units a key may use in a period
+type Limit struct {
+	Period Period `json:"period"`
+	Max    int64  `json:"max"`
+}
+
+// Usage is how much of a limit a key has used in the current window
+type Usage struct {
+	Key       string    `json:"key"`
+	Period    Period    `json:"period"`
+	Used      int64     `json:"used"`
+	Max       int64     `json:"max"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// QuotasOpt is a functional option to modify Quotas
+type QuotasOpt func(*Quotas)
+
+// Quotas counts usage per key against daily and monthly limits
+type Quotas struct {
+	kv     nats.KeyValue
+	limits func(key string) []Limit
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// SetLimits sets the limits every key gets
+func SetLimits(l ...Limit) QuotasOpt {
+	return func(q *Quotas) {
+		q.limits = func(string) []Limit { return l }
+	}
+}
+
+// SetKeyLimits sets a function returning the limits of a key, such as the plan of a tenant
+func SetKeyLimits(f func(key string) []Limit) QuotasOpt {
+	return func(q *Quotas) {
+		q.limits = f
+	}
+}
+
+// SetLogger sets the logger used to report failed quota updates
+func SetLogger(l *slog.Logger) QuotasOpt {
+	return func(q *Quotas) {
+		q.logger = l
+	}
+}
+
+// NewQuotas returns Quotas counting in kv under <key>.<period>.<window>. Counters of past windows stay in the
+// bucket, so give it a TTL longer than a month.
+func NewQuotas(kv nats.KeyValue, opts ...QuotasOpt) *Quotas {
+	q := &Quotas{
+		kv:     kv,
+		limits: func(string) []Limit { return nil },
+		logger: slog.Default(),
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+func counterKey(key string, p Period, now time.Time) string {
+	return fmt.Sprintf("%s.%s.%s", key, p, p.window(now))
+}
+
+// counter returns a counter's value and revision, with a zero revision if it doesn't exist yet
+func (q *Quotas) counter(name string) (int64, uint64, error) {
+	e, err := q.kv.Get(name)
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err := strconv.ParseInt(string(e.Value()), 10, 64)
+	return n, e.Revision(), err
+}
+
+// add adds n to a counter with compare and swap, refusing to go over limit. It returns the new value.
+func (q *Quotas) add(name string, n, limit int64) (int64, error) {
+	for {
+		used, rev, err := q.counter(name)
+		if err != nil {
+			return used, err
+		}
+		if n > 0 && used+n > limit {
+			return used, ErrQuotaExceeded
+		}
+
+		value := []byte(strconv.FormatInt(max(used+n, 0), 10))
+		if rev == 0 {
+			_, err = q.kv.Create(name, value)
+		} else {
+			_, err = q.kv.Update(name, value, rev)
+		}
+		if errors.Is(err, nats.ErrKeyExists) {
+			continue
+		}
+
+		return used + n, err
+	}
+}
+
+func usage(key string, l Limit, used int64, now time.Time) Usage {
+	return Usage{
+		Key:       key,
+		Period:    l.Period,
+		Used:      used,
+		Max:       l.Max,
+		Remaining: max(l.Max-used, 0),
+		Reset:     l.Period.end(now),
+	}
+}
+
+// Take uses n units of the key's quotas. If any limit would be exceeded nothing is used and the error wraps
+// ErrQuotaExceeded. The usage is returned either way so callers can set quota headers.
+func (q *Quotas) Take(ctx context.Context, key string, n int64) ([]Usage, error) {
+	if !validKey.MatchString(key) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	now := q.now()
+	limits := q.limits(key)
+	usages := make([]Usage, 0, len(limits))
+	for i, l := range limits {
+		used, err := q.add(counterKey(key, l.Period, now), n, l.Max)
+		if err == nil {
+			usages = append(usages, usage(key, l, used, now))
+			continue
+		}
+
+		// give back what was taken from the limits before this one
+		for _, taken := range limits[:i] {
+			if _, rerr := q.add(counterKey(key, taken.Period, now), -n, taken.Max); rerr != nil {
+				q.logger.Error(fmt.Sprintf("error returning quota for %s: %v", key, rerr))
+			}
+		}
+		if errors.Is(err, ErrQuotaExceeded) {
+			current, _ := q.Usage(ctx, key)
+			return current, fmt.Errorf("%w: %s %s limit of %d", ErrQuotaExceeded, key, l.Period, l.Max)
+		}
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+// Usage returns the key's usage of each of its limits in the current windows
+func (q *Quotas) Usage(ctx context.Context, key string) ([]Usage, error) {
+	if !validKey.MatchString(key) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	now := q.now()
+	limits := q.limits(key)
+	usages := make([]Usage, 0, len(limits))
+	for _, l := range limits {
+		used, _, err := q.counter(counterKey(key, l.Period, now))
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage(key, l, used, now))
+	}
+
+	return usages, nil
+}
+
+// Reset clears the key's usage in the current windows
+func (q *Quotas) Reset(ctx context.Context, key string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	now := q.now()
+	for _, l := range q.limits(key) {
+		if err := q.kv.Delete(counterKey(key, l.Period, now)); err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+)
+
+func newTestQuotas(now *time.Time) (*Quotas, *kvtest.KV) {
+	kv := kvtest.New()
+	q := NewQuotas(kv, SetKeyLimits(func(key string) []Limit {
+		if key == "enterprise" {
+			return []Limit{{Period: Daily, Max: 1000}}
+		}
+		return []Limit{{Period: Daily, Max: 3}, {Period: Monthly, Max: 4}}
+	}))
+	q.now = func() time.Time { return *now }
+	return q, kv
+}
+
+func TestTake(t *testing.T) {
+	now := time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC)
+	q, kv := newTestQuotas(&now)
+	ctx := context.Background()
+
+	tt := []struct {
+		name      string
+		advance   time.Duration
+		exceeded  bool
+		day       int64
+		month     int64
+		remaining int64
+	}{
+		{name: "first", day: 1, month: 1, remaining: 2},
+		{name: "second", day: 2, month: 2, remaining: 1},
+		{name: "third", day: 3, month: 3, remaining: 0},
+		{name: "daily limit", exceeded: true, day: 3, month: 3},
+		// the next day is in a new month too
+		{name: "new day", advance: 3 * time.Hour, day: 1, month: 1, remaining: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			now = now.Add(v.advance)
+			usages, err := q.Take(ctx, "acme", 1)
+			if errors.Is(err, ErrQuotaExceeded) != v.exceeded {
+				t.Fatalf("expected exceeded to be %v but got %v", v.exceeded, err)
+			}
+			if len(usages) != 2 || usages[0].Used != v.day || usages[1].Used != v.month {
+				t.Fatalf("expected %d used today and %d this month but got %+v", v.day, v.month, usages)
+			}
+			if u, _ := tightest(usages); u.Remaining != v.remaining {
+				t.Errorf("expected %d remaining but got %d", v.remaining, u.Remaining)
+			}
+		})
+	}
+
+	// a request exceeding the monthly limit doesn't use the daily one
+	if _, err := q.Take(ctx, "acme", 1); err != nil {
+		t.Fatal(err)
+	}
+	usages, err := q.Take(ctx, "acme", 3)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected %v but got %v", ErrQuotaExceeded, err)
+	}
+	if usages[0].Used != 2 {
+		t.Errorf("expected the daily usage to be returned but got %d", usages[0].Used)
+	}
+
+	if got := string(kv.Value("acme.month.202502")); got != "2" {
+		t.Errorf("expected the monthly counter under its window but got %q", got)
+	}
+	if err := q.Reset(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	usages, err = q.Usage(ctx, "acme")
+	if err != nil || usages[0].Used != 0 || usages[1].Used != 0 {
+		t.Errorf("expected the usage to be reset but got %+v: %v", usages, err)
+	}
+	if usages[0].Reset != time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC) || usages[1].Reset != time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("expected the windows to reset at midnight UTC but got %+v", usages)
+	}
+
+	if _, err := q.Take(ctx, "acme.*", 1); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected %v but got %v", ErrInvalidKey, err)
+	}
+}
+
+func TestTakeConcurrent(t *testing.T) {
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	q, _ := newTestQuotas(&now)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	taken := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.Take(context.Background(), "enterprise", 1); err == nil {
+				mu.Lock()
+				taken++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	usages, err := q.Usage(context.Background(), "enterprise")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taken != 50 || usages[0].Used != 50 {
+		t.Errorf("expected every concurrent request to be counted but got %d taken and %d used", taken, usages[0].Used)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry is a live catalog of running service instances. Each instance heartbeats its metadata to
+// a well-known KV bucket, and the catalog can be listed from any service or with `sgoctl services list`.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// DefaultBucket is the KV bucket instances register in
+const DefaultBucket = "sencillo_services"
+
+const (
+	HealthOK        = "ok"
+	HealthUnhealthy = "unhealthy"
+)
+
+var ErrInvalidInstance = fmt.Errorf("instances need a name and id")
+
+// Endpoint is a NATS subject or HTTP route an instance serves
+type Endpoint struct {
+	Name       string            `json:"name"`
+	Subject    string            `json:"subject,omitempty"`
+	QueueGroup string            `json:"queue_group,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Instance is the metadata a running instance publishes on every heartbeat
+type Instance struct {
+	Name      string            `json:"name"`
+	ID        string            `json:"id"`
+	Version   string            `json:"version"`
+	Endpoints []Endpoint        `json:"endpoints,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	// Health is HealthOK or the reason the instance is unhealthy
+	Health    string    `json:"health"`
+	Started   time.Time `json:"started"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+func (i Instance) key() string {
+	return i.Name + "." + i.ID
+}
+
+// FromMicro describes a micro service and its endpoints
+func FromMicro(svc micro.Service) Instance {
+	info := svc.Info()
+	inst := Instance{
+		Name:     info.Name,
+		ID:       info.ID,
+		Version:  info.Version,
+		Metadata: info.Metadata,
+		Started:  svc.Stats().Started,
+	}
+
+	for _, v := range info.Endpoints {
+		inst.Endpoints = append(inst.Endpoints, Endpoint{
+			Name:       v.Name,
+			Subject:    v.Subject,
+			QueueGroup: v.QueueGroup,
+			Metadata:   v.Metadata,
+		})
+	}
+
+	return inst
+}
+
+// Registry reads and writes instances in a KV bucket
+type Registry struct {
+	kv         nats.KeyValue
+	staleAfter time.Duration
+}
+
+type RegistryOpt func(*Registry)
+
+// SetStaleAfter sets how long after its last heartbeat was stored an instance is left out of listings
+func SetStaleAfter(d time.Duration) RegistryOpt {
+	return func(r *Registry) {
+		r.staleAfter = d
+	}
+}
+
+// NewRegistry uses the bucket in kv. Instances are stale 30 seconds after their last heartbeat by default.
+func NewRegistry(kv nats.KeyValue, opts ...RegistryOpt) *Registry {
+	r := &Registry{
+		kv:         kv,
+		staleAfter: 30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// CreateBucket creates the bucket if it doesn't exist. Entries expire after the ttl so instances that stop
+// without deregistering are removed.
+func CreateBucket(js nats.KeyValueManager, bucket string, ttl time.Duration) (nats.KeyValue, error) {
+	kv, err := js.KeyValue(bucket)
+	if err == nil {
+		return kv, nil
+	}
+	if !errors.Is(err, nats.ErrBucketNotFound) {
+		return nil, err
+	}
+
+	return js.CreateKeyValue(&nats.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "sencillo service registry",
+		TTL:         ttl,
+	})
+}
+
+// Put writes the instance with the current time as its heartbeat
+func (r *Registry) Put(inst Instance) error {
+	if inst.Name == "" || inst.ID == "" {
+		return ErrInvalidInstance
+	}
+	if inst.Health == "" {
+		inst.Health = HealthOK
+	}
+	inst.Heartbeat = time.Now().UTC()
+
+	b, err := json.Marshal(inst)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.kv.Put(inst.key(), b)
+	return err
+}
+
+// Deregister removes the instance
+func (r *Registry) Deregister(inst Instance) error {
+	return r.kv.Delete(inst.key())
+}
+
+// List returns the instances that heartbeated recently, sorted by name and id. Staleness is decided by when the
+// server stored the heartbeat, so a skewed clock on the instance doesn't hide it or keep it listed.
+func (r *Registry) List(ctx context.Context) ([]Instance, error) {
+	keys, err := r.kv.Keys(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Instance
+	for _, k := range keys {
+		entry, err := r.kv.Get(k)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var inst Instance
+		if err := json.Unmarshal(entry.Value(), &inst); err != nil {
+			return nil, fmt.Errorf("invalid instance %s: %w", k, err)
+		}
+		if time.Since(entry.Created()) > r.staleAfter {
+			continue
+		}
+		out = append(out, inst)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+
+	return out, nil
+}
+
+// Service returns the live instances of the named service
+func (r *Registry) Service(ctx context.Context, name string) ([]Instance, error) {
+	all, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Instance
+	for _, v := range all {
+		if v.Name == name {
+			out = append(out, v)
+		}
+	}
+
+	return out, nil
+}
+
+// HeartbeatOpt is a functional option to modify Heartbeat
+type HeartbeatOpt func(*heartbeatConfig)
+
+type heartbeatConfig struct {
+	interval time.Duration
+	health   func(context.Context) error
+	logger   *slog.Logger
+}
+
+// SetHeartbeatInterval sets how often the instance is written
+func SetHeartbeatInterval(d time.Duration) HeartbeatOpt {
+	return func(h *heartbeatConfig) {
+		h.interval = d
+	}
+}
+
+// SetHealthCheck sets a check run before every heartbeat. Its error is published as the instance's health.
+func SetHealthCheck(f func(context.Context) error) HeartbeatOpt {
+	return func(h *heartbeatConfig) {
+		h.health = f
+	}
+}
+
+// SetHeartbeatLogger sets the logger used to report failed writes
+func SetHeartbeatLogger(l *slog.Logger) HeartbeatOpt {
+	return func(h *heartbeatConfig) {
+		h.logger = l
+	}
+}
+
+// Heartbeat writes the instance every interval until the context is cancelled and then deregisters it. The
+// instance's Started time defaults to when Heartbeat is called. Failed writes are logged and retried on the
+// next interval.
+func (r *Registry) Heartbeat(ctx context.Context, inst Instance, opts ...HeartbeatOpt) error {
+	cfg := heartbeatConfig{
+		interval: 10 * time.Second,
+		logger:   slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if inst.Started.IsZero() {
+		inst.Started = time.Now().UTC()
+	}
+
+	ticker := time.NewTicker(cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		inst.Health = HealthOK
+		if cfg.health != nil {
+			if err := cfg.health(ctx); err != nil {
+				inst.Health = fmt.Sprintf("%s: %v", HealthUnhealthy, err)
+			}
+		}
+
+		if err := r.Put(inst); err != nil {
+			if errors.Is(err, ErrInvalidInstance) {
+				return err
+			}
+			cfg.logger.Error(fmt.Sprintf("error registering %s: %v", inst.key(), err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return r.Deregister(inst)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go"
+)
+
+func TestList(t *testing.T) {
+	kv := kvtest.New()
+	r := NewRegistry(kv, SetStaleAfter(time.Minute))
+
+	got, err := r.List(context.Background())
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected an empty registry but got %v, %v", got, err)
+	}
+
+	for _, v := range []Instance{
+		{Name: "users", ID: "b", Version: "1.0.0"},
+		{Name: "orders", ID: "a", Version: "2.0.0"},
+		{Name: "users", ID: "a", Version: "1.1.0", Health: "unhealthy: db down"},
+	} {
+		if err := r.Put(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the instance's clocks are skewed, so only the stored time decides staleness
+	stale, _ := json.Marshal(Instance{Name: "users", ID: "c", Heartbeat: time.Now()})
+	kv.Now = func() time.Time { return time.Now().Add(-time.Hour) }
+	kv.Put("users.c", stale)
+	kv.Now = time.Now
+	fresh, _ := json.Marshal(Instance{Name: "users", ID: "d", Health: HealthOK, Heartbeat: time.Now().Add(-time.Hour)})
+	kv.Put("users.d", fresh)
+
+	got, err = r.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, v := range got {
+		ids = append(ids, v.Name+"."+v.ID+"="+v.Health)
+	}
+	want := "orders.a=ok users.a=unhealthy: db down users.b=ok users.d=ok"
+	if strings.Join(ids, " ") != want {
+		t.Errorf("expected %s but got %s", want, strings.Join(ids, " "))
+	}
+
+	users, err := r.Service(context.Background(), "users")
+	if err != nil || len(users) != 3 {
+		t.Errorf("expected 3 users instances but got %v, %v", users, err)
+	}
+
+	if err := r.Put(Instance{Name: "users"}); !errors.Is(err, ErrInvalidInstance) {
+		t.Errorf("expected %v but got %v", ErrInvalidInstance, err)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	kv := kvtest.New()
+	r := NewRegistry(kv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	unhealthy := errors.New("db down")
+	go func() {
+		done <- r.Heartbeat(ctx, Instance{Name: "users", ID: "a"},
+			SetHeartbeatInterval(5*time.Millisecond),
+			SetHealthCheck(func(context.Context) error { return unhealthy }),
+		)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		if kv.Revision() >= 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for heartbeats")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	got, err := r.List(context.Background())
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected 1 instance but got %v, %v", got, err)
+	}
+	if got[0].Health != "unhealthy: db down" || got[0].Started.IsZero() {
+		t.Errorf("unexpected instance %+v", got[0])
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Get("users.a"); !errors.Is(err, nats.ErrKeyNotFound) {
+		t.Errorf("expected the instance to be deregistered but got %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay reads a range of messages from a JetStream stream and republishes them, so messages can be
+// reprocessed after a bug fix
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Headers added to every replayed message so consumers can tell a replay from the original
+const (
+	StreamHeader   = "Sencillo-Replay-Stream"
+	SequenceHeader = "Sencillo-Replay-Sequence"
+	TimeHeader     = "Sencillo-Replay-Time"
+)
+
+var (
+	ErrNoStream  = fmt.Errorf("a stream is required")
+	ErrNoSubject = fmt.Errorf("a subject filter is required")
+	ErrNoTarget  = fmt.Errorf("a target subject or prefix is required")
+)
+
+// Range selects the messages to replay. Sequences take precedence over times when both are set, and an unset
+// end replays up to the last message in the stream when the replay started.
+type Range struct {
+	Stream string
+	// Subject filters the stream and can contain wildcards
+	Subject  string
+	StartSeq uint64
+	EndSeq   uint64
+	Start    time.Time
+	End      time.Time
+}
+
+// Stats describes a finished replay
+type Stats struct {
+	Read      int
+	Published int
+	FirstSeq  uint64
+	LastSeq   uint64
+	Duration  time.Duration
+}
+
+// HeaderRewrite changes the headers of a message before it is republished
+type HeaderRewrite func(nats.Header)
+
+// SetHeader sets a header on every replayed message
+func SetHeader(key, value string) HeaderRewrite {
+	return func(h nats.Header) {
+		h.Set(key, value)
+	}
+}
+
+// RemoveHeader removes a header from every replayed message
+func RemoveHeader(key string) HeaderRewrite {
+	return func(h nats.Header) {
+		h.Del(key)
+	}
+}
+
+// RenameHeader moves the values of a header to another key
+func RenameHeader(from, to string) HeaderRewrite {
+	return func(h nats.Header) {
+		values := h.Values(from)
+		if len(values) == 0 {
+			return
+		}
+		h.Del(from)
+		for _, v := range values {
+			h.Add(to, v)
+		}
+	}
+}
+
+// Publisher publishes a message. *nats.Conn implements it.
+type Publisher interface {
+	PublishMsg(*nats.Msg) error
+}
+
+// ReplayerOpt is a functional option to modify the Replayer
+type ReplayerOpt func(*Replayer)
+
+// Replayer republishes a range of a stream to a target subject at a controlled rate
+type Replayer struct {
+	js       nats.JetStreamContext
+	pub      Publisher
+	target   func(subject string) string
+	rewrites []HeaderRewrite
+	rate     float64
+	idle     time.Duration
+	logger   *slog.Logger
+}
+
+// SetTarget republishes every message to subject
+func SetTarget(subject string) ReplayerOpt {
+	return func(r *Replayer) {
+		r.target = func(string) string { return subject }
+	}
+}
+
+// SetTargetPrefix republishes every message to its original subject with prefix and a dot in front
+func SetTargetPrefix(prefix string) ReplayerOpt {
+	return func(r *Replayer) {
+		r.target = func(s string) string { return prefix + "." + s }
+	}
+}
+
+// SetRate limits the replay to perSecond messages. Zero or less doesn't limit it.
+func SetRate(perSecond float64) ReplayerOpt {
+	return func(r *Replayer) {
+		r.rate = perSecond
+	}
+}
+
+// SetHeaderRewrites sets the header rewrites applied in order to every message
+func SetHeaderRewrites(rw ...HeaderRewrite) ReplayerOpt {
+	return func(r *Replayer) {
+		r.rewrites = append(r.rewrites, rw...)
+	}
+}
+
+// SetIdleTimeout sets how long to wait for the next message before the replay is considered finished
+func SetIdleTimeout(d time.Duration) ReplayerOpt {
+	return func(r *Replayer) {
+		r.idle = d
+	}
+}
+
+// SetLogger sets the logger used to report progress
+func SetLogger(l *slog.Logger) ReplayerOpt {
+	return func(r *Replayer) {
+		r.logger = l
+	}
+}
+
+// NewReplayer returns a Replayer reading from js and publishing with pub. Pass nil for pub to do a dry run that
+// only reads the range.
+func NewReplayer(js nats.JetStreamContext, pub Publisher, opts ...ReplayerOpt) *Replayer {
+	r := &Replayer{
+		js:     js,
+		pub:    pub,
+		idle:   5 * time.Second,
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run replays the range. Messages get the replay headers and lose Nats-Msg-Id and Nats-Expected-* headers so
+// they aren't dropped as duplicates or rejected by the target stream. Run stops at the end of the range, after
+// the idle timeout, or when ctx is done.
+func (r *Replayer) Run(ctx context.Context, rng Range) (Stats, error) {
+	if err := r.validate(rng); err != nil {
+		return Stats{}, err
+	}
+
+	info, err := r.js.StreamInfo(rng.Stream, nats.Context(ctx))
+	if err != nil {
+		return Stats{}, err
+	}
+	// messages replayed into the same stream would otherwise be read again
+	if rng.EndSeq == 0 || rng.EndSeq > info.State.LastSeq {
+		rng.EndSeq = info.State.LastSeq
+	}
+
+	start := nats.DeliverAll()
+	switch {
+	case rng.StartSeq > 0:
+		start = nats.StartSequence(rng.StartSeq)
+	case !rng.Start.IsZero():
+		start = nats.StartTime(rng.Start)
+	}
+
+	sub, err := r.js.SubscribeSync(rng.Subject, nats.BindStream(rng.Stream), nats.OrderedConsumer(), start)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer sub.Unsubscribe()
+
+	return r.replay(ctx, rng, func(ctx context.Context) (*nats.Msg, *nats.MsgMetadata, error) {
+		ctx, cancel := context.WithTimeout(ctx, r.idle)
+		defer cancel()
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta, err := msg.Metadata()
+		return msg, meta, err
+	})
+}
+
+func (r *Replayer) validate(rng Range) error {
+	var errs []error
+	if rng.Stream == "" {
+		errs = append(errs, ErrNoStream)
+	}
+	if rng.Subject == "" {
+		errs = append(errs, ErrNoSubject)
+	}
+	if r.target == nil && r.pub != nil {
+		errs = append(errs, ErrNoTarget)
+	}
+
+	return errors.Join(errs...)
+}
+
+// next returns the next message from the stream and its metadata
+type next func(context.Context) (*nats.Msg, *nats.MsgMetadata, error)
+
+// replay republishes messages returned by next until the range ends
+func (r *Replayer) replay(ctx context.Context, rng Range, next next) (Stats, error) {
+	var stats Stats
+	begin := time.Now()
+	defer func() { stats.Duration = time.Since(begin) }()
+
+	var interval time.Duration
+	if r.rate > 0 {
+		interval = time.Duration(float64(time.Second) / r.rate)
+	}
+	nextSend := time.Now()
+
+	for {
+		if rng.EndSeq > 0 && stats.LastSeq >= rng.EndSeq {
+			return stats, nil
+		}
+
+		msg, meta, err := next(ctx)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			r.logger.Info("no more messages to replay", "read", stats.Read)
+			return stats, nil
+		case err != nil:
+			return stats, err
+		}
+
+		if rng.EndSeq > 0 && meta.Sequence.Stream > rng.EndSeq {
+			return stats, nil
+		}
+		if rng.StartSeq == 0 && !rng.End.IsZero() && meta.Timestamp.After(rng.End) {
+			return stats, nil
+		}
+
+		stats.Read++
+		if stats.FirstSeq == 0 {
+			stats.FirstSeq = meta.Sequence.Stream
+		}
+		stats.LastSeq = meta.Sequence.Stream
+
+		if r.pub == nil {
+			continue
+		}
+
+		if interval > 0 {
+			if err := wait(ctx, time.Until(nextSend)); err != nil {
+				return stats, err
+			}
+			nextSend = nextSend.Add(interval)
+			if now := time.Now(); nextSend.Before(now) {
+				nextSend = now
+			}
+		}
+
+		if err := r.pub.PublishMsg(r.rewrite(msg, meta)); err != nil {
+			return stats, fmt.Errorf("publishing sequence %d: %w", meta.Sequence.Stream, err)
+		}
+		stats.Published++
+	}
+}
+
+func (r *Replayer) rewrite(msg *nats.Msg, meta *nats.MsgMetadata) *nats.Msg {
+	out := nats.NewMsg(r.target(msg.Subject))
+	out.Data = msg.Data
+	for k, v := range msg.Header {
+		out.Header[k] = append([]string(nil), v...)
+	}
+
+	out.Header.Del(nats.MsgIdHdr)
+	out.Header.Del(nats.ExpectedStreamHdr)
+	out.Header.Del(nats.ExpectedLastSeqHdr)
+	out.Header.Del(nats.ExpectedLastSubjSeqHdr)
+	out.Header.Del(nats.ExpectedLastMsgIdHdr)
+	out.Header.Set(StreamHeader, meta.Stream)
+	out.Header.Set(SequenceHeader, strconv.FormatUint(meta.Sequence.Stream, 10))
+	out.Header.Set(TimeHeader, meta.Timestamp.UTC().Format(time.RFC3339Nano))
+
+	for _, rw := range r.rewrites {
+		rw(out.Header)
+	}
+
+	return out
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
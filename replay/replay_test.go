@@ -0,0 +1,144 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type fakePublisher struct {
+	msgs  []*nats.Msg
+	times []time.Time
+}
+
+func (f *fakePublisher) PublishMsg(m *nats.Msg) error {
+	f.msgs = append(f.msgs, m)
+	f.times = append(f.times, time.Now())
+	return nil
+}
+
+var base = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// stream returns a next func for messages with sequences 1 to n, a minute apart, followed by an idle timeout
+func stream(n int) next {
+	var seq int
+	return func(context.Context) (*nats.Msg, *nats.MsgMetadata, error) {
+		if seq == n {
+			return nil, nil, context.DeadlineExceeded
+		}
+		seq++
+		ts := base.Add(time.Duration(seq) * time.Minute)
+		msg := nats.NewMsg("orders.created")
+		msg.Data = []byte(fmt.Sprintf(`{"id": %d}`, seq))
+		msg.Header.Set(nats.MsgIdHdr, fmt.Sprint(seq))
+		msg.Header.Set("Trace-Id", "abc")
+		meta := &nats.MsgMetadata{
+			Stream:     "ORDERS",
+			Sequence:   nats.SequencePair{Stream: uint64(seq), Consumer: uint64(seq)},
+			NumPending: uint64(n - seq),
+			Timestamp:  ts,
+		}
+		return msg, meta, nil
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tt := []struct {
+		name  string
+		rng   Range
+		first uint64
+		last  uint64
+	}{
+		{name: "all", rng: Range{}, first: 1, last: 5},
+		{name: "end sequence", rng: Range{EndSeq: 3}, first: 1, last: 3},
+		{name: "end time", rng: Range{End: base.Add(2 * time.Minute)}, first: 1, last: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			r := NewReplayer(nil, pub, SetTargetPrefix("replay"), SetHeaderRewrites(
+				SetHeader("Sencillo-Reason", "bug 12"),
+				RenameHeader("Trace-Id", "Original-Trace-Id"),
+			))
+
+			stats, err := r.replay(context.Background(), v.rng, stream(5))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.FirstSeq != v.first || stats.LastSeq != v.last || stats.Published != int(v.last-v.first+1) {
+				t.Fatalf("expected sequences %d to %d but got %+v", v.first, v.last, stats)
+			}
+
+			msg := pub.msgs[0]
+			if msg.Subject != "replay.orders.created" {
+				t.Errorf("expected the prefixed subject but got %s", msg.Subject)
+			}
+			want := map[string]string{
+				nats.MsgIdHdr:       "",
+				"Trace-Id":          "",
+				"Original-Trace-Id": "abc",
+				"Sencillo-Reason":   "bug 12",
+				StreamHeader:        "ORDERS",
+				SequenceHeader:      "1",
+				TimeHeader:          "2025-03-01T12:01:00Z",
+			}
+			for k, v := range want {
+				if got := msg.Header.Get(k); got != v {
+					t.Errorf("expected header %s to be %q but got %q", k, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayRate(t *testing.T) {
+	pub := &fakePublisher{}
+	r := NewReplayer(nil, pub, SetTarget("orders.reprocess"), SetRate(100))
+
+	if _, err := r.replay(context.Background(), Range{}, stream(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := pub.times[4].Sub(pub.times[0]); elapsed < 35*time.Millisecond {
+		t.Errorf("expected 5 messages at 100/s to take about 40ms but took %s", elapsed)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	r := NewReplayer(nil, nil)
+	stats, err := r.replay(context.Background(), Range{}, stream(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Read != 3 || stats.Published != 0 {
+		t.Errorf("expected 3 messages read and none published but got %+v", stats)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := NewReplayer(nil, &fakePublisher{}).validate(Range{})
+	for _, want := range []error{ErrNoStream, ErrNoSubject, ErrNoTarget} {
+		if !errors.Is(err, want) {
+			t.Errorf("expected %v in %v", want, err)
+		}
+	}
+}
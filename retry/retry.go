@@ -0,0 +1,184 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var ErrMaxAttempts = fmt.Errorf("max attempts reached")
+
+// PolicyOpt is a functional option to modify the Policy
+type PolicyOpt func(*Policy)
+
+// Policy controls how many times an operation is attempted and how long to wait between attempts
+type Policy struct {
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	retryable   func(error) bool
+	onRetry     func(attempt int, err error, delay time.Duration)
+}
+
+// NewPolicy returns a Policy with 5 attempts and exponential backoff starting at 100ms, capped at 10s,
+// with 20% jitter
+func NewPolicy(opts ...PolicyOpt) Policy {
+	p := Policy{
+		maxAttempts: 5,
+		initial:     100 * time.Millisecond,
+		max:         10 * time.Second,
+		multiplier:  2,
+		jitter:      0.2,
+	}
+
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	return p
+}
+
+// SetMaxAttempts sets the total number of attempts. Zero or less retries until the context is done.
+func SetMaxAttempts(n int) PolicyOpt {
+	return func(p *Policy) {
+		p.maxAttempts = n
+	}
+}
+
+// SetBackoff sets the first delay, the maximum delay, and the multiplier applied after each attempt
+func SetBackoff(initial, max time.Duration, multiplier float64) PolicyOpt {
+	return func(p *Policy) {
+		p.initial = initial
+		p.max = max
+		p.multiplier = multiplier
+	}
+}
+
+// SetJitter sets the fraction, between 0 and 1, of each delay that is randomized
+func SetJitter(j float64) PolicyOpt {
+	return func(p *Policy) {
+		p.jitter = math.Max(0, math.Min(1, j))
+	}
+}
+
+// SetRetryable sets the predicate that decides if an error should be retried. By default every error is
+// retried except those wrapped with Permanent.
+func SetRetryable(f func(error) bool) PolicyOpt {
+	return func(p *Policy) {
+		p.retryable = f
+	}
+}
+
+// SetOnRetry sets a hook called before waiting for the next attempt, for logging or metrics
+func SetOnRetry(f func(attempt int, err error, delay time.Duration)) PolicyOpt {
+	return func(p *Policy) {
+		p.onRetry = f
+	}
+}
+
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string {
+	return p.err.Error()
+}
+
+func (p permanent) Unwrap() error {
+	return p.err
+}
+
+// Permanent wraps an error so it is never retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return permanent{err: err}
+}
+
+// IsPermanent reports whether the error was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var p permanent
+	return errors.As(err, &p)
+}
+
+// Backoff returns the delay before the given attempt, starting at 1 for the first retry
+func (p Policy) Backoff(attempt int) time.Duration {
+	d := float64(p.initial) * math.Pow(p.multiplier, float64(attempt-1))
+	if max := float64(p.max); p.max > 0 && d > max {
+		d = max
+	}
+
+	if p.jitter > 0 {
+		delta := d * p.jitter
+		d = d - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(d)
+}
+
+// Do calls f until it succeeds, returns a non retryable error, the attempts are exhausted, or the context is
+// done. When attempts are exhausted the last error is wrapped with ErrMaxAttempts, unless only one attempt is
+// allowed, in which case the error is returned as is.
+func (p Policy) Do(ctx context.Context, f func(context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+
+	return err
+}
+
+// DoValue is like Policy.Do for functions that return a value
+func DoValue[T any](ctx context.Context, p Policy, f func(context.Context) (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		v, err := f(ctx)
+		if err == nil {
+			return v, nil
+		}
+
+		if IsPermanent(err) || (p.retryable != nil && !p.retryable(err)) {
+			return v, err
+		}
+
+		// a single attempt is not a retry, so callers see the error they would without a policy
+		if p.maxAttempts == 1 {
+			return v, err
+		}
+		if p.maxAttempts > 0 && attempt >= p.maxAttempts {
+			return v, fmt.Errorf("%w after %d attempts: %w", ErrMaxAttempts, attempt, err)
+		}
+
+		delay := p.Backoff(attempt)
+		if p.onRetry != nil {
+			p.onRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errTemporary = fmt.Errorf("temporary")
+
+func TestDo(t *testing.T) {
+	tt := []struct {
+		name     string
+		failures int
+		err      error
+		want     error
+		attempts int
+	}{
+		{name: "success", failures: 0, attempts: 1},
+		{name: "eventual success", failures: 2, err: errTemporary, attempts: 3},
+		{name: "exhausted", failures: 10, err: errTemporary, want: ErrMaxAttempts, attempts: 4},
+		{name: "permanent", failures: 10, err: Permanent(errTemporary), want: errTemporary, attempts: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var attempts, hooks int
+			p := NewPolicy(
+				SetMaxAttempts(4),
+				SetBackoff(time.Millisecond, 5*time.Millisecond, 2),
+				SetOnRetry(func(int, error, time.Duration) { hooks++ }),
+			)
+
+			err := p.Do(context.Background(), func(context.Context) error {
+				attempts++
+				if attempts <= v.failures {
+					return v.err
+				}
+				return nil
+			})
+
+			if !errors.Is(err, v.want) {
+				t.Errorf("expected error %v but got %v", v.want, err)
+			}
+			if attempts != v.attempts || hooks != attempts-1 {
+				t.Errorf("expected %d attempts and %d hooks but got %d and %d", v.attempts, v.attempts-1, attempts, hooks)
+			}
+		})
+	}
+}
+
+func TestDoSingleAttempt(t *testing.T) {
+	p := NewPolicy(SetMaxAttempts(1))
+	err := p.Do(context.Background(), func(context.Context) error {
+		return errTemporary
+	})
+	if err != errTemporary || err.Error() != "temporary" {
+		t.Errorf("expected the original error but got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := NewPolicy(SetBackoff(100*time.Millisecond, time.Second, 2), SetJitter(0.5))
+	for attempt := 1; attempt < 10; attempt++ {
+		want := 100 * time.Millisecond << (attempt - 1)
+		if want > time.Second {
+			want = time.Second
+		}
+
+		d := p.Backoff(attempt)
+		if d < want/2 || d > want*3/2 {
+			t.Errorf("attempt %d: expected %v +/- 50%% but got %v", attempt, want, d)
+		}
+	}
+}
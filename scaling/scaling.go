@@ -0,0 +1,200 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scaling exports the signals an autoscaler scales on, such as queue depth, in-flight requests, and
+// consumer lag. Signals are read when Prometheus scrapes them or when KEDA's metrics-api scaler polls them, so
+// the value is always current.
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/metrics"
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ErrInvalidName     = fmt.Errorf("signal names must be lowercase letters, digits, and underscores")
+	ErrDuplicateSignal = fmt.Errorf("signal already registered")
+	ErrUnknownSignal   = fmt.Errorf("unknown signal")
+)
+
+var validName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Signal returns the current value of something to scale on
+type Signal func(context.Context) (float64, error)
+
+// ExporterOpt is a functional option to modify the Exporter
+type ExporterOpt func(*Exporter)
+
+// Exporter reads registered signals and exports them for autoscalers. Every signal is one series of the
+// scaling_signal gauge, labeled with its name, so a KEDA Prometheus trigger or a prometheus-adapter rule can
+// select it. The same values are served as JSON for KEDA's metrics-api trigger.
+type Exporter struct {
+	timeout time.Duration
+	logger  *slog.Logger
+
+	mu      sync.RWMutex
+	signals map[string]Signal
+
+	desc     *prometheus.Desc
+	failures *prometheus.CounterVec
+}
+
+// SetTimeout sets how long a signal can take to read. The default is 2 seconds.
+func SetTimeout(d time.Duration) ExporterOpt {
+	return func(e *Exporter) {
+		e.timeout = d
+	}
+}
+
+// SetLogger sets the logger used to report signals that couldn't be read
+func SetLogger(l *slog.Logger) ExporterOpt {
+	return func(e *Exporter) {
+		e.logger = l
+	}
+}
+
+// NewExporter returns an Exporter with no signals
+func NewExporter(opts ...ExporterOpt) *Exporter {
+	e := &Exporter{
+		timeout:  2 * time.Second,
+		logger:   slog.Default(),
+		signals:  make(map[string]Signal),
+		desc:     prometheus.NewDesc("scaling_signal", "Current value of a signal to autoscale on", []string{"signal"}, nil),
+		failures: metrics.NewCounterVec("scaling_signal_failures", "Signals that couldn't be read", []string{"signal"}),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Register adds a signal. Names are lowercase letters, digits, and underscores, such as queue_depth.
+func (e *Exporter) Register(name string, s Signal) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.signals[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateSignal, name)
+	}
+	e.signals[name] = s
+	return nil
+}
+
+// Read returns the current value of a signal
+func (e *Exporter) Read(ctx context.Context, name string) (float64, error) {
+	e.mu.RLock()
+	s, ok := e.signals[name]
+	e.mu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownSignal, name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	v, err := s(ctx)
+	if err != nil {
+		e.failures.WithLabelValues(name).Inc()
+		e.logger.Warn(fmt.Sprintf("reading scaling signal %s: %v", name, err))
+		return 0, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// Values reads every signal. Signals that couldn't be read are left out.
+func (e *Exporter) Values(ctx context.Context) map[string]float64 {
+	values := make(map[string]float64)
+	for _, name := range e.names() {
+		if v, err := e.Read(ctx, name); err == nil {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+func (e *Exporter) names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	names := make([]string, 0, len(e.signals))
+	for name := range e.signals {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Describe implements prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.desc
+}
+
+// Collect implements prometheus.Collector by reading every signal
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	for name, v := range e.Values(context.Background()) {
+		ch <- prometheus.MustNewConstMetric(e.desc, prometheus.GaugeValue, v, name)
+	}
+}
+
+// Metrics returns the exporter's collectors
+func (e *Exporter) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{e, e.failures}
+}
+
+type signalValue struct {
+	Value float64 `json:"value"`
+}
+
+// Routes returns the endpoints for KEDA's metrics-api trigger. GET /signals returns every signal by name and
+// GET /signals/{signal} returns {"value": n}, so the trigger's valueLocation is "value".
+func (e *Exporter) Routes() []sdhttp.Route {
+	return []sdhttp.Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/signals",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return sdhttp.Respond(w, r, http.StatusOK, e.Values(r.Context()))
+			}},
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/signals/{signal}",
+			Handler: &sdhttp.ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				v, err := e.Read(r.Context(), r.PathValue("signal"))
+				if errors.Is(err, ErrUnknownSignal) {
+					return sderrors.NewClientError(err, http.StatusNotFound)
+				}
+				if err != nil {
+					return sderrors.NewClientError(err, http.StatusServiceUnavailable)
+				}
+				return sdhttp.Respond(w, r, http.StatusOK, signalValue{Value: v})
+			}},
+		},
+	}
+}
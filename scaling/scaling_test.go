@@ -0,0 +1,147 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeConsumer struct {
+	info *nats.ConsumerInfo
+	err  error
+}
+
+func (f *fakeConsumer) ConsumerInfo() (*nats.ConsumerInfo, error) { return f.info, f.err }
+
+func TestExporter(t *testing.T) {
+	consumer := &fakeConsumer{info: &nats.ConsumerInfo{NumPending: 12, NumAckPending: 3}}
+	var inFlight InFlight
+	e := NewExporter(SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	for name, s := range map[string]Signal{
+		"queue_depth":  QueueDepth(consumer),
+		"consumer_lag": ConsumerLag(consumer),
+		"in_flight":    inFlight.Signal(),
+	} {
+		if err := e.Register(name, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg := sdtest.NewRegistry(t, e.Metrics()...)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	h := inFlight.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	for range 2 {
+		go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		<-started
+	}
+
+	tt := []struct {
+		signal string
+		want   float64
+	}{
+		{signal: "queue_depth", want: 15},
+		{signal: "consumer_lag", want: 12},
+		{signal: "in_flight", want: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.signal, func(t *testing.T) {
+			reg.AssertGauge(t, "scaling_signal", prometheus.Labels{"signal": v.signal}, v.want)
+		})
+	}
+	close(release)
+
+	consumer.err = errors.New("timeout")
+	if _, err := e.Read(context.Background(), "queue_depth"); err == nil {
+		t.Error("expected an error reading the queue depth")
+	}
+	// gathering the counter scrapes the signals again
+	reg.AssertCounter(t, "scaling_signal_failures", prometheus.Labels{"signal": "queue_depth"}, 2)
+}
+
+func TestExporterRegister(t *testing.T) {
+	e := NewExporter()
+	zero := func(context.Context) (float64, error) { return 0, nil }
+	if err := e.Register("orders_backlog", zero); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+		err  error
+	}{
+		{name: "orders_backlog", err: ErrDuplicateSignal},
+		{name: "Orders", err: ErrInvalidName},
+		{name: "orders-backlog", err: ErrInvalidName},
+		{name: "", err: ErrInvalidName},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if err := e.Register(v.name, zero); !errors.Is(err, v.err) {
+				t.Errorf("expected %v but got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestExporterRoutes(t *testing.T) {
+	e := NewExporter(SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	e.Register("orders_backlog", func(context.Context) (float64, error) { return 42, nil })
+	e.Register("broken", func(context.Context) (float64, error) { return 0, errors.New("down") })
+	mux := http.NewServeMux()
+	for _, r := range e.Routes() {
+		mux.Handle(r.Method+" "+r.Path, r.Handler)
+	}
+
+	tt := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/signals/orders_backlog", code: http.StatusOK, body: `{"value":42}`},
+		{path: "/signals", code: http.StatusOK, body: `{"orders_backlog":42}`},
+		{path: "/signals/missing", code: http.StatusNotFound},
+		{path: "/signals/broken", code: http.StatusServiceUnavailable},
+	}
+
+	for _, v := range tt {
+		t.Run(v.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v.path, nil))
+			if rec.Code != v.code {
+				t.Errorf("expected %d but got %d", v.code, rec.Code)
+			}
+			if v.body != "" && strings.TrimSpace(rec.Body.String()) != v.body {
+				t.Errorf("expected %s but got %s", v.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaling
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Consumer reports a JetStream consumer's state. *nats.Subscription implements it.
+type Consumer interface {
+	ConsumerInfo() (*nats.ConsumerInfo, error)
+}
+
+// QueueDepth returns a signal counting the consumer's outstanding work: messages not yet delivered plus
+// messages delivered but not acknowledged
+func QueueDepth(c Consumer) Signal {
+	return func(context.Context) (float64, error) {
+		info, err := c.ConsumerInfo()
+		if err != nil {
+			return 0, err
+		}
+		return float64(info.NumPending + uint64(info.NumAckPending)), nil
+	}
+}
+
+// ConsumerLag returns a signal counting the messages in the stream the consumer hasn't been delivered yet
+func ConsumerLag(c Consumer) Signal {
+	return func(context.Context) (float64, error) {
+		info, err := c.ConsumerInfo()
+		if err != nil {
+			return 0, err
+		}
+		return float64(info.NumPending), nil
+	}
+}
+
+// InFlight counts requests being handled. Register its Signal to scale on concurrency.
+type InFlight struct {
+	n atomic.Int64
+}
+
+// Track counts a request until the returned function is called
+func (f *InFlight) Track() func() {
+	f.n.Add(1)
+	return func() { f.n.Add(-1) }
+}
+
+// Count returns how many requests are in flight
+func (f *InFlight) Count() int64 {
+	return f.n.Load()
+}
+
+// Signal returns the in-flight count as a signal
+func (f *InFlight) Signal() Signal {
+	return func(context.Context) (float64, error) {
+		return float64(f.n.Load()), nil
+	}
+}
+
+// Middleware counts HTTP requests in flight
+func (f *InFlight) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer f.Track()()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// MicroHandler counts NATS requests in flight
+func (f *InFlight) MicroHandler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		defer f.Track()()
+		h.Handle(r)
+	})
+}
@@ -0,0 +1,344 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scrub finds personal data in values and replaces it before it reaches logs, traces, archives, or audit
+// events. A Scrubber runs detectors matching field names, patterns, or custom funcs, so compliance rules are set
+// in one place and applied to every output.
+package scrub
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Detector finds personal data in a value. Scrub returns the value with the data replaced by replacement and
+// whether anything was found. The key is the field or attribute name holding the value, and can be empty.
+type Detector struct {
+	Name  string
+	Scrub func(key, value, replacement string) (string, bool)
+}
+
+// Regex returns a detector replacing every match of pattern
+func Regex(name string, pattern *regexp.Regexp) Detector {
+	return Detector{
+		Name: name,
+		Scrub: func(_, value, replacement string) (string, bool) {
+			if !pattern.MatchString(value) {
+				return value, false
+			}
+			return pattern.ReplaceAllLiteralString(value, replacement), true
+		},
+	}
+}
+
+// normalizeKey lowercases a key and drops everything but letters and digits, so apiKey, api_key, and API-Key match
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+var (
+	jsonPair = regexp.MustCompile(`"([^"\\]*)"\s*:\s*("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	formPair = regexp.MustCompile(`\b([A-Za-z0-9_.-]+)=([^&\s]*)`)
+)
+
+// Fields returns a detector replacing the whole value of the named fields. Names match without case and ignoring
+// separators. Values without a key, such as log messages and payloads that aren't valid JSON, have the fields'
+// "name": value pairs and name=value form fields replaced instead, so truncated JSON is still scrubbed.
+func Fields(names ...string) Detector {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[normalizeKey(n)] = true
+	}
+
+	return Detector{
+		Name: "field",
+		Scrub: func(key, value, replacement string) (string, bool) {
+			if key == "" {
+				return scrubPairs(set, value, replacement)
+			}
+			// dotted keys like http.request.header.authorization match on their last token too
+			last := key[strings.LastIndex(key, ".")+1:]
+			if !set[normalizeKey(key)] && !set[normalizeKey(last)] {
+				return value, false
+			}
+			return replacement, true
+		},
+	}
+}
+
+// FieldSuffixes returns a detector replacing the whole value of fields whose names end with one of the names,
+// matched without case and ignoring separators, so password also matches db_password and nats.password
+func FieldSuffixes(names ...string) Detector {
+	suffixes := make([]string, 0, len(names))
+	for _, n := range names {
+		suffixes = append(suffixes, normalizeKey(n))
+	}
+
+	return Detector{
+		Name: "field",
+		Scrub: func(key, value, replacement string) (string, bool) {
+			k := normalizeKey(key)
+			if k == "" {
+				return value, false
+			}
+			for _, s := range suffixes {
+				if strings.HasSuffix(k, s) {
+					return replacement, true
+				}
+			}
+			return value, false
+		},
+	}
+}
+
+// scrubPairs replaces the values of JSON pairs and form fields named in set
+func scrubPairs(set map[string]bool, value, replacement string) (string, bool) {
+	found := false
+	replace := func(pattern *regexp.Regexp, format string, already string) {
+		value = pattern.ReplaceAllStringFunc(value, func(m string) string {
+			sub := pattern.FindStringSubmatch(m)
+			if !set[normalizeKey(sub[1])] || sub[2] == already {
+				return m
+			}
+			found = true
+			return fmt.Sprintf(format, sub[1], replacement)
+		})
+	}
+	replace(jsonPair, `"%s":%q`, strconv.Quote(replacement))
+	replace(formPair, "%s=%s", replacement)
+
+	return value, found
+}
+
+// Func returns a detector from a func, for rules patterns can't express
+func Func(name string, fn func(key, value, replacement string) (string, bool)) Detector {
+	return Detector{Name: name, Scrub: fn}
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	ssnPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	bearer       = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`)
+)
+
+// Email detects email addresses
+var Email = Regex("email", emailPattern)
+
+// SSN detects US social security numbers written with dashes
+var SSN = Regex("ssn", ssnPattern)
+
+// BearerToken detects bearer tokens, such as in a logged Authorization header
+var BearerToken = Regex("bearer", bearer)
+
+// CardNumber detects payment card numbers, checking candidates with the Luhn algorithm so order numbers and
+// timestamps aren't scrubbed
+var CardNumber = Func("card", func(_, value, replacement string) (string, bool) {
+	found := false
+	out := cardPattern.ReplaceAllStringFunc(value, func(m string) string {
+		if !luhn(m) {
+			return m
+		}
+		found = true
+		return replacement
+	})
+	return out, found
+})
+
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+
+	return n >= 13 && sum%10 == 0
+}
+
+// DefaultFields are the field names scrubbed by DefaultDetectors
+var DefaultFields = []string{"password", "secret", "token", "authorization", "apikey", "ssn", "cardnumber", "cvv"}
+
+// DefaultDetectors returns detectors for common secrets and personal data
+func DefaultDetectors() []Detector {
+	return []Detector{Fields(DefaultFields...), Email, SSN, CardNumber, BearerToken}
+}
+
+// ScrubberOpt is a functional option to modify the Scrubber
+type ScrubberOpt func(*Scrubber)
+
+// Scrubber applies detectors to values
+type Scrubber struct {
+	detectors   []Detector
+	replacement string
+	matches     *prometheus.CounterVec
+}
+
+// SetDetectors replaces the default detectors
+func SetDetectors(d ...Detector) ScrubberOpt {
+	return func(s *Scrubber) {
+		s.detectors = d
+	}
+}
+
+// AddDetectors adds detectors to the defaults
+func AddDetectors(d ...Detector) ScrubberOpt {
+	return func(s *Scrubber) {
+		s.detectors = append(s.detectors, d...)
+	}
+}
+
+// SetReplacement sets what found data is replaced with. The default is codec.RedactedValue.
+func SetReplacement(r string) ScrubberOpt {
+	return func(s *Scrubber) {
+		s.replacement = r
+	}
+}
+
+// NewScrubber returns a Scrubber using DefaultDetectors
+func NewScrubber(opts ...ScrubberOpt) *Scrubber {
+	s := &Scrubber{
+		detectors:   DefaultDetectors(),
+		replacement: codec.RedactedValue,
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scrub_matches",
+			Help: "Values scrubbed by each detector",
+		}, []string{"detector"}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Metrics returns the scrubber's collectors to register with the exporter
+func (s *Scrubber) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{s.matches}
+}
+
+// String returns value with personal data replaced. The key is the name of the field holding it. A value holding
+// a JSON object or array, such as a payload sample, is scrubbed field by field.
+func (s *Scrubber) String(key, value string) string {
+	if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v any
+		if json.Unmarshal([]byte(trimmed), &v) == nil {
+			if out, err := json.Marshal(s.Value(key, v)); err == nil {
+				return s.detect(key, string(out))
+			}
+		}
+	}
+
+	return s.detect(key, value)
+}
+
+// detect runs the detectors over value
+func (s *Scrubber) detect(key, value string) string {
+	for _, d := range s.detectors {
+		out, found := d.Scrub(key, value, s.replacement)
+		if !found {
+			continue
+		}
+		s.matches.WithLabelValues(d.Name).Inc()
+		value = out
+		if value == s.replacement {
+			return value
+		}
+	}
+
+	return value
+}
+
+// Value scrubs the strings in a decoded JSON value, such as a map[string]any, using map keys as field names.
+// Maps and slices are scrubbed in place.
+func (s *Scrubber) Value(key string, v any) any {
+	switch v := v.(type) {
+	case string:
+		return s.String(key, v)
+	case map[string]any:
+		for k, child := range v {
+			v[k] = s.Value(k, child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = s.Value(key, child)
+		}
+	case nil, bool:
+	default:
+		// numbers are checked as text, so a card number sent as a number is caught
+		if _, found := s.scrubbed(key, v); found {
+			return s.replacement
+		}
+	}
+
+	return v
+}
+
+func (s *Scrubber) scrubbed(key string, v any) (string, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	out := s.String(key, string(b))
+	return out, out != string(b)
+}
+
+// Bytes scrubs a payload. JSON payloads are scrubbed field by field and anything else as a single string.
+// tracing.PayloadSampler uses it to scrub payload samples.
+func (s *Scrubber) Bytes(payload []byte) []byte {
+	var v any
+	if json.Unmarshal(payload, &v) != nil {
+		return []byte(s.detect("", string(payload)))
+	}
+
+	out, err := json.Marshal(s.Value("", v))
+	if err != nil {
+		return payload
+	}
+
+	return out
+}
+
+// JSON marshals v and scrubs the result, such as for an audit event before it is written
+func (s *Scrubber) JSON(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Bytes(b), nil
+}
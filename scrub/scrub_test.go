@@ -0,0 +1,121 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scrub
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestScrubber(t *testing.T) {
+	s := NewScrubber(AddDetectors(
+		Regex("order", regexp.MustCompile(`ORD-\d+`)),
+		Func("upper", func(key, value, replacement string) (string, bool) {
+			if key == "shout" && strings.ToUpper(value) == value {
+				return replacement, true
+			}
+			return value, false
+		}),
+	))
+
+	tt := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{name: "field", key: "api_key", value: "abc", want: "[REDACTED]"},
+		{name: "dotted field", key: "http.request.header.authorization", value: "abc", want: "[REDACTED]"},
+		{name: "email", value: "contact ana@example.com now", want: "contact [REDACTED] now"},
+		{name: "card", value: "paid with 4111 1111 1111 1111", want: "paid with [REDACTED]"},
+		{name: "not a card", value: "order 1234567890123", want: "order 1234567890123"},
+		{name: "ssn", value: "ssn 123-45-6789", want: "ssn [REDACTED]"},
+		{name: "bearer", value: "Authorization: Bearer eyJhbGciOi.x.y", want: "Authorization: [REDACTED]"},
+		{name: "regex", value: "see ORD-42", want: "see [REDACTED]"},
+		{name: "func", key: "shout", value: "HELLO", want: "[REDACTED]"},
+		{name: "clean", key: "status", value: "shipped", want: "shipped"},
+		{name: "truncated json", value: `{"user":"ana","Password":"hunt`, want: `{"user":"ana","Password":"[REDACTED]"`},
+		{name: "form", value: "user=ana&api_key=abc", want: "user=ana&api_key=[REDACTED]"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := s.String(v.key, v.value); got != v.want {
+				t.Errorf("expected %q but got %q", v.want, got)
+			}
+		})
+	}
+
+	got := s.Bytes([]byte(`{"user":{"email":"ana@example.com","password":"x"},"card":4111111111111111,"items":[{"note":"call 123-45-6789"}]}`))
+	want := `{"card":"[REDACTED]","items":[{"note":"call [REDACTED]"}],"user":{"email":"[REDACTED]","password":"[REDACTED]"}}`
+	if string(got) != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+
+	s = NewScrubber()
+	reg := sdtest.NewRegistry(t, s.Metrics()...)
+	s.String("", "ana@example.com")
+	reg.AssertCounter(t, "scrub_matches", prometheus.Labels{"detector": "email"}, 1)
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil), NewScrubber())).With("token", "abc")
+	logger.Info("signup for ana@example.com", "user", "ana", slog.Group("card", "number", "4111111111111111"), "count", 3)
+
+	out := buf.String()
+	for _, leaked := range []string{"abc", "ana@example.com", "4111111111111111"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("expected %s to be scrubbed from %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "user=ana") || !strings.Contains(out, "count=3") {
+		t.Errorf("expected other attributes to be kept in %s", out)
+	}
+}
+
+func TestSpanExporter(t *testing.T) {
+	rec := &sdtest.SpanRecorder{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewSpanExporter(rec, NewScrubber())))
+	defer tp.Shutdown(context.Background())
+
+	_, span := tp.Tracer("test").Start(context.Background(), "signup")
+	span.SetAttributes(attribute.String("user.email", "ana@example.com"), attribute.Int("user.age", 30))
+	span.AddEvent("payload", trace.WithAttributes(attribute.String("payload.sample", `{"password":"x"}`)))
+	span.SetStatus(codes.Error, "no account for ana@example.com")
+	span.End()
+
+	got := rec.Spans()[0]
+	if v := got.Attributes()[0].Value.AsString(); v != "[REDACTED]" {
+		t.Errorf("expected the email attribute to be scrubbed but got %s", v)
+	}
+	if v := got.Events()[0].Attributes[0].Value.AsString(); strings.Contains(v, `"x"`) {
+		t.Errorf("expected the event to be scrubbed but got %s", v)
+	}
+	if d := got.Status().Description; strings.Contains(d, "ana@example.com") {
+		t.Errorf("expected the status to be scrubbed but got %s", d)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scrub
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler scrubs the message and attributes of log records before passing them to the next handler
+type Handler struct {
+	next slog.Handler
+	s    *Scrubber
+}
+
+// NewHandler wraps next so every record it handles is scrubbed
+func NewHandler(next slog.Handler, s *Scrubber) *Handler {
+	return &Handler{next: next, s: s}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.s.String("", r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = h.attr(a)
+	}
+
+	return &Handler{next: h.next.WithAttrs(scrubbed), s: h.s}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), s: h.s}
+}
+
+// attr scrubs an attribute, keeping its kind unless something was found in it
+func (h *Handler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		group := v.Group()
+		scrubbed := make([]any, len(group))
+		for i, child := range group {
+			scrubbed[i] = h.attr(child)
+		}
+		return slog.Group(a.Key, scrubbed...)
+	}
+
+	s := v.String()
+	if out := h.s.String(a.Key, s); out != s {
+		return slog.String(a.Key, out)
+	}
+
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scrub
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanExporter scrubs the attributes, events, and status of spans before passing them to the next exporter
+type SpanExporter struct {
+	next sdktrace.SpanExporter
+	s    *Scrubber
+}
+
+// NewSpanExporter wraps next so every span it exports is scrubbed. Pass it to sdktrace.WithBatcher.
+func NewSpanExporter(next sdktrace.SpanExporter, s *Scrubber) *SpanExporter {
+	return &SpanExporter{next: next, s: s}
+}
+
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	scrubbed := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		scrubbed[i] = scrubbedSpan{ReadOnlySpan: span, s: e.s}
+	}
+
+	return e.next.ExportSpans(ctx, scrubbed)
+}
+
+func (e *SpanExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// scrubbedSpan scrubs a span's values as the exporter reads them
+type scrubbedSpan struct {
+	sdktrace.ReadOnlySpan
+	s *Scrubber
+}
+
+func (s scrubbedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes(s.ReadOnlySpan.Attributes())
+}
+
+func (s scrubbedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	scrubbed := make([]sdktrace.Event, len(events))
+	for i, e := range events {
+		e.Attributes = s.attributes(e.Attributes)
+		scrubbed[i] = e
+	}
+
+	return scrubbed
+}
+
+func (s scrubbedSpan) Status() sdktrace.Status {
+	status := s.ReadOnlySpan.Status()
+	status.Description = s.s.String("", status.Description)
+	return status
+}
+
+func (s scrubbedSpan) attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	scrubbed := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		key := string(a.Key)
+		switch a.Value.Type() {
+		case attribute.STRING:
+			a = attribute.String(key, s.s.String(key, a.Value.AsString()))
+		case attribute.STRINGSLICE:
+			values := a.Value.AsStringSlice()
+			for j, v := range values {
+				values[j] = s.s.String(key, v)
+			}
+			a = attribute.StringSlice(key, values)
+		}
+		scrubbed[i] = a
+	}
+
+	return scrubbed
+}
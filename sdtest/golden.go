@@ -0,0 +1,191 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/internal/diff"
+)
+
+// UpdateEnv rewrites golden files instead of comparing against them when set to a non empty value, as does
+// passing -update-golden to go test
+const UpdateEnv = "SENCILLO_UPDATE_GOLDEN"
+
+var update = flag.Bool("update-golden", false, "rewrite golden files in testdata with the current output")
+
+// Timestamp matches RFC 3339 timestamps such as 2025-01-07T15:04:05.123Z
+var Timestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// UUID matches UUIDs in their canonical form
+var UUID = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// GoldenOpt is a functional option to modify how output is normalized before it is compared
+type GoldenOpt func(*golden)
+
+type golden struct {
+	scrubbers []func(string) string
+	keys      map[string]bool
+	dir       string
+}
+
+// Scrub replaces matches of the regular expression with the replacement, which may use $1 style references.
+// Scrubbers run in order on the normalized output.
+func Scrub(re *regexp.Regexp, replacement string) GoldenOpt {
+	return func(g *golden) {
+		g.scrubbers = append(g.scrubbers, func(s string) string {
+			return re.ReplaceAllString(s, replacement)
+		})
+	}
+}
+
+// ScrubTimestamps replaces RFC 3339 timestamps with <timestamp>
+func ScrubTimestamps() GoldenOpt {
+	return Scrub(Timestamp, "<timestamp>")
+}
+
+// ScrubKeys replaces the value of JSON object keys with the name at any depth with <scrubbed>, for values like
+// generated IDs that have no fixed format
+func ScrubKeys(keys ...string) GoldenOpt {
+	return func(g *golden) {
+		for _, v := range keys {
+			g.keys[v] = true
+		}
+	}
+}
+
+// SetGoldenDir sets the directory golden files are kept in. The default is testdata.
+func SetGoldenDir(dir string) GoldenOpt {
+	return func(g *golden) {
+		g.dir = dir
+	}
+}
+
+// Golden compares got with testdata/<name>.golden and fails the test with a diff when they differ. Values that
+// aren't strings or byte slices are marshaled to JSON. JSON is re-indented with sorted keys so field order and
+// formatting don't cause failures. Run the tests with -update-golden or SENCILLO_UPDATE_GOLDEN=1 to write the
+// current output as the new golden file.
+func Golden(t testing.TB, name string, got any, opts ...GoldenOpt) {
+	t.Helper()
+	g := golden{dir: "testdata", keys: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(&g)
+	}
+
+	out, err := g.normalize(got)
+	if err != nil {
+		t.Fatalf("normalizing golden output: %v", err)
+	}
+
+	path := filepath.Join(g.dir, name+".golden")
+	if *update || os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file, run with -update-golden to create it: %v", err)
+	}
+
+	var b strings.Builder
+	if diff.Write(&b, path, string(want), out) {
+		t.Errorf("output does not match %s, run with -update-golden to accept it:\n%s", path, b.String())
+	}
+}
+
+// normalize renders the value as text, formatting JSON consistently and applying the scrubbers
+func (g golden) normalize(got any) (string, error) {
+	var raw []byte
+	switch v := got.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		raw = b
+	}
+
+	out := string(raw)
+	if js, ok := g.normalizeJSON(raw); ok {
+		out = js
+	}
+
+	for _, s := range g.scrubbers {
+		out = s(out)
+	}
+
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	return out, nil
+}
+
+// normalizeJSON re-indents JSON with sorted keys, reporting false if the input isn't JSON
+func (g golden) normalizeJSON(raw []byte) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g.scrubKeys(v)); err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+func (g golden) scrubKeys(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if g.keys[k] {
+				val[k] = "<scrubbed>"
+				continue
+			}
+			val[k] = g.scrubKeys(child)
+		}
+	case []any:
+		for i, child := range val {
+			val[i] = g.scrubKeys(child)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGoldenNormalize(t *testing.T) {
+	tt := []struct {
+		name string
+		got  any
+		opts []GoldenOpt
+		want string
+	}{
+		{name: "sorted keys", got: `{"b":1,"a":{"d":2,"c":3}}`, want: "{\n  \"a\": {\n    \"c\": 3,\n    \"d\": 2\n  },\n  \"b\": 1\n}\n"},
+		{name: "struct", got: struct {
+			Name string `json:"name"`
+		}{Name: "a"}, want: "{\n  \"name\": \"a\"\n}\n"},
+		{name: "large numbers kept", got: []byte(`[12345678901234567890]`), want: "[\n  12345678901234567890\n]\n"},
+		{name: "text", got: "not json", want: "not json\n"},
+		{name: "timestamps", got: `{"at":"2025-01-07T15:04:05.123Z","in":"2025-01-07T15:04:05-05:00"}`, opts: []GoldenOpt{ScrubTimestamps()}, want: "{\n  \"at\": \"<timestamp>\",\n  \"in\": \"<timestamp>\"\n}\n"},
+		{name: "keys", got: `{"id":"x1","items":[{"id":"x2","qty":1}]}`, opts: []GoldenOpt{ScrubKeys("id")}, want: "{\n  \"id\": \"<scrubbed>\",\n  \"items\": [\n    {\n      \"id\": \"<scrubbed>\",\n      \"qty\": 1\n    }\n  ]\n}\n"},
+		{name: "regex", got: "took 15ms", opts: []GoldenOpt{Scrub(regexp.MustCompile(`\d+ms`), "<duration>")}, want: "took <duration>\n"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			g := golden{keys: make(map[string]bool)}
+			for _, opt := range v.opts {
+				opt(&g)
+			}
+
+			got, err := g.normalize(v.got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != v.want {
+				t.Errorf("expected\n%s\ngot\n%s", v.want, got)
+			}
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":         "ord_" + time.Now().Format("150405.000000"),
+			"status":     "created",
+			"created_at": time.Now().UTC(),
+			"items":      []map[string]any{{"sku": "abc", "qty": 2}},
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	Golden(t, "order", rec.Body.Bytes(), ScrubTimestamps(), ScrubKeys("id"))
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, "nested/value", map[string]int{"a": 1}, SetGoldenDir(dir))
+
+	b, err := os.ReadFile(filepath.Join(dir, "nested", "value.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("unexpected golden file %q", b)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	Golden(t, "nested/value", `{"a":1}`, SetGoldenDir(dir))
+
+	ft := &fakeTB{}
+	Golden(ft, "nested/value", `{"a":2}`, SetGoldenDir(dir))
+	if !ft.failed {
+		t.Errorf("expected mismatch to fail")
+	}
+}
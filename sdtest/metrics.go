@@ -0,0 +1,159 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Registry is an isolated Prometheus registry so metrics from one test don't leak into another or collide with
+// the default registry
+type Registry struct {
+	*prometheus.Registry
+}
+
+// NewRegistry returns a pedantic registry with the collectors registered. The test fails if a collector can't be
+// registered or is inconsistent.
+func NewRegistry(t testing.TB, collectors ...prometheus.Collector) *Registry {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	for _, v := range collectors {
+		if err := reg.Register(v); err != nil {
+			t.Fatalf("registering collector: %v", err)
+		}
+	}
+
+	return &Registry{Registry: reg}
+}
+
+// series returns the metrics in the family whose labels include every label given
+func (r *Registry) series(t testing.TB, name string, labels prometheus.Labels) []*dto.Metric {
+	t.Helper()
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+
+	var matched []*dto.Metric
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if hasLabels(m, labels) {
+				matched = append(matched, m)
+			}
+		}
+	}
+
+	return matched
+}
+
+func hasLabels(m *dto.Metric, labels prometheus.Labels) bool {
+	found := 0
+	for _, l := range m.GetLabel() {
+		v, ok := labels[l.GetName()]
+		if !ok {
+			continue
+		}
+		if v != l.GetValue() {
+			return false
+		}
+		found++
+	}
+
+	return found == len(labels)
+}
+
+// CounterValue returns the sum of the counters with the labels. Labels left out match any value, so
+// CounterValue(t, "http_requests", prometheus.Labels{"code": "500"}) counts server errors on every path.
+func (r *Registry) CounterValue(t testing.TB, name string, labels prometheus.Labels) float64 {
+	t.Helper()
+	var total float64
+	for _, m := range r.series(t, name, labels) {
+		total += m.GetCounter().GetValue()
+	}
+
+	return total
+}
+
+// GaugeValue returns the sum of the gauges with the labels
+func (r *Registry) GaugeValue(t testing.TB, name string, labels prometheus.Labels) float64 {
+	t.Helper()
+	var total float64
+	for _, m := range r.series(t, name, labels) {
+		total += m.GetGauge().GetValue()
+	}
+
+	return total
+}
+
+// HistogramCount returns the number of observations in the histograms with the labels
+func (r *Registry) HistogramCount(t testing.TB, name string, labels prometheus.Labels) uint64 {
+	t.Helper()
+	var total uint64
+	for _, m := range r.series(t, name, labels) {
+		total += m.GetHistogram().GetSampleCount()
+	}
+
+	return total
+}
+
+// HistogramSum returns the sum of the observations in the histograms with the labels
+func (r *Registry) HistogramSum(t testing.TB, name string, labels prometheus.Labels) float64 {
+	t.Helper()
+	var total float64
+	for _, m := range r.series(t, name, labels) {
+		total += m.GetHistogram().GetSampleSum()
+	}
+
+	return total
+}
+
+// AssertCounter fails the test unless the counters with the labels add up to want
+func (r *Registry) AssertCounter(t testing.TB, name string, labels prometheus.Labels, want float64) {
+	t.Helper()
+	if got := r.CounterValue(t, name, labels); got != want {
+		t.Errorf("expected %s%s to be %v, got %v", name, formatLabels(labels), want, got)
+	}
+}
+
+// AssertGauge fails the test unless the gauges with the labels add up to want
+func (r *Registry) AssertGauge(t testing.TB, name string, labels prometheus.Labels, want float64) {
+	t.Helper()
+	if got := r.GaugeValue(t, name, labels); got != want {
+		t.Errorf("expected %s%s to be %v, got %v", name, formatLabels(labels), want, got)
+	}
+}
+
+// AssertHistogramCount fails the test unless the histograms with the labels observed want values
+func (r *Registry) AssertHistogramCount(t testing.TB, name string, labels prometheus.Labels, want uint64) {
+	t.Helper()
+	if got := r.HistogramCount(t, name, labels); got != want {
+		t.Errorf("expected %s%s to have %d observations, got %d", name, formatLabels(labels), want, got)
+	}
+}
+
+func formatLabels(labels prometheus.Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	return fmt.Sprint(map[string]string(labels))
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	sdmiddleware "github.com/SencilloDev/sencillo-go/transports/http/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCodeStatsMetrics(t *testing.T) {
+	counter := metrics.NewCounterVec("http_requests", "test", []string{"code", "method", "path"})
+	hist := metrics.NewHistogramVec("http_request_latency", "test", []string{"code", "method", "path"})
+	reg := NewRegistry(t, counter, hist)
+
+	h := sdmiddleware.CodeStats(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}), counter, hist)
+
+	for _, path := range []string{"/ok", "/ok", "/fail", "/other"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tt := []struct {
+		name   string
+		labels prometheus.Labels
+		want   float64
+	}{
+		{name: "all", want: 4},
+		{name: "one series", labels: prometheus.Labels{"code": "200", "path": "/ok"}, want: 2},
+		{name: "partial labels", labels: prometheus.Labels{"code": "200"}, want: 3},
+		{name: "no match", labels: prometheus.Labels{"code": "404"}, want: 0},
+		{name: "unknown label", labels: prometheus.Labels{"route": "/ok"}, want: 0},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			reg.AssertCounter(t, "http_requests", v.labels, v.want)
+			reg.AssertHistogramCount(t, "http_request_latency", v.labels, uint64(v.want))
+		})
+	}
+}
+
+func TestRegistryIsolated(t *testing.T) {
+	counter := metrics.NewCounterVec("isolated_total", "test", []string{"code"})
+	counter.WithLabelValues("200").Add(3)
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "in_flight", Help: "test"})
+	gauge.Set(2)
+
+	reg := NewRegistry(t, counter, gauge)
+	reg.AssertCounter(t, "isolated_total", nil, 3)
+	reg.AssertGauge(t, "in_flight", nil, 2)
+
+	// registering in a second registry works because nothing touched the default one
+	NewRegistry(t, counter).AssertCounter(t, "isolated_total", prometheus.Labels{"code": "200"}, 3)
+
+	ft := &fakeTB{}
+	reg.AssertCounter(ft, "isolated_total", nil, 4)
+	if !ft.failed {
+		t.Errorf("expected assertion to fail")
+	}
+}
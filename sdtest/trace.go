@@ -0,0 +1,211 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdtest contains helpers for testing services built with sencillo-go
+package sdtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanRecorder is an in memory exporter that keeps every finished span
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+// NewTracerProvider returns a tracer provider that exports synchronously to a SpanRecorder so spans can be
+// asserted on as soon as they end. The provider is shut down when the test finishes.
+func NewTracerProvider(t testing.TB) (*sdktrace.TracerProvider, *SpanRecorder) {
+	t.Helper()
+	rec := &SpanRecorder{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(rec))
+	t.Cleanup(func() {
+		tp.Shutdown(context.Background())
+	})
+
+	return tp, rec
+}
+
+func (r *SpanRecorder) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func (r *SpanRecorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the finished spans in the order they ended
+func (r *SpanRecorder) Spans() []sdktrace.ReadOnlySpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sdktrace.ReadOnlySpan(nil), r.spans...)
+}
+
+// Named returns the finished spans with the name
+func (r *SpanRecorder) Named(name string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, v := range r.Spans() {
+		if v.Name() == name {
+			spans = append(spans, v)
+		}
+	}
+
+	return spans
+}
+
+// Reset drops the recorded spans
+func (r *SpanRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+// SpanMatcher returns an error describing why the span doesn't match
+type SpanMatcher func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error
+
+// HasAttribute matches spans with the attribute set to the value. Values are compared by their printed form so
+// an int matches an int64 attribute.
+func HasAttribute(key string, value any) SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		for _, v := range span.Attributes() {
+			if string(v.Key) != key {
+				continue
+			}
+			if got := fmt.Sprint(v.Value.AsInterface()); got != fmt.Sprint(value) {
+				return fmt.Errorf("attribute %s is %s, expected %v", key, got, value)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("attribute %s is not set", key)
+	}
+}
+
+// HasStatus matches spans with the status code
+func HasStatus(code codes.Code) SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		if got := span.Status().Code; got != code {
+			return fmt.Errorf("status is %s, expected %s", got, code)
+		}
+		return nil
+	}
+}
+
+// HasEvent matches spans that recorded an event with the name, such as "exception" for span.RecordError
+func HasEvent(name string) SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		for _, v := range span.Events() {
+			if v.Name == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("no %s event", name)
+	}
+}
+
+// HasParent matches spans whose parent is a recorded span with the name
+func HasParent(name string) SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		parent := span.Parent()
+		if !parent.IsValid() {
+			return fmt.Errorf("span has no parent, expected %s", name)
+		}
+
+		for _, v := range rec.Spans() {
+			if v.SpanContext().SpanID() == parent.SpanID() {
+				if v.Name() != name {
+					return fmt.Errorf("parent is %s, expected %s", v.Name(), name)
+				}
+				return nil
+			}
+		}
+
+		return fmt.Errorf("parent %s was not recorded, expected %s", parent.SpanID(), name)
+	}
+}
+
+// HasRemoteParent matches spans continuing a trace propagated from another process
+func HasRemoteParent() SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		if !span.Parent().IsRemote() {
+			return fmt.Errorf("parent is not remote")
+		}
+		return nil
+	}
+}
+
+// IsRoot matches spans that started a new trace
+func IsRoot() SpanMatcher {
+	return func(span sdktrace.ReadOnlySpan, rec *SpanRecorder) error {
+		if span.Parent().IsValid() {
+			return fmt.Errorf("span has parent %s", span.Parent().SpanID())
+		}
+		return nil
+	}
+}
+
+// AssertSpan fails the test unless a finished span with the name satisfies every matcher. The matching span is
+// returned, or nil if there was none.
+func (r *SpanRecorder) AssertSpan(t testing.TB, name string, matchers ...SpanMatcher) sdktrace.ReadOnlySpan {
+	t.Helper()
+	candidates := r.Named(name)
+	if len(candidates) == 0 {
+		t.Errorf("no span named %s, recorded: %s", name, strings.Join(r.names(), ", "))
+		return nil
+	}
+
+	var reasons []string
+	for i, span := range candidates {
+		var errs []string
+		for _, m := range matchers {
+			if err := m(span, r); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) == 0 {
+			return span
+		}
+		reasons = append(reasons, fmt.Sprintf("span %d: %s", i, strings.Join(errs, "; ")))
+	}
+
+	t.Errorf("no span named %s matched:\n%s", name, strings.Join(reasons, "\n"))
+	return nil
+}
+
+// AssertNoSpan fails the test if a span with the name finished
+func (r *SpanRecorder) AssertNoSpan(t testing.TB, name string) {
+	t.Helper()
+	if n := len(r.Named(name)); n > 0 {
+		t.Errorf("expected no span named %s, got %d", name, n)
+	}
+}
+
+func (r *SpanRecorder) names() []string {
+	var names []string
+	for _, v := range r.Spans() {
+		names = append(names, v.Name())
+	}
+	return names
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go/micro"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type fakeRequest struct {
+	micro.Request
+	headers micro.Headers
+	code    string
+}
+
+func (f *fakeRequest) Headers() micro.Headers { return f.headers }
+func (f *fakeRequest) Subject() string        { return "orders.get" }
+
+func (f *fakeRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	f.code = code
+	return nil
+}
+
+func TestErrorHandlerSpans(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		matchers []SpanMatcher
+	}{
+		{name: "ok", matchers: []SpanMatcher{HasStatus(codes.Ok), HasAttribute("X-Request-ID", "abc"), HasRemoteParent()}},
+		{name: "error", err: fmt.Errorf("boom"), matchers: []SpanMatcher{HasStatus(codes.Error), HasEvent("exception")}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			tp, rec := NewTracerProvider(t)
+			tracer := tp.Tracer("test")
+			app := sdnats.AppContext{
+				Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+				Tracer:     tracer,
+				Propagator: propagation.TraceContext{},
+			}
+
+			h := sdnats.ErrorHandler("orders.get", app, func(ctx context.Context, r micro.Request, h sdnats.HandlerContext) error {
+				_, span := h.Tracer.Start(ctx, "db.query")
+				span.End()
+				return v.err
+			})
+
+			h.Handle(&fakeRequest{headers: micro.Headers{
+				"X-Request-ID": {"abc"},
+				"traceparent":  {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+			}})
+
+			rec.AssertSpan(t, "orders.get", v.matchers...)
+			rec.AssertSpan(t, "db.query", HasParent("orders.get"))
+		})
+	}
+}
+
+func TestOtelHTTPSpans(t *testing.T) {
+	tp, rec := NewTracerProvider(t)
+	h := otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), "teapot:GET", otelhttp.WithTracerProvider(tp))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/teapot", nil))
+
+	rec.AssertSpan(t, "teapot:GET", IsRoot(), HasAttribute("http.status_code", http.StatusTeapot))
+	rec.AssertNoSpan(t, "healthz:GET")
+}
+
+func TestAssertSpanFailures(t *testing.T) {
+	tp, rec := NewTracerProvider(t)
+	_, span := tp.Tracer("test").Start(context.Background(), "work")
+	span.SetStatus(codes.Error, "failed")
+	span.End()
+
+	tt := []struct {
+		name     string
+		span     string
+		matchers []SpanMatcher
+	}{
+		{name: "missing span", span: "other"},
+		{name: "wrong status", span: "work", matchers: []SpanMatcher{HasStatus(codes.Ok)}},
+		{name: "missing attribute", span: "work", matchers: []SpanMatcher{HasAttribute("key", "value")}},
+		{name: "no parent", span: "work", matchers: []SpanMatcher{HasParent("root")}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ft := &fakeTB{}
+			if got := rec.AssertSpan(ft, v.span, v.matchers...); got != nil || !ft.failed {
+				t.Errorf("expected assertion to fail")
+			}
+		})
+	}
+}
+
+// fakeTB records failures so assertion failures can be tested
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.failed = true
+}
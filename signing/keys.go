@@ -0,0 +1,100 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+var ErrUnknownSigner = fmt.Errorf("unknown signer")
+
+// KeyStore returns the trusted public key for a signing service
+type KeyStore interface {
+	PublicKey(ctx context.Context, name string) (string, error)
+}
+
+// StaticKeys is a fixed map of service names to public keys
+type StaticKeys map[string]string
+
+func (s StaticKeys) PublicKey(ctx context.Context, name string) (string, error) {
+	pub, ok := s[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownSigner, name)
+	}
+
+	return pub, nil
+}
+
+type cachedKey struct {
+	pub     string
+	fetched time.Time
+}
+
+// KVKeyStore distributes public keys through a KV bucket keyed by service name. Keys are cached for the TTL so
+// rotations propagate without a lookup on every request.
+type KVKeyStore struct {
+	kv  nats.KeyValue
+	ttl time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedKey
+}
+
+// NewKVKeyStore returns a KVKeyStore caching keys for the ttl
+func NewKVKeyStore(kv nats.KeyValue, ttl time.Duration) *KVKeyStore {
+	return &KVKeyStore{
+		kv:    kv,
+		ttl:   ttl,
+		cache: make(map[string]cachedKey),
+	}
+}
+
+func (k *KVKeyStore) PublicKey(ctx context.Context, name string) (string, error) {
+	k.mu.RLock()
+	c, ok := k.cache[name]
+	k.mu.RUnlock()
+	if ok && time.Since(c.fetched) < k.ttl {
+		return c.pub, nil
+	}
+
+	entry, err := k.kv.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrUnknownSigner, name, err)
+	}
+
+	pub := string(entry.Value())
+	k.mu.Lock()
+	k.cache[name] = cachedKey{pub: pub, fetched: time.Now()}
+	k.mu.Unlock()
+
+	return pub, nil
+}
+
+// Register publishes the public key of the key pair under the service name
+func (k *KVKeyStore) Register(name string, kp nkeys.KeyPair) error {
+	pub, err := kp.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	_, err = k.kv.PutString(name, pub)
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NonceStore records the nonces of verified requests so a captured request can't be replayed
+type NonceStore interface {
+	// Claim records the nonce until expires and returns false if it was already recorded
+	Claim(ctx context.Context, nonce string, expires time.Time) (bool, error)
+}
+
+// MemoryNonces is a NonceStore for a single instance. Expired nonces are dropped as new ones are claimed.
+type MemoryNonces struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	expiry nonceQueue
+}
+
+// claimed is a nonce and the time it was claimed until
+type claimed struct {
+	nonce   string
+	expires time.Time
+}
+
+// nonceQueue is a heap of claimed nonces, soonest to expire first
+type nonceQueue []claimed
+
+func (q nonceQueue) Len() int           { return len(q) }
+func (q nonceQueue) Less(i, j int) bool { return q[i].expires.Before(q[j].expires) }
+func (q nonceQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nonceQueue) Push(x any)        { *q = append(*q, x.(claimed)) }
+func (q *nonceQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// NewMemoryNonces returns an empty MemoryNonces
+func NewMemoryNonces() *MemoryNonces {
+	return &MemoryNonces{
+		now:    time.Now,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+func (m *MemoryNonces) Claim(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if e, ok := m.nonces[nonce]; ok && now.Before(e) {
+		return false, nil
+	}
+
+	// a nonce claimed again after expiring has a newer entry in the queue, so only the entry matching the map
+	// removes it
+	for len(m.expiry) > 0 && !now.Before(m.expiry[0].expires) {
+		c := heap.Pop(&m.expiry).(claimed)
+		if m.nonces[c.nonce].Equal(c.expires) {
+			delete(m.nonces, c.nonce)
+		}
+	}
+	m.nonces[nonce] = expires
+	heap.Push(&m.expiry, claimed{nonce: nonce, expires: expires})
+
+	return true, nil
+}
+
+// KVNonces shares nonces between instances through a KV bucket. The bucket's TTL must be at least twice the
+// verifier's skew, since the bucket expires nonces rather than the store.
+type KVNonces struct {
+	kv nats.KeyValue
+}
+
+// NewKVNonces returns a KVNonces using the bucket
+func NewKVNonces(kv nats.KeyValue) *KVNonces {
+	return &KVNonces{kv: kv}
+}
+
+func (k *KVNonces) Claim(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	_, err := k.kv.Create(nonce, nil)
+	if errors.Is(err, nats.ErrKeyExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+func TestSignVerify(t *testing.T) {
+	kp, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := kp.PublicKey()
+
+	signer := NewSigner("orders", kp)
+	verifier := NewVerifier(StaticKeys{"orders": pub})
+
+	msg := nats.NewMsg("orders.create")
+	msg.Data = []byte(`{"id": 1}`)
+	msg.Header.Set("X-Request-ID", "abc")
+	if err := signer.SignMsg(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	name, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, msg.Data)
+	if err != nil || name != "orders" {
+		t.Fatalf("expected valid signature from orders, got %s %v", name, err)
+	}
+
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, []byte(`{"id": 2}`)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected tampered body to fail, got %v", err)
+	}
+
+	if _, err := verifier.VerifyMsg(context.Background(), "orders.delete", msg.Header, msg.Data); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected different subject to fail, got %v", err)
+	}
+
+	tampered := nats.Header{}
+	for k, v := range msg.Header {
+		tampered[k] = v
+	}
+	tampered.Set("X-Request-ID", "xyz")
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, tampered, msg.Data); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected tampered header to fail, got %v", err)
+	}
+
+	verifier.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, msg.Data); !errors.Is(err, ErrExpiredSignature) {
+		t.Errorf("expected old signature to fail, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	kp, _ := nkeys.CreateUser()
+	pub, _ := kp.PublicKey()
+	signer := NewSigner("billing", kp)
+
+	h := NewVerifier(StaticKeys{"billing": pub}, SetMaxBodyBytes(16)).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	signed := httptest.NewRequest(http.MethodPost, "/charge?id=1", strings.NewReader("amount=10"))
+	if err := signer.SignRequest(signed); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{name: "signed", req: signed, status: http.StatusOK},
+		{name: "unsigned", req: httptest.NewRequest(http.MethodPost, "/charge?id=1", strings.NewReader("amount=10")), status: http.StatusUnauthorized},
+		{name: "too large", req: httptest.NewRequest(http.MethodPost, "/charge?id=1", strings.NewReader(strings.Repeat("a", 17))), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, v.req)
+			if rr.Code != v.status {
+				t.Errorf("expected status %d but got %d", v.status, rr.Code)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSubRouter(t *testing.T) {
+	kp, _ := nkeys.CreateUser()
+	pub, _ := kp.PublicKey()
+	signer := NewSigner("billing", kp)
+
+	// RegisterSubRouter strips its prefix before the middleware runs
+	var called bool
+	h := http.StripPrefix("/billing", NewVerifier(StaticKeys{"billing": pub}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})))
+
+	out, _ := http.NewRequest(http.MethodPost, "http://billing.svc/billing/charge?id=1", strings.NewReader("amount=10"))
+	if err := signer.SignRequest(out); err != nil {
+		t.Fatal(err)
+	}
+
+	in := httptest.NewRequest(http.MethodPost, "/billing/charge?id=1", strings.NewReader("amount=10"))
+	in.Header = out.Header
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, in)
+	if rr.Code != http.StatusOK || !called {
+		t.Errorf("expected the signature to verify after the prefix is stripped but got %d", rr.Code)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	kp, _ := nkeys.CreateUser()
+	pub, _ := kp.PublicKey()
+	signer := NewSigner("orders", kp)
+	verifier := NewVerifier(StaticKeys{"orders": pub}, SetNonceStore(NewMemoryNonces()))
+
+	msg := nats.NewMsg("orders.create")
+	msg.Data = []byte(`{"id": 1}`)
+	if err := signer.SignMsg(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, msg.Data); err != nil {
+		t.Fatalf("expected the first use to verify, got %v", err)
+	}
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, msg.Data); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("expected the replay to fail, got %v", err)
+	}
+
+	msg.Header.Del(SignatureNonceHeader)
+	if _, err := verifier.VerifyMsg(context.Background(), msg.Subject, msg.Header, msg.Data); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("expected a request without a nonce to fail, got %v", err)
+	}
+}
+
+func TestMemoryNoncesExpire(t *testing.T) {
+	now := time.Now()
+	n := NewMemoryNonces()
+	n.now = func() time.Time { return now }
+
+	if fresh, _ := n.Claim(context.Background(), "a", now.Add(time.Minute)); !fresh {
+		t.Fatal("expected a new nonce to be fresh")
+	}
+	if fresh, _ := n.Claim(context.Background(), "a", now.Add(time.Minute)); fresh {
+		t.Error("expected a claimed nonce not to be fresh")
+	}
+
+	if fresh, _ := n.Claim(context.Background(), "b", now.Add(3*time.Minute)); !fresh {
+		t.Fatal("expected a new nonce to be fresh")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if fresh, _ := n.Claim(context.Background(), "a", now.Add(time.Minute)); !fresh {
+		t.Error("expected an expired nonce to be fresh again")
+	}
+	if len(n.nonces) != 2 || len(n.expiry) != 2 {
+		t.Errorf("expected only the expired claim to be dropped but have %d nonces and %d queued", len(n.nonces), len(n.expiry))
+	}
+
+	now = now.Add(time.Minute)
+	n.Claim(context.Background(), "c", now.Add(time.Minute))
+	if len(n.nonces) != 1 || len(n.expiry) != 1 {
+		t.Errorf("expected every expired claim to be dropped but have %v", n.nonces)
+	}
+}
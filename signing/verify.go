@@ -0,0 +1,254 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/nats-io/nkeys"
+)
+
+// Verifier checks signatures on incoming requests against public keys from a KeyStore
+type Verifier struct {
+	keys    KeyStore
+	headers []string
+	skew    time.Duration
+	maxBody int64
+	nonces  NonceStore
+	now     func() time.Time
+}
+
+// VerifierOpt is a functional option to modify the Verifier
+type VerifierOpt func(*Verifier)
+
+// NewVerifier returns a Verifier allowing five minutes of clock skew and HTTP bodies up to 10MiB
+func NewVerifier(keys KeyStore, opts ...VerifierOpt) *Verifier {
+	v := &Verifier{
+		keys:    keys,
+		headers: DefaultHeaders,
+		skew:    5 * time.Minute,
+		maxBody: 10 << 20,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// SetVerifiedHeaders sets the headers included in the signature. Signers must use the same list.
+func SetVerifiedHeaders(h ...string) VerifierOpt {
+	return func(v *Verifier) {
+		v.headers = h
+	}
+}
+
+// SetMaxSkew sets how far the signature timestamp may be from the current time
+func SetMaxSkew(d time.Duration) VerifierOpt {
+	return func(v *Verifier) {
+		v.skew = d
+	}
+}
+
+// SetMaxBodyBytes limits the size of HTTP request bodies read for verification
+func SetMaxBodyBytes(n int64) VerifierOpt {
+	return func(v *Verifier) {
+		v.maxBody = n
+	}
+}
+
+// SetNonceStore rejects requests whose nonce was already used. Requests without a nonce are rejected as unsigned.
+func SetNonceStore(n NonceStore) VerifierOpt {
+	return func(v *Verifier) {
+		v.nonces = n
+	}
+}
+
+// verify checks the signature headers and returns the name of the signing service
+func (v *Verifier) verify(ctx context.Context, target string, headers http.Header, values headerValues, body []byte) (string, error) {
+	name := headers.Get(SignatureKeyHeader)
+	ts := headers.Get(SignatureTimeHeader)
+	encoded := headers.Get(SignatureHeader)
+	nonce := headers.Get(SignatureNonceHeader)
+	if name == "" || ts == "" || encoded == "" || (v.nonces != nil && nonce == "") {
+		return "", ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+
+	if d := v.now().Sub(time.Unix(unix, 0)); d > v.skew || d < -v.skew {
+		return "", ErrExpiredSignature
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad encoding", ErrInvalidSignature)
+	}
+
+	pub, err := v.keys.PublicKey(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("error getting public key for %s: %w", name, err)
+	}
+
+	kp, err := nkeys.FromPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	if err := kp.Verify(canonical(ts, nonce, target, values, v.headers, body), sig); err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	// nonces are only recorded once the signature is valid, so unsigned requests can't fill the store. A nonce
+	// only has to be remembered until its timestamp falls outside the skew.
+	if v.nonces != nil {
+		fresh, err := v.nonces.Claim(ctx, name+"."+nonce, time.Unix(unix, 0).Add(v.skew))
+		if err != nil {
+			return "", fmt.Errorf("error recording signature nonce: %w", err)
+		}
+		if !fresh {
+			return "", ErrReplayedRequest
+		}
+	}
+
+	return name, nil
+}
+
+// SignMsg signs the message data, subject, and selected headers
+func (s *Signer) SignMsg(msg *nats.Msg) error {
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+
+	return s.sign(msg.Subject, http.Header(msg.Header), natsValues(msg.Header), msg.Data)
+}
+
+// VerifyMsg verifies a message signed with SignMsg and returns the signing service name
+func (v *Verifier) VerifyMsg(ctx context.Context, subject string, headers nats.Header, data []byte) (string, error) {
+	return v.verify(ctx, subject, http.Header(headers), natsValues(headers), data)
+}
+
+// SignRequest signs the request method, URI, selected headers, and body. The body is buffered and replaced.
+func (s *Signer) SignRequest(r *http.Request) error {
+	body, err := readBody(r, 0)
+	if err != nil {
+		return err
+	}
+
+	return s.sign(httpTarget(r), r.Header, r.Header.Values, body)
+}
+
+// VerifyRequest verifies a request signed with SignRequest and returns the signing service name
+func (v *Verifier) VerifyRequest(r *http.Request) (string, error) {
+	body, err := readBody(r, v.maxBody)
+	if err != nil {
+		return "", err
+	}
+
+	return v.verify(r.Context(), httpTarget(r), r.Header, r.Header.Values, body)
+}
+
+// readBody buffers and replaces the request body. A limit above zero rejects larger bodies with ErrBodyTooLarge.
+func readBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	reader := io.Reader(r.Body)
+	if limit > 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// Transport returns a RoundTripper that signs every request before sending it with next
+func (s *Signer) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		if err := s.SignRequest(r); err != nil {
+			return nil, err
+		}
+		return next.RoundTrip(r)
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware rejects HTTP requests without a valid signature with a 401
+func (v *Verifier) Middleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := v.VerifyRequest(r); err != nil {
+			if errors.Is(err, ErrBodyTooLarge) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// MicroHandler rejects NATS requests without a valid signature with a 401 error
+func (v *Verifier) MicroHandler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		if _, err := v.VerifyMsg(context.Background(), r.Subject(), nats.Header(r.Headers()), r.Data()); err != nil {
+			body := []byte(`{"errors": ["invalid request signature"]}`)
+			if errors.Is(err, ErrMissingSignature) {
+				body = []byte(`{"errors": ["missing request signature"]}`)
+			}
+			r.Error("401", http.StatusText(http.StatusUnauthorized), body)
+			return
+		}
+
+		h.Handle(r)
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExportType is the kind of NATS account export
+type ExportType string
+
+const (
+	ExportService ExportType = "service"
+	ExportStream  ExportType = "stream"
+)
+
+// Export is a subject a shared account makes available to tenant accounts
+type Export struct {
+	Type    ExportType
+	Subject string
+}
+
+// Import maps a subject exported by a shared account into a tenant account
+type Import struct {
+	Type    ExportType
+	Account string
+	Subject string
+	To      string
+}
+
+// Exports returns the exports for a shared account where each subject is available to every tenant under
+// its tenant prefix
+func (m *Mapper) Exports(t ExportType, subjects ...string) []Export {
+	var exports []Export
+	for _, s := range subjects {
+		exports = append(exports, Export{Type: t, Subject: fmt.Sprintf("%s.%s", m.Prefix("*"), s)})
+	}
+
+	return exports
+}
+
+// Imports returns the imports for a tenant account so local, unprefixed subjects reach the shared account
+// under the tenant prefix. This follows the convention of mapping services with the import "to" field.
+func (m *Mapper) Imports(tenant, account string, t ExportType, subjects ...string) ([]Import, error) {
+	if err := ValidateTenant(tenant); err != nil {
+		return nil, err
+	}
+
+	var imports []Import
+	for _, s := range subjects {
+		imports = append(imports, Import{
+			Type:    t,
+			Account: account,
+			Subject: fmt.Sprintf("%s.%s", m.Prefix(tenant), s),
+			To:      s,
+		})
+	}
+
+	return imports, nil
+}
+
+// Config renders the import in nats-server configuration syntax
+func (i Import) Config() string {
+	return fmt.Sprintf("{%s: {account: %q, subject: %q}, to: %q}", i.Type, i.Account, i.Subject, i.To)
+}
+
+// Config renders the export in nats-server configuration syntax
+func (e Export) Config() string {
+	return fmt.Sprintf("{%s: %q}", e.Type, e.Subject)
+}
+
+// AccountConfig renders imports or exports as a nats-server configuration list
+func AccountConfig[T interface{ Config() string }](items []T) string {
+	lines := make([]string, 0, len(items))
+	for _, v := range items {
+		lines = append(lines, "  "+v.Config())
+	}
+
+	return fmt.Sprintf("[\n%s\n]", strings.Join(lines, ",\n"))
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+var (
+	ErrInvalidTenant = fmt.Errorf("invalid tenant name")
+	ErrOutsideTenant = fmt.Errorf("subject is outside of the tenant namespace")
+)
+
+// MapperOpt is a functional option to modify the Mapper
+type MapperOpt func(*Mapper)
+
+// Mapper computes per-tenant subjects of the form <root>.<tenant>.<subject>
+type Mapper struct {
+	root string
+}
+
+// NewMapper returns a Mapper with the root prefix "tenants"
+func NewMapper(opts ...MapperOpt) *Mapper {
+	m := &Mapper{
+		root: "tenants",
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// SetRoot sets the prefix placed before the tenant token. An empty root places the tenant first.
+func SetRoot(r string) MapperOpt {
+	return func(m *Mapper) {
+		m.root = strings.Trim(r, ".")
+	}
+}
+
+// ValidateTenant returns an error if the name can't be used as a single subject token
+func ValidateTenant(name string) error {
+	if name == "" || strings.ContainsAny(name, ".*> \t\r\n") {
+		return fmt.Errorf("%w: %q", ErrInvalidTenant, name)
+	}
+
+	return nil
+}
+
+// Prefix returns the subject prefix for the tenant without a trailing dot
+func (m *Mapper) Prefix(tenant string) string {
+	if m.root == "" {
+		return tenant
+	}
+
+	return fmt.Sprintf("%s.%s", m.root, tenant)
+}
+
+// Subject returns the subject scoped to the tenant
+func (m *Mapper) Subject(tenant, subject string) (string, error) {
+	if err := ValidateTenant(tenant); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", m.Prefix(tenant), subject), nil
+}
+
+// Tenant returns the tenant a subject belongs to
+func (m *Mapper) Tenant(subject string) (string, bool) {
+	tokens := strings.Split(subject, ".")
+	idx := 0
+	if m.root != "" {
+		root := strings.Split(m.root, ".")
+		if len(tokens) <= len(root) || strings.Join(tokens[:len(root)], ".") != m.root {
+			return "", false
+		}
+		idx = len(root)
+	}
+
+	if idx >= len(tokens) || ValidateTenant(tokens[idx]) != nil {
+		return "", false
+	}
+
+	return tokens[idx], true
+}
+
+// Strip returns the subject with the tenant prefix removed
+func (m *Mapper) Strip(tenant, subject string) (string, error) {
+	prefix := m.Prefix(tenant) + "."
+	if !strings.HasPrefix(subject, prefix) || len(subject) == len(prefix) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideTenant, subject)
+	}
+
+	return strings.TrimPrefix(subject, prefix), nil
+}
+
+// Within reports whether the subject, which may contain wildcards, only matches subjects in the tenant namespace
+func (m *Mapper) Within(tenant, subject string) bool {
+	if ValidateTenant(tenant) != nil {
+		return false
+	}
+
+	_, err := m.Strip(tenant, subject)
+	return err == nil
+}
+
+// Scoped wraps a connection so every subject is placed in and checked against a single tenant namespace. Every
+// method takes subjects relative to the tenant prefix.
+type Scoped struct {
+	Conn   *nats.Conn
+	Tenant string
+	Mapper *Mapper
+}
+
+// NewScoped returns a Scoped connection for the tenant
+func NewScoped(nc *nats.Conn, tenant string, m *Mapper) (*Scoped, error) {
+	if err := ValidateTenant(tenant); err != nil {
+		return nil, err
+	}
+
+	return &Scoped{Conn: nc, Tenant: tenant, Mapper: m}, nil
+}
+
+// Subject returns the subject scoped to the tenant
+func (s *Scoped) Subject(subject string) string {
+	return fmt.Sprintf("%s.%s", s.Mapper.Prefix(s.Tenant), subject)
+}
+
+// Check returns ErrOutsideTenant if the fully qualified subject is not in the tenant namespace
+func (s *Scoped) Check(subject string) error {
+	if !s.Mapper.Within(s.Tenant, subject) {
+		return fmt.Errorf("%w: %s", ErrOutsideTenant, subject)
+	}
+
+	return nil
+}
+
+// scoped returns the subject relative to the tenant prefix as a fully qualified subject in the namespace
+func (s *Scoped) scoped(subject string) (string, error) {
+	full := s.Subject(subject)
+	if err := s.Check(full); err != nil {
+		return "", err
+	}
+
+	return full, nil
+}
+
+// scopedMsg returns a copy of the message with its subject scoped to the tenant, after checking its reply
+func (s *Scoped) scopedMsg(msg *nats.Msg) (*nats.Msg, error) {
+	subject, err := s.scoped(msg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkReply(msg.Reply); err != nil {
+		return nil, err
+	}
+
+	return &nats.Msg{Subject: subject, Reply: msg.Reply, Header: msg.Header, Data: msg.Data}, nil
+}
+
+// PublishMsg publishes the message to its subject relative to the tenant prefix. The reply must be fully
+// qualified, such as one from NewInbox.
+func (s *Scoped) PublishMsg(msg *nats.Msg) error {
+	m, err := s.scopedMsg(msg)
+	if err != nil {
+		return err
+	}
+
+	return s.Conn.PublishMsg(m)
+}
+
+// checkReply allows replies in the tenant namespace and to the connection's own request inbox. Other inboxes
+// may belong to another tenant's connection, so they are rejected; use NewInbox for inboxes in the namespace.
+func (s *Scoped) checkReply(reply string) error {
+	if reply == "" {
+		return nil
+	}
+
+	resp := s.Conn.NewRespInbox()
+	if prefix := resp[:strings.LastIndex(resp, ".")+1]; strings.HasPrefix(reply, prefix) {
+		return nil
+	}
+
+	return s.Check(reply)
+}
+
+// NewInbox returns a unique inbox subject in the tenant namespace
+func (s *Scoped) NewInbox() string {
+	return s.Subject("_INBOX." + nuid.Next())
+}
+
+// Publish publishes data to the subject relative to the tenant prefix
+func (s *Scoped) Publish(subject string, data []byte) error {
+	full, err := s.scoped(subject)
+	if err != nil {
+		return err
+	}
+
+	return s.Conn.Publish(full, data)
+}
+
+// Subscribe subscribes to the subject relative to the tenant prefix
+func (s *Scoped) Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	full, err := s.scoped(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Conn.Subscribe(full, cb)
+}
+
+// QueueSubscribe subscribes to the subject relative to the tenant prefix with a queue group
+func (s *Scoped) QueueSubscribe(subject, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	full, err := s.scoped(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Conn.QueueSubscribe(full, queue, cb)
+}
+
+// RequestMsg sends the request to its subject relative to the tenant prefix
+func (s *Scoped) RequestMsg(msg *nats.Msg, timeout time.Duration) (*nats.Msg, error) {
+	m, err := s.scopedMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Conn.RequestMsg(m, timeout)
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestMapper(t *testing.T) {
+	tt := []struct {
+		name    string
+		opts    []MapperOpt
+		tenant  string
+		subject string
+		within  bool
+	}{
+		{name: "scoped", tenant: "acme", subject: "tenants.acme.orders.created", within: true},
+		{name: "scoped wildcard", tenant: "acme", subject: "tenants.acme.>", within: true},
+		{name: "other tenant", tenant: "acme", subject: "tenants.globex.orders.created", within: false},
+		{name: "tenant wildcard", tenant: "acme", subject: "tenants.*.orders.created", within: false},
+		{name: "full wildcard", tenant: "acme", subject: ">", within: false},
+		{name: "prefix only", tenant: "acme", subject: "tenants.acme", within: false},
+		{name: "custom root", opts: []MapperOpt{SetRoot("prod.t")}, tenant: "acme", subject: "prod.t.acme.orders", within: true},
+		{name: "no root", opts: []MapperOpt{SetRoot("")}, tenant: "acme", subject: "acme.orders", within: true},
+		{name: "invalid tenant", tenant: "ac.me", subject: "tenants.ac.me.orders", within: false},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			m := NewMapper(v.opts...)
+			if got := m.Within(v.tenant, v.subject); got != v.within {
+				t.Fatalf("expected within %t but got %t", v.within, got)
+			}
+
+			if !v.within {
+				return
+			}
+
+			tenant, ok := m.Tenant(v.subject)
+			if !ok || tenant != v.tenant {
+				t.Errorf("expected tenant %s but got %s", v.tenant, tenant)
+			}
+		})
+	}
+}
+
+func TestScopedReply(t *testing.T) {
+	s, err := NewScoped(&nats.Conn{}, "acme", NewMapper())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name  string
+		reply string
+		err   error
+	}{
+		{name: "none", reply: ""},
+		{name: "tenant", reply: "tenants.acme.replies.1"},
+		{name: "tenant inbox", reply: s.NewInbox()},
+		{name: "own request inbox", reply: s.Conn.NewRespInbox()},
+		{name: "other inbox", reply: nats.NewInbox(), err: ErrOutsideTenant},
+		{name: "other tenant", reply: "tenants.globex.replies.1", err: ErrOutsideTenant},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if err := s.checkReply(v.reply); !errors.Is(err, v.err) {
+				t.Errorf("expected %v but got %v", v.err, err)
+			}
+		})
+	}
+}
+
+func TestScopedMsg(t *testing.T) {
+	s, err := NewScoped(&nats.Conn{}, "acme", NewMapper())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name string
+		msg  *nats.Msg
+		want string
+		err  error
+	}{
+		{name: "relative", msg: &nats.Msg{Subject: "orders.created"}, want: "tenants.acme.orders.created"},
+		{name: "prefixed again", msg: &nats.Msg{Subject: "tenants.acme.orders"}, want: "tenants.acme.tenants.acme.orders"},
+		{name: "empty", msg: &nats.Msg{}, err: ErrOutsideTenant},
+		{name: "reply outside", msg: &nats.Msg{Subject: "orders", Reply: "tenants.globex.replies"}, err: ErrOutsideTenant},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			m, err := s.scopedMsg(v.msg)
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if err == nil && m.Subject != v.want {
+				t.Errorf("expected %s but got %s", v.want, m.Subject)
+			}
+			if err == nil && v.msg.Subject == m.Subject {
+				t.Error("expected the caller's message not to be changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package toggle switches individual HTTP routes and NATS endpoints off at runtime. Each toggle is stored as
+// JSON in its own KV key, so a misbehaving feature can be turned off in seconds without a deploy.
+package toggle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/metrics"
+	sdnats "github.com/SencilloDev/sencillo-go/transports/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Header names the toggle that turned a request away
+const Header = "Sencillo-Toggle"
+
+var ErrInvalidToggle = fmt.Errorf("invalid toggle")
+
+// Toggle turns off the HTTP requests whose path matches Path and the NATS requests whose subject matches
+// Subject while Disabled is set. Unlike chaos faults, an empty Path or Subject matches nothing.
+type Toggle struct {
+	// Path is a path.Match pattern like /api/orders/*
+	Path string `json:"path,omitempty"`
+	// Method limits the toggle to one HTTP method
+	Method string `json:"method,omitempty"`
+	// Subject is a NATS subject that can contain * and > wildcards
+	Subject  string `json:"subject,omitempty"`
+	Disabled bool   `json:"disabled"`
+	// Status is returned while the toggle is off, 503 by default. Use 404 to hide the endpoint.
+	Status int `json:"status,omitempty"`
+	// Message is returned to callers while the toggle is off
+	Message string `json:"message,omitempty"`
+}
+
+// Validate checks that the toggle matches something and has an error status
+func (t Toggle) Validate() error {
+	if t.Path == "" && t.Subject == "" {
+		return fmt.Errorf("%w: set a path or subject", ErrInvalidToggle)
+	}
+	if t.Path != "" {
+		if _, err := path.Match(t.Path, ""); err != nil {
+			return fmt.Errorf("%w: path %q: %v", ErrInvalidToggle, t.Path, err)
+		}
+	}
+	if t.Subject != "" {
+		if err := sdnats.ValidateSubject(t.Subject, true); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidToggle, err)
+		}
+	}
+	if t.Status != 0 && (t.Status < 400 || t.Status > 599) {
+		return fmt.Errorf("%w: status %d is not an error", ErrInvalidToggle, t.Status)
+	}
+	return nil
+}
+
+// err returns the client error for requests turned away by the toggle
+func (t Toggle) err() sderrors.ClientError {
+	status := t.Status
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	msg := t.Message
+	if msg == "" {
+		msg = "this endpoint is temporarily disabled"
+	}
+	return sderrors.NewClientError(fmt.Errorf("%s", msg), status)
+}
+
+// ControllerOpt is a functional option to modify the Controller
+type ControllerOpt func(*Controller)
+
+// Controller holds the current toggles and turns away requests to disabled endpoints
+type Controller struct {
+	mu      sync.Mutex
+	toggles atomic.Pointer[map[string]Toggle]
+	logger  *slog.Logger
+
+	disabled prometheus.Gauge
+	rejected *prometheus.CounterVec
+}
+
+// NewController returns a Controller with every endpoint enabled until a toggle is set
+func NewController(opts ...ControllerOpt) *Controller {
+	c := &Controller{
+		logger:   slog.Default(),
+		disabled: prometheus.NewGauge(prometheus.GaugeOpts{Name: "endpoint_toggles_disabled", Help: "Toggles currently turning endpoints off"}),
+		rejected: metrics.NewCounterVec("endpoint_toggle_rejections", "Requests turned away by a disabled toggle", []string{"toggle", "transport"}),
+	}
+	c.toggles.Store(&map[string]Toggle{})
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// SetLogger sets the logger used to report toggle changes
+func SetLogger(l *slog.Logger) ControllerOpt {
+	return func(c *Controller) {
+		c.logger = l
+	}
+}
+
+// Metrics returns the collectors to register with the exporter
+func (c *Controller) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.disabled, c.rejected}
+}
+
+// Set adds or replaces the named toggle
+func (c *Controller) Set(name string, t Toggle) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	c.update(func(m map[string]Toggle) { m[name] = t })
+	return nil
+}
+
+// Remove deletes the named toggle, enabling whatever it turned off
+func (c *Controller) Remove(name string) {
+	c.update(func(m map[string]Toggle) { delete(m, name) })
+}
+
+// Toggles returns the current toggles by name
+func (c *Controller) Toggles() map[string]Toggle {
+	return maps.Clone(*c.toggles.Load())
+}
+
+// update copies the toggles so requests can read them without locking
+func (c *Controller) update(f func(map[string]Toggle)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := maps.Clone(*c.toggles.Load())
+	f(m)
+	c.toggles.Store(&m)
+
+	disabled := 0
+	for _, t := range m {
+		if t.Disabled {
+			disabled++
+		}
+	}
+	c.disabled.Set(float64(disabled))
+}
+
+// Watch keeps the toggles in sync with the KV keys matching keys, such as toggles.>, until the context is
+// cancelled. Each key holds one toggle named after the key. Deleting a key removes its toggle. Invalid
+// toggles are logged and the previous value is kept.
+func (c *Controller) Watch(ctx context.Context, kv nats.KeyValue, keys string) error {
+	w, err := kv.Watch(keys, nats.Context(ctx))
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-w.Updates():
+			if !ok {
+				return nil
+			}
+			// nil marks the end of the initial values
+			if e == nil {
+				continue
+			}
+			c.apply(e.Key(), e.Operation(), e.Value())
+		}
+	}
+}
+
+func (c *Controller) apply(key string, op nats.KeyValueOp, value []byte) {
+	if op != nats.KeyValuePut {
+		c.Remove(key)
+		c.logger.Info(fmt.Sprintf("toggle %s removed", key))
+		return
+	}
+
+	var t Toggle
+	if err := json.Unmarshal(value, &t); err != nil {
+		c.logger.Error(fmt.Sprintf("invalid toggle %s: %v", key, err))
+		return
+	}
+	if err := c.Set(key, t); err != nil {
+		c.logger.Error(fmt.Sprintf("toggle %s: %v", key, err))
+		return
+	}
+
+	c.logger.Info(fmt.Sprintf("toggle %s updated: disabled=%v", key, t.Disabled))
+}
+
+// disabledBy returns the disabled toggle matching the request, if any. Toggles are checked by name so the
+// same one wins every time when several match.
+func (c *Controller) disabledBy(match func(Toggle) bool) (string, Toggle, bool) {
+	m := *c.toggles.Load()
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if t := m[name]; t.Disabled && match(t) {
+			return name, t, true
+		}
+	}
+	return "", Toggle{}, false
+}
+
+// Middleware turns away HTTP requests to disabled endpoints with the toggle's status and message
+func (c *Controller) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, t, ok := c.disabledBy(func(t Toggle) bool {
+			return t.Path != "" && matchPath(t.Path, r.URL.Path) && (t.Method == "" || t.Method == r.Method)
+		})
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c.rejected.WithLabelValues(name, "http").Inc()
+		ce := t.err()
+		w.Header().Set(Header, name)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ce.Code())
+		w.Write(ce.Body())
+	})
+}
+
+// Handler turns away NATS requests to disabled endpoints with an error carrying the toggle's status and
+// message
+func (c *Controller) Handler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		name, t, ok := c.disabledBy(func(t Toggle) bool { return t.Subject != "" && sdnats.MatchSubject(t.Subject, r.Subject()) })
+		if !ok {
+			h.Handle(r)
+			return
+		}
+
+		c.rejected.WithLabelValues(name, "nats").Inc()
+		ce := t.err()
+		r.Error(strconv.Itoa(ce.Code()), http.StatusText(ce.Code()), ce.Body(), micro.WithHeaders(micro.Headers{Header: {name}}))
+	})
+}
+
+// matchPath reports whether the path matches the path.Match pattern
+func matchPath(pattern, p string) bool {
+	ok, err := path.Match(pattern, p)
+	return err == nil && ok
+}
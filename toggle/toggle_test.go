@@ -0,0 +1,146 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toggle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMiddleware(t *testing.T) {
+	tt := []struct {
+		name   string
+		toggle Toggle
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{name: "enabled", toggle: Toggle{Path: "/orders"}, method: http.MethodGet, path: "/orders", code: 200},
+		{name: "disabled", toggle: Toggle{Path: "/orders", Disabled: true}, method: http.MethodGet, path: "/orders", code: 503, body: `{"errors": ["this endpoint is temporarily disabled"]}`},
+		{name: "hidden", toggle: Toggle{Path: "/orders/*", Disabled: true, Status: 404, Message: "not found"}, method: http.MethodGet, path: "/orders/7", code: 404, body: `{"errors": ["not found"]}`},
+		{name: "other path", toggle: Toggle{Path: "/users/*", Disabled: true}, method: http.MethodGet, path: "/orders", code: 200},
+		{name: "other method", toggle: Toggle{Path: "/orders", Method: http.MethodPost, Disabled: true}, method: http.MethodGet, path: "/orders", code: 200},
+		{name: "subject only", toggle: Toggle{Subject: "orders.>", Disabled: true}, method: http.MethodGet, path: "/orders", code: 200},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			c := NewController()
+			if err := c.Set("orders", v.toggle); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, httptest.NewRequest(v.method, v.path, nil))
+			if rec.Code != v.code {
+				t.Fatalf("expected %d but got %d", v.code, rec.Code)
+			}
+			if v.body != "" && rec.Body.String() != v.body {
+				t.Errorf("expected %s but got %s", v.body, rec.Body.String())
+			}
+			if toggled := rec.Header().Get(Header) == "orders"; toggled != (v.code != 200) {
+				t.Errorf("unexpected %s header %q", Header, rec.Header().Get(Header))
+			}
+		})
+	}
+}
+
+type fakeRequest struct {
+	micro.Request
+	subject string
+	code    string
+	data    []byte
+	handled bool
+}
+
+func (f *fakeRequest) Subject() string { return f.subject }
+
+func (f *fakeRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	f.code = code
+	f.data = data
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	c := NewController()
+	reg := sdtest.NewRegistry(t, c.Metrics()...)
+	c.Set("create", Toggle{Subject: "orders.create", Disabled: true, Message: "ordering is paused"})
+	c.Set("reads", Toggle{Subject: "orders.get.*", Disabled: true, Status: 404})
+	c.Set("users", Toggle{Subject: "users.>"})
+
+	tt := []struct {
+		subject string
+		handled bool
+		code    string
+		data    string
+	}{
+		{subject: "orders.create", code: "503", data: `{"errors": ["ordering is paused"]}`},
+		{subject: "orders.get.7", code: "404"},
+		{subject: "users.create", handled: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.subject, func(t *testing.T) {
+			r := &fakeRequest{subject: v.subject}
+			c.Handler(micro.HandlerFunc(func(micro.Request) { r.handled = true })).Handle(r)
+
+			if r.handled != v.handled || r.code != v.code {
+				t.Errorf("expected handled=%v code=%q but got handled=%v code=%q", v.handled, v.code, r.handled, r.code)
+			}
+			if v.data != "" && string(r.data) != v.data {
+				t.Errorf("expected %s but got %s", v.data, r.data)
+			}
+		})
+	}
+	reg.AssertGauge(t, "endpoint_toggles_disabled", nil, 2)
+	reg.AssertCounter(t, "endpoint_toggle_rejections", prometheus.Labels{"toggle": "create", "transport": "nats"}, 1)
+}
+
+func TestApply(t *testing.T) {
+	c := NewController()
+
+	c.apply("toggles.orders", nats.KeyValuePut, []byte(`{"subject": "orders.>", "disabled": true, "status": 404}`))
+	if got := c.Toggles()["toggles.orders"]; !got.Disabled || got.Status != 404 {
+		t.Fatalf("unexpected toggle %+v", got)
+	}
+
+	for _, invalid := range []string{`{"disabled": true}`, `{"subject": "orders.>", "status": 200}`, `{"path": "[", "disabled": true}`, `not json`} {
+		c.apply("toggles.orders", nats.KeyValuePut, []byte(invalid))
+		if got := c.Toggles()["toggles.orders"]; got.Subject != "orders.>" {
+			t.Errorf("expected %s to keep the previous toggle but got %+v", invalid, got)
+		}
+	}
+
+	c.apply("toggles.orders", nats.KeyValueDelete, nil)
+	if len(c.Toggles()) != 0 {
+		t.Error("expected deleting the key to remove the toggle")
+	}
+
+	if err := c.Set("empty", Toggle{}); !errors.Is(err, ErrInvalidToggle) {
+		t.Errorf("expected %v but got %v", ErrInvalidToggle, err)
+	}
+
+	if err := c.Set("subject", Toggle{Subject: "orders.>.create"}); !errors.Is(err, ErrInvalidToggle) {
+		t.Errorf("expected %v but got %v", ErrInvalidToggle, err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing adds debugging context to OpenTelemetry spans
+package tracing
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"unicode/utf8"
+
+	"github.com/SencilloDev/sencillo-go/scrub"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PayloadEvent is the name of span events holding payload samples
+const PayloadEvent = "payload"
+
+// PayloadOpt is a functional option to modify the PayloadSampler
+type PayloadOpt func(*PayloadSampler)
+
+// PayloadSampler attaches truncated, scrubbed samples of request and response payloads to spans as events, so a
+// trace of a failed request shows what was sent without logging every payload
+type PayloadSampler struct {
+	rate      float64
+	errorRate float64
+	maxBytes  int
+	scrubber  *scrub.Scrubber
+	random    func() float64
+}
+
+// SetSampleRate sets the fraction of successful requests sampled. The default is 0.
+func SetSampleRate(rate float64) PayloadOpt {
+	return func(p *PayloadSampler) {
+		p.rate = rate
+	}
+}
+
+// SetErrorSampleRate sets the fraction of failed requests sampled. The default is 1.
+func SetErrorSampleRate(rate float64) PayloadOpt {
+	return func(p *PayloadSampler) {
+		p.errorRate = rate
+	}
+}
+
+// SetMaxPayloadBytes sets how much of each payload is kept after scrubbing. The default is 1KB.
+func SetMaxPayloadBytes(n int) PayloadOpt {
+	return func(p *PayloadSampler) {
+		p.maxBytes = n
+	}
+}
+
+// SetScrubber sets the scrubber applied to payloads before they are truncated. The default is a scrub.Scrubber
+// with the default detectors.
+func SetScrubber(s *scrub.Scrubber) PayloadOpt {
+	return func(p *PayloadSampler) {
+		p.scrubber = s
+	}
+}
+
+// NewPayloadSampler returns a PayloadSampler sampling every failed request and no successful ones
+func NewPayloadSampler(opts ...PayloadOpt) *PayloadSampler {
+	p := &PayloadSampler{
+		errorRate: 1,
+		maxBytes:  1024,
+		scrubber:  scrub.NewScrubber(),
+		random:    rand.Float64,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Sampled reports whether a request's payloads should be attached to its span
+func (p *PayloadSampler) Sampled(span trace.Span, failed bool) bool {
+	if p == nil || !span.IsRecording() {
+		return false
+	}
+
+	rate := p.rate
+	if failed {
+		rate = p.errorRate
+	}
+
+	return rate > 0 && p.random() < rate
+}
+
+// Record adds a payload event to the span for the payload, such as request or response. Call it for each
+// payload of a request once Sampled returns true.
+func (p *PayloadSampler) Record(span trace.Span, kind string, payload []byte) {
+	attrs := []attribute.KeyValue{
+		attribute.String("payload.kind", kind),
+		attribute.Int("payload.size", len(payload)),
+	}
+
+	// binary payloads are sent base64 encoded since span attributes must be valid UTF-8
+	if !utf8.Valid(payload) {
+		sample := payload[:min(len(payload), p.maxBytes*3/4)]
+		attrs = append(attrs,
+			attribute.String("payload.encoding", "base64"),
+			attribute.Bool("payload.truncated", len(sample) < len(payload)),
+			attribute.String("payload.sample", base64.StdEncoding.EncodeToString(sample)),
+		)
+		span.AddEvent(PayloadEvent, trace.WithAttributes(attrs...))
+		return
+	}
+
+	scrubbed := p.scrubber.Bytes(payload)
+	sample := truncateUTF8(scrubbed, p.maxBytes)
+	attrs = append(attrs,
+		attribute.Bool("payload.truncated", len(sample) < len(scrubbed)),
+		attribute.String("payload.sample", string(sample)),
+	)
+	span.AddEvent(PayloadEvent, trace.WithAttributes(attrs...))
+}
+
+// truncateUTF8 cuts b to at most n bytes without splitting a rune
+func truncateUTF8(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	b = b[:n]
+	for len(b) > 0 && !utf8.Valid(b) {
+		b = b[:len(b)-1]
+	}
+
+	return b
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/scrub"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+func TestPayloadSampler(t *testing.T) {
+	tp, rec := sdtest.NewTracerProvider(t)
+	tracer := tp.Tracer("test")
+
+	tt := []struct {
+		name    string
+		opts    []PayloadOpt
+		failed  bool
+		payload []byte
+		sampled bool
+		sample  string
+		attrs   map[string]string
+	}{
+		{name: "success not sampled", payload: []byte(`{}`)},
+		{name: "failure", failed: true, sampled: true, payload: []byte(`{"password":"x","id":1}`), sample: `{"id":1,"password":"[REDACTED]"}`},
+		{name: "truncated json", failed: true, sampled: true, payload: []byte(`{"user":"ana","password":"hunt`), sample: `{"user":"ana","password":"[REDACTED]"`},
+		{name: "form", failed: true, sampled: true, payload: []byte(`user=ana&api_key=abc`), sample: `user=ana&api_key=[REDACTED]`},
+		{name: "custom scrubber", opts: []PayloadOpt{SetScrubber(scrub.NewScrubber(scrub.SetDetectors(scrub.Fields("pin"))))}, failed: true, sampled: true, payload: []byte(`{"pin":"1234","password":"x"}`), sample: `{"password":"x","pin":"[REDACTED]"}`},
+		{name: "success sampled", opts: []PayloadOpt{SetSampleRate(1)}, sampled: true, payload: []byte(`{"id":1}`), sample: `{"id":1}`},
+		{name: "truncated", opts: []PayloadOpt{SetMaxPayloadBytes(4)}, failed: true, sampled: true, payload: []byte("héllo"), sample: "hél", attrs: map[string]string{"payload.truncated": "true"}},
+		{name: "binary", failed: true, sampled: true, payload: []byte{0xff, 0xfe, 0x00}, sample: "//4A", attrs: map[string]string{"payload.encoding": "base64"}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			rec.Reset()
+			s := NewPayloadSampler(v.opts...)
+			_, span := tracer.Start(context.Background(), v.name)
+			sampled := s.Sampled(span, v.failed)
+			if sampled {
+				s.Record(span, "request", v.payload)
+			}
+			span.End()
+
+			if sampled != v.sampled {
+				t.Fatalf("expected sampled to be %v", v.sampled)
+			}
+			if !v.sampled {
+				return
+			}
+
+			events := rec.Spans()[0].Events()
+			if len(events) != 1 || events[0].Name != PayloadEvent {
+				t.Fatalf("expected one payload event but got %v", events)
+			}
+			attrs := map[string]string{}
+			for _, a := range events[0].Attributes {
+				attrs[string(a.Key)] = a.Value.Emit()
+			}
+			if attrs["payload.sample"] != v.sample {
+				t.Errorf("expected sample %q but got %q", v.sample, attrs["payload.sample"])
+			}
+			for k, want := range v.attrs {
+				if attrs[k] != want {
+					t.Errorf("expected %s to be %s but got %s", k, want, attrs[k])
+				}
+			}
+		})
+	}
+}
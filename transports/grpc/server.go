@@ -0,0 +1,312 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// HealthCheck returns an error when something a service depends on is unhealthy. It has the same signature as
+// the NATS transport's health checks, so the same functions work with both.
+type HealthCheck func(context.Context) error
+
+// Readiness reports whether the process as a whole is ready for requests. *lifecycle.Sequencer implements it.
+type Readiness interface {
+	Ready() bool
+}
+
+// ServerOption is a functional option to modify the server
+type ServerOption func(*Server)
+
+// Server is a gRPC server with the standard health and reflection services registered. Services registered with
+// RegisterService get a health status that follows the process readiness and their own checks.
+type Server struct {
+	GRPC   *grpc.Server
+	Logger *slog.Logger
+
+	addr      string
+	adminAddr string
+	grpcOpts  []grpc.ServerOption
+	health    *health.Server
+	readiness Readiness
+	checks    map[string][]HealthCheck
+	interval  time.Duration
+	timeout   time.Duration
+
+	admin *grpc.Server
+
+	mu         sync.Mutex
+	services   []string
+	stopHealth context.CancelFunc
+}
+
+// NewGRPCServer initializes and returns a new Server listening on port 9090
+func NewGRPCServer(opts ...ServerOption) *Server {
+	s := &Server{
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		addr:     ":9090",
+		health:   health.NewServer(),
+		checks:   make(map[string][]HealthCheck),
+		interval: 10 * time.Second,
+		timeout:  5 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.GRPC = grpc.NewServer(s.grpcOpts...)
+	healthpb.RegisterHealthServer(s.GRPC, s.health)
+	reflection.Register(s.GRPC)
+
+	// nothing is serving until the first health evaluation when Serve starts
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	if s.adminAddr != "" {
+		s.admin = grpc.NewServer()
+		channelz.RegisterChannelzServiceToServer(s.admin)
+	}
+
+	return s
+}
+
+// SetServerPort sets the server listening port
+func SetServerPort(p int) ServerOption {
+	return func(s *Server) {
+		s.addr = fmt.Sprintf(":%d", p)
+	}
+}
+
+// SetServerOptions sets options passed to grpc.NewServer, such as interceptors and credentials
+func SetServerOptions(opts ...grpc.ServerOption) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, opts...)
+	}
+}
+
+// SetLogger sets the server logger
+func SetLogger(l *slog.Logger) ServerOption {
+	return func(s *Server) {
+		s.Logger = l
+	}
+}
+
+// SetChannelz serves the channelz service on a separate admin listener on the given port, never on the main
+// port. It exposes connection internals, so the admin port should not be reachable from outside the cluster.
+func SetChannelz(adminPort int) ServerOption {
+	return func(s *Server) {
+		s.adminAddr = fmt.Sprintf(":%d", adminPort)
+	}
+}
+
+// SetReadiness makes every service report NOT_SERVING while r isn't ready
+func SetReadiness(r Readiness) ServerOption {
+	return func(s *Server) {
+		s.readiness = r
+	}
+}
+
+// SetHealthCheck adds a check to the named service's health status. The service is NOT_SERVING while the check
+// fails. Checks for an empty service name apply to every service.
+func SetHealthCheck(service string, check HealthCheck) ServerOption {
+	return func(s *Server) {
+		s.checks[service] = append(s.checks[service], check)
+	}
+}
+
+// SetHealthInterval sets how often health checks run
+func SetHealthInterval(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.interval = d
+	}
+}
+
+// SetHealthTimeout sets the deadline for a single run of a health check
+func SetHealthTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// RegisterService registers a service implementation and tracks its health status. Server implements
+// grpc.ServiceRegistrar, so generated RegisterXServer functions can be passed the Server directly.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.GRPC.RegisterService(desc, impl)
+	s.mu.Lock()
+	s.services = append(s.services, desc.ServiceName)
+	s.mu.Unlock()
+	s.health.SetServingStatus(desc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Serve listens on the server port and serves until ShutdownServer is called. Errors are sent on errChan.
+func (s *Server) Serve(errChan chan<- error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	if s.admin != nil {
+		adminLn, err := net.Listen("tcp", s.adminAddr)
+		if err != nil {
+			ln.Close()
+			errChan <- err
+			return
+		}
+		go func() {
+			if err := s.serveAdmin(adminLn); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
+	if err := s.serve(ln); err != nil {
+		errChan <- err
+	}
+}
+
+// serveAdmin serves channelz on the admin listener
+func (s *Server) serveAdmin(ln net.Listener) error {
+	s.Logger.Info(fmt.Sprintf("starting gRPC admin server on %s", ln.Addr()))
+	if err := s.admin.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serve(ln net.Listener) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.stopHealth = cancel
+	s.mu.Unlock()
+	s.updateHealth(ctx)
+	go s.watchHealth(ctx)
+
+	s.Logger.Info(fmt.Sprintf("starting gRPC server on %s", ln.Addr()))
+	if err := s.GRPC.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// ShutdownServer marks every service NOT_SERVING so clients stop sending new calls, then stops gracefully. In
+// flight calls still running when ctx is done are cancelled.
+func (s *Server) ShutdownServer(ctx context.Context) {
+	s.mu.Lock()
+	if s.stopHealth != nil {
+		s.stopHealth()
+	}
+	s.mu.Unlock()
+	s.health.Shutdown()
+
+	if s.admin != nil {
+		s.admin.Stop()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GRPC.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Logger.Warn("gRPC graceful stop timed out, cancelling in flight calls")
+		s.GRPC.Stop()
+	}
+}
+
+func (s *Server) watchHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.updateHealth(ctx)
+		}
+	}
+}
+
+// updateHealth runs every check and sets each service's status. The overall "" status is SERVING only when
+// every service is.
+func (s *Server) updateHealth(ctx context.Context) {
+	failed := make(map[string]bool)
+	for service, checks := range s.checks {
+		for _, check := range checks {
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			err := check(checkCtx)
+			cancel()
+			if err != nil {
+				name := service
+				if name == "" {
+					name = "all services"
+				}
+				s.Logger.Warn(fmt.Sprintf("health check for %s failed: %v", name, err))
+				failed[service] = true
+				break
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	ready := !failed[""] && (s.readiness == nil || s.readiness.Ready())
+	overall := ready
+
+	s.mu.Lock()
+	services := append([]string{}, s.services...)
+	s.mu.Unlock()
+	for service := range s.checks {
+		if service != "" && !slices.Contains(services, service) {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		serving := ready && !failed[service]
+		overall = overall && serving
+		s.health.SetServingStatus(service, status(serving))
+	}
+	s.health.SetServingStatus("", status(overall))
+}
+
+func status(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+type readiness struct {
+	ready atomic.Bool
+}
+
+func (r *readiness) Ready() bool {
+	return r.ready.Load()
+}
+
+// start serves s on a random local port and returns a client connection to it
+func start(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.serve(ln)
+	t.Cleanup(func() { s.ShutdownServer(context.Background()) })
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestHealth(t *testing.T) {
+	ready := &readiness{}
+	ready.ready.Store(true)
+	var ordersDown atomic.Bool
+	ordersDown.Store(true)
+
+	s := NewGRPCServer(
+		SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		SetReadiness(ready),
+		SetHealthInterval(10*time.Millisecond),
+		SetHealthCheck("orders.v1.Orders", func(context.Context) error {
+			if ordersDown.Load() {
+				return fmt.Errorf("database unavailable")
+			}
+			return nil
+		}),
+	)
+	s.RegisterService(&grpc.ServiceDesc{ServiceName: "users.v1.Users", HandlerType: (*any)(nil)}, struct{}{})
+	client := healthpb.NewHealthClient(start(t, s))
+
+	expect := func(service string, want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		var got healthpb.HealthCheckResponse_ServingStatus
+		for range 100 {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got = resp.Status; got == want {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Errorf("expected %q to be %s but got %s", service, want, got)
+	}
+
+	expect("users.v1.Users", healthpb.HealthCheckResponse_SERVING)
+	expect("orders.v1.Orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	expect("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	ordersDown.Store(false)
+	expect("orders.v1.Orders", healthpb.HealthCheckResponse_SERVING)
+	expect("", healthpb.HealthCheckResponse_SERVING)
+
+	ready.ready.Store(false)
+	expect("users.v1.Users", healthpb.HealthCheckResponse_NOT_SERVING)
+	expect("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestReflection(t *testing.T) {
+	s := NewGRPCServer(SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), SetChannelz(0))
+	client := reflectionpb.NewServerReflectionClient(start(t, s))
+
+	stream, err := client.ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}
+	if err := stream.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, v := range resp.GetListServicesResponse().GetService() {
+		got = append(got, v.Name)
+	}
+	sort.Strings(got)
+
+	want := []string{"grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection", "grpc.reflection.v1alpha.ServerReflection"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected services %v but got %v", want, got)
+	}
+}
+
+func TestChannelzAdmin(t *testing.T) {
+	s := NewGRPCServer(SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), SetChannelz(0))
+	start(t, s)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.serveAdmin(ln)
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := channelzpb.NewChannelzClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.GetServers(ctx, &channelzpb.GetServersRequest{}); err != nil {
+		t.Errorf("expected channelz on the admin listener but got %v", err)
+	}
+}
+
+func TestRegisterServiceWhileServing(t *testing.T) {
+	s := NewGRPCServer(SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			s.updateHealth(ctx)
+		}
+	}()
+	for i := range 20 {
+		s.RegisterService(&grpc.ServiceDesc{ServiceName: fmt.Sprintf("test.v1.Service%d", i), HandlerType: (*any)(nil)}, struct{}{})
+	}
+	cancel()
+	<-done
+
+	s.updateHealth(context.Background())
+	if st, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "test.v1.Service19"}); err != nil || st.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected the last service to be serving but got %v %v", st, err)
+	}
+}
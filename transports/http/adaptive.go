@@ -0,0 +1,44 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	sdmiddleware "github.com/SencilloDev/sencillo-go/transports/http/middleware"
+)
+
+// AdaptiveLimit admits requests through the adaptive limiter. Responses with a 5xx status or a canceled
+// request context count as failures, and shed requests get a 503 with Retry-After.
+func AdaptiveLimit(l *concurrency.AdaptiveLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			release, err := l.Acquire()
+			if err != nil {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			rec := &sdmiddleware.StatusRec{ResponseWriter: w}
+			defer func() {
+				release(rec.Status < http.StatusInternalServerError && r.Context().Err() == nil)
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
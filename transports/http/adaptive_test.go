@@ -0,0 +1,60 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	tt := []struct {
+		name   string
+		status int
+		held   bool
+		code   int
+		limit  int
+	}{
+		{name: "ok", status: http.StatusOK, code: http.StatusOK, limit: 2},
+		{name: "client error", status: http.StatusNotFound, code: http.StatusNotFound, limit: 2},
+		{name: "server error", status: http.StatusBadGateway, code: http.StatusBadGateway, limit: 1},
+		{name: "over the limit", status: http.StatusOK, held: true, code: http.StatusServiceUnavailable, limit: 2},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			l := concurrency.NewAdaptiveLimiter("test", concurrency.SetInitialLimit(2), concurrency.SetBackoff(0.5))
+			h := AdaptiveLimit(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(v.status)
+			}))
+			if v.held {
+				l.Acquire()
+				l.Acquire()
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rr.Code != v.code {
+				t.Errorf("expected %d but got %d", v.code, rr.Code)
+			}
+			if got := l.Limit(); got != v.limit {
+				t.Errorf("expected limit %d but got %d", v.limit, got)
+			}
+		})
+	}
+}
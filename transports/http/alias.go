@@ -0,0 +1,119 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrUnknownAliasTarget = fmt.Errorf("alias target is not a route")
+
+// RouteAlias keeps an old path working after a route moves. Requests to Path are handled by the route at Target
+// with the same method, so the two paths must use the same wildcard names.
+type RouteAlias struct {
+	Path   string
+	Target string
+	// Method limits the alias to one method of the target. Empty aliases every method.
+	Method string
+	// Sunset is when the alias will be removed, sent in the Sunset header when set
+	Sunset time.Time
+}
+
+// Aliases registers deprecated paths for renamed routes. An alias responds like its target and marks the
+// response deprecated, so consumers keep working while they migrate and the metrics show who still hasn't.
+type Aliases struct {
+	requests *prometheus.CounterVec
+}
+
+// NewAliases returns Aliases
+func NewAliases() *Aliases {
+	return &Aliases{
+		requests: metrics.NewCounterVec("http_deprecated_requests", "Requests to deprecated route aliases", []string{"method", "alias", "target"}),
+	}
+}
+
+// Metrics returns the aliases' collectors to register with the exporter
+func (a *Aliases) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{a.requests}
+}
+
+// Routes returns routes with a route added for each alias. Alias routes copy their target, including its
+// timeout, body limit, middlewares, and cache policy.
+func (a *Aliases) Routes(routes []Route, aliases ...RouteAlias) ([]Route, error) {
+	out := append([]Route(nil), routes...)
+	var errs []error
+	for _, alias := range aliases {
+		found := false
+		for _, r := range routes {
+			if r.Path != alias.Target || (alias.Method != "" && r.Method != alias.Method) {
+				continue
+			}
+			found = true
+			r.Path = alias.Path
+			r.Middlewares = append([]func(http.Handler) http.Handler{a.deprecate(r.Method, alias)}, r.Middlewares...)
+			out = append(out, r)
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%w: %s %s", ErrUnknownAliasTarget, alias.Method, alias.Target))
+		}
+	}
+
+	return out, errors.Join(errs...)
+}
+
+// deprecate marks responses deprecated and counts them
+func (a *Aliases) deprecate(method string, alias RouteAlias) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.requests.WithLabelValues(method, alias.Path, alias.Target).Inc()
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor(r, alias.Target)))
+			if !alias.Sunset.IsZero() {
+				w.Header().Set("Sunset", alias.Sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Warning", fmt.Sprintf(`299 - "%s is deprecated, use %s"`, alias.Path, alias.Target))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+var wildcard = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// successor returns the path of the alias's target for the request, with its wildcards filled in and the prefix
+// the sub router stripped put back
+func successor(r *http.Request, target string) string {
+	path := wildcard.ReplaceAllStringFunc(target, func(m string) string {
+		sub := wildcard.FindStringSubmatch(m)
+		if sub[2] != "" {
+			// a trailing wildcard matches the rest of the path, slashes included
+			return r.PathValue(sub[1])
+		}
+		return url.PathEscape(r.PathValue(sub[1]))
+	})
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		path = strings.TrimSuffix(u.Path, r.URL.Path) + path
+	}
+	return path
+}
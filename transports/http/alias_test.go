@@ -0,0 +1,85 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestAliases(t *testing.T) {
+	a := NewAliases()
+	reg := sdtest.NewRegistry(t, a.Metrics()...)
+	get := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(r.PathValue("id"))) }
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	routes, err := a.Routes([]Route{
+		{Method: http.MethodGet, Path: "/customers/{id}", Handler: http.HandlerFunc(get)},
+		{Method: http.MethodDelete, Path: "/customers/{id}", Handler: http.HandlerFunc(get)},
+	},
+		RouteAlias{Path: "/users/{id}", Target: "/customers/{id}", Method: http.MethodGet, Sunset: sunset},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewHTTPServer()
+	s.RegisterSubRouter("/aliases", routes)
+
+	tt := []struct {
+		name       string
+		method     string
+		path       string
+		code       int
+		deprecated bool
+	}{
+		{name: "new path", method: http.MethodGet, path: "/aliases/customers/7", code: http.StatusOK},
+		{name: "old path", method: http.MethodGet, path: "/aliases/users/7", code: http.StatusOK, deprecated: true},
+		{name: "method not aliased", method: http.MethodDelete, path: "/aliases/users/7", code: http.StatusMethodNotAllowed},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.Router.ServeHTTP(rec, httptest.NewRequest(v.method, v.path, nil))
+			if rec.Code != v.code {
+				t.Fatalf("expected %d but got %d", v.code, rec.Code)
+			}
+			if v.code == http.StatusOK && rec.Body.String() != "7" {
+				t.Errorf("expected the target's response but got %q", rec.Body.String())
+			}
+			if deprecated := rec.Header().Get("Deprecation") == "true"; deprecated != v.deprecated {
+				t.Errorf("expected deprecated to be %v", v.deprecated)
+			}
+			if v.deprecated {
+				if got := rec.Header().Get("Sunset"); got != "Tue, 30 Jun 2026 00:00:00 GMT" {
+					t.Errorf("unexpected Sunset %q", got)
+				}
+				if got := rec.Header().Get("Link"); got != `</aliases/customers/7>; rel="successor-version"` {
+					t.Errorf("unexpected Link %q", got)
+				}
+			}
+		})
+	}
+	reg.AssertCounter(t, "http_deprecated_requests", prometheus.Labels{"method": "GET", "alias": "/users/{id}", "target": "/customers/{id}"}, 1)
+
+	if _, err := a.Routes(routes, RouteAlias{Path: "/old", Target: "/missing"}); !errors.Is(err, ErrUnknownAliasTarget) {
+		t.Errorf("expected ErrUnknownAliasTarget but got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+)
+
+var ErrInvalidBindTarget = fmt.Errorf("bind target must be a pointer to a struct")
+
+// bindSources are the struct tags read by Bind, in the order they are checked
+var bindSources = []string{"path", "query", "header"}
+
+// Bind populates the struct pointed to by dst from path values, query parameters, and headers using the
+// path, query, and header struct tags. Adding ",required" to a tag rejects requests without the value.
+// Conversion and required errors for all fields are returned together as a 400 ClientError.
+//
+//	type listParams struct {
+//		ID     string   `path:"id"`
+//		Limit  int      `query:"limit"`
+//		Tags   []string `query:"tag"`
+//		Tenant string   `header:"X-Tenant,required"`
+//	}
+func Bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+
+	var errs []error
+	bindStruct(r, v.Elem(), &errs)
+	if len(errs) > 0 {
+		return sderrors.MultipleClientErrors(errs, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func bindStruct(r *http.Request, v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindStruct(r, v.Field(i), errs)
+			continue
+		}
+
+		for _, source := range bindSources {
+			tag, ok := field.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			values := lookupValues(r, source, name)
+			if len(values) == 0 {
+				if opts == "required" {
+					*errs = append(*errs, fmt.Errorf("%s %s is required", source, name))
+				}
+				continue
+			}
+
+			if err := setField(v.Field(i), values); err != nil {
+				*errs = append(*errs, fmt.Errorf("invalid %s %s: %w", source, name, err))
+			}
+		}
+	}
+}
+
+func lookupValues(r *http.Request, source, name string) []string {
+	switch source {
+	case "path":
+		if v := r.PathValue(name); v != "" {
+			return []string{v}
+		}
+	case "query":
+		return r.URL.Query()[name]
+	case "header":
+		return r.Header.Values(name)
+	}
+
+	return nil
+}
+
+func setField(f reflect.Value, values []string) error {
+	if f.Kind() == reflect.Pointer {
+		ptr := reflect.New(f.Type().Elem())
+		if err := setField(ptr.Elem(), values); err != nil {
+			return err
+		}
+		f.Set(ptr)
+		return nil
+	}
+
+	if f.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(f.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(s.Index(i), v); err != nil {
+				return err
+			}
+		}
+		f.Set(s)
+		return nil
+	}
+
+	return setValue(f, values[0])
+}
+
+func setValue(f reflect.Value, s string) error {
+	switch f.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("expected a duration")
+		}
+		f.SetInt(int64(d))
+		return nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("expected an RFC3339 time")
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected a boolean")
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a positive integer")
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+
+	return nil
+}
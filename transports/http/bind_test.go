@@ -0,0 +1,97 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+)
+
+type Paging struct {
+	Limit int `query:"limit"`
+}
+
+type bindParams struct {
+	Paging
+	ID      string        `path:"id"`
+	Tags    []string      `query:"tag"`
+	Active  *bool         `query:"active"`
+	Timeout time.Duration `query:"timeout"`
+	Tenant  string        `header:"X-Tenant,required"`
+}
+
+func TestBind(t *testing.T) {
+	active := true
+	tt := []struct {
+		name    string
+		target  string
+		headers map[string]string
+		want    bindParams
+		details []string
+	}{
+		{
+			name:    "all sources",
+			target:  "/users/42?limit=10&tag=a&tag=b&active=true&timeout=5s",
+			headers: map[string]string{"X-Tenant": "acme"},
+			want:    bindParams{Paging: Paging{Limit: 10}, ID: "42", Tags: []string{"a", "b"}, Active: &active, Timeout: 5 * time.Second, Tenant: "acme"},
+		},
+		{
+			name:    "invalid values",
+			target:  "/users/42?limit=ten&active=maybe",
+			details: []string{`"invalid query limit: expected an integer"`, `"invalid query active: expected a boolean"`, `"header X-Tenant is required"`},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var got bindParams
+			var err error
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				err = Bind(r, &got)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, v.target, nil)
+			for k, h := range v.headers {
+				req.Header.Set(k, h)
+			}
+			mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			if v.details != nil {
+				var ce sderrors.ClientError
+				if !errors.As(err, &ce) {
+					t.Fatalf("expected a ClientError but got %v", err)
+				}
+				if ce.Code() != http.StatusBadRequest || !reflect.DeepEqual(ce.Details, v.details) {
+					t.Errorf("expected 400 with %v but got %d with %v", v.details, ce.Code(), ce.Details)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %+v but got %+v", v.want, got)
+			}
+		})
+	}
+}
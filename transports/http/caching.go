@@ -0,0 +1,430 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/cache"
+)
+
+// CacheHeader reports how a response was served by StaleWhileRevalidate: HIT, STALE, or MISS
+const CacheHeader = "X-Cache"
+
+// CachePolicy describes how a route's responses may be cached by clients, shared caches, and CDNs
+type CachePolicy struct {
+	// MaxAge is how long a response is fresh
+	MaxAge time.Duration
+	// SharedMaxAge overrides MaxAge for shared caches
+	SharedMaxAge time.Duration
+	// StaleWhileRevalidate is how long after it expires a response may be served while it is refreshed
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long after it expires a response may be served when refreshing it fails
+	StaleIfError time.Duration
+	// Private keeps responses out of shared caches, such as responses for a signed in user
+	Private bool
+	// NoStore keeps responses out of every cache and overrides the other fields
+	NoStore bool
+	// Immutable tells clients a fresh response never changes, so they don't revalidate it on reload
+	Immutable bool
+	// SurrogateMaxAge sets Surrogate-Control so a CDN keeps responses longer than clients do. CDNs strip it.
+	SurrogateMaxAge time.Duration
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d.Seconds()))
+}
+
+// CacheControl returns the Cache-Control header value for the policy
+func (p CachePolicy) CacheControl() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	var d []string
+	if p.Private {
+		d = append(d, "private")
+	} else {
+		d = append(d, "public")
+	}
+	d = append(d, "max-age="+seconds(p.MaxAge))
+	if p.SharedMaxAge > 0 && !p.Private {
+		d = append(d, "s-maxage="+seconds(p.SharedMaxAge))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		d = append(d, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	}
+	if p.StaleIfError > 0 {
+		d = append(d, "stale-if-error="+seconds(p.StaleIfError))
+	}
+	if p.Immutable {
+		d = append(d, "immutable")
+	}
+
+	return strings.Join(d, ", ")
+}
+
+// SurrogateControl returns the Surrogate-Control header value for the policy, or an empty string if it doesn't
+// set one
+func (p CachePolicy) SurrogateControl() string {
+	if p.NoStore || p.Private || p.SurrogateMaxAge <= 0 {
+		return ""
+	}
+	return "max-age=" + seconds(p.SurrogateMaxAge)
+}
+
+// Apply sets the policy's headers unless the handler already set Cache-Control
+func (p CachePolicy) Apply(h http.Header) {
+	if h.Get("Cache-Control") != "" {
+		return
+	}
+	h.Set("Cache-Control", p.CacheControl())
+	if s := p.SurrogateControl(); s != "" {
+		h.Set("Surrogate-Control", s)
+	}
+}
+
+// ttl is how long the server side cache treats a response as fresh
+func (p CachePolicy) ttl() time.Duration {
+	if p.SharedMaxAge > 0 {
+		return p.SharedMaxAge
+	}
+	return p.MaxAge
+}
+
+// cacheableStatus reports whether a response with the status gets the policy's headers. Errors are left alone
+// so caches don't hold on to a failure.
+func cacheableStatus(code int) bool {
+	return code < http.StatusBadRequest || code == http.StatusNotFound || code == http.StatusGone
+}
+
+// policyWriter applies a cache policy to successful responses when the header is written
+type policyWriter struct {
+	http.ResponseWriter
+	policy  CachePolicy
+	written bool
+}
+
+func (w *policyWriter) WriteHeader(code int) {
+	if !w.written && cacheableStatus(code) {
+		w.policy.Apply(w.Header())
+	}
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *policyWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *policyWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CacheHeaders sets the policy's Cache-Control and Surrogate-Control headers on GET and HEAD responses that
+// aren't errors. Handlers setting Cache-Control themselves keep their value.
+func CacheHeaders(p CachePolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&policyWriter{ResponseWriter: w, policy: p}, r)
+		})
+	}
+}
+
+// cachedResponse is a response stored by StaleWhileRevalidate. Vary holds the request's values of the headers
+// named by the response's Vary header, which a request must match to be served the response.
+type cachedResponse struct {
+	Status int               `json:"status"`
+	Header http.Header       `json:"header"`
+	Body   []byte            `json:"body"`
+	Vary   map[string]string `json:"vary,omitempty"`
+}
+
+// bufferWriter holds a response so it can be stored before it is sent
+type bufferWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferWriter() *bufferWriter {
+	return &bufferWriter{header: make(http.Header)}
+}
+
+func (b *bufferWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferWriter) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferWriter) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *bufferWriter) response(r *http.Request) cachedResponse {
+	b.WriteHeader(http.StatusOK)
+	h := b.header.Clone()
+	h.Del("Set-Cookie")
+	resp := cachedResponse{Status: b.status, Header: h, Body: b.body.Bytes()}
+	for _, name := range varyHeaders(h) {
+		if resp.Vary == nil {
+			resp.Vary = make(map[string]string)
+		}
+		resp.Vary[name] = r.Header.Get(name)
+	}
+	return resp
+}
+
+// varyHeaders returns the canonical header names listed in the Vary header
+func varyHeaders(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// matches reports whether the request has the values the response was stored for
+func (c cachedResponse) matches(r *http.Request) bool {
+	for name, v := range c.Vary {
+		if r.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// SWROpt is a functional option to modify StaleWhileRevalidate
+type SWROpt func(*swr)
+
+type swr struct {
+	cache  cache.Cache
+	policy CachePolicy
+	key    func(*http.Request) string
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu         sync.Mutex
+	refreshing map[string]struct{}
+}
+
+// SetCacheKey sets the function returning the cache key of a request. Requests with an empty key skip the
+// cache. The default key is the request URI and the Accept and Accept-Language headers, and requests with an
+// Authorization header or cookies skip the cache so one user's response isn't served to another.
+func SetCacheKey(f func(*http.Request) string) SWROpt {
+	return func(s *swr) {
+		s.key = f
+	}
+}
+
+// SetCacheLogger sets the logger used to report failed cache reads, writes, and refreshes
+func SetCacheLogger(l *slog.Logger) SWROpt {
+	return func(s *swr) {
+		s.logger = l
+	}
+}
+
+func defaultCacheKey(r *http.Request) string {
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+		return ""
+	}
+	return r.URL.RequestURI() + " " + r.Header.Get("Accept") + " " + r.Header.Get("Accept-Language")
+}
+
+// StaleWhileRevalidate caches successful GET responses in c for expensive read endpoints. A fresh response is
+// served from the cache. Within the policy's stale-while-revalidate window a stale response is served right
+// away while one background request refreshes it, and within its stale-if-error window a stale response is
+// served when the handler fails with a server error. The policy's headers are set on every response. Private and
+// no-store responses aren't stored, and a stored response is only served to requests matching its Vary headers.
+func StaleWhileRevalidate(c cache.Cache, p CachePolicy, opts ...SWROpt) func(http.Handler) http.Handler {
+	return newSWR(c, p, opts...).middleware
+}
+
+func newSWR(c cache.Cache, p CachePolicy, opts ...SWROpt) *swr {
+	s := &swr{
+		cache:      c,
+		policy:     p,
+		key:        defaultCacheKey,
+		logger:     slog.Default(),
+		now:        time.Now,
+		refreshing: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *swr) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := ""
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			key = s.key(r)
+		}
+		if key == "" {
+			CacheHeaders(s.policy)(next).ServeHTTP(w, r)
+			return
+		}
+
+		cached, age, ok := s.get(r.Context(), key)
+		ok = ok && cached.matches(r)
+		ttl := s.policy.ttl()
+		switch {
+		case ok && age < ttl:
+			s.write(w, r, cached, age, "HIT")
+			return
+		case ok && age < ttl+s.policy.StaleWhileRevalidate:
+			s.write(w, r, cached, age, "STALE")
+			s.refresh(r, key, next)
+			return
+		}
+
+		rec := newBufferWriter()
+		next.ServeHTTP(rec, r)
+		resp := rec.response(r)
+		if resp.Status >= http.StatusInternalServerError && ok && age < ttl+s.policy.StaleIfError {
+			s.write(w, r, cached, age, "STALE")
+			return
+		}
+		// HEAD responses have no body to serve a later GET
+		if r.Method == http.MethodGet && s.storable(resp) {
+			s.set(r.Context(), key, resp)
+		}
+		s.write(w, r, resp, 0, "MISS")
+	})
+}
+
+// storable reports whether the response may be kept in the cache. Responses the policy or handler marks private
+// or no-store belong to one user, and Vary: * can't be matched.
+func (s *swr) storable(resp cachedResponse) bool {
+	if resp.Status != http.StatusOK || s.policy.Private || s.policy.NoStore {
+		return false
+	}
+	for _, d := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d == "private" || d == "no-store" {
+			return false
+		}
+	}
+	_, wildcard := resp.Vary["*"]
+	return !wildcard
+}
+
+func (s *swr) get(ctx context.Context, key string) (cachedResponse, time.Duration, bool) {
+	var resp cachedResponse
+	e, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			s.logger.Error(fmt.Sprintf("error reading cache: %v", err))
+		}
+		return resp, 0, false
+	}
+	if err := json.Unmarshal(e.Value, &resp); err != nil {
+		s.logger.Error(fmt.Sprintf("invalid cached response for %s: %v", key, err))
+		return resp, 0, false
+	}
+
+	return resp, e.Age(s.now()), true
+}
+
+func (s *swr) set(ctx context.Context, key string, resp cachedResponse) {
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = s.cache.Set(ctx, key, cache.Entry{Value: data, Stored: s.now()})
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error writing cache: %v", err))
+	}
+}
+
+// refresh runs the handler in the background to replace a stale response, once per key at a time. A panic in the
+// handler is logged, since there is no request left to fail.
+func (s *swr) refresh(r *http.Request, key string, next http.Handler) {
+	s.mu.Lock()
+	if _, ok := s.refreshing[key]; ok {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing[key] = struct{}{}
+	s.mu.Unlock()
+
+	req := r.Clone(context.WithoutCancel(r.Context()))
+	req.Method = http.MethodGet
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				s.logger.Error(fmt.Sprintf("refreshing %s panicked: %v", req.URL.RequestURI(), p))
+			}
+			s.mu.Lock()
+			delete(s.refreshing, key)
+			s.mu.Unlock()
+		}()
+
+		rec := newBufferWriter()
+		next.ServeHTTP(rec, req)
+		resp := rec.response(req)
+		if resp.Status != http.StatusOK {
+			s.logger.Error(fmt.Sprintf("refreshing %s returned %d", req.URL.RequestURI(), resp.Status))
+			return
+		}
+		if s.storable(resp) {
+			s.set(req.Context(), key, resp)
+		}
+	}()
+}
+
+func (s *swr) write(w http.ResponseWriter, r *http.Request, resp cachedResponse, age time.Duration, result string) {
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	if cacheableStatus(resp.Status) {
+		s.policy.Apply(w.Header())
+	}
+	w.Header().Set("Age", seconds(age))
+	w.Header().Set(CacheHeader, result)
+	w.WriteHeader(resp.Status)
+	if r.Method != http.MethodHead {
+		w.Write(resp.Body)
+	}
+}
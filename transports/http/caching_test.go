@@ -0,0 +1,273 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/cache"
+)
+
+func TestCachePolicy(t *testing.T) {
+	tt := []struct {
+		name      string
+		policy    CachePolicy
+		control   string
+		surrogate string
+	}{
+		{name: "public", policy: CachePolicy{MaxAge: time.Minute}, control: "public, max-age=60"},
+		{
+			name:      "shared",
+			policy:    CachePolicy{MaxAge: time.Minute, SharedMaxAge: 5 * time.Minute, StaleWhileRevalidate: 30 * time.Second, StaleIfError: time.Hour, SurrogateMaxAge: 24 * time.Hour},
+			control:   "public, max-age=60, s-maxage=300, stale-while-revalidate=30, stale-if-error=3600",
+			surrogate: "max-age=86400",
+		},
+		{name: "private", policy: CachePolicy{MaxAge: time.Minute, SharedMaxAge: time.Hour, Private: true, SurrogateMaxAge: time.Hour}, control: "private, max-age=60"},
+		{name: "immutable", policy: CachePolicy{MaxAge: 365 * 24 * time.Hour, Immutable: true}, control: "public, max-age=31536000, immutable"},
+		{name: "no store", policy: CachePolicy{MaxAge: time.Minute, NoStore: true}, control: "no-store"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if got := v.policy.CacheControl(); got != v.control {
+				t.Errorf("expected Cache-Control %q but got %q", v.control, got)
+			}
+			if got := v.policy.SurrogateControl(); got != v.surrogate {
+				t.Errorf("expected Surrogate-Control %q but got %q", v.surrogate, got)
+			}
+		})
+	}
+}
+
+func TestCacheHeaders(t *testing.T) {
+	status := http.StatusOK
+	route := Route{
+		Method: http.MethodGet,
+		Path:   "/posts",
+		Cache:  &CachePolicy{MaxAge: time.Minute},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}),
+	}
+
+	tt := []struct {
+		name    string
+		method  string
+		status  int
+		control string
+	}{
+		{name: "ok", method: http.MethodGet, status: http.StatusOK, control: "public, max-age=60"},
+		{name: "not found", method: http.MethodGet, status: http.StatusNotFound, control: "public, max-age=60"},
+		{name: "error", method: http.MethodGet, status: http.StatusInternalServerError},
+		{name: "post", method: http.MethodPost, status: http.StatusOK},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			status = v.status
+			rr := httptest.NewRecorder()
+			route.handler().ServeHTTP(rr, httptest.NewRequest(v.method, "/posts", nil))
+			if got := rr.Header().Get("Cache-Control"); got != v.control {
+				t.Errorf("expected Cache-Control %q but got %q", v.control, got)
+			}
+		})
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte{byte('0' + n)})
+	})
+
+	var clock atomic.Int64
+	clock.Store(time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC).UnixNano())
+	s := newSWR(cache.NewMemory(10), CachePolicy{MaxAge: time.Minute, StaleWhileRevalidate: time.Minute, StaleIfError: time.Hour})
+	s.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	mw := s.middleware(h)
+
+	get := func(header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/posts?page=1", nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		mw.ServeHTTP(rr, req)
+		return rr
+	}
+	advance := func(d time.Duration) { clock.Add(int64(d)) }
+
+	tt := []struct {
+		name    string
+		advance time.Duration
+		failing bool
+		header  []string
+		result  string
+		body    string
+		code    int
+	}{
+		{name: "miss", result: "MISS", body: "1", code: http.StatusOK},
+		{name: "hit", advance: 30 * time.Second, result: "HIT", body: "1", code: http.StatusOK},
+		{name: "stale", advance: 45 * time.Second, result: "STALE", body: "1", code: http.StatusOK},
+		{name: "refreshed", result: "HIT", body: "2", code: http.StatusOK},
+		{name: "authorized", header: []string{"Authorization", "Bearer token"}, body: "3", code: http.StatusOK},
+		{name: "stale if error", advance: 10 * time.Minute, failing: true, result: "STALE", body: "2", code: http.StatusOK},
+		{name: "error", advance: 2 * time.Hour, failing: true, result: "MISS", code: http.StatusBadGateway},
+	}
+
+	for _, v := range tt {
+		advance(v.advance)
+		failing.Store(v.failing)
+		rr := get(v.header...)
+		if v.name == "refreshed" {
+			// the stale request refreshed the entry in the background
+			deadline := time.Now().Add(time.Second)
+			for rr.Body.String() != v.body && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				rr = get()
+			}
+		}
+		if rr.Code != v.code || rr.Body.String() != v.body || rr.Header().Get(CacheHeader) != v.result {
+			t.Errorf("%s: expected %d %q %q but got %d %q %q", v.name, v.code, v.body, v.result, rr.Code, rr.Body.String(), rr.Header().Get(CacheHeader))
+		}
+		if v.code == http.StatusOK && rr.Header().Get("Cache-Control") != "public, max-age=60, stale-while-revalidate=60, stale-if-error=3600" {
+			t.Errorf("%s: expected the policy's Cache-Control but got %q", v.name, rr.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestStaleWhileRevalidateStore(t *testing.T) {
+	tt := []struct {
+		name   string
+		policy CachePolicy
+		header []string
+		req    []string
+		stored bool
+	}{
+		{name: "public", policy: CachePolicy{MaxAge: time.Minute}, stored: true},
+		{name: "private policy", policy: CachePolicy{MaxAge: time.Minute, Private: true}},
+		{name: "no store policy", policy: CachePolicy{MaxAge: time.Minute, NoStore: true}},
+		{name: "private response", policy: CachePolicy{MaxAge: time.Minute}, header: []string{"Cache-Control", "Private, max-age=60"}},
+		{name: "no store response", policy: CachePolicy{MaxAge: time.Minute}, header: []string{"Cache-Control", "no-store"}},
+		{name: "vary any", policy: CachePolicy{MaxAge: time.Minute}, header: []string{"Vary", "*"}},
+		{name: "vary matches", policy: CachePolicy{MaxAge: time.Minute}, header: []string{"Vary", "accept-encoding"}, stored: true},
+		{name: "vary differs", policy: CachePolicy{MaxAge: time.Minute}, header: []string{"Vary", "Accept-Encoding"}, req: []string{"Accept-Encoding", "br"}},
+		{name: "language differs", policy: CachePolicy{MaxAge: time.Minute}, req: []string{"Accept-Language", "es"}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < len(v.header); i += 2 {
+					w.Header().Set(v.header[i], v.header[i+1])
+				}
+				w.Write([]byte("ok"))
+			})
+			mw := StaleWhileRevalidate(cache.NewMemory(10), v.policy)(h)
+
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			mw.ServeHTTP(httptest.NewRecorder(), req)
+
+			req = httptest.NewRequest(http.MethodGet, "/posts", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			for i := 0; i < len(v.req); i += 2 {
+				req.Header.Set(v.req[i], v.req[i+1])
+			}
+			rr := httptest.NewRecorder()
+			mw.ServeHTTP(rr, req)
+			if stored := rr.Header().Get(CacheHeader) == "HIT"; stored != v.stored {
+				t.Errorf("expected stored to be %v but got %s", v.stored, rr.Header().Get(CacheHeader))
+			}
+		})
+	}
+}
+
+// syncBuffer guards a bytes.Buffer since the refresh logs from its own goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStaleWhileRevalidateRefreshPanic(t *testing.T) {
+	var calls atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			panic("refresh failed")
+		}
+		w.Write([]byte("ok"))
+	})
+
+	var logs syncBuffer
+	now := time.Now()
+	s := newSWR(cache.NewMemory(10), CachePolicy{MaxAge: time.Minute, StaleWhileRevalidate: time.Minute}, SetCacheLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	s.now = func() time.Time { return now }
+	mw := s.middleware(h)
+
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts", nil))
+	now = now.Add(90 * time.Second)
+	rr := httptest.NewRecorder()
+	mw.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	if rr.Header().Get(CacheHeader) != "STALE" {
+		t.Fatalf("expected a stale response but got %s", rr.Header().Get(CacheHeader))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(logs.String(), "refreshing /posts panicked: refresh failed") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "panicked") {
+		t.Fatalf("expected the panic to be logged but got %q", logs.String())
+	}
+
+	// the refresh is released so the next stale request tries again
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.refreshing)
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("expected the refresh to be released after the panic")
+}
@@ -14,9 +14,54 @@
 
 package http
 
+import (
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+)
+
 type ClientError interface {
 	Error() string
 	Body() []byte
 	Code() int
 	LoggedError() []error
 }
+
+// localizedClientError is implemented by client errors that can return their body in the caller's language
+type localizedClientError interface {
+	LocalizedBody(acceptLanguage string) []byte
+}
+
+// xmlClientError is implemented by client errors that can return their body as XML
+type xmlClientError interface {
+	XMLBody(acceptLanguage string) []byte
+}
+
+// errorCodecs are the formats client error bodies can be written in
+var errorCodecs = codec.NewRegistry(codec.JSON{}, codec.XML{})
+
+// writeClientError writes the client error in the format and language preferred by the request. XML is only
+// used when the client prefers it to JSON.
+func writeClientError(w http.ResponseWriter, r *http.Request, ce ClientError) {
+	if x, ok := ce.(xmlClientError); ok {
+		c, err := errorCodecs.Negotiate(r.Header.Get("Accept"))
+		if err == nil && c.ContentType() == (codec.XML{}).ContentType() {
+			w.Header().Set("Content-Type", c.ContentType())
+			w.WriteHeader(ce.Code())
+			w.Write(x.XMLBody(r.Header.Get("Accept-Language")))
+			return
+		}
+	}
+
+	w.WriteHeader(ce.Code())
+	w.Write(clientErrorBody(ce, r))
+}
+
+func clientErrorBody(ce ClientError, r *http.Request) []byte {
+
+	if l, ok := ce.(localizedClientError); ok {
+		return l.LocalizedBody(r.Header.Get("Accept-Language"))
+	}
+
+	return ce.Body()
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+)
+
+// CoalescedHeader is set on responses shared from an identical request that was already in flight
+const CoalescedHeader = "X-Coalesced"
+
+// CoalesceKey returns the key identical reads share: the method, path, query with sorted parameters, Accept and
+// Accept-Language headers, and principal
+func CoalesceKey(r *http.Request, principal string) string {
+	return strings.Join([]string{r.Method, r.URL.Path, r.URL.Query().Encode(), r.Header.Get("Accept"), r.Header.Get("Accept-Language"), principal}, "\n")
+}
+
+// Coalesce shares one handler call between identical GET and HEAD requests in flight at the same time, so a
+// thundering herd of reads costs the backend one request. Requests are identical when CoalesceKey matches, so
+// principal must return who is asking whenever responses differ by user. A nil principal treats requests with
+// an Authorization header or cookies as distinct.
+func Coalesce(principal PrincipalFunc) func(http.Handler) http.Handler {
+	var flight concurrency.Flight[cachedResponse]
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var p string
+			switch {
+			case principal != nil:
+				p = principal(r)
+			case r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "":
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resp, shared, err := flight.Do(r.Context(), CoalesceKey(r, p), func(ctx context.Context) (cachedResponse, error) {
+				rec := newBufferWriter()
+				next.ServeHTTP(rec, r.WithContext(ctx))
+				return rec.response(r), nil
+			})
+			if errors.Is(err, concurrency.ErrPanic) {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if err != nil {
+				// the caller went away
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			for k, v := range resp.Header {
+				w.Header()[k] = v
+			}
+			if shared {
+				w.Header().Set(CoalescedHeader, "true")
+			}
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+		})
+	}
+}
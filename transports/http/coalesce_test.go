@@ -0,0 +1,95 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	a := httptest.NewRequest(http.MethodGet, "/posts?page=2&sort=new", nil)
+	b := httptest.NewRequest(http.MethodGet, "/posts?sort=new&page=2", nil)
+	if CoalesceKey(a, "alice") != CoalesceKey(b, "alice") {
+		t.Error("expected the order of query parameters not to matter")
+	}
+	if CoalesceKey(a, "alice") == CoalesceKey(b, "bob") {
+		t.Error("expected principals not to share a key")
+	}
+	b.Header.Set("Accept-Language", "es")
+	if CoalesceKey(a, "alice") == CoalesceKey(b, "alice") {
+		t.Error("expected languages not to share a key")
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := Coalesce(func(r *http.Request) string { return r.Header.Get("User") })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("posts for " + r.Header.Get("User")))
+	}))
+
+	users := []string{"alice", "alice", "alice", "alice", "bob"}
+	recorders := make([]*httptest.ResponseRecorder, len(users))
+	var wg sync.WaitGroup
+	for i, u := range users {
+		recorders[i] = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+		req.Header.Set("User", u)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h.ServeHTTP(recorders[i], req)
+		}(i)
+	}
+
+	// give every request time to join the calls in flight
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected one call per principal but got %d", n)
+	}
+	coalesced := 0
+	for i, rr := range recorders {
+		if want := "posts for " + users[i]; rr.Body.String() != want || rr.Code != http.StatusOK {
+			t.Errorf("expected %q but got %d %q", want, rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get(CoalescedHeader) == "true" {
+			coalesced++
+		}
+	}
+	if coalesced != 3 {
+		t.Errorf("expected 3 coalesced responses but got %d", coalesced)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/posts", nil))
+	if n := calls.Load(); n != 3 || rr.Header().Get(CoalescedHeader) != "" {
+		t.Error("expected writes to skip coalescing")
+	}
+}
@@ -0,0 +1,471 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConnectCode is an error code from the Connect protocol. gRPC-Web responses send the matching numeric gRPC status.
+type ConnectCode string
+
+const (
+	CodeCanceled           ConnectCode = "canceled"
+	CodeUnknown            ConnectCode = "unknown"
+	CodeInvalidArgument    ConnectCode = "invalid_argument"
+	CodeDeadlineExceeded   ConnectCode = "deadline_exceeded"
+	CodeNotFound           ConnectCode = "not_found"
+	CodeAlreadyExists      ConnectCode = "already_exists"
+	CodePermissionDenied   ConnectCode = "permission_denied"
+	CodeResourceExhausted  ConnectCode = "resource_exhausted"
+	CodeFailedPrecondition ConnectCode = "failed_precondition"
+	CodeAborted            ConnectCode = "aborted"
+	CodeOutOfRange         ConnectCode = "out_of_range"
+	CodeUnimplemented      ConnectCode = "unimplemented"
+	CodeInternal           ConnectCode = "internal"
+	CodeUnavailable        ConnectCode = "unavailable"
+	CodeDataLoss           ConnectCode = "data_loss"
+	CodeUnauthenticated    ConnectCode = "unauthenticated"
+)
+
+// connectMaxBytes limits request messages, before and after decompression, to gRPC's default receive size
+const connectMaxBytes = 4 << 20
+
+var errConnectTooLarge = fmt.Errorf("request message exceeds %d bytes", connectMaxBytes)
+
+// connectCodes lists codes in gRPC status order, so a code's index is its gRPC status
+var connectCodes = []ConnectCode{"ok", CodeCanceled, CodeUnknown, CodeInvalidArgument, CodeDeadlineExceeded,
+	CodeNotFound, CodeAlreadyExists, CodePermissionDenied, CodeResourceExhausted, CodeFailedPrecondition,
+	CodeAborted, CodeOutOfRange, CodeUnimplemented, CodeInternal, CodeUnavailable, CodeDataLoss, CodeUnauthenticated}
+
+// connectStatus is the HTTP status the Connect protocol uses for each code
+var connectStatus = map[ConnectCode]int{
+	CodeCanceled:           499,
+	CodeUnknown:            http.StatusInternalServerError,
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeResourceExhausted:  http.StatusTooManyRequests,
+	CodeFailedPrecondition: http.StatusBadRequest,
+	CodeAborted:            http.StatusConflict,
+	CodeOutOfRange:         http.StatusBadRequest,
+	CodeUnimplemented:      http.StatusNotImplemented,
+	CodeInternal:           http.StatusInternalServerError,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeDataLoss:           http.StatusInternalServerError,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+}
+
+// ConnectError sets the code sent for an error returned by a Connect handler
+type ConnectError struct {
+	Code ConnectCode
+	Err  error
+}
+
+// NewConnectError returns an error sent to the client with the given code and the error's message
+func NewConnectError(code ConnectCode, err error) error {
+	return ConnectError{Code: code, Err: err}
+}
+
+func (c ConnectError) Error() string {
+	return c.Err.Error()
+}
+
+func (c ConnectError) Unwrap() error {
+	return c.Err
+}
+
+// ConnectCodeFromStatus maps the HTTP status of a ClientError to a Connect code
+func ConnectCodeFromStatus(status int) ConnectCode {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusPreconditionFailed:
+		return CodeFailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case 499:
+		return CodeCanceled
+	case http.StatusNotImplemented:
+		return CodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	}
+
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+type connectProtocol int
+
+const (
+	protocolConnect connectProtocol = iota
+	protocolGRPCWeb
+)
+
+type connectCodec struct {
+	unmarshal func([]byte, proto.Message) error
+	marshal   func(proto.Message) ([]byte, error)
+}
+
+var (
+	protoCodec = connectCodec{unmarshal: proto.Unmarshal, marshal: proto.Marshal}
+	jsonCodec  = connectCodec{
+		unmarshal: protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal,
+		marshal:   protojson.Marshal,
+	}
+)
+
+// connectContentTypes are the request content types a Connect route accepts and how each is handled
+var connectContentTypes = map[string]struct {
+	protocol connectProtocol
+	codec    connectCodec
+}{
+	"application/proto":          {protocolConnect, protoCodec},
+	"application/json":           {protocolConnect, jsonCodec},
+	"application/grpc-web":       {protocolGRPCWeb, protoCodec},
+	"application/grpc-web+proto": {protocolGRPCWeb, protoCodec},
+	"application/grpc-web+json":  {protocolGRPCWeb, jsonCodec},
+}
+
+// ConnectRoute serves a unary procedure over the Connect protocol and gRPC-Web, in both binary protobuf and
+// JSON. Register the routes for a service under its fully qualified name so the paths match generated clients:
+//
+//	s.RegisterSubRouter("/orders.v1.Orders", []sdhttp.Route{sdhttp.ConnectRoute("Create", h.Create)})
+//
+// Errors implementing ClientError are sent with the Connect code for their status, ConnectError sets the code
+// directly, and any other error is logged and sent as internal.
+func ConnectRoute[Req, Res proto.Message](procedure string, fn func(context.Context, Req) (Res, error)) Route {
+	return Route{
+		Method:       http.MethodPost,
+		Path:         "/" + procedure,
+		Handler:      connectHandler(fn),
+		MaxBodyBytes: connectMaxBytes,
+	}
+}
+
+func connectHandler[Req, Res proto.Message](fn func(context.Context, Req) (Res, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+		ct, ok := connectContentTypes[strings.TrimSpace(contentType)]
+		if !ok {
+			w.Header().Set("Accept-Post", "application/proto, application/json, application/grpc-web+proto, application/grpc-web+json")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		ctx, cancel, err := connectDeadline(r, ct.protocol)
+		if err != nil {
+			writeConnectError(w, r, ct.protocol, contentType, err)
+			return
+		}
+		defer cancel()
+
+		var zero Req
+		req := zero.ProtoReflect().Type().New().Interface().(Req)
+		if err := readConnectRequest(r, ct.protocol, ct.codec, req); err != nil {
+			writeConnectError(w, r, ct.protocol, contentType, err)
+			return
+		}
+
+		res, err := fn(ctx, req)
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			writeConnectError(w, r, ct.protocol, contentType, err)
+			return
+		}
+
+		data, err := ct.codec.marshal(res)
+		if err != nil {
+			writeConnectError(w, r, ct.protocol, contentType, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if ct.protocol == protocolConnect {
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(grpcWebFrame(0, data))
+		w.Write(grpcWebTrailer(0, ""))
+	}
+}
+
+// connectDeadline applies the Connect-Timeout-Ms or grpc-timeout header to the request context
+func connectDeadline(r *http.Request, protocol connectProtocol) (context.Context, context.CancelFunc, error) {
+	var timeout time.Duration
+	switch protocol {
+	case protocolConnect:
+		if v := r.Header.Get("Connect-Timeout-Ms"); v != "" {
+			ms, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || ms < 0 || len(v) > 10 {
+				return nil, nil, NewConnectError(CodeInvalidArgument, fmt.Errorf("invalid Connect-Timeout-Ms %q", v))
+			}
+			timeout = time.Duration(ms) * time.Millisecond
+		}
+	case protocolGRPCWeb:
+		if v := r.Header.Get("Grpc-Timeout"); v != "" {
+			d, err := parseGRPCTimeout(v)
+			if err != nil {
+				return nil, nil, NewConnectError(CodeInvalidArgument, err)
+			}
+			timeout = d
+		}
+	}
+
+	if timeout == 0 {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
+
+// parseGRPCTimeout parses a grpc-timeout header, which is up to eight digits followed by a unit
+func parseGRPCTimeout(v string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	if len(v) < 2 || len(v) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", v)
+	}
+
+	unit, ok := units[v[len(v)-1]]
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", v)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+func readConnectRequest(r *http.Request, protocol connectProtocol, codec connectCodec, msg proto.Message) error {
+	body := io.LimitReader(r.Body, connectMaxBytes+1)
+	encoding := r.Header.Get("Content-Encoding")
+	if protocol == protocolGRPCWeb {
+		encoding = r.Header.Get("Grpc-Encoding")
+	}
+
+	switch encoding {
+	case "", "identity":
+	case "gzip":
+		if protocol == protocolConnect {
+			zr, err := gzip.NewReader(body)
+			if err != nil {
+				return NewConnectError(CodeInvalidArgument, err)
+			}
+			defer zr.Close()
+			body = zr
+		}
+	default:
+		return NewConnectError(CodeUnimplemented, fmt.Errorf("unsupported encoding %q", encoding))
+	}
+
+	data, err := readConnectLimited(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) || errors.Is(err, errConnectTooLarge) {
+			return NewConnectError(CodeResourceExhausted, err)
+		}
+		return NewConnectError(CodeInvalidArgument, err)
+	}
+
+	if protocol == protocolGRPCWeb {
+		data, err = readGRPCWebFrame(data, encoding == "gzip")
+		if errors.Is(err, errConnectTooLarge) {
+			return NewConnectError(CodeResourceExhausted, err)
+		}
+		if err != nil {
+			return NewConnectError(CodeInvalidArgument, err)
+		}
+	}
+
+	if err := codec.unmarshal(data, msg); err != nil {
+		return NewConnectError(CodeInvalidArgument, fmt.Errorf("decoding request: %w", err))
+	}
+
+	return nil
+}
+
+// readGRPCWebFrame returns the message from a unary gRPC-Web request body
+func readGRPCWebFrame(data []byte, gzipped bool) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, fmt.Errorf("gRPC-Web request is missing its frame header")
+	}
+
+	flags := data[0]
+	size := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) != size {
+		return nil, fmt.Errorf("gRPC-Web frame is %d bytes but the header says %d", len(data)-5, size)
+	}
+
+	msg := data[5:]
+	if flags&1 == 0 {
+		return msg, nil
+	}
+	if !gzipped {
+		return nil, fmt.Errorf("gRPC-Web frame is compressed without a grpc-encoding")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readConnectLimited(zr)
+}
+
+// readConnectLimited reads at most connectMaxBytes so a small compressed body cannot expand without bound
+func readConnectLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, connectMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > connectMaxBytes {
+		return nil, errConnectTooLarge
+	}
+
+	return data, nil
+}
+
+func grpcWebFrame(flags byte, data []byte) []byte {
+	frame := make([]byte, 5, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+// grpcWebTrailer encodes the status as a trailer frame
+func grpcWebTrailer(status int, message string) []byte {
+	trailer := fmt.Sprintf("grpc-status: %d\r\n", status)
+	if message != "" {
+		trailer += fmt.Sprintf("grpc-message: %s\r\n", grpcPercentEncode(message))
+	}
+	return grpcWebFrame(0x80, []byte(trailer))
+}
+
+// grpcPercentEncode encodes a grpc-message value, escaping everything outside printable ASCII and %
+func grpcPercentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// connectCode returns the code and client facing message for a handler error
+func connectCode(err error) (ConnectCode, string, bool) {
+	var connectErr ConnectError
+	if errors.As(err, &connectErr) {
+		return connectErr.Code, connectErr.Error(), true
+	}
+
+	var ce ClientError
+	if errors.As(err, &ce) {
+		message := ce.Error()
+		if m, ok := ce.(interface{ Messages() []string }); ok {
+			message = strings.Join(m.Messages(), ", ")
+		}
+		return ConnectCodeFromStatus(ce.Code()), message, true
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded, err.Error(), true
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled, err.Error(), true
+	}
+
+	return CodeInternal, ErrInternalError.Error(), false
+}
+
+func writeConnectError(w http.ResponseWriter, r *http.Request, protocol connectProtocol, contentType string, err error) {
+	code, message, expected := connectCode(err)
+	if !expected {
+		LoggerFrom(r.Context()).Error(fmt.Sprintf("code=%s, err=%v", code, err))
+	}
+
+	if protocol == protocolGRPCWeb {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(grpcWebTrailer(connectGRPCStatus(code), message))
+		return
+	}
+
+	body, _ := json.Marshal(struct {
+		Code    ConnectCode `json:"code"`
+		Message string      `json:"message,omitempty"`
+	}{code, message})
+
+	status, ok := connectStatus[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func connectGRPCStatus(code ConnectCode) int {
+	for i, v := range connectCodes {
+		if v == code {
+			return i
+		}
+	}
+	return 2
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func greet(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch req.Value {
+	case "":
+		return nil, sderrors.NewClientError(fmt.Errorf("name is required"), http.StatusBadRequest)
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "denied":
+		return nil, NewConnectError(CodePermissionDenied, fmt.Errorf("not allowed"))
+	case "boom":
+		return nil, fmt.Errorf("database password is hunter2")
+	}
+	return wrapperspb.String("hello " + req.Value), nil
+}
+
+func TestConnectRoute(t *testing.T) {
+	route := ConnectRoute("Greet", greet)
+	if route.Method != http.MethodPost || route.Path != "/Greet" {
+		t.Fatalf("unexpected route %s %s", route.Method, route.Path)
+	}
+
+	protoBody := func(v string) []byte {
+		data, _ := proto.Marshal(wrapperspb.String(v))
+		return data
+	}
+	gzipBody := func(data []byte) []byte {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write(data)
+		zw.Close()
+		return b.Bytes()
+	}
+	gzipped := map[string]string{"Content-Encoding": "gzip"}
+
+	tt := []struct {
+		name        string
+		contentType string
+		headers     map[string]string
+		body        []byte
+		wantStatus  int
+		wantBody    string
+		wantValue   string
+	}{
+		{name: "json", contentType: "application/json", body: []byte(`"ada"`), wantStatus: 200, wantBody: `"hello ada"`},
+		{name: "json charset", contentType: "application/json; charset=utf-8", body: []byte(`"ada"`), wantStatus: 200, wantBody: `"hello ada"`},
+		{name: "proto", contentType: "application/proto", body: protoBody("ada"), wantStatus: 200, wantValue: "hello ada"},
+		{name: "client error", contentType: "application/json", body: []byte(`""`), wantStatus: 400, wantBody: `{"code":"invalid_argument","message":"name is required"}`},
+		{name: "connect error", contentType: "application/json", body: []byte(`"denied"`), wantStatus: 403, wantBody: `{"code":"permission_denied","message":"not allowed"}`},
+		{name: "internal", contentType: "application/json", body: []byte(`"boom"`), wantStatus: 500, wantBody: `{"code":"internal","message":"internal server error"}`},
+		{name: "bad body", contentType: "application/json", body: []byte(`{`), wantStatus: 400},
+		{name: "timeout", contentType: "application/json", headers: map[string]string{"Connect-Timeout-Ms": "10"}, body: []byte(`"slow"`), wantStatus: 504},
+		{name: "bad timeout", contentType: "application/json", headers: map[string]string{"Connect-Timeout-Ms": "soon"}, body: []byte(`"ada"`), wantStatus: 400},
+		{name: "unsupported", contentType: "text/plain", body: []byte("ada"), wantStatus: 415},
+		{name: "gzip", contentType: "application/json", headers: gzipped, body: gzipBody([]byte(`"ada"`)), wantStatus: 200, wantBody: `"hello ada"`},
+		{name: "decompression bomb", contentType: "application/proto", headers: gzipped, body: gzipBody(make([]byte, connectMaxBytes+1)), wantStatus: 429, wantBody: `{"code":"resource_exhausted","message":"request message exceeds 4194304 bytes"}`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/Greet", bytes.NewReader(v.body))
+			r.Header.Set("Content-Type", v.contentType)
+			for k, h := range v.headers {
+				r.Header.Set(k, h)
+			}
+			w := httptest.NewRecorder()
+			route.Handler.ServeHTTP(w, r)
+
+			if w.Code != v.wantStatus {
+				t.Fatalf("expected status %d but got %d: %s", v.wantStatus, w.Code, w.Body.String())
+			}
+			if v.wantBody != "" && strings.ReplaceAll(w.Body.String(), " ", "") != strings.ReplaceAll(v.wantBody, " ", "") {
+				t.Errorf("expected body %s but got %s", v.wantBody, w.Body.String())
+			}
+			if v.wantValue != "" {
+				var got wrapperspb.StringValue
+				if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Value != v.wantValue {
+					t.Errorf("expected %q but got %q", v.wantValue, got.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestConnectGRPCWeb(t *testing.T) {
+	route := ConnectRoute("Greet", greet)
+
+	tt := []struct {
+		name        string
+		value       string
+		timeout     string
+		wantMessage string
+		wantTrailer string
+	}{
+		{name: "ok", value: "ada", wantMessage: "hello ada", wantTrailer: "grpc-status: 0\r\n"},
+		{name: "client error", value: "", wantTrailer: "grpc-status: 3\r\ngrpc-message: name is required\r\n"},
+		{name: "deadline", value: "slow", timeout: "10m", wantTrailer: "grpc-status: 4\r\ngrpc-message: context deadline exceeded\r\n"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			data, _ := proto.Marshal(wrapperspb.String(v.value))
+			r := httptest.NewRequest(http.MethodPost, "/Greet", bytes.NewReader(grpcWebFrame(0, data)))
+			r.Header.Set("Content-Type", "application/grpc-web+proto")
+			if v.timeout != "" {
+				r.Header.Set("Grpc-Timeout", v.timeout)
+			}
+			w := httptest.NewRecorder()
+			start := time.Now()
+			route.Handler.ServeHTTP(w, r)
+
+			if time.Since(start) > time.Second {
+				t.Error("expected the grpc-timeout to cancel the handler")
+			}
+			if w.Code != 200 || w.Header().Get("Content-Type") != "application/grpc-web+proto" {
+				t.Fatalf("unexpected response %d %s", w.Code, w.Header().Get("Content-Type"))
+			}
+
+			body, _ := io.ReadAll(w.Body)
+			var message, trailer string
+			for len(body) >= 5 {
+				size := int(body[1])<<24 | int(body[2])<<16 | int(body[3])<<8 | int(body[4])
+				frame := body[5 : 5+size]
+				if body[0]&0x80 != 0 {
+					trailer = string(frame)
+				} else {
+					var got wrapperspb.StringValue
+					if err := proto.Unmarshal(frame, &got); err != nil {
+						t.Fatal(err)
+					}
+					message = got.Value
+				}
+				body = body[5+size:]
+			}
+
+			if message != v.wantMessage {
+				t.Errorf("expected message %q but got %q", v.wantMessage, message)
+			}
+			if trailer != v.wantTrailer {
+				t.Errorf("expected trailer %q but got %q", v.wantTrailer, trailer)
+			}
+		})
+	}
+}
+
+func TestGRPCPercentEncode(t *testing.T) {
+	if got := grpcPercentEncode("100% café\n"); got != "100%25 caf%C3%A9%0A" {
+		t.Errorf("unexpected encoding %q", got)
+	}
+}
@@ -0,0 +1,491 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+// TusVersion is the version of the tus resumable upload protocol implemented by Uploads
+const TusVersion = "1.0.0"
+
+var ErrUploadNotFound = fmt.Errorf("upload not found")
+
+// ObjectStore is the subset of nats.ObjectStore used by the file helpers
+type ObjectStore interface {
+	Get(name string, opts ...nats.GetObjectOpt) (nats.ObjectResult, error)
+	GetInfo(name string, opts ...nats.GetObjectInfoOpt) (*nats.ObjectInfo, error)
+	Put(obj *nats.ObjectMeta, reader io.Reader, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error)
+	PutBytes(name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error)
+	GetBytes(name string, opts ...nats.GetObjectOpt) ([]byte, error)
+	Delete(name string) error
+}
+
+// Download returns a handler that serves the object named by the path value param. Range and conditional
+// requests are supported, so interrupted downloads can resume.
+func Download(store ObjectStore, param string) http.Handler {
+	return &ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		name := r.PathValue(param)
+		info, err := store.GetInfo(name)
+		if errors.Is(err, nats.ErrObjectNotFound) {
+			return sderrors.NewClientError(fmt.Errorf("file %s not found", name), http.StatusNotFound)
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.Digest != "" {
+			w.Header().Set("ETag", strconv.Quote(info.Digest))
+		}
+		if ct := info.Headers.Get("Content-Type"); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		if cd := info.Headers.Get("Content-Disposition"); cd != "" {
+			w.Header().Set("Content-Disposition", cd)
+		}
+
+		rs := &objectSeeker{store: store, name: name, size: int64(info.Size)}
+		defer rs.Close()
+
+		http.ServeContent(w, r, name, info.ModTime, rs)
+		return nil
+	}}
+}
+
+// objectSeeker lets http.ServeContent seek within an object by reopening it and skipping to the offset
+type objectSeeker struct {
+	store  ObjectStore
+	name   string
+	size   int64
+	offset int64
+	r      io.ReadCloser
+}
+
+func (o *objectSeeker) Read(p []byte) (int, error) {
+	if o.r == nil {
+		res, err := o.store.Get(o.name)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.CopyN(io.Discard, res, o.offset); err != nil {
+			res.Close()
+			return 0, err
+		}
+		o.r = res
+	}
+
+	n, err := o.r.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *objectSeeker) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = o.offset + offset
+	case io.SeekEnd:
+		next = o.size + offset
+	}
+
+	if next < 0 {
+		return 0, fmt.Errorf("invalid seek to %d", next)
+	}
+
+	if next != o.offset {
+		o.Close()
+		o.offset = next
+	}
+
+	return next, nil
+}
+
+func (o *objectSeeker) Close() error {
+	if o.r == nil {
+		return nil
+	}
+
+	err := o.r.Close()
+	o.r = nil
+	return err
+}
+
+// UploadProgress is published to NATS after each chunk of an upload is stored. The completed object is named
+// after ID, and Name is the filename the client sent.
+type UploadProgress struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Offset   int64  `json:"offset"`
+	Length   int64  `json:"length"`
+	Complete bool   `json:"complete"`
+}
+
+// uploadState is stored next to the chunks of an upload in progress. Once the upload completes its chunks are
+// gone and Completed records when, so HEAD keeps reporting the full offset for a while.
+type uploadState struct {
+	Name      string     `json:"name"`
+	Length    int64      `json:"length"`
+	Offset    int64      `json:"offset"`
+	Chunks    []int64    `json:"chunks"`
+	Completed *time.Time `json:"completed,omitempty"`
+}
+
+// UploadsOpt is a functional option to modify Uploads
+type UploadsOpt func(*Uploads)
+
+// Uploads implements the core of the tus resumable upload protocol on top of an ObjectStore. Each PATCH is
+// stored as a chunk and the chunks are joined into the final object once every byte has arrived. The final
+// object is named after the upload id so clients can't overwrite other objects. The client's filename is kept
+// in the object's metadata.
+//
+// Concurrent PATCHes of an upload are rejected by a lock held in this process only. When several instances
+// serve uploads, route an upload's requests to one instance, for example by its id.
+type Uploads struct {
+	store        ObjectStore
+	basePath     string
+	maxSize      int64
+	completedTTL time.Duration
+	nc           *nats.Conn
+	subject      string
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu      sync.Mutex
+	patches map[string]bool
+}
+
+// NewUploads returns Uploads storing files in store. basePath is the path the upload routes are mounted on
+// and is used to build the Location of new uploads.
+func NewUploads(store ObjectStore, basePath string, opts ...UploadsOpt) *Uploads {
+	u := &Uploads{
+		store:        store,
+		basePath:     strings.TrimSuffix(basePath, "/"),
+		maxSize:      1 << 30,
+		completedTTL: 24 * time.Hour,
+		logger:       slog.Default(),
+		now:          time.Now,
+		patches:      map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
+
+// SetMaxUploadSize sets the largest Upload-Length accepted. The default is 1GiB.
+func SetMaxUploadSize(n int64) UploadsOpt {
+	return func(u *Uploads) {
+		u.maxSize = n
+	}
+}
+
+// SetCompletedUploadTTL sets how long HEAD reports a completed upload as done, so a client that lost the response
+// to its last PATCH can tell the upload finished. After that the upload's state is removed the next time it is
+// requested. The default is 24 hours.
+func SetCompletedUploadTTL(d time.Duration) UploadsOpt {
+	return func(u *Uploads) {
+		u.completedTTL = d
+	}
+}
+
+// SetUploadProgress publishes an UploadProgress message to "<subject>.<upload id>" after every chunk
+func SetUploadProgress(nc *nats.Conn, subject string) UploadsOpt {
+	return func(u *Uploads) {
+		u.nc = nc
+		u.subject = subject
+	}
+}
+
+// SetUploadsLogger sets the logger used for errors that can't be returned to the client
+func SetUploadsLogger(l *slog.Logger) UploadsOpt {
+	return func(u *Uploads) {
+		u.logger = l
+	}
+}
+
+// Routes returns the routes for creating uploads, checking their offset, and sending data
+func (u *Uploads) Routes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/", Handler: &ErrHandler{Handler: u.create}},
+		{Method: http.MethodHead, Path: "/{id}", Handler: &ErrHandler{Handler: u.head}},
+		{Method: http.MethodPatch, Path: "/{id}", Handler: &ErrHandler{Handler: u.patch}},
+	}
+}
+
+// uploadID matches the ids nuid generates
+var uploadID = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// FilenameMetadata is the object metadata key holding the filename a client sent with an upload
+const FilenameMetadata = "filename"
+
+func stateName(id string) string {
+	return fmt.Sprintf(".uploads/%s", id)
+}
+
+func chunkName(id string, offset int64) string {
+	return fmt.Sprintf(".uploads/%s/%d", id, offset)
+}
+
+func (u *Uploads) create(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Resumable", TusVersion)
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return sderrors.NewClientError(fmt.Errorf("invalid Upload-Length"), http.StatusBadRequest)
+	}
+	if length > u.maxSize {
+		return sderrors.NewClientError(fmt.Errorf("upload exceeds %d bytes", u.maxSize), http.StatusRequestEntityTooLarge)
+	}
+
+	id := nuid.Next()
+	state := uploadState{
+		Name:   uploadFilename(r.Header.Get("Upload-Metadata")),
+		Length: length,
+	}
+	if err := u.saveState(id, state); err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", u.basePath, id))
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// lock marks a PATCH of the upload in progress. It returns false when another PATCH already is.
+func (u *Uploads) lock(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.patches[id] {
+		return false
+	}
+	u.patches[id] = true
+	return true
+}
+
+func (u *Uploads) unlock(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.patches, id)
+}
+
+func (u *Uploads) head(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Resumable", TusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	state, err := u.loadState(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(state.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(state.Length, 10))
+	return nil
+}
+
+func (u *Uploads) patch(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Resumable", TusVersion)
+	id := r.PathValue("id")
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		return sderrors.NewClientError(fmt.Errorf("Content-Type must be application/offset+octet-stream"), http.StatusUnsupportedMediaType)
+	}
+
+	if !u.lock(id) {
+		return sderrors.NewClientError(fmt.Errorf("upload %s is already being written", id), http.StatusConflict)
+	}
+	defer u.unlock(id)
+
+	state, err := u.loadState(id)
+	if err != nil {
+		return err
+	}
+	if state.Completed != nil {
+		return sderrors.NewClientError(fmt.Errorf("upload %s is already complete", id), http.StatusConflict)
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset != state.Offset {
+		return sderrors.NewClientError(fmt.Errorf("Upload-Offset must be %d", state.Offset), http.StatusConflict)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, state.Length-state.Offset)
+	info, err := u.store.Put(&nats.ObjectMeta{Name: chunkName(id, offset)}, body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return sderrors.NewClientError(fmt.Errorf("upload exceeds Upload-Length"), http.StatusRequestEntityTooLarge)
+		}
+		return err
+	}
+
+	if info.Size > 0 {
+		state.Chunks = append(state.Chunks, offset)
+		state.Offset += int64(info.Size)
+	}
+
+	if state.Offset == state.Length {
+		if err := u.complete(id, state); err != nil {
+			return err
+		}
+	} else if err := u.saveState(id, state); err != nil {
+		return err
+	}
+
+	u.publish(UploadProgress{ID: id, Name: state.Name, Offset: state.Offset, Length: state.Length, Complete: state.Offset == state.Length})
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(state.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// complete joins the chunks into the final object, removes them, and marks the upload state completed
+func (u *Uploads) complete(id string, state uploadState) error {
+	readers := make([]io.Reader, len(state.Chunks))
+	for i, offset := range state.Chunks {
+		readers[i] = &lazyObject{store: u.store, name: chunkName(id, offset)}
+	}
+
+	meta := &nats.ObjectMeta{Name: id}
+	if state.Name != "" {
+		meta.Metadata = map[string]string{FilenameMetadata: state.Name}
+		meta.Headers = nats.Header{"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": state.Name})}}
+	}
+	if _, err := u.store.Put(meta, io.MultiReader(readers...)); err != nil {
+		return err
+	}
+
+	for _, offset := range state.Chunks {
+		if err := u.store.Delete(chunkName(id, offset)); err != nil {
+			u.logger.Error(fmt.Sprintf("error deleting upload chunk: %v", err))
+		}
+	}
+
+	now := u.now()
+	state.Chunks = nil
+	state.Completed = &now
+	return u.saveState(id, state)
+}
+
+func (u *Uploads) loadState(id string) (uploadState, error) {
+	var state uploadState
+	if !uploadID.MatchString(id) {
+		return state, sderrors.NewClientError(ErrUploadNotFound, http.StatusNotFound)
+	}
+	data, err := u.store.GetBytes(stateName(id))
+	if errors.Is(err, nats.ErrObjectNotFound) {
+		return state, sderrors.NewClientError(ErrUploadNotFound, http.StatusNotFound)
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+
+	if state.Completed != nil && u.now().Sub(*state.Completed) >= u.completedTTL {
+		if err := u.store.Delete(stateName(id)); err != nil {
+			u.logger.Error(fmt.Sprintf("error deleting completed upload state: %v", err))
+		}
+		return state, sderrors.NewClientError(ErrUploadNotFound, http.StatusNotFound)
+	}
+
+	return state, nil
+}
+
+func (u *Uploads) saveState(id string, state uploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.store.PutBytes(stateName(id), data)
+	return err
+}
+
+func (u *Uploads) publish(p UploadProgress) {
+	if u.nc == nil {
+		return
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+
+	if err := u.nc.Publish(fmt.Sprintf("%s.%s", u.subject, p.ID), data); err != nil {
+		u.logger.Error(fmt.Sprintf("error publishing upload progress: %v", err))
+	}
+}
+
+// uploadFilename returns the filename from a tus Upload-Metadata header
+func uploadFilename(metadata string) string {
+	for _, pair := range strings.Split(metadata, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key != "filename" {
+			continue
+		}
+
+		name, err := base64.StdEncoding.DecodeString(value)
+		if err == nil && len(name) > 0 {
+			return string(name)
+		}
+	}
+
+	return ""
+}
+
+// lazyObject opens an object on first read so joining chunks doesn't hold them all open at once
+type lazyObject struct {
+	store ObjectStore
+	name  string
+	r     io.ReadCloser
+}
+
+func (l *lazyObject) Read(p []byte) (int, error) {
+	if l.r == nil {
+		res, err := l.store.Get(l.name)
+		if err != nil {
+			return 0, err
+		}
+		l.r = res
+	}
+
+	n, err := l.r.Read(p)
+	if err == io.EOF {
+		l.r.Close()
+	}
+
+	return n, err
+}
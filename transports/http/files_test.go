@@ -0,0 +1,266 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type memoryObject struct {
+	*bytes.Reader
+	info *nats.ObjectInfo
+}
+
+func (m memoryObject) Close() error                    { return nil }
+func (m memoryObject) Info() (*nats.ObjectInfo, error) { return m.info, nil }
+func (m memoryObject) Error() error                    { return nil }
+
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (m *memoryStore) info(name string) (*nats.ObjectInfo, error) {
+	data, ok := m.objects[name]
+	if !ok {
+		return nil, nats.ErrObjectNotFound
+	}
+
+	return &nats.ObjectInfo{ObjectMeta: nats.ObjectMeta{Name: name}, Size: uint64(len(data)), ModTime: time.Unix(0, 0), Digest: "SHA-256=test"}, nil
+}
+
+func (m *memoryStore) Get(name string, opts ...nats.GetObjectOpt) (nats.ObjectResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, err := m.info(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return memoryObject{Reader: bytes.NewReader(m.objects[name]), info: info}, nil
+}
+
+func (m *memoryStore) GetInfo(name string, opts ...nats.GetObjectInfoOpt) (*nats.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.info(name)
+}
+
+func (m *memoryStore) Put(obj *nats.ObjectMeta, reader io.Reader, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return m.PutBytes(obj.Name, data)
+}
+
+func (m *memoryStore) PutBytes(name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[name] = data
+	return m.info(name)
+}
+
+func (m *memoryStore) GetBytes(name string, opts ...nats.GetObjectOpt) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[name]
+	if !ok {
+		return nil, nats.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, name)
+	return nil
+}
+
+func TestDownload(t *testing.T) {
+	store := newMemoryStore()
+	store.PutBytes("report.txt", []byte("hello sencillo"))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /files/{name}", Download(store, "name"))
+
+	tt := []struct {
+		name string
+		file string
+		rng  string
+		code int
+		want string
+	}{
+		{name: "full", file: "report.txt", code: http.StatusOK, want: "hello sencillo"},
+		{name: "range", file: "report.txt", rng: "bytes=6-", code: http.StatusPartialContent, want: "sencillo"},
+		{name: "missing", file: "nope.txt", code: http.StatusNotFound},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/files/"+v.file, nil)
+			if v.rng != "" {
+				req.Header.Set("Range", v.rng)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != v.code {
+				t.Fatalf("expected status %d but got %d", v.code, w.Code)
+			}
+			if v.want != "" && w.Body.String() != v.want {
+				t.Errorf("expected %q but got %q", v.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUploads(t *testing.T) {
+	store := newMemoryStore()
+	u := NewUploads(store, "/uploads")
+	mux := http.NewServeMux()
+	for _, r := range u.Routes() {
+		mux.Handle(r.Method+" /uploads"+r.Path, r.Handler)
+	}
+
+	do := func(method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/uploads/", "", map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("notes.txt")),
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d but got %d", http.StatusCreated, w.Code)
+	}
+	location := w.Header().Get("Location")
+
+	chunk := map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": "0"}
+	if w := do(http.MethodPatch, location, "hello ", chunk); w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("expected offset 6 but got %d %s", w.Code, w.Header().Get("Upload-Offset"))
+	}
+
+	if w := do(http.MethodPatch, location, "world", chunk); w.Code != http.StatusConflict {
+		t.Errorf("expected %d for a stale offset but got %d", http.StatusConflict, w.Code)
+	}
+
+	if w := do(http.MethodHead, location, "", nil); w.Header().Get("Upload-Offset") != "6" {
+		t.Errorf("expected offset 6 but got %s", w.Header().Get("Upload-Offset"))
+	}
+
+	chunk["Upload-Offset"] = "6"
+	if w := do(http.MethodPatch, location, "world", chunk); w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d but got %d", http.StatusNoContent, w.Code)
+	}
+
+	id := strings.TrimPrefix(location, "/uploads/")
+	data, err := store.GetBytes(id)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("expected completed upload named after its id but got %q, %v", data, err)
+	}
+	if len(store.objects) != 2 {
+		t.Errorf("expected chunks to be removed but found %d objects", len(store.objects))
+	}
+
+	// a client that lost the last response learns the upload is done
+	if w := do(http.MethodHead, location, "", nil); w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "11" || w.Header().Get("Upload-Length") != "11" {
+		t.Errorf("expected the completed offset but got %d %s", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	chunk["Upload-Offset"] = "11"
+	if w := do(http.MethodPatch, location, "", chunk); w.Code != http.StatusConflict {
+		t.Errorf("expected %d for a completed upload but got %d", http.StatusConflict, w.Code)
+	}
+
+	u.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	if w := do(http.MethodHead, location, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected %d after the completed state expires but got %d", http.StatusNotFound, w.Code)
+	}
+	if _, err := store.GetBytes(stateName(id)); !errors.Is(err, nats.ErrObjectNotFound) {
+		t.Errorf("expected the expired state to be removed but got %v", err)
+	}
+}
+
+func TestUploadsIsolation(t *testing.T) {
+	store := newMemoryStore()
+	store.objects["config.json"] = []byte("original")
+	u := NewUploads(store, "/uploads")
+	mux := http.NewServeMux()
+	for _, r := range u.Routes() {
+		mux.Handle(r.Method+" /uploads"+r.Path, r.Handler)
+	}
+
+	do := func(method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	victim := do(http.MethodPost, "/uploads/", "", map[string]string{"Upload-Length": "5"}).Header().Get("Location")
+	victimID := strings.TrimPrefix(victim, "/uploads/")
+	attacker := do(http.MethodPost, "/uploads/", "", map[string]string{
+		"Upload-Length":   "4",
+		"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte(".uploads/"+victimID)),
+	}).Header().Get("Location")
+	chunk := map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": "0"}
+	if w := do(http.MethodPatch, attacker, "evil", chunk); w.Code != http.StatusNoContent {
+		t.Fatalf("expected %d but got %d", http.StatusNoContent, w.Code)
+	}
+
+	if w := do(http.MethodHead, victim, "", nil); w.Code != http.StatusOK || w.Header().Get("Upload-Length") != "5" {
+		t.Errorf("expected the other upload's state to be untouched but got %d %s", w.Code, w.Header().Get("Upload-Length"))
+	}
+	if string(store.objects["config.json"]) != "original" {
+		t.Errorf("expected existing objects to be untouched but got %q", store.objects["config.json"])
+	}
+
+	if !u.lock(victimID) {
+		t.Fatal("expected the lock to be free")
+	}
+	if w := do(http.MethodPatch, victim, "hello", chunk); w.Code != http.StatusConflict {
+		t.Errorf("expected %d for a concurrent PATCH but got %d", http.StatusConflict, w.Code)
+	}
+	u.unlock(victimID)
+
+	if w := do(http.MethodHead, "/uploads/a.b", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected %d for an invalid id but got %d", http.StatusNotFound, w.Code)
+	}
+}
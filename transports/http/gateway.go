@@ -0,0 +1,285 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// VariantHeader lets a caller pick a gateway route's variant by name, overriding the weights
+const VariantHeader = "Sencillo-Variant"
+
+// bridgeQueryHeader carries the query string the same way the NATS HTTP bridge does, so handlers can read
+// it with the nats transport's query helpers
+const bridgeQueryHeader = "X-NatsBridge-UrlQuery"
+
+// hopHeaders only apply to a single connection, so the gateway doesn't forward them in either direction
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Requester sends a NATS request. *nats.Conn implements it.
+type Requester interface {
+	RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error)
+}
+
+// Variant is a subject a gateway route forwards to and its share of the traffic
+type Variant struct {
+	Name    string
+	Subject string
+	// Weight is relative to the other variants of the route. Negative weights count as zero.
+	Weight int
+}
+
+// GatewayOpt is a functional option to modify the Gateway
+type GatewayOpt func(*Gateway)
+
+// Gateway forwards HTTP requests to NATS services
+type Gateway struct {
+	nc         Requester
+	timeout    time.Duration
+	maxBody    int64
+	propagator propagation.TextMapPropagator
+	logger     *slog.Logger
+	requests   *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	intn       func(int) int
+}
+
+// NewGateway returns a Gateway sending requests over nc. Request bodies are limited to 1MB, the default NATS
+// max payload, by default.
+func NewGateway(nc Requester, opts ...GatewayOpt) *Gateway {
+	g := &Gateway{
+		nc:         nc,
+		timeout:    10 * time.Second,
+		maxBody:    1 << 20,
+		propagator: otel.GetTextMapPropagator(),
+		logger:     slog.Default(),
+		requests:   metrics.NewCounterVec("gateway_requests", "Gateway requests by route, variant, and status", []string{"route", "variant", "code"}),
+		latency:    metrics.NewHistogramVec("gateway_request_latency", "Gateway latency by route, variant, and status", []string{"route", "variant", "code"}),
+		intn:       rand.IntN,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// SetGatewayTimeout sets how long to wait for a reply. The request's own deadline applies if it is sooner.
+func SetGatewayTimeout(d time.Duration) GatewayOpt {
+	return func(g *Gateway) {
+		g.timeout = d
+	}
+}
+
+// SetGatewayMaxBody sets the largest request body that is forwarded. Larger requests get a 413.
+func SetGatewayMaxBody(n int64) GatewayOpt {
+	return func(g *Gateway) {
+		g.maxBody = n
+	}
+}
+
+// SetGatewayPropagator sets the propagator used to inject trace headers into the NATS request
+func SetGatewayPropagator(p propagation.TextMapPropagator) GatewayOpt {
+	return func(g *Gateway) {
+		g.propagator = p
+	}
+}
+
+// SetGatewayLogger sets the logger used to report failed requests
+func SetGatewayLogger(l *slog.Logger) GatewayOpt {
+	return func(g *Gateway) {
+		g.logger = l
+	}
+}
+
+// Metrics returns the gateway's collectors. Append them to the server's Exporter to serve them on /metrics.
+func (g *Gateway) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{g.requests, g.latency}
+}
+
+// Route returns a route that forwards requests to one of the variants. Variants are picked at random by
+// weight unless the request names one in the Sencillo-Variant header. The chosen variant is returned in the
+// same header.
+func (g *Gateway) Route(method, path string, variants ...Variant) Route {
+	return Route{
+		Method:  method,
+		Path:    path,
+		Handler: g.handler(method+" "+path, variants),
+	}
+}
+
+// pick returns the variant named in the header or a random one by weight
+func (g *Gateway) pick(r *http.Request, variants []Variant) Variant {
+	if name := r.Header.Get(VariantHeader); name != "" {
+		for _, v := range variants {
+			if v.Name == name {
+				return v
+			}
+		}
+	}
+
+	var total int
+	for _, v := range variants {
+		total += max(v.Weight, 0)
+	}
+	if total == 0 {
+		return variants[g.intn(len(variants))]
+	}
+
+	n := g.intn(total)
+	for _, v := range variants {
+		n -= max(v.Weight, 0)
+		if n < 0 {
+			return v
+		}
+	}
+
+	return variants[len(variants)-1]
+}
+
+func (g *Gateway) handler(route string, variants []Variant) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(variants) == 0 {
+			http.Error(w, "no variants configured", http.StatusInternalServerError)
+			return
+		}
+
+		start := time.Now()
+		v := g.pick(r, variants)
+		code := g.forward(w, r, v)
+
+		labels := prometheus.Labels{"route": route, "variant": v.Name, "code": strconv.Itoa(code)}
+		g.requests.With(labels).Inc()
+		g.latency.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// forward sends the request to the variant's subject, writes the reply, and returns the status code
+func (g *Gateway) forward(w http.ResponseWriter, r *http.Request, v Variant) int {
+	w.Header().Set(VariantHeader, v.Name)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.maxBody))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+
+	msg := nats.NewMsg(v.Subject)
+	msg.Data = body
+	for k, vals := range r.Header {
+		msg.Header[k] = vals
+	}
+	removeHopHeaders(http.Header(msg.Header))
+	msg.Header.Set(bridgeQueryHeader, r.URL.RawQuery)
+	g.propagator.Inject(r.Context(), propagation.HeaderCarrier(msg.Header))
+
+	ctx, cancel := context.WithTimeout(r.Context(), g.timeout)
+	defer cancel()
+
+	reply, err := g.nc.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		code := http.StatusBadGateway
+		switch {
+		case errors.Is(err, nats.ErrNoResponders):
+			code = http.StatusServiceUnavailable
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, nats.ErrTimeout):
+			code = http.StatusGatewayTimeout
+		}
+		g.logger.Error(fmt.Sprintf("error forwarding to %s: %v", v.Subject, err))
+		http.Error(w, http.StatusText(code), code)
+		return code
+	}
+
+	code := http.StatusOK
+	if c := reply.Header.Get(micro.ErrorCodeHeader); c != "" {
+		if n, err := strconv.Atoi(c); err == nil && n >= 100 && n <= 599 {
+			code = n
+		} else {
+			code = http.StatusInternalServerError
+		}
+	}
+
+	for k, vals := range reply.Header {
+		if strings.HasPrefix(k, "Nats-Service-") {
+			continue
+		}
+		w.Header()[k] = vals
+	}
+	removeHopHeaders(w.Header())
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	w.WriteHeader(code)
+	w.Write(reply.Data)
+	return code
+}
+
+// removeHopHeaders deletes the hop-by-hop headers and any headers the Connection header names. NATS headers
+// aren't canonicalized, so names are compared ignoring case.
+func removeHopHeaders(h http.Header) {
+	remove := make(map[string]bool)
+	for _, v := range hopHeaders {
+		remove[strings.ToLower(v)] = true
+	}
+	for k, vals := range h {
+		if !strings.EqualFold(k, "Connection") {
+			continue
+		}
+		for _, v := range vals {
+			for _, name := range strings.Split(v, ",") {
+				remove[strings.ToLower(strings.TrimSpace(name))] = true
+			}
+		}
+	}
+
+	for k := range h {
+		if remove[strings.ToLower(k)] {
+			delete(h, k)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type fakeRequester struct {
+	reply func(*nats.Msg) (*nats.Msg, error)
+	got   []*nats.Msg
+}
+
+func (f *fakeRequester) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	f.got = append(f.got, msg)
+	return f.reply(msg)
+}
+
+func TestGateway(t *testing.T) {
+	echo := func(m *nats.Msg) (*nats.Msg, error) {
+		return &nats.Msg{Data: []byte(m.Subject + ":" + string(m.Data))}, nil
+	}
+
+	tt := []struct {
+		name     string
+		variants []Variant
+		header   string
+		reply    func(*nats.Msg) (*nats.Msg, error)
+		code     int
+		body     string
+		variant  string
+	}{
+		{
+			name:     "weighted",
+			variants: []Variant{{Name: "v1", Subject: "orders.v1", Weight: 90}, {Name: "v2", Subject: "orders.v2", Weight: 10}},
+			reply:    echo,
+			code:     200,
+			body:     "orders.v2:hi",
+			variant:  "v2",
+		},
+		{
+			name:     "override",
+			variants: []Variant{{Name: "v1", Subject: "orders.v1", Weight: 100}, {Name: "v2", Subject: "orders.v2"}},
+			header:   "v2",
+			reply:    echo,
+			code:     200,
+			body:     "orders.v2:hi",
+			variant:  "v2",
+		},
+		{
+			name:     "unknown override uses weights",
+			variants: []Variant{{Name: "v1", Subject: "orders.v1", Weight: 1}},
+			header:   "v9",
+			reply:    echo,
+			code:     200,
+			body:     "orders.v1:hi",
+			variant:  "v1",
+		},
+		{
+			name:     "service error",
+			variants: []Variant{{Name: "v1", Subject: "orders.v1", Weight: 1}},
+			reply: func(*nats.Msg) (*nats.Msg, error) {
+				return &nats.Msg{Header: nats.Header{micro.ErrorCodeHeader: []string{"404"}}, Data: []byte(`{"errors":["not found"]}`)}, nil
+			},
+			code:    404,
+			body:    `{"errors":["not found"]}`,
+			variant: "v1",
+		},
+		{
+			name:     "no responders",
+			variants: []Variant{{Name: "v1", Subject: "orders.v1", Weight: 1}},
+			reply:    func(*nats.Msg) (*nats.Msg, error) { return nil, nats.ErrNoResponders },
+			code:     503,
+			body:     "Service Unavailable\n",
+			variant:  "v1",
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			nc := &fakeRequester{reply: v.reply}
+			g := NewGateway(nc)
+			// pick the last unit of weight so the smallest variant is chosen
+			g.intn = func(n int) int { return n - 1 }
+
+			route := g.Route("POST", "/orders", v.variants...)
+			req := httptest.NewRequest("POST", "/orders?limit=5", strings.NewReader("hi"))
+			req.Header.Set("X-Tenant", "acme")
+			if v.header != "" {
+				req.Header.Set(VariantHeader, v.header)
+			}
+			w := httptest.NewRecorder()
+			route.Handler.ServeHTTP(w, req)
+
+			if w.Code != v.code || w.Body.String() != v.body {
+				t.Errorf("expected %d %q but got %d %q", v.code, v.body, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(VariantHeader); got != v.variant {
+				t.Errorf("expected variant %s but got %s", v.variant, got)
+			}
+			if w.Header().Get(micro.ErrorCodeHeader) != "" {
+				t.Error("expected service error headers to be dropped")
+			}
+
+			msg := nc.got[0]
+			if msg.Header.Get("X-Tenant") != "acme" || msg.Header.Get(bridgeQueryHeader) != "limit=5" {
+				t.Errorf("expected request headers to be forwarded but got %v", msg.Header)
+			}
+
+			var m dto.Metric
+			if err := g.requests.WithLabelValues("POST /orders", v.variant, strconv.Itoa(v.code)).Write(&m); err != nil {
+				t.Fatal(err)
+			}
+			if m.GetCounter().GetValue() != 1 {
+				t.Errorf("expected 1 request recorded for %s but got %v", v.variant, m.GetCounter().GetValue())
+			}
+		})
+	}
+}
+
+func TestGatewayHopHeaders(t *testing.T) {
+	nc := &fakeRequester{reply: func(*nats.Msg) (*nats.Msg, error) {
+		return &nats.Msg{Header: nats.Header{"connection": []string{"X-Internal"}, "X-Internal": []string{"1"}, "Keep-Alive": []string{"timeout=5"}, "X-Order": []string{"1"}}}, nil
+	}}
+	route := NewGateway(nc).Route("POST", "/orders", Variant{Name: "v1", Subject: "orders.v1", Weight: 1})
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader("hi"))
+	for k, v := range map[string]string{
+		"Connection":          "X-Hop, Upgrade",
+		"X-Hop":               "1",
+		"Upgrade":             "websocket",
+		"Te":                  "trailers",
+		"Proxy-Authorization": "Basic dXNlcjpwYXNz",
+		"Transfer-Encoding":   "chunked",
+		"X-Tenant":            "acme",
+	} {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	route.Handler.ServeHTTP(w, req)
+
+	msg := nc.got[0]
+	for _, k := range []string{"Connection", "X-Hop", "Upgrade", "Te", "Proxy-Authorization", "Transfer-Encoding"} {
+		if msg.Header.Get(k) != "" {
+			t.Errorf("expected %s not to be forwarded", k)
+		}
+	}
+	if msg.Header.Get("X-Tenant") != "acme" {
+		t.Error("expected end-to-end headers to be forwarded")
+	}
+
+	for _, k := range []string{"connection", "X-Internal", "Keep-Alive"} {
+		if _, ok := w.Header()[k]; ok {
+			t.Errorf("expected %s not to be returned", k)
+		}
+	}
+	if w.Header().Get("X-Order") != "1" {
+		t.Error("expected end-to-end reply headers to be returned")
+	}
+}
+
+func TestGatewayMaxBody(t *testing.T) {
+	nc := &fakeRequester{reply: func(*nats.Msg) (*nats.Msg, error) { return &nats.Msg{}, nil }}
+	route := NewGateway(nc, SetGatewayMaxBody(3)).Route("POST", "/orders", Variant{Name: "v1", Subject: "orders.v1", Weight: 1})
+
+	w := httptest.NewRecorder()
+	route.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/orders", strings.NewReader("hello")))
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected %d but got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+	if len(nc.got) != 0 {
+		t.Error("expected the request not to be forwarded")
+	}
+}
+
+func TestGatewayPick(t *testing.T) {
+	variants := []Variant{{Name: "v1", Weight: 90}, {Name: "v2", Weight: 10}, {Name: "off", Weight: -5}}
+	g := NewGateway(&fakeRequester{})
+
+	counts := map[string]int{}
+	for i := 0; i < 100; i++ {
+		g.intn = func(int) int { return i }
+		counts[g.pick(httptest.NewRequest("GET", "/", nil), variants).Name]++
+	}
+
+	if counts["v1"] != 90 || counts["v2"] != 10 || counts["off"] != 0 {
+		t.Errorf("expected a 90/10 split but got %v", counts)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sagikazarmark/slog-shim"
+)
+
+// PrincipalFunc returns the authenticated principal for a request, or an empty string if there isn't one
+type PrincipalFunc func(*http.Request) string
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of the context carrying the logger
+func ContextWithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFrom returns the request scoped logger from the context. If there isn't one, slog.Default is returned.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+
+	return slog.Default()
+}
+
+// RequestLogger is a middleware that places a logger with the request ID, route, and principal into the request
+// context. It is applied to every route registered with RegisterSubRouter.
+func RequestLogger(l *slog.Logger, route string, principal PrincipalFunc) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			attrs := []any{"request_id", r.Header.Get("X-Request-ID"), "route", route}
+			if principal != nil {
+				if p := principal(r); p != "" {
+					attrs = append(attrs, "principal", p)
+				}
+			}
+
+			ctx := ContextWithLogger(r.Context(), l.With(attrs...))
+			h.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogFormat selects how access logs are written
+type LogFormat int
+
+const (
+	// FormatStructured logs each request as attributes on the configured slog.Logger
+	FormatStructured LogFormat = iota
+	// FormatJSON writes each request as a JSON object on its own line
+	FormatJSON
+	// FormatCLF writes each request in the common log format
+	FormatCLF
+	// FormatTemplate writes each request using a user supplied text/template
+	FormatTemplate
+)
+
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// defaultLogger is shared by every Logging middleware that isn't given a logger
+var defaultLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// LoggingOption is a functional option to modify the Logging middleware
+type LoggingOption func(*accessLogger)
+
+// AccessLogEntry contains the fields available to access log formats and templates
+type AccessLogEntry struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	Proto      string        `json:"proto"`
+	Host       string        `json:"host"`
+	RemoteAddr string        `json:"remote_addr"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	Referer    string        `json:"referer,omitempty"`
+	Status     int           `json:"status"`
+	Bytes      int           `json:"bytes"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+	RequestID  string        `json:"request_id,omitempty"`
+	TraceID    string        `json:"trace_id,omitempty"`
+}
+
+type accessLogger struct {
+	format   LogFormat
+	logger   *slog.Logger
+	out      io.Writer
+	template *template.Template
+}
+
+// SetLogFormat sets the access log format
+func SetLogFormat(f LogFormat) LoggingOption {
+	return func(a *accessLogger) {
+		a.format = f
+	}
+}
+
+// SetLogger sets the logger used by FormatStructured
+func SetLogger(l *slog.Logger) LoggingOption {
+	return func(a *accessLogger) {
+		a.logger = l
+	}
+}
+
+// SetLogOutput sets the writer used by FormatJSON, FormatCLF, and FormatTemplate
+func SetLogOutput(w io.Writer) LoggingOption {
+	return func(a *accessLogger) {
+		a.out = w
+	}
+}
+
+// SetLogTemplate switches to FormatTemplate using the text/template, which is executed with an AccessLogEntry.
+// It panics if the template cannot be parsed.
+func SetLogTemplate(text string) LoggingOption {
+	t := template.Must(template.New("access").Parse(text))
+	return func(a *accessLogger) {
+		a.format = FormatTemplate
+		a.template = t
+	}
+}
+
+// Logging is a middleware that writes an access log entry for every request
+func Logging(h http.Handler, opts ...LoggingOption) http.Handler {
+	a := &accessLogger{
+		format: FormatStructured,
+		logger: defaultLogger,
+		out:    os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec, ok := w.(*StatusRec)
+		if !ok {
+			rec = &StatusRec{ResponseWriter: w}
+		}
+
+		span := &recordedSpan{}
+		r = r.WithContext(context.WithValue(r.Context(), recordedSpanKey{}, span))
+		defer func() {
+			a.write(newAccessLogEntry(r, rec, span, start))
+		}()
+
+		h.ServeHTTP(rec, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+type recordedSpanKey struct{}
+
+// recordedSpan carries the span started inside Logging back out to the access log entry
+type recordedSpan struct {
+	mu sync.Mutex
+	sc trace.SpanContext
+}
+
+// RecordSpan records the request's span for the access log written by Logging, which wraps the handler that
+// starts the span. Place it inside the tracing handler.
+func RecordSpan(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span, ok := r.Context().Value(recordedSpanKey{}).(*recordedSpan); ok {
+			span.mu.Lock()
+			span.sc = trace.SpanContextFromContext(r.Context())
+			span.mu.Unlock()
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func newAccessLogEntry(r *http.Request, rec *StatusRec, span *recordedSpan, start time.Time) AccessLogEntry {
+	status := rec.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	requestID := r.Header.Get("X-Request-ID")
+	if requestID == "" {
+		requestID = rec.Header().Get("X-Request-ID")
+	}
+
+	span.mu.Lock()
+	sc := span.sc
+	span.mu.Unlock()
+	if !sc.HasTraceID() {
+		sc = trace.SpanContextFromContext(r.Context())
+	}
+	var traceID string
+	if sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+
+	d := time.Since(start)
+	return AccessLogEntry{
+		Time:       start,
+		Method:     r.Method,
+		Path:       r.URL.String(),
+		Proto:      r.Proto,
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		Referer:    r.Referer(),
+		Status:     status,
+		Bytes:      rec.Bytes,
+		Duration:   d,
+		DurationMS: d.Milliseconds(),
+		RequestID:  requestID,
+		TraceID:    traceID,
+	}
+}
+
+func (a *accessLogger) write(e AccessLogEntry) {
+	var buf bytes.Buffer
+
+	switch a.format {
+	case FormatJSON:
+		if err := json.NewEncoder(&buf).Encode(e); err != nil {
+			a.logger.Error(fmt.Sprintf("error encoding access log: %v", err))
+			return
+		}
+	case FormatCLF:
+		buf.WriteString(e.CLF())
+		buf.WriteByte('\n')
+	case FormatTemplate:
+		if err := a.template.Execute(&buf, e); err != nil {
+			a.logger.Error(fmt.Sprintf("error executing access log template: %v", err))
+			return
+		}
+		if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	default:
+		a.logger.Info("request",
+			"method", e.Method,
+			"path", e.Path,
+			"host", e.Host,
+			"status", e.Status,
+			"bytes", e.Bytes,
+			"duration_ms", e.DurationMS,
+			"request_id", e.RequestID,
+			"trace_id", e.TraceID,
+		)
+		return
+	}
+
+	a.out.Write(buf.Bytes())
+}
+
+// CLF returns the entry in the common log format
+func (e AccessLogEntry) CLF() string {
+	size := "-"
+	if e.Bytes > 0 {
+		size = fmt.Sprintf("%d", e.Bytes)
+	}
+
+	host := e.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		host = "-"
+	}
+
+	return fmt.Sprintf("%s - - [%s] %q %d %s", host, e.Time.Format(clfTime), fmt.Sprintf("%s %s %s", e.Method, e.Path, e.Proto), e.Status, size)
+}
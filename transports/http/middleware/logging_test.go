@@ -0,0 +1,107 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLoggingFormats(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})
+
+	tt := []struct {
+		name  string
+		opts  []LoggingOption
+		check func(t *testing.T, out string)
+	}{
+		{
+			name: "json",
+			opts: []LoggingOption{SetLogFormat(FormatJSON)},
+			check: func(t *testing.T, out string) {
+				var e AccessLogEntry
+				if err := json.Unmarshal([]byte(out), &e); err != nil {
+					t.Fatal(err)
+				}
+				if e.Status != http.StatusTeapot || e.Bytes != 5 || e.RequestID != "abc" || e.Path != "/testing" {
+					t.Errorf("unexpected entry %+v", e)
+				}
+			},
+		},
+		{
+			name: "clf",
+			opts: []LoggingOption{SetLogFormat(FormatCLF)},
+			check: func(t *testing.T, out string) {
+				if !strings.HasPrefix(out, "192.0.2.1 - - [") || !strings.HasSuffix(out, `"GET /testing HTTP/1.1" 418 5`+"\n") {
+					t.Errorf("unexpected clf line %q", out)
+				}
+			},
+		},
+		{
+			name: "template",
+			opts: []LoggingOption{SetLogTemplate("{{ .Method }} {{ .Status }} {{ .RequestID }}")},
+			check: func(t *testing.T, out string) {
+				if out != "GET 418 abc\n" {
+					t.Errorf("unexpected template output %q", out)
+				}
+			},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := Logging(handler, append(v.opts, SetLogOutput(&buf))...)
+
+			req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+			req.Header.Set("X-Request-ID", "abc")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			v.check(t, buf.String())
+		})
+	}
+}
+
+func TestLoggingTraceID(t *testing.T) {
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	// stands in for the tracing handler, which starts the span inside Logging
+	tracing := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+			h.ServeHTTP(w, r.WithContext(trace.ContextWithSpanContext(r.Context(), sc)))
+		})
+	}
+
+	var buf bytes.Buffer
+	h := Logging(tracing(RecordSpan(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))), SetLogFormat(FormatJSON), SetLogOutput(&buf))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/testing", nil))
+
+	var e AccessLogEntry
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.TraceID != traceID.String() {
+		t.Errorf("expected trace ID %s but got %q", traceID, e.TraceID)
+	}
+}
@@ -17,30 +17,14 @@ package middleware
 import (
 	"bufio"
 	"fmt"
-	"log/slog"
 	"net"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/segmentio/ksuid"
 )
 
-func Logging(h http.Handler) http.Handler {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		defer func() {
-			logger.Info("path", r.URL.String(), "host", r.Host, fmt.Sprintf("request duration: %dms", time.Since(start).Milliseconds()))
-		}()
-
-		h.ServeHTTP(w, r)
-	}
-
-	return http.HandlerFunc(fn)
-}
-
 func RequestID(h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("X-Request-ID") == "" {
@@ -59,6 +43,7 @@ func RequestID(h http.Handler) http.Handler {
 type StatusRec struct {
 	http.ResponseWriter
 	Status int
+	Bytes  int
 }
 
 // WriteHeader captures the status code
@@ -72,7 +57,9 @@ func (r *StatusRec) Write(b []byte) (int, error) {
 	if r.Status == 0 {
 		r.Status = http.StatusOK
 	}
-	return r.ResponseWriter.Write(b)
+	n, err := r.ResponseWriter.Write(b)
+	r.Bytes += n
+	return n, err
 }
 
 // Flush implements http.Flusher if the underlying writer supports it.
@@ -0,0 +1,74 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+)
+
+// NDJSONWriter writes one JSON record per line and flushes after each so clients receive records as they
+// are produced
+type NDJSONWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+// NewNDJSONWriter sets the NDJSON content type on w and returns a writer for it
+func NewNDJSONWriter(w http.ResponseWriter) *NDJSONWriter {
+	w.Header().Set("Content-Type", codec.NDJSONContentType)
+	flusher, _ := w.(http.Flusher)
+
+	return &NDJSONWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}
+}
+
+// Write encodes v as a single line and flushes it
+func (n *NDJSONWriter) Write(v any) error {
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+
+	if n.flusher != nil {
+		n.flusher.Flush()
+	}
+
+	return nil
+}
+
+// StreamNDJSON writes every item received from items as NDJSON until the channel is closed or the request
+// context is done. The producer should stop sending once the request context is done.
+func StreamNDJSON[T any](w http.ResponseWriter, r *http.Request, items <-chan T) error {
+	nw := NewNDJSONWriter(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+			if err := nw.Write(item); err != nil {
+				return err
+			}
+		}
+	}
+}
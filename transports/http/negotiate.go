@@ -0,0 +1,60 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/codec"
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+)
+
+// Respond encodes v with the codec from codec.DefaultRegistry that best matches the request's Accept
+// header and writes it with the status code. When the best match can't encode v, such as protobuf for a value
+// that isn't a proto.Message, the next acceptable codec is tried. A 406 ClientError is returned when no codec is
+// acceptable, and an error, which ErrHandler answers with a 500, when no acceptable codec can encode v.
+func Respond(w http.ResponseWriter, r *http.Request, code int, v any) error {
+	return RespondWith(codec.DefaultRegistry, w, r, code, v)
+}
+
+// RespondWith is like Respond but negotiates against the given registry
+func RespondWith(reg *codec.Registry, w http.ResponseWriter, r *http.Request, code int, v any) error {
+	codecs := reg.Acceptable(r.Header.Get("Accept"))
+	if len(codecs) == 0 {
+		return sderrors.NewClientError(codec.ErrNotAcceptable, http.StatusNotAcceptable)
+	}
+
+	var c codec.Codec
+	var data []byte
+	var err error
+	for _, acceptable := range codecs {
+		if data, err = acceptable.Marshal(v); err == nil {
+			c = acceptable
+			break
+		}
+	}
+	if c == nil {
+		// the client asked for formats the server supports, so failing to encode v is a server bug
+		return fmt.Errorf("encoding %T as %s: %w", v, codecs[len(codecs)-1].ContentType(), err)
+	}
+
+	w.Header().Set("Content-Type", c.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+
+	return err
+}
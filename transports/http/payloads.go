@@ -0,0 +1,105 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// payloadCaptureLimit bounds how much of each payload is kept for redaction before the sampler truncates it
+const payloadCaptureLimit = 64 << 10
+
+// limitedBuffer keeps the first bytes written to it up to its limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) keep(p []byte) {
+	if n := b.limit - b.Len(); n > 0 {
+		b.Write(p[:min(n, len(p))])
+	}
+}
+
+// captureReader keeps the start of a request body as the handler reads it
+type captureReader struct {
+	io.ReadCloser
+	buf *limitedBuffer
+}
+
+func (c captureReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.buf.keep(p[:n])
+	return n, err
+}
+
+// captureWriter keeps the status and the start of the response body
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	buf    *limitedBuffer
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.buf.keep(p)
+	return c.ResponseWriter.Write(p)
+}
+
+func (c *captureWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// PayloadSamples attaches samples of the request and response bodies to the request's span when the sampler
+// picks it. Responses with a 5xx status are failed requests. Use it inside the route's tracing, such as in
+// Route.Middlewares.
+func PayloadSamples(s *tracing.PayloadSampler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			if !span.IsRecording() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			req := &limitedBuffer{limit: payloadCaptureLimit}
+			if r.Body != nil {
+				r.Body = captureReader{ReadCloser: r.Body, buf: req}
+			}
+			cw := &captureWriter{ResponseWriter: w, buf: &limitedBuffer{limit: payloadCaptureLimit}}
+			next.ServeHTTP(cw, r)
+
+			if !s.Sampled(span, cw.status >= http.StatusInternalServerError) {
+				return
+			}
+			s.Record(span, "request", req.Bytes())
+			s.Record(span, "response", cw.buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/SencilloDev/sencillo-go/tracing"
+)
+
+func TestPayloadSamples(t *testing.T) {
+	tp, rec := sdtest.NewTracerProvider(t)
+	h := PayloadSamples(tracing.NewPayloadSampler())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/ok", "/fail"} {
+		ctx, span := tp.Tracer("test").Start(httptest.NewRequest(http.MethodPost, path, nil).Context(), path)
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"token":"abc","id":1}`)).WithContext(ctx)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		span.End()
+	}
+
+	if events := rec.Named("/ok")[0].Events(); len(events) != 0 {
+		t.Errorf("expected successful requests not to be sampled but got %v", events)
+	}
+
+	events := rec.Named("/fail")[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("expected request and response samples but got %v", events)
+	}
+	samples := map[string]string{}
+	for _, e := range events {
+		attrs := map[string]string{}
+		for _, a := range e.Attributes {
+			attrs[string(a.Key)] = a.Value.Emit()
+		}
+		samples[attrs["payload.kind"]] = attrs["payload.sample"]
+	}
+	if samples["request"] != `{"id":1,"token":"[REDACTED]"}` {
+		t.Errorf("expected the redacted request but got %s", samples["request"])
+	}
+	if !strings.Contains(samples["response"], "boom") {
+		t.Errorf("expected the response but got %s", samples["response"])
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+)
+
+func TestPriorityLimit(t *testing.T) {
+	l := concurrency.NewLimiter(1, concurrency.SetMaxQueue(1), concurrency.SetMaxWait(time.Minute))
+	h := PriorityLimit(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	release, err := l.Acquire(context.Background(), concurrency.PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		queued <- rr.Code
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(concurrency.PriorityHeader, "high")
+	done := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		done <- rr.Code
+	}()
+
+	if code := <-queued; code != http.StatusServiceUnavailable {
+		t.Errorf("expected the normal request to be shed with a 503 but got %d", code)
+	}
+	release()
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("expected the high request to be admitted but got %d", code)
+	}
+}
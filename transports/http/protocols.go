@@ -0,0 +1,91 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTP3Server is a QUIC server that can serve the same handler as the HTTP server, such as quic-go's
+// http3.Server
+type HTTP3Server interface {
+	ListenAndServe() error
+	Close() error
+}
+
+// HTTP3ServerFunc builds an HTTP3Server listening on addr for the given handler
+type HTTP3ServerFunc func(addr string, h http.Handler) HTTP3Server
+
+// SetH2C serves HTTP/2 without TLS to clients that use prior knowledge or an h2c upgrade, which is common for internal mesh
+// traffic. HTTP/1 requests on the same port are unaffected.
+func SetH2C(b bool) ServerOption {
+	return func(s *Server) {
+		s.h2c = b
+	}
+}
+
+// SetHTTP3 serves HTTP/3 on the given UDP port using the server built by f and advertises it to HTTP/1 and
+// HTTP/2 clients with an Alt-Svc header. This is experimental.
+func SetHTTP3(port int, f HTTP3ServerFunc) ServerOption {
+	return func(s *Server) {
+		s.http3Port = port
+		s.newHTTP3 = f
+	}
+}
+
+// protocolHandler wraps h with the Alt-Svc header and h2c support when they are enabled
+func (s *Server) protocolHandler(h http.Handler) http.Handler {
+	if s.newHTTP3 != nil {
+		h = altSvc(h, fmt.Sprintf(`h3=":%d"; ma=86400`, s.http3Port))
+	}
+
+	if s.h2c {
+		h = h2c.NewHandler(h, &http2.Server{})
+	}
+
+	return h
+}
+
+// serveHTTP3 starts the HTTP/3 server if one is configured
+func (s *Server) serveHTTP3(h http.Handler, errChan chan<- error) {
+	if s.newHTTP3 == nil {
+		return
+	}
+
+	h3 := s.newHTTP3(fmt.Sprintf(":%d", s.http3Port), h)
+	s.mu.Lock()
+	s.http3 = h3
+	s.mu.Unlock()
+
+	s.Logger.Info(fmt.Sprintf("starting HTTP/3 server on :%d", s.http3Port))
+	go func() {
+		if err := h3.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+}
+
+func altSvc(h http.Handler, value string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 {
+			w.Header().Set("Alt-Svc", value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
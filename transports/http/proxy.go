@@ -0,0 +1,254 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var ErrNoUpstreams = fmt.Errorf("at least one upstream is required")
+
+// Balancer selects which upstream receives a request
+type Balancer int
+
+const (
+	// RoundRobin cycles through healthy upstreams in order
+	RoundRobin Balancer = iota
+	// LeastConnections picks the healthy upstream with the fewest requests in flight
+	LeastConnections
+)
+
+// ProxyOpt is a functional option to modify the Proxy
+type ProxyOpt func(*Proxy)
+
+// Upstream is a single backend in a Proxy's pool
+type Upstream struct {
+	URL     *url.URL
+	proxy   *httputil.ReverseProxy
+	healthy atomic.Bool
+	active  atomic.Int64
+}
+
+// Healthy reports whether the upstream passed its last health check
+func (u *Upstream) Healthy() bool {
+	return u.healthy.Load()
+}
+
+// Active returns the number of requests in flight to the upstream
+func (u *Upstream) Active() int64 {
+	return u.active.Load()
+}
+
+// Proxy forwards requests to a pool of upstreams
+type Proxy struct {
+	Upstreams      []*Upstream
+	balancer       Balancer
+	next           atomic.Uint64
+	rewrite        func(*httputil.ProxyRequest)
+	modifyResponse func(*http.Response) error
+	healthPath     string
+	healthInterval time.Duration
+	client         *http.Client
+	propagator     propagation.TextMapPropagator
+	logger         *slog.Logger
+}
+
+// NewProxy returns a Proxy for the given upstream base URLs. Every upstream starts healthy.
+func NewProxy(targets []string, opts ...ProxyOpt) (*Proxy, error) {
+	if len(targets) == 0 {
+		return nil, ErrNoUpstreams
+	}
+
+	p := &Proxy{
+		healthInterval: 10 * time.Second,
+		client:         &http.Client{Timeout: 2 * time.Second},
+		propagator:     otel.GetTextMapPropagator(),
+		logger:         slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for _, v := range targets {
+		u, err := url.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream %q: %w", v, err)
+		}
+
+		up := &Upstream{URL: u}
+		up.healthy.Store(true)
+		up.proxy = &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.SetURL(u)
+				pr.SetXForwarded()
+				p.propagator.Inject(pr.In.Context(), propagation.HeaderCarrier(pr.Out.Header))
+				if p.rewrite != nil {
+					p.rewrite(pr)
+				}
+			},
+			ModifyResponse: p.modifyResponse,
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				p.logger.Error(fmt.Sprintf("error proxying to %s: %v", u, err))
+				w.WriteHeader(http.StatusBadGateway)
+			},
+		}
+		p.Upstreams = append(p.Upstreams, up)
+	}
+
+	return p, nil
+}
+
+// SetBalancer sets how upstreams are selected. The default is RoundRobin.
+func SetBalancer(b Balancer) ProxyOpt {
+	return func(p *Proxy) {
+		p.balancer = b
+	}
+}
+
+// SetRewrite sets a function to modify the outgoing request, such as adding or removing headers
+func SetRewrite(f func(*httputil.ProxyRequest)) ProxyOpt {
+	return func(p *Proxy) {
+		p.rewrite = f
+	}
+}
+
+// SetModifyResponse sets a function to modify responses from upstreams
+func SetModifyResponse(f func(*http.Response) error) ProxyOpt {
+	return func(p *Proxy) {
+		p.modifyResponse = f
+	}
+}
+
+// SetProxyHealthCheck sets the path requested on each upstream by HealthCheck and how often it runs
+func SetProxyHealthCheck(path string, interval time.Duration) ProxyOpt {
+	return func(p *Proxy) {
+		p.healthPath = path
+		p.healthInterval = interval
+	}
+}
+
+// SetProxyPropagator sets the propagator used to inject trace headers into upstream requests
+func SetProxyPropagator(prop propagation.TextMapPropagator) ProxyOpt {
+	return func(p *Proxy) {
+		p.propagator = prop
+	}
+}
+
+// SetProxyLogger sets the proxy logger
+func SetProxyLogger(l *slog.Logger) ProxyOpt {
+	return func(p *Proxy) {
+		p.logger = l
+	}
+}
+
+// ProxyRoute returns a Route that forwards matching requests to the proxy
+func ProxyRoute(method, path string, p *Proxy) Route {
+	return Route{
+		Method:  method,
+		Path:    path,
+		Handler: p,
+	}
+}
+
+// ServeHTTP forwards the request to an upstream chosen by the balancer
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	up := p.pick()
+	if up == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	up.active.Add(1)
+	defer up.active.Add(-1)
+
+	up.proxy.ServeHTTP(w, r)
+}
+
+func (p *Proxy) pick() *Upstream {
+	var chosen *Upstream
+	switch p.balancer {
+	case LeastConnections:
+		for _, v := range p.Upstreams {
+			if v.Healthy() && (chosen == nil || v.Active() < chosen.Active()) {
+				chosen = v
+			}
+		}
+	default:
+		n := uint64(len(p.Upstreams))
+		for i := uint64(0); i < n; i++ {
+			if v := p.Upstreams[(p.next.Add(1)-1)%n]; v.Healthy() {
+				chosen = v
+				break
+			}
+		}
+	}
+
+	return chosen
+}
+
+// HealthCheck requests the health path on every upstream until ctx is done, marking upstreams that fail
+// or return a non 2xx status as unhealthy. It does nothing if no health path is set.
+func (p *Proxy) HealthCheck(ctx context.Context) {
+	if p.healthPath == "" {
+		return
+	}
+
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		p.checkUpstreams(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Proxy) checkUpstreams(ctx context.Context) {
+	for _, v := range p.Upstreams {
+		healthy := p.checkUpstream(ctx, v)
+		if v.healthy.Swap(healthy) != healthy {
+			p.logger.Info(fmt.Sprintf("upstream %s healthy=%v", v.URL, healthy))
+		}
+	}
+}
+
+func (p *Proxy) checkUpstream(ctx context.Context, u *Upstream) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL.JoinPath(p.healthPath).String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"testing"
+)
+
+func newUpstream(name string, healthy bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" && !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(name + r.URL.Path + r.Header.Get("X-Test")))
+	}))
+}
+
+func TestProxy(t *testing.T) {
+	a := newUpstream("a", true)
+	defer a.Close()
+	b := newUpstream("b", false)
+	defer b.Close()
+	c := newUpstream("c", true)
+	defer c.Close()
+
+	tt := []struct {
+		name        string
+		opts        []ProxyOpt
+		healthCheck bool
+		want        []string
+	}{
+		{name: "round robin", want: []string{"a/users", "b/users", "c/users", "a/users"}},
+		{name: "skips unhealthy", healthCheck: true, want: []string{"a/users", "c/users", "a/users"}},
+		{
+			name: "rewrite",
+			opts: []ProxyOpt{SetRewrite(func(pr *httputil.ProxyRequest) { pr.Out.Header.Set("X-Test", "!") })},
+			want: []string{"a/users!", "b/users!"},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			opts := append([]ProxyOpt{SetProxyHealthCheck("/healthz", 0)}, v.opts...)
+			p, err := NewProxy([]string{a.URL, b.URL, c.URL}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.healthCheck {
+				p.checkUpstreams(context.Background())
+			}
+
+			for _, want := range v.want {
+				w := httptest.NewRecorder()
+				p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+				body, _ := io.ReadAll(w.Body)
+				if string(body) != want {
+					t.Errorf("expected %q but got %q", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestProxyLeastConnections(t *testing.T) {
+	p, err := NewProxy([]string{"http://a", "http://b"}, SetBalancer(LeastConnections))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Upstreams[0].active.Store(3)
+	if got := p.pick(); got != p.Upstreams[1] {
+		t.Errorf("expected upstream b but got %s", got.URL)
+	}
+
+	p.Upstreams[1].healthy.Store(false)
+	if got := p.pick(); got != p.Upstreams[0] {
+		t.Errorf("expected upstream a but got %s", got.URL)
+	}
+
+	p.Upstreams[0].healthy.Store(false)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d but got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// ListenFDEnv is set on a child process started by Restart and holds the file descriptor of the inherited
+// listener
+const ListenFDEnv = "SENCILLO_LISTEN_FD"
+
+var ErrNoListener = fmt.Errorf("server is not listening")
+
+// SetReusePort binds the listener with SO_REUSEPORT so a new process can bind the same address while the
+// old one drains
+func SetReusePort(b bool) ServerOption {
+	return func(s *Server) {
+		s.reusePort = b
+	}
+}
+
+// SetGracefulRestart enables restarting on SIGHUP. The listener is handed to a new copy of the binary
+// before this process drains and exits.
+func SetGracefulRestart(b bool) ServerOption {
+	return func(s *Server) {
+		s.gracefulRestart = b
+	}
+}
+
+// listen returns the listener inherited from a parent process if there is one, otherwise it binds a new one
+func (s *Server) listen() (net.Listener, error) {
+	if v := os.Getenv(ListenFDEnv); v != "" {
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ListenFDEnv, err)
+		}
+
+		f := os.NewFile(uintptr(fd), "listener")
+		defer f.Close()
+		ln, err := net.FileListener(f)
+		if err != nil {
+			return nil, fmt.Errorf("error inheriting listener: %w", err)
+		}
+
+		// don't pass the descriptor on to processes this one starts
+		os.Unsetenv(ListenFDEnv)
+		return ln, nil
+	}
+
+	lc := net.ListenConfig{}
+	if s.reusePort {
+		lc.Control = reusePort
+	}
+
+	return lc.Listen(context.Background(), "tcp", s.apiServer.Addr)
+}
+
+// Restart starts a new copy of the running binary with the same arguments and hands it the listener.
+// The caller should shut this server down once Restart returns so in flight requests can finish.
+func (s *Server) Restart() (*os.Process, error) {
+	s.mu.Lock()
+	ln := s.listener
+	s.mu.Unlock()
+
+	tcp, ok := ln.(*net.TCPListener)
+	if !ok {
+		return nil, ErrNoListener
+	}
+
+	f, err := tcp.File()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// ExtraFiles start at descriptor 3
+	cmd.ExtraFiles = []*os.File{f}
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=3", ListenFDEnv))
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info(fmt.Sprintf("started new process %d", cmd.Process.Pid))
+
+	return cmd.Process, nil
+}
+
+var restartSignal = syscall.SIGHUP
@@ -18,10 +18,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -44,7 +46,8 @@ type handlerWithError func(http.ResponseWriter, *http.Request) error
 
 type MiddlewareWithLogger func(*Server, http.Handler) http.Handler
 
-// errHandler contains a handler that returns an error and a logger
+// errHandler contains a handler that returns an error and a logger. If Logger is nil the request scoped
+// logger from LoggerFrom is used.
 type ErrHandler struct {
 	Handler handlerWithError
 	Logger  *slog.Logger
@@ -52,19 +55,66 @@ type ErrHandler struct {
 
 // Server holds the http.Server, a logger, and the router to attach to the http.Server
 type Server struct {
-	apiServer      *http.Server
-	Logger         *slog.Logger
-	Router         *http.ServeMux
-	Exporter       *metrics.Exporter
-	traceShutdown  func(context.Context) error
-	TracerProvider *trace.TracerProvider
+	apiServer       *http.Server
+	Logger          *slog.Logger
+	Router          *http.ServeMux
+	Exporter        *metrics.Exporter
+	traceShutdown   func(context.Context) error
+	TracerProvider  *trace.TracerProvider
+	accessLogOpts   []sdmiddleware.LoggingOption
+	principal       PrincipalFunc
+	reusePort       bool
+	gracefulRestart bool
+	mu              sync.Mutex
+	listener        net.Listener
+	h2c             bool
+	http3Port       int
+	newHTTP3        HTTP3ServerFunc
+	http3           HTTP3Server
+	// registerErr holds the problems found by RegisterSubRouter, which Serve reports
+	registerErr error
 }
 
-// Route contains the information needed for an HTTP handler
+// Route contains the information needed for an HTTP handler. Timeout, MaxBodyBytes, and Middlewares are
+// optional and applied when the route is registered.
 type Route struct {
 	Method  string
 	Path    string
 	Handler http.Handler
+	// Timeout responds with 503 if the handler takes longer than the duration
+	Timeout time.Duration
+	// MaxBodyBytes limits the size of the request body
+	MaxBodyBytes int64
+	// Middlewares wrap the handler in order, so the first is the outermost
+	Middlewares []func(http.Handler) http.Handler
+	// Cache sets Cache-Control and Surrogate-Control on the route's successful GET and HEAD responses
+	Cache *CachePolicy
+}
+
+// handler returns the route handler wrapped with its middlewares and limits
+func (r Route) handler() http.Handler {
+	h := r.Handler
+	if r.MaxBodyBytes > 0 {
+		next := h
+		h = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Body = http.MaxBytesReader(w, req.Body, r.MaxBodyBytes)
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	if r.Timeout > 0 {
+		h = http.TimeoutHandler(h, r.Timeout, http.StatusText(http.StatusServiceUnavailable))
+	}
+
+	if r.Cache != nil {
+		h = CacheHeaders(*r.Cache)(h)
+	}
+
+	for i := len(r.Middlewares) - 1; i >= 0; i-- {
+		h = r.Middlewares[i](h)
+	}
+
+	return h
 }
 
 func JsonHandler(h handlerWithError) handlerWithError {
@@ -121,8 +171,7 @@ func HandleWithContextError[T any](h func(http.ResponseWriter, *http.Request, T)
 
 		var ce ClientError
 		if errors.As(err, &ce) {
-			w.WriteHeader(ce.Code())
-			w.Write([]byte(ce.Body()))
+			writeClientError(w, r, ce)
 			return
 		}
 
@@ -174,6 +223,20 @@ func SetTracerProvider(t *trace.TracerProvider) ServerOption {
 	}
 }
 
+// SetAccessLogOptions sets the options passed to the access log middleware on every sub router
+func SetAccessLogOptions(opts ...sdmiddleware.LoggingOption) ServerOption {
+	return func(s *Server) {
+		s.accessLogOpts = opts
+	}
+}
+
+// SetPrincipalFunc sets the function used to add the authenticated principal to request scoped loggers
+func SetPrincipalFunc(f PrincipalFunc) ServerOption {
+	return func(s *Server) {
+		s.principal = f
+	}
+}
+
 // ServeHTTP satisfies the http.Handler interface to allow for handling of errors from handlers in one place
 func (e *ErrHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := e.Handler(w, r)
@@ -183,18 +246,29 @@ func (e *ErrHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	var ce ClientError
 	if errors.As(err, &ce) {
-		w.WriteHeader(ce.Code())
-		w.Write([]byte(ce.Body()))
+		writeClientError(w, r, ce)
 		return
 	}
 
-	e.Logger.Error(fmt.Sprintf("status=%d, err=%v", http.StatusInternalServerError, err))
+	logger := e.Logger
+	if logger == nil {
+		logger = LoggerFrom(r.Context())
+	}
+
+	logger.Error(fmt.Sprintf("status=%d, err=%v", http.StatusInternalServerError, err))
 	w.WriteHeader(http.StatusInternalServerError)
 	w.Write([]byte(ErrInternalError.Error()))
 }
 
 // RegisterSubRouter creates a subrouter based on a path and a slice of routes. Any middlewares passed in will be mounted to the sub router
+// An invalid prefix or routes found by ValidateRoutes skip the sub router, and Serve returns every problem on
+// its error channel so invalid routes fail at startup.
 func (s *Server) RegisterSubRouter(prefix string, routes []Route, middleware ...func(http.Handler) http.Handler) *Server {
+	if err := errors.Join(validatePrefix(prefix), ValidateRoutes(routes)); err != nil {
+		s.registerErr = errors.Join(s.registerErr, fmt.Errorf("registering %s: %w", prefix, err))
+		return s
+	}
+
 	// HTTP Muxer requires the trailing slash for the prefix but hen we remove the slash in the strip prefix
 	var prefixWithSlash string
 	if strings.HasSuffix(prefix, "/") {
@@ -219,27 +293,50 @@ func (s *Server) RegisterSubRouter(prefix string, routes []Route, middleware ...
 
 	// wrap subrouter to catch all middleware and total metrics for the subrouter
 	for _, v := range routes {
+		handler := RequestLogger(s.Logger, fmt.Sprintf("%s %s%s", v.Method, stripped, v.Path), s.principal)(v.handler())
 		if s.traceShutdown != nil {
 			m := fmt.Sprintf("%v:%v", v.Path, v.Method)
-			subRouter.Handle(fmt.Sprintf("%s %s", v.Method, v.Path), otelhttp.NewHandler(v.Handler, m))
+			// the access log is written outside the span, so the span is recorded for it from inside
+			subRouter.Handle(fmt.Sprintf("%s %s", v.Method, v.Path), otelhttp.NewHandler(sdmiddleware.RecordSpan(handler), m))
 		} else {
-			subRouter.Handle(fmt.Sprintf("%s %s", v.Method, v.Path), v.Handler)
+			subRouter.Handle(fmt.Sprintf("%s %s", v.Method, v.Path), handler)
 		}
 	}
 
 	s.Exporter.Metrics = append(s.Exporter.Metrics, counter, hist)
 
-	s.Router.Handle(prefixWithSlash, sdmiddleware.Logging(sdmiddleware.CodeStats(http.StripPrefix(stripped, reqWrapped), counter, hist)))
+	accessLogOpts := append([]sdmiddleware.LoggingOption{sdmiddleware.SetLogger(s.Logger)}, s.accessLogOpts...)
+
+	s.Router.Handle(prefixWithSlash, sdmiddleware.Logging(sdmiddleware.CodeStats(http.StripPrefix(stripped, reqWrapped), counter, hist), accessLogOpts...))
 
 	return s
 }
 
-// Serve starts the http.Server
+// Serve starts the http.Server. It sends the problems found by RegisterSubRouter to errChan instead of starting.
 func (s *Server) Serve(errChan chan<- error) {
+	if s.registerErr != nil {
+		errChan <- s.registerErr
+		return
+	}
+
 	prometheus.MustRegister(s.Exporter.Metrics...)
 
-	s.Logger.Info(fmt.Sprintf("starting HTTP server on %s", s.apiServer.Addr))
-	if err := s.apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	ln, err := s.listen()
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	handler := s.apiServer.Handler
+	s.apiServer.Handler = s.protocolHandler(handler)
+	s.serveHTTP3(handler, errChan)
+
+	s.Logger.Info(fmt.Sprintf("starting HTTP server on %s", ln.Addr()))
+	if err := s.apiServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		errChan <- err
 	}
 }
@@ -258,11 +355,24 @@ func (s *Server) AutoHandleErrors(ctx context.Context, errChan <-chan error) {
 	}()
 
 	sigTerm := make(chan os.Signal, 1)
-	signal.Notify(sigTerm, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
-	sig := <-sigTerm
-	s.Logger.Info(fmt.Sprintf("received signal: %s", sig))
-	s.ShutdownServer(ctx)
+	signals := []os.Signal{os.Interrupt, syscall.SIGINT, syscall.SIGTERM}
+	if s.gracefulRestart {
+		signals = append(signals, restartSignal)
+	}
+	signal.Notify(sigTerm, signals...)
+
+	for sig := range sigTerm {
+		s.Logger.Info(fmt.Sprintf("received signal: %s", sig))
+		if sig == restartSignal {
+			// without a new process to take over, keep serving on the current listener
+			if _, err := s.Restart(); err != nil {
+				s.Logger.Error(fmt.Sprintf("error restarting server, still serving: %v", err))
+				continue
+			}
+		}
+		s.ShutdownServer(ctx)
+		return
+	}
 }
 
 func (s *Server) ShutdownServer(ctx context.Context) {
@@ -276,6 +386,15 @@ func (s *Server) ShutdownServer(ctx context.Context) {
 		}
 	}
 
+	s.mu.Lock()
+	h3 := s.http3
+	s.mu.Unlock()
+	if h3 != nil {
+		if err := h3.Close(); err != nil {
+			s.Logger.Error(fmt.Sprintf("error stopping HTTP/3 server: %v\n", err))
+		}
+	}
+
 	if err := s.apiServer.Shutdown(ctx); err != nil {
 		s.Logger.Error(fmt.Sprintf("error shutting down server: %v\n", err))
 	}
@@ -15,16 +15,22 @@
 package http
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"golang.org/x/net/http2"
 )
 
 var (
@@ -207,3 +213,198 @@ func TestJsonHandlerServeHTTP(t *testing.T) {
 	}
 
 }
+
+func TestListenReusePort(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SO_REUSEPORT is not supported on windows")
+	}
+
+	first := NewHTTPServer(SetReusePort(true))
+	first.apiServer.Addr = "127.0.0.1:0"
+	ln, err := first.listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	second := NewHTTPServer(SetReusePort(true))
+	second.apiServer.Addr = ln.Addr().String()
+	ln2, err := second.listen()
+	if err != nil {
+		t.Fatalf("expected second listener to bind %s but got %v", ln.Addr(), err)
+	}
+	ln2.Close()
+}
+
+func TestProtocolHandler(t *testing.T) {
+	tt := []struct {
+		name   string
+		opts   []ServerOption
+		h2c    bool
+		altSvc string
+	}{
+		{name: "http1"},
+		{name: "h2c", opts: []ServerOption{SetH2C(true)}, h2c: true},
+		{name: "alt-svc", opts: []ServerOption{SetHTTP3(8443, func(string, http.Handler) HTTP3Server { return nil })}, altSvc: `h3=":8443"; ma=86400`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			s := NewHTTPServer(v.opts...)
+
+			h := s.protocolHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(r.Proto))
+			}))
+			ts := httptest.NewServer(h)
+			defer ts.Close()
+
+			client := ts.Client()
+			if v.h2c {
+				client.Transport = &http2.Transport{
+					AllowHTTP: true,
+					DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+						return net.Dial(network, addr)
+					},
+				}
+			}
+
+			resp, err := client.Get(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.ProtoMajor == 2 != v.h2c {
+				t.Errorf("expected HTTP/2 to be %v but got %s", v.h2c, resp.Proto)
+			}
+			if got := resp.Header.Get("Alt-Svc"); got != v.altSvc {
+				t.Errorf("expected Alt-Svc %q but got %q", v.altSvc, got)
+			}
+		})
+	}
+}
+
+func TestRouteHandler(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sleep") != "" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(r.Header.Get("X-Order")))
+	})
+
+	order := func(s string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("X-Order", r.Header.Get("X-Order")+s)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	tt := []struct {
+		name   string
+		route  Route
+		target string
+		body   string
+		code   int
+		want   string
+	}{
+		{name: "plain", route: Route{Handler: echo}, target: "/", code: http.StatusOK},
+		{name: "middleware order", route: Route{Handler: echo, Middlewares: []func(http.Handler) http.Handler{order("a"), order("b")}}, target: "/", code: http.StatusOK, want: "ab"},
+		{name: "body limit", route: Route{Handler: echo, MaxBodyBytes: 4}, target: "/", body: "too long", code: http.StatusRequestEntityTooLarge},
+		{name: "timeout", route: Route{Handler: echo, Timeout: 10 * time.Millisecond}, target: "/?sleep=1", code: http.StatusServiceUnavailable, want: http.StatusText(http.StatusServiceUnavailable)},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			v.route.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, v.target, strings.NewReader(v.body)))
+
+			if w.Code != v.code {
+				t.Errorf("expected status %d but got %d", v.code, w.Code)
+			}
+			if w.Body.String() != v.want {
+				t.Errorf("expected body %q but got %q", v.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	tt := []struct {
+		name        string
+		accept      string
+		code        int
+		contentType string
+	}{
+		{name: "default", code: http.StatusOK, contentType: "application/json"},
+		{name: "msgpack", accept: "application/msgpack", code: http.StatusOK, contentType: "application/msgpack"},
+		{name: "fallback", accept: "application/x-protobuf, application/json;q=0.5", code: http.StatusOK, contentType: "application/json"},
+		{name: "not acceptable", accept: "text/csv", code: http.StatusNotAcceptable},
+		{name: "cannot encode", accept: "application/x-protobuf", code: http.StatusInternalServerError},
+		// XML can't encode a map, so msgpack is the first acceptable codec left once JSON is excluded
+		{name: "excluded", accept: "*/*, application/json;q=0", code: http.StatusOK, contentType: "application/msgpack"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			h := &ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return Respond(w, r, http.StatusOK, map[string]string{"hello": "world"})
+			}}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", v.accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != v.code {
+				t.Errorf("expected status %d but got %d", v.code, w.Code)
+			}
+			if v.contentType != "" && w.Header().Get("Content-Type") != v.contentType {
+				t.Errorf("expected content type %s but got %s", v.contentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestClientErrorXML(t *testing.T) {
+	h := &ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		return sderrors.NewClientError(fmt.Errorf("bad <input>"), http.StatusBadRequest)
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<errors><error>bad &lt;input&gt;</error></errors>`
+	if w.Body.String() != want || w.Header().Get("Content-Type") != "application/xml" {
+		t.Errorf("expected %s but got %s", want, w.Body.String())
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	items := make(chan int)
+	go func() {
+		defer close(items)
+		for i := 1; i <= 3; i++ {
+			items <- i
+		}
+	}()
+
+	w := httptest.NewRecorder()
+	if err := StreamNDJSON(w, httptest.NewRequest(http.MethodGet, "/", nil), items); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Body.String() != "1\n2\n3\n" || !w.Flushed {
+		t.Errorf("expected flushed records but got %q", w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("expected NDJSON content type but got %s", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ShadowHeader is set on mirrored requests so the shadow service can tell them apart
+const ShadowHeader = "Sencillo-Shadow"
+
+// ShadowFunc sends a mirrored request. Its response is discarded.
+type ShadowFunc func(ctx context.Context, r *http.Request, body []byte) error
+
+// Publisher publishes a NATS message. *nats.Conn implements it.
+type Publisher interface {
+	PublishMsg(msg *nats.Msg) error
+}
+
+// ShadowOpt is a functional option to modify the Shadow
+type ShadowOpt func(*Shadow)
+
+// Shadow mirrors a sample of requests to a shadow target in the background
+type Shadow struct {
+	send    ShadowFunc
+	percent float64
+	scrub   func(r *http.Request, body []byte) []byte
+	maxBody int64
+	timeout time.Duration
+	sem     chan struct{}
+	logger  *slog.Logger
+	sample  func() float64
+}
+
+// NewShadow mirrors every request with send by default. Credentials in the Authorization, Proxy-Authorization,
+// Cookie, X-Api-Key, and request signing headers are always removed from the mirrored request.
+func NewShadow(send ShadowFunc, opts ...ShadowOpt) *Shadow {
+	s := &Shadow{
+		send:    send,
+		percent: 100,
+		scrub:   func(r *http.Request, body []byte) []byte { return body },
+		maxBody: 1 << 20,
+		timeout: 10 * time.Second,
+		sem:     make(chan struct{}, 100),
+		logger:  slog.Default(),
+		sample:  rand.Float64,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// SetShadowPercent sets the percentage of requests that are mirrored
+func SetShadowPercent(p float64) ShadowOpt {
+	return func(s *Shadow) {
+		s.percent = p
+	}
+}
+
+// SetShadowScrubber sets a hook that removes sensitive data before a request is mirrored. It runs after the
+// Authorization and Cookie headers are removed, can modify the copied request's headers, and returns the body to
+// send.
+func SetShadowScrubber(f func(r *http.Request, body []byte) []byte) ShadowOpt {
+	return func(s *Shadow) {
+		s.scrub = f
+	}
+}
+
+// SetShadowMaxBody sets the largest body that is mirrored. Larger requests are not mirrored.
+func SetShadowMaxBody(n int64) ShadowOpt {
+	return func(s *Shadow) {
+		s.maxBody = n
+	}
+}
+
+// SetShadowTimeout sets the deadline for sending a mirrored request
+func SetShadowTimeout(d time.Duration) ShadowOpt {
+	return func(s *Shadow) {
+		s.timeout = d
+	}
+}
+
+// SetShadowConcurrency sets how many mirrored requests can be in flight. Requests are not mirrored while
+// the limit is reached so a slow shadow target never backs up production traffic.
+func SetShadowConcurrency(n int) ShadowOpt {
+	return func(s *Shadow) {
+		if n > 0 {
+			s.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// SetShadowLogger sets the logger used to report failed mirrors
+func SetShadowLogger(l *slog.Logger) ShadowOpt {
+	return func(s *Shadow) {
+		s.logger = l
+	}
+}
+
+// ShadowToURL mirrors requests to the same path and query on the base URL
+func ShadowToURL(base string, client *http.Client) (ShadowFunc, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, r *http.Request, body []byte) error {
+		target := *u
+		target.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(r.URL.Path, "/")
+		target.RawQuery = r.URL.RawQuery
+
+		req, err := http.NewRequestWithContext(ctx, r.Method, target.String(), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header = r.Header
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		return resp.Body.Close()
+	}, nil
+}
+
+// ShadowToSubject publishes mirrored requests to the subject without waiting for a reply
+func ShadowToSubject(nc Publisher, subject string) ShadowFunc {
+	return func(ctx context.Context, r *http.Request, body []byte) error {
+		msg := nats.NewMsg(subject)
+		msg.Data = body
+		for k, v := range r.Header {
+			msg.Header[k] = v
+		}
+		msg.Header.Set(bridgeQueryHeader, r.URL.RawQuery)
+
+		return nc.PublishMsg(msg)
+	}
+}
+
+// Middleware mirrors a sample of the requests passing through it. The request is passed on unchanged.
+func (s *Shadow) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.sample()*100 >= s.percent || r.ContentLength > s.maxBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case s.sem <- struct{}{}:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, s.maxBody+1))
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if err != nil || int64(len(body)) > s.maxBody {
+			<-s.sem
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mirror := r.Clone(context.WithoutCancel(r.Context()))
+		mirror.Body = http.NoBody
+		mirror.Header.Set(ShadowHeader, "true")
+		scrubCredentials(mirror)
+		mirrorBody := s.scrub(mirror, bytes.Clone(body))
+
+		go func() {
+			defer func() { <-s.sem }()
+
+			ctx, cancel := context.WithTimeout(mirror.Context(), s.timeout)
+			defer cancel()
+
+			if err := s.send(ctx, mirror, mirrorBody); err != nil {
+				s.logger.Warn(fmt.Sprintf("error mirroring %s %s: %v", r.Method, r.URL.Path, err))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// credentialHeaders are removed from every mirrored request
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// signatureHeaderPrefix matches the request signing headers, which would let the shadow replay the request
+const signatureHeaderPrefix = "X-Sencillo-Signature"
+
+func scrubCredentials(r *http.Request) {
+	for _, v := range credentialHeaders {
+		r.Header.Del(v)
+	}
+	for k := range r.Header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(k), signatureHeaderPrefix) {
+			r.Header.Del(k)
+		}
+	}
+}
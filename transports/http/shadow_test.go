@@ -0,0 +1,155 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type shadowed struct {
+	path   string
+	auth   string
+	shadow string
+	body   string
+}
+
+func TestShadow(t *testing.T) {
+	tt := []struct {
+		name   string
+		opts   []ShadowOpt
+		body   string
+		mirror bool
+		want   shadowed
+	}{
+		{name: "mirrors", body: "hello", mirror: true, want: shadowed{path: "/v2/orders", shadow: "true", body: "hello"}},
+		{name: "sampled out", opts: []ShadowOpt{SetShadowPercent(0)}, body: "hello"},
+		{name: "too large", opts: []ShadowOpt{SetShadowMaxBody(3)}, body: "hello"},
+		{
+			name: "scrubber",
+			opts: []ShadowOpt{SetShadowScrubber(func(r *http.Request, body []byte) []byte {
+				return []byte(strings.ReplaceAll(string(body), "secret", "***"))
+			})},
+			body:   "my secret",
+			mirror: true,
+			want:   shadowed{path: "/v2/orders", shadow: "true", body: "my ***"},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			got := make(chan shadowed, 1)
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				var auth []string
+				for _, h := range []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Sencillo-Signature", "X-Sencillo-Signature-Key"} {
+					if r.Header.Get(h) != "" {
+						auth = append(auth, h)
+					}
+				}
+				got <- shadowed{path: r.URL.Path, auth: strings.Join(auth, ","), shadow: r.Header.Get(ShadowHeader), body: string(b)}
+			}))
+			defer target.Close()
+
+			send, err := ShadowToURL(target.URL+"/v2", target.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewShadow(send, v.opts...)
+
+			var mainBody string
+			h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				mainBody = string(b)
+			}))
+
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(v.body))
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+			req.Header.Set("Cookie", "session=abc")
+			req.Header.Set("X-Api-Key", "key")
+			req.Header.Set("X-Sencillo-Signature", "sig")
+			req.Header.Set("X-Sencillo-Signature-Key", "key-1")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if mainBody != v.body {
+				t.Errorf("expected the handler to get %q but got %q", v.body, mainBody)
+			}
+
+			select {
+			case m := <-got:
+				if !v.mirror {
+					t.Fatalf("expected no mirror but got %+v", m)
+				}
+				if m != v.want {
+					t.Errorf("expected %+v but got %+v", v.want, m)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if v.mirror {
+					t.Fatal("timed out waiting for the mirror")
+				}
+			}
+		})
+	}
+}
+
+type fakePublisher chan *nats.Msg
+
+func (f fakePublisher) PublishMsg(msg *nats.Msg) error {
+	f <- msg
+	return nil
+}
+
+func TestShadowToSubject(t *testing.T) {
+	pub := make(fakePublisher, 1)
+	s := NewShadow(ShadowToSubject(pub, "orders.shadow"), SetShadowConcurrency(1))
+	h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders?limit=1", strings.NewReader("hi")))
+
+	msg := <-pub
+	if msg.Subject != "orders.shadow" || string(msg.Data) != "hi" || msg.Header.Get(bridgeQueryHeader) != "limit=1" {
+		t.Errorf("unexpected message %s %q %v", msg.Subject, msg.Data, msg.Header)
+	}
+}
+
+func TestShadowConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	sent := make(chan struct{}, 2)
+	s := NewShadow(func(ctx context.Context, r *http.Request, body []byte) error {
+		sent <- struct{}{}
+		<-release
+		return nil
+	}, SetShadowConcurrency(1))
+	h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-sent
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	close(release)
+
+	select {
+	case <-sent:
+		t.Error("expected the second request not to be mirrored while the first was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidRoute   = fmt.Errorf("invalid route")
+	ErrDuplicateRoute = fmt.Errorf("duplicate route")
+	ErrAmbiguousRoute = fmt.Errorf("ambiguous routes")
+)
+
+// segment is one slash separated part of a route path. A trailing slash is a multi segment matching the rest of
+// the path and {$} is the literal "/".
+type segment struct {
+	literal  string
+	wildcard bool
+	multi    bool
+}
+
+type routePattern struct {
+	method   string
+	path     string
+	segments []segment
+}
+
+// parseRoute checks the method and path of a route against the syntax http.ServeMux accepts, with clearer
+// errors than the mux's registration panics
+func parseRoute(method, path string) (routePattern, error) {
+	p := routePattern{method: method, path: path}
+
+	for _, r := range method {
+		if !unicode.IsUpper(r) {
+			return p, fmt.Errorf("%w: method %q must be upper case letters", ErrInvalidRoute, method)
+		}
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return p, fmt.Errorf("%w: path %q must start with /", ErrInvalidRoute, path)
+	}
+	if i := strings.IndexFunc(path, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) || r == '?' || r == '#' }); i >= 0 {
+		return p, fmt.Errorf("%w: path %q contains %q", ErrInvalidRoute, path, path[i])
+	}
+
+	parts := strings.Split(path[1:], "/")
+	names := make(map[string]bool)
+	for i, part := range parts {
+		last := i == len(parts)-1
+		if part == "" {
+			if !last {
+				return p, fmt.Errorf("%w: path %q has an empty segment", ErrInvalidRoute, path)
+			}
+			p.segments = append(p.segments, segment{multi: true})
+			continue
+		}
+
+		if !strings.ContainsAny(part, "{}") {
+			p.segments = append(p.segments, segment{literal: part})
+			continue
+		}
+
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			return p, fmt.Errorf("%w: path %q has a wildcard that is not a whole segment in %q", ErrInvalidRoute, path, part)
+		}
+
+		name := part[1 : len(part)-1]
+		if name == "$" {
+			if !last {
+				return p, fmt.Errorf("%w: path %q uses {$} before the end", ErrInvalidRoute, path)
+			}
+			p.segments = append(p.segments, segment{literal: "/"})
+			continue
+		}
+
+		s := segment{wildcard: true}
+		if n, ok := strings.CutSuffix(name, "..."); ok {
+			if !last {
+				return p, fmt.Errorf("%w: path %q uses {%s} before the last segment", ErrInvalidRoute, path, name)
+			}
+			name, s.multi = n, true
+		}
+
+		if !token.IsIdentifier(name) {
+			return p, fmt.Errorf("%w: path %q has an invalid wildcard name %q", ErrInvalidRoute, path, name)
+		}
+		if names[name] {
+			return p, fmt.Errorf("%w: path %q uses the wildcard {%s} twice", ErrInvalidRoute, path, name)
+		}
+		names[name] = true
+
+		p.segments = append(p.segments, s)
+	}
+
+	return p, nil
+}
+
+// relationship is how the sets of requests two patterns match compare, following http.ServeMux's precedence
+// rules
+type relationship int
+
+const (
+	equivalent relationship = iota
+	moreGeneral
+	moreSpecific
+	overlaps
+	disjoint
+)
+
+func combine(r1, r2 relationship) relationship {
+	switch {
+	case r1 == equivalent:
+		return r2
+	case r2 == equivalent:
+		return r1
+	case r1 == disjoint || r2 == disjoint:
+		return disjoint
+	case r1 == r2:
+		return r1
+	}
+
+	return overlaps
+}
+
+func compareMethods(m1, m2 string) relationship {
+	switch {
+	case m1 == m2:
+		return equivalent
+	case m1 == "":
+		return moreGeneral
+	case m2 == "":
+		return moreSpecific
+	case m1 == http.MethodGet && m2 == http.MethodHead:
+		return moreGeneral
+	case m1 == http.MethodHead && m2 == http.MethodGet:
+		return moreSpecific
+	}
+
+	return disjoint
+}
+
+func compareSegments(s1, s2 segment) relationship {
+	switch {
+	case s1.multi && s2.multi:
+		return equivalent
+	case s1.multi:
+		return moreGeneral
+	case s2.multi:
+		return moreSpecific
+	case s1.wildcard && s2.wildcard:
+		return equivalent
+	case s1.wildcard:
+		// a single wildcard doesn't match the trailing slash of {$}
+		if s2.literal == "/" {
+			return disjoint
+		}
+		return moreGeneral
+	case s2.wildcard:
+		if s1.literal == "/" {
+			return disjoint
+		}
+		return moreSpecific
+	case s1.literal == s2.literal:
+		return equivalent
+	}
+
+	return disjoint
+}
+
+func (p routePattern) compare(o routePattern) relationship {
+	rel := compareMethods(p.method, o.method)
+
+	s1, s2 := p.segments, o.segments
+	for ; len(s1) > 0 && len(s2) > 0 && rel != disjoint; s1, s2 = s1[1:], s2[1:] {
+		rel = combine(rel, compareSegments(s1[0], s2[0]))
+	}
+
+	switch {
+	case rel == disjoint || (len(s1) == 0 && len(s2) == 0):
+		return rel
+	case len(s1) == 0 && p.segments[len(p.segments)-1].multi:
+		return combine(rel, moreGeneral)
+	case len(s2) == 0 && o.segments[len(o.segments)-1].multi:
+		return combine(rel, moreSpecific)
+	}
+
+	return disjoint
+}
+
+// conflict returns an error when both patterns match the same requests and neither is more specific, which
+// http.ServeMux can't resolve
+func (p routePattern) conflict(o routePattern) error {
+	switch p.compare(o) {
+	case equivalent:
+		return fmt.Errorf("%w: %s and %s", ErrDuplicateRoute, p, o)
+	case overlaps:
+		return fmt.Errorf("%w: %s and %s match some of the same requests and neither is more specific", ErrAmbiguousRoute, p, o)
+	}
+
+	return nil
+}
+
+func (p routePattern) String() string {
+	return strings.TrimSpace(p.method + " " + p.path)
+}
+
+// validatePrefix checks a sub router prefix, which can't contain wildcards because it is stripped from the path
+func validatePrefix(prefix string) error {
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("%w: prefix %q must start with /", ErrInvalidRoute, prefix)
+	}
+	if strings.ContainsAny(prefix, "{} ?#") {
+		return fmt.Errorf("%w: prefix %q can't contain wildcards, spaces, ? or #", ErrInvalidRoute, prefix)
+	}
+
+	return nil
+}
+
+// ValidateRoutes checks every route's method and path and that no two routes are duplicates or ambiguous once
+// wildcard names are ignored. Every problem is returned, not just the first.
+func ValidateRoutes(routes []Route) error {
+	var errs []error
+	var patterns []routePattern
+	for _, v := range routes {
+		p, err := parseRoute(v.Method, v.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, prev := range patterns {
+			if err := prev.conflict(p); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		patterns = append(patterns, p)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	h := http.NotFoundHandler()
+	tt := []struct {
+		name   string
+		routes []Route
+		want   []error
+	}{
+		{name: "valid", routes: []Route{{Method: "GET", Path: "/users/{id}"}, {Method: "GET", Path: "/users/me"}, {Method: "POST", Path: "/users/{id}"}, {Path: "/files/{path...}"}, {Method: "GET", Path: "/{$}"}}},
+		{name: "lower case method", routes: []Route{{Method: "get", Path: "/users"}}, want: []error{ErrInvalidRoute}},
+		{name: "relative path", routes: []Route{{Method: "GET", Path: "users"}}, want: []error{ErrInvalidRoute}},
+		{name: "query", routes: []Route{{Method: "GET", Path: "/users?limit=1"}}, want: []error{ErrInvalidRoute}},
+		{name: "partial wildcard", routes: []Route{{Method: "GET", Path: "/users/id-{id}"}}, want: []error{ErrInvalidRoute}},
+		{name: "invalid wildcard name", routes: []Route{{Method: "GET", Path: "/users/{user-id}"}}, want: []error{ErrInvalidRoute}},
+		{name: "repeated wildcard", routes: []Route{{Method: "GET", Path: "/{id}/{id}"}}, want: []error{ErrInvalidRoute}},
+		{name: "multi wildcard not last", routes: []Route{{Method: "GET", Path: "/{rest...}/x"}}, want: []error{ErrInvalidRoute}},
+		{name: "empty segment", routes: []Route{{Method: "GET", Path: "/users//posts"}}, want: []error{ErrInvalidRoute}},
+		{name: "duplicate", routes: []Route{{Method: "GET", Path: "/users/{id}"}, {Method: "GET", Path: "/users/{name}"}}, want: []error{ErrDuplicateRoute}},
+		{name: "duplicate subtree", routes: []Route{{Method: "GET", Path: "/static/"}, {Method: "GET", Path: "/static/"}}, want: []error{ErrDuplicateRoute}},
+		{name: "ambiguous", routes: []Route{{Method: "GET", Path: "/users/{id}/posts"}, {Method: "GET", Path: "/users/me/{kind}"}}, want: []error{ErrAmbiguousRoute}},
+		{name: "all problems", routes: []Route{{Method: "get", Path: "/a"}, {Method: "GET", Path: "b"}, {Method: "GET", Path: "/c"}, {Method: "GET", Path: "/c"}}, want: []error{ErrInvalidRoute, ErrInvalidRoute, ErrDuplicateRoute}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for i := range v.routes {
+				v.routes[i].Handler = h
+			}
+
+			err := ValidateRoutes(v.routes)
+			var got []error
+			if err != nil {
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					for _, target := range []error{ErrInvalidRoute, ErrDuplicateRoute, ErrAmbiguousRoute} {
+						if errors.Is(e, target) {
+							got = append(got, target)
+						}
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %v but got %v", v.want, err)
+			}
+		})
+	}
+}
+
+func TestRegisterSubRouterInvalid(t *testing.T) {
+	tt := []struct {
+		name   string
+		prefix string
+		path   string
+		want   string
+	}{
+		{name: "route", prefix: "/api", path: "users", want: `"users" must start with /`},
+		{name: "prefix", prefix: "api", path: "/users", want: `prefix "api" must start with /`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			s := NewHTTPServer().RegisterSubRouter(v.prefix, []Route{{Method: "GET", Path: v.path, Handler: http.NotFoundHandler()}})
+
+			errChan := make(chan error, 1)
+			s.Serve(errChan)
+
+			err := <-errChan
+			if !errors.Is(err, ErrInvalidRoute) || !strings.Contains(err.Error(), v.want) {
+				t.Errorf("expected an error describing the invalid route, got %v", err)
+			}
+		})
+	}
+}
+
+// routePair generates two routes from a small alphabet so duplicates and conflicts are common
+type routePair [2]Route
+
+func (routePair) Generate(r *rand.Rand, size int) reflect.Value {
+	segments := []string{"a", "b", "{x}", "{y}", "{z...}", "{$}", "", "{x}"}
+	methods := []string{"GET", "POST", ""}
+
+	var pair routePair
+	for i := range pair {
+		var parts []string
+		for n := r.Intn(4); n >= 0; n-- {
+			parts = append(parts, segments[r.Intn(len(segments))])
+		}
+		pair[i] = Route{Method: methods[r.Intn(len(methods))], Path: "/" + strings.Join(parts, "/"), Handler: http.NotFoundHandler()}
+	}
+
+	return reflect.ValueOf(pair)
+}
+
+// registers reports whether http.ServeMux accepts both routes
+func registers(routes ...Route) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	mux := http.NewServeMux()
+	for _, v := range routes {
+		mux.Handle(strings.TrimSpace(v.Method+" "+v.Path), v.Handler)
+	}
+
+	return true
+}
+
+func TestValidateRoutesMatchesServeMux(t *testing.T) {
+	// ValidateRoutes accepts exactly the routes http.ServeMux accepts, except for paths with empty segments. The
+	// mux registers those but cleans // out of request paths, so they never match.
+	property := func(pair routePair) bool {
+		if strings.Contains(pair[0].Path, "//") || strings.Contains(pair[1].Path, "//") {
+			return ValidateRoutes(pair[:]) != nil
+		}
+		return (ValidateRoutes(pair[:]) == nil) == registers(pair[:]...)
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConflictSymmetric(t *testing.T) {
+	property := func(pair routePair) bool {
+		a, errA := parseRoute(pair[0].Method, pair[0].Path)
+		b, errB := parseRoute(pair[1].Method, pair[1].Path)
+		if errA != nil || errB != nil {
+			return true
+		}
+
+		return (a.conflict(b) == nil) == (b.conflict(a) == nil) && a.conflict(a) != nil
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Error(err)
+	}
+}
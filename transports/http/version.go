@@ -0,0 +1,107 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/SencilloDev/sencillo-go/versioned"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Headers used to negotiate the API version
+const (
+	AcceptVersionHeader  = "Accept-Version"
+	ContentVersionHeader = "Content-Version"
+)
+
+// Versions routes requests to a handler by the API version in the Accept-Version header
+type Versions struct {
+	api *versioned.API
+	now func() time.Time
+
+	requests *prometheus.CounterVec
+}
+
+// NewVersions returns Versions for the API
+func NewVersions(api *versioned.API) *Versions {
+	return &Versions{
+		api:      api,
+		now:      time.Now,
+		requests: metrics.NewCounterVec("http_api_version_requests", "HTTP requests by API version and outcome", []string{"version", "outcome"}),
+	}
+}
+
+// Metrics returns the collectors to register with the exporter
+func (v *Versions) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{v.requests}
+}
+
+// Handler returns a handler serving each version with the newest handler registered at or before it, so a
+// handler only needs registering for the versions that changed the route. Requests without Accept-Version get
+// the API's default. Unknown versions get a 400 and retired ones a 410. Responses name the version served in
+// Content-Version, and deprecated versions add Deprecation and Sunset headers. It panics if a handler is
+// registered for a version the API doesn't have.
+func (v *Versions) Handler(handlers map[string]http.Handler) http.Handler {
+	var unknown []string
+	for name := range handlers {
+		if _, err := v.api.Lookup(name, time.Time{}); errors.Is(err, versioned.ErrUnknownVersion) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		panic(fmt.Sprintf("%v: %s", versioned.ErrUnknownVersion, strings.Join(unknown, ", ")))
+	}
+
+	return &ErrHandler{Handler: func(w http.ResponseWriter, r *http.Request) error {
+		version, err := v.api.Lookup(strings.TrimSpace(r.Header.Get(AcceptVersionHeader)), v.now())
+		switch {
+		case errors.Is(err, versioned.ErrUnknownVersion):
+			v.requests.WithLabelValues("unknown", "unknown").Inc()
+			return sderrors.NewClientError(err, http.StatusBadRequest)
+		case errors.Is(err, versioned.ErrVersionSunset):
+			v.requests.WithLabelValues(version.Name, "retired").Inc()
+			return sderrors.NewClientError(err, http.StatusGone)
+		}
+
+		w.Header().Add("Vary", AcceptVersionHeader)
+		w.Header().Set(ContentVersionHeader, version.Name)
+		if version.Deprecated {
+			w.Header().Set("Deprecation", "true")
+			if !version.Sunset.IsZero() {
+				w.Header().Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+
+		served, err := v.api.Resolve(version.Name, func(name string) bool { return handlers[name] != nil })
+		if err != nil {
+			v.requests.WithLabelValues(version.Name, "unhandled").Inc()
+			return sderrors.NewClientError(err, http.StatusNotFound)
+		}
+		outcome := "served"
+		if version.Deprecated {
+			outcome = "deprecated"
+		}
+		v.requests.WithLabelValues(version.Name, outcome).Inc()
+		handlers[served].ServeHTTP(w, r)
+		return nil
+	}}
+}
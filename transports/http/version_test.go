@@ -0,0 +1,105 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/SencilloDev/sencillo-go/versioned"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestVersions(t *testing.T) {
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	v := NewVersions(versioned.NewAPI(
+		versioned.APIVersion{Name: "2024-01-01", Deprecated: true, Sunset: sunset},
+		versioned.APIVersion{Name: "2025-01-01"},
+		versioned.APIVersion{Name: "2025-06-01"},
+	))
+	v.now = func() time.Time { return sunset.Add(-time.Hour) }
+	reg := sdtest.NewRegistry(t, v.Metrics()...)
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) })
+	}
+	s := NewHTTPServer()
+	s.RegisterSubRouter("/api", []Route{{Method: http.MethodGet, Path: "/orders", Handler: v.Handler(map[string]http.Handler{
+		"2024-01-01": respond("old"),
+		"2025-06-01": respond("new"),
+	})}})
+
+	tt := []struct {
+		name       string
+		version    string
+		now        time.Time
+		code       int
+		body       string
+		served     string
+		deprecated bool
+	}{
+		{name: "default", code: http.StatusOK, body: "new", served: "2025-06-01"},
+		{name: "deprecated", version: "2024-01-01", code: http.StatusOK, body: "old", served: "2024-01-01", deprecated: true},
+		{name: "unchanged version", version: "2025-01-01", code: http.StatusOK, body: "old", served: "2025-01-01"},
+		{name: "unknown", version: "2099-01-01", code: http.StatusBadRequest},
+		{name: "retired", version: "2024-01-01", now: sunset, code: http.StatusGone},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.now.IsZero() {
+				v.now = func() time.Time { return tc.now }
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/orders", nil)
+			if tc.version != "" {
+				req.Header.Set(AcceptVersionHeader, tc.version)
+			}
+			rec := httptest.NewRecorder()
+			s.Router.ServeHTTP(rec, req)
+			if rec.Code != tc.code {
+				t.Fatalf("expected %d but got %d: %s", tc.code, rec.Code, rec.Body.String())
+			}
+			if tc.code != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != tc.body {
+				t.Errorf("expected %q but got %q", tc.body, rec.Body.String())
+			}
+			if got := rec.Header().Get(ContentVersionHeader); got != tc.served {
+				t.Errorf("expected Content-Version %s but got %s", tc.served, got)
+			}
+			if deprecated := rec.Header().Get("Deprecation") == "true"; deprecated != tc.deprecated {
+				t.Errorf("expected deprecated to be %v", tc.deprecated)
+			}
+			if tc.deprecated && rec.Header().Get("Sunset") != "Tue, 30 Jun 2026 00:00:00 GMT" {
+				t.Errorf("unexpected Sunset %q", rec.Header().Get("Sunset"))
+			}
+		})
+	}
+	reg.AssertCounter(t, "http_api_version_requests", prometheus.Labels{"version": "2024-01-01", "outcome": "deprecated"}, 1)
+	reg.AssertCounter(t, "http_api_version_requests", prometheus.Labels{"version": "2024-01-01", "outcome": "retired"}, 1)
+	reg.AssertCounter(t, "http_api_version_requests", prometheus.Labels{"version": "2025-06-01", "outcome": "served"}, 1)
+}
+
+func TestVersionsUnknownHandler(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a handler registered to an unknown version")
+		}
+	}()
+	NewVersions(versioned.NewAPI(versioned.APIVersion{Name: "v1"})).Handler(map[string]http.Handler{"v2": http.NotFoundHandler()})
+}
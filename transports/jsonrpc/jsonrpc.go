@@ -0,0 +1,344 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	sdhttp "github.com/SencilloDev/sencillo-go/transports/http"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Error codes defined by the JSON-RPC 2.0 specification
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the start of the range reserved for server errors. Client errors are sent as
+	// CodeServerError minus the amount their status is over 400.
+	CodeServerError = -32000
+)
+
+// Error is a JSON-RPC error object. Methods can return one to choose the code and data sent to the caller.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// CodeFromStatus maps the HTTP status of a ClientError to an error code. 400 and 422 are invalid params, other 4xx
+// statuses count down from CodeServerError so 404 is -32004, and anything else is an internal error.
+func CodeFromStatus(status int) int {
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return CodeInvalidParams
+	case status > 400 && status < 500:
+		return CodeServerError - (status - 400)
+	}
+	return CodeInternalError
+}
+
+type request struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type method func(context.Context, json.RawMessage) (any, error)
+
+// ServerOpt is a functional option to modify the Server
+type ServerOpt func(*Server)
+
+// Server multiplexes JSON-RPC 2.0 methods over a single HTTP route or NATS endpoint
+type Server struct {
+	logger      *slog.Logger
+	maxBatch    int
+	concurrency int
+	maxBody     int64
+	mu          sync.RWMutex
+	methods     map[string]method
+}
+
+// NewServer returns a Server with no methods. Batches are limited to 100 requests run 10 at a time, and HTTP
+// bodies to 1MiB.
+func NewServer(opts ...ServerOpt) *Server {
+	s := &Server{
+		logger:      slog.Default(),
+		maxBatch:    100,
+		concurrency: 10,
+		maxBody:     1 << 20,
+		methods:     make(map[string]method),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// SetLogger sets the logger used for unexpected method errors
+func SetLogger(l *slog.Logger) ServerOpt {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// SetMaxBatch sets the most requests allowed in a batch. Larger batches get an invalid request error.
+func SetMaxBatch(n int) ServerOpt {
+	return func(s *Server) {
+		s.maxBatch = n
+	}
+}
+
+// SetConcurrency sets how many requests in a batch run at the same time
+func SetConcurrency(n int) ServerOpt {
+	return func(s *Server) {
+		s.concurrency = max(n, 1)
+	}
+}
+
+// SetMaxBodyBytes limits the size of HTTP request bodies
+func SetMaxBodyBytes(n int64) ServerOpt {
+	return func(s *Server) {
+		s.maxBody = n
+	}
+}
+
+// Register adds a typed method. Params are decoded into P, so by-name params decode into a struct and by-position
+// params into a slice or array. Registering a name twice replaces the method.
+func Register[P, R any](s *Server, name string, fn func(context.Context, P) (R, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.methods[name] = func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+			}
+		}
+		return fn(ctx, params)
+	}
+}
+
+// Handle processes a single request or a batch and returns the encoded response. It returns nil when there is
+// nothing to send back, which is the case for notifications.
+func (s *Server) Handle(ctx context.Context, data []byte) []byte {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return encode(errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()}))
+		}
+		if len(batch) == 0 {
+			return encode(errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "empty batch"}))
+		}
+		if s.maxBatch > 0 && len(batch) > s.maxBatch {
+			return encode(errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("batch exceeds %d requests", s.maxBatch)}))
+		}
+
+		results := make([]*response, len(batch))
+		sem := make(chan struct{}, s.concurrency)
+		var wg sync.WaitGroup
+		for i, v := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				results[i] = s.call(ctx, v)
+			}()
+		}
+		wg.Wait()
+
+		var responses []*response
+		for _, v := range results {
+			if v != nil {
+				responses = append(responses, v)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return encode(responses)
+	}
+
+	if !json.Valid(data) {
+		return encode(errorResponse(nil, &Error{Code: CodeParseError, Message: "invalid JSON"}))
+	}
+
+	resp := s.call(ctx, data)
+	if resp == nil {
+		return nil
+	}
+	return encode(resp)
+}
+
+// call runs a single request and returns nil for notifications
+func (s *Server) call(ctx context.Context, data json.RawMessage) (resp *response) {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil || req.Version != "2.0" || req.Method == "" || !validID(req.ID) {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+	notification := req.ID == nil
+
+	s.mu.RLock()
+	m, ok := s.methods[req.Method]
+	s.mu.RUnlock()
+	if !ok {
+		if notification {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error(fmt.Sprintf("jsonrpc method %s panicked: %v", req.Method, r))
+			resp = errorResponse(req.ID, &Error{Code: CodeInternalError, Message: sdhttp.ErrInternalError.Error()})
+			if notification {
+				resp = nil
+			}
+		}
+	}()
+
+	result, err := m(ctx, req.Params)
+	if notification {
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("jsonrpc notification %s failed: %v", req.Method, err))
+		}
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, s.toError(req.Method, err))
+	}
+
+	// a nil result still has to be sent, so it is encoded as null rather than omitted
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return &response{Version: "2.0", Result: result, ID: req.ID}
+}
+
+// toError maps a method error to an error object. Unexpected errors are logged and hidden from the caller.
+func (s *Server) toError(method string, err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	var ce sdhttp.ClientError
+	if errors.As(err, &ce) {
+		message := ce.Error()
+		if m, ok := ce.(interface{ Messages() []string }); ok {
+			message = strings.Join(m.Messages(), ", ")
+		}
+		return &Error{Code: CodeFromStatus(ce.Code()), Message: message, Data: map[string]int{"status": ce.Code()}}
+	}
+
+	s.logger.Error(fmt.Sprintf("jsonrpc method %s failed: %v", method, err))
+	return &Error{Code: CodeInternalError, Message: sdhttp.ErrInternalError.Error()}
+}
+
+// validID reports whether the id is absent, null, a string, or a number
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{Version: "2.0", Error: err, ID: id}
+}
+
+func encode(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(nil, &Error{Code: CodeInternalError, Message: err.Error()}))
+	}
+	return data
+}
+
+// Route returns a POST route at path serving every registered method. Notifications get a 204.
+func (s *Server) Route(path string) sdhttp.Route {
+	return sdhttp.Route{
+		Method: http.MethodPost,
+		Path:   path,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBody))
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			resp := s.Handle(r.Context(), data)
+			if resp == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(resp)
+		}),
+	}
+}
+
+// Handler returns a micro handler serving every registered method. Requests made only of notifications get an
+// empty response so the caller isn't left waiting.
+func (s *Server) Handler() micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		resp := s.Handle(context.Background(), r.Data())
+		r.Respond(resp, micro.WithHeaders(micro.Headers{"Content-Type": []string{"application/json"}}))
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type addParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func newTestServer(opts ...ServerOpt) (*Server, *atomic.Int32) {
+	s := NewServer(append([]ServerOpt{SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))}, opts...)...)
+	var logged atomic.Int32
+	Register(s, "add", func(ctx context.Context, p addParams) (int, error) {
+		return p.A + p.B, nil
+	})
+	Register(s, "sum", func(ctx context.Context, p []int) (int, error) {
+		var total int
+		for _, v := range p {
+			total += v
+		}
+		return total, nil
+	})
+	Register(s, "get", func(ctx context.Context, p struct{ ID string }) (any, error) {
+		return nil, sderrors.NewClientError(fmt.Errorf("order %s not found", p.ID), http.StatusNotFound)
+	})
+	Register(s, "divide", func(ctx context.Context, p addParams) (int, error) {
+		if p.B == 0 {
+			return 0, &Error{Code: 1001, Message: "division by zero"}
+		}
+		return p.A / p.B, nil
+	})
+	Register(s, "fail", func(ctx context.Context, p any) (any, error) {
+		return nil, fmt.Errorf("database password is hunter2")
+	})
+	Register(s, "panic", func(ctx context.Context, p any) (any, error) {
+		panic("nil map")
+	})
+	Register(s, "log", func(ctx context.Context, p string) (any, error) {
+		logged.Add(1)
+		return nil, nil
+	})
+
+	return s, &logged
+}
+
+func TestHandle(t *testing.T) {
+	tt := []struct {
+		name    string
+		request string
+		want    string
+		logged  int32
+	}{
+		{
+			name:    "by name",
+			request: `{"jsonrpc": "2.0", "method": "add", "params": {"a": 1, "b": 2}, "id": 1}`,
+			want:    `{"jsonrpc":"2.0","result":3,"id":1}`,
+		},
+		{
+			name:    "by position",
+			request: `{"jsonrpc": "2.0", "method": "sum", "params": [1, 2, 3], "id": "abc"}`,
+			want:    `{"jsonrpc":"2.0","result":6,"id":"abc"}`,
+		},
+		{
+			name:    "null result",
+			request: `{"jsonrpc": "2.0", "method": "log", "params": "hi", "id": 2}`,
+			want:    `{"jsonrpc":"2.0","result":null,"id":2}`,
+			logged:  1,
+		},
+		{
+			name:    "client error",
+			request: `{"jsonrpc": "2.0", "method": "get", "params": {"ID": "42"}, "id": 3}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32004,"message":"order 42 not found","data":{"status":404}},"id":3}`,
+		},
+		{
+			name:    "rpc error",
+			request: `{"jsonrpc": "2.0", "method": "divide", "params": {"a": 1, "b": 0}, "id": 4}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":1001,"message":"division by zero"},"id":4}`,
+		},
+		{
+			name:    "internal error",
+			request: `{"jsonrpc": "2.0", "method": "fail", "id": 5}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32603,"message":"internal server error"},"id":5}`,
+		},
+		{
+			name:    "panic",
+			request: `{"jsonrpc": "2.0", "method": "panic", "id": 6}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32603,"message":"internal server error"},"id":6}`,
+		},
+		{
+			name:    "invalid params",
+			request: `{"jsonrpc": "2.0", "method": "add", "params": [1, 2], "id": 7}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32602,"message":"json: cannot unmarshal array into Go value of type jsonrpc.addParams"},"id":7}`,
+		},
+		{
+			name:    "method not found",
+			request: `{"jsonrpc": "2.0", "method": "multiply", "id": 8}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method \"multiply\" not found"},"id":8}`,
+		},
+		{
+			name:    "wrong version",
+			request: `{"jsonrpc": "1.0", "method": "add", "id": 9}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":9}`,
+		},
+		{
+			name:    "invalid id",
+			request: `{"jsonrpc": "2.0", "method": "add", "id": {}}`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":{}}`,
+		},
+		{
+			name:    "parse error",
+			request: `{"jsonrpc": "2.0", "method"`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32700,"message":"invalid JSON"},"id":null}`,
+		},
+		{
+			name:    "notification",
+			request: `{"jsonrpc": "2.0", "method": "log", "params": "hi"}`,
+			logged:  1,
+		},
+		{
+			name:    "empty batch",
+			request: `[]`,
+			want:    `{"jsonrpc":"2.0","error":{"code":-32600,"message":"empty batch"},"id":null}`,
+		},
+		{
+			name: "batch",
+			request: `[
+				{"jsonrpc": "2.0", "method": "add", "params": {"a": 1, "b": 2}, "id": 1},
+				{"jsonrpc": "2.0", "method": "log", "params": "hi"},
+				{"jsonrpc": "2.0", "method": "multiply", "id": 2},
+				1
+			]`,
+			want:   `[{"jsonrpc":"2.0","result":3,"id":1},{"jsonrpc":"2.0","error":{"code":-32601,"message":"method \"multiply\" not found"},"id":2},{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}]`,
+			logged: 1,
+		},
+		{
+			name:    "batch of notifications",
+			request: `[{"jsonrpc": "2.0", "method": "log", "params": "a"}, {"jsonrpc": "2.0", "method": "log", "params": "b"}]`,
+			logged:  2,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			s, logged := newTestServer()
+			got := s.Handle(context.Background(), []byte(v.request))
+			if string(got) != v.want {
+				t.Errorf("expected %s but got %s", v.want, got)
+			}
+			if logged.Load() != v.logged {
+				t.Errorf("expected log to be called %d times but got %d", v.logged, logged.Load())
+			}
+		})
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	s, _ := newTestServer(SetMaxBatch(3), SetConcurrency(2))
+	var running, peak atomic.Int32
+	Register(s, "wait", func(ctx context.Context, p any) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	})
+
+	call := `{"jsonrpc": "2.0", "method": "wait", "id": 1}`
+	got := s.Handle(context.Background(), []byte("["+strings.Repeat(call+",", 3)+call+"]"))
+	want := `{"jsonrpc":"2.0","error":{"code":-32600,"message":"batch exceeds 3 requests"},"id":null}`
+	if string(got) != want {
+		t.Errorf("expected %s but got %s", want, got)
+	}
+
+	s.Handle(context.Background(), []byte("["+strings.Repeat(call+",", 2)+call+"]"))
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent requests but got %d", peak.Load())
+	}
+}
+
+func TestRoute(t *testing.T) {
+	s, _ := newTestServer(SetMaxBodyBytes(128))
+	route := s.Route("/rpc")
+
+	tt := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "call", body: `{"jsonrpc": "2.0", "method": "add", "params": {"a": 2, "b": 2}, "id": 1}`, wantStatus: 200, wantBody: `{"jsonrpc":"2.0","result":4,"id":1}`},
+		{name: "notification", body: `{"jsonrpc": "2.0", "method": "log", "params": "hi"}`, wantStatus: 204},
+		{name: "too large", body: `{"jsonrpc": "2.0", "method": "log", "params": "` + strings.Repeat("a", 128) + `"}`, wantStatus: 413},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			route.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewBufferString(v.body)))
+			if w.Code != v.wantStatus {
+				t.Errorf("expected status %d but got %d", v.wantStatus, w.Code)
+			}
+			if w.Body.String() != v.wantBody {
+				t.Errorf("expected body %s but got %s", v.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type fakeRequest struct {
+	micro.Request
+	data      []byte
+	responded []byte
+}
+
+func (f *fakeRequest) Data() []byte { return f.data }
+
+func (f *fakeRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	f.responded = data
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := newTestServer()
+	r := &fakeRequest{data: []byte(`[{"jsonrpc": "2.0", "method": "sum", "params": [1, 2], "id": 1}]`)}
+	s.Handler().Handle(r)
+
+	var got []map[string]any
+	if err := json.Unmarshal(r.responded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0]["result"] != float64(3) {
+		t.Errorf("unexpected response %s", r.responded)
+	}
+}
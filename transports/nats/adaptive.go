@@ -0,0 +1,57 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// outcomeRequest records whether the handler responded with a server error
+type outcomeRequest struct {
+	micro.Request
+	failed bool
+}
+
+func (o *outcomeRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	if c, err := strconv.Atoi(code); err != nil || c >= http.StatusInternalServerError {
+		o.failed = true
+	}
+	return o.Request.Error(code, description, data, opts...)
+}
+
+// AdaptiveLimit admits requests through the adaptive limiter. Like PriorityLimit, each admitted request is
+// handled in its own goroutine so the limiter decides the concurrency. Error responses with a 5xx code count as
+// failures, and shed requests get a 503 error.
+func AdaptiveLimit(l *concurrency.AdaptiveLimiter, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		release, err := l.Acquire()
+		if err != nil {
+			r.Error("503", http.StatusText(http.StatusServiceUnavailable), []byte(fmt.Sprintf(`{"errors": [%q]}`, err.Error())))
+			return
+		}
+
+		go func() {
+			o := &outcomeRequest{Request: r}
+			defer func() { release(!o.failed) }()
+
+			h.Handle(o)
+		}()
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	l := concurrency.NewAdaptiveLimiter("test", concurrency.SetInitialLimit(4), concurrency.SetBackoff(0.5))
+	h := AdaptiveLimit(l, micro.HandlerFunc(func(r micro.Request) {
+		r.Error("500", "Internal Server Error", nil)
+	}))
+
+	h.Handle(&replyRequest{msg: &nats.Msg{Subject: "orders.get", Header: nats.Header{}}})
+	deadline := time.Now().Add(time.Second)
+	for l.Limit() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("expected the server error to cut the limit to 2 but got %d", got)
+	}
+
+	l.Acquire()
+	l.Acquire()
+	shed := &replyRequest{msg: &nats.Msg{Subject: "orders.get", Header: nats.Header{}}}
+	h.Handle(shed)
+	if code := shed.reply.Header.Get(micro.ErrorCodeHeader); code != "503" {
+		t.Errorf("expected the request over the limit to get a 503 but got %q", code)
+	}
+}
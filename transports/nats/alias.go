@@ -0,0 +1,125 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Headers set on responses to deprecated subject aliases
+const (
+	DeprecatedHeader = "Sencillo-Deprecated"
+	SuccessorHeader  = "Sencillo-Successor"
+	SunsetHeader     = "Sencillo-Sunset"
+)
+
+var ErrUnknownAliasTarget = fmt.Errorf("alias target is not an endpoint")
+
+// SubjectAlias keeps an old subject working after an endpoint moves. Requests to Subject are handled by the
+// endpoint named Target.
+type SubjectAlias struct {
+	Subject string
+	Target  string
+	// Name is the alias endpoint's name. The default is the target's name with _deprecated appended.
+	Name string
+	// Sunset is when the alias will be removed, sent in the Sencillo-Sunset header when set
+	Sunset time.Time
+}
+
+// Aliases registers deprecated subjects for renamed endpoints. An alias responds like its target and marks the
+// response deprecated, so requesters keep working while they migrate and the metrics show who still hasn't.
+type Aliases struct {
+	requests *prometheus.CounterVec
+}
+
+// NewAliases returns Aliases
+func NewAliases() *Aliases {
+	return &Aliases{
+		requests: metrics.NewCounterVec("nats_deprecated_requests", "Requests to deprecated subject aliases", []string{"alias", "target"}),
+	}
+}
+
+// Metrics returns the aliases' collectors to register with the exporter
+func (a *Aliases) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{a.requests}
+}
+
+// Endpoints returns endpoints with an endpoint added for each alias, ready for AddEndpoints
+func (a *Aliases) Endpoints(endpoints []Endpoint, aliases ...SubjectAlias) ([]Endpoint, error) {
+	out := append([]Endpoint(nil), endpoints...)
+	var errs []error
+	for _, alias := range aliases {
+		i := -1
+		for j, e := range endpoints {
+			if e.Name == alias.Target {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownAliasTarget, alias.Target))
+			continue
+		}
+
+		e := endpoints[i]
+		if alias.Name == "" {
+			alias.Name = e.Name + "_deprecated"
+		}
+		e.Name, e.Subject, e.Handler = alias.Name, alias.Subject, a.deprecate(alias, endpointSubject(e), e.Handler)
+		out = append(out, e)
+	}
+
+	return out, errors.Join(errs...)
+}
+
+// deprecate marks responses deprecated and counts them
+func (a *Aliases) deprecate(alias SubjectAlias, successor string, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		a.requests.WithLabelValues(alias.Subject, successor).Inc()
+		headers := micro.Headers{
+			DeprecatedHeader: {"true"},
+			SuccessorHeader:  {successor},
+		}
+		if !alias.Sunset.IsZero() {
+			headers[SunsetHeader] = []string{alias.Sunset.UTC().Format(http.TimeFormat)}
+		}
+		h.Handle(&deprecatedRequest{Request: r, headers: headers})
+	})
+}
+
+// deprecatedRequest adds the deprecation headers to whatever the handler responds with
+type deprecatedRequest struct {
+	micro.Request
+	headers micro.Headers
+}
+
+func (d *deprecatedRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	return d.Request.Respond(data, append(opts, micro.WithHeaders(d.headers))...)
+}
+
+func (d *deprecatedRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	return d.Request.RespondJSON(v, append(opts, micro.WithHeaders(d.headers))...)
+}
+
+func (d *deprecatedRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	return d.Request.Error(code, description, data, append(opts, micro.WithHeaders(d.headers))...)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestAliases(t *testing.T) {
+	a := NewAliases()
+	endpoints, err := a.Endpoints([]Endpoint{
+		{Name: "get", Subject: "customers.get", Handler: micro.HandlerFunc(func(r micro.Request) {
+			if string(r.Data()) == "missing" {
+				r.Error("404", "Not Found", nil)
+				return
+			}
+			r.Respond([]byte("ok"))
+		})},
+	}, SubjectAlias{Subject: "users.get", Target: "get"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateEndpoints(endpoints...); err != nil {
+		t.Fatal(err)
+	}
+	alias := endpoints[1]
+	if alias.Name != "get_deprecated" || alias.Subject != "users.get" {
+		t.Errorf("unexpected alias endpoint %s on %s", alias.Name, alias.Subject)
+	}
+
+	tt := []struct {
+		name string
+		data string
+		code string
+	}{
+		{name: "respond", data: "7"},
+		{name: "error", data: "missing", code: "404"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			r := &replyRequest{msg: &nats.Msg{Subject: "users.get", Data: []byte(v.data), Header: nats.Header{}}}
+			alias.Handler.Handle(r)
+			if r.reply.Header.Get(DeprecatedHeader) != "true" || r.reply.Header.Get(SuccessorHeader) != "customers.get" {
+				t.Errorf("expected deprecation headers but got %v", r.reply.Header)
+			}
+			if got := r.reply.Header.Get(micro.ErrorCodeHeader); got != v.code {
+				t.Errorf("expected error code %q but got %q", v.code, got)
+			}
+		})
+	}
+
+	if _, err := a.Endpoints(endpoints, SubjectAlias{Subject: "old", Target: "missing"}); !errors.Is(err, ErrUnknownAliasTarget) {
+		t.Errorf("expected ErrUnknownAliasTarget but got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+const (
+	// RevisionHeader holds the revision of the value in a response, like an HTTP ETag
+	RevisionHeader = "Sencillo-Revision"
+	// IfNoneMatchHeader holds the revision a client already has, like HTTP's If-None-Match
+	IfNoneMatchHeader = "Sencillo-If-None-Match"
+	// NotModifiedHeader marks an empty response telling the client its revision is current
+	NotModifiedHeader = "Sencillo-Not-Modified"
+)
+
+// Requester sends a NATS request. *nats.Conn implements it.
+type Requester interface {
+	RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error)
+}
+
+// RespondEntry responds with a KV entry's value and revision. If the request already has the revision, the
+// response is empty and marked not modified.
+func RespondEntry(r micro.Request, e nats.KeyValueEntry) error {
+	rev := strconv.FormatUint(e.Revision(), 10)
+	h := micro.Headers{RevisionHeader: []string{rev}}
+	if r.Headers().Get(IfNoneMatchHeader) == rev {
+		h[NotModifiedHeader] = []string{"true"}
+		return r.Respond(nil, micro.WithHeaders(h))
+	}
+
+	return r.Respond(e.Value(), micro.WithHeaders(h))
+}
+
+// KVHandler responds with the entry of kv named by key, using RespondEntry, or a 404 error if it doesn't exist
+func KVHandler(kv nats.KeyValue, key func(micro.Request) string) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		e, err := kv.Get(key(r))
+		switch {
+		case errors.Is(err, nats.ErrKeyNotFound), errors.Is(err, nats.ErrInvalidKey):
+			ce := sderrors.NewClientError(err, http.StatusNotFound)
+			r.Error("404", http.StatusText(http.StatusNotFound), ce.Body())
+			return
+		case err != nil:
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		RespondEntry(r, e)
+	})
+}
+
+type revisioned struct {
+	revision string
+	data     []byte
+}
+
+// TypedClient sends JSON requests and decodes JSON responses into T. It remembers the revision of each response
+// and sends it with the next identical request, so a responder using RespondEntry skips sending a value that
+// hasn't changed. One revision is kept per subject and request body.
+type TypedClient[T any] struct {
+	nc Requester
+
+	mu        sync.Mutex
+	revisions map[string]revisioned
+}
+
+// NewTypedClient returns a TypedClient sending requests with nc. Pass a Hedger as nc to hedge slow requests.
+func NewTypedClient[T any](nc Requester) *TypedClient[T] {
+	return &TypedClient[T]{nc: nc, revisions: make(map[string]revisioned)}
+}
+
+// Request sends req encoded as JSON, or an empty body if it is nil, and decodes the response. Error responses
+// from micro services are returned as sderrors.ClientError with the service's status code.
+func (c *TypedClient[T]) Request(ctx context.Context, subject string, req any) (T, error) {
+	var res T
+	msg := nats.NewMsg(subject)
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return res, err
+		}
+		msg.Data = data
+	}
+
+	key := subject + "\n" + string(msg.Data)
+	c.mu.Lock()
+	cached, ok := c.revisions[key]
+	c.mu.Unlock()
+	if ok {
+		msg.Header.Set(IfNoneMatchHeader, cached.revision)
+	}
+
+	reply, err := c.nc.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		return res, err
+	}
+	if err := replyError(reply); err != nil {
+		return res, err
+	}
+
+	data := reply.Data
+	rev := reply.Header.Get(RevisionHeader)
+	switch {
+	case ok && reply.Header.Get(NotModifiedHeader) != "" && rev == cached.revision:
+		data = cached.data
+	case rev != "":
+		c.mu.Lock()
+		c.revisions[key] = revisioned{revision: rev, data: data}
+		c.mu.Unlock()
+	}
+
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// replyError returns the error a micro service responded with
+func replyError(reply *nats.Msg) error {
+	code := reply.Header.Get(micro.ErrorCodeHeader)
+	if code == "" {
+		return nil
+	}
+
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		status = http.StatusInternalServerError
+	}
+
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	msg := reply.Header.Get(micro.ErrorHeader)
+	if json.Unmarshal(reply.Data, &body) == nil && len(body.Errors) > 0 {
+		msg = strings.Join(body.Errors, "; ")
+	}
+
+	return sderrors.NewClientError(fmt.Errorf("%s", msg), status)
+}
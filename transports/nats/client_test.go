@@ -0,0 +1,129 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/internal/kvtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// replyRequest is a micro request that records its reply
+type replyRequest struct {
+	micro.Request
+	msg   *nats.Msg
+	reply *nats.Msg
+}
+
+func (r *replyRequest) Headers() micro.Headers { return micro.Headers(r.msg.Header) }
+func (r *replyRequest) Data() []byte           { return r.msg.Data }
+func (r *replyRequest) Subject() string        { return r.msg.Subject }
+
+func (r *replyRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	r.reply = &nats.Msg{Data: data, Header: nats.Header{}}
+	for _, opt := range opts {
+		opt(r.reply)
+	}
+	return nil
+}
+
+func (r *replyRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	r.reply = &nats.Msg{Data: data, Header: nats.Header{micro.ErrorCodeHeader: {code}, micro.ErrorHeader: {description}}}
+	for _, opt := range opts {
+		opt(r.reply)
+	}
+	return nil
+}
+
+// handlerRequester sends requests straight to a micro handler
+type handlerRequester struct {
+	handler micro.Handler
+	sent    []*nats.Msg
+	replies []*nats.Msg
+}
+
+func (h *handlerRequester) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	r := &replyRequest{msg: msg}
+	h.handler.Handle(r)
+	h.sent = append(h.sent, msg)
+	h.replies = append(h.replies, r.reply)
+	return r.reply, nil
+}
+
+type settings struct {
+	Theme string `json:"theme"`
+}
+
+func TestTypedClientConditional(t *testing.T) {
+	kv := kvtest.New()
+	kv.Put("settings.acme", []byte(`{"theme": "dark"}`))
+	nc := &handlerRequester{handler: KVHandler(kv, func(r micro.Request) string {
+		return "settings." + strings.Trim(string(r.Data()), `"`)
+	})}
+	c := NewTypedClient[settings](nc)
+	ctx := context.Background()
+
+	tt := []struct {
+		name        string
+		ifNoneMatch string
+		notModified bool
+		theme       string
+	}{
+		{name: "first", theme: "dark"},
+		{name: "unchanged", ifNoneMatch: "1", notModified: true, theme: "dark"},
+		{name: "changed", ifNoneMatch: "1", theme: "light"},
+		{name: "unchanged again", ifNoneMatch: "2", notModified: true, theme: "light"},
+	}
+
+	for i, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if v.name == "changed" {
+				kv.Put("settings.acme", []byte(`{"theme": "light"}`))
+			}
+
+			s, err := c.Request(ctx, "settings.get", "acme")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Theme != v.theme {
+				t.Errorf("expected theme %s but got %s", v.theme, s.Theme)
+			}
+			if got := nc.sent[i].Header.Get(IfNoneMatchHeader); got != v.ifNoneMatch {
+				t.Errorf("expected If-None-Match %q but got %q", v.ifNoneMatch, got)
+			}
+			reply := nc.replies[i]
+			if notModified := reply.Header.Get(NotModifiedHeader) != ""; notModified != v.notModified || (notModified && len(reply.Data) != 0) {
+				t.Errorf("expected not modified to be %v with no payload but got %v with %q", v.notModified, notModified, reply.Data)
+			}
+		})
+	}
+}
+
+func TestTypedClientError(t *testing.T) {
+	nc := &handlerRequester{handler: KVHandler(kvtest.New(), func(r micro.Request) string { return "missing" })}
+	_, err := NewTypedClient[settings](nc).Request(context.Background(), "settings.get", nil)
+
+	var ce sderrors.ClientError
+	if !errors.As(err, &ce) || ce.Code() != http.StatusNotFound {
+		t.Errorf("expected a 404 client error but got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// CoalescedHeader is set on responses shared from an identical request that was already in flight
+const CoalescedHeader = "Sencillo-Coalesced"
+
+// reply is a micro response recorded so it can be sent to every coalesced request
+type reply struct {
+	data        []byte
+	header      nats.Header
+	code        string
+	description string
+}
+
+// recordingRequest captures the response of the handler running on behalf of coalesced requests
+type recordingRequest struct {
+	micro.Request
+	reply reply
+}
+
+func (r *recordingRequest) headers(opts []micro.RespondOpt) nats.Header {
+	msg := nats.NewMsg("")
+	for _, opt := range opts {
+		opt(msg)
+	}
+	return msg.Header
+}
+
+func (r *recordingRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	r.reply = reply{data: data, header: r.headers(opts)}
+	return nil
+}
+
+func (r *recordingRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return micro.ErrMarshalResponse
+	}
+	return r.Respond(data, opts...)
+}
+
+func (r *recordingRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	r.reply = reply{data: data, header: r.headers(opts), code: code, description: description}
+	return nil
+}
+
+// CoalesceOpt is a functional option to modify Coalesce
+type CoalesceOpt func(*coalescer)
+
+type coalescer struct {
+	principal func(micro.Request) string
+	flight    concurrency.Flight[reply]
+}
+
+// SetCoalescePrincipal sets the function returning who made a request, so only requests from the same
+// principal share a response. The default uses the Authorization header.
+func SetCoalescePrincipal(f func(micro.Request) string) CoalesceOpt {
+	return func(c *coalescer) {
+		c.principal = f
+	}
+}
+
+// CoalesceKey returns the key identical reads share: the subject, gateway query with sorted parameters,
+// Accept-Language and AcceptEncodingHeader headers, principal, and payload. Replies are localized and compressed
+// for the requester, so requests differing in those headers don't share one.
+func CoalesceKey(r micro.Request, principal string) string {
+	query := r.Headers().Get("X-NatsBridge-UrlQuery")
+	if parsed, err := url.ParseQuery(query); err == nil {
+		query = parsed.Encode()
+	}
+	return strings.Join([]string{r.Subject(), query, r.Headers().Get("Accept-Language"), r.Headers().Get(AcceptEncodingHeader), principal, string(r.Data())}, "\n")
+}
+
+// Coalesce shares one call of h between identical requests in flight at the same time. Use it for read
+// endpoints that don't change anything, so a thundering herd of reads costs the backend one request.
+func Coalesce(h micro.Handler, opts ...CoalesceOpt) micro.Handler {
+	c := &coalescer{
+		principal: func(r micro.Request) string { return r.Headers().Get("Authorization") },
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return micro.HandlerFunc(func(r micro.Request) {
+		res, shared, err := c.flight.Do(context.Background(), CoalesceKey(r, c.principal(r)), func(ctx context.Context) (reply, error) {
+			rec := &recordingRequest{Request: r}
+			h.Handle(rec)
+			return rec.reply, nil
+		})
+		if errors.Is(err, concurrency.ErrPanic) {
+			r.Error("500", http.StatusText(http.StatusInternalServerError), nil)
+			return
+		}
+
+		header := nats.Header{}
+		for k, v := range res.header {
+			header[k] = v
+		}
+		if shared {
+			header.Set(CoalescedHeader, "true")
+		}
+		if res.code != "" {
+			r.Error(res.code, res.description, res.data, micro.WithHeaders(micro.Headers(header)))
+			return
+		}
+		r.Respond(res.data, micro.WithHeaders(micro.Headers(header)))
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	request := func(header ...string) micro.Request {
+		msg := &nats.Msg{Subject: "posts.get", Data: []byte("1"), Header: nats.Header{}}
+		for i := 0; i < len(header); i += 2 {
+			msg.Header.Set(header[i], header[i+1])
+		}
+		return &replyRequest{msg: msg}
+	}
+
+	tt := []struct {
+		name   string
+		header []string
+	}{
+		{name: "language", header: []string{"Accept-Language", "es"}},
+		{name: "encoding", header: []string{AcceptEncodingHeader, "zstd"}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if CoalesceKey(request(), "alice") == CoalesceKey(request(v.header...), "alice") {
+				t.Errorf("expected requests differing in %s not to share a key", v.header[0])
+			}
+		})
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := Coalesce(micro.HandlerFunc(func(r micro.Request) {
+		calls.Add(1)
+		<-release
+		if string(r.Data()) == "missing" {
+			r.Error("404", "Not Found", []byte(`{"errors": ["not found"]}`))
+			return
+		}
+		r.RespondJSON(map[string]string{"id": string(r.Data())}, micro.WithHeaders(micro.Headers{"Content-Type": {"application/json"}}))
+	}))
+
+	payloads := []string{"1", "1", "1", "missing", "missing"}
+	requests := make([]*replyRequest, len(payloads))
+	var wg sync.WaitGroup
+	for i, p := range payloads {
+		requests[i] = &replyRequest{msg: &nats.Msg{Subject: "posts.get", Data: []byte(p), Header: nats.Header{}}}
+		wg.Add(1)
+		go func(r *replyRequest) {
+			defer wg.Done()
+			h.Handle(r)
+		}(requests[i])
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected one call per payload but got %d", n)
+	}
+	coalesced := 0
+	for i, r := range requests {
+		if r.reply.Header.Get(CoalescedHeader) == "true" {
+			coalesced++
+		}
+		if payloads[i] == "missing" {
+			if r.reply.Header.Get(micro.ErrorCodeHeader) != "404" {
+				t.Errorf("expected the shared error but got %v", r.reply.Header)
+			}
+			continue
+		}
+		if string(r.reply.Data) != `{"id":"1"}` || r.reply.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected the shared response but got %s %v", r.reply.Data, r.reply.Header)
+		}
+	}
+	if coalesced != 3 {
+		t.Errorf("expected 3 coalesced responses but got %d", coalesced)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ContentEncodingHeader names the encoding a payload is compressed with
+	ContentEncodingHeader = "Sencillo-Content-Encoding"
+	// AcceptEncodingHeader lists the encodings a requester can decompress, comma separated
+	AcceptEncodingHeader = "Sencillo-Accept-Encoding"
+	// maxDecompressed bounds the size of a decompressed payload, so a small compressed payload can't exhaust memory
+	maxDecompressed = 64 << 20
+)
+
+var (
+	ErrUnsupportedEncoding = fmt.Errorf("unsupported encoding")
+	ErrPayloadTooLarge     = fmt.Errorf("decompressed payload too large")
+)
+
+// Encoding is a compression algorithm for payloads
+type Encoding string
+
+const (
+	Gzip Encoding = "gzip"
+	Zstd Encoding = "zstd"
+	S2   Encoding = "s2"
+)
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressed))
+)
+
+// Compress compresses data with enc
+func Compress(enc Encoding, data []byte) ([]byte, error) {
+	switch enc {
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	case S2:
+		return s2.Encode(nil, data), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, enc)
+}
+
+// Decompress decompresses data compressed with enc
+func Decompress(enc Encoding, data []byte) ([]byte, error) {
+	switch enc {
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		out, err := io.ReadAll(io.LimitReader(r, maxDecompressed+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > maxDecompressed {
+			return nil, ErrPayloadTooLarge
+		}
+		return out, nil
+	case Zstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	case S2:
+		n, err := s2.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxDecompressed {
+			return nil, ErrPayloadTooLarge
+		}
+		return s2.Decode(nil, data)
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, enc)
+}
+
+// CompressionOpt is a functional option to modify Compression
+type CompressionOpt func(*Compression)
+
+// Compression compresses payloads over a size threshold on both sides of a request. Requesters list the
+// encodings they accept in AcceptEncodingHeader, and payloads name their encoding in ContentEncodingHeader, so
+// compressing clients and handlers work with ones that don't compress.
+type Compression struct {
+	encoding  Encoding
+	threshold int
+
+	ratio *prometheus.HistogramVec
+	saved *prometheus.CounterVec
+}
+
+// SetEncoding sets the encoding payloads are compressed with. The default is Zstd.
+func SetEncoding(enc Encoding) CompressionOpt {
+	return func(c *Compression) {
+		c.encoding = enc
+	}
+}
+
+// SetCompressionThreshold sets the smallest payload that is compressed. The default is 1KB, since compressing
+// smaller payloads rarely saves enough to be worth the CPU.
+func SetCompressionThreshold(n int) CompressionOpt {
+	return func(c *Compression) {
+		c.threshold = n
+	}
+}
+
+// NewCompression returns Compression for a client or handlers
+func NewCompression(opts ...CompressionOpt) *Compression {
+	c := &Compression{
+		encoding:  Zstd,
+		threshold: 1024,
+		ratio: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "nats_compression_ratio",
+			Help:    "Compressed size of payloads as a fraction of their original size",
+			Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+		}, []string{"encoding"}),
+		saved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nats_compression_saved_bytes",
+			Help: "Bytes saved by compressing payloads",
+		}, []string{"encoding"}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Metrics returns the compression collectors to register with the exporter
+func (c *Compression) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.ratio, c.saved}
+}
+
+// compress compresses data if it is over the threshold, returning the original data and an empty encoding if it
+// isn't or compressing doesn't make it smaller
+func (c *Compression) compress(data []byte) ([]byte, Encoding) {
+	if len(data) < c.threshold {
+		return data, ""
+	}
+
+	out, err := Compress(c.encoding, data)
+	if err != nil || len(out) >= len(data) {
+		return data, ""
+	}
+	c.ratio.WithLabelValues(string(c.encoding)).Observe(float64(len(out)) / float64(len(data)))
+	c.saved.WithLabelValues(string(c.encoding)).Add(float64(len(data) - len(out)))
+
+	return out, c.encoding
+}
+
+func accepts(header string, enc Encoding) bool {
+	for _, v := range strings.Split(header, ",") {
+		if Encoding(strings.TrimSpace(v)) == enc {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decompress returns the payload of a message with header h
+func decompress(h nats.Header, data []byte) ([]byte, error) {
+	enc := h.Get(ContentEncodingHeader)
+	if enc == "" {
+		return data, nil
+	}
+
+	return Decompress(Encoding(enc), data)
+}
+
+// Client returns a Requester compressing requests with nc and decompressing replies. Pass it to NewTypedClient.
+func (c *Compression) Client(nc Requester) Requester {
+	return compressingRequester{c: c, nc: nc}
+}
+
+type compressingRequester struct {
+	c  *Compression
+	nc Requester
+}
+
+func (r compressingRequester) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	out := &nats.Msg{Subject: msg.Subject, Header: nats.Header{}}
+	for k, v := range msg.Header {
+		out.Header[k] = v
+	}
+	out.Header.Set(AcceptEncodingHeader, strings.Join([]string{string(Zstd), string(S2), string(Gzip)}, ","))
+
+	var enc Encoding
+	out.Data, enc = r.c.compress(msg.Data)
+	if enc != "" {
+		out.Header.Set(ContentEncodingHeader, string(enc))
+	}
+
+	reply, err := r.nc.RequestMsgWithContext(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decompress(reply.Header, reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	reply.Data = data
+	reply.Header.Del(ContentEncodingHeader)
+
+	return reply, nil
+}
+
+// Handler decompresses requests before h handles them and compresses its responses when the requester accepts
+// the encoding. A request that can't be decompressed gets a 400 error.
+func (c *Compression) Handler(h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		data, err := decompress(nats.Header(r.Headers()), r.Data())
+		if err != nil {
+			ce := sderrors.NewClientError(err, http.StatusBadRequest)
+			r.Error("400", http.StatusText(http.StatusBadRequest), ce.Body())
+			return
+		}
+
+		h.Handle(&compressingRequest{
+			Request:  r,
+			c:        c,
+			data:     data,
+			compress: accepts(r.Headers().Get(AcceptEncodingHeader), c.encoding),
+		})
+	})
+}
+
+// compressingRequest hands the handler the decompressed payload and compresses its response
+type compressingRequest struct {
+	micro.Request
+	c        *Compression
+	data     []byte
+	compress bool
+}
+
+func (r *compressingRequest) Data() []byte {
+	return r.data
+}
+
+func (r *compressingRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	if !r.compress {
+		return r.Request.Respond(data, opts...)
+	}
+
+	data, enc := r.c.compress(data)
+	if enc == "" {
+		return r.Request.Respond(data, opts...)
+	}
+
+	return r.Request.Respond(data, append(opts, func(m *nats.Msg) {
+		h := nats.Header{}
+		for k, v := range m.Header {
+			h[k] = v
+		}
+		h.Set(ContentEncodingHeader, string(enc))
+		m.Header = h
+	})...)
+}
+
+func (r *compressingRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return r.Respond(data, opts...)
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"id": "order", "status": "shipped"}`), 100)
+	for _, enc := range []Encoding{Gzip, Zstd, S2} {
+		t.Run(string(enc), func(t *testing.T) {
+			compressed, err := Compress(enc, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(compressed) >= len(data) {
+				t.Errorf("expected %s to shrink the payload", enc)
+			}
+			out, err := Decompress(enc, compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out, data) {
+				t.Error("expected the payload to survive the round trip")
+			}
+		})
+	}
+
+	if _, err := Compress("br", data); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("expected %v but got %v", ErrUnsupportedEncoding, err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	large := strings.Repeat("shipped ", 500)
+	tt := []struct {
+		name       string
+		clientOpts []CompressionOpt
+		compressed bool
+		response   string
+	}{
+		{name: "small", response: "ok"},
+		{name: "large", response: large, compressed: true},
+		{name: "gzip client", clientOpts: []CompressionOpt{SetEncoding(Gzip)}, response: large, compressed: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			server := NewCompression()
+			reg := sdtest.NewRegistry(t, server.Metrics()...)
+			var received []byte
+			rec := &handlerRequester{handler: server.Handler(micro.HandlerFunc(func(r micro.Request) {
+				received = r.Data()
+				r.Respond([]byte(v.response))
+			}))}
+			client := NewCompression(v.clientOpts...).Client(rec)
+
+			msg := nats.NewMsg("orders.list")
+			msg.Data = []byte(large)
+			reply, err := client.RequestMsgWithContext(context.Background(), msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(received) != large {
+				t.Error("expected the handler to get the decompressed request")
+			}
+			if rec.sent[0].Header.Get(ContentEncodingHeader) == "" || len(rec.sent[0].Data) >= len(large) {
+				t.Error("expected the request to be sent compressed")
+			}
+			if string(reply.Data) != v.response {
+				t.Errorf("expected the decompressed response but got %d bytes", len(reply.Data))
+			}
+			// the client decompresses the reply in place, so the metric shows whether it was compressed
+			if v.compressed {
+				if n := reg.HistogramCount(t, "nats_compression_ratio", prometheus.Labels{"encoding": "zstd"}); n != 1 {
+					t.Errorf("expected one compressed response but got %d", n)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressionInvalid(t *testing.T) {
+	rec := &handlerRequester{handler: NewCompression().Handler(micro.HandlerFunc(func(r micro.Request) {
+		t.Error("expected the handler not to be called")
+	}))}
+
+	msg := nats.NewMsg("orders.list")
+	msg.Header.Set(ContentEncodingHeader, "zstd")
+	msg.Data = []byte("not zstd")
+	reply, err := rec.RequestMsgWithContext(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := reply.Header.Get(micro.ErrorCodeHeader); code != "400" {
+		t.Errorf("expected a 400 but got %q", code)
+	}
+}
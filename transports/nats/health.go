@@ -0,0 +1,174 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// HealthFunc reports a reason to stop the service on the channel. It is passed to HandleNotify.
+type HealthFunc func(chan<- string, micro.Service)
+
+// HealthCheck is a single check run periodically by PeriodicHealthCheck
+type HealthCheck func(context.Context) error
+
+// HealthOpt is a functional option to modify a periodic health check
+type HealthOpt func(*healthConfig)
+
+type healthConfig struct {
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	logger    *slog.Logger
+}
+
+// SetHealthInterval sets how often the check runs
+func SetHealthInterval(d time.Duration) HealthOpt {
+	return func(h *healthConfig) {
+		h.interval = d
+	}
+}
+
+// SetHealthTimeout sets the deadline for a single run of the check
+func SetHealthTimeout(d time.Duration) HealthOpt {
+	return func(h *healthConfig) {
+		h.timeout = d
+	}
+}
+
+// SetFailureThreshold sets the number of consecutive failures before the service is stopped
+func SetFailureThreshold(n int) HealthOpt {
+	return func(h *healthConfig) {
+		if n > 0 {
+			h.threshold = n
+		}
+	}
+}
+
+// SetHealthLogger sets the logger used to report individual check failures
+func SetHealthLogger(l *slog.Logger) HealthOpt {
+	return func(h *healthConfig) {
+		h.logger = l
+	}
+}
+
+// PeriodicHealthCheck runs the check on an interval and stops the service once the failure threshold is reached.
+// The check stops without stopping the service when the context is cancelled.
+func PeriodicHealthCheck(ctx context.Context, name string, check HealthCheck, opts ...HealthOpt) HealthFunc {
+	cfg := healthConfig{
+		interval:  10 * time.Second,
+		timeout:   5 * time.Second,
+		threshold: 3,
+		logger:    slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(stopChan chan<- string, s micro.Service) {
+		ticker := time.NewTicker(cfg.interval)
+		defer ticker.Stop()
+
+		var failures int
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			checkCtx, cancel := context.WithTimeout(ctx, cfg.timeout)
+			err := check(checkCtx)
+			cancel()
+
+			if err == nil {
+				failures = 0
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			failures++
+			cfg.logger.Error(fmt.Sprintf("health check %s failed (%d/%d): %v", name, failures, cfg.threshold, err))
+			if failures >= cfg.threshold {
+				notify(ctx, stopChan, fmt.Sprintf("health check %s failed: %v", name, err))
+				return
+			}
+		}
+	}
+}
+
+// closedPollInterval is how often ConnectionMonitor checks whether the connection was closed
+const closedPollInterval = time.Second
+
+// ConnectionMonitor stops the service when the NATS connection is closed or has not been connected for the
+// failure threshold number of intervals
+func ConnectionMonitor(ctx context.Context, nc *nats.Conn, opts ...HealthOpt) HealthFunc {
+	periodic := PeriodicHealthCheck(ctx, "nats connection", func(context.Context) error {
+		if status := nc.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("connection status %s", status)
+		}
+		return nil
+	}, opts...)
+
+	return func(stopChan chan<- string, s micro.Service) {
+		go periodic(stopChan, s)
+
+		// the connection is polled since nats.go can't remove a StatusChanged listener, so each monitor would
+		// leave one registered on the connection after ctx is done
+		ticker := time.NewTicker(closedPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if nc.IsClosed() {
+				notify(ctx, stopChan, fmt.Sprintf("nats connection closed: %v", nc.LastError()))
+				return
+			}
+		}
+	}
+}
+
+// notify sends the reason to stop unless ctx is done first, so a check doesn't block forever once the service is
+// already stopping and nothing reads the channel
+func notify(ctx context.Context, stopChan chan<- string, reason string) {
+	select {
+	case stopChan <- reason:
+	case <-ctx.Done():
+	}
+}
+
+// KVHeartbeat writes the current time to the key on every interval so other services can detect this one is
+// alive. The service is stopped once the failure threshold of failed writes is reached.
+func KVHeartbeat(ctx context.Context, kv nats.KeyValue, key string, opts ...HealthOpt) HealthFunc {
+	return PeriodicHealthCheck(ctx, fmt.Sprintf("kv heartbeat %s", key), func(context.Context) error {
+		_, err := kv.PutString(key, time.Now().UTC().Format(time.RFC3339Nano))
+		return err
+	}, opts...)
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type fakeHeartbeatKV struct {
+	nats.KeyValue
+	mu   sync.Mutex
+	puts int
+	err  error
+}
+
+func (f *fakeHeartbeatKV) PutString(key string, value string) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.puts++
+	if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+		return 0, err
+	}
+	return uint64(f.puts), f.err
+}
+
+func TestPeriodicHealthCheck(t *testing.T) {
+	errDown := errors.New("down")
+	tt := []struct {
+		name    string
+		results []error
+		calls   int
+		stopped bool
+	}{
+		{name: "threshold", results: []error{errDown, errDown, errDown}, calls: 3, stopped: true},
+		{name: "recovers", results: []error{errDown, errDown, nil, errDown, errDown, errDown}, calls: 6, stopped: true},
+		{name: "healthy", results: []error{nil, nil, nil}, calls: 3},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var calls int
+			check := func(context.Context) error {
+				if calls == len(v.results) {
+					cancel()
+					return nil
+				}
+				err := v.results[calls]
+				calls++
+				return err
+			}
+
+			stopChan := make(chan string, 1)
+			PeriodicHealthCheck(ctx, "db", check,
+				SetHealthInterval(time.Millisecond),
+				SetHealthLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			)(stopChan, nil)
+
+			if calls != v.calls {
+				t.Errorf("expected %d checks but got %d", v.calls, calls)
+			}
+			select {
+			case msg := <-stopChan:
+				if !v.stopped {
+					t.Errorf("expected the service to keep running but got %q", msg)
+				}
+				if !strings.Contains(msg, "health check db failed: down") {
+					t.Errorf("unexpected stop reason %q", msg)
+				}
+			default:
+				if v.stopped {
+					t.Error("expected the service to be stopped")
+				}
+			}
+		})
+	}
+}
+
+func TestPeriodicHealthCheckCancelledSend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	failed := make(chan struct{})
+	check := func(context.Context) error {
+		select {
+		case <-failed:
+		default:
+			close(failed)
+		}
+		return errors.New("down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// nothing reads the unbuffered channel, like a service that already stopped
+		PeriodicHealthCheck(ctx, "db", check,
+			SetHealthInterval(time.Millisecond),
+			SetFailureThreshold(1),
+			SetHealthLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		)(make(chan string), nil)
+	}()
+
+	<-failed
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the check to return once the context was cancelled")
+	}
+}
+
+func TestKVHeartbeat(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	kv := &fakeHeartbeatKV{err: errors.New("no quorum")}
+	stopChan := make(chan string, 1)
+	KVHeartbeat(ctx, kv, "orders.1",
+		SetHealthInterval(time.Millisecond),
+		SetFailureThreshold(2),
+		SetHealthLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)(stopChan, nil)
+
+	if kv.puts != 2 {
+		t.Errorf("expected 2 heartbeats but got %d", kv.puts)
+	}
+	if msg := <-stopChan; msg != "health check kv heartbeat orders.1 failed: no quorum" {
+		t.Errorf("unexpected stop reason %q", msg)
+	}
+}
+
+func TestConnectionMonitorStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor := ConnectionMonitor(ctx, &nats.Conn{}, SetHealthInterval(time.Hour))
+
+	done := make(chan struct{})
+	go func() {
+		monitor(make(chan string), nil)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the monitor to return once the context is done")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HedgeOpt is a functional option to modify a Hedger
+type HedgeOpt func(*Hedger)
+
+// Hedger sends a second copy of a request when the first hasn't been answered within a delay and returns the
+// first reply. Members of a queue group pick up requests at random, so the copy usually lands on another
+// instance and a slow instance doesn't decide the tail latency. Only hedge idempotent requests.
+type Hedger struct {
+	nc       Requester
+	delay    time.Duration
+	ratio    float64
+	burst    float64
+	inFlight int64
+
+	mu     sync.Mutex
+	tokens float64
+	active atomic.Int64
+
+	sent      prometheus.Counter
+	won       prometheus.Counter
+	throttled prometheus.Counter
+}
+
+// SetHedgeDelay sets how long a request waits for a reply before it is hedged. Set it near the endpoint's p95
+// latency. The default is 50 milliseconds.
+func SetHedgeDelay(d time.Duration) HedgeOpt {
+	return func(h *Hedger) {
+		h.delay = d
+	}
+}
+
+// SetHedgeRatio caps hedges to a fraction of requests, so a slow service isn't sent double the load. Each
+// request earns the fraction of a hedge, and up to 10 unused hedges are saved for bursts. The default is 0.1.
+func SetHedgeRatio(ratio float64) HedgeOpt {
+	return func(h *Hedger) {
+		h.ratio = ratio
+	}
+}
+
+// SetMaxHedgesInFlight caps the hedges waiting on a reply at once. The default is 10.
+func SetMaxHedgesInFlight(n int) HedgeOpt {
+	return func(h *Hedger) {
+		h.inFlight = int64(n)
+	}
+}
+
+// NewHedger returns a Hedger sending requests with nc. It implements Requester, so it can be passed to
+// NewTypedClient or anything else sending requests.
+func NewHedger(nc Requester, opts ...HedgeOpt) *Hedger {
+	h := &Hedger{
+		nc:       nc,
+		delay:    50 * time.Millisecond,
+		ratio:    0.1,
+		burst:    10,
+		inFlight: 10,
+		sent: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nats_hedged_requests",
+			Help: "Requests sent a second time after the hedge delay",
+		}),
+		won: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nats_hedged_requests_won",
+			Help: "Hedged requests answered by the second request first",
+		}),
+		throttled: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nats_hedged_requests_throttled",
+			Help: "Requests not hedged because the hedge ratio or in flight cap was reached",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.tokens = h.burst
+
+	return h
+}
+
+// Metrics returns the hedger's collectors to register with the exporter
+func (h *Hedger) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{h.sent, h.won, h.throttled}
+}
+
+type hedgeResult struct {
+	reply *nats.Msg
+	err   error
+	hedge bool
+}
+
+// RequestMsgWithContext sends msg and hedges it if no reply arrives within the delay. A failed request waits for
+// the other one before returning its error.
+func (h *Hedger) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	h.earn()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	send := func(hedge bool) {
+		m := &nats.Msg{Subject: msg.Subject, Header: msg.Header, Data: msg.Data}
+		reply, err := h.nc.RequestMsgWithContext(ctx, m)
+		results <- hedgeResult{reply: reply, err: err, hedge: hedge}
+	}
+	go send(false)
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	pending := 1
+	select {
+	case r := <-results:
+		return r.reply, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+
+	if h.take() {
+		pending++
+		h.sent.Inc()
+		go func() {
+			defer h.active.Add(-1)
+			send(true)
+		}()
+	} else {
+		h.throttled.Inc()
+	}
+
+	var r hedgeResult
+	for pending > 0 {
+		r = <-results
+		pending--
+		if r.err == nil {
+			break
+		}
+	}
+	if r.err == nil && r.hedge {
+		h.won.Inc()
+	}
+
+	return r.reply, r.err
+}
+
+// earn adds a request's share of a hedge to the budget
+func (h *Hedger) earn() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = min(h.tokens+h.ratio, h.burst)
+}
+
+// take spends a hedge from the budget if one is available and the in flight cap isn't reached
+func (h *Hedger) take() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 || h.active.Load() >= h.inFlight {
+		return false
+	}
+	h.tokens--
+	h.active.Add(1)
+
+	return true
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+)
+
+// slowFirst answers every request after the first right away and the first one after its delay
+type slowFirst struct {
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (s *slowFirst) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	n := s.calls.Add(1)
+	if n == 1 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+	}
+
+	return &nats.Msg{Data: []byte{byte('0' + n)}}, nil
+}
+
+func TestHedger(t *testing.T) {
+	tt := []struct {
+		name      string
+		first     time.Duration
+		firstErr  error
+		opts      []HedgeOpt
+		reply     string
+		calls     int32
+		hedged    float64
+		won       float64
+		throttled float64
+	}{
+		{name: "fast", first: 0, reply: "1", calls: 1},
+		{name: "hedge wins", first: time.Second, reply: "2", calls: 2, hedged: 1, won: 1},
+		{name: "first wins", first: 20 * time.Millisecond, firstErr: nil, opts: []HedgeOpt{SetMaxHedgesInFlight(0)}, reply: "1", calls: 1, throttled: 1},
+		{name: "first fails", first: 20 * time.Millisecond, firstErr: errors.New("no responders"), reply: "2", calls: 2, hedged: 1, won: 1},
+		{name: "no budget", first: 20 * time.Millisecond, opts: []HedgeOpt{SetHedgeRatio(0), func(h *Hedger) { h.burst = 0 }}, reply: "1", calls: 1, throttled: 1},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			nc := &slowFirst{delay: v.first, err: v.firstErr}
+			h := NewHedger(nc, append([]HedgeOpt{SetHedgeDelay(5 * time.Millisecond)}, v.opts...)...)
+			reg := sdtest.NewRegistry(t, h.Metrics()...)
+
+			reply, err := h.RequestMsgWithContext(context.Background(), nats.NewMsg("orders.get"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(reply.Data) != v.reply {
+				t.Errorf("expected reply %s but got %s", v.reply, reply.Data)
+			}
+			if calls := nc.calls.Load(); calls != v.calls {
+				t.Errorf("expected %d requests but got %d", v.calls, calls)
+			}
+			reg.AssertCounter(t, "nats_hedged_requests", nil, v.hedged)
+			reg.AssertCounter(t, "nats_hedged_requests_won", nil, v.won)
+			reg.AssertCounter(t, "nats_hedged_requests_throttled", nil, v.throttled)
+		})
+	}
+}
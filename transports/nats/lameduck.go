@@ -0,0 +1,267 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const lameDuckReason = "nats lame duck mode"
+
+var ErrNoServerConn = fmt.Errorf("no connection to the server was dialed by the lame duck handler")
+
+// Readiness is marked not ready while the connection moves off a server in lame duck mode. *lifecycle.Sequencer
+// implements it.
+type Readiness interface {
+	Hold(reason string)
+	Release(reason string)
+}
+
+// Pauser stops taking new work, such as a JetStream consumer fetching messages, until it is resumed
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// LameDuckOpt is a functional option to modify a LameDuck
+type LameDuckOpt func(*LameDuck)
+
+// LameDuck moves a connection off a server that has entered lame duck mode. Between the server's notification
+// and the connection reaching another server, readiness is held and pausers are paused so no new work starts on
+// a connection that is about to be dropped. Use one LameDuck per connection.
+type LameDuck struct {
+	logger    *slog.Logger
+	readiness []Readiness
+	pausers   []Pauser
+	reconnect func(*nats.Conn) error
+	timeout   time.Duration
+	dialer    *connDialer
+
+	listen   sync.Once
+	statuses chan nats.Status
+
+	mu     sync.Mutex
+	server string
+	since  time.Time
+}
+
+// SetLameDuckLogger sets the logger used to report transitions
+func SetLameDuckLogger(l *slog.Logger) LameDuckOpt {
+	return func(ld *LameDuck) {
+		ld.logger = l
+	}
+}
+
+// SetLameDuckReadiness sets the readiness held while the connection moves
+func SetLameDuckReadiness(r ...Readiness) LameDuckOpt {
+	return func(ld *LameDuck) {
+		ld.readiness = append(ld.readiness, r...)
+	}
+}
+
+// SetLameDuckPause sets consumers, or anything else implementing Pauser, to pause while the connection moves
+func SetLameDuckPause(p ...Pauser) LameDuckOpt {
+	return func(ld *LameDuck) {
+		ld.pausers = append(ld.pausers, p...)
+	}
+}
+
+// SetLameDuckReconnect sets a function called when lame duck mode starts to move the connection to another server
+// right away. By default the connection to the server is dropped, so the client reconnects to another one instead
+// of waiting for the server to close it, which the server spreads over its lame duck duration.
+func SetLameDuckReconnect(fn func(*nats.Conn) error) LameDuckOpt {
+	return func(ld *LameDuck) {
+		ld.reconnect = fn
+	}
+}
+
+// SetLameDuckTimeout sets how long to wait for the connection to move before resuming anyway. The default of two
+// minutes matches the server's default lame duck duration.
+func SetLameDuckTimeout(d time.Duration) LameDuckOpt {
+	return func(ld *LameDuck) {
+		ld.timeout = d
+	}
+}
+
+// NewLameDuck returns a LameDuck. Pass its Option to nats.Connect.
+func NewLameDuck(opts ...LameDuckOpt) *LameDuck {
+	ld := &LameDuck{
+		logger:  slog.Default(),
+		timeout: 2 * time.Minute,
+		dialer:  &connDialer{dialer: net.Dialer{Timeout: nats.GetDefaultOptions().Timeout}},
+	}
+	ld.reconnect = ld.drop
+
+	for _, opt := range opts {
+		opt(ld)
+	}
+
+	return ld
+}
+
+// Option sets the connection's lame duck mode handler. Unless the connection already has a custom dialer, it also
+// sets one that keeps the connection to the server so the default reconnect can drop it. Pass Option after
+// nats.SetCustomDialer, or the default reconnect fails and the connection moves when the server closes it.
+func (ld *LameDuck) Option() nats.Option {
+	return func(o *nats.Options) error {
+		o.LameDuckModeHandler = ld.handle
+		if o.CustomDialer == nil {
+			o.CustomDialer = ld.dialer
+		}
+		return nil
+	}
+}
+
+// drop closes the connection to the lame duck server, so the client reconnects to another server right away
+func (ld *LameDuck) drop(*nats.Conn) error {
+	return ld.dialer.drop()
+}
+
+// connDialer dials servers like the client's default dialer and keeps the latest connection
+type connDialer struct {
+	dialer net.Dialer
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (d *connDialer) Dial(network, address string) (net.Conn, error) {
+	conn, err := d.dialer.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conn = conn
+	return conn, nil
+}
+
+func (d *connDialer) drop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return ErrNoServerConn
+	}
+	return d.conn.Close()
+}
+
+// Active reports whether the connection is moving off a server in lame duck mode
+func (ld *LameDuck) Active() bool {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	return ld.server != ""
+}
+
+func (ld *LameDuck) handle(nc *nats.Conn) {
+	// the client can't remove status listeners, so one is registered for the connection's lifetime. It listens
+	// before reconnecting so the move can't be missed.
+	ld.listen.Do(func() {
+		ld.statuses = nc.StatusChanged(nats.CONNECTED, nats.CLOSED)
+	})
+	if ld.Active() {
+		return
+	}
+	drain(ld.statuses)
+
+	server := nc.ConnectedServerId()
+	if !ld.enter(server) {
+		return
+	}
+
+	if ld.reconnect != nil {
+		if err := ld.reconnect(nc); err != nil {
+			ld.logger.Error("error moving off lame duck server", "server", server, "error", err)
+		}
+	}
+
+	go ld.wait(ld.statuses, nc.ConnectedServerId, server)
+}
+
+// drain drops the statuses from reconnects before this notification
+func drain(statuses <-chan nats.Status) {
+	for {
+		select {
+		case <-statuses:
+		default:
+			return
+		}
+	}
+}
+
+// enter starts the transition, returning false if one is already in progress
+func (ld *LameDuck) enter(server string) bool {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if ld.server != "" {
+		return false
+	}
+	ld.server = server
+	ld.since = time.Now()
+
+	ld.logger.Warn("nats server entered lame duck mode", "server", server)
+	for _, r := range ld.readiness {
+		r.Hold(lameDuckReason)
+	}
+	for _, p := range ld.pausers {
+		p.Pause()
+	}
+
+	return true
+}
+
+// wait ends the transition once the connection is on a different server, is closed, or the timeout passes
+func (ld *LameDuck) wait(statuses <-chan nats.Status, current func() string, from string) {
+	timer := time.NewTimer(ld.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			ld.exit("timeout", current())
+			return
+		case status := <-statuses:
+			if status == nats.CLOSED {
+				ld.exit("closed", "")
+				return
+			}
+			if to := current(); to != from {
+				ld.exit("moved", to)
+				return
+			}
+		}
+	}
+}
+
+func (ld *LameDuck) exit(outcome, to string) {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	ld.logger.Info("nats lame duck transition finished", "outcome", outcome, "from", ld.server, "to", to,
+		"duration", time.Since(ld.since))
+	ld.server = ""
+	for _, p := range ld.pausers {
+		p.Resume()
+	}
+	for _, r := range ld.readiness {
+		r.Release(lameDuckReason)
+	}
+}
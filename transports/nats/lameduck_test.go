@@ -0,0 +1,185 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type fakeReadiness struct {
+	mu    sync.Mutex
+	holds map[string]bool
+}
+
+func (f *fakeReadiness) Hold(reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holds[reason] = true
+}
+
+func (f *fakeReadiness) Release(reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.holds, reason)
+}
+
+func (f *fakeReadiness) held() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.holds) > 0
+}
+
+type fakePauser struct {
+	mu     sync.Mutex
+	paused bool
+}
+
+func (f *fakePauser) Pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+func (f *fakePauser) Resume() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+}
+
+func (f *fakePauser) isPaused() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paused
+}
+
+func TestLameDuck(t *testing.T) {
+	tt := []struct {
+		name     string
+		statuses []nats.Status
+		servers  []string
+		outcome  string
+	}{
+		{name: "moved", statuses: []nats.Status{nats.CONNECTED}, servers: []string{"NB"}, outcome: "moved"},
+		{name: "same server", statuses: []nats.Status{nats.CONNECTED}, servers: []string{"NA", "NA"}, outcome: "timeout"},
+		{name: "closed", statuses: []nats.Status{nats.CLOSED}, outcome: "closed"},
+		{name: "timeout", outcome: "timeout"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ready := &fakeReadiness{holds: map[string]bool{}}
+			consumer := &fakePauser{}
+			ld := NewLameDuck(
+				SetLameDuckLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+				SetLameDuckReadiness(ready),
+				SetLameDuckPause(consumer),
+				SetLameDuckTimeout(50*time.Millisecond),
+			)
+
+			if !ld.enter("NA") {
+				t.Fatal("expected the transition to start")
+			}
+			if ld.enter("NA") {
+				t.Error("expected a second notification to be ignored")
+			}
+			if !ld.Active() || !ready.held() || !consumer.isPaused() {
+				t.Fatal("expected readiness to be held and the consumer paused")
+			}
+
+			statuses := make(chan nats.Status, len(v.statuses))
+			for _, s := range v.statuses {
+				statuses <- s
+			}
+			servers := append(v.servers, "NA")
+			current := func() string {
+				s := servers[0]
+				if len(servers) > 1 {
+					servers = servers[1:]
+				}
+				return s
+			}
+			ld.wait(statuses, current, "NA")
+
+			if ld.Active() || ready.held() || consumer.isPaused() {
+				t.Error("expected readiness to be released and the consumer resumed")
+			}
+			if !strings.Contains(buf.String(), "outcome="+v.outcome) {
+				t.Errorf("expected outcome %s to be logged\n%s", v.outcome, buf.String())
+			}
+		})
+	}
+}
+
+func TestLameDuckDrop(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ld := NewLameDuck()
+	if err := ld.reconnect(nil); !errors.Is(err, ErrNoServerConn) {
+		t.Errorf("expected %v before dialing but got %v", ErrNoServerConn, err)
+	}
+
+	opts := nats.GetDefaultOptions()
+	if err := ld.Option()(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.CustomDialer != ld.dialer || opts.LameDuckModeHandler == nil {
+		t.Fatal("expected the option to set the handler and dialer")
+	}
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	if _, err := opts.CustomDialer.Dial("tcp", l.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	server := <-accepted
+	defer server.Close()
+
+	if err := ld.reconnect(nil); err != nil {
+		t.Fatal(err)
+	}
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := server.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the connection to be dropped but got %v", err)
+	}
+
+	// a custom dialer set earlier is kept
+	custom := &connDialer{}
+	opts = nats.GetDefaultOptions()
+	nats.SetCustomDialer(custom)(&opts)
+	ld.Option()(&opts)
+	if opts.CustomDialer != custom {
+		t.Error("expected the custom dialer to be kept")
+	}
+}
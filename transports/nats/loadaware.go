@@ -0,0 +1,305 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DirectSubject returns the subject the instance with id answers subject on directly, outside of its queue group
+func DirectSubject(id, subject string) string {
+	return fmt.Sprintf("direct.%s.%s", id, subject)
+}
+
+// DirectEndpoints returns a copy of each endpoint listening on its DirectSubject for the instance with id, such as
+// micro.Service.Info().ID, so a LoadAware client can pick the instance. Add them alongside the endpoints. When
+// they are added to a micro.Group, the client needs SetDirectGroup with the group's prefix.
+func DirectEndpoints(id string, endpoints ...Endpoint) []Endpoint {
+	direct := make([]Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		e.Subject = DirectSubject(id, endpointSubject(e))
+		e.Name += "-direct"
+		direct = append(direct, e)
+	}
+
+	return direct
+}
+
+type instanceLoad struct {
+	id string
+	// load is the share of the last poll interval the instance spent handling requests, summed over endpoints,
+	// so 2 means two requests were in flight on average
+	load float64
+}
+
+// LoadAwareOpt is a functional option to modify a LoadAware client
+type LoadAwareOpt func(*LoadAware)
+
+// LoadAware sends requests to the less loaded instances of a micro service. It polls the service's stats and
+// derives each instance's load from how much processing time it added since the last poll, then sends each
+// request to the less loaded of two random instances on the instance's DirectSubject.
+type LoadAware struct {
+	nc       Requester
+	service  string
+	interval time.Duration
+	timeout  time.Duration
+	group    string
+	logger   *slog.Logger
+	fetch    func(context.Context) ([]micro.Stats, error)
+	intn     func(int) int
+
+	mu        sync.Mutex
+	instances []instanceLoad
+	totals    map[string]time.Duration
+	polled    time.Time
+
+	direct    *prometheus.CounterVec
+	fallbacks prometheus.Counter
+}
+
+// SetLoadInterval sets how often the service's stats are polled. The default is 5 seconds.
+func SetLoadInterval(d time.Duration) LoadAwareOpt {
+	return func(l *LoadAware) {
+		l.interval = d
+	}
+}
+
+// SetStatsTimeout sets how long a poll waits for instances to answer. The default is 500 milliseconds.
+func SetStatsTimeout(d time.Duration) LoadAwareOpt {
+	return func(l *LoadAware) {
+		l.timeout = d
+	}
+}
+
+// SetDirectGroup sets the prefix of the micro.Group the service's endpoints are added to. A grouped endpoint
+// listens on the prefix followed by its subject, so the instance's DirectSubject goes between the two.
+func SetDirectGroup(prefix string) LoadAwareOpt {
+	return func(l *LoadAware) {
+		l.group = prefix
+	}
+}
+
+// SetLoadLogger sets the logger used to report failed polls
+func SetLoadLogger(l *slog.Logger) LoadAwareOpt {
+	return func(la *LoadAware) {
+		la.logger = l
+	}
+}
+
+// NewLoadAware returns a LoadAware client for the micro service named service. Every endpoint it is used with
+// needs DirectEndpoints registered. Until the first poll, and for services without them, requests go to the
+// endpoint's subject as usual.
+func NewLoadAware(nc *nats.Conn, service string, opts ...LoadAwareOpt) *LoadAware {
+	l := &LoadAware{
+		nc:       nc,
+		service:  service,
+		interval: 5 * time.Second,
+		timeout:  500 * time.Millisecond,
+		logger:   slog.Default(),
+		intn:     rand.Intn,
+		totals:   make(map[string]time.Duration),
+		direct: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nats_direct_requests",
+			Help: "Requests sent to a picked instance by the load aware client",
+		}, []string{"service", "instance"}),
+		fallbacks: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nats_direct_fallbacks",
+			Help: "Requests resent to the queue group because the picked instance didn't respond",
+		}),
+	}
+	l.fetch = func(ctx context.Context) ([]micro.Stats, error) {
+		return gatherStats(ctx, nc, service, l.timeout)
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Metrics returns the client's collectors to register with the exporter
+func (l *LoadAware) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{l.direct, l.fallbacks}
+}
+
+// Run polls the service's stats on the interval until ctx is done
+func (l *LoadAware) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := l.fetch(ctx)
+		switch {
+		case ctx.Err() != nil:
+			// a poll cut short by cancellation is a clean exit, not a failure
+			return
+		case err != nil:
+			l.logger.Error(fmt.Sprintf("polling %s stats: %v", l.service, err))
+		default:
+			l.update(stats, time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update replaces the known instances with the ones in stats and computes their load
+func (l *LoadAware) update(stats []micro.Stats, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elapsed := now.Sub(l.polled).Seconds()
+	totals := make(map[string]time.Duration, len(stats))
+	instances := make([]instanceLoad, 0, len(stats))
+	for _, s := range stats {
+		var total time.Duration
+		for _, e := range s.Endpoints {
+			total += e.ProcessingTime
+		}
+		totals[s.ID] = total
+
+		// an instance seen for the first time has no load yet, so it ramps up as it takes requests
+		var load float64
+		if prev, ok := l.totals[s.ID]; ok && elapsed > 0 && total >= prev {
+			load = (total - prev).Seconds() / elapsed
+		}
+		instances = append(instances, instanceLoad{id: s.ID, load: load})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].id < instances[j].id })
+
+	l.instances, l.totals, l.polled = instances, totals, now
+}
+
+// pick returns the less loaded of two random instances, or false if none are known. Picking from two instead
+// of always taking the least loaded keeps every client from piling onto the same instance between polls.
+func (l *LoadAware) pick() (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch len(l.instances) {
+	case 0:
+		return "", false
+	case 1:
+		return l.instances[0].id, true
+	}
+
+	a := l.instances[l.intn(len(l.instances))]
+	b := l.instances[l.intn(len(l.instances))]
+	if b.load < a.load {
+		return b.id, true
+	}
+
+	return a.id, true
+}
+
+// forget drops an instance that stopped answering until the next poll
+func (l *LoadAware) forget(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, v := range l.instances {
+		if v.id == id {
+			l.instances = append(l.instances[:i:i], l.instances[i+1:]...)
+			return
+		}
+	}
+}
+
+// RequestMsgWithContext sends msg to a picked instance on its DirectSubject. When the instance has no
+// responders, it is forgotten and msg is sent to its own subject instead.
+func (l *LoadAware) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	id, ok := l.pick()
+	if !ok {
+		return l.nc.RequestMsgWithContext(ctx, msg)
+	}
+
+	l.direct.WithLabelValues(l.service, id).Inc()
+	direct := &nats.Msg{Subject: l.directSubject(id, msg.Subject), Header: msg.Header, Data: msg.Data}
+	reply, err := l.nc.RequestMsgWithContext(ctx, direct)
+	if !errors.Is(err, nats.ErrNoResponders) {
+		return reply, err
+	}
+
+	l.forget(id)
+	l.fallbacks.Inc()
+	return l.nc.RequestMsgWithContext(ctx, msg)
+}
+
+// directSubject returns the subject instance id answers subject on, the way a micro.Group prefixes the
+// subjects of DirectEndpoints
+func (l *LoadAware) directSubject(id, subject string) string {
+	rest, ok := strings.CutPrefix(subject, l.group+".")
+	if l.group == "" || !ok {
+		return DirectSubject(id, subject)
+	}
+
+	return l.group + "." + DirectSubject(id, rest)
+}
+
+// gatherStats collects the stats of every instance of service that answers within timeout
+func gatherStats(ctx context.Context, nc *nats.Conn, service string, timeout time.Duration) ([]micro.Stats, error) {
+	subject, err := micro.ControlSubject(micro.StatsVerb, service, "")
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := nc.NewInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	if err := nc.PublishRequest(subject, inbox, nil); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stats []micro.Stats
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			// instances are done answering once the timeout passes
+			if errors.Is(err, context.DeadlineExceeded) {
+				return stats, nil
+			}
+			return stats, err
+		}
+
+		var s micro.Stats
+		if err := json.Unmarshal(msg.Data, &s); err != nil {
+			continue
+		}
+		stats = append(stats, s)
+	}
+}
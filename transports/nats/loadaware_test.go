@@ -0,0 +1,150 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type subjectRecorder struct {
+	mu       sync.Mutex
+	subjects []string
+	down     string
+}
+
+func (s *subjectRecorder) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subjects = append(s.subjects, msg.Subject)
+	if s.down != "" && strings.HasPrefix(msg.Subject, DirectSubject(s.down, "")) {
+		return nil, nats.ErrNoResponders
+	}
+	return &nats.Msg{Data: []byte("ok")}, nil
+}
+
+func stats(id string, processing time.Duration) micro.Stats {
+	s := micro.Stats{Endpoints: []*micro.EndpointStats{{Name: "get", ProcessingTime: processing}}}
+	s.ID = id
+	return s
+}
+
+func TestLoadAware(t *testing.T) {
+	rec := &subjectRecorder{}
+	l := NewLoadAware(nil, "orders")
+	l.nc = rec
+	reg := sdtest.NewRegistry(t, l.Metrics()...)
+
+	var picks int
+	// pick the first and last instance so the comparison decides
+	l.intn = func(n int) int {
+		picks++
+		if picks%2 == 0 {
+			return n - 1
+		}
+		return 0
+	}
+
+	ctx := context.Background()
+	if _, err := l.RequestMsgWithContext(ctx, nats.NewMsg("orders.get")); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	l.update([]micro.Stats{stats("a", 0), stats("b", 0)}, start)
+	// a was busy for 9 of the last 10 seconds and b for 1
+	l.update([]micro.Stats{stats("a", 9*time.Second), stats("b", time.Second)}, start.Add(10*time.Second))
+	if _, err := l.RequestMsgWithContext(ctx, nats.NewMsg("orders.get")); err != nil {
+		t.Fatal(err)
+	}
+
+	rec.down = "b"
+	if _, err := l.RequestMsgWithContext(ctx, nats.NewMsg("orders.get")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.RequestMsgWithContext(ctx, nats.NewMsg("orders.get")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"orders.get",
+		"direct.b.orders.get",
+		"direct.b.orders.get", "orders.get",
+		"direct.a.orders.get",
+	}
+	if strings.Join(rec.subjects, ",") != strings.Join(want, ",") {
+		t.Errorf("expected requests to %v but got %v", want, rec.subjects)
+	}
+	reg.AssertCounter(t, "nats_direct_requests", prometheus.Labels{"service": "orders", "instance": "b"}, 2)
+	reg.AssertCounter(t, "nats_direct_fallbacks", nil, 1)
+}
+
+func TestLoadAwareGroup(t *testing.T) {
+	rec := &subjectRecorder{}
+	l := NewLoadAware(nil, "orders", SetDirectGroup("svc.orders"))
+	l.nc = rec
+	l.update([]micro.Stats{stats("a", 0)}, time.Now())
+
+	if _, err := l.RequestMsgWithContext(context.Background(), nats.NewMsg("svc.orders.get")); err != nil {
+		t.Fatal(err)
+	}
+
+	// a micro.Group joins its prefix and the endpoint's subject with a dot
+	direct := DirectEndpoints("a", Endpoint{Name: "get", Subject: "get"})
+	want := "svc.orders." + direct[0].Subject
+	if len(rec.subjects) != 1 || rec.subjects[0] != want {
+		t.Errorf("expected a request to %s but got %v", want, rec.subjects)
+	}
+}
+
+func TestDirectEndpoints(t *testing.T) {
+	endpoints := []Endpoint{{Name: "get", Subject: "orders.get"}, {Name: "list"}}
+	direct := DirectEndpoints("abc", endpoints...)
+	if err := ValidateEndpoints(append(endpoints, direct...)...); err != nil {
+		t.Fatal(err)
+	}
+	if direct[0].Subject != "direct.abc.orders.get" || direct[1].Subject != "direct.abc.list" || direct[1].Name != "list-direct" {
+		t.Errorf("unexpected direct endpoints %+v", direct)
+	}
+	if endpoints[0].Subject != "orders.get" {
+		t.Error("expected the endpoints to be left unchanged")
+	}
+}
+
+func TestLoadAwareRunCancelled(t *testing.T) {
+	var logs bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	l := NewLoadAware(nil, "orders", SetLoadLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	l.fetch = func(ctx context.Context) ([]micro.Stats, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	l.Run(ctx)
+	if logs.Len() != 0 {
+		t.Errorf("expected cancellation not to be logged but got %s", logs.String())
+	}
+}
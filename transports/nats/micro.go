@@ -26,6 +26,7 @@ import (
 	"time"
 
 	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/tracing"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/micro"
 	"github.com/segmentio/ksuid"
@@ -51,8 +52,26 @@ type AppContext struct {
 	Logger     *slog.Logger
 	Tracer     trace.Tracer
 	Propagator propagation.TextMapPropagator
+	// Payloads attaches request and response payload samples to spans when set. Error responses with a 5xx code
+	// are failed requests.
+	Payloads *tracing.PayloadSampler
 }
 
+// probeParent is an unsampled remote parent for detecting noop tracers. A tracer that creates spans gives its child
+// a new span ID, while a noop tracer, including the global tracer before a provider is registered, returns the
+// parent itself.
+var probeParent = trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, Remote: true})
+
+// discardLogger stands in for a nil AppContext.Logger
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
 type ClientError interface {
 	Error() string
 	Code() int
@@ -76,15 +95,36 @@ func (m microHeaderCarrier) Keys() []string {
 	return keys
 }
 
+// TracingEnabled reports whether the AppContext has a tracer that creates spans and a propagator. A nil or noop
+// tracer, or the global tracer before a provider is registered, disables span creation and header propagation in
+// ErrorHandler.
+func (a AppContext) TracingEnabled() bool {
+	if a.Tracer == nil || a.Propagator == nil {
+		return false
+	}
+
+	// the probe span is unsampled and never ended, so it isn't exported
+	_, span := a.Tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), probeParent), "probe")
+	return span.SpanContext().SpanID() != probeParent.SpanID()
+}
+
 func (h HandlerContext) InjectTraceHeaders(ctx context.Context, headers map[string][]string) {
-	h.Propagator.Inject(ctx, microHeaderCarrier(headers))
+	InjectTraceHeaders(ctx, h.Propagator, headers)
 }
 
 func InjectTraceHeaders(ctx context.Context, p propagation.TextMapPropagator, headers map[string][]string) {
+	if p == nil {
+		return
+	}
 	p.Inject(ctx, microHeaderCarrier(headers))
 }
 
 func HandleNotify(s micro.Service, healthFuncs ...func(chan<- string, micro.Service)) error {
+	return HandleNotifyContext(context.Background(), s, healthFuncs...)
+}
+
+// HandleNotifyContext is like HandleNotify but also stops the service when the context is cancelled
+func HandleNotifyContext(ctx context.Context, s micro.Service, healthFuncs ...func(chan<- string, micro.Service)) error {
 	stopChan := make(chan string, 1)
 	for _, v := range healthFuncs {
 		go v(stopChan, s)
@@ -92,7 +132,12 @@ func HandleNotify(s micro.Service, healthFuncs ...func(chan<- string, micro.Serv
 
 	go handleNotify(stopChan)
 
-	slog.Info(<-stopChan)
+	select {
+	case msg := <-stopChan:
+		slog.Info(msg)
+	case <-ctx.Done():
+		slog.Info(fmt.Sprintf("context done: %v", ctx.Err()))
+	}
 	return s.Stop()
 }
 
@@ -106,19 +151,32 @@ func handleNotify(stopChan chan<- string) {
 
 // ErrorHandler wraps a normal micro endpoint and allows for returning errors natively. Errors are
 // checked and if an error is a client error, details are returned, otherwise a 500 is returned and logged
+// If tracing is not enabled on the AppContext, no spans are created and trace headers are not extracted. It is
+// checked on every request, so a global tracer starts tracing once its provider is registered. Likewise the
+// request logger's attributes are only added when the logger writes errors, and the duration is only logged when
+// it writes info records. A nil logger logs nothing.
 func ErrorHandler(name string, a AppContext, handler AppHandler) micro.Handler {
 	ctx := context.Background()
+	logger := a.Logger
+	if logger == nil {
+		logger = discardLogger
+	}
 	return micro.ContextHandler(ctx, func(ctx context.Context, r micro.Request) {
-		start := time.Now()
 		id, err := MsgID(r)
 		if err != nil {
-			handleRequestError(a.Logger, sderrors.NewClientError(err, 400), r)
+			handleRequestError(logger, sderrors.NewClientError(err, 400), r)
 			return
 		}
-		reqLogger := a.Logger.With("request_id", id, "path", r.Subject())
-		defer func() {
-			reqLogger.Info("duration", "milliseconds", time.Since(start).Milliseconds())
-		}()
+		reqLogger := logger
+		if logger.Enabled(ctx, slog.LevelError) {
+			reqLogger = logger.With("request_id", id, "path", r.Subject())
+		}
+		if reqLogger.Enabled(ctx, slog.LevelInfo) {
+			start := time.Now()
+			defer func() {
+				reqLogger.Info("duration", "milliseconds", time.Since(start).Milliseconds())
+			}()
+		}
 
 		if err := buildQueryHeaders(r); err != nil {
 			handleRequestError(reqLogger, err, r)
@@ -130,12 +188,30 @@ func ErrorHandler(name string, a AppContext, handler AppHandler) micro.Handler {
 			Propagator: a.Propagator,
 		}
 
+		if !a.TracingEnabled() {
+			if err := handler(ctx, r, handlerCtx); err != nil {
+				handleRequestError(reqLogger, err, r)
+			}
+			return
+		}
+
 		headers := r.Headers()
 		newCtx := a.Propagator.Extract(ctx, microHeaderCarrier(headers))
 		startCtx, span := a.Tracer.Start(newCtx, name)
 		span.SetAttributes(attribute.KeyValue{Key: "X-Request-ID", Value: attribute.StringValue(id)})
 		defer span.End()
 
+		if a.Payloads != nil {
+			sampled := &sampledRequest{Request: r}
+			r = sampled
+			defer func() {
+				if a.Payloads.Sampled(span, sampled.failed) {
+					a.Payloads.Record(span, "request", sampled.Data())
+					a.Payloads.Record(span, "response", sampled.response)
+				}
+			}()
+		}
+
 		err = handler(startCtx, r, handlerCtx)
 		if err == nil {
 			span.SetStatus(codes.Ok, "success")
@@ -178,7 +254,11 @@ func handleRequestError(logger *slog.Logger, err error, r micro.Request) {
 		for _, v := range ce.LoggedError() {
 			logger.Error(v.Error())
 		}
-		r.Error(fmt.Sprintf("%d", ce.Code()), http.StatusText(ce.Code()), ce.Body())
+		body := ce.Body()
+		if l, ok := ce.(interface{ LocalizedBody(string) []byte }); ok {
+			body = l.LocalizedBody(r.Headers().Get("Accept-Language"))
+		}
+		r.Error(fmt.Sprintf("%d", ce.Code()), http.StatusText(ce.Code()), body)
 		return
 	}
 
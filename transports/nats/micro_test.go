@@ -0,0 +1,187 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go/micro"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fakeRequest struct {
+	micro.Request
+	subject string
+	headers micro.Headers
+}
+
+func (f *fakeRequest) Headers() micro.Headers { return f.headers }
+func (f *fakeRequest) Subject() string        { return f.subject }
+
+func TestBuildQueryHeaders(t *testing.T) {
+	tt := []struct {
+		name    string
+		query   string
+		key     string
+		want    []string
+		invalid bool
+	}{
+		{name: "single", query: "limit=10", key: "limit", want: []string{"10"}},
+		{name: "repeated", query: "tag=a&tag=b", key: "tag", want: []string{"a", "b"}},
+		{name: "escaped", query: "q=a%20b%26c", key: "q", want: []string{"a b&c"}},
+		{name: "missing", query: "limit=10", key: "offset"},
+		{name: "invalid escape", query: "q=%zz", invalid: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			r := &fakeRequest{headers: micro.Headers{"X-NatsBridge-UrlQuery": {v.query}}}
+			err := buildQueryHeaders(r)
+			if (err != nil) != v.invalid {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := GetQueryHeaders(r.headers, v.key); !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %v but got %v", v.want, got)
+			}
+		})
+	}
+}
+
+func FuzzBuildQueryHeaders(f *testing.F) {
+	for _, v := range []string{"limit=10&tag=a&tag=b", "q=a%20b", "a=1;b=2", "%zz", "=", "&&", "k=%00"} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		r := &fakeRequest{headers: micro.Headers{"X-NatsBridge-UrlQuery": {query}}}
+		if err := buildQueryHeaders(r); err != nil {
+			return
+		}
+
+		parsed, _ := url.ParseQuery(query)
+		for k, want := range parsed {
+			if got := GetQueryHeaders(r.headers, k); !reflect.DeepEqual(got, want) {
+				t.Errorf("query %q: expected %s to be %q but got %q", query, k, want, got)
+			}
+		}
+	})
+}
+
+func TestTracingEnabled(t *testing.T) {
+	tp, rec := sdtest.NewTracerProvider(t)
+
+	tt := []struct {
+		name    string
+		tracer  trace.Tracer
+		enabled bool
+	}{
+		{name: "nil tracer"},
+		{name: "noop tracer", tracer: trace.NewNoopTracerProvider().Tracer("orders")},
+		{name: "global tracer without a provider", tracer: otel.Tracer("orders")},
+		{name: "sdk tracer", tracer: tp.Tracer("orders"), enabled: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			a := AppContext{Tracer: v.tracer, Propagator: propagation.TraceContext{}}
+			if got := a.TracingEnabled(); got != v.enabled {
+				t.Errorf("expected tracing enabled %t but got %t", v.enabled, got)
+			}
+		})
+	}
+
+	if spans := rec.Spans(); len(spans) != 0 {
+		t.Errorf("expected the probe not to be exported but got %d spans", len(spans))
+	}
+}
+
+// lateTracer is a noop tracer until next is set, like the global tracer before its provider is registered
+type lateTracer struct {
+	trace.Tracer
+	next trace.Tracer
+}
+
+func (l *lateTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if l.next != nil {
+		return l.next.Start(ctx, name, opts...)
+	}
+	return l.Tracer.Start(ctx, name, opts...)
+}
+
+func TestErrorHandlerLateProvider(t *testing.T) {
+	tp, rec := sdtest.NewTracerProvider(t)
+	tracer := &lateTracer{Tracer: trace.NewNoopTracerProvider().Tracer("orders")}
+	h := ErrorHandler("get", AppContext{Tracer: tracer, Propagator: propagation.TraceContext{}}, func(ctx context.Context, r micro.Request, h HandlerContext) error {
+		return nil
+	})
+
+	h.Handle(&fakeRequest{subject: "orders.get", headers: micro.Headers{"X-Request-ID": {"1"}}})
+	tracer.next = tp.Tracer("orders")
+	h.Handle(&fakeRequest{subject: "orders.get", headers: micro.Headers{"X-Request-ID": {"2"}}})
+
+	if spans := rec.Spans(); len(spans) != 1 || spans[0].Name() != "get" {
+		t.Errorf("expected only the request after the provider was registered to be traced but got %d spans", len(spans))
+	}
+}
+
+func TestErrorHandlerLogging(t *testing.T) {
+	tt := []struct {
+		name  string
+		level slog.Level
+		want  string
+	}{
+		{name: "info", level: slog.LevelInfo, want: "msg=duration request_id=1 path=orders.get"},
+		{name: "warn", level: slog.LevelWarn},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: v.level}))
+
+			var got *slog.Logger
+			h := ErrorHandler("get", AppContext{Logger: logger}, func(ctx context.Context, r micro.Request, h HandlerContext) error {
+				got = h.Logger
+				return nil
+			})
+			h.Handle(&fakeRequest{subject: "orders.get", headers: micro.Headers{"X-Request-ID": {"1"}}})
+
+			if got == nil {
+				t.Fatal("expected the handler to run")
+			}
+			if v.want == "" && buf.Len() != 0 {
+				t.Errorf("expected nothing to be logged but got %q", buf.String())
+			}
+			if !strings.Contains(buf.String(), v.want) {
+				t.Errorf("expected the log to contain %q but got %q", v.want, buf.String())
+			}
+		})
+	}
+
+	h := ErrorHandler("get", AppContext{}, func(ctx context.Context, r micro.Request, h HandlerContext) error {
+		h.Logger.Info("handled")
+		return nil
+	})
+	h.Handle(&fakeRequest{subject: "orders.get", headers: micro.Headers{"X-Request-ID": {"1"}}})
+}
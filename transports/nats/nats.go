@@ -25,7 +25,9 @@ import (
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/SencilloDev/sencillo-go/retry"
 	"github.com/nats-io/nats.go"
+	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
@@ -36,6 +38,8 @@ type NATSClient struct {
 	Conn    *nats.Conn
 	JS      nats.JetStreamContext
 	NATSGraph
+	retry     *retry.Policy
+	heartbeat time.Duration
 }
 
 type NATSGraph struct {
@@ -76,6 +80,13 @@ func SetSubject(s string) ClientOpt {
 	}
 }
 
+// SetConnectRetry retries the initial connection with the given policy
+func SetConnectRetry(p retry.Policy) ClientOpt {
+	return func(n *NATSClient) {
+		n.retry = &p
+	}
+}
+
 func SetGraphQLExecutableSchema(e graphql.ExecutableSchema) ClientOpt {
 	ng := NATSGraph{
 		ExecutableSchema: e,
@@ -87,7 +98,16 @@ func SetGraphQLExecutableSchema(e graphql.ExecutableSchema) ClientOpt {
 }
 
 func (n *NATSClient) Connect() error {
-	nc, err := nats.Connect(n.Servers, n.Options...)
+	connect := func(context.Context) (*nats.Conn, error) {
+		return nats.Connect(n.Servers, n.Options...)
+	}
+
+	policy := retry.NewPolicy(retry.SetMaxAttempts(1))
+	if n.retry != nil {
+		policy = *n.retry
+	}
+
+	nc, err := retry.DoValue(context.Background(), policy, connect)
 	if err != nil {
 		return err
 	}
@@ -166,6 +186,12 @@ func (n *NATSClient) HandleAndLogRequests(m *nats.Msg) {
 		return
 	}
 
+	// subscriptions stream events to the reply subject instead of answering once
+	if rc.Operation != nil && rc.Operation.Operation == ast.Subscription && m.Reply != "" {
+		go n.subscribe(ctx, m.Reply, rc)
+		return
+	}
+
 	var responses graphql.ResponseHandler
 	responses, ctx = n.Exec.DispatchOperation(ctx, rc)
 	m.RespondMsg(natsResponse(responses(ctx)))
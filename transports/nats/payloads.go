@@ -0,0 +1,52 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+// sampledRequest keeps the response of a request so the payload sampler can attach it to the span
+type sampledRequest struct {
+	micro.Request
+	response []byte
+	failed   bool
+}
+
+func (r *sampledRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	r.response = data
+	return r.Request.Respond(data, opts...)
+}
+
+func (r *sampledRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return r.Respond(data, opts...)
+}
+
+func (r *sampledRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	r.response = data
+	if status, err := strconv.Atoi(code); err != nil || status >= 500 {
+		r.failed = true
+	}
+
+	return r.Request.Error(code, description, data, opts...)
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// PriorityLimit admits requests through the limiter by the priority in concurrency.PriorityHeader. A micro
+// endpoint handles one request at a time, so each request is handled in its own goroutine once admitted and the
+// limiter decides the concurrency. Shed requests get a 503 error.
+func PriorityLimit(l *concurrency.Limiter, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		p, _ := concurrency.ParsePriority(r.Headers().Get(concurrency.PriorityHeader))
+		go func() {
+			release, err := l.Acquire(context.Background(), p)
+			if err != nil {
+				r.Error("503", http.StatusText(http.StatusServiceUnavailable), []byte(fmt.Sprintf(`{"errors": [%q]}`, err.Error())))
+				return
+			}
+			defer release()
+
+			h.Handle(r)
+		}()
+	})
+}
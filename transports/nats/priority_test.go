@@ -0,0 +1,73 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/concurrency"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// signalRequest signals once it has replied with an error
+type signalRequest struct {
+	*replyRequest
+	done chan struct{}
+}
+
+func (r signalRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	defer close(r.done)
+	return r.replyRequest.Error(code, description, data, opts...)
+}
+
+func TestPriorityLimit(t *testing.T) {
+	l := concurrency.NewLimiter(1, concurrency.SetMaxQueue(1), concurrency.SetMaxWait(time.Minute))
+	release, err := l.Acquire(context.Background(), concurrency.PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan string, 2)
+	h := PriorityLimit(l, micro.HandlerFunc(func(r micro.Request) {
+		handled <- r.Headers().Get(concurrency.PriorityHeader)
+		r.Respond([]byte("ok"))
+	}))
+
+	low := signalRequest{
+		replyRequest: &replyRequest{msg: &nats.Msg{Subject: "orders.get", Header: nats.Header{concurrency.PriorityHeader: {"low"}}}},
+		done:         make(chan struct{}),
+	}
+	h.Handle(low)
+	time.Sleep(20 * time.Millisecond)
+	h.Handle(&replyRequest{msg: &nats.Msg{Subject: "orders.get", Header: nats.Header{concurrency.PriorityHeader: {"high"}}}})
+	time.Sleep(20 * time.Millisecond)
+	release()
+
+	select {
+	case p := <-handled:
+		if p != "high" {
+			t.Errorf("expected the high request to be handled but got %s", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the high request to be handled once the slot was free")
+	}
+	<-low.done
+	if low.reply == nil || low.reply.Header.Get(micro.ErrorCodeHeader) != "503" {
+		t.Errorf("expected the low request to be shed with a 503 but got %v", low.reply)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrBackpressure = fmt.Errorf("publisher is backed up")
+
+// AsyncPublisher publishes JetStream messages without waiting for acks. nats.JetStreamContext implements it.
+type AsyncPublisher interface {
+	PublishMsgAsync(m *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error)
+	PublishAsyncPending() int
+	PublishAsyncComplete() <-chan struct{}
+}
+
+// Buffered reports the bytes waiting in a connection's outgoing buffer. *nats.Conn implements it.
+type Buffered interface {
+	Buffered() (int, error)
+}
+
+// PublisherOpt is a functional option to modify the Publisher
+type PublisherOpt func(*Publisher)
+
+// Publisher publishes JetStream messages asynchronously and applies backpressure when the broker falls behind.
+// Once too many acks are pending, or the connection's outgoing buffer is too big, a publish blocks until they
+// drain, or fails with ErrBackpressure when shedding, instead of letting memory grow.
+type Publisher struct {
+	js          AsyncPublisher
+	conn        Buffered
+	maxPending  int
+	maxBuffered int
+	shed        bool
+	poll        time.Duration
+
+	pending prometheus.GaugeFunc
+	blocked *prometheus.HistogramVec
+	shedded *prometheus.CounterVec
+}
+
+// SetMaxPending sets how many publishes can wait for an ack. The default is 1000.
+func SetMaxPending(n int) PublisherOpt {
+	return func(p *Publisher) {
+		p.maxPending = n
+	}
+}
+
+// SetMaxBuffered sets how many bytes can wait in the connection's outgoing buffer, checked when SetPublisherConn
+// is used. The default is 8MB.
+func SetMaxBuffered(n int) PublisherOpt {
+	return func(p *Publisher) {
+		p.maxBuffered = n
+	}
+}
+
+// SetPublisherConn sets the connection whose outgoing buffer is checked
+func SetPublisherConn(c Buffered) PublisherOpt {
+	return func(p *Publisher) {
+		p.conn = c
+	}
+}
+
+// SetShed makes a backed up publish fail right away with ErrBackpressure instead of blocking
+func SetShed(shed bool) PublisherOpt {
+	return func(p *Publisher) {
+		p.shed = shed
+	}
+}
+
+// NewPublisher returns a Publisher publishing with js
+func NewPublisher(js AsyncPublisher, opts ...PublisherOpt) *Publisher {
+	p := &Publisher{
+		js:          js,
+		maxPending:  1000,
+		maxBuffered: 8 << 20,
+		poll:        5 * time.Millisecond,
+		blocked:     metrics.NewHistogramVec("nats_publish_blocked_seconds", "Time publishes waited for backpressure to clear", []string{"reason"}),
+		shedded:     metrics.NewCounterVec("nats_publish_shed", "Publishes failed with backpressure", []string{"reason"}),
+	}
+	p.pending = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "nats_publish_pending",
+		Help: "Publishes waiting for an ack",
+	}, func() float64 { return float64(p.js.PublishAsyncPending()) })
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Metrics returns the publisher's collectors to register with the exporter
+func (p *Publisher) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{p.pending, p.blocked, p.shedded}
+}
+
+// backedUp returns why the publisher is backed up, or an empty string if it isn't
+func (p *Publisher) backedUp() string {
+	if p.js.PublishAsyncPending() >= p.maxPending {
+		return "pending"
+	}
+	if p.conn != nil {
+		if n, err := p.conn.Buffered(); err == nil && n >= p.maxBuffered {
+			return "buffered"
+		}
+	}
+
+	return ""
+}
+
+// Publish publishes msg once the publisher isn't backed up. It waits until ctx is done unless the publisher sheds.
+// Check the returned future for the ack.
+func (p *Publisher) Publish(ctx context.Context, msg *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	if reason := p.backedUp(); reason != "" {
+		if err := p.wait(ctx, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.js.PublishMsgAsync(msg, opts...)
+}
+
+// wait blocks until the publisher isn't backed up
+func (p *Publisher) wait(ctx context.Context, reason string) error {
+	if p.shed {
+		p.shedded.WithLabelValues(reason).Inc()
+		return fmt.Errorf("%w: too many %s", ErrBackpressure, reason)
+	}
+
+	start := time.Now()
+	defer func() {
+		p.blocked.WithLabelValues(reason).Observe(time.Since(start).Seconds())
+	}()
+
+	ticker := time.NewTicker(p.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.shedded.WithLabelValues(reason).Inc()
+			return fmt.Errorf("%w: %w", ErrBackpressure, ctx.Err())
+		case <-ticker.C:
+		}
+		if p.backedUp() == "" {
+			return nil
+		}
+	}
+}
+
+// Flush waits until every publish has been acked or ctx is done
+func (p *Publisher) Flush(ctx context.Context) error {
+	select {
+	case <-p.js.PublishAsyncComplete():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
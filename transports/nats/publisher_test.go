@@ -0,0 +1,103 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeAsync struct {
+	pending   atomic.Int32
+	published atomic.Int32
+}
+
+func (f *fakeAsync) PublishMsgAsync(m *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	f.published.Add(1)
+	f.pending.Add(1)
+	return nil, nil
+}
+
+func (f *fakeAsync) PublishAsyncPending() int { return int(f.pending.Load()) }
+
+func (f *fakeAsync) PublishAsyncComplete() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+type fakeBuffered int
+
+func (f fakeBuffered) Buffered() (int, error) { return int(f), nil }
+
+func TestPublisher(t *testing.T) {
+	tt := []struct {
+		name      string
+		opts      []PublisherOpt
+		drain     bool
+		err       error
+		reason    string
+		shed      float64
+		blocked   uint64
+		published int32
+	}{
+		{name: "blocks until drained", drain: true, reason: "pending", blocked: 1, published: 3},
+		{name: "sheds", opts: []PublisherOpt{SetShed(true)}, err: ErrBackpressure, reason: "pending", shed: 1, published: 2},
+		{name: "times out", err: context.DeadlineExceeded, reason: "pending", shed: 1, blocked: 1, published: 2},
+		{name: "buffered", opts: []PublisherOpt{SetMaxPending(10), SetMaxBuffered(10), SetPublisherConn(fakeBuffered(20)), SetShed(true)}, err: ErrBackpressure, reason: "buffered", shed: 1, published: 0},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			js := &fakeAsync{}
+			p := NewPublisher(js, append([]PublisherOpt{SetMaxPending(2)}, v.opts...)...)
+			reg := sdtest.NewRegistry(t, p.Metrics()...)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			var err error
+			for i := 0; i < 3 && err == nil; i++ {
+				if i == 2 && v.drain {
+					go func() {
+						time.Sleep(10 * time.Millisecond)
+						js.pending.Store(0)
+					}()
+				}
+				_, err = p.Publish(ctx, nats.NewMsg("orders.created"))
+			}
+
+			if !errors.Is(err, v.err) || (v.err != nil && !errors.Is(err, ErrBackpressure)) {
+				t.Errorf("expected %v but got %v", v.err, err)
+			}
+			if n := js.published.Load(); n != v.published {
+				t.Errorf("expected %d publishes but got %d", v.published, n)
+			}
+			labels := prometheus.Labels{"reason": v.reason}
+			if v.shed > 0 {
+				reg.AssertCounter(t, "nats_publish_shed", labels, v.shed)
+			}
+			if v.blocked > 0 {
+				reg.AssertHistogramCount(t, "nats_publish_blocked_seconds", labels, v.blocked)
+			}
+		})
+	}
+}
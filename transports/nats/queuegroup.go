@@ -0,0 +1,158 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Environment variables read by TopologyFromEnv, along with SENCILLO_REGION
+const (
+	ZoneEnv               = "SENCILLO_ZONE"
+	VariantEnv            = "SENCILLO_VARIANT"
+	CanaryEnv             = "SENCILLO_CANARY"
+	QueueGroupTemplateEnv = "SENCILLO_QUEUE_GROUP"
+)
+
+// DefaultQueueGroupTemplate puts every instance of a service in one queue group
+const DefaultQueueGroupTemplate = "{service}"
+
+// VariantStable and VariantCanary are the variants set by SENCILLO_CANARY
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+var ErrInvalidQueueGroup = fmt.Errorf("invalid queue group")
+
+// Topology is where and as what an instance is deployed. It names the instance's queue groups from a template, so
+// operators decide which instances share requests by setting SENCILLO_QUEUE_GROUP, without a code change.
+type Topology struct {
+	Region  string
+	Zone    string
+	Variant string
+	// Template names queue groups. {service}, {region}, {zone}, and {variant} are replaced with their values.
+	Template string
+}
+
+// TopologyFromEnv returns the topology from SENCILLO_REGION, SENCILLO_ZONE, SENCILLO_VARIANT, and
+// SENCILLO_QUEUE_GROUP. SENCILLO_CANARY=true sets the variant to canary when SENCILLO_VARIANT is empty. The
+// variant defaults to stable and the template to DefaultQueueGroupTemplate.
+func TopologyFromEnv() (Topology, error) {
+	t := Topology{
+		Region:   os.Getenv(RegionEnv),
+		Zone:     os.Getenv(ZoneEnv),
+		Variant:  os.Getenv(VariantEnv),
+		Template: os.Getenv(QueueGroupTemplateEnv),
+	}
+	if t.Variant == "" {
+		t.Variant = VariantStable
+		if v := os.Getenv(CanaryEnv); v != "" {
+			canary, err := strconv.ParseBool(v)
+			if err != nil {
+				return Topology{}, fmt.Errorf("%w: %s: %v", ErrInvalidQueueGroup, CanaryEnv, err)
+			}
+			if canary {
+				t.Variant = VariantCanary
+			}
+		}
+	}
+	if t.Template == "" {
+		t.Template = DefaultQueueGroupTemplate
+	}
+
+	_, err := t.QueueGroup("service")
+	return t, err
+}
+
+// QueueGroup returns the queue group of the service's endpoints. It fails when the template uses a value the
+// topology doesn't have, or the result isn't a valid queue group name.
+func (t Topology) QueueGroup(service string) (string, error) {
+	template := t.Template
+	if template == "" {
+		template = DefaultQueueGroupTemplate
+	}
+
+	values := map[string]string{"service": service, "region": t.Region, "zone": t.Zone, "variant": t.Variant}
+	group := template
+	for name, v := range values {
+		token := "{" + name + "}"
+		if !strings.Contains(group, token) {
+			continue
+		}
+		if v == "" {
+			return "", fmt.Errorf("%w: %q uses %s, which isn't set", ErrInvalidQueueGroup, template, token)
+		}
+		group = strings.ReplaceAll(group, token, v)
+	}
+
+	if strings.ContainsAny(group, "{}*>") || strings.IndexFunc(group, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidQueueGroup, group)
+	}
+	return group, nil
+}
+
+// Metadata returns the topology as service metadata, so micro discovery shows which region, zone, and variant
+// each instance runs as
+func (t Topology) Metadata() map[string]string {
+	md := make(map[string]string)
+	for k, v := range map[string]string{"region": t.Region, "zone": t.Zone, "variant": t.Variant} {
+		if v != "" {
+			md[k] = v
+		}
+	}
+	return md
+}
+
+// Service returns the micro config with the queue group set from the template, unless it was already set, and
+// the topology added to its metadata
+func (t Topology) Service(cfg micro.Config) (micro.Config, error) {
+	if cfg.QueueGroup == "" {
+		group, err := t.QueueGroup(cfg.Name)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.QueueGroup = group
+	}
+
+	md := t.Metadata()
+	maps.Copy(md, cfg.Metadata)
+	cfg.Metadata = md
+	return cfg, nil
+}
+
+// Endpoints returns the endpoints with the queue group set from the template where it is empty
+func (t Topology) Endpoints(service string, endpoints ...Endpoint) ([]Endpoint, error) {
+	group, err := t.QueueGroup(service)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make([]Endpoint, len(endpoints))
+	for i, e := range endpoints {
+		if e.QueueGroup == "" {
+			e.QueueGroup = group
+		}
+		grouped[i] = e
+	}
+	return grouped, nil
+}
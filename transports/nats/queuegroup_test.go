@@ -0,0 +1,86 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestTopologyQueueGroup(t *testing.T) {
+	tt := []struct {
+		name  string
+		env   map[string]string
+		group string
+		err   error
+	}{
+		{name: "default", env: map[string]string{RegionEnv: "us-east"}, group: "orders"},
+		{name: "by variant", env: map[string]string{QueueGroupTemplateEnv: "{service}.{variant}", CanaryEnv: "true"}, group: "orders.canary"},
+		{name: "stable", env: map[string]string{QueueGroupTemplateEnv: "{service}.{variant}"}, group: "orders.stable"},
+		{name: "variant wins over canary", env: map[string]string{QueueGroupTemplateEnv: "{service}.{variant}", VariantEnv: "blue", CanaryEnv: "true"}, group: "orders.blue"},
+		{name: "by zone", env: map[string]string{QueueGroupTemplateEnv: "{service}-{region}-{zone}", RegionEnv: "us-east", ZoneEnv: "us-east-1a"}, group: "orders-us-east-us-east-1a"},
+		{name: "missing zone", env: map[string]string{QueueGroupTemplateEnv: "{service}-{zone}"}, err: ErrInvalidQueueGroup},
+		{name: "unknown token", env: map[string]string{QueueGroupTemplateEnv: "{service}-{cell}"}, err: ErrInvalidQueueGroup},
+		{name: "bad canary", env: map[string]string{CanaryEnv: "maybe"}, err: ErrInvalidQueueGroup},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for _, env := range []string{RegionEnv, ZoneEnv, VariantEnv, CanaryEnv, QueueGroupTemplateEnv} {
+				t.Setenv(env, v.env[env])
+			}
+			top, err := TopologyFromEnv()
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if v.err != nil {
+				return
+			}
+			group, err := top.QueueGroup("orders")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if group != v.group {
+				t.Errorf("expected queue group %s but got %s", v.group, group)
+			}
+		})
+	}
+}
+
+func TestTopologyService(t *testing.T) {
+	top := Topology{Region: "us-east", Variant: VariantCanary, Template: "{service}.{variant}"}
+
+	cfg, err := top.Service(micro.Config{Name: "orders", Metadata: map[string]string{"team": "checkout"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.QueueGroup != "orders.canary" {
+		t.Errorf("expected the queue group to be set but got %q", cfg.QueueGroup)
+	}
+	if want := map[string]string{"team": "checkout", "region": "us-east", "variant": "canary"}; !reflect.DeepEqual(cfg.Metadata, want) {
+		t.Errorf("expected metadata %v but got %v", want, cfg.Metadata)
+	}
+
+	endpoints, err := top.Endpoints("orders", Endpoint{Name: "get"}, Endpoint{Name: "audit", QueueGroup: "audit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoints[0].QueueGroup != "orders.canary" || endpoints[1].QueueGroup != "audit" {
+		t.Errorf("unexpected queue groups %q and %q", endpoints[0].QueueGroup, endpoints[1].QueueGroup)
+	}
+}
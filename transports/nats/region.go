@@ -0,0 +1,204 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Environment variables read by RegionFromEnv
+const (
+	RegionEnv          = "SENCILLO_REGION"
+	RegionFallbacksEnv = "SENCILLO_REGION_FALLBACKS"
+	RegionTimeoutEnv   = "SENCILLO_REGION_TIMEOUT"
+	RegionBudgetEnv    = "SENCILLO_REGION_BUDGET"
+)
+
+// RegionHeader carries the region a request was sent from
+const RegionHeader = "Sencillo-Region"
+
+var (
+	ErrNoRegion      = fmt.Errorf("region not set")
+	ErrInvalidRegion = fmt.Errorf("invalid region")
+	ErrRegionBudget  = fmt.Errorf("not enough time left to try another region")
+)
+
+// Region is where a service runs in a NATS supercluster. Each region's services listen on subjects prefixed with
+// the region's name, so a request reaches a region by its subject and gateways only carry it across when a
+// client asks for another region.
+type Region struct {
+	Name string
+	// Fallbacks are the regions to try, in order, when the local region can't answer
+	Fallbacks []string
+	// Timeout is how long the local region has to answer before the fallbacks are tried. Zero waits for the
+	// caller's deadline and only falls back when nothing in the region is listening.
+	Timeout time.Duration
+	// Budget is how long a fallback region has to answer. A fallback is only tried when the caller's deadline
+	// leaves at least that long.
+	Budget time.Duration
+}
+
+// RegionFromEnv returns the region from SENCILLO_REGION, with SENCILLO_REGION_FALLBACKS as a comma separated
+// list, and SENCILLO_REGION_TIMEOUT and SENCILLO_REGION_BUDGET as durations. The budget defaults to 1 second.
+func RegionFromEnv() (Region, error) {
+	r := Region{Name: os.Getenv(RegionEnv), Budget: time.Second}
+	if r.Name == "" {
+		return Region{}, fmt.Errorf("%w: %s is empty", ErrNoRegion, RegionEnv)
+	}
+	for _, f := range strings.Split(os.Getenv(RegionFallbacksEnv), ",") {
+		if f = strings.TrimSpace(f); f != "" && f != r.Name {
+			r.Fallbacks = append(r.Fallbacks, f)
+		}
+	}
+
+	for env, d := range map[string]*time.Duration{RegionTimeoutEnv: &r.Timeout, RegionBudgetEnv: &r.Budget} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return Region{}, fmt.Errorf("%w: %s: %v", ErrInvalidRegion, env, err)
+		}
+		*d = parsed
+	}
+
+	return r, r.Validate()
+}
+
+// Validate checks that the region and its fallbacks can be used as subject tokens
+func (r Region) Validate() error {
+	for _, name := range append([]string{r.Name}, r.Fallbacks...) {
+		if err := ValidateSubject(name, false); err != nil || strings.Contains(name, ".") {
+			return fmt.Errorf("%w: %q must be a single subject token", ErrInvalidRegion, name)
+		}
+	}
+	return nil
+}
+
+// RegionSubject prefixes subject with region
+func RegionSubject(region, subject string) string {
+	return region + "." + subject
+}
+
+// Subject prefixes subject with the region's name
+func (r Region) Subject(subject string) string {
+	return RegionSubject(r.Name, subject)
+}
+
+// Endpoints returns the endpoints with their subjects prefixed with the region's name, so the service only
+// answers requests sent to its region
+func (r Region) Endpoints(endpoints ...Endpoint) []Endpoint {
+	regional := make([]Endpoint, len(endpoints))
+	for i, e := range endpoints {
+		e.Subject = r.Subject(endpointSubject(e))
+		regional[i] = e
+	}
+	return regional
+}
+
+// RegionRouter sends requests to the local region first and falls back to the region's fallbacks in order when
+// nothing local answers in time. It implements Requester, so it can be passed to NewTypedClient. Requests are
+// sent with unprefixed subjects, which the router prefixes for each region it tries.
+type RegionRouter struct {
+	nc     Requester
+	region Region
+
+	requests *prometheus.CounterVec
+}
+
+// NewRegionRouter returns a RegionRouter sending requests with nc
+func NewRegionRouter(nc Requester, region Region) *RegionRouter {
+	return &RegionRouter{
+		nc:       nc,
+		region:   region,
+		requests: metrics.NewCounterVec("nats_region_requests", "Requests by the region that answered them or failed", []string{"region", "outcome"}),
+	}
+}
+
+// Metrics returns the router's collectors to register with the exporter
+func (r *RegionRouter) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{r.requests}
+}
+
+// RequestMsgWithContext sends msg to the local region and then to each fallback until one answers. A region is
+// skipped to the next when nothing there is listening or it doesn't answer within its time. Any other error is
+// returned as is.
+func (r *RegionRouter) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	reply, err := r.try(ctx, r.region.Name, r.region.Timeout, msg)
+	if !r.shouldFallBack(ctx, err) {
+		return reply, err
+	}
+
+	for _, region := range r.region.Fallbacks {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < r.region.Budget {
+			r.requests.WithLabelValues(region, "skipped").Inc()
+			return nil, fmt.Errorf("%w: %w", ErrRegionBudget, err)
+		}
+		reply, err = r.try(ctx, region, r.region.Budget, msg)
+		if !r.shouldFallBack(ctx, err) {
+			return reply, err
+		}
+	}
+
+	return nil, err
+}
+
+// try sends msg to one region, with the region's time to answer when it is set
+func (r *RegionRouter) try(ctx context.Context, region string, timeout time.Duration, msg *nats.Msg) (*nats.Msg, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	m := nats.NewMsg(RegionSubject(region, msg.Subject))
+	m.Data = msg.Data
+	for k, v := range msg.Header {
+		m.Header[k] = v
+	}
+	m.Header.Set(RegionHeader, r.region.Name)
+
+	reply, err := r.nc.RequestMsgWithContext(ctx, m)
+	switch {
+	case err == nil:
+		r.requests.WithLabelValues(region, "answered").Inc()
+	case errors.Is(err, nats.ErrNoResponders):
+		r.requests.WithLabelValues(region, "no_responders").Inc()
+	case errors.Is(err, context.DeadlineExceeded):
+		r.requests.WithLabelValues(region, "timeout").Inc()
+	default:
+		r.requests.WithLabelValues(region, "failed").Inc()
+	}
+	return reply, err
+}
+
+// shouldFallBack reports whether a region's error means another region should be tried. A timeout only counts
+// when it was the region's own, not the caller's deadline.
+func (r *RegionRouter) shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, nats.ErrNoResponders) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout)
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// regionRequester answers by the region token of the subject
+type regionRequester struct {
+	regions map[string]func(ctx context.Context) error
+	sent    []string
+	origin  []string
+}
+
+func (r *regionRequester) RequestMsgWithContext(ctx context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	r.sent = append(r.sent, msg.Subject)
+	r.origin = append(r.origin, msg.Header.Get(RegionHeader))
+	region, _, _ := strings.Cut(msg.Subject, ".")
+	answer, ok := r.regions[region]
+	if !ok {
+		return nil, nats.ErrNoResponders
+	}
+	if err := answer(ctx); err != nil {
+		return nil, err
+	}
+	return &nats.Msg{Data: []byte(region)}, nil
+}
+
+func answers(context.Context) error { return nil }
+
+func hangs(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRegionRouter(t *testing.T) {
+	tt := []struct {
+		name     string
+		regions  map[string]func(context.Context) error
+		deadline time.Duration
+		reply    string
+		err      error
+		sent     []string
+	}{
+		{
+			name:    "local",
+			regions: map[string]func(context.Context) error{"us-east": answers, "eu-west": answers},
+			reply:   "us-east",
+			sent:    []string{"us-east.orders.get"},
+		},
+		{
+			name:    "no local responders",
+			regions: map[string]func(context.Context) error{"eu-west": answers},
+			reply:   "eu-west",
+			sent:    []string{"us-east.orders.get", "eu-west.orders.get"},
+		},
+		{
+			name:    "local too slow",
+			regions: map[string]func(context.Context) error{"us-east": hangs, "ap-south": answers},
+			reply:   "ap-south",
+			sent:    []string{"us-east.orders.get", "eu-west.orders.get", "ap-south.orders.get"},
+		},
+		{
+			name:    "local error",
+			regions: map[string]func(context.Context) error{"us-east": func(context.Context) error { return nats.ErrBadSubject }},
+			err:     nats.ErrBadSubject,
+			sent:    []string{"us-east.orders.get"},
+		},
+		{
+			name:     "over budget",
+			regions:  map[string]func(context.Context) error{"eu-west": answers},
+			deadline: 50 * time.Millisecond,
+			err:      ErrRegionBudget,
+			sent:     []string{"us-east.orders.get"},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			nc := &regionRequester{regions: v.regions}
+			r := NewRegionRouter(nc, Region{
+				Name:      "us-east",
+				Fallbacks: []string{"eu-west", "ap-south"},
+				Timeout:   20 * time.Millisecond,
+				Budget:    100 * time.Millisecond,
+			})
+			reg := sdtest.NewRegistry(t, r.Metrics()...)
+
+			ctx := context.Background()
+			if v.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, v.deadline)
+				defer cancel()
+			}
+			reply, err := r.RequestMsgWithContext(ctx, &nats.Msg{Subject: "orders.get", Header: nats.Header{}})
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if v.err == nil && string(reply.Data) != v.reply {
+				t.Errorf("expected a reply from %s but got %s", v.reply, reply.Data)
+			}
+			if !reflect.DeepEqual(nc.sent, v.sent) {
+				t.Errorf("expected requests to %v but got %v", v.sent, nc.sent)
+			}
+			for _, origin := range nc.origin {
+				if origin != "us-east" {
+					t.Errorf("expected the origin region header but got %q", origin)
+				}
+			}
+			if v.reply != "" {
+				reg.AssertCounter(t, "nats_region_requests", prometheus.Labels{"region": v.reply, "outcome": "answered"}, 1)
+			}
+		})
+	}
+}
+
+func TestRegionFromEnv(t *testing.T) {
+	tt := []struct {
+		name   string
+		env    map[string]string
+		region Region
+		err    error
+	}{
+		{
+			name:   "full",
+			env:    map[string]string{RegionEnv: "us-east", RegionFallbacksEnv: "eu-west, us-east,ap-south", RegionTimeoutEnv: "250ms", RegionBudgetEnv: "2s"},
+			region: Region{Name: "us-east", Fallbacks: []string{"eu-west", "ap-south"}, Timeout: 250 * time.Millisecond, Budget: 2 * time.Second},
+		},
+		{
+			name:   "defaults",
+			env:    map[string]string{RegionEnv: "us-east"},
+			region: Region{Name: "us-east", Budget: time.Second},
+		},
+		{name: "missing", err: ErrNoRegion},
+		{name: "bad duration", env: map[string]string{RegionEnv: "us-east", RegionBudgetEnv: "soon"}, err: ErrInvalidRegion},
+		{name: "bad name", env: map[string]string{RegionEnv: "us.east"}, err: ErrInvalidRegion},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for _, env := range []string{RegionEnv, RegionFallbacksEnv, RegionTimeoutEnv, RegionBudgetEnv} {
+				t.Setenv(env, v.env[env])
+			}
+			region, err := RegionFromEnv()
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if v.err == nil && !reflect.DeepEqual(region, v.region) {
+				t.Errorf("expected %+v but got %+v", v.region, region)
+			}
+		})
+	}
+}
+
+func TestRegionEndpoints(t *testing.T) {
+	endpoints := Region{Name: "eu-west"}.Endpoints(Endpoint{Name: "get"}, Endpoint{Name: "list", Subject: "orders.list"})
+	if endpoints[0].Subject != "eu-west.get" || endpoints[1].Subject != "eu-west.orders.list" {
+		t.Errorf("unexpected subjects %s and %s", endpoints[0].Subject, endpoints[1].Subject)
+	}
+}
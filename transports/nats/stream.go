@@ -0,0 +1,175 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// StreamHeader marks the final message of a chunked stream
+	StreamHeader = "Sencillo-Stream"
+	// StreamErrorHeader carries an error that ended a chunked stream early
+	StreamErrorHeader = "Sencillo-Stream-Error"
+	streamEOF         = "eof"
+)
+
+var (
+	ErrStreamFailed   = fmt.Errorf("stream failed")
+	ErrStreamOverflow = fmt.Errorf("stream chunks were dropped because the reader fell behind")
+)
+
+// StreamWriter sends NDJSON records to a reply subject in chunks of up to chunkSize bytes so large results
+// don't exceed the server's max payload
+type StreamWriter struct {
+	nc        *nats.Conn
+	reply     string
+	chunkSize int
+	buf       bytes.Buffer
+	enc       *json.Encoder
+}
+
+// NewStreamWriter returns a StreamWriter publishing to reply. A chunkSize of zero or less uses half of the
+// server's max payload.
+func NewStreamWriter(nc *nats.Conn, reply string, chunkSize int) *StreamWriter {
+	if chunkSize <= 0 {
+		chunkSize = int(nc.MaxPayload() / 2)
+	}
+
+	s := &StreamWriter{
+		nc:        nc,
+		reply:     reply,
+		chunkSize: chunkSize,
+	}
+	s.enc = json.NewEncoder(&s.buf)
+
+	return s
+}
+
+// Write encodes v as a single NDJSON line, publishing the buffered chunk once it reaches the chunk size
+func (s *StreamWriter) Write(v any) error {
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+
+	if s.buf.Len() >= s.chunkSize {
+		return s.flush()
+	}
+
+	return nil
+}
+
+func (s *StreamWriter) flush() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+
+	data := make([]byte, s.buf.Len())
+	copy(data, s.buf.Bytes())
+	s.buf.Reset()
+
+	return s.nc.Publish(s.reply, data)
+}
+
+// Close publishes any buffered records followed by the end of stream marker
+func (s *StreamWriter) Close() error {
+	return s.CloseWithError(nil)
+}
+
+// CloseWithError ends the stream. If err is not nil the reader receives it instead of a clean end of stream.
+func (s *StreamWriter) CloseWithError(err error) error {
+	if flushErr := s.flush(); flushErr != nil {
+		return flushErr
+	}
+
+	msg := nats.NewMsg(s.reply)
+	msg.Header.Set(StreamHeader, streamEOF)
+	if err != nil {
+		msg.Header.Set(StreamErrorHeader, err.Error())
+	}
+
+	return s.nc.PublishMsg(msg)
+}
+
+// RequestStream sends a request to subject and returns the NDJSON stream written by a StreamWriter as an
+// io.ReadCloser, so it can be consumed with codec.DecodeNDJSON. It waits for the first chunk, so a request without
+// responders fails right away with nats.ErrNoResponders. The stream ends with an error wrapping ErrStreamOverflow
+// if chunks arrive faster than they are read and the subscription's pending limits drop some, or with ctx's error
+// if ctx is done before the end of stream marker arrives.
+func RequestStream(ctx context.Context, nc *nats.Conn, subject string, data []byte) (io.ReadCloser, error) {
+	inbox := nc.NewInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := nc.PublishRequest(subject, inbox, data); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	return readStream(ctx, sub.NextMsgWithContext, func() { sub.Unsubscribe() })
+}
+
+// readStream waits for the first chunk, then copies the stream into a pipe until the end of stream marker. done
+// is called once no more chunks are read.
+func readStream(ctx context.Context, next func(context.Context) (*nats.Msg, error), done func()) (io.ReadCloser, error) {
+	msg, err := next(ctx)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	if msg.Header.Get("Status") == "503" && len(msg.Data) == 0 {
+		done()
+		return nil, nats.ErrNoResponders
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer done()
+		for {
+			if msg.Header.Get(StreamHeader) == streamEOF {
+				if e := msg.Header.Get(StreamErrorHeader); e != "" {
+					pw.CloseWithError(fmt.Errorf("%w: %s", ErrStreamFailed, e))
+					return
+				}
+				pw.Close()
+				return
+			}
+
+			if _, err := pw.Write(msg.Data); err != nil {
+				return
+			}
+
+			msg, err = next(ctx)
+			if errors.Is(err, nats.ErrSlowConsumer) {
+				err = fmt.Errorf("%w: %w", ErrStreamOverflow, err)
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return pr, nil
+}
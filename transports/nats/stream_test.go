@@ -0,0 +1,89 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestReadStream(t *testing.T) {
+	chunk := func(data string) *nats.Msg {
+		return &nats.Msg{Data: []byte(data), Header: nats.Header{}}
+	}
+	eof := func(e string) *nats.Msg {
+		m := nats.NewMsg("")
+		m.Header.Set(StreamHeader, streamEOF)
+		if e != "" {
+			m.Header.Set(StreamErrorHeader, e)
+		}
+		return m
+	}
+	noResponders := nats.NewMsg("")
+	noResponders.Header.Set("Status", "503")
+
+	tt := []struct {
+		name       string
+		msgs       []*nats.Msg
+		errs       []error
+		data       string
+		requestErr error
+		readErr    error
+	}{
+		{name: "complete", msgs: []*nats.Msg{chunk("{\"a\":1}\n"), chunk("{\"a\":2}\n"), eof("")}, data: "{\"a\":1}\n{\"a\":2}\n"},
+		{name: "no responders", msgs: []*nats.Msg{noResponders}, requestErr: nats.ErrNoResponders},
+		{name: "failed", msgs: []*nats.Msg{chunk("{\"a\":1}\n"), eof("database down")}, data: "{\"a\":1}\n", readErr: ErrStreamFailed},
+		{name: "overflow", msgs: []*nats.Msg{chunk("{\"a\":1}\n"), nil}, errs: []error{nil, nats.ErrSlowConsumer}, data: "{\"a\":1}\n", readErr: ErrStreamOverflow},
+		{name: "timeout", errs: []error{context.DeadlineExceeded}, msgs: []*nats.Msg{nil}, requestErr: context.DeadlineExceeded},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var i int
+			next := func(context.Context) (*nats.Msg, error) {
+				m := v.msgs[i]
+				var err error
+				if i < len(v.errs) {
+					err = v.errs[i]
+				}
+				i++
+				return m, err
+			}
+			done := make(chan struct{})
+
+			r, err := readStream(context.Background(), next, func() { close(done) })
+			if !errors.Is(err, v.requestErr) {
+				t.Fatalf("expected %v but got %v", v.requestErr, err)
+			}
+			if err != nil {
+				<-done
+				return
+			}
+
+			data, err := io.ReadAll(r)
+			if !errors.Is(err, v.readErr) {
+				t.Errorf("expected %v but got %v", v.readErr, err)
+			}
+			if string(data) != v.data {
+				t.Errorf("expected %q but got %q", v.data, data)
+			}
+			<-done
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+var (
+	ErrInvalidSubject      = fmt.Errorf("invalid subject")
+	ErrDuplicateEndpoint   = fmt.Errorf("duplicate endpoint")
+	ErrOverlappingSubjects = fmt.Errorf("overlapping subjects")
+	ErrInvalidEndpoint     = fmt.Errorf("invalid endpoint")
+)
+
+// endpointName and queueGroup are the patterns micro checks when an endpoint is added
+var (
+	endpointName = regexp.MustCompile(`^[A-Za-z0-9\-_]+$`)
+	queueGroup   = regexp.MustCompile(`^[^ >]*[>]?$`)
+)
+
+// ValidateSubject checks that the subject is made of non empty dot separated tokens without whitespace or
+// control characters. When wildcards is true, * may be used as a whole token and > as the last token.
+func ValidateSubject(subject string, wildcards bool) error {
+	if subject == "" {
+		return fmt.Errorf("%w: subject is empty", ErrInvalidSubject)
+	}
+
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+		if token == "" {
+			return fmt.Errorf("%w: %q has an empty token at position %d", ErrInvalidSubject, subject, i+1)
+		}
+
+		for _, r := range token {
+			if unicode.IsSpace(r) || unicode.IsControl(r) {
+				return fmt.Errorf("%w: %q contains whitespace or a control character", ErrInvalidSubject, subject)
+			}
+		}
+
+		switch {
+		case token == "*" || token == ">":
+			if !wildcards {
+				return fmt.Errorf("%w: %q contains the wildcard %s", ErrInvalidSubject, subject, token)
+			}
+			if token == ">" && i != len(tokens)-1 {
+				return fmt.Errorf("%w: %q uses > before the last token", ErrInvalidSubject, subject)
+			}
+		case strings.ContainsAny(token, "*>"):
+			return fmt.Errorf("%w: %q has a wildcard inside the token %q, wildcards must be whole tokens", ErrInvalidSubject, subject, token)
+		}
+	}
+
+	return nil
+}
+
+// SubjectsOverlap reports whether a message could match both subject patterns
+func SubjectsOverlap(a, b string) bool {
+	at, bt := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(at) && i < len(bt); i++ {
+		if at[i] == ">" || bt[i] == ">" {
+			return true
+		}
+		if at[i] != bt[i] && at[i] != "*" && bt[i] != "*" {
+			return false
+		}
+	}
+
+	return len(at) == len(bt)
+}
+
+// MatchSubject reports whether the literal subject matches the pattern, which can contain * and > wildcards
+func MatchSubject(pattern, subject string) bool {
+	pt, st := strings.Split(pattern, "."), strings.Split(subject, ".")
+	for i, v := range pt {
+		if v == ">" {
+			return len(st) > i
+		}
+		if i >= len(st) || (v != "*" && v != st[i]) {
+			return false
+		}
+	}
+
+	return len(pt) == len(st)
+}
+
+// Endpoint is a micro endpoint to be validated together with the rest of a service's endpoints before any of
+// them are added
+type Endpoint struct {
+	Name       string
+	Subject    string
+	QueueGroup string
+	Handler    micro.Handler
+	Metadata   map[string]string
+}
+
+// EndpointAdder is implemented by micro.Service and micro.Group
+type EndpointAdder interface {
+	AddEndpoint(name string, handler micro.Handler, opts ...micro.EndpointOpt) error
+}
+
+// ValidateEndpoints checks each endpoint's name, queue group, and subject, defaulting to its name like micro
+// does, and that no two endpoints share a name or have overlapping subjects, which would leave it up to NATS
+// which endpoint, or both, handles a request. Every problem is returned, not just the first.
+func ValidateEndpoints(endpoints ...Endpoint) error {
+	var errs []error
+	names := make(map[string]bool)
+	for i, v := range endpoints {
+		if !endpointName.MatchString(v.Name) {
+			errs = append(errs, fmt.Errorf("%w: name %q must be letters, numbers, dashes, and underscores", ErrInvalidEndpoint, v.Name))
+		}
+		if !queueGroup.MatchString(v.QueueGroup) {
+			errs = append(errs, fmt.Errorf("%w: endpoint %s has the invalid queue group %q", ErrInvalidEndpoint, v.Name, v.QueueGroup))
+		}
+		if names[v.Name] {
+			errs = append(errs, fmt.Errorf("%w: %s is registered twice", ErrDuplicateEndpoint, v.Name))
+		}
+		names[v.Name] = true
+
+		subject := endpointSubject(v)
+		if err := ValidateSubject(subject, true); err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %s: %w", v.Name, err))
+			continue
+		}
+
+		for _, prev := range endpoints[:i] {
+			if ValidateSubject(endpointSubject(prev), true) != nil {
+				continue
+			}
+			if SubjectsOverlap(endpointSubject(prev), subject) {
+				errs = append(errs, fmt.Errorf("%w: endpoints %s (%s) and %s (%s)", ErrOverlappingSubjects, prev.Name, endpointSubject(prev), v.Name, subject))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func endpointSubject(e Endpoint) string {
+	if e.Subject == "" {
+		return e.Name
+	}
+
+	return e.Subject
+}
+
+// AddEndpoints validates the endpoints with ValidateEndpoints and adds them only if they are all valid, so a
+// misconfigured service fails at startup instead of when a request arrives. Everything micro checks is validated
+// first, so adding an endpoint only fails when subscribing does, such as on a closed connection. micro can't
+// remove endpoints, so stop the service in that case.
+func AddEndpoints(a EndpointAdder, endpoints ...Endpoint) error {
+	if err := ValidateEndpoints(endpoints...); err != nil {
+		return err
+	}
+
+	for _, v := range endpoints {
+		opts := []micro.EndpointOpt{micro.WithEndpointSubject(endpointSubject(v))}
+		if v.QueueGroup != "" {
+			opts = append(opts, micro.WithEndpointQueueGroup(v.QueueGroup))
+		}
+		if v.Metadata != nil {
+			opts = append(opts, micro.WithEndpointMetadata(v.Metadata))
+		}
+
+		if err := a.AddEndpoint(v.Name, v.Handler, opts...); err != nil {
+			return fmt.Errorf("adding endpoint %s: %w", v.Name, err)
+		}
+	}
+
+	return nil
+}
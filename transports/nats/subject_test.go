@@ -0,0 +1,176 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestValidateSubject(t *testing.T) {
+	tt := []struct {
+		subject   string
+		wildcards bool
+		valid     bool
+	}{
+		{subject: "orders.create", valid: true},
+		{subject: "orders.*.created", wildcards: true, valid: true},
+		{subject: "orders.>", wildcards: true, valid: true},
+		{subject: "orders.*", wildcards: false},
+		{subject: ""},
+		{subject: "orders..create"},
+		{subject: ".orders"},
+		{subject: "orders."},
+		{subject: "orders create"},
+		{subject: "orders.\tcreate"},
+		{subject: "orders.>.create", wildcards: true},
+		{subject: "orders.cre*te", wildcards: true},
+		{subject: "orders.>x", wildcards: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.subject, func(t *testing.T) {
+			err := ValidateSubject(v.subject, v.wildcards)
+			if (err == nil) != v.valid {
+				t.Errorf("expected valid=%v but got %v", v.valid, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSubject) {
+				t.Errorf("expected ErrInvalidSubject but got %v", err)
+			}
+		})
+	}
+}
+
+type fakeAdder struct {
+	added []string
+}
+
+func (f *fakeAdder) AddEndpoint(name string, handler micro.Handler, opts ...micro.EndpointOpt) error {
+	f.added = append(f.added, name)
+	return nil
+}
+
+func TestAddEndpoints(t *testing.T) {
+	h := micro.HandlerFunc(func(micro.Request) {})
+	tt := []struct {
+		name      string
+		endpoints []Endpoint
+		want      []error
+	}{
+		{name: "valid", endpoints: []Endpoint{{Name: "create", Subject: "orders.create"}, {Name: "get", Subject: "orders.get.*"}, {Name: "list"}}},
+		{name: "invalid subject", endpoints: []Endpoint{{Name: "create", Subject: "orders..create"}}, want: []error{ErrInvalidSubject}},
+		{name: "duplicate name", endpoints: []Endpoint{{Name: "create", Subject: "a"}, {Name: "create", Subject: "b"}}, want: []error{ErrDuplicateEndpoint}},
+		{name: "overlap", endpoints: []Endpoint{{Name: "get", Subject: "orders.*"}, {Name: "create", Subject: "orders.create"}}, want: []error{ErrOverlappingSubjects}},
+		{name: "default subject overlaps", endpoints: []Endpoint{{Name: "all", Subject: ">"}, {Name: "create"}}, want: []error{ErrOverlappingSubjects}},
+		{name: "invalid name", endpoints: []Endpoint{{Name: "orders.create", Subject: "orders.create"}}, want: []error{ErrInvalidEndpoint}},
+		{name: "invalid queue group", endpoints: []Endpoint{{Name: "create", QueueGroup: "a b"}, {Name: "get"}}, want: []error{ErrInvalidEndpoint}},
+		{name: "all problems", endpoints: []Endpoint{{Name: "a", Subject: "x.>"}, {Name: "b", Subject: "x.y"}, {Name: "c", Subject: "bad subject"}}, want: []error{ErrOverlappingSubjects, ErrInvalidSubject}},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			for i := range v.endpoints {
+				v.endpoints[i].Handler = h
+			}
+
+			adder := &fakeAdder{}
+			err := AddEndpoints(adder, v.endpoints...)
+
+			var got []error
+			if err != nil {
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					for _, target := range []error{ErrInvalidSubject, ErrDuplicateEndpoint, ErrOverlappingSubjects, ErrInvalidEndpoint} {
+						if errors.Is(e, target) {
+							got = append(got, target)
+						}
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("expected %v but got %v", v.want, err)
+			}
+
+			if wantAdded := len(v.want) == 0; (len(adder.added) == len(v.endpoints)) != wantAdded || (!wantAdded && len(adder.added) > 0) {
+				t.Errorf("expected endpoints to be added only when valid, added %v", adder.added)
+			}
+		})
+	}
+}
+
+// subjectPair generates two subject patterns and a literal subject from a small alphabet so matches are common
+type subjectPair struct {
+	a, b, literal string
+}
+
+func (subjectPair) Generate(r *rand.Rand, size int) reflect.Value {
+	pattern := func(tokens ...string) string {
+		var parts []string
+		for n := r.Intn(3); n >= 0; n-- {
+			parts = append(parts, tokens[r.Intn(len(tokens))])
+		}
+		if len(parts) > 0 && r.Intn(4) == 0 {
+			parts[len(parts)-1] = ">"
+		}
+		return strings.Join(parts, ".")
+	}
+
+	return reflect.ValueOf(subjectPair{a: pattern("a", "b", "*"), b: pattern("a", "b", "*"), literal: strings.ReplaceAll(pattern("a", "b"), ">", "a")})
+}
+
+func TestMatchSubject(t *testing.T) {
+	tt := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{pattern: "", subject: "orders.create", want: false},
+		{pattern: "orders.create", subject: "orders.create", want: true},
+		{pattern: "orders.*", subject: "orders.create", want: true},
+		{pattern: "orders.*", subject: "orders.create.v2", want: false},
+		{pattern: "orders.>", subject: "orders.create.v2", want: true},
+		{pattern: "orders.>", subject: "orders", want: false},
+		{pattern: "orders.create.v2", subject: "orders.create", want: false},
+		{pattern: "users.*", subject: "orders.create", want: false},
+	}
+
+	for _, v := range tt {
+		t.Run(v.pattern+" "+v.subject, func(t *testing.T) {
+			if got := MatchSubject(v.pattern, v.subject); got != v.want {
+				t.Errorf("expected %v but got %v", v.want, got)
+			}
+		})
+	}
+}
+
+func TestSubjectsOverlapProperties(t *testing.T) {
+	property := func(p subjectPair) bool {
+		overlap := SubjectsOverlap(p.a, p.b)
+		// a subject matching both patterns proves they overlap, overlap is symmetric, and a pattern overlaps itself
+		if MatchSubject(p.a, p.literal) && MatchSubject(p.b, p.literal) && !overlap {
+			return false
+		}
+		return overlap == SubjectsOverlap(p.b, p.a) && SubjectsOverlap(p.a, p.a)
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
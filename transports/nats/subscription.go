@@ -0,0 +1,209 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/nats-io/nats.go"
+)
+
+// GraphQLEventHeader marks each message sent to a NATS GraphQL subscriber as a next, heartbeat or complete event
+const GraphQLEventHeader = "Sencillo-Graphql-Event"
+
+const (
+	GraphQLEventNext      = "next"
+	GraphQLEventHeartbeat = "heartbeat"
+	GraphQLEventComplete  = "complete"
+)
+
+// DefaultSubscriptionHeartbeat is how often a NATS GraphQL subscriber is checked for interest
+const DefaultSubscriptionHeartbeat = 15 * time.Second
+
+// Subscriber creates NATS subscriptions. *nats.Conn implements it.
+type Subscriber interface {
+	Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+// SubscribeOpt is a functional option to modify a resolver subscription
+type SubscribeOpt func(*subscribeConfig)
+
+type subscribeConfig struct {
+	buffer int
+	logger *slog.Logger
+}
+
+// SetSubscribeBuffer sets how many events are buffered for a slow client before NATS starts holding messages
+func SetSubscribeBuffer(n int) SubscribeOpt {
+	return func(c *subscribeConfig) {
+		c.buffer = n
+	}
+}
+
+// SetSubscribeLogger sets the logger used to report messages that fail to decode
+func SetSubscribeLogger(l *slog.Logger) SubscribeOpt {
+	return func(c *subscribeConfig) {
+		c.logger = l
+	}
+}
+
+// DecodeJSON decodes a message body as JSON into T
+func DecodeJSON[T any](m *nats.Msg) (T, error) {
+	var v T
+	err := json.Unmarshal(m.Data, &v)
+	return v, err
+}
+
+// Subscribe feeds a GraphQL subscription resolver from a NATS subject. Each call gets its own NATS subscription so
+// filter can decide per client which events it sees; a nil filter passes everything. gqlgen cancels ctx when the
+// client disconnects, which removes the NATS subscription and closes the returned channel. Messages that fail to
+// decode are logged and skipped.
+func Subscribe[T any](ctx context.Context, nc Subscriber, subject string, decode func(*nats.Msg) (T, error), filter func(context.Context, T) bool, opts ...SubscribeOpt) (<-chan T, error) {
+	cfg := subscribeConfig{
+		buffer: 16,
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	out := make(chan T, cfg.buffer)
+	var mu sync.Mutex
+	var closed bool
+
+	sub, err := nc.Subscribe(subject, func(m *nats.Msg) {
+		v, err := decode(m)
+		if err != nil {
+			cfg.logger.Warn("dropping subscription event", "subject", m.Subject, "error", err)
+			return
+		}
+		if filter != nil && !filter(ctx, v) {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case out <- v:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// SetSubscriptionHeartbeat sets how often NATS GraphQL subscribers are checked for interest. Subscriptions whose
+// reply subject has no subscriber left are stopped.
+func SetSubscriptionHeartbeat(d time.Duration) ClientOpt {
+	return func(n *NATSClient) {
+		n.heartbeat = d
+	}
+}
+
+// subscribe streams a subscription operation's responses to the request's reply subject until the resolver
+// finishes or the subscriber goes away
+func (n *NATSClient) subscribe(ctx context.Context, reply string, rc *graphql.OperationContext) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interval := n.heartbeat
+	if interval == 0 {
+		interval = DefaultSubscriptionHeartbeat
+	}
+
+	go heartbeat(ctx, cancel, interval, func() error {
+		msg := nats.NewMsg(reply)
+		msg.Header.Set(GraphQLEventHeader, GraphQLEventHeartbeat)
+		_, err := n.Conn.RequestMsg(msg, interval/2)
+		return err
+	})
+
+	responses, ctx := n.Exec.DispatchOperation(ctx, rc)
+	err := streamResponses(ctx, responses, func(m *nats.Msg) error {
+		m.Subject = reply
+		return n.Conn.PublishMsg(m)
+	})
+	if err != nil {
+		slog.Error("error streaming subscription", "subject", reply, "error", err)
+	}
+}
+
+// streamResponses publishes each response as a next event followed by a complete event once the responses run out
+func streamResponses(ctx context.Context, next graphql.ResponseHandler, publish func(*nats.Msg) error) error {
+	for {
+		resp := next(ctx)
+		if resp == nil {
+			break
+		}
+
+		msg := natsResponse(resp)
+		msg.Header = nats.Header{}
+		msg.Header.Set(GraphQLEventHeader, GraphQLEventNext)
+		if err := publish(msg); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	msg := &nats.Msg{Header: nats.Header{}}
+	msg.Header.Set(GraphQLEventHeader, GraphQLEventComplete)
+	return publish(msg)
+}
+
+// heartbeat pings the subscriber every interval and cancels the subscription once nothing is listening. A
+// subscriber that gets the heartbeat without replying times out, which still shows it is there.
+func heartbeat(ctx context.Context, cancel context.CancelFunc, interval time.Duration, ping func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := ping()
+		if errors.Is(err, nats.ErrNoResponders) || errors.Is(err, nats.ErrConnectionClosed) {
+			cancel()
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/nats-io/nats.go"
+)
+
+type fakeSubscriber struct {
+	subject string
+	cb      nats.MsgHandler
+}
+
+func (f *fakeSubscriber) Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	f.subject = subject
+	f.cb = cb
+	return &nats.Subscription{Subject: subject}, nil
+}
+
+type orderEvent struct {
+	Customer string `json:"customer"`
+	ID       int    `json:"id"`
+}
+
+type customerKey struct{}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), customerKey{}, "acme"))
+	nc := &fakeSubscriber{}
+
+	// each client only sees its own customer's orders
+	filter := func(ctx context.Context, e orderEvent) bool {
+		return e.Customer == ctx.Value(customerKey{})
+	}
+
+	events, err := Subscribe(ctx, nc, "orders.created", DecodeJSON[orderEvent], filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nc.subject != "orders.created" {
+		t.Errorf("expected a subscription on orders.created but got %q", nc.subject)
+	}
+
+	for _, data := range []string{
+		`{"customer": "acme", "id": 1}`,
+		`{"customer": "globex", "id": 2}`,
+		`not json`,
+		`{"customer": "acme", "id": 3}`,
+	} {
+		nc.cb(&nats.Msg{Subject: "orders.created", Data: []byte(data)})
+	}
+
+	var got []int
+	for range 2 {
+		got = append(got, (<-events).ID)
+	}
+	if !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("expected orders [1 3] but got %v", got)
+	}
+
+	// disconnecting closes the channel and later messages are dropped
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed after the client disconnected")
+	}
+	nc.cb(&nats.Msg{Data: []byte(`{"customer": "acme", "id": 4}`)})
+}
+
+func TestStreamResponses(t *testing.T) {
+	tt := []struct {
+		name     string
+		cancel   bool
+		want     []string
+		wantData []string
+	}{
+		{
+			name:     "completes",
+			want:     []string{GraphQLEventNext, GraphQLEventNext, GraphQLEventComplete},
+			wantData: []string{`{"data":1}`, `{"data":2}`, ``},
+		},
+		{
+			name:     "disconnected",
+			cancel:   true,
+			want:     []string{GraphQLEventNext, GraphQLEventNext},
+			wantData: []string{`{"data":1}`, `{"data":2}`},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var sent int
+			next := func(ctx context.Context) *graphql.Response {
+				if sent == 2 {
+					if v.cancel {
+						cancel()
+					}
+					return nil
+				}
+				sent++
+				return &graphql.Response{Data: []byte(fmt.Sprint(sent))}
+			}
+
+			var events, data []string
+			err := streamResponses(ctx, next, func(m *nats.Msg) error {
+				events = append(events, m.Header.Get(GraphQLEventHeader))
+				data = append(data, string(m.Data))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(events, v.want) {
+				t.Errorf("expected events %v but got %v", v.want, events)
+			}
+			if !reflect.DeepEqual(data, v.wantData) {
+				t.Errorf("expected data %v but got %v", v.wantData, data)
+			}
+		})
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	tt := []struct {
+		name   string
+		err    error
+		cancel bool
+	}{
+		{name: "listening", err: nats.ErrTimeout},
+		{name: "replied"},
+		{name: "gone", err: nats.ErrNoResponders, cancel: true},
+		{name: "closed", err: nats.ErrConnectionClosed, cancel: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			var cancelled bool
+			pings := 0
+			heartbeat(ctx, func() { cancelled = true }, 5*time.Millisecond, func() error {
+				pings++
+				return v.err
+			})
+
+			if cancelled != v.cancel {
+				t.Errorf("expected cancelled to be %t", v.cancel)
+			}
+			if v.cancel && pings != 1 {
+				t.Errorf("expected heartbeats to stop after the subscriber left but got %d pings", pings)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/metrics"
+	"github.com/SencilloDev/sencillo-go/versioned"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// VersionToken is replaced with the version in an endpoint subject passed to Versions.Endpoints
+const VersionToken = "{version}"
+
+// VersionHeader names the API version a response was served by
+const VersionHeader = "Sencillo-Version"
+
+// Versions registers an endpoint once per API version, with the version as a subject token
+type Versions struct {
+	api *versioned.API
+	now func() time.Time
+
+	requests *prometheus.CounterVec
+}
+
+// NewVersions returns Versions for the API
+func NewVersions(api *versioned.API) *Versions {
+	return &Versions{
+		api:      api,
+		now:      time.Now,
+		requests: metrics.NewCounterVec("nats_api_version_requests", "NATS requests by API version and outcome", []string{"version", "outcome"}),
+	}
+}
+
+// Metrics returns the collectors to register with the exporter
+func (v *Versions) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{v.requests}
+}
+
+// Endpoints returns an endpoint for every version of the API, ready for AddEndpoints. The endpoint's subject
+// must contain VersionToken as a whole token, such as orders.{version}.get, and each version's endpoint is
+// named with the version appended. A version is served by the newest handler registered at or before it.
+// One more endpoint, with the version token removed from the subject, serves the API's default version.
+// Retired versions respond with a 410 error. Deprecated versions add the Sencillo-Deprecated and
+// Sencillo-Sunset headers.
+func (v *Versions) Endpoints(e Endpoint, handlers map[string]micro.Handler) ([]Endpoint, error) {
+	tokens := strings.Split(e.Subject, ".")
+	i := -1
+	for j, t := range tokens {
+		if t == VersionToken {
+			i = j
+		}
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %q has no %s token", ErrInvalidSubject, e.Subject, VersionToken)
+	}
+	for name := range handlers {
+		if _, err := v.api.Lookup(name, time.Time{}); errors.Is(err, versioned.ErrUnknownVersion) {
+			return nil, err
+		}
+	}
+
+	var endpoints []Endpoint
+	for _, version := range v.api.Versions {
+		served, err := v.api.Resolve(version.Name, func(name string) bool { return handlers[name] != nil })
+		if err != nil {
+			continue
+		}
+		ve := e
+		ve.Name = fmt.Sprintf("%s_%s", e.Name, version.Name)
+		ve.Subject = strings.Replace(e.Subject, VersionToken, version.Name, 1)
+		ve.Handler = v.handler(version.Name, handlers[served])
+		endpoints = append(endpoints, ve)
+	}
+
+	def, err := v.api.Resolve(v.api.Default, func(name string) bool { return handlers[name] != nil })
+	if err != nil {
+		return nil, err
+	}
+	unversioned := e
+	unversioned.Subject = strings.Join(append(tokens[:i:i], tokens[i+1:]...), ".")
+	unversioned.Handler = v.handler(v.api.Default, handlers[def])
+	return append(endpoints, unversioned), nil
+}
+
+func (v *Versions) handler(name string, h micro.Handler) micro.Handler {
+	return micro.HandlerFunc(func(r micro.Request) {
+		version, err := v.api.Lookup(name, v.now())
+		if errors.Is(err, versioned.ErrVersionSunset) {
+			v.requests.WithLabelValues(name, "retired").Inc()
+			r.Error("410", http.StatusText(http.StatusGone), []byte(fmt.Sprintf(`{"errors": [%q]}`, err.Error())))
+			return
+		}
+
+		headers := micro.Headers{VersionHeader: {name}}
+		outcome := "served"
+		if version.Deprecated {
+			outcome = "deprecated"
+			headers[DeprecatedHeader] = []string{"true"}
+			if !version.Sunset.IsZero() {
+				headers[SunsetHeader] = []string{version.Sunset.UTC().Format(http.TimeFormat)}
+			}
+		}
+		v.requests.WithLabelValues(name, outcome).Inc()
+		h.Handle(&deprecatedRequest{Request: r, headers: headers})
+	})
+}
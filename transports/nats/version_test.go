@@ -0,0 +1,102 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SencilloDev/sencillo-go/sdtest"
+	"github.com/SencilloDev/sencillo-go/versioned"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestVersions(t *testing.T) {
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	v := NewVersions(versioned.NewAPI(
+		versioned.APIVersion{Name: "v1", Deprecated: true, Sunset: sunset},
+		versioned.APIVersion{Name: "v2"},
+		versioned.APIVersion{Name: "v3"},
+	))
+	v.now = func() time.Time { return sunset.Add(-time.Hour) }
+	reg := sdtest.NewRegistry(t, v.Metrics()...)
+	respond := func(body string) micro.Handler {
+		return micro.HandlerFunc(func(r micro.Request) { r.Respond([]byte(body)) })
+	}
+	endpoints, err := v.Endpoints(Endpoint{Name: "get_order", Subject: "orders.{version}.get"}, map[string]micro.Handler{
+		"v1": respond("old"),
+		"v3": respond("new"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateEndpoints(endpoints...); err != nil {
+		t.Fatal(err)
+	}
+	bySubject := make(map[string]Endpoint)
+	for _, e := range endpoints {
+		bySubject[e.Subject] = e
+	}
+
+	tt := []struct {
+		name       string
+		subject    string
+		now        time.Time
+		code       string
+		body       string
+		deprecated bool
+	}{
+		{name: "unversioned", subject: "orders.get", body: "new"},
+		{name: "deprecated", subject: "orders.v1.get", body: "old", deprecated: true},
+		{name: "unchanged version", subject: "orders.v2.get", body: "old"},
+		{name: "newest", subject: "orders.v3.get", body: "new"},
+		{name: "retired", subject: "orders.v1.get", now: sunset, code: "410"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.now.IsZero() {
+				v.now = func() time.Time { return tc.now }
+			}
+			e, ok := bySubject[tc.subject]
+			if !ok {
+				t.Fatalf("no endpoint for %s", tc.subject)
+			}
+			r := &replyRequest{msg: &nats.Msg{Subject: tc.subject, Header: nats.Header{}}}
+			e.Handler.Handle(r)
+			if got := r.reply.Header.Get(micro.ErrorCodeHeader); got != tc.code {
+				t.Fatalf("expected error code %q but got %q", tc.code, got)
+			}
+			if tc.code != "" {
+				return
+			}
+			if string(r.reply.Data) != tc.body {
+				t.Errorf("expected %q but got %q", tc.body, r.reply.Data)
+			}
+			if deprecated := r.reply.Header.Get(DeprecatedHeader) == "true"; deprecated != tc.deprecated {
+				t.Errorf("expected deprecated to be %v", tc.deprecated)
+			}
+		})
+	}
+	reg.AssertCounter(t, "nats_api_version_requests", prometheus.Labels{"version": "v1", "outcome": "retired"}, 1)
+	reg.AssertCounter(t, "nats_api_version_requests", prometheus.Labels{"version": "v3", "outcome": "served"}, 2)
+
+	if _, err := v.Endpoints(Endpoint{Name: "get_order", Subject: "orders.get"}, nil); !errors.Is(err, ErrInvalidSubject) {
+		t.Errorf("expected %v but got %v", ErrInvalidSubject, err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tcp serves custom wire protocols over raw TCP and UDP. Framers split a byte stream into messages, a
+// Server runs a handler per connection with the same logging and client error model as the HTTP and NATS
+// transports, and shutting the server down drains connections between frames.
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxFrame is the largest frame read or written when a framer doesn't set MaxFrame
+const DefaultMaxFrame = 1 << 20
+
+var (
+	ErrFrameTooLarge    = fmt.Errorf("frame too large")
+	ErrDelimiterInFrame = fmt.Errorf("frame contains the delimiter")
+)
+
+// Framer splits a byte stream into frames. ReadFrame returns io.EOF when the stream ends cleanly between frames
+// and io.ErrUnexpectedEOF when it ends inside one.
+type Framer interface {
+	ReadFrame(r *bufio.Reader) ([]byte, error)
+	WriteFrame(w io.Writer, frame []byte) error
+}
+
+// LengthPrefixed frames are preceded by their length as an unsigned integer of Size bytes: 1, 2, 4, or 8. Size
+// defaults to 4 and Order to big endian, the usual network byte order.
+type LengthPrefixed struct {
+	Size     int
+	Order    binary.ByteOrder
+	MaxFrame int
+}
+
+func (l LengthPrefixed) size() int {
+	if l.Size == 0 {
+		return 4
+	}
+	return l.Size
+}
+
+func (l LengthPrefixed) order() binary.ByteOrder {
+	if l.Order == nil {
+		return binary.BigEndian
+	}
+	return l.Order
+}
+
+// limit returns the largest frame, capped at what the prefix can hold
+func (l LengthPrefixed) limit() uint64 {
+	limit := uint64(DefaultMaxFrame)
+	if l.MaxFrame > 0 {
+		limit = uint64(l.MaxFrame)
+	}
+	if l.size() < 8 {
+		limit = min(limit, 1<<(8*l.size())-1)
+	}
+	return limit
+}
+
+func (l LengthPrefixed) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	prefix := make([]byte, l.size())
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+
+	var n uint64
+	switch l.size() {
+	case 1:
+		n = uint64(prefix[0])
+	case 2:
+		n = uint64(l.order().Uint16(prefix))
+	case 4:
+		n = uint64(l.order().Uint32(prefix))
+	case 8:
+		n = l.order().Uint64(prefix)
+	default:
+		return nil, fmt.Errorf("invalid length prefix size %d", l.Size)
+	}
+	if n > l.limit() {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, n)
+	}
+
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (l LengthPrefixed) WriteFrame(w io.Writer, frame []byte) error {
+	if uint64(len(frame)) > l.limit() {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(frame))
+	}
+
+	// the prefix and frame are written together so concurrent writers don't interleave
+	buf := make([]byte, l.size(), l.size()+len(frame))
+	switch l.size() {
+	case 1:
+		buf[0] = byte(len(frame))
+	case 2:
+		l.order().PutUint16(buf, uint16(len(frame)))
+	case 4:
+		l.order().PutUint32(buf, uint32(len(frame)))
+	case 8:
+		l.order().PutUint64(buf, uint64(len(frame)))
+	default:
+		return fmt.Errorf("invalid length prefix size %d", l.Size)
+	}
+
+	_, err := w.Write(append(buf, frame...))
+	return err
+}
+
+// Delimited frames end with Delimiter, which isn't part of the frame. Delimiter defaults to a newline, for line
+// based protocols.
+type Delimited struct {
+	Delimiter []byte
+	MaxFrame  int
+}
+
+func (d Delimited) delimiter() []byte {
+	if len(d.Delimiter) == 0 {
+		return []byte("\n")
+	}
+	return d.Delimiter
+}
+
+func (d Delimited) limit() int {
+	if d.MaxFrame > 0 {
+		return d.MaxFrame
+	}
+	return DefaultMaxFrame
+}
+
+func (d Delimited) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	delim := d.delimiter()
+	last := delim[len(delim)-1]
+
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(last)
+		frame = append(frame, chunk...)
+		if len(frame) > d.limit()+len(delim) {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrFrameTooLarge, d.limit())
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(frame) > 0:
+			return nil, io.ErrUnexpectedEOF
+		case err != nil:
+			return nil, err
+		}
+
+		if bytes.HasSuffix(frame, delim) {
+			return frame[:len(frame)-len(delim)], nil
+		}
+	}
+}
+
+func (d Delimited) WriteFrame(w io.Writer, frame []byte) error {
+	if len(frame) > d.limit() {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(frame))
+	}
+	delim := d.delimiter()
+	if bytes.Contains(frame, delim) {
+		return ErrDelimiterInFrame
+	}
+
+	_, err := w.Write(append(frame[:len(frame):len(frame)], delim...))
+	return err
+}
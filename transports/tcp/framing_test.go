@@ -0,0 +1,100 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFramers(t *testing.T) {
+	tt := []struct {
+		name   string
+		framer Framer
+		frames []string
+		wire   string
+	}{
+		{name: "length prefixed", framer: LengthPrefixed{}, frames: []string{"hello", "", "world"}, wire: "\x00\x00\x00\x05hello\x00\x00\x00\x00\x00\x00\x00\x05world"},
+		{name: "little endian", framer: LengthPrefixed{Size: 2, Order: binary.LittleEndian}, frames: []string{"hi"}, wire: "\x02\x00hi"},
+		{name: "one byte", framer: LengthPrefixed{Size: 1}, frames: []string{"abc"}, wire: "\x03abc"},
+		{name: "lines", framer: Delimited{}, frames: []string{"PING", "PONG"}, wire: "PING\nPONG\n"},
+		{name: "crlf", framer: Delimited{Delimiter: []byte("\r\n")}, frames: []string{"a\rb", "c"}, wire: "a\rb\r\nc\r\n"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			for _, f := range v.frames {
+				if err := v.framer.WriteFrame(&buf, []byte(f)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if buf.String() != v.wire {
+				t.Errorf("expected %q on the wire but got %q", v.wire, buf.String())
+			}
+
+			r := bufio.NewReaderSize(&buf, 16)
+			for _, want := range v.frames {
+				got, err := v.framer.ReadFrame(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("expected frame %q but got %q", want, got)
+				}
+			}
+			if _, err := v.framer.ReadFrame(r); !errors.Is(err, io.EOF) {
+				t.Errorf("expected a clean EOF but got %v", err)
+			}
+		})
+	}
+}
+
+func TestFramerErrors(t *testing.T) {
+	tt := []struct {
+		name   string
+		framer Framer
+		wire   string
+		write  string
+		err    error
+	}{
+		{name: "prefix too large", framer: LengthPrefixed{MaxFrame: 4}, wire: "\x00\x00\x00\x05hello", err: ErrFrameTooLarge},
+		{name: "write too large", framer: LengthPrefixed{Size: 1}, write: strings.Repeat("a", 256), err: ErrFrameTooLarge},
+		{name: "truncated frame", framer: LengthPrefixed{}, wire: "\x00\x00\x00\x05hel", err: io.ErrUnexpectedEOF},
+		{name: "truncated prefix", framer: LengthPrefixed{}, wire: "\x00\x00", err: io.ErrUnexpectedEOF},
+		{name: "line too long", framer: Delimited{MaxFrame: 20}, wire: strings.Repeat("a", 40) + "\n", err: ErrFrameTooLarge},
+		{name: "unterminated line", framer: Delimited{}, wire: "PING", err: io.ErrUnexpectedEOF},
+		{name: "delimiter in frame", framer: Delimited{}, write: "a\nb", err: ErrDelimiterInFrame},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			var err error
+			if v.write != "" {
+				err = v.framer.WriteFrame(io.Discard, []byte(v.write))
+			} else {
+				_, err = v.framer.ReadFrame(bufio.NewReaderSize(strings.NewReader(v.wire), 16))
+			}
+			if !errors.Is(err, v.err) {
+				t.Errorf("expected %v but got %v", v.err, err)
+			}
+		})
+	}
+}
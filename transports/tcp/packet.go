@@ -0,0 +1,63 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+)
+
+// ServePackets serves UDP and other datagram protocols, where every packet is one frame, until ctx is done or
+// pc is closed. Responses and client errors go back to the sender; other errors are logged and the packet is
+// dropped. Packets are handled one at a time in order, so h should be quick.
+func ServePackets(ctx context.Context, pc net.PacketConn, h Handler, logger *slog.Logger) error {
+	go func() {
+		<-ctx.Done()
+		pc.Close()
+	}()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		frame := append([]byte(nil), buf[:n]...)
+		l := logger.With("remote", addr.String())
+		resp, err := safeHandle(context.WithValue(ctx, loggerKey{}, l), h, frame)
+		var ce ClientError
+		switch {
+		case errors.As(err, &ce):
+			resp = ce.Body()
+		case err != nil:
+			l.Error(fmt.Sprintf("dropping packet: %v", err))
+			continue
+		}
+
+		if resp == nil {
+			continue
+		}
+		if _, err := pc.WriteTo(resp, addr); err != nil {
+			l.Warn(fmt.Sprintf("writing response: %v", err))
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrDraining is returned by ReadFrame once the server is shutting down, so handlers finish between frames
+var ErrDraining = fmt.Errorf("server is draining")
+
+// ClientError is an error caused by the client. Its body is written back as a frame and the connection stays
+// open. *errors.ClientError implements it.
+type ClientError interface {
+	Error() string
+	Body() []byte
+	Code() int
+}
+
+// Handler handles one frame and returns the response frame. A nil response writes nothing. Client errors are
+// written back and other errors close the connection.
+type Handler func(ctx context.Context, frame []byte) ([]byte, error)
+
+// ConnHandler serves a whole connection, for protocols that aren't one response per frame. Returning closes the
+// connection; errors other than io.EOF and ErrDraining are logged.
+type ConnHandler func(ctx context.Context, c *Conn) error
+
+type loggerKey struct{}
+
+// LoggerFrom returns the connection's logger, which has the remote address, from a handler's context. If there
+// isn't one, slog.Default is returned.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// Conn is a connection being served
+type Conn struct {
+	net.Conn
+	Logger *slog.Logger
+
+	framer Framer
+	reader *bufio.Reader
+	idle   time.Duration
+	drain  context.Context
+	wmu    sync.Mutex
+}
+
+// ReadFrame reads the next frame, waiting at most the idle timeout for it. It returns ErrDraining once the
+// server is shutting down.
+func (c *Conn) ReadFrame() ([]byte, error) {
+	if c.idle > 0 {
+		c.SetReadDeadline(time.Now().Add(c.idle))
+	}
+	// checked after setting the deadline so it can't undo the deadline set when draining starts
+	if c.drain.Err() != nil {
+		return nil, ErrDraining
+	}
+
+	frame, err := c.framer.ReadFrame(c.reader)
+	if err != nil && c.drain.Err() != nil {
+		return nil, ErrDraining
+	}
+	return frame, err
+}
+
+// WriteFrame writes a frame. It is safe to call from several goroutines.
+func (c *Conn) WriteFrame(frame []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.framer.WriteFrame(c.Conn, frame)
+}
+
+// Frames serves a connection one frame at a time with h until the client closes it or the server drains.
+// encode writes client errors as a frame; a nil encode writes their body.
+func Frames(h Handler, encode func(ClientError) []byte) ConnHandler {
+	if encode == nil {
+		encode = func(ce ClientError) []byte { return ce.Body() }
+	}
+
+	return func(ctx context.Context, c *Conn) error {
+		for {
+			frame, err := c.ReadFrame()
+			if err != nil {
+				return err
+			}
+
+			resp, err := safeHandle(ctx, h, frame)
+			var ce ClientError
+			switch {
+			case errors.As(err, &ce):
+				c.Logger.Debug(fmt.Sprintf("client error: %v", err))
+				resp = encode(ce)
+			case err != nil:
+				return err
+			}
+
+			if resp == nil {
+				continue
+			}
+			if err := c.WriteFrame(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func safeHandle(ctx context.Context, h Handler, frame []byte) (resp []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, frame)
+}
+
+// ServerOption is a functional option to modify the server
+type ServerOption func(*Server)
+
+// Server accepts TCP connections and serves each with a ConnHandler in its own goroutine
+type Server struct {
+	Logger *slog.Logger
+
+	addr     string
+	handler  ConnHandler
+	framer   Framer
+	idle     time.Duration
+	maxConns int
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*Conn]struct{}
+	wg       sync.WaitGroup
+	drain    context.Context
+	stop     context.CancelFunc
+
+	connections prometheus.Gauge
+	rejected    prometheus.Counter
+	failures    prometheus.Counter
+}
+
+// SetServerPort sets the server listening port
+func SetServerPort(p int) ServerOption {
+	return func(s *Server) {
+		s.addr = fmt.Sprintf(":%d", p)
+	}
+}
+
+// SetFramer sets how connections are split into frames. It defaults to LengthPrefixed with a 4 byte prefix.
+func SetFramer(f Framer) ServerOption {
+	return func(s *Server) {
+		s.framer = f
+	}
+}
+
+// SetIdleTimeout closes connections that don't send a frame for the duration
+func SetIdleTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.idle = d
+	}
+}
+
+// SetMaxConns closes new connections while n are open
+func SetMaxConns(n int) ServerOption {
+	return func(s *Server) {
+		s.maxConns = n
+	}
+}
+
+// SetLogger sets the server logger
+func SetLogger(l *slog.Logger) ServerOption {
+	return func(s *Server) {
+		s.Logger = l
+	}
+}
+
+// NewServer returns a Server listening on port 9000 that serves each connection with h. Wrap a Handler with
+// Frames for request response protocols.
+func NewServer(h ConnHandler, opts ...ServerOption) *Server {
+	drain, stop := context.WithCancel(context.Background())
+	s := &Server{
+		Logger:  slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		addr:    ":9000",
+		handler: h,
+		framer:  LengthPrefixed{},
+		conns:   make(map[*Conn]struct{}),
+		drain:   drain,
+		stop:    stop,
+		connections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tcp_connections",
+			Help: "Open TCP connections",
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tcp_connections_rejected",
+			Help: "TCP connections closed because the server was at its connection limit",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tcp_connection_errors",
+			Help: "TCP connections closed by a handler error",
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Metrics returns the server's collectors
+func (s *Server) Metrics() []prometheus.Collector {
+	return []prometheus.Collector{s.connections, s.rejected, s.failures}
+}
+
+// Serve listens on the server port and serves until ShutdownServer is called. Errors are sent on errChan.
+func (s *Server) Serve(errChan chan<- error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	if err := s.serve(ln); err != nil {
+		errChan <- err
+	}
+}
+
+func (s *Server) serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	s.Logger.Info(fmt.Sprintf("starting TCP server on %s", ln.Addr()))
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if s.drain.Err() != nil {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		c, ok := s.track(nc)
+		if !ok {
+			continue
+		}
+		go s.handle(c)
+	}
+}
+
+// track registers a new connection, closing it when the server is draining or full
+func (s *Server) track(nc net.Conn) (*Conn, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.drain.Err() != nil {
+		nc.Close()
+		return nil, false
+	}
+	if s.maxConns > 0 && len(s.conns) >= s.maxConns {
+		s.rejected.Inc()
+		s.Logger.Warn(fmt.Sprintf("closing connection from %s: %d connections open", nc.RemoteAddr(), len(s.conns)))
+		nc.Close()
+		return nil, false
+	}
+
+	c := &Conn{
+		Conn:   nc,
+		Logger: s.Logger.With("remote", nc.RemoteAddr().String()),
+		framer: s.framer,
+		reader: bufio.NewReader(nc),
+		idle:   s.idle,
+		drain:  s.drain,
+	}
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+	s.connections.Inc()
+
+	return c, true
+}
+
+func (s *Server) handle(c *Conn) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.failures.Inc()
+			c.Logger.Error(fmt.Sprintf("connection handler panicked: %v", r))
+		}
+
+		c.Close()
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		s.connections.Dec()
+		s.wg.Done()
+	}()
+
+	c.Logger.Debug("connection opened")
+	ctx := context.WithValue(context.Background(), loggerKey{}, c.Logger)
+	err := s.handler(ctx, c)
+
+	var ne net.Error
+	switch {
+	case err == nil, errors.Is(err, io.EOF), errors.Is(err, ErrDraining), errors.Is(err, net.ErrClosed):
+		c.Logger.Debug("connection closed")
+	case errors.As(err, &ne) && ne.Timeout():
+		c.Logger.Debug("closing idle connection")
+	default:
+		s.failures.Inc()
+		c.Logger.Error(fmt.Sprintf("closing connection: %v", err))
+	}
+}
+
+// ShutdownServer stops accepting connections and drains open ones: reads waiting for the next frame return
+// ErrDraining, so handlers finish the frame they are working on and return. Connections still open when ctx is
+// done are closed.
+func (s *Server) ShutdownServer(ctx context.Context) {
+	s.mu.Lock()
+	s.stop()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for c := range s.conns {
+		c.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		s.Logger.Warn("TCP drain timed out, closing open connections")
+		s.mu.Lock()
+		for c := range s.conns {
+			c.Close()
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	sderrors "github.com/SencilloDev/sencillo-go/errors"
+	"github.com/SencilloDev/sencillo-go/sdtest"
+)
+
+// start serves s on a random local port and returns its address
+func start(t *testing.T, s *Server) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.serve(ln)
+	return ln.Addr().String()
+}
+
+func echo(ctx context.Context, frame []byte) ([]byte, error) {
+	switch string(frame) {
+	case "bad":
+		return nil, sderrors.NewClientError(errors.New("bad command"), http.StatusBadRequest)
+	case "boom":
+		return nil, errors.New("database unavailable")
+	case "quiet":
+		return nil, nil
+	case "slow":
+		time.Sleep(50 * time.Millisecond)
+	}
+	return append([]byte("echo "), frame...), nil
+}
+
+func TestServerFrames(t *testing.T) {
+	s := NewServer(Frames(echo, nil), SetFramer(Delimited{}), SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	reg := sdtest.NewRegistry(t, s.Metrics()...)
+	conn, err := net.Dial("tcp", start(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	tt := []struct {
+		name  string
+		frame string
+		want  string
+	}{
+		{name: "echo", frame: "hello", want: "echo hello"},
+		{name: "no response", frame: "quiet"},
+		{name: "client error", frame: "bad", want: `{"errors": ["bad command"]}`},
+		{name: "still open", frame: "again", want: "echo again"},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			if _, err := io.WriteString(conn, v.frame+"\n"); err != nil {
+				t.Fatal(err)
+			}
+			if v.want == "" {
+				return
+			}
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSuffix(line, "\n"); got != v.want {
+				t.Errorf("expected %q but got %q", v.want, got)
+			}
+		})
+	}
+
+	io.WriteString(conn, "boom\n")
+	if _, err := r.ReadString('\n'); !errors.Is(err, io.EOF) {
+		t.Errorf("expected a server error to close the connection but got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for reg.CounterValue(t, "tcp_connection_errors", nil) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	reg.AssertCounter(t, "tcp_connection_errors", nil, 1)
+}
+
+func TestServerDrain(t *testing.T) {
+	s := NewServer(Frames(echo, nil), SetMaxConns(2), SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	reg := sdtest.NewRegistry(t, s.Metrics()...)
+	addr := start(t, s)
+	framer := LengthPrefixed{}
+
+	busy, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	idle, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for reg.GaugeValue(t, "tcp_connections", nil) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	full, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := full.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected a connection over the limit to be closed but got %v", err)
+	}
+	full.Close()
+
+	if err := framer.WriteFrame(busy, []byte("slow")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.ShutdownServer(ctx)
+
+	resp, err := framer.ReadFrame(bufio.NewReader(busy))
+	if err != nil || string(resp) != "echo slow" {
+		t.Errorf("expected the frame in flight to finish but got %q %v", resp, err)
+	}
+	if _, err := idle.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the idle connection to be closed but got %v", err)
+	}
+	reg.AssertGauge(t, "tcp_connections", nil, 0)
+	reg.AssertCounter(t, "tcp_connections_rejected", nil, 1)
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("expected the listener to be closed")
+	}
+}
+
+func TestServePackets(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ServePackets(ctx, pc, echo, slog.New(slog.NewTextHandler(io.Discard, nil))) }()
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	for _, v := range []struct{ req, want string }{{"ping", "echo ping"}, {"bad", `{"errors": ["bad command"]}`}} {
+		if _, err := conn.Write([]byte(v.req)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 128)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != v.want {
+			t.Errorf("expected %q but got %q", v.want, buf[:n])
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean stop but got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioned
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrUnknownVersion = fmt.Errorf("unknown API version")
+	ErrVersionSunset  = fmt.Errorf("API version has been retired")
+	ErrNoHandler      = fmt.Errorf("no handler for API version")
+)
+
+// APIVersion is one version of an API
+type APIVersion struct {
+	Name       string
+	Deprecated bool
+	// Sunset is when the version stops being served. Requests for it after that are refused.
+	Sunset time.Time
+}
+
+// API lists an API's versions from oldest to newest. Requests that don't ask for a version get the default.
+type API struct {
+	Versions []APIVersion
+	Default  string
+}
+
+// NewAPI returns an API with the versions from oldest to newest. The default is the newest version that isn't
+// deprecated.
+func NewAPI(versions ...APIVersion) *API {
+	a := &API{Versions: versions}
+	for _, v := range versions {
+		if !v.Deprecated {
+			a.Default = v.Name
+		}
+	}
+	if a.Default == "" && len(versions) > 0 {
+		a.Default = versions[len(versions)-1].Name
+	}
+	return a
+}
+
+// Names returns the versions' names from oldest to newest
+func (a *API) Names() []string {
+	names := make([]string, len(a.Versions))
+	for i, v := range a.Versions {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// Lookup returns the requested version, or the default when name is empty. It returns ErrUnknownVersion for a
+// version the API doesn't have and ErrVersionSunset for one past its sunset at now.
+func (a *API) Lookup(name string, now time.Time) (APIVersion, error) {
+	if name == "" {
+		name = a.Default
+	}
+	i := slices.IndexFunc(a.Versions, func(v APIVersion) bool { return v.Name == name })
+	if i < 0 {
+		return APIVersion{}, fmt.Errorf("%w: %q, supported versions are %v", ErrUnknownVersion, name, a.Names())
+	}
+	v := a.Versions[i]
+	if !v.Sunset.IsZero() && !now.Before(v.Sunset) {
+		return v, fmt.Errorf("%w: %s on %s", ErrVersionSunset, v.Name, v.Sunset.UTC().Format(time.DateOnly))
+	}
+	return v, nil
+}
+
+// Resolve returns which of the versions with handlers serves version: the newest one that isn't newer than it.
+// Handlers only need registering for the versions that changed something.
+func (a *API) Resolve(version string, handled func(string) bool) (string, error) {
+	i := slices.IndexFunc(a.Versions, func(v APIVersion) bool { return v.Name == version })
+	for ; i >= 0; i-- {
+		if handled(a.Versions[i].Name) {
+			return a.Versions[i].Name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoHandler, version)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioned
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAPI(t *testing.T) {
+	sunset := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	api := NewAPI(
+		APIVersion{Name: "v1", Deprecated: true, Sunset: sunset},
+		APIVersion{Name: "v2"},
+		APIVersion{Name: "v3"},
+	)
+	if api.Default != "v3" {
+		t.Errorf("expected the newest version as default but got %s", api.Default)
+	}
+	handled := func(name string) bool { return name == "v1" || name == "v3" }
+
+	tt := []struct {
+		name     string
+		version  string
+		now      time.Time
+		err      error
+		resolved string
+	}{
+		{name: "default", now: sunset, resolved: "v3"},
+		{name: "handled", version: "v1", now: sunset.Add(-time.Hour), resolved: "v1"},
+		{name: "inherits older handler", version: "v2", now: sunset, resolved: "v1"},
+		{name: "retired", version: "v1", now: sunset, err: ErrVersionSunset},
+		{name: "unknown", version: "v9", now: sunset, err: ErrUnknownVersion},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			version, err := api.Lookup(v.version, v.now)
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected %v but got %v", v.err, err)
+			}
+			if err != nil {
+				return
+			}
+			resolved, err := api.Resolve(version.Name, handled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolved != v.resolved {
+				t.Errorf("expected %s but got %s", v.resolved, resolved)
+			}
+		})
+	}
+
+	if _, err := api.Resolve("v3", func(string) bool { return false }); !errors.Is(err, ErrNoHandler) {
+		t.Errorf("expected %v but got %v", ErrNoHandler, err)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioned
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// VersionHeader is the message header holding the payload version for event data
+const VersionHeader = "X-Sencillo-Schema-Version"
+
+var (
+	ErrMissingMigration = fmt.Errorf("missing migration")
+	ErrFutureVersion    = fmt.Errorf("payload version is newer than the current version")
+)
+
+// MigrationFunc upgrades a JSON payload by one version
+type MigrationFunc func([]byte) ([]byte, error)
+
+// envelope wraps KV data with its version. Data without an envelope is treated as version 0.
+type envelope struct {
+	Version *int            `json:"schema_version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Migrator encodes values of T with the current version and upgrades older payloads on read
+type Migrator[T any] struct {
+	current    int
+	migrations map[int]MigrationFunc
+}
+
+// NewMigrator returns a Migrator where current is the version of T
+func NewMigrator[T any](current int) *Migrator[T] {
+	return &Migrator[T]{
+		current:    current,
+		migrations: make(map[int]MigrationFunc),
+	}
+}
+
+// Register adds the migration from version from to version from+1
+func (m *Migrator[T]) Register(from int, f MigrationFunc) *Migrator[T] {
+	m.migrations[from] = f
+	return m
+}
+
+// Version returns the current version
+func (m *Migrator[T]) Version() int {
+	return m.current
+}
+
+// Marshal encodes the value in an envelope with the current version
+func (m *Migrator[T]) Marshal(v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	version := m.current
+	return json.Marshal(envelope{Version: &version, Data: data})
+}
+
+// Unmarshal decodes an enveloped payload, running any migrations needed to reach the current version.
+// The returned bool reports whether the payload was upgraded.
+func (m *Migrator[T]) Unmarshal(b []byte) (T, bool, error) {
+	var e envelope
+	if err := json.Unmarshal(b, &e); err != nil || e.Version == nil {
+		return m.decode(0, b)
+	}
+
+	return m.decode(*e.Version, e.Data)
+}
+
+// EncodeMsg returns a message with the encoded value and the current version header
+func (m *Migrator[T]) EncodeMsg(subject string, v T) (*nats.Msg, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(VersionHeader, strconv.Itoa(m.current))
+	msg.Data = data
+
+	return msg, nil
+}
+
+// DecodeMsg decodes the message data using the version header, treating a missing header as version 0
+func (m *Migrator[T]) DecodeMsg(msg *nats.Msg) (T, error) {
+	var version int
+	if h := msg.Header.Get(VersionHeader); h != "" {
+		v, err := strconv.Atoi(h)
+		if err != nil {
+			var zero T
+			return zero, fmt.Errorf("invalid %s header: %w", VersionHeader, err)
+		}
+		version = v
+	}
+
+	v, _, err := m.decode(version, msg.Data)
+	return v, err
+}
+
+// Get reads the key and upgrades it. When the stored payload was an older version it is written back with
+// the current version, unless another writer updated the key first.
+func (m *Migrator[T]) Get(kv nats.KeyValue, key string) (T, error) {
+	var zero T
+	entry, err := kv.Get(key)
+	if err != nil {
+		return zero, err
+	}
+
+	v, upgraded, err := m.Unmarshal(entry.Value())
+	if err != nil || !upgraded {
+		return v, err
+	}
+
+	data, err := m.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+
+	if _, err := kv.Update(key, data, entry.Revision()); err != nil && !errors.Is(err, nats.ErrKeyExists) {
+		return v, err
+	}
+
+	return v, nil
+}
+
+// Put writes the value to the key with the current version
+func (m *Migrator[T]) Put(kv nats.KeyValue, key string, v T) (uint64, error) {
+	data, err := m.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return kv.Put(key, data)
+}
+
+func (m *Migrator[T]) decode(version int, data []byte) (T, bool, error) {
+	var v T
+	if version > m.current {
+		return v, false, fmt.Errorf("%w: %d > %d", ErrFutureVersion, version, m.current)
+	}
+
+	upgraded := version < m.current
+	for ; version < m.current; version++ {
+		f, ok := m.migrations[version]
+		if !ok {
+			return v, false, fmt.Errorf("%w: from version %d", ErrMissingMigration, version)
+		}
+
+		var err error
+		data, err = f(data)
+		if err != nil {
+			return v, false, fmt.Errorf("error migrating from version %d: %w", version, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, false, err
+	}
+
+	return v, upgraded, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Sencillo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioned
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type user struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Active    bool   `json:"active"`
+}
+
+func testMigrator() *Migrator[user] {
+	return NewMigrator[user](2).
+		Register(0, func(b []byte) ([]byte, error) {
+			var v0 struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(b, &v0); err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]string{"first_name": v0.Name})
+		}).
+		Register(1, func(b []byte) ([]byte, error) {
+			var v1 map[string]any
+			if err := json.Unmarshal(b, &v1); err != nil {
+				return nil, err
+			}
+			v1["active"] = true
+			return json.Marshal(v1)
+		})
+}
+
+func TestUnmarshal(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		want     user
+		upgraded bool
+		err      error
+	}{
+		{name: "unversioned", data: `{"name": "Ada"}`, want: user{FirstName: "Ada", Active: true}, upgraded: true},
+		{name: "version 1", data: `{"schema_version": 1, "data": {"first_name": "Ada"}}`, want: user{FirstName: "Ada", Active: true}, upgraded: true},
+		{name: "current", data: `{"schema_version": 2, "data": {"first_name": "Ada", "last_name": "L"}}`, want: user{FirstName: "Ada", LastName: "L"}},
+		{name: "future", data: `{"schema_version": 3, "data": {}}`, err: ErrFutureVersion},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			got, upgraded, err := testMigrator().Unmarshal([]byte(v.data))
+			if !errors.Is(err, v.err) {
+				t.Fatalf("expected error %v but got %v", v.err, err)
+			}
+
+			if !reflect.DeepEqual(got, v.want) || upgraded != v.upgraded {
+				t.Errorf("expected %+v (upgraded %t) but got %+v (upgraded %t)", v.want, v.upgraded, got, upgraded)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	m := testMigrator()
+	want := user{FirstName: "Grace", LastName: "H", Active: true}
+
+	data, err := m.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, upgraded, err := m.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if upgraded || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v but got %+v", want, got)
+	}
+
+	msg, err := m.EncodeMsg("users.created", want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = m.DecodeMsg(msg)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v but got %+v (%v)", want, got, err)
+	}
+}